@@ -20,10 +20,11 @@ import (
 	invoicesJobs "github.com/bowerbird/internal/invoices/adapters/jobs"
 	organizationModule "github.com/bowerbird/internal/organization"
 	"github.com/bowerbird/internal/platform"
+	"github.com/bowerbird/internal/platform/audit"
 	"github.com/bowerbird/internal/platform/auth"
 	awsConfig "github.com/bowerbird/internal/platform/awsconfig"
-	platformCrypto "github.com/bowerbird/internal/platform/crypto"
 	"github.com/bowerbird/internal/platform/events"
+	"github.com/bowerbird/internal/platform/http/api"
 	platformJobs "github.com/bowerbird/internal/platform/jobs"
 	"github.com/bowerbird/internal/platform/tenant"
 )
@@ -46,17 +47,9 @@ func main() {
 	mux := http.NewServeMux()
 
 	// Setup Health Context
-	healthApp := health.NewApplication(pool)
+	healthApp := health.NewApplication(pool, tenantsDbRegistry)
 	health.NewHTTPHandler(mux, healthApp, cfg)
 
-	// Setup Auth & Identity
-	tokenGen := auth.NewTokenGenerator(cfg.JWT.AccessSecret, cfg.JWT.RefreshSecret, cfg.JWT.AccessTTL, cfg.JWT.RefreshTTL)
-	authMiddleware := auth.Middleware(tokenGen)
-
-	identityApp := identityModule.NewApplication(cfg, pool, tenantsDbRegistry, tokenGen)
-	identityModule.NewHTTPHandler(mux, identityApp, pool, tenantsDbRegistry, authMiddleware, cfg)
-
-	// Setup Organization Context
 	// Provide the root directory for migrations relative to the running binary (or use an env var)
 	migrationsDir := os.Getenv("TENANT_MIGRATIONS_DIR")
 	if migrationsDir == "" {
@@ -65,7 +58,28 @@ func main() {
 			migrationsDir = "apps/backend/migrations/tenant"
 		}
 	}
-	organizationApp := organizationModule.NewApplication(pool, cfg.DatabaseURL, migrationsDir)
+	// Built before the auth middleware, which accepts organization API keys.
+	organizationApp := organizationModule.NewApplication(pool, cfg, migrationsDir, platformModule.FilePurger, platformModule.JobQueue, tenantsDbRegistry, platformModule.FileStore, platformModule.EventBus)
+
+	// Setup Auth & Identity
+	tokenGen, err := auth.NewTokenGeneratorFromConfig(cfg.JWT)
+	if err != nil {
+		log.Fatalf("failed to build token generator: %v", err)
+	}
+	authMiddleware := auth.Middleware(
+		tokenGen,
+		auth.WithMembershipVerifier(identityModule.NewMembershipVerifier(pool, tenantsDbRegistry)),
+		auth.WithAPIKeys(organizationModule.NewAPIKeyAuthenticator(organizationApp)),
+	)
+	mux.Handle("GET /.well-known/jwks.json", auth.JWKSHandler(tokenGen))
+
+	// Config loading guarantees a key is set; the cipher also protects TOTP secrets at rest.
+	secretCipher := platformModule.CredentialsCipher
+
+	identityApp := identityModule.NewApplication(cfg, pool, tenantsDbRegistry, tokenGen, platformModule.EventBus, secretCipher, platformModule.AuditRecorder)
+	identityModule.NewHTTPHandler(mux, identityApp, pool, tenantsDbRegistry, authMiddleware, cfg)
+
+	// Setup Organization Context
 	organizationModule.NewHTTPHandler(mux, organizationApp, authMiddleware, cfg)
 
 	// Setup AWS Config
@@ -86,17 +100,14 @@ func main() {
 	// Setup Connections Context
 	var connectionsService connectionsApp.InternalService
 	if cfg.InboxCredentialsEncryptionKey != "" {
-		cipher, err := platformCrypto.NewAESCipherFromBase64Key(cfg.InboxCredentialsEncryptionKey)
-		if err != nil {
-			log.Fatalf("new cipher failed: %v", err)
-		}
-		connectionsApp := connectionsModule.NewApplication(tenantsDbRegistry, cipher)
+		cipher := platformModule.CredentialsCipher
+		connectionsApp := connectionsModule.NewApplication(cfg, tenantsDbRegistry, cipher, platformModule.AuditRecorder)
 		connectionsService = connectionsModule.NewInternalService(connectionsApp)
-		connectionsModule.NewHTTPHandler(mux, cfg, tenantsDbRegistry, cipher, tokenGen, cipher, connectionsEventBus, authMiddleware)
+		connectionsModule.NewHTTPHandler(mux, cfg, tenantsDbRegistry, cipher, tokenGen, cipher, connectionsEventBus, platformModule.AuditRecorder, authMiddleware)
 	} else {
-		connectionsApp := connectionsModule.NewApplication(tenantsDbRegistry, nil)
+		connectionsApp := connectionsModule.NewApplication(cfg, tenantsDbRegistry, nil, platformModule.AuditRecorder)
 		connectionsService = connectionsModule.NewInternalService(connectionsApp)
-		connectionsModule.NewHTTPHandler(mux, cfg, tenantsDbRegistry, nil, tokenGen, nil, connectionsEventBus, authMiddleware)
+		connectionsModule.NewHTTPHandler(mux, cfg, tenantsDbRegistry, nil, tokenGen, nil, connectionsEventBus, platformModule.AuditRecorder, authMiddleware)
 	}
 
 	// Setup Inbox Context
@@ -109,6 +120,8 @@ func main() {
 		connectionsService,
 		platformModule.EventBus,
 		platformModule.FileStore,
+		platformModule.FileDeleter,
+		platformModule.JobQueue,
 		tenantsDbRegistry,
 	)
 	inboxModule.NewHTTPHandler(mux, inboxApp, authMiddleware, cfg)
@@ -119,6 +132,7 @@ func main() {
 		platformModule.JobQueue,
 		platformModule.FileStore,
 		tenantsDbRegistry,
+		platformModule.AuditRecorder,
 	)
 	invoicesModule.NewHTTPHandler(mux, invoicingApp, authMiddleware, cfg)
 
@@ -126,8 +140,15 @@ func main() {
 	invoiceExtractionProcessor := invoicesJobs.NewInvoiceExtractionRequestedProcessor(invoicingApp.Commands.ProcessInvoiceExtractionJob)
 
 	inboxEventsSubscriber := inboxModule.NewConnectionAddedSubscriber(inboxApp)
-	eventHandler := events.NewEventHandler(inboxMessageSubscriber, inboxEventsSubscriber)
-	jobHandler := platformJobs.NewHandler(invoiceExtractionProcessor)
+	inboxMessageProcessedSubscriber := inboxModule.NewInboxMessageProcessedSubscriber(inboxApp)
+	purgeDueSubscriber := organizationModule.NewPurgeDueSubscriber(organizationApp)
+	erasureDueSubscriber := identityModule.NewAccountErasureDueSubscriber(pool, tenantsDbRegistry, connectionsService, inboxModule.NewInternalService(inboxApp), platformModule.FilePurger, platformModule.AuditRecorder)
+	eventHandler := events.NewEventHandler(inboxMessageSubscriber, inboxEventsSubscriber, inboxMessageProcessedSubscriber, purgeDueSubscriber, erasureDueSubscriber)
+	provisioningProcessor := organizationModule.NewProvisioningProcessor(organizationApp)
+	exportProcessor := organizationModule.NewExportProcessor(organizationApp)
+	importProcessor := organizationModule.NewImportProcessor(organizationApp)
+	historicalImportProcessor := inboxModule.NewHistoricalImportProcessor(inboxApp)
+	jobHandler := platformJobs.NewHandler(invoiceExtractionProcessor, provisioningProcessor, exportProcessor, importProcessor, historicalImportProcessor)
 
 	if cfg.EnableLocalEventLoop && cfg.AWSEndpointURL != "" {
 		sqsClient := awsConfig.NewSQSClient(awsCfg, cfg.AWSEndpointURL)
@@ -140,7 +161,7 @@ func main() {
 
 	server := &http.Server{
 		Addr:         ":" + cfg.Port,
-		Handler:      withSecurityHeaders(withCORS(tenant.Middleware(mux), cfg.AllowedOrigins)),
+		Handler:      withSecurityHeaders(withCORS(api.GenerateTraceIDMiddleware(api.ClientIPMiddleware(cfg.TrustedProxyHops)(audit.Middleware(tenant.Middleware(mux)))), cfg.AllowedOrigins)),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  30 * time.Second,
@@ -179,7 +200,7 @@ func withSecurityHeaders(next http.Handler) http.Handler {
 func withCORS(next http.Handler, allowedOrigins string) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", allowedOrigins)
-		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization,X-Tenant-ID")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
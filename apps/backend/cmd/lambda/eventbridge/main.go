@@ -3,15 +3,17 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 	connectionsModule "github.com/bowerbird/internal/connections"
+	identityModule "github.com/bowerbird/internal/identity"
 	inboxModule "github.com/bowerbird/internal/inbox"
 	invoicesModule "github.com/bowerbird/internal/invoices"
 	invoicesEvents "github.com/bowerbird/internal/invoices/adapters/events"
+	organizationModule "github.com/bowerbird/internal/organization"
 	"github.com/bowerbird/internal/platform"
-	platformCrypto "github.com/bowerbird/internal/platform/crypto"
 	platformEvents "github.com/bowerbird/internal/platform/events"
 )
 
@@ -30,15 +32,11 @@ func init() {
 		platformModule.JobQueue,
 		platformModule.FileStore,
 		platformModule.TenantRegistry,
+		platformModule.AuditRecorder,
 	)
 	inboxMessageSubscriber := invoicesEvents.NewInboxMessageReceivedSubscriber(invoicingApp.Commands.CreateInvoicesFromInboxMessage)
 
-	cipher, err := platformCrypto.NewAESCipherFromBase64Key(cfg.InboxCredentialsEncryptionKey)
-	if err != nil {
-		log.Fatalf("failed to create inbox credentials cipher at boot: %v", err)
-	}
-
-	connectionsApp := connectionsModule.NewApplication(platformModule.TenantRegistry, cipher)
+	connectionsApp := connectionsModule.NewApplication(cfg, platformModule.TenantRegistry, platformModule.CredentialsCipher, platformModule.AuditRecorder)
 	connectionsService := connectionsModule.NewInternalService(connectionsApp)
 
 	inboxApp := inboxModule.NewApplication(
@@ -46,10 +44,42 @@ func init() {
 		connectionsService,
 		platformModule.EventBus,
 		platformModule.FileStore,
+		platformModule.FileDeleter,
+		platformModule.JobQueue,
 		platformModule.TenantRegistry,
 	)
 	connectionAddedSubscriber := inboxModule.NewConnectionAddedSubscriber(inboxApp)
-	eventHandler = platformEvents.NewEventHandler(inboxMessageSubscriber, connectionAddedSubscriber)
+	inboxMessageProcessedSubscriber := inboxModule.NewInboxMessageProcessedSubscriber(inboxApp)
+
+	// Purging only drops databases, so the migrations dir is never read here.
+	migrationsDir := os.Getenv("TENANT_MIGRATIONS_DIR")
+	if migrationsDir == "" {
+		migrationsDir = "migrations/tenant"
+	}
+	organizationApp := organizationModule.NewApplication(platformModule.ControlDB, cfg, migrationsDir, platformModule.FilePurger, platformModule.JobQueue, platformModule.TenantRegistry, platformModule.FileStore, platformModule.EventBus)
+	purgeDueSubscriber := organizationModule.NewPurgeDueSubscriber(organizationApp)
+
+	erasureDueSubscriber := identityModule.NewAccountErasureDueSubscriber(
+		platformModule.ControlDB,
+		platformModule.TenantRegistry,
+		connectionsService,
+		inboxModule.NewInternalService(inboxApp),
+		platformModule.FilePurger,
+		platformModule.AuditRecorder,
+	)
+
+	credentialsRewrapDueSubscriber := connectionsModule.NewCredentialsRewrapDueSubscriber(
+		platformModule.ControlDB,
+		platformModule.TenantRegistry,
+		platformModule.CredentialsCipher,
+	)
+	secretsRewrapDueSubscriber := identityModule.NewCredentialsRewrapDueSubscriber(
+		platformModule.ControlDB,
+		platformModule.TenantRegistry,
+		platformModule.CredentialsCipher,
+	)
+
+	eventHandler = platformEvents.NewEventHandler(inboxMessageSubscriber, connectionAddedSubscriber, inboxMessageProcessedSubscriber, purgeDueSubscriber, erasureDueSubscriber, credentialsRewrapDueSubscriber, secretsRewrapDueSubscriber)
 }
 
 func handle(ctx context.Context, event events.CloudWatchEvent) error {
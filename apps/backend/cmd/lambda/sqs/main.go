@@ -3,11 +3,15 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	connectionsModule "github.com/bowerbird/internal/connections"
+	inboxModule "github.com/bowerbird/internal/inbox"
 	invoicesModule "github.com/bowerbird/internal/invoices"
 	invoicesJobs "github.com/bowerbird/internal/invoices/adapters/jobs"
+	organizationModule "github.com/bowerbird/internal/organization"
 	"github.com/bowerbird/internal/platform"
 	platformJobs "github.com/bowerbird/internal/platform/jobs"
 )
@@ -26,13 +30,47 @@ func init() {
 		platformModule.JobQueue,
 		platformModule.FileStore,
 		platformModule.TenantRegistry,
+		platformModule.AuditRecorder,
 	)
 
 	processorCommand := invoicesJobs.NewInvoiceExtractionRequestedProcessor(
 		invoicesApp.Commands.ProcessInvoiceExtractionJob,
 	)
 
-	jobHandler = platformJobs.NewHandler(processorCommand)
+	// Provisioning migrates new tenant databases, so the tenant migrations
+	// must be packaged with this function.
+	migrationsDir := os.Getenv("TENANT_MIGRATIONS_DIR")
+	if migrationsDir == "" {
+		migrationsDir = "migrations/tenant"
+	}
+	organizationApp := organizationModule.NewApplication(
+		platformModule.ControlDB,
+		platformModule.Config,
+		migrationsDir,
+		platformModule.FilePurger,
+		platformModule.JobQueue,
+		platformModule.TenantRegistry,
+		platformModule.FileStore,
+		platformModule.EventBus,
+	)
+	provisioningProcessor := organizationModule.NewProvisioningProcessor(organizationApp)
+	exportProcessor := organizationModule.NewExportProcessor(organizationApp)
+	importProcessor := organizationModule.NewImportProcessor(organizationApp)
+
+	// Historical imports run the inbox sync pipeline chunk by chunk.
+	connectionsApp := connectionsModule.NewApplication(platformModule.Config, platformModule.TenantRegistry, platformModule.CredentialsCipher, platformModule.AuditRecorder)
+	inboxApp := inboxModule.NewApplication(
+		platformModule.Config,
+		connectionsModule.NewInternalService(connectionsApp),
+		platformModule.EventBus,
+		platformModule.FileStore,
+		platformModule.FileDeleter,
+		platformModule.JobQueue,
+		platformModule.TenantRegistry,
+	)
+	historicalImportProcessor := inboxModule.NewHistoricalImportProcessor(inboxApp)
+
+	jobHandler = platformJobs.NewHandler(processorCommand, provisioningProcessor, exportProcessor, importProcessor, historicalImportProcessor)
 }
 
 func handle(ctx context.Context, event events.SQSEvent) error {
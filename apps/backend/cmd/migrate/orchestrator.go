@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// tenantDatabase is a tenant whose database is migrated by the orchestrator.
+type tenantDatabase struct {
+	ID     string
+	Slug   string
+	DBName string
+	// SharedDatabase holds the tenant as the schema DBName; empty for a
+	// dedicated database.
+	SharedDatabase string
+	// LastError is the error recorded by the previous run, if any.
+	LastError string
+}
+
+// tenantCatalog lists tenants and records migration results in the control plane.
+type tenantCatalog interface {
+	ListTenants(ctx context.Context, refs []string) ([]tenantDatabase, error)
+	RecordResult(ctx context.Context, tenantID string, version uint, dirty bool, migrateErr error) error
+	// RecordError keeps the recorded version when the database could not be read.
+	RecordError(ctx context.Context, tenantID string, migrateErr error) error
+}
+
+// schemaMigrator reads and changes the schema version of a tenant database.
+type schemaMigrator interface {
+	Version(ctx context.Context, tenant tenantDatabase) (uint, bool, error)
+	// MigrateTo migrates up or down to version.
+	MigrateTo(ctx context.Context, tenant tenantDatabase, version uint) error
+}
+
+type tenantResult struct {
+	Tenant      tenantDatabase
+	FromVersion uint
+	ToVersion   uint
+	Dirty       bool
+	// Pending lists the versions to apply (up) or revert (down).
+	Pending []uint
+	Err     error
+}
+
+// migration is what a run asks of every tenant database.
+type migration struct {
+	Target uint
+	// Down reverts migrations instead of applying them. A target on the other
+	// side of a database's version is rejected rather than followed.
+	Down   bool
+	DryRun bool
+}
+
+type orchestrator struct {
+	catalog     tenantCatalog
+	migrator    schemaMigrator
+	concurrency int
+	// versions are the tenant migrations available, in ascending order.
+	versions []uint
+}
+
+func newOrchestrator(catalog tenantCatalog, migrator schemaMigrator, concurrency int, versions []uint) *orchestrator {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &orchestrator{catalog: catalog, migrator: migrator, concurrency: concurrency, versions: versions}
+}
+
+func (o *orchestrator) latestVersion() uint {
+	if len(o.versions) == 0 {
+		return 0
+	}
+	return o.versions[len(o.versions)-1]
+}
+
+// Migrate moves every tenant to the target, or only plans the change on a dry
+// run. Tenants are processed concurrently up to the configured limit and a
+// failing tenant does not stop the others.
+func (o *orchestrator) Migrate(ctx context.Context, tenants []tenantDatabase, plan migration) []tenantResult {
+	target := plan.Target
+	return o.forEach(ctx, tenants, func(ctx context.Context, tenant tenantDatabase) tenantResult {
+		result := o.inspect(ctx, tenant, target)
+		if result.Err == nil {
+			// Rejected before anything runs, so there is nothing to record.
+			if err := checkDirection(plan.Down, result.FromVersion, target); err != nil {
+				result.Err, result.Pending, result.ToVersion = err, nil, result.FromVersion
+				return result
+			}
+		}
+		if plan.DryRun {
+			return result
+		}
+		if result.Err != nil {
+			if err := o.catalog.RecordError(ctx, tenant.ID, result.Err); err != nil {
+				result.Err = errors.Join(result.Err, fmt.Errorf("record migration error: %w", err))
+			}
+			return result
+		}
+		if result.Dirty {
+			result.Err = fmt.Errorf("database is dirty at version %d; fix it and force the version before migrating", result.FromVersion)
+			o.record(ctx, &result)
+			return result
+		}
+		if len(result.Pending) == 0 {
+			o.record(ctx, &result)
+			return result
+		}
+
+		migrateErr := o.migrator.MigrateTo(ctx, tenant, target)
+
+		// Record what the database is at now, even after a failure.
+		version, dirty, versionErr := o.migrator.Version(ctx, tenant)
+		if versionErr != nil && migrateErr == nil {
+			migrateErr = versionErr
+		}
+		result.ToVersion, result.Dirty, result.Err = version, dirty, migrateErr
+		o.record(ctx, &result)
+
+		return result
+	})
+}
+
+// Status reports each tenant's version and what migrating to target would do.
+func (o *orchestrator) Status(ctx context.Context, tenants []tenantDatabase, target uint) []tenantResult {
+	return o.forEach(ctx, tenants, func(ctx context.Context, tenant tenantDatabase) tenantResult {
+		return o.inspect(ctx, tenant, target)
+	})
+}
+
+func (o *orchestrator) inspect(ctx context.Context, tenant tenantDatabase, target uint) tenantResult {
+	result := tenantResult{Tenant: tenant, ToVersion: target}
+
+	version, dirty, err := o.migrator.Version(ctx, tenant)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.FromVersion, result.Dirty = version, dirty
+	result.Pending = pendingVersions(o.versions, version, target)
+	if len(result.Pending) == 0 && !dirty {
+		result.ToVersion = version
+	}
+
+	return result
+}
+
+// record stores the result in the control plane. A failure to record is
+// reported as the tenant's error unless migrating already failed.
+func (o *orchestrator) record(ctx context.Context, result *tenantResult) {
+	err := o.catalog.RecordResult(ctx, result.Tenant.ID, result.ToVersion, result.Dirty, result.Err)
+	if err != nil && result.Err == nil {
+		result.Err = fmt.Errorf("record schema version: %w", err)
+	}
+}
+
+func (o *orchestrator) forEach(ctx context.Context, tenants []tenantDatabase, run func(context.Context, tenantDatabase) tenantResult) []tenantResult {
+	results := make([]tenantResult, len(tenants))
+	slots := make(chan struct{}, o.concurrency)
+	var wg sync.WaitGroup
+
+	for i, tenant := range tenants {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[i] = run(ctx, tenant)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// checkDirection rejects a target on the wrong side of the current version:
+// golang-migrate moves either way, so "up" to an older version would revert
+// migrations and "down" to a newer one would apply them.
+func checkDirection(down bool, current, target uint) error {
+	switch {
+	case down && target > current:
+		return fmt.Errorf("version %d is above the current version %d; use up", target, current)
+	case !down && target < current:
+		return fmt.Errorf("version %d is below the current version %d; use down", target, current)
+	}
+	return nil
+}
+
+// pendingVersions lists the versions between from and to: the ones to apply
+// when migrating up, or to revert, newest first, when migrating down.
+func pendingVersions(available []uint, from, to uint) []uint {
+	var pending []uint
+	if to >= from {
+		for _, version := range available {
+			if version > from && version <= to {
+				pending = append(pending, version)
+			}
+		}
+		return pending
+	}
+
+	for i := len(available) - 1; i >= 0; i-- {
+		if available[i] <= from && available[i] > to {
+			pending = append(pending, available[i])
+		}
+	}
+	return pending
+}
+
+func failedCount(results []tenantResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type recordedResult struct {
+	version uint
+	dirty   bool
+	err     error
+}
+
+type fakeCatalog struct {
+	mu      sync.Mutex
+	results map[string]recordedResult
+	errors  map[string]error
+}
+
+func newFakeCatalog() *fakeCatalog {
+	return &fakeCatalog{results: map[string]recordedResult{}, errors: map[string]error{}}
+}
+
+func (c *fakeCatalog) ListTenants(ctx context.Context, refs []string) ([]tenantDatabase, error) {
+	return nil, nil
+}
+
+func (c *fakeCatalog) RecordResult(ctx context.Context, tenantID string, version uint, dirty bool, migrateErr error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[tenantID] = recordedResult{version: version, dirty: dirty, err: migrateErr}
+	return nil
+}
+
+func (c *fakeCatalog) RecordError(ctx context.Context, tenantID string, migrateErr error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[tenantID] = migrateErr
+	return nil
+}
+
+type fakeMigrator struct {
+	mu       sync.Mutex
+	versions map[string]uint
+	dirty    map[string]bool
+	failOn   map[string]error
+	migrated []string
+
+	running    atomic.Int32
+	maxRunning atomic.Int32
+}
+
+func (m *fakeMigrator) Version(ctx context.Context, tenant tenantDatabase) (uint, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.versions[tenant.DBName], m.dirty[tenant.DBName], nil
+}
+
+func (m *fakeMigrator) MigrateTo(ctx context.Context, tenant tenantDatabase, version uint) error {
+	dbName := tenant.DBName
+	running := m.running.Add(1)
+	defer m.running.Add(-1)
+	for {
+		peak := m.maxRunning.Load()
+		if running <= peak || m.maxRunning.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.migrated = append(m.migrated, dbName)
+	if err := m.failOn[dbName]; err != nil {
+		m.dirty[dbName] = true
+		return err
+	}
+	m.versions[dbName] = version
+	return nil
+}
+
+func tenantsNamed(names ...string) []tenantDatabase {
+	tenants := make([]tenantDatabase, 0, len(names))
+	for _, name := range names {
+		tenants = append(tenants, tenantDatabase{ID: name, Slug: name, DBName: "tenant_" + name})
+	}
+	return tenants
+}
+
+func TestMigrateContinuesAfterTenantFailure(t *testing.T) {
+	catalog := newFakeCatalog()
+	migrator := &fakeMigrator{
+		versions: map[string]uint{"tenant_acme": 1, "tenant_stark": 1, "tenant_wayne": 1},
+		dirty:    map[string]bool{},
+		failOn:   map[string]error{"tenant_stark": errors.New("syntax error")},
+	}
+	orch := newOrchestrator(catalog, migrator, 2, []uint{1, 2, 3})
+
+	results := orch.Migrate(context.Background(), tenantsNamed("acme", "stark", "wayne"), migration{Target: 3})
+
+	if failedCount(results) != 1 {
+		t.Fatalf("expected one failed tenant, got %d", failedCount(results))
+	}
+	if len(migrator.migrated) != 3 {
+		t.Fatalf("expected every tenant to be migrated, got %v", migrator.migrated)
+	}
+	if got := catalog.results["acme"]; got.version != 3 || got.err != nil {
+		t.Fatalf("expected acme recorded at version 3, got %+v", got)
+	}
+	if got := catalog.results["stark"]; !got.dirty || got.err == nil {
+		t.Fatalf("expected stark recorded as dirty with error, got %+v", got)
+	}
+}
+
+func TestMigrateBoundsConcurrency(t *testing.T) {
+	migrator := &fakeMigrator{versions: map[string]uint{}, dirty: map[string]bool{}}
+	orch := newOrchestrator(newFakeCatalog(), migrator, 2, []uint{1})
+
+	orch.Migrate(context.Background(), tenantsNamed("a", "b", "c", "d", "e", "f"), migration{Target: 1})
+
+	if peak := migrator.maxRunning.Load(); peak > 2 {
+		t.Fatalf("expected at most 2 concurrent migrations, got %d", peak)
+	}
+	if len(migrator.migrated) != 6 {
+		t.Fatalf("expected 6 migrations, got %d", len(migrator.migrated))
+	}
+}
+
+func TestMigrateDryRunDoesNotChangeDatabases(t *testing.T) {
+	catalog := newFakeCatalog()
+	migrator := &fakeMigrator{versions: map[string]uint{"tenant_acme": 1}, dirty: map[string]bool{}}
+	orch := newOrchestrator(catalog, migrator, 1, []uint{1, 2, 3})
+
+	results := orch.Migrate(context.Background(), tenantsNamed("acme"), migration{Target: 3, DryRun: true})
+
+	if len(migrator.migrated) != 0 || len(catalog.results) != 0 {
+		t.Fatalf("expected no migrations or records, got %v and %v", migrator.migrated, catalog.results)
+	}
+	if !slices.Equal(results[0].Pending, []uint{2, 3}) {
+		t.Fatalf("expected pending [2 3], got %v", results[0].Pending)
+	}
+}
+
+func TestMigrateSkipsDirtyDatabase(t *testing.T) {
+	catalog := newFakeCatalog()
+	migrator := &fakeMigrator{
+		versions: map[string]uint{"tenant_acme": 2},
+		dirty:    map[string]bool{"tenant_acme": true},
+	}
+	orch := newOrchestrator(catalog, migrator, 1, []uint{1, 2, 3})
+
+	results := orch.Migrate(context.Background(), tenantsNamed("acme"), migration{Target: 3})
+
+	if results[0].Err == nil {
+		t.Fatal("expected dirty database to be reported as failed")
+	}
+	if len(migrator.migrated) != 0 {
+		t.Fatalf("expected dirty database not to be migrated, got %v", migrator.migrated)
+	}
+}
+
+func TestMigrateRejectsUpBelowCurrentVersion(t *testing.T) {
+	catalog := newFakeCatalog()
+	migrator := &fakeMigrator{versions: map[string]uint{"tenant_acme": 3, "tenant_stark": 1}, dirty: map[string]bool{}}
+	orch := newOrchestrator(catalog, migrator, 1, []uint{1, 2, 3})
+
+	results := orch.Migrate(context.Background(), tenantsNamed("acme", "stark"), migration{Target: 2})
+
+	if results[0].Err == nil || len(results[0].Pending) != 0 {
+		t.Fatalf("expected up to an older version to be rejected, got %+v", results[0])
+	}
+	if _, recorded := catalog.results["acme"]; recorded {
+		t.Fatal("expected nothing recorded for a rejected target")
+	}
+	if results[1].Err != nil || !slices.Equal(migrator.migrated, []string{"tenant_stark"}) {
+		t.Fatalf("expected only the older tenant to be migrated up, got %v: %v", migrator.migrated, results[1].Err)
+	}
+}
+
+func TestMigrateRejectsDownAboveCurrentVersion(t *testing.T) {
+	migrator := &fakeMigrator{versions: map[string]uint{"tenant_acme": 1, "tenant_stark": 3}, dirty: map[string]bool{}}
+	orch := newOrchestrator(newFakeCatalog(), migrator, 1, []uint{1, 2, 3})
+
+	results := orch.Migrate(context.Background(), tenantsNamed("acme", "stark"), migration{Target: 2, Down: true})
+
+	if results[0].Err == nil {
+		t.Fatalf("expected down to a newer version to be rejected, got %+v", results[0])
+	}
+	if results[1].Err != nil || !slices.Equal(migrator.migrated, []string{"tenant_stark"}) {
+		t.Fatalf("expected only the newer tenant to be migrated down, got %v: %v", migrator.migrated, results[1].Err)
+	}
+	if migrator.versions["tenant_acme"] != 1 || migrator.versions["tenant_stark"] != 2 {
+		t.Fatalf("unexpected versions %v", migrator.versions)
+	}
+}
+
+func TestMigrateDownToZero(t *testing.T) {
+	migrator := &fakeMigrator{versions: map[string]uint{"tenant_acme": 2}, dirty: map[string]bool{}}
+	orch := newOrchestrator(newFakeCatalog(), migrator, 1, []uint{1, 2})
+
+	results := orch.Migrate(context.Background(), tenantsNamed("acme"), migration{Target: 0, Down: true})
+
+	if results[0].Err != nil || migrator.versions["tenant_acme"] != 0 {
+		t.Fatalf("expected the tenant reverted to 0, got %+v", results[0])
+	}
+	if !slices.Equal(results[0].Pending, []uint{2, 1}) {
+		t.Fatalf("expected pending [2 1], got %v", results[0].Pending)
+	}
+}
+
+func TestPendingVersions(t *testing.T) {
+	available := []uint{1, 2, 3, 4}
+
+	if got := pendingVersions(available, 1, 3); !slices.Equal(got, []uint{2, 3}) {
+		t.Fatalf("expected up [2 3], got %v", got)
+	}
+	if got := pendingVersions(available, 4, 1); !slices.Equal(got, []uint{4, 3, 2}) {
+		t.Fatalf("expected down [4 3 2], got %v", got)
+	}
+	if got := pendingVersions(available, 4, 4); len(got) != 0 {
+		t.Fatalf("expected nothing pending, got %v", got)
+	}
+}
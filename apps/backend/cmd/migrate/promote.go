@@ -0,0 +1,485 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bowerbird/internal/platform/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// statusMaintenance takes a tenant offline while it is promoted; the tenant
+// registry only serves active tenants.
+const statusMaintenance = "maintenance"
+
+// promotableStatuses are the statuses a tenant can be promoted from. The
+// status is restored once the promotion finishes or fails.
+var promotableStatuses = []string{"active", "suspended", "archived"}
+
+// promotionTenant is a tenant stored as a schema of a shared database.
+type promotionTenant struct {
+	ID             string
+	Slug           string
+	DBName         string
+	SharedDatabase string
+	Status         string
+}
+
+// promoter moves tenants from a schema of a shared database to a dedicated
+// database with the same name, so their connection strings stay stable.
+type promoter struct {
+	control       *pgxpool.Pool
+	baseURL       string
+	migrationsDir string
+	drain         time.Duration
+	dropSchema    bool
+}
+
+func runPromote(ctx context.Context, databaseURL string, opts options) error {
+	control, err := database.Connect(ctx, databaseURL)
+	if err != nil {
+		return fmt.Errorf("connect to control plane: %w", err)
+	}
+	defer control.Close()
+
+	p := &promoter{control: control, baseURL: databaseURL, migrationsDir: tenantMigrationsDir, drain: opts.drain, dropSchema: opts.dropSchema}
+
+	var errs []error
+	for _, ref := range opts.tenants {
+		if err := p.Promote(ctx, ref); err != nil {
+			log.Printf("Promoting %s failed: %v", ref, err)
+			errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+			continue
+		}
+		log.Printf("Tenant %s now has a dedicated database", ref)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Promote copies the tenant's schema into a new database and points the
+// control plane at it. The tenant is in maintenance meanwhile, and any failure
+// leaves it where it was.
+func (p *promoter) Promote(ctx context.Context, ref string) (err error) {
+	tenant, err := p.loadTenant(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	version, dirty, err := database.MigrationVersion(p.tenantURL(tenant.SharedDatabase, tenant.DBName), p.migrationsDir)
+	if err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("schema is dirty at version %d; fix it before promoting", version)
+	}
+	if exists, err := p.databaseExists(ctx, tenant.DBName); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("database %s already exists", tenant.DBName)
+	}
+
+	source, err := pgxpool.New(ctx, p.tenantURL(tenant.SharedDatabase, ""))
+	if err != nil {
+		return fmt.Errorf("connect to shared database: %w", err)
+	}
+	defer source.Close()
+
+	if err := p.setStatus(ctx, tenant.ID, tenant.Status, statusMaintenance); err != nil {
+		return fmt.Errorf("enter maintenance: %w", err)
+	}
+	// Undo runs without the caller's context so an interrupted promotion
+	// still puts the tenant back.
+	var renamed, created bool
+	defer func() {
+		if err == nil {
+			return
+		}
+		undoCtx := context.WithoutCancel(ctx)
+		if renamed {
+			err = errors.Join(err, renameSchema(undoCtx, source, promotedSchema(tenant.DBName), tenant.DBName))
+		}
+		if created {
+			err = errors.Join(err, p.dropDatabase(undoCtx, tenant.DBName))
+		}
+		err = errors.Join(err, p.setStatus(undoCtx, tenant.ID, statusMaintenance, tenant.Status))
+	}()
+
+	// Processes resolving the tenant from a cached lookup keep scoping
+	// connections to its schema until the lookup expires.
+	log.Printf("Tenant %s in maintenance; draining for %s", tenant.Slug, p.drain)
+	select {
+	case <-time.After(p.drain):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// Renaming makes any write that slipped past the drain fail instead of
+	// landing in a schema that is no longer read.
+	if err := renameSchema(ctx, source, tenant.DBName, promotedSchema(tenant.DBName)); err != nil {
+		return err
+	}
+	renamed = true
+
+	if _, err := p.control.Exec(ctx, fmt.Sprintf("CREATE DATABASE %s", pgx.Identifier{tenant.DBName}.Sanitize())); err != nil {
+		return fmt.Errorf("create database: %w", err)
+	}
+	created = true
+
+	if err := database.MigrateToVersion(p.tenantURL(tenant.DBName, ""), p.migrationsDir, version); err != nil {
+		return fmt.Errorf("migrate database to version %d: %w", version, err)
+	}
+
+	target, err := pgxpool.New(ctx, p.tenantURL(tenant.DBName, ""))
+	if err != nil {
+		return fmt.Errorf("connect to new database: %w", err)
+	}
+	defer target.Close()
+
+	if err := copySchema(ctx, source, target, promotedSchema(tenant.DBName)); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE tenants
+		SET isolation_mode = 'database', shared_database = NULL, status = $2,
+		    schema_version = $3, schema_dirty = FALSE, updated_at = NOW()
+		WHERE id = $1 AND status = $4
+	`
+	tag, err := p.control.Exec(ctx, query, tenant.ID, tenant.Status, int64(version), statusMaintenance)
+	if err != nil {
+		return fmt.Errorf("update control plane: %w", err)
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("tenant left maintenance during the promotion")
+	}
+	// The tenant is served from its new database from here on, so a failure
+	// to clean up must not undo the promotion.
+	renamed, created = false, false
+
+	if !p.dropSchema {
+		log.Printf("Kept the old schema as %s in %s; drop it once the tenant is verified", promotedSchema(tenant.DBName), tenant.SharedDatabase)
+		return nil
+	}
+	query = fmt.Sprintf("DROP SCHEMA %s CASCADE", pgx.Identifier{promotedSchema(tenant.DBName)}.Sanitize())
+	if _, err := source.Exec(ctx, query); err != nil {
+		log.Printf("Tenant %s was promoted but its old schema was not dropped: %v", tenant.Slug, err)
+	}
+
+	return nil
+}
+
+func (p *promoter) loadTenant(ctx context.Context, ref string) (promotionTenant, error) {
+	query := `
+		SELECT id, slug, db_name, isolation_mode, COALESCE(shared_database, ''), status
+		FROM tenants
+		WHERE id = $1 OR slug = $1
+	`
+	var tenant promotionTenant
+	var mode string
+	err := p.control.QueryRow(ctx, query, ref).Scan(&tenant.ID, &tenant.Slug, &tenant.DBName, &mode, &tenant.SharedDatabase, &tenant.Status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return tenant, fmt.Errorf("tenant not found")
+	}
+	if err != nil {
+		return tenant, fmt.Errorf("load tenant: %w", err)
+	}
+
+	if mode != "schema" {
+		return tenant, fmt.Errorf("tenant already has a dedicated database")
+	}
+	if !slices.Contains(promotableStatuses, tenant.Status) {
+		return tenant, fmt.Errorf("tenant is %s; only %s tenants can be promoted", tenant.Status, strings.Join(promotableStatuses, ", "))
+	}
+
+	return tenant, nil
+}
+
+func (p *promoter) setStatus(ctx context.Context, tenantID, from, to string) error {
+	tag, err := p.control.Exec(ctx, `UPDATE tenants SET status = $1, updated_at = NOW() WHERE id = $2 AND status = $3`, to, tenantID, from)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() != 1 {
+		return fmt.Errorf("tenant is no longer %s", from)
+	}
+	return nil
+}
+
+func (p *promoter) databaseExists(ctx context.Context, dbName string) (bool, error) {
+	var exists bool
+	if err := p.control.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = $1)`, dbName).Scan(&exists); err != nil {
+		return false, fmt.Errorf("check database exists: %w", err)
+	}
+	return exists, nil
+}
+
+func (p *promoter) dropDatabase(ctx context.Context, dbName string) error {
+	query := fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", pgx.Identifier{dbName}.Sanitize())
+	if _, err := p.control.Exec(ctx, query); err != nil {
+		return fmt.Errorf("drop new database: %w", err)
+	}
+	return nil
+}
+
+func (p *promoter) tenantURL(dbName, schema string) string {
+	tenantURL, err := database.TenantURL(p.baseURL, dbName, schema)
+	if err != nil {
+		return p.baseURL // Fallback, though likely to fail
+	}
+	return tenantURL
+}
+
+func promotedSchema(schema string) string {
+	return schema + "_promoted"
+}
+
+func renameSchema(ctx context.Context, pool *pgxpool.Pool, from, to string) error {
+	query := fmt.Sprintf("ALTER SCHEMA %s RENAME TO %s", pgx.Identifier{from}.Sanitize(), pgx.Identifier{to}.Sanitize())
+	if _, err := pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("rename schema %s to %s: %w", from, to, err)
+	}
+	return nil
+}
+
+// copySchema copies every table of schema into the public schema of target,
+// parents before children, in one transaction, and checks that each table
+// received as many rows as were read. Rows the migrations seeded in target
+// are removed first.
+func copySchema(ctx context.Context, source, target *pgxpool.Pool, schema string) error {
+	tables, dependencies, err := readTables(ctx, source, schema)
+	if err != nil {
+		return err
+	}
+	order, err := copyOrder(tables, dependencies)
+	if err != nil {
+		return err
+	}
+
+	tx, err := target.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin copy: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := clearSeededRows(ctx, tx, order); err != nil {
+		return err
+	}
+	for _, table := range order {
+		copied, err := copyTable(ctx, source, tx, schema, table)
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", table, err)
+		}
+		log.Printf("Copied %d rows of %s", copied, table)
+	}
+	if err := resetSequences(ctx, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit copy: %w", err)
+	}
+	return nil
+}
+
+// readTables lists the tables of schema, except golang-migrate's own, and
+// the tables each one references.
+func readTables(ctx context.Context, pool *pgxpool.Pool, schema string) ([]string, map[string][]string, error) {
+	rows, err := pool.Query(ctx, `SELECT tablename FROM pg_tables WHERE schemaname = $1 AND tablename <> 'schema_migrations' ORDER BY tablename`, schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list tables: %w", err)
+	}
+	tables, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, nil, fmt.Errorf("list tables: %w", err)
+	}
+
+	query := `
+		SELECT child.relname, parent.relname
+		FROM pg_constraint con
+		JOIN pg_class child ON child.oid = con.conrelid
+		JOIN pg_class parent ON parent.oid = con.confrelid
+		JOIN pg_namespace n ON n.oid = child.relnamespace
+		WHERE con.contype = 'f' AND n.nspname = $1
+	`
+	rows, err = pool.Query(ctx, query, schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	dependencies := map[string][]string{}
+	for rows.Next() {
+		var child, parent string
+		if err := rows.Scan(&child, &parent); err != nil {
+			return nil, nil, fmt.Errorf("scan foreign key: %w", err)
+		}
+		dependencies[child] = append(dependencies[child], parent)
+	}
+
+	return tables, dependencies, rows.Err()
+}
+
+// copyOrder sorts tables so that every table comes after the tables it
+// references. Self references are fine, since foreign keys are checked at the
+// end of each COPY, but a cycle between tables cannot be copied in order.
+func copyOrder(tables []string, dependencies map[string][]string) ([]string, error) {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(tables))
+	order := make([]string, 0, len(tables))
+
+	var visit func(table string, path []string) error
+	visit = func(table string, path []string) error {
+		switch state[table] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("foreign key cycle: %s", strings.Join(append(path, table), " -> "))
+		}
+
+		state[table] = visiting
+		for _, parent := range dependencies[table] {
+			if parent == table || !slices.Contains(tables, parent) {
+				continue
+			}
+			if err := visit(parent, append(path, table)); err != nil {
+				return err
+			}
+		}
+		state[table] = done
+		order = append(order, table)
+		return nil
+	}
+
+	for _, table := range tables {
+		if err := visit(table, nil); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// clearSeededRows empties the tables the migrations filled, such as
+// permissions, roles and role_permissions. The source holds those rows as
+// well, possibly edited since, and its other tables reference them by ID, so
+// they are replaced rather than merged. Tables are emptied children first
+// with DELETE: TRUNCATE would also need every referencing table listed.
+func clearSeededRows(ctx context.Context, tx pgx.Tx, order []string) error {
+	for _, table := range slices.Backward(order) {
+		var seeded bool
+		query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s)", pgx.Identifier{table}.Sanitize())
+		if err := tx.QueryRow(ctx, query).Scan(&seeded); err != nil {
+			return fmt.Errorf("check seeded rows of %s: %w", table, err)
+		}
+		if !seeded {
+			continue
+		}
+		if _, err := tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s", pgx.Identifier{table}.Sanitize())); err != nil {
+			return fmt.Errorf("clear seeded rows of %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// copyTable streams the table from source to tx in binary COPY format,
+// naming the columns so that column order does not matter.
+func copyTable(ctx context.Context, source *pgxpool.Pool, tx pgx.Tx, schema, table string) (int64, error) {
+	rows, err := source.Query(ctx, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2 AND is_generated = 'NEVER'
+		ORDER BY ordinal_position
+	`, schema, table)
+	if err != nil {
+		return 0, fmt.Errorf("list columns: %w", err)
+	}
+	columns, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, fmt.Errorf("list columns: %w", err)
+	}
+	columnList := make([]string, len(columns))
+	for i, column := range columns {
+		columnList[i] = pgx.Identifier{column}.Sanitize()
+	}
+
+	conn, err := source.Acquire(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Release()
+
+	reader, writer := io.Pipe()
+	type copyResult struct {
+		rows int64
+		err  error
+	}
+	read := make(chan copyResult, 1)
+	go func() {
+		sql := fmt.Sprintf("COPY %s (%s) TO STDOUT (FORMAT binary)", pgx.Identifier{schema, table}.Sanitize(), strings.Join(columnList, ", "))
+		tag, err := conn.Conn().PgConn().CopyTo(ctx, writer, sql)
+		writer.CloseWithError(err)
+		read <- copyResult{rows: tag.RowsAffected(), err: err}
+	}()
+
+	sql := fmt.Sprintf("COPY %s (%s) FROM STDIN (FORMAT binary)", pgx.Identifier{table}.Sanitize(), strings.Join(columnList, ", "))
+	tag, writeErr := tx.Conn().PgConn().CopyFrom(ctx, reader, sql)
+	// Unblock the reader if writing stopped early.
+	reader.CloseWithError(io.ErrClosedPipe)
+	result := <-read
+
+	if result.err != nil {
+		return 0, fmt.Errorf("read rows: %w", result.err)
+	}
+	if writeErr != nil {
+		return 0, fmt.Errorf("write rows: %w", writeErr)
+	}
+	if tag.RowsAffected() != result.rows {
+		return 0, fmt.Errorf("read %d rows but wrote %d", result.rows, tag.RowsAffected())
+	}
+
+	return result.rows, nil
+}
+
+// resetSequences moves every sequence owned by a column past the copied values.
+func resetSequences(ctx context.Context, tx pgx.Tx) error {
+	query := `
+		SELECT seq.oid::regclass::text, tbl.oid::regclass::text, att.attname
+		FROM pg_depend dep
+		JOIN pg_class seq ON seq.oid = dep.objid AND seq.relkind = 'S'
+		JOIN pg_class tbl ON tbl.oid = dep.refobjid
+		JOIN pg_attribute att ON att.attrelid = tbl.oid AND att.attnum = dep.refobjsubid
+		JOIN pg_namespace n ON n.oid = tbl.relnamespace
+		WHERE dep.deptype IN ('a', 'i') AND n.nspname = 'public'
+	`
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("list sequences: %w", err)
+	}
+	type ownedSequence struct{ sequence, table, column string }
+	sequences, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (ownedSequence, error) {
+		var s ownedSequence
+		err := row.Scan(&s.sequence, &s.table, &s.column)
+		return s, err
+	})
+	if err != nil {
+		return fmt.Errorf("list sequences: %w", err)
+	}
+
+	for _, s := range sequences {
+		// regclass text is already quoted as needed.
+		sql := fmt.Sprintf("SELECT setval($1, COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)", pgx.Identifier{s.column}.Sanitize(), s.table)
+		if _, err := tx.Exec(ctx, sql, s.sequence); err != nil {
+			return fmt.Errorf("reset sequence %s: %w", s.sequence, err)
+		}
+	}
+	return nil
+}
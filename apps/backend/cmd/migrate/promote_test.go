@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bowerbird/internal/platform/database"
+	"github.com/bowerbird/internal/platform/database/databasetest"
+	"github.com/bowerbird/internal/platform/id"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestCopyOrderPutsParentsFirst(t *testing.T) {
+	tables := []string{"attachments", "emails", "inboxes", "users"}
+	dependencies := map[string][]string{
+		"attachments": {"emails"},
+		"emails":      {"inboxes", "emails"},
+		"inboxes":     {"users"},
+	}
+
+	order, err := copyOrder(tables, dependencies)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(order, []string{"users", "inboxes", "emails", "attachments"}) {
+		t.Fatalf("expected parents before children, got %v", order)
+	}
+}
+
+func TestCopyOrderRejectsCycles(t *testing.T) {
+	dependencies := map[string][]string{"a": {"b"}, "b": {"a"}}
+
+	if _, err := copyOrder([]string{"a", "b"}, dependencies); err == nil {
+		t.Fatal("expected a foreign key cycle to be rejected")
+	}
+}
+
+func TestPromoteCopiesAMigratedSchemaOverTheSeededDatabase(t *testing.T) {
+	ctx := context.Background()
+	admin := databasetest.AdminPool(t)
+
+	controlURL := databasetest.TenantURL(t, databasetest.CreateDatabase(t, admin), "")
+	if err := database.RunMigrations(controlURL, databasetest.MigrationsDir(t, "controlplane")); err != nil {
+		t.Fatalf("migrate control plane: %v", err)
+	}
+	control, err := pgxpool.New(ctx, controlURL)
+	if err != nil {
+		t.Fatalf("connect to control plane: %v", err)
+	}
+	defer control.Close()
+
+	// A tenant stored as a schema of a shared database, with the seeded RBAC
+	// rows edited and data that references them.
+	shared := databasetest.CreateDatabase(t, admin)
+	schema := "tenant_" + strings.ToLower(id.NewULID())
+	// Promote creates a database named after the schema.
+	t.Cleanup(func() {
+		if _, err := admin.Exec(context.Background(), fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", pgx.Identifier{schema}.Sanitize())); err != nil {
+			t.Errorf("drop promoted database: %v", err)
+		}
+	})
+	sharedPool, err := pgxpool.New(ctx, databasetest.TenantURL(t, shared, ""))
+	if err != nil {
+		t.Fatalf("connect to shared database: %v", err)
+	}
+	defer sharedPool.Close()
+	if _, err := sharedPool.Exec(ctx, fmt.Sprintf("CREATE SCHEMA %s", pgx.Identifier{schema}.Sanitize())); err != nil {
+		t.Fatalf("create tenant schema: %v", err)
+	}
+	schemaURL := databasetest.TenantURL(t, shared, schema)
+	if err := database.RunMigrations(schemaURL, databasetest.MigrationsDir(t, "tenant")); err != nil {
+		t.Fatalf("migrate tenant schema: %v", err)
+	}
+	source, err := pgxpool.New(ctx, schemaURL)
+	if err != nil {
+		t.Fatalf("connect to tenant schema: %v", err)
+	}
+	defer source.Close()
+	for _, statement := range []string{
+		`INSERT INTO users (id, email) VALUES ('01JW58TAT9M0N4R8M1P3Q6R9Z0', 'owner@acme.test')`,
+		`INSERT INTO user_roles (user_id, role_id) SELECT '01JW58TAT9M0N4R8M1P3Q6R9Z0', id FROM roles WHERE name = 'admin'`,
+		`UPDATE permissions SET description = 'Edited' WHERE code = 'users:read'`,
+		`DELETE FROM role_permissions WHERE permission_id = (SELECT id FROM permissions WHERE code = 'roles:write')`,
+	} {
+		if _, err := source.Exec(ctx, statement); err != nil {
+			t.Fatalf("seed tenant schema: %v", err)
+		}
+	}
+
+	tenantID := id.NewULID()
+	if _, err := control.Exec(ctx, `
+		INSERT INTO tenants (id, organization_name, slug, db_name, status, isolation_mode, shared_database)
+		VALUES ($1, 'Acme', $2, $3, 'active', 'schema', $4)
+	`, tenantID, "acme-"+strings.ToLower(tenantID), schema, shared); err != nil {
+		t.Fatalf("register tenant: %v", err)
+	}
+
+	p := &promoter{control: control, baseURL: controlURL, migrationsDir: databasetest.MigrationsDir(t, "tenant")}
+	if err := p.Promote(ctx, tenantID); err != nil {
+		t.Fatalf("promote: %v", err)
+	}
+
+	var mode, status string
+	if err := control.QueryRow(ctx, `SELECT isolation_mode, status FROM tenants WHERE id = $1`, tenantID).Scan(&mode, &status); err != nil {
+		t.Fatalf("read tenant: %v", err)
+	}
+	if mode != "database" || status != "active" {
+		t.Fatalf("expected an active tenant with a dedicated database, got %s and %s", mode, status)
+	}
+
+	target, err := pgxpool.New(ctx, databasetest.TenantURL(t, schema, ""))
+	if err != nil {
+		t.Fatalf("connect to promoted database: %v", err)
+	}
+	defer target.Close()
+	var description string
+	var adminPermissions, ownerRoles int
+	if err := target.QueryRow(ctx, `
+		SELECT
+			(SELECT description FROM permissions WHERE code = 'users:read'),
+			(SELECT COUNT(*) FROM role_permissions rp JOIN roles r ON r.id = rp.role_id WHERE r.name = 'admin'),
+			(SELECT COUNT(*) FROM user_roles WHERE user_id = '01JW58TAT9M0N4R8M1P3Q6R9Z0')
+	`).Scan(&description, &adminPermissions, &ownerRoles); err != nil {
+		t.Fatalf("read promoted database: %v", err)
+	}
+	var sourcePermissions int
+	if err := source.QueryRow(ctx, `SELECT COUNT(*) FROM role_permissions`).Scan(&sourcePermissions); err != nil {
+		t.Fatalf("read source permissions: %v", err)
+	}
+	if description != "Edited" || adminPermissions != sourcePermissions || ownerRoles != 1 {
+		t.Fatalf("expected the source rows to replace the seeds, got description %q, %d of %d admin permissions, %d owner roles", description, adminPermissions, sourcePermissions, ownerRoles)
+	}
+}
@@ -9,21 +9,17 @@ import (
 	idinfra "github.com/bowerbird/internal/identity/infrastructure"
 	organizationModule "github.com/bowerbird/internal/organization"
 	"github.com/bowerbird/internal/organization/application"
-	"github.com/bowerbird/internal/platform/config"
-	"github.com/bowerbird/internal/platform/database"
+	"github.com/bowerbird/internal/platform"
 )
 
 func main() {
 	ctx := context.Background()
-	cfg, err := config.Load(ctx)
+	platformModule, err := platform.NewModule(ctx)
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
-	}
-
-	pool, err := database.Connect(ctx, cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to build dependencies: %v", err)
 	}
+	cfg := platformModule.Config
+	pool := platformModule.ControlDB
 	defer pool.Close()
 
 	migrationsDir := os.Getenv("TENANT_MIGRATIONS_DIR")
@@ -34,12 +30,13 @@ func main() {
 		}
 	}
 
-	organizationApp := organizationModule.NewApplication(pool, cfg.DatabaseURL, migrationsDir)
+	// Without a job queue the tenant is provisioned before Execute returns.
+	organizationApp := organizationModule.NewApplication(pool, cfg, migrationsDir, platformModule.FilePurger, nil, platformModule.TenantRegistry, platformModule.FileStore, platformModule.EventBus)
 	orgUseCase := application.NewCreateOrganizationUseCaseFromCommand(organizationApp.Commands.CreateOrganization)
 
 	// We also need the user to exist in the Control Plane identity tables before we create the tenant.
 	// Because the AddMembership requires a foreign key to users.id
-	dbRegistry := database.NewRegistry(pool, cfg.DatabaseURL)
+	dbRegistry := platformModule.TenantRegistry
 	defer dbRegistry.CloseAll()
 	idRepo := idinfra.NewPostgresRepository(pool, dbRegistry)
 
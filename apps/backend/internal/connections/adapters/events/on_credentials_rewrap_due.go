@@ -0,0 +1,38 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	awsEvents "github.com/aws/aws-lambda-go/events"
+	"github.com/bowerbird/internal/connections/application/commands"
+	contractEvents "github.com/bowerbird/internal/contracts/events"
+)
+
+// OnCredentialsRewrapDue rewraps every tenant's connection credentials under
+// the active master key. The event carries no payload.
+type OnCredentialsRewrapDue struct {
+	command *commands.RewrapCredentialsCommand
+}
+
+func NewOnCredentialsRewrapDue(command *commands.RewrapCredentialsCommand) *OnCredentialsRewrapDue {
+	return &OnCredentialsRewrapDue{command: command}
+}
+
+func (h *OnCredentialsRewrapDue) DetailType() string {
+	return contractEvents.CredentialsRewrapDueDetailType
+}
+
+func (h *OnCredentialsRewrapDue) HandleEventBridge(ctx context.Context, _ awsEvents.CloudWatchEvent) error {
+	report, err := h.command.Execute(ctx)
+	slog.Info("Connection credentials rewrapped",
+		"tenants", report.Tenants,
+		"rewrapped", report.Rewrapped,
+		"unchanged", report.Unchanged,
+		"skipped", report.Skipped,
+		"failed", report.Failed,
+		"key_usage", report.KeyUsage,
+	)
+
+	return err
+}
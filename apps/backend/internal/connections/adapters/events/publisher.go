@@ -23,7 +23,9 @@ func NewPublisher(eventBus platformEvents.EventBus) *Publisher {
 	return &Publisher{eventBus: eventBus}
 }
 
-func (p *Publisher) PublishConnectionAdded(ctx context.Context, connection *domain.Connection) error {
+// PublishConnectionAdded announces a new connection; backfillSince, if set,
+// is the date its mail should be imported from.
+func (p *Publisher) PublishConnectionAdded(ctx context.Context, connection *domain.Connection, backfillSince string) error {
 	tenantSlug, _ := tenant.TenantIDFromContext(ctx)
 
 	event := contractEvents.ConnectionAdded{
@@ -33,6 +35,7 @@ func (p *Publisher) PublishConnectionAdded(ctx context.Context, connection *doma
 		ConnectionID:         connection.ID,
 		Provider:             connection.Provider,
 		ProviderAccountEmail: connection.ProviderAccountEmail,
+		BackfillSince:        backfillSince,
 	}
 
 	payload, err := contractEvents.MarshalConnectionAdded(event)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,6 +12,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bowerbird/internal/connections/application"
+	"github.com/bowerbird/internal/connections/application/commands"
+	"github.com/bowerbird/internal/connections/application/queries"
 	"github.com/bowerbird/internal/connections/domain"
 	"github.com/bowerbird/internal/platform/auth"
 	appErrors "github.com/bowerbird/internal/platform/errors"
@@ -34,11 +38,15 @@ type StateProtector interface {
 }
 
 type EventPublisher interface {
-	PublishConnectionAdded(ctx context.Context, connection *domain.Connection) error
+	PublishConnectionAdded(ctx context.Context, connection *domain.Connection, backfillSince string) error
 }
 
 type Controller struct {
 	repo         domain.Repository
+	listQuery    *queries.ListAccessibleConnectionsQuery
+	deleteCmd    *commands.DeleteConnectionCommand
+	updateCmd    *commands.UpdateConnectionCommand
+	reconnectCmd *commands.ReconnectConnectionCommand
 	credSetter   ConnectionCredentialsSetter
 	googleConfig *oauth2.Config
 	tokenGen     TokenValidator
@@ -47,10 +55,22 @@ type Controller struct {
 	frontendURL  string
 }
 
-func NewController(repo domain.Repository, credSetter ConnectionCredentialsSetter, googleConfig *oauth2.Config, tokenGen TokenValidator, stateProtect StateProtector, publisher EventPublisher, frontendURL string) *Controller {
+func NewController(repo domain.Repository, listQuery *queries.ListAccessibleConnectionsQuery, deleteCmd *commands.DeleteConnectionCommand, updateCmd *commands.UpdateConnectionCommand, reconnectCmd *commands.ReconnectConnectionCommand, credSetter ConnectionCredentialsSetter, googleConfig *oauth2.Config, tokenGen TokenValidator, stateProtect StateProtector, publisher EventPublisher, frontendURL string) *Controller {
 	if repo == nil {
 		panic("connections repository is required")
 	}
+	if listQuery == nil {
+		panic("list accessible connections query is required")
+	}
+	if deleteCmd == nil {
+		panic("delete connection command is required")
+	}
+	if updateCmd == nil {
+		panic("update connection command is required")
+	}
+	if reconnectCmd == nil {
+		panic("reconnect connection command is required")
+	}
 
 	if tokenGen == nil {
 		panic("token validator is required")
@@ -58,6 +78,10 @@ func NewController(repo domain.Repository, credSetter ConnectionCredentialsSette
 
 	return &Controller{
 		repo:         repo,
+		listQuery:    listQuery,
+		deleteCmd:    deleteCmd,
+		updateCmd:    updateCmd,
+		reconnectCmd: reconnectCmd,
 		credSetter:   credSetter,
 		googleConfig: googleConfig,
 		tokenGen:     tokenGen,
@@ -67,35 +91,116 @@ func NewController(repo domain.Repository, credSetter ConnectionCredentialsSette
 	}
 }
 
+// ListConnections returns the connections the caller may see: their own,
+// the shared ones, and every one for managers.
 func (c *Controller) ListConnections(w http.ResponseWriter, r *http.Request) error {
-	ctx := r.Context()
+	actor, err := actorFromRequest(r)
+	if err != nil {
+		return err
+	}
 
-	connections, err := c.repo.ListAll(ctx)
+	connections, err := c.listQuery.Execute(r.Context(), actor, domain.AccessView)
 	if err != nil {
 		return err
 	}
 
 	response := make([]connectionResponse, 0, len(connections))
 	for _, connection := range connections {
-		response = append(response, newConnectionResponse(connection))
+		response = append(response, newConnectionInfoResponse(connection))
 	}
 
 	return api.Success(w, http.StatusOK, map[string]interface{}{"data": response})
 }
 
 func (c *Controller) DeleteConnection(w http.ResponseWriter, r *http.Request) error {
-	ctx := r.Context()
+	actor, err := actorFromRequest(r)
+	if err != nil {
+		return err
+	}
+
 	connectionID := r.PathValue("id")
 	if connectionID == "" {
 		return appErrors.New(appErrors.CodeValidation, "connection id is required")
 	}
 
-	err := c.repo.Delete(ctx, connectionID)
+	if err := c.deleteCmd.ExecuteAs(r.Context(), connectionID, actor); err != nil {
+		return mapConnectionError(err, "failed to delete connection")
+	}
+
+	return api.Success(w, http.StatusNoContent, nil)
+}
+
+// UpdateConnection changes the sharing policy or pauses and resumes syncing.
+func (c *Controller) UpdateConnection(w http.ResponseWriter, r *http.Request) error {
+	actor, err := actorFromRequest(r)
 	if err != nil {
 		return err
 	}
 
-	return api.Success(w, http.StatusNoContent, nil)
+	var req updateConnectionRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	if err := req.Validate(); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	connection, err := c.updateCmd.Execute(r.Context(), commands.UpdateConnectionInput{
+		ConnectionID:   r.PathValue("id"),
+		Actor:          actor,
+		SharingPolicy:  req.SharingPolicy,
+		Paused:         req.paused(),
+		IngestionRules: req.IngestionRules,
+		WriteBack:      req.WriteBack,
+	})
+	if err != nil {
+		return mapConnectionError(err, "failed to update connection")
+	}
+
+	return api.Success(w, http.StatusOK, map[string]interface{}{"data": newConnectionResponse(connection)})
+}
+
+// ReconnectConnection starts the Google flow again for a connection that
+// lost its grant; the callback replaces its credentials in place.
+func (c *Controller) ReconnectConnection(w http.ResponseWriter, r *http.Request) error {
+	if c.googleConfig == nil {
+		return appErrors.New(appErrors.CodeInternal, "google integration not configured")
+	}
+
+	actor, err := actorFromRequest(r)
+	if err != nil {
+		return err
+	}
+	if actor.UserID == "" {
+		return appErrors.New(appErrors.CodeForbidden, "api keys cannot reconnect connections")
+	}
+
+	tenantID, err := tenant.TenantIDFromContext(r.Context())
+	if err != nil {
+		return appErrors.New(appErrors.CodeValidation, "missing tenant context")
+	}
+
+	connectionID := r.PathValue("id")
+	if err := c.reconnectCmd.Authorize(r.Context(), connectionID, actor); err != nil {
+		return mapConnectionError(err, "failed to reconnect connection")
+	}
+
+	statePayload := fmt.Sprintf("%s|%s|%d|%s", actor.UserID, tenantID, time.Now().Unix(), connectionID)
+	encryptedState, err := c.stateProtect.Encrypt([]byte(statePayload))
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to secure state parameter")
+	}
+	opaqueState := base64.URLEncoding.EncodeToString(encryptedState)
+
+	slog.Info("Starting Google reconnection flow", "user_id", actor.UserID, "tenant_id", tenantID, "connection_id", connectionID)
+
+	url := c.googleConfig.AuthCodeURL(opaqueState, oauth2.AccessTypeOffline, oauth2.ApprovalForce)
+	return api.Success(w, http.StatusOK, map[string]interface{}{
+		"data": map[string]string{"auth_url": url},
+	})
 }
 
 func (c *Controller) GoogleConnect(w http.ResponseWriter, r *http.Request) error {
@@ -120,7 +225,19 @@ func (c *Controller) GoogleConnect(w http.ResponseWriter, r *http.Request) error
 		return appErrors.New(appErrors.CodeValidation, "missing tenant context")
 	}
 
+	// backfill_since (YYYY-MM-DD) asks for the mail received since that date
+	// to be imported once the mailbox is connected.
+	backfillSince := r.URL.Query().Get("backfill_since")
+	if backfillSince != "" {
+		if _, err := time.Parse(time.DateOnly, backfillSince); err != nil {
+			return appErrors.New(appErrors.CodeValidation, "backfill_since must be a date (YYYY-MM-DD)")
+		}
+	}
+
 	statePayload := fmt.Sprintf("%s|%s|%d", claims.Subject, tenantID, time.Now().Unix())
+	if backfillSince != "" {
+		statePayload += "||" + backfillSince
+	}
 	encryptedState, err := c.stateProtect.Encrypt([]byte(statePayload))
 	if err != nil {
 		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to secure state parameter")
@@ -171,8 +288,10 @@ func (c *Controller) GoogleCallback(w http.ResponseWriter, r *http.Request) erro
 		return redirectOnError("", "failed to decrypt state")
 	}
 
+	// A fourth part names the connection being reconnected; a fifth carries
+	// the backfill date picked for a new connection.
 	stateParts := strings.Split(string(decryptedState), "|")
-	if len(stateParts) != 3 {
+	if len(stateParts) < 3 || len(stateParts) > 5 {
 		return redirectOnError("", "invalid state format")
 	}
 
@@ -200,6 +319,16 @@ func (c *Controller) GoogleCallback(w http.ResponseWriter, r *http.Request) erro
 		return redirectOnError(tenantID, fmt.Sprintf("exchange token failed: %v", err))
 	}
 
+	// Users can untick scopes on the consent screen; a grant without them
+	// would only fail later, during a sync.
+	grantedScopes := c.googleConfig.Scopes
+	if scope, ok := token.Extra("scope").(string); ok && scope != "" {
+		grantedScopes = strings.Fields(scope)
+	}
+	if missing := domain.MissingScopes(c.googleConfig.Scopes, grantedScopes); len(missing) > 0 {
+		return redirectOnError(tenantID, fmt.Sprintf("missing granted scopes: %s", strings.Join(missing, " ")))
+	}
+
 	client := c.googleConfig.Client(ctx, token)
 	resp, err := client.Get("https://www.googleapis.com/oauth2/v2/userinfo")
 	if err != nil {
@@ -218,13 +347,29 @@ func (c *Controller) GoogleCallback(w http.ResponseWriter, r *http.Request) erro
 
 	tokenBytes, _ := json.Marshal(token)
 
+	if len(stateParts) >= 4 && stateParts[3] != "" {
+		connection, err := c.reconnectCmd.Execute(tenant.WithTenantID(ctx, tenantID), commands.ReconnectConnectionInput{
+			ConnectionID:         stateParts[3],
+			Actor:                domain.Actor{UserID: userID},
+			ProviderAccountEmail: userInfo.Email,
+			Credentials:          tokenBytes,
+			GrantedScopes:        grantedScopes,
+		})
+		if err != nil {
+			return redirectOnError(tenantID, fmt.Sprintf("reconnect connection failed: %v", err))
+		}
+
+		slog.Info("Google connection reconnected successfully", "connection_id", connection.ID, "user_id", userID, "tenant_id", tenantID)
+		return c.redirectToConnections(w, r, tenantID)
+	}
+
 	connection := &domain.Connection{
 		ID:                   id.NewULID(),
 		OwnerUserID:          userID,
 		Provider:             "gmail",
 		ProviderAccountEmail: userInfo.Email,
 		Status:               domain.ConnectionStatusActive,
-		GrantedScopes:        c.googleConfig.Scopes,
+		GrantedScopes:        grantedScopes,
 		SharingPolicy:        domain.SharingPolicyPrivate,
 		CreatedAt:            time.Now().UTC(),
 		UpdatedAt:            time.Now().UTC(),
@@ -243,13 +388,21 @@ func (c *Controller) GoogleCallback(w http.ResponseWriter, r *http.Request) erro
 	slog.Info("Google connection saved successfully", "connection_id", connection.ID, "email", userInfo.Email, "user_id", userID, "tenant_id", tenantID)
 
 	if c.publisher != nil {
-		if err := c.publisher.PublishConnectionAdded(ctx, connection); err != nil {
+		backfillSince := ""
+		if len(stateParts) == 5 {
+			backfillSince = stateParts[4]
+		}
+		if err := c.publisher.PublishConnectionAdded(ctx, connection, backfillSince); err != nil {
 			slog.Error("failed to publish ConnectionAdded event", "error", err, "connection_id", connection.ID)
 		}
 	}
 
 	slog.Info("ConnectionAdded event published successfully", "connection_id", connection.ID)
 
+	return c.redirectToConnections(w, r, tenantID)
+}
+
+func (c *Controller) redirectToConnections(w http.ResponseWriter, r *http.Request, tenantID string) error {
 	frontendURL := c.frontendURL
 	if frontendURL == "" {
 		frontendURL = "https://app.bowerbird.dev"
@@ -261,3 +414,29 @@ func (c *Controller) GoogleCallback(w http.ResponseWriter, r *http.Request) erro
 	http.Redirect(w, r, frontendURL+path, http.StatusTemporaryRedirect)
 	return nil
 }
+
+func actorFromRequest(r *http.Request) (domain.Actor, error) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return domain.Actor{}, appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+
+	return application.ActorFromClaims(claims), nil
+}
+
+func mapConnectionError(err error, fallback string) error {
+	switch {
+	case errors.Is(err, domain.ErrConnectionNotFound):
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "connection not found")
+	case errors.Is(err, domain.ErrConnectionForbidden):
+		return appErrors.Wrap(err, appErrors.CodeForbidden, "not allowed to do this with the connection")
+	case errors.Is(err, domain.ErrInvalidSharingPolicy), errors.Is(err, domain.ErrInvalidIngestionRules), errors.Is(err, domain.ErrInvalidWriteBackSettings):
+		return appErrors.Wrap(err, appErrors.CodeValidation, err.Error())
+	case errors.Is(err, domain.ErrInvalidStatusTransition):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "the connection must be reconnected first")
+	case errors.Is(err, domain.ErrReconnectNotRequired):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "the connection does not need to be reconnected")
+	}
+
+	return appErrors.Wrap(err, appErrors.CodeInternal, fallback)
+}
@@ -0,0 +1,34 @@
+package v1
+
+import (
+	"fmt"
+
+	"github.com/bowerbird/internal/connections/domain"
+)
+
+type updateConnectionRequest struct {
+	SharingPolicy  *string                   `json:"sharing_policy"`
+	Status         *string                   `json:"status"`
+	IngestionRules *domain.IngestionRules    `json:"ingestion_rules"`
+	WriteBack      *domain.WriteBackSettings `json:"write_back"`
+}
+
+func (r updateConnectionRequest) Validate() error {
+	if r.SharingPolicy == nil && r.Status == nil && r.IngestionRules == nil && r.WriteBack == nil {
+		return fmt.Errorf("sharing_policy, status, ingestion_rules or write_back is required")
+	}
+	if r.Status != nil && *r.Status != domain.ConnectionStatusActive && *r.Status != domain.ConnectionStatusPaused {
+		return fmt.Errorf("status must be %q or %q", domain.ConnectionStatusActive, domain.ConnectionStatusPaused)
+	}
+
+	return nil
+}
+
+// paused maps the requested status onto a pause or resume.
+func (r updateConnectionRequest) paused() *bool {
+	if r.Status == nil {
+		return nil
+	}
+	paused := *r.Status == domain.ConnectionStatusPaused
+	return &paused
+}
@@ -1,13 +1,19 @@
 package v1
 
-import "github.com/bowerbird/internal/connections/domain"
+import (
+	"github.com/bowerbird/internal/connections/application"
+	"github.com/bowerbird/internal/connections/domain"
+)
 
 type connectionResponse struct {
-	ID                   string `json:"id"`
-	Provider             string `json:"provider"`
-	ProviderAccountEmail string `json:"provider_account_email"`
-	Status               string `json:"status"`
-	SharingPolicy        string `json:"sharing_policy"`
+	ID                   string                   `json:"id"`
+	Provider             string                   `json:"provider"`
+	ProviderAccountEmail string                   `json:"provider_account_email"`
+	Status               string                   `json:"status"`
+	ReconnectReason      string                   `json:"requires_reconnect_reason,omitempty"`
+	SharingPolicy        string                   `json:"sharing_policy"`
+	IngestionRules       domain.IngestionRules    `json:"ingestion_rules"`
+	WriteBack            domain.WriteBackSettings `json:"write_back"`
 }
 
 func newConnectionResponse(connection *domain.Connection) connectionResponse {
@@ -16,6 +22,22 @@ func newConnectionResponse(connection *domain.Connection) connectionResponse {
 		Provider:             connection.Provider,
 		ProviderAccountEmail: connection.ProviderAccountEmail,
 		Status:               connection.Status,
+		ReconnectReason:      string(connection.ReconnectReason),
 		SharingPolicy:        connection.SharingPolicy,
+		IngestionRules:       connection.IngestionRules,
+		WriteBack:            connection.WriteBack,
+	}
+}
+
+func newConnectionInfoResponse(connection application.ConnectionInfo) connectionResponse {
+	return connectionResponse{
+		ID:                   connection.ID,
+		Provider:             connection.Provider,
+		ProviderAccountEmail: connection.ProviderAccountEmail,
+		Status:               connection.Status,
+		ReconnectReason:      connection.ReconnectReason,
+		SharingPolicy:        connection.SharingPolicy,
+		IngestionRules:       connection.IngestionRules,
+		WriteBack:            connection.WriteBack,
 	}
 }
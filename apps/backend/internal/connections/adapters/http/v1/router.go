@@ -24,4 +24,7 @@ func (h *Router) Register(mux *http.ServeMux, cfg config.Config, authMiddleware
 	mux.Handle("GET /api/v1/connections/google", authMiddleware(api.Wrap(h.controller.GoogleConnect, cfg)))
 	mux.Handle("GET /api/v1/connections/google/callback", api.Wrap(h.controller.GoogleCallback, cfg))
 	mux.Handle("DELETE /api/v1/connections/{id}", authMiddleware(api.Wrap(h.controller.DeleteConnection, cfg)))
+	// The commands check ownership and the connections:manage permission.
+	mux.Handle("PATCH /api/v1/connections/{id}", authMiddleware(api.Wrap(h.controller.UpdateConnection, cfg)))
+	mux.Handle("POST /api/v1/connections/{id}/reconnect", authMiddleware(api.Wrap(h.controller.ReconnectConnection, cfg)))
 }
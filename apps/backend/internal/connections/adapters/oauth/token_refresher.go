@@ -0,0 +1,71 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// TokenRefresher refreshes stored oauth2.Token credentials at the provider.
+type TokenRefresher struct {
+	client         *http.Client
+	clientID       string
+	clientSecret   string
+	googleEndpoint oauth2.Endpoint
+}
+
+func NewTokenRefresher(clientID, clientSecret string, client *http.Client) *TokenRefresher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &TokenRefresher{
+		client:         client,
+		clientID:       clientID,
+		clientSecret:   clientSecret,
+		googleEndpoint: google.Endpoint,
+	}
+}
+
+// Refresh exchanges the refresh token for a new access token. An
+// invalid_grant answer means the grant was revoked or expired and is
+// reported as ports.ErrGrantRevoked.
+func (r *TokenRefresher) Refresh(ctx context.Context, provider string, token *oauth2.Token) (ports.RefreshedToken, error) {
+	if provider != "gmail" {
+		return ports.RefreshedToken{}, ErrUnsupportedProvider
+	}
+	if r.clientID == "" || r.clientSecret == "" {
+		return ports.RefreshedToken{}, errors.New("google oauth client is not configured")
+	}
+
+	config := &oauth2.Config{
+		ClientID:     r.clientID,
+		ClientSecret: r.clientSecret,
+		Endpoint:     r.googleEndpoint,
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, r.client)
+
+	// Only the refresh token is passed so the source always hits the
+	// provider instead of reusing a still valid access token.
+	fresh, err := config.TokenSource(ctx, &oauth2.Token{RefreshToken: token.RefreshToken}).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			return ports.RefreshedToken{}, fmt.Errorf("%w: %s", ports.ErrGrantRevoked, retrieveErr.ErrorDescription)
+		}
+		return ports.RefreshedToken{}, err
+	}
+
+	var scopes []string
+	if scope, ok := fresh.Extra("scope").(string); ok {
+		scopes = strings.Fields(scope)
+	}
+
+	return ports.RefreshedToken{Token: fresh, Scopes: scopes}, nil
+}
@@ -0,0 +1,72 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"golang.org/x/oauth2"
+)
+
+func newTestRefresher(handler http.HandlerFunc) (*TokenRefresher, func()) {
+	server := httptest.NewServer(handler)
+	refresher := NewTokenRefresher("client-id", "client-secret", server.Client())
+	refresher.googleEndpoint = oauth2.Endpoint{TokenURL: server.URL, AuthStyle: oauth2.AuthStyleInParams}
+	return refresher, server.Close
+}
+
+func TestRefreshReturnsTokenAndGrantedScopes(t *testing.T) {
+	var sent string
+	refresher, closeServer := newTestRefresher(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.FormValue("refresh_token")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"fresh","token_type":"Bearer","expires_in":3600,"scope":"email https://www.googleapis.com/auth/gmail.modify"}`))
+	})
+	defer closeServer()
+
+	refreshed, err := refresher.Refresh(context.Background(), "gmail", &oauth2.Token{AccessToken: "stale", RefreshToken: "refresh"})
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if sent != "refresh" {
+		t.Fatalf("expected the refresh token to be sent, got %q", sent)
+	}
+	if refreshed.Token.AccessToken != "fresh" || refreshed.Token.Expiry.IsZero() {
+		t.Fatalf("unexpected token %+v", refreshed.Token)
+	}
+	if len(refreshed.Scopes) != 2 || refreshed.Scopes[1] != "https://www.googleapis.com/auth/gmail.modify" {
+		t.Fatalf("unexpected scopes %v", refreshed.Scopes)
+	}
+}
+
+func TestRefreshReportsRevokedGrant(t *testing.T) {
+	refresher, closeServer := newTestRefresher(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"Token has been expired or revoked."}`))
+	})
+	defer closeServer()
+
+	_, err := refresher.Refresh(context.Background(), "gmail", &oauth2.Token{RefreshToken: "gone"})
+	if !errors.Is(err, ports.ErrGrantRevoked) {
+		t.Fatalf("expected ErrGrantRevoked, got %v", err)
+	}
+}
+
+func TestRefreshKeepsOtherFailuresTransient(t *testing.T) {
+	refresher, closeServer := newTestRefresher(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer closeServer()
+
+	_, err := refresher.Refresh(context.Background(), "gmail", &oauth2.Token{RefreshToken: "refresh"})
+	if err == nil || errors.Is(err, ports.ErrGrantRevoked) {
+		t.Fatalf("expected a transient error, got %v", err)
+	}
+	if _, err := refresher.Refresh(context.Background(), "outlook", &oauth2.Token{}); !errors.Is(err, ErrUnsupportedProvider) {
+		t.Fatalf("expected ErrUnsupportedProvider, got %v", err)
+	}
+}
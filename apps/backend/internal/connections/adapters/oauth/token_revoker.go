@@ -0,0 +1,77 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+const googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+var ErrUnsupportedProvider = errors.New("oauth provider is not supported")
+
+// TokenRevoker revokes stored oauth2.Token credentials at the provider.
+type TokenRevoker struct {
+	client    *http.Client
+	googleURL string
+}
+
+func NewTokenRevoker(client *http.Client) *TokenRevoker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &TokenRevoker{client: client, googleURL: googleRevokeURL}
+}
+
+// Revoke prefers the refresh token: revoking it also invalidates the access
+// tokens issued from it. A grant the provider no longer knows counts as
+// revoked.
+func (r *TokenRevoker) Revoke(ctx context.Context, provider string, credentials []byte) error {
+	if provider != "gmail" {
+		return ErrUnsupportedProvider
+	}
+
+	var token oauth2.Token
+	if err := json.Unmarshal(credentials, &token); err != nil {
+		return fmt.Errorf("decode credentials: %w", err)
+	}
+	value := token.RefreshToken
+	if value == "" {
+		value = token.AccessToken
+	}
+	if value == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.googleURL, strings.NewReader(url.Values{"token": {value}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("revoke token: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &payload) == nil && payload.Error == "invalid_token" {
+		return nil
+	}
+	return fmt.Errorf("revoke token: provider returned %d", res.StatusCode)
+}
@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRevokeSendsRefreshToken(t *testing.T) {
+	var revoked string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		revoked = r.FormValue("token")
+	}))
+	defer server.Close()
+
+	revoker := NewTokenRevoker(server.Client())
+	revoker.googleURL = server.URL
+
+	err := revoker.Revoke(context.Background(), "gmail", []byte(`{"access_token":"access","refresh_token":"refresh"}`))
+	if err != nil {
+		t.Fatalf("revoke failed: %v", err)
+	}
+	if revoked != "refresh" {
+		t.Fatalf("expected the refresh token to be revoked, got %q", revoked)
+	}
+}
+
+func TestRevokeTreatsUnknownGrantAsRevoked(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid_token","error_description":"Token expired or revoked"}`))
+	}))
+	defer server.Close()
+
+	revoker := NewTokenRevoker(server.Client())
+	revoker.googleURL = server.URL
+
+	if err := revoker.Revoke(context.Background(), "gmail", []byte(`{"refresh_token":"gone"}`)); err != nil {
+		t.Fatalf("expected an unknown grant to count as revoked, got %v", err)
+	}
+}
+
+func TestRevokeReportsProviderFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	revoker := NewTokenRevoker(server.Client())
+	revoker.googleURL = server.URL
+
+	if err := revoker.Revoke(context.Background(), "gmail", []byte(`{"refresh_token":"refresh"}`)); err == nil {
+		t.Fatal("expected an error")
+	}
+	if err := revoker.Revoke(context.Background(), "outlook", []byte(`{}`)); !errors.Is(err, ErrUnsupportedProvider) {
+		t.Fatalf("expected ErrUnsupportedProvider, got %v", err)
+	}
+}
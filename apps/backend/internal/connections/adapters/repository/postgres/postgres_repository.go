@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/bowerbird/internal/connections/domain"
 	"github.com/bowerbird/internal/platform/database"
@@ -26,13 +27,13 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Co
 	}
 
 	query := `
-		SELECT id, owner_user_id, provider, email_address, status, encrypted_credentials, granted_scopes, sharing_policy, raw_data, created_at, updated_at
+		SELECT id, owner_user_id, provider, email_address, status, requires_reconnect_reason, encrypted_credentials, granted_scopes, sharing_policy, ingestion_rules, write_back, raw_data, created_at, updated_at
 		FROM connections
 		WHERE id = $1
 	`
 	var c domain.Connection
-	var rawData, grantedScopes []byte
-	var ownerID *string
+	var rawData, grantedScopes, ingestionRules, writeBack []byte
+	var ownerID, reconnectReason *string
 
 	err = conn.QueryRow(ctx, query, id).Scan(
 		&c.ID,
@@ -40,9 +41,12 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Co
 		&c.Provider,
 		&c.ProviderAccountEmail,
 		&c.Status,
+		&reconnectReason,
 		&c.EncryptedCredentials,
 		&grantedScopes,
 		&c.SharingPolicy,
+		&ingestionRules,
+		&writeBack,
 		&rawData,
 		&c.CreatedAt,
 		&c.UpdatedAt,
@@ -57,6 +61,9 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Co
 	if ownerID != nil {
 		c.OwnerUserID = *ownerID
 	}
+	if reconnectReason != nil {
+		c.ReconnectReason = domain.ReconnectReason(*reconnectReason)
+	}
 	c.RawData = rawData
 
 	if len(grantedScopes) > 0 {
@@ -64,6 +71,16 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id string) (*domain.Co
 			return nil, fmt.Errorf("unmarshal granted scopes: %w", err)
 		}
 	}
+	if len(ingestionRules) > 0 {
+		if err := json.Unmarshal(ingestionRules, &c.IngestionRules); err != nil {
+			return nil, fmt.Errorf("unmarshal ingestion rules: %w", err)
+		}
+	}
+	if len(writeBack) > 0 {
+		if err := json.Unmarshal(writeBack, &c.WriteBack); err != nil {
+			return nil, fmt.Errorf("unmarshal write-back settings: %w", err)
+		}
+	}
 
 	return &c, nil
 }
@@ -75,7 +92,7 @@ func (r *PostgresRepository) ListAll(ctx context.Context) ([]*domain.Connection,
 	}
 
 	query := `
-		SELECT id, owner_user_id, provider, email_address, status, encrypted_credentials, granted_scopes, sharing_policy, raw_data, created_at, updated_at
+		SELECT id, owner_user_id, provider, email_address, status, requires_reconnect_reason, encrypted_credentials, granted_scopes, sharing_policy, ingestion_rules, write_back, raw_data, created_at, updated_at
 		FROM connections
 	`
 	rows, err := conn.Query(ctx, query)
@@ -98,7 +115,7 @@ func (r *PostgresRepository) listByStatus(ctx context.Context, status string) ([
 	}
 
 	query := `
-		SELECT id, owner_user_id, provider, email_address, status, encrypted_credentials, granted_scopes, sharing_policy, raw_data, created_at, updated_at
+		SELECT id, owner_user_id, provider, email_address, status, requires_reconnect_reason, encrypted_credentials, granted_scopes, sharing_policy, ingestion_rules, write_back, raw_data, created_at, updated_at
 		FROM connections
 		WHERE status = $1
 	`
@@ -118,7 +135,7 @@ func (r *PostgresRepository) ListByOwner(ctx context.Context, ownerUserID string
 	}
 
 	query := `
-		SELECT id, owner_user_id, provider, email_address, status, encrypted_credentials, granted_scopes, sharing_policy, raw_data, created_at, updated_at
+		SELECT id, owner_user_id, provider, email_address, status, requires_reconnect_reason, encrypted_credentials, granted_scopes, sharing_policy, ingestion_rules, write_back, raw_data, created_at, updated_at
 		FROM connections
 		WHERE owner_user_id = $1
 	`
@@ -135,8 +152,8 @@ func scanConnections(rows pgx.Rows) ([]*domain.Connection, error) {
 	var connections []*domain.Connection
 	for rows.Next() {
 		var c domain.Connection
-		var rawData, grantedScopes []byte
-		var ownerID *string
+		var rawData, grantedScopes, ingestionRules, writeBack []byte
+		var ownerID, reconnectReason *string
 
 		err := rows.Scan(
 			&c.ID,
@@ -144,9 +161,12 @@ func scanConnections(rows pgx.Rows) ([]*domain.Connection, error) {
 			&c.Provider,
 			&c.ProviderAccountEmail,
 			&c.Status,
+			&reconnectReason,
 			&c.EncryptedCredentials,
 			&grantedScopes,
 			&c.SharingPolicy,
+			&ingestionRules,
+			&writeBack,
 			&rawData,
 			&c.CreatedAt,
 			&c.UpdatedAt,
@@ -158,6 +178,9 @@ func scanConnections(rows pgx.Rows) ([]*domain.Connection, error) {
 		if ownerID != nil {
 			c.OwnerUserID = *ownerID
 		}
+		if reconnectReason != nil {
+			c.ReconnectReason = domain.ReconnectReason(*reconnectReason)
+		}
 		c.RawData = rawData
 
 		if len(grantedScopes) > 0 {
@@ -165,6 +188,16 @@ func scanConnections(rows pgx.Rows) ([]*domain.Connection, error) {
 				return nil, fmt.Errorf("unmarshal granted scopes: %w", err)
 			}
 		}
+		if len(ingestionRules) > 0 {
+			if err := json.Unmarshal(ingestionRules, &c.IngestionRules); err != nil {
+				return nil, fmt.Errorf("unmarshal ingestion rules: %w", err)
+			}
+		}
+		if len(writeBack) > 0 {
+			if err := json.Unmarshal(writeBack, &c.WriteBack); err != nil {
+				return nil, fmt.Errorf("unmarshal write-back settings: %w", err)
+			}
+		}
 
 		connections = append(connections, &c)
 	}
@@ -190,24 +223,43 @@ func (r *PostgresRepository) Upsert(ctx context.Context, c *domain.Connection) e
 		ownerID = &c.OwnerUserID
 	}
 
+	ingestionRulesJSON, err := json.Marshal(c.IngestionRules)
+	if err != nil {
+		return fmt.Errorf("marshal ingestion rules: %w", err)
+	}
+
+	writeBackJSON, err := json.Marshal(c.WriteBack)
+	if err != nil {
+		return fmt.Errorf("marshal write-back settings: %w", err)
+	}
+
 	rawData := c.RawData
 	if len(rawData) == 0 {
 		rawData = []byte("{}")
 	}
 
+	var reconnectReason *string
+	if c.ReconnectReason != "" {
+		reason := string(c.ReconnectReason)
+		reconnectReason = &reason
+	}
+
 	query := `
 		INSERT INTO connections (
-			id, owner_user_id, provider, email_address, status, encrypted_credentials, granted_scopes, sharing_policy, raw_data, created_at, updated_at
+			id, owner_user_id, provider, email_address, status, encrypted_credentials, granted_scopes, sharing_policy, raw_data, created_at, updated_at, requires_reconnect_reason, ingestion_rules, write_back
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		) ON CONFLICT (provider, email_address) DO UPDATE SET
 			owner_user_id = EXCLUDED.owner_user_id,
 			provider = EXCLUDED.provider,
 			email_address = EXCLUDED.email_address,
 			status = EXCLUDED.status,
+			requires_reconnect_reason = EXCLUDED.requires_reconnect_reason,
 			encrypted_credentials = EXCLUDED.encrypted_credentials,
 			granted_scopes = EXCLUDED.granted_scopes,
 			sharing_policy = EXCLUDED.sharing_policy,
+			ingestion_rules = EXCLUDED.ingestion_rules,
+			write_back = EXCLUDED.write_back,
 			raw_data = EXCLUDED.raw_data,
 			updated_at = EXCLUDED.updated_at
 		RETURNING id
@@ -225,6 +277,9 @@ func (r *PostgresRepository) Upsert(ctx context.Context, c *domain.Connection) e
 		rawData,
 		c.CreatedAt,
 		c.UpdatedAt,
+		reconnectReason,
+		ingestionRulesJSON,
+		writeBackJSON,
 	).Scan(&c.ID)
 	if err != nil {
 		return fmt.Errorf("upsert connection: %w", err)
@@ -233,6 +288,112 @@ func (r *PostgresRepository) Upsert(ctx context.Context, c *domain.Connection) e
 	return nil
 }
 
+// UpdateSettings writes only the settings that changed. The credentials are
+// never written back, so a token refreshed or rewrapped meanwhile is kept.
+func (r *PostgresRepository) UpdateSettings(ctx context.Context, id string, settings domain.ConnectionSettings) error {
+	conn, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return err
+	}
+
+	args := []any{id, settings.UpdatedAt}
+	set := "updated_at = $2"
+	column := func(name string, value any) {
+		args = append(args, value)
+		set += fmt.Sprintf(", %s = $%d", name, len(args))
+	}
+	if settings.SharingPolicy != nil {
+		column("sharing_policy", *settings.SharingPolicy)
+	}
+	if settings.Status != nil {
+		column("status", *settings.Status)
+	}
+	if settings.IngestionRules != nil {
+		rules, err := json.Marshal(settings.IngestionRules)
+		if err != nil {
+			return fmt.Errorf("marshal ingestion rules: %w", err)
+		}
+		column("ingestion_rules", rules)
+	}
+	if settings.WriteBack != nil {
+		writeBack, err := json.Marshal(settings.WriteBack)
+		if err != nil {
+			return fmt.Errorf("marshal write-back settings: %w", err)
+		}
+		column("write_back", writeBack)
+	}
+
+	tag, err := conn.Exec(ctx, `UPDATE connections SET `+set+` WHERE id = $1`, args...)
+	if err != nil {
+		return fmt.Errorf("update connection settings: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrConnectionNotFound
+	}
+
+	return nil
+}
+
+// UpdateGrant stores the grant of a reconnected connection and activates
+// it, only while it still requires reconnecting. Its settings are left as
+// stored.
+func (r *PostgresRepository) UpdateGrant(ctx context.Context, c *domain.Connection) (bool, error) {
+	conn, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	grantedScopesJSON, err := json.Marshal(c.GrantedScopes)
+	if err != nil {
+		return false, fmt.Errorf("marshal granted scopes: %w", err)
+	}
+
+	query := `
+		UPDATE connections
+		SET encrypted_credentials = $2, granted_scopes = $3, status = $4, requires_reconnect_reason = NULL, updated_at = $5
+		WHERE id = $1 AND status = $6
+	`
+	tag, err := conn.Exec(ctx, query, c.ID, c.EncryptedCredentials, grantedScopesJSON, c.Status, c.UpdatedAt, domain.ConnectionStatusRequiresReconnect)
+	if err != nil {
+		return false, fmt.Errorf("update connection grant: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// UpdateCredentials only replaces the stored credentials, so a token
+// refreshed mid-sync does not overwrite a concurrent status change.
+func (r *PostgresRepository) UpdateCredentials(ctx context.Context, id string, encryptedCredentials []byte, at time.Time) error {
+	conn, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE connections SET encrypted_credentials = $2, updated_at = $3 WHERE id = $1`
+	if _, err := conn.Exec(ctx, query, id, encryptedCredentials, at); err != nil {
+		return fmt.Errorf("update connection credentials: %w", err)
+	}
+
+	return nil
+}
+
+// ReplaceCredentials swaps the credentials only while they still hold the
+// current ciphertext, so a token refreshed meanwhile is never overwritten.
+func (r *PostgresRepository) ReplaceCredentials(ctx context.Context, id string, current, replacement []byte) (bool, error) {
+	conn, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	query := `UPDATE connections SET encrypted_credentials = $3 WHERE id = $1 AND encrypted_credentials = $2`
+	tag, err := conn.Exec(ctx, query, id, current, replacement)
+	if err != nil {
+		return false, fmt.Errorf("replace connection credentials: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
 func (r *PostgresRepository) Delete(ctx context.Context, id string) error {
 	conn, err := r.registry.GetPool(ctx)
 	if err != nil {
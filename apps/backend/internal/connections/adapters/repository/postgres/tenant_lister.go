@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TenantLister reads the provisioned tenants from the control plane.
+type TenantLister struct {
+	controlDB *pgxpool.Pool
+}
+
+func NewTenantLister(controlDB *pgxpool.Pool) *TenantLister {
+	return &TenantLister{controlDB: controlDB}
+}
+
+// ListProvisionedTenants leaves out organizations whose database is not
+// there yet or is being purged.
+func (l *TenantLister) ListProvisionedTenants(ctx context.Context) ([]ports.TenantDatabase, error) {
+	rows, err := l.controlDB.Query(ctx, `
+		SELECT id, db_name, status FROM tenants
+		WHERE status NOT IN ('provisioning', 'failed', 'deleting')
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("list provisioned tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []ports.TenantDatabase
+	for rows.Next() {
+		var t ports.TenantDatabase
+		if err := rows.Scan(&t.TenantID, &t.DBName, &t.Status); err != nil {
+			return nil, fmt.Errorf("scan tenant: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+
+	return tenants, rows.Err()
+}
@@ -0,0 +1,21 @@
+package application
+
+import (
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/auth"
+)
+
+// ActorFromClaims turns the authenticated caller into a connection actor.
+// Managing comes from the tenant role's permissions; API keys get no user, so
+// they never count as the owner of a mailbox.
+func ActorFromClaims(claims *auth.CustomClaims) domain.Actor {
+	if claims == nil {
+		return domain.Actor{}
+	}
+
+	actor := domain.Actor{CanManage: claims.HasPermission(domain.ManagePermission)}
+	if claims.APIKeyID == "" {
+		actor.UserID = claims.UserID
+	}
+	return actor
+}
@@ -2,8 +2,10 @@ package application
 
 import (
 	"github.com/bowerbird/internal/connections/application/commands"
+	"github.com/bowerbird/internal/connections/application/ports"
 	"github.com/bowerbird/internal/connections/application/queries"
 	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
 )
 
 type Application struct {
@@ -13,23 +15,49 @@ type Application struct {
 
 type Commands struct {
 	MarkRequiresReconnect *commands.MarkRequiresReconnectCommand
+	DeleteConnection      *commands.DeleteConnectionCommand
+	RevokeCredentials     *commands.RevokeCredentialsCommand
+	TransferConnection    *commands.TransferConnectionCommand
+	UpdateConnection      *commands.UpdateConnectionCommand
+	ReconnectConnection   *commands.ReconnectConnectionCommand
+	// Tokens hands out provider tokens; syncs must not decrypt credentials
+	// themselves or refreshed tokens are lost.
+	Tokens *commands.TokenManager
 }
 
 type Queries struct {
 	GetActiveConnections *queries.GetActiveConnectionsQuery
+	FindActiveConnection *queries.FindActiveConnectionQuery
 	DecryptCredentials   *queries.DecryptCredentialsQuery
 	GetSharingPolicy     *queries.GetSharingPolicyQuery
+	ListOwnedConnections *queries.ListOwnedConnectionsQuery
+	// ListAccessibleConnections applies the connection access rules; other
+	// modules filter through it rather than checking sharing policies.
+	ListAccessibleConnections *queries.ListAccessibleConnectionsQuery
 }
 
-func NewApplication(repo domain.Repository, credentialsService *commands.CredentialsService) *Application {
+func NewApplication(repo domain.Repository, credentialsService *commands.CredentialsService, revoker ports.TokenRevoker, refresher ports.TokenRefresher, recorder audit.Recorder) *Application {
+	markRequiresReconnect := commands.NewMarkRequiresReconnectCommand(repo, recorder)
+	revokeCredentials := commands.NewRevokeCredentialsCommand(repo, credentialsService, revoker)
+
 	return &Application{
 		Commands: Commands{
-			MarkRequiresReconnect: commands.NewMarkRequiresReconnectCommand(repo),
+			MarkRequiresReconnect: markRequiresReconnect,
+			DeleteConnection:      commands.NewDeleteConnectionCommand(repo, revokeCredentials, recorder),
+			RevokeCredentials:     revokeCredentials,
+			TransferConnection:    commands.NewTransferConnectionCommand(repo, recorder),
+			UpdateConnection:      commands.NewUpdateConnectionCommand(repo, recorder),
+			ReconnectConnection:   commands.NewReconnectConnectionCommand(repo, credentialsService, recorder),
+			Tokens:                commands.NewTokenManager(repo, credentialsService, refresher, markRequiresReconnect),
 		},
 		Queries: Queries{
 			GetActiveConnections: queries.NewGetActiveConnectionsQuery(repo),
+			FindActiveConnection: queries.NewFindActiveConnectionQuery(repo),
 			DecryptCredentials:   queries.NewDecryptCredentialsQuery(repo, credentialsService),
 			GetSharingPolicy:     queries.NewGetSharingPolicyQuery(repo),
+			ListOwnedConnections: queries.NewListOwnedConnectionsQuery(repo),
+
+			ListAccessibleConnections: queries.NewListAccessibleConnectionsQuery(repo),
 		},
 	}
 }
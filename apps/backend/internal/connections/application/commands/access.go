@@ -0,0 +1,16 @@
+package commands
+
+import "github.com/bowerbird/internal/connections/domain"
+
+// authorize applies the connection access rules for a command. A connection
+// the actor cannot even see is reported as not found, so its existence does
+// not leak to other members.
+func authorize(conn *domain.Connection, actor domain.Actor, access domain.Access) error {
+	if conn == nil || !conn.Allows(actor, domain.AccessView) {
+		return domain.ErrConnectionNotFound
+	}
+	if !conn.Allows(actor, access) {
+		return domain.ErrConnectionForbidden
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+type DeleteConnectionCommand struct {
+	repo     ports.ConnectionRepository
+	revoke   *RevokeCredentialsCommand
+	recorder audit.Recorder
+}
+
+func NewDeleteConnectionCommand(repo ports.ConnectionRepository, revoke *RevokeCredentialsCommand, recorder audit.Recorder) *DeleteConnectionCommand {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+	if revoke == nil {
+		panic("revoke credentials command is required")
+	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
+
+	return &DeleteConnectionCommand{repo: repo, revoke: revoke, recorder: recorder}
+}
+
+// ExecuteAs deletes the connection on behalf of a member, revoking its grant
+// at the provider first.
+func (cmd *DeleteConnectionCommand) ExecuteAs(ctx context.Context, connectionID string, actor domain.Actor) error {
+	conn, err := cmd.repo.GetByID(ctx, connectionID)
+	if err != nil {
+		return fmt.Errorf("get connection %s: %w", connectionID, err)
+	}
+	if err := authorize(conn, actor, domain.AccessDelete); err != nil {
+		return err
+	}
+
+	// Revocation is best effort: the credentials are deleted either way and
+	// the member can still revoke the grant from the provider's settings.
+	revoked := true
+	if err := cmd.revoke.Execute(ctx, conn.ID); err != nil {
+		slog.Warn("Connection token revocation failed", "connection_id", conn.ID, "error", err)
+		revoked = false
+	}
+
+	return cmd.delete(ctx, conn, map[string]any{"token_revoked": revoked})
+}
+
+// Execute deletes the connection for the system, e.g. an account erasure.
+// Revocation is left to the caller, which revokes before deciding whether to
+// transfer or delete.
+func (cmd *DeleteConnectionCommand) Execute(ctx context.Context, connectionID string) error {
+	conn, err := cmd.repo.GetByID(ctx, connectionID)
+	if err != nil {
+		return fmt.Errorf("get connection %s: %w", connectionID, err)
+	}
+	if conn == nil {
+		return domain.ErrConnectionNotFound
+	}
+
+	return cmd.delete(ctx, conn, nil)
+}
+
+// delete records the deletion before making it, so a connection is never
+// deleted without a trace in the audit log.
+func (cmd *DeleteConnectionCommand) delete(ctx context.Context, conn *domain.Connection, metadata map[string]any) error {
+	err := cmd.recorder.Record(ctx, audit.Event{
+		Action:     audit.ActionConnectionDeleted,
+		TargetType: audit.TargetConnection,
+		TargetID:   conn.ID,
+		Before:     conn.AuditSnapshot(),
+		Metadata:   metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.repo.Delete(ctx, conn.ID)
+}
@@ -0,0 +1,211 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+type memoryConnections struct {
+	ports.ConnectionRepository
+
+	connections map[string]*domain.Connection
+	upserts     int
+}
+
+func (r *memoryConnections) GetByID(ctx context.Context, id string) (*domain.Connection, error) {
+	return r.connections[id], nil
+}
+
+func (r *memoryConnections) Upsert(ctx context.Context, conn *domain.Connection) error {
+	r.connections[conn.ID] = conn
+	r.upserts++
+	return nil
+}
+
+func (r *memoryConnections) UpdateSettings(ctx context.Context, id string, settings domain.ConnectionSettings) error {
+	stored := r.connections[id]
+	if settings.SharingPolicy != nil {
+		stored.SharingPolicy = *settings.SharingPolicy
+	}
+	if settings.Status != nil {
+		stored.Status = *settings.Status
+	}
+	if settings.IngestionRules != nil {
+		stored.IngestionRules = *settings.IngestionRules
+	}
+	if settings.WriteBack != nil {
+		stored.WriteBack = *settings.WriteBack
+	}
+	stored.UpdatedAt = settings.UpdatedAt
+	return nil
+}
+
+func (r *memoryConnections) UpdateGrant(ctx context.Context, conn *domain.Connection) (bool, error) {
+	r.connections[conn.ID] = conn
+	return true, nil
+}
+
+func (r *memoryConnections) UpdateCredentials(ctx context.Context, id string, encryptedCredentials []byte, at time.Time) error {
+	r.connections[id].EncryptedCredentials = encryptedCredentials
+	r.connections[id].UpdatedAt = at
+	return nil
+}
+
+func (r *memoryConnections) ReplaceCredentials(ctx context.Context, id string, current, replacement []byte) (bool, error) {
+	if string(r.connections[id].EncryptedCredentials) != string(current) {
+		return false, nil
+	}
+	r.connections[id].EncryptedCredentials = replacement
+	return true, nil
+}
+
+func (r *memoryConnections) Delete(ctx context.Context, id string) error {
+	delete(r.connections, id)
+	return nil
+}
+
+type recordingAudit struct {
+	events []audit.Event
+	err    error
+}
+
+func (r *recordingAudit) Record(ctx context.Context, event audit.Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.events = append(r.events, event)
+	return nil
+}
+
+type fakeRevoker struct {
+	revoked []string
+	err     error
+}
+
+func (r *fakeRevoker) Revoke(ctx context.Context, provider string, credentials []byte) error {
+	r.revoked = append(r.revoked, string(credentials))
+	return r.err
+}
+
+func newDeleteCommand(repo *memoryConnections, revoker *fakeRevoker, recorder *recordingAudit) *DeleteConnectionCommand {
+	return NewDeleteConnectionCommand(repo, NewRevokeCredentialsCommand(repo, NewCredentialsService(prefixCipher{}), revoker), recorder)
+}
+
+func newConnectionsFixture() (*memoryConnections, *recordingAudit) {
+	repo := &memoryConnections{connections: map[string]*domain.Connection{
+		"conn-1": {ID: "conn-1", Provider: "gmail", Status: domain.ConnectionStatusActive, EncryptedCredentials: []byte("enc:secret")},
+	}}
+	return repo, &recordingAudit{}
+}
+
+func TestDeleteConnectionRecordsDeletedState(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	revoker := &fakeRevoker{}
+
+	if err := newDeleteCommand(repo, revoker, recorder).Execute(context.Background(), "conn-1"); err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if len(revoker.revoked) != 0 {
+		t.Fatal("the system path leaves revocation to the caller")
+	}
+
+	if _, ok := repo.connections["conn-1"]; ok {
+		t.Fatal("expected the connection to be deleted")
+	}
+	if len(recorder.events) != 1 || recorder.events[0].Action != audit.ActionConnectionDeleted || recorder.events[0].TargetID != "conn-1" {
+		t.Fatalf("expected a deletion event, got %+v", recorder.events)
+	}
+	before := recorder.events[0].Before.(map[string]any)
+	if before["status"] != domain.ConnectionStatusActive {
+		t.Fatalf("expected the deleted state, got %v", before)
+	}
+	if _, leaked := before["encrypted_credentials"]; leaked {
+		t.Fatal("credentials must not be recorded")
+	}
+}
+
+func TestDeleteConnectionRejectsUnknownConnection(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+
+	err := newDeleteCommand(repo, &fakeRevoker{}, recorder).Execute(context.Background(), "missing")
+	if !errors.Is(err, domain.ErrConnectionNotFound) || len(recorder.events) != 0 {
+		t.Fatalf("expected ErrConnectionNotFound without events, got %v", err)
+	}
+}
+
+func TestDeleteConnectionKeepsConnectionWhenAuditFails(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	recorder.err = errors.New("audit log unavailable")
+
+	err := newDeleteCommand(repo, &fakeRevoker{}, recorder).Execute(context.Background(), "conn-1")
+	if err == nil {
+		t.Fatal("expected the audit failure to abort the deletion")
+	}
+	if _, ok := repo.connections["conn-1"]; !ok {
+		t.Fatal("expected the connection to be kept")
+	}
+}
+
+func TestDeleteConnectionAsMemberAppliesAccessRules(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "owner-1"
+	repo.connections["conn-1"].SharingPolicy = domain.SharingPolicyTenantAll
+	revoker := &fakeRevoker{}
+	cmd := newDeleteCommand(repo, revoker, recorder)
+
+	err := cmd.ExecuteAs(context.Background(), "conn-1", domain.Actor{UserID: "member-1"})
+	if !errors.Is(err, domain.ErrConnectionForbidden) {
+		t.Fatalf("expected ErrConnectionForbidden for a member, got %v", err)
+	}
+
+	if err := cmd.ExecuteAs(context.Background(), "conn-1", domain.Actor{UserID: "admin-1", CanManage: true}); err != nil {
+		t.Fatalf("expected a manager to delete the connection, got %v", err)
+	}
+	if _, ok := repo.connections["conn-1"]; ok {
+		t.Fatal("expected the connection to be deleted")
+	}
+	if len(revoker.revoked) != 1 || revoker.revoked[0] != "secret" {
+		t.Fatalf("expected the grant to be revoked once, got %v", revoker.revoked)
+	}
+}
+
+func TestDeleteConnectionAsMemberDeletesWhenRevocationFails(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "user-1"
+
+	err := newDeleteCommand(repo, &fakeRevoker{err: errors.New("provider down")}, recorder).ExecuteAs(context.Background(), "conn-1", domain.Actor{UserID: "user-1"})
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if _, ok := repo.connections["conn-1"]; ok {
+		t.Fatal("expected the connection to be deleted")
+	}
+	if recorder.events[0].Metadata["token_revoked"] != false {
+		t.Fatalf("expected the failed revocation to be recorded, got %v", recorder.events[0].Metadata)
+	}
+}
+
+func TestMarkRequiresReconnectRecordsStatusChangeOnce(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	cmd := NewMarkRequiresReconnectCommand(repo, recorder)
+
+	for range 2 {
+		if err := cmd.Execute(context.Background(), "conn-1", string(domain.ReconnectReasonGrantRevoked)); err != nil {
+			t.Fatalf("mark failed: %v", err)
+		}
+	}
+
+	conn := repo.connections["conn-1"]
+	if conn.Status != domain.ConnectionStatusRequiresReconnect || conn.ReconnectReason != domain.ReconnectReasonGrantRevoked {
+		t.Fatalf("expected the connection to require a reconnect with its reason, got %s/%s", conn.Status, conn.ReconnectReason)
+	}
+	if len(recorder.events) != 1 || recorder.events[0].Metadata["reason"] != "grant_revoked" {
+		t.Fatalf("expected one event with the reason, got %+v", recorder.events)
+	}
+}
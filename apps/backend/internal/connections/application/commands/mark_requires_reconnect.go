@@ -6,31 +6,57 @@ import (
 	"time"
 
 	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
 )
 
 type MarkRequiresReconnectCommand struct {
-	repo ports.ConnectionRepository
-	now  func() time.Time
+	repo     ports.ConnectionRepository
+	recorder audit.Recorder
+	now      func() time.Time
 }
 
-func NewMarkRequiresReconnectCommand(repo ports.ConnectionRepository) *MarkRequiresReconnectCommand {
+func NewMarkRequiresReconnectCommand(repo ports.ConnectionRepository, recorder audit.Recorder) *MarkRequiresReconnectCommand {
 	if repo == nil {
 		panic("connection repository is required")
 	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
 
-	return &MarkRequiresReconnectCommand{repo: repo, now: time.Now}
+	return &MarkRequiresReconnectCommand{repo: repo, recorder: recorder, now: time.Now}
 }
 
+// Execute takes one of the domain reconnect reasons; anything else is
+// recorded as provider_rejected.
 func (cmd *MarkRequiresReconnectCommand) Execute(ctx context.Context, connectionID, reason string) error {
 	conn, err := cmd.repo.GetByID(ctx, connectionID)
 	if err != nil {
 		return fmt.Errorf("get connection %s: %w", connectionID, err)
 	}
 	if conn == nil {
-		return fmt.Errorf("connection not found")
+		return domain.ErrConnectionNotFound
+	}
+	if conn.Status == domain.ConnectionStatusRequiresReconnect {
+		return nil
+	}
+
+	before := conn.AuditSnapshot()
+	reconnectReason := domain.ParseReconnectReason(reason)
+	if err := conn.MarkRequiresReconnect(reconnectReason, cmd.now()); err != nil {
+		return err
 	}
 
-	if err := conn.MarkRequiresReconnect(reason, cmd.now()); err != nil {
+	// Recorded before it is stored, so the change is never saved untraced.
+	err = cmd.recorder.Record(ctx, audit.Event{
+		Action:     audit.ActionConnectionRequiresReconnect,
+		TargetType: audit.TargetConnection,
+		TargetID:   conn.ID,
+		Before:     before,
+		After:      conn.AuditSnapshot(),
+		Metadata:   map[string]any{"reason": string(reconnectReason)},
+	})
+	if err != nil {
 		return err
 	}
 
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+// ReconnectConnectionInput carries the result of the OAuth flow the owner
+// ran again for the connection.
+type ReconnectConnectionInput struct {
+	ConnectionID         string
+	Actor                domain.Actor
+	ProviderAccountEmail string
+	Credentials          []byte
+	GrantedScopes        []string
+}
+
+type ReconnectConnectionCommand struct {
+	repo        ports.ConnectionRepository
+	credentials *CredentialsService
+	recorder    audit.Recorder
+	now         func() time.Time
+}
+
+func NewReconnectConnectionCommand(repo ports.ConnectionRepository, credentials *CredentialsService, recorder audit.Recorder) *ReconnectConnectionCommand {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+	if credentials == nil {
+		panic("credentials service is required")
+	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
+
+	return &ReconnectConnectionCommand{repo: repo, credentials: credentials, recorder: recorder, now: time.Now}
+}
+
+// Authorize checks, before the OAuth flow starts, that the actor may
+// reconnect the connection, so they are not sent to the provider for nothing.
+func (cmd *ReconnectConnectionCommand) Authorize(ctx context.Context, connectionID string, actor domain.Actor) error {
+	conn, err := cmd.repo.GetByID(ctx, connectionID)
+	if err != nil {
+		return fmt.Errorf("get connection %s: %w", connectionID, err)
+	}
+	return checkReconnect(conn, actor)
+}
+
+func (cmd *ReconnectConnectionCommand) Execute(ctx context.Context, input ReconnectConnectionInput) (*domain.Connection, error) {
+	conn, err := cmd.repo.GetByID(ctx, input.ConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("get connection %s: %w", input.ConnectionID, err)
+	}
+	if err := checkReconnect(conn, input.Actor); err != nil {
+		return nil, err
+	}
+
+	encrypted, err := cmd.credentials.EncryptForStorage(input.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	before := conn.AuditSnapshot()
+	if err := conn.Reconnect(input.ProviderAccountEmail, encrypted, input.GrantedScopes, cmd.now()); err != nil {
+		return nil, err
+	}
+
+	// Recorded before it is stored, so the change is never saved untraced.
+	// The OAuth callback carries no claims, so the owner is named here.
+	err = cmd.recorder.Record(ctx, audit.Event{
+		ActorUserID: input.Actor.UserID,
+		Action:      audit.ActionConnectionReconnected,
+		TargetType:  audit.TargetConnection,
+		TargetID:    conn.ID,
+		Before:      before,
+		After:       conn.AuditSnapshot(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the grant is written; settings changed meanwhile are kept.
+	updated, err := cmd.repo.UpdateGrant(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, domain.ErrReconnectNotRequired
+	}
+
+	return conn, nil
+}
+
+func checkReconnect(conn *domain.Connection, actor domain.Actor) error {
+	if err := authorize(conn, actor, domain.AccessReconnect); err != nil {
+		return err
+	}
+	if conn.Status != domain.ConnectionStatusRequiresReconnect {
+		return domain.ErrReconnectNotRequired
+	}
+	return nil
+}
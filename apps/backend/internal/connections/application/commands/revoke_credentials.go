@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+)
+
+type RevokeCredentialsCommand struct {
+	repo               ports.ConnectionRepository
+	credentialsService *CredentialsService
+	revoker            ports.TokenRevoker
+}
+
+func NewRevokeCredentialsCommand(repo ports.ConnectionRepository, credentialsService *CredentialsService, revoker ports.TokenRevoker) *RevokeCredentialsCommand {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+	if revoker == nil {
+		panic("token revoker is required")
+	}
+
+	return &RevokeCredentialsCommand{repo: repo, credentialsService: credentialsService, revoker: revoker}
+}
+
+// Execute revokes the provider grant of the connection. The stored
+// credentials are left as they are: callers delete or transfer the
+// connection right after.
+func (cmd *RevokeCredentialsCommand) Execute(ctx context.Context, connectionID string) error {
+	conn, err := cmd.repo.GetByID(ctx, connectionID)
+	if err != nil {
+		return fmt.Errorf("get connection %s: %w", connectionID, err)
+	}
+	if conn == nil {
+		return domain.ErrConnectionNotFound
+	}
+	if len(conn.EncryptedCredentials) == 0 {
+		return nil
+	}
+	if cmd.credentialsService == nil {
+		return ErrCipherNotConfigured
+	}
+
+	credentials, err := cmd.credentialsService.ReadDecryptedCredentials(conn)
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.revoker.Revoke(ctx, conn.Provider, credentials); err != nil {
+		return fmt.Errorf("revoke %s credentials: %w", conn.Provider, err)
+	}
+	return nil
+}
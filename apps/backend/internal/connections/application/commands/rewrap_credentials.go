@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/platform/database"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+// keyUsageScope names the connection credentials among the records counted
+// per master key.
+const keyUsageScope = "connections"
+
+var errTenantInMaintenance = errors.New("organization is being migrated")
+
+// RewrapReport counts what a rewrap run did across tenants.
+type RewrapReport struct {
+	Tenants   int
+	Rewrapped int
+	Unchanged int
+	// Skipped counts credentials that changed while being rewrapped; the
+	// new ciphertext is already under the active key.
+	Skipped int
+	Failed  int
+	// KeyUsage counts the credentials each master key seals after the run.
+	KeyUsage map[string]int
+}
+
+// RewrapCredentialsCommand moves the stored credentials of every tenant's
+// connections under the active master key, so retired keys can be dropped
+// from the key ring.
+type RewrapCredentialsCommand struct {
+	tenants   ports.TenantLister
+	repo      ports.ConnectionRepository
+	rewrapper ports.CredentialsRewrapper
+	usage     ports.KeyUsageRecorder
+}
+
+func NewRewrapCredentialsCommand(tenants ports.TenantLister, repo ports.ConnectionRepository, rewrapper ports.CredentialsRewrapper, usage ports.KeyUsageRecorder) *RewrapCredentialsCommand {
+	if tenants == nil {
+		panic("tenant lister is required")
+	}
+	if repo == nil {
+		panic("connection repository is required")
+	}
+	if rewrapper == nil {
+		panic("credentials rewrapper is required")
+	}
+	if usage == nil {
+		panic("key usage recorder is required")
+	}
+
+	return &RewrapCredentialsCommand{tenants: tenants, repo: repo, rewrapper: rewrapper, usage: usage}
+}
+
+// Execute is idempotent: a failed tenant or record is picked up by the next
+// run, and records already under the active key are left alone. The keys the
+// credentials still use are recorded either way; only a run without failures
+// lets a retired key drop out of that record.
+func (cmd *RewrapCredentialsCommand) Execute(ctx context.Context) (RewrapReport, error) {
+	// The active key seals whatever is written while the run goes on.
+	report := RewrapReport{KeyUsage: map[string]int{cmd.rewrapper.ActiveKeyID(): 0}}
+
+	tenants, err := cmd.tenants.ListProvisionedTenants(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	var errs []error
+	for _, t := range tenants {
+		if err := cmd.rewrapTenant(ctx, t, &report); err != nil {
+			slog.Error("Credentials rewrap failed", "tenant_id", t.TenantID, "error", err)
+			errs = append(errs, fmt.Errorf("rewrap tenant %s: %w", t.TenantID, err))
+			continue
+		}
+		report.Tenants++
+	}
+
+	if err := cmd.usage.RecordKeyUsage(ctx, keyUsageScope, report.KeyUsage, len(errs) == 0); err != nil {
+		errs = append(errs, fmt.Errorf("record key usage: %w", err))
+	}
+
+	return report, errors.Join(errs...)
+}
+
+func (cmd *RewrapCredentialsCommand) rewrapTenant(ctx context.Context, t ports.TenantDatabase, report *RewrapReport) error {
+	if t.Status == "maintenance" {
+		// Rows rewrapped while the migrate tool copies them could be lost.
+		return errTenantInMaintenance
+	}
+
+	// Suspended or archived organizations keep their credentials, so their
+	// database is reached by name rather than through the active tenants.
+	ctx = database.WithTenantDatabase(tenant.WithTenantID(ctx, t.TenantID), t.DBName)
+
+	connections, err := cmd.repo.ListAll(ctx)
+	if err != nil {
+		return fmt.Errorf("list connections: %w", err)
+	}
+
+	var errs []error
+	for _, conn := range connections {
+		if len(conn.EncryptedCredentials) == 0 {
+			continue
+		}
+
+		rewrapped, changed, err := cmd.rewrapper.Rewrap(conn.EncryptedCredentials)
+		if err != nil {
+			report.Failed++
+			report.KeyUsage[cmd.rewrapper.KeyID(conn.EncryptedCredentials)]++
+			errs = append(errs, fmt.Errorf("rewrap connection %s: %w", conn.ID, err))
+			continue
+		}
+		if !changed {
+			report.Unchanged++
+			report.KeyUsage[cmd.rewrapper.KeyID(conn.EncryptedCredentials)]++
+			continue
+		}
+
+		replaced, err := cmd.repo.ReplaceCredentials(ctx, conn.ID, conn.EncryptedCredentials, rewrapped)
+		if err != nil {
+			report.Failed++
+			report.KeyUsage[cmd.rewrapper.KeyID(conn.EncryptedCredentials)]++
+			errs = append(errs, fmt.Errorf("store connection %s: %w", conn.ID, err))
+			continue
+		}
+		report.KeyUsage[cmd.rewrapper.KeyID(rewrapped)]++
+		if !replaced {
+			report.Skipped++
+			continue
+		}
+		report.Rewrapped++
+	}
+
+	return errors.Join(errs...)
+}
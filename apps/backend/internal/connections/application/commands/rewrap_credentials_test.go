@@ -0,0 +1,189 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+type staticTenants []ports.TenantDatabase
+
+func (t staticTenants) ListProvisionedTenants(ctx context.Context) ([]ports.TenantDatabase, error) {
+	return t, nil
+}
+
+func activeTenants(ids ...string) staticTenants {
+	tenants := make(staticTenants, 0, len(ids))
+	for _, id := range ids {
+		tenants = append(tenants, ports.TenantDatabase{TenantID: id, DBName: "db_" + id, Status: "active"})
+	}
+	return tenants
+}
+
+type recordedKeyUsage struct {
+	usage    map[string]int
+	complete bool
+}
+
+type memoryKeyUsage struct {
+	recorded []recordedKeyUsage
+}
+
+func (u *memoryKeyUsage) RecordKeyUsage(ctx context.Context, scope string, usage map[string]int, complete bool) error {
+	u.recorded = append(u.recorded, recordedKeyUsage{usage: usage, complete: complete})
+	return nil
+}
+
+// tenantConnections routes each call to the memory repository of the tenant
+// in the context.
+type tenantConnections struct {
+	ports.ConnectionRepository
+
+	tenants map[string]*memoryConnections
+}
+
+func (r *tenantConnections) tenantRepo(ctx context.Context) *memoryConnections {
+	tenantID, _ := tenant.TenantIDFromContext(ctx)
+	return r.tenants[tenantID]
+}
+
+func (r *tenantConnections) ReplaceCredentials(ctx context.Context, id string, current, replacement []byte) (bool, error) {
+	return r.tenantRepo(ctx).ReplaceCredentials(ctx, id, current, replacement)
+}
+
+func (r *tenantConnections) ListAll(ctx context.Context) ([]*domain.Connection, error) {
+	repo := r.tenantRepo(ctx)
+
+	ids := make([]string, 0, len(repo.connections))
+	for id := range repo.connections {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	connections := make([]*domain.Connection, 0, len(ids))
+	for _, id := range ids {
+		clone := *repo.connections[id]
+		connections = append(connections, &clone)
+	}
+	return connections, nil
+}
+
+// prefixRewrapper moves "old:" ciphertext, sealed by k1, to "new:", sealed
+// by the active k2, and fails on "bad:", sealed by a retired k0.
+type prefixRewrapper struct {
+	onRewrap func()
+}
+
+func (r prefixRewrapper) KeyID(ciphertext []byte) string {
+	prefix, _, _ := strings.Cut(string(ciphertext), ":")
+	return map[string]string{"bad": "k0", "old": "k1", "new": "k2"}[prefix]
+}
+
+func (r prefixRewrapper) ActiveKeyID() string {
+	return "k2"
+}
+
+func (r prefixRewrapper) Rewrap(ciphertext []byte) ([]byte, bool, error) {
+	switch {
+	case strings.HasPrefix(string(ciphertext), "bad:"):
+		return nil, false, errors.New("unknown master key")
+	case strings.HasPrefix(string(ciphertext), "old:"):
+		if r.onRewrap != nil {
+			r.onRewrap()
+		}
+		return []byte("new:" + string(ciphertext[len("old:"):])), true, nil
+	default:
+		return ciphertext, false, nil
+	}
+}
+
+func TestRewrapCredentialsRewrapsEveryTenant(t *testing.T) {
+	repo := &tenantConnections{tenants: map[string]*memoryConnections{
+		"tenant-a": {connections: map[string]*domain.Connection{
+			"conn-1": {ID: "conn-1", EncryptedCredentials: []byte("old:a")},
+			"conn-2": {ID: "conn-2", EncryptedCredentials: []byte("new:b")},
+			"conn-3": {ID: "conn-3"},
+		}},
+		"tenant-b": {connections: map[string]*domain.Connection{
+			"conn-4": {ID: "conn-4", EncryptedCredentials: []byte("old:c")},
+			"conn-5": {ID: "conn-5", EncryptedCredentials: []byte("bad:d")},
+		}},
+	}}
+
+	tenants := activeTenants("tenant-a", "tenant-b")
+	tenants[1].Status = "suspended"
+	usage := &memoryKeyUsage{}
+
+	report, err := NewRewrapCredentialsCommand(tenants, repo, prefixRewrapper{}, usage).Execute(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "conn-5") {
+		t.Fatalf("expected the failing record to be reported, got %v", err)
+	}
+
+	if got := string(repo.tenants["tenant-a"].connections["conn-1"].EncryptedCredentials); got != "new:a" {
+		t.Fatalf("expected conn-1 to be rewrapped, got %q", got)
+	}
+	if got := string(repo.tenants["tenant-b"].connections["conn-4"].EncryptedCredentials); got != "new:c" {
+		t.Fatalf("expected conn-4 to be rewrapped, got %q", got)
+	}
+	if report.Tenants != 1 || report.Rewrapped != 2 || report.Unchanged != 1 || report.Failed != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	if len(usage.recorded) != 1 || usage.recorded[0].complete {
+		t.Fatalf("expected an incomplete key usage record, got %+v", usage.recorded)
+	}
+	if got := usage.recorded[0].usage; got["k2"] != 3 || got["k0"] != 1 || got["k1"] != 0 {
+		t.Fatalf("expected k0 to stay in use after the failure, got %v", got)
+	}
+}
+
+func TestRewrapCredentialsWaitsForTenantsInMaintenance(t *testing.T) {
+	repo := &tenantConnections{tenants: map[string]*memoryConnections{
+		"tenant-a": {connections: map[string]*domain.Connection{
+			"conn-1": {ID: "conn-1", EncryptedCredentials: []byte("old:a")},
+		}},
+	}}
+	tenants := activeTenants("tenant-a")
+	tenants[0].Status = "maintenance"
+	usage := &memoryKeyUsage{}
+
+	if _, err := NewRewrapCredentialsCommand(tenants, repo, prefixRewrapper{}, usage).Execute(context.Background()); !errors.Is(err, errTenantInMaintenance) {
+		t.Fatalf("expected errTenantInMaintenance, got %v", err)
+	}
+	if got := string(repo.tenants["tenant-a"].connections["conn-1"].EncryptedCredentials); got != "old:a" {
+		t.Fatalf("expected the tenant to be left alone, got %q", got)
+	}
+	if len(usage.recorded) != 1 || usage.recorded[0].complete {
+		t.Fatalf("expected the run to be recorded as incomplete, got %+v", usage.recorded)
+	}
+}
+
+func TestRewrapCredentialsKeepsCredentialsRefreshedMeanwhile(t *testing.T) {
+	tenantRepo := &memoryConnections{connections: map[string]*domain.Connection{
+		"conn-1": {ID: "conn-1", EncryptedCredentials: []byte("old:stale")},
+	}}
+	repo := &tenantConnections{tenants: map[string]*memoryConnections{"tenant-a": tenantRepo}}
+	refresh := func() { tenantRepo.connections["conn-1"].EncryptedCredentials = []byte("new:fresh") }
+
+	usage := &memoryKeyUsage{}
+
+	report, err := NewRewrapCredentialsCommand(activeTenants("tenant-a"), repo, prefixRewrapper{onRewrap: refresh}, usage).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("rewrap failed: %v", err)
+	}
+	if got := string(tenantRepo.connections["conn-1"].EncryptedCredentials); got != "new:fresh" {
+		t.Fatalf("expected the refreshed token to survive, got %q", got)
+	}
+	if report.Skipped != 1 || report.Rewrapped != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(usage.recorded) != 1 || !usage.recorded[0].complete || usage.recorded[0].usage["k1"] != 0 {
+		t.Fatalf("expected a complete run without k1, got %+v", usage.recorded)
+	}
+}
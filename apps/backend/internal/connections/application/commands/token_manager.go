@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"golang.org/x/oauth2"
+)
+
+// tokenRefreshWindow refreshes tokens this long before they expire, so a
+// sync never starts with a token about to lapse.
+const tokenRefreshWindow = 5 * time.Minute
+
+// TokenManager hands out valid provider tokens for connections. Refreshed
+// tokens are written back, and a grant that can no longer be used marks the
+// connection as requiring a reconnect with a structured reason.
+type TokenManager struct {
+	repo          ports.ConnectionRepository
+	credentials   *CredentialsService
+	refresher     ports.TokenRefresher
+	markReconnect *MarkRequiresReconnectCommand
+	now           func() time.Time
+}
+
+func NewTokenManager(repo ports.ConnectionRepository, credentials *CredentialsService, refresher ports.TokenRefresher, markReconnect *MarkRequiresReconnectCommand) *TokenManager {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+	if refresher == nil {
+		panic("token refresher is required")
+	}
+	if markReconnect == nil {
+		panic("mark requires reconnect command is required")
+	}
+
+	return &TokenManager{
+		repo:          repo,
+		credentials:   credentials,
+		refresher:     refresher,
+		markReconnect: markReconnect,
+		now:           time.Now,
+	}
+}
+
+// TokenSource returns a token source for the connection that refreshes ahead
+// of expiry and persists every rotated token.
+func (m *TokenManager) TokenSource(ctx context.Context, connectionID string) oauth2.TokenSource {
+	return oauth2.ReuseTokenSourceWithExpiry(nil, &connectionTokenSource{ctx: ctx, manager: m, connectionID: connectionID}, tokenRefreshWindow)
+}
+
+type connectionTokenSource struct {
+	ctx          context.Context
+	manager      *TokenManager
+	connectionID string
+}
+
+func (s *connectionTokenSource) Token() (*oauth2.Token, error) {
+	return s.manager.Token(s.ctx, s.connectionID)
+}
+
+// Token returns the stored token, refreshing it first when it expires within
+// the refresh window.
+func (m *TokenManager) Token(ctx context.Context, connectionID string) (*oauth2.Token, error) {
+	conn, err := m.repo.GetByID(ctx, connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("get connection %s: %w", connectionID, err)
+	}
+	if conn == nil {
+		return nil, domain.ErrConnectionNotFound
+	}
+	if conn.Status == domain.ConnectionStatusRequiresReconnect {
+		return nil, &domain.RequiresReconnectError{Reason: conn.ReconnectReason}
+	}
+	if m.credentials == nil {
+		return nil, ErrCipherNotConfigured
+	}
+
+	plaintext, err := m.credentials.ReadDecryptedCredentials(conn)
+	if err != nil {
+		return nil, err
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(plaintext, &token); err != nil {
+		return nil, fmt.Errorf("decode credentials: %w", err)
+	}
+
+	if token.Expiry.IsZero() || token.Expiry.After(m.now().Add(tokenRefreshWindow)) {
+		return &token, nil
+	}
+	if token.RefreshToken == "" {
+		return nil, m.requireReconnect(ctx, conn, domain.ReconnectReasonMissingRefreshToken)
+	}
+
+	refreshed, err := m.refresher.Refresh(ctx, conn.Provider, &token)
+	if errors.Is(err, ports.ErrGrantRevoked) {
+		return nil, m.requireReconnect(ctx, conn, domain.ReconnectReasonGrantRevoked)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("refresh %s token: %w", conn.Provider, err)
+	}
+	if len(refreshed.Scopes) > 0 && len(domain.MissingScopes(conn.GrantedScopes, refreshed.Scopes)) > 0 {
+		return nil, m.requireReconnect(ctx, conn, domain.ReconnectReasonScopeDowngraded)
+	}
+
+	fresh := refreshed.Token
+	// Providers only return a refresh token when they rotate it.
+	if fresh.RefreshToken == "" {
+		fresh.RefreshToken = token.RefreshToken
+	}
+	if err := m.persist(ctx, conn, fresh); err != nil {
+		return nil, err
+	}
+
+	return fresh, nil
+}
+
+func (m *TokenManager) persist(ctx context.Context, conn *domain.Connection, token *oauth2.Token) error {
+	plaintext, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("encode credentials: %w", err)
+	}
+	encrypted, err := m.credentials.EncryptForStorage(plaintext)
+	if err != nil {
+		return err
+	}
+
+	if err := m.repo.UpdateCredentials(ctx, conn.ID, encrypted, m.now().UTC()); err != nil {
+		return fmt.Errorf("persist refreshed token: %w", err)
+	}
+	return nil
+}
+
+func (m *TokenManager) requireReconnect(ctx context.Context, conn *domain.Connection, reason domain.ReconnectReason) error {
+	reconnectErr := &domain.RequiresReconnectError{Reason: reason}
+	if err := m.markReconnect.Execute(ctx, conn.ID, string(reason)); err != nil {
+		return errors.Join(reconnectErr, fmt.Errorf("mark requires reconnect: %w", err))
+	}
+	return reconnectErr
+}
@@ -0,0 +1,149 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"golang.org/x/oauth2"
+)
+
+type fakeRefresher struct {
+	refreshed ports.RefreshedToken
+	err       error
+	calls     int
+}
+
+func (r *fakeRefresher) Refresh(ctx context.Context, provider string, token *oauth2.Token) (ports.RefreshedToken, error) {
+	r.calls++
+	return r.refreshed, r.err
+}
+
+var tokenManagerNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTokenManagerFixture(t *testing.T, stored oauth2.Token, refresher *fakeRefresher) (*TokenManager, *memoryConnections, *recordingAudit) {
+	t.Helper()
+	repo, recorder := newConnectionsFixture()
+	credentials := NewCredentialsService(prefixCipher{})
+	plaintext, _ := json.Marshal(stored)
+	if err := credentials.SetEncryptedCredentials(repo.connections["conn-1"], plaintext); err != nil {
+		t.Fatalf("store credentials: %v", err)
+	}
+	repo.connections["conn-1"].GrantedScopes = []string{"email", "https://www.googleapis.com/auth/gmail.modify"}
+
+	manager := NewTokenManager(repo, credentials, refresher, NewMarkRequiresReconnectCommand(repo, recorder))
+	manager.now = func() time.Time { return tokenManagerNow }
+	return manager, repo, recorder
+}
+
+func storedToken(t *testing.T, repo *memoryConnections) oauth2.Token {
+	t.Helper()
+	var token oauth2.Token
+	if err := json.Unmarshal(repo.connections["conn-1"].EncryptedCredentials[len("enc:"):], &token); err != nil {
+		t.Fatalf("decode stored token: %v", err)
+	}
+	return token
+}
+
+func TestTokenManagerReturnsValidTokenWithoutRefreshing(t *testing.T) {
+	refresher := &fakeRefresher{}
+	manager, _, _ := newTokenManagerFixture(t, oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: tokenManagerNow.Add(time.Hour)}, refresher)
+
+	token, err := manager.Token(context.Background(), "conn-1")
+	if err != nil {
+		t.Fatalf("token failed: %v", err)
+	}
+	if token.AccessToken != "access" || refresher.calls != 0 {
+		t.Fatalf("expected the stored token without a refresh, got %q after %d refreshes", token.AccessToken, refresher.calls)
+	}
+}
+
+func TestTokenManagerRefreshesAheadOfExpiryAndPersists(t *testing.T) {
+	refresher := &fakeRefresher{refreshed: ports.RefreshedToken{
+		Token:  &oauth2.Token{AccessToken: "fresh", Expiry: tokenManagerNow.Add(time.Hour)},
+		Scopes: []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/gmail.modify"},
+	}}
+	manager, repo, _ := newTokenManagerFixture(t, oauth2.Token{AccessToken: "stale", RefreshToken: "refresh", Expiry: tokenManagerNow.Add(2 * time.Minute)}, refresher)
+
+	token, err := manager.Token(context.Background(), "conn-1")
+	if err != nil {
+		t.Fatalf("token failed: %v", err)
+	}
+	if token.AccessToken != "fresh" || refresher.calls != 1 {
+		t.Fatalf("expected a refreshed token, got %q after %d refreshes", token.AccessToken, refresher.calls)
+	}
+
+	stored := storedToken(t, repo)
+	if stored.AccessToken != "fresh" || stored.RefreshToken != "refresh" {
+		t.Fatalf("expected the refreshed token to be persisted with the kept refresh token, got %+v", stored)
+	}
+}
+
+func TestTokenManagerMarksReconnectWhenTheGrantIsUnusable(t *testing.T) {
+	cases := []struct {
+		name      string
+		stored    oauth2.Token
+		refresher *fakeRefresher
+		reason    domain.ReconnectReason
+	}{
+		{
+			name:      "missing refresh token",
+			stored:    oauth2.Token{AccessToken: "stale", Expiry: tokenManagerNow.Add(-time.Minute)},
+			refresher: &fakeRefresher{},
+			reason:    domain.ReconnectReasonMissingRefreshToken,
+		},
+		{
+			name:      "revoked grant",
+			stored:    oauth2.Token{AccessToken: "stale", RefreshToken: "refresh", Expiry: tokenManagerNow.Add(-time.Minute)},
+			refresher: &fakeRefresher{err: ports.ErrGrantRevoked},
+			reason:    domain.ReconnectReasonGrantRevoked,
+		},
+		{
+			name:   "scope downgrade",
+			stored: oauth2.Token{AccessToken: "stale", RefreshToken: "refresh", Expiry: tokenManagerNow.Add(-time.Minute)},
+			refresher: &fakeRefresher{refreshed: ports.RefreshedToken{
+				Token:  &oauth2.Token{AccessToken: "fresh"},
+				Scopes: []string{"https://www.googleapis.com/auth/userinfo.email"},
+			}},
+			reason: domain.ReconnectReasonScopeDowngraded,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			manager, repo, recorder := newTokenManagerFixture(t, tc.stored, tc.refresher)
+
+			_, err := manager.Token(context.Background(), "conn-1")
+			var reconnectErr *domain.RequiresReconnectError
+			if !errors.As(err, &reconnectErr) || reconnectErr.Reason != tc.reason {
+				t.Fatalf("expected a reconnect error with reason %s, got %v", tc.reason, err)
+			}
+			if conn := repo.connections["conn-1"]; conn.Status != domain.ConnectionStatusRequiresReconnect || conn.ReconnectReason != tc.reason {
+				t.Fatalf("expected the connection to be marked, got %s/%s", conn.Status, conn.ReconnectReason)
+			}
+			if len(recorder.events) != 1 {
+				t.Fatalf("expected the status change to be recorded, got %+v", recorder.events)
+			}
+			if storedToken(t, repo).AccessToken != "stale" {
+				t.Fatal("an unusable grant must not overwrite the stored token")
+			}
+		})
+	}
+}
+
+func TestTokenSourceDoesNotRefreshConnectionsThatRequireReconnect(t *testing.T) {
+	refresher := &fakeRefresher{}
+	manager, repo, _ := newTokenManagerFixture(t, oauth2.Token{AccessToken: "stale", RefreshToken: "refresh"}, refresher)
+	if err := repo.connections["conn-1"].MarkRequiresReconnect(domain.ReconnectReasonGrantRevoked, tokenManagerNow); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+
+	_, err := manager.TokenSource(context.Background(), "conn-1").Token()
+	if !errors.Is(err, domain.ErrRequiresReconnect) || refresher.calls != 0 {
+		t.Fatalf("expected ErrRequiresReconnect without refreshing, got %v after %d refreshes", err, refresher.calls)
+	}
+}
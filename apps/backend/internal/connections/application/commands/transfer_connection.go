@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+type TransferConnectionCommand struct {
+	repo     ports.ConnectionRepository
+	recorder audit.Recorder
+	now      func() time.Time
+}
+
+func NewTransferConnectionCommand(repo ports.ConnectionRepository, recorder audit.Recorder) *TransferConnectionCommand {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
+
+	return &TransferConnectionCommand{repo: repo, recorder: recorder, now: time.Now}
+}
+
+func (cmd *TransferConnectionCommand) Execute(ctx context.Context, connectionID, ownerUserID, reason string) error {
+	conn, err := cmd.repo.GetByID(ctx, connectionID)
+	if err != nil {
+		return fmt.Errorf("get connection %s: %w", connectionID, err)
+	}
+	if conn == nil {
+		return domain.ErrConnectionNotFound
+	}
+
+	before := conn.AuditSnapshot()
+	if err := conn.TransferTo(ownerUserID, cmd.now()); err != nil {
+		return err
+	}
+
+	// Recorded before it is stored, so the change is never saved untraced.
+	err = cmd.recorder.Record(ctx, audit.Event{
+		Action:     audit.ActionConnectionTransferred,
+		TargetType: audit.TargetConnection,
+		TargetID:   conn.ID,
+		Before:     before,
+		After:      conn.AuditSnapshot(),
+		Metadata:   map[string]any{"reason": reason},
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.repo.Upsert(ctx, conn)
+}
@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+func TestTransferConnectionDropsCredentialsAndRecordsNewOwner(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "user-1"
+
+	if err := NewTransferConnectionCommand(repo, recorder).Execute(context.Background(), "conn-1", "owner-1", "owner_erased"); err != nil {
+		t.Fatalf("transfer failed: %v", err)
+	}
+
+	conn := repo.connections["conn-1"]
+	if conn.OwnerUserID != "owner-1" || conn.Status != domain.ConnectionStatusRequiresReconnect || len(conn.EncryptedCredentials) != 0 {
+		t.Fatalf("expected a credential-less connection owned by owner-1, got %+v", conn)
+	}
+	if len(recorder.events) != 1 || recorder.events[0].Action != audit.ActionConnectionTransferred {
+		t.Fatalf("expected a transfer event, got %+v", recorder.events)
+	}
+	if after := recorder.events[0].After.(map[string]any); after["owner_user_id"] != "owner-1" {
+		t.Fatalf("expected the new owner in the event, got %v", after)
+	}
+}
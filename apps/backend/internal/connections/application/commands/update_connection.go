@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+// UpdateConnectionInput leaves nil fields unchanged.
+type UpdateConnectionInput struct {
+	ConnectionID   string
+	Actor          domain.Actor
+	SharingPolicy  *string
+	Paused         *bool
+	IngestionRules *domain.IngestionRules
+	WriteBack      *domain.WriteBackSettings
+}
+
+type UpdateConnectionCommand struct {
+	repo     ports.ConnectionRepository
+	recorder audit.Recorder
+	now      func() time.Time
+}
+
+func NewUpdateConnectionCommand(repo ports.ConnectionRepository, recorder audit.Recorder) *UpdateConnectionCommand {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
+
+	return &UpdateConnectionCommand{repo: repo, recorder: recorder, now: time.Now}
+}
+
+func (cmd *UpdateConnectionCommand) Execute(ctx context.Context, input UpdateConnectionInput) (*domain.Connection, error) {
+	conn, err := cmd.repo.GetByID(ctx, input.ConnectionID)
+	if err != nil {
+		return nil, fmt.Errorf("get connection %s: %w", input.ConnectionID, err)
+	}
+	if conn == nil {
+		return nil, domain.ErrConnectionNotFound
+	}
+	if input.SharingPolicy != nil {
+		if err := authorize(conn, input.Actor, domain.AccessShare); err != nil {
+			return nil, err
+		}
+	}
+	if input.Paused != nil {
+		if err := authorize(conn, input.Actor, domain.AccessChangeStatus); err != nil {
+			return nil, err
+		}
+	}
+	if input.IngestionRules != nil || input.WriteBack != nil {
+		if err := authorize(conn, input.Actor, domain.AccessConfigure); err != nil {
+			return nil, err
+		}
+	}
+
+	before := conn.AuditSnapshot()
+	previousRules := conn.IngestionRules
+	previousWriteBack := conn.WriteBack
+	now := cmd.now()
+	if input.SharingPolicy != nil {
+		if err := conn.UpdateSharingPolicy(*input.SharingPolicy, now); err != nil {
+			return nil, err
+		}
+	}
+	if input.Paused != nil {
+		if *input.Paused {
+			err = conn.Pause(now)
+		} else {
+			err = conn.Resume(now)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if input.IngestionRules != nil {
+		if err := conn.UpdateIngestionRules(*input.IngestionRules, now); err != nil {
+			return nil, err
+		}
+	}
+	if input.WriteBack != nil {
+		if err := conn.UpdateWriteBack(*input.WriteBack, now); err != nil {
+			return nil, err
+		}
+	}
+
+	after := conn.AuditSnapshot()
+	settings := domain.ConnectionSettings{UpdatedAt: conn.UpdatedAt}
+	if before["sharing_policy"] != after["sharing_policy"] {
+		settings.SharingPolicy = &conn.SharingPolicy
+	}
+	if before["status"] != after["status"] {
+		settings.Status = &conn.Status
+	}
+	if !previousRules.Equal(conn.IngestionRules) {
+		settings.IngestionRules = &conn.IngestionRules
+	}
+	if previousWriteBack != conn.WriteBack {
+		settings.WriteBack = &conn.WriteBack
+	}
+	if settings.SharingPolicy == nil && settings.Status == nil && settings.IngestionRules == nil && settings.WriteBack == nil {
+		return conn, nil
+	}
+
+	// Recorded before it is stored, so the change is never saved untraced.
+	err = cmd.recorder.Record(ctx, audit.Event{
+		Action:     audit.ActionConnectionUpdated,
+		TargetType: audit.TargetConnection,
+		TargetID:   conn.ID,
+		Before:     before,
+		After:      after,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.repo.UpdateSettings(ctx, conn.ID, settings); err != nil {
+		return nil, err
+	}
+
+	return conn, nil
+}
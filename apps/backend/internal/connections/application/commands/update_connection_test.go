@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+type prefixCipher struct{}
+
+func (prefixCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	return append([]byte("enc:"), plaintext...), nil
+}
+
+func (prefixCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	return ciphertext[len("enc:"):], nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestUpdateConnectionLetsTheOwnerShareAndPause(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "user-1"
+	repo.connections["conn-1"].SharingPolicy = domain.SharingPolicyPrivate
+
+	conn, err := NewUpdateConnectionCommand(repo, recorder).Execute(context.Background(), UpdateConnectionInput{
+		ConnectionID:  "conn-1",
+		Actor:         domain.Actor{UserID: "user-1"},
+		SharingPolicy: ptr(domain.SharingPolicyTenantAll),
+		Paused:        ptr(true),
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	if conn.SharingPolicy != domain.SharingPolicyTenantAll || conn.Status != domain.ConnectionStatusPaused {
+		t.Fatalf("expected a shared, paused connection, got %s %s", conn.SharingPolicy, conn.Status)
+	}
+	if len(recorder.events) != 1 || recorder.events[0].Action != audit.ActionConnectionUpdated {
+		t.Fatalf("expected an update event, got %+v", recorder.events)
+	}
+}
+
+func TestUpdateConnectionKeepsSharingWithTheOwner(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "user-1"
+	cmd := NewUpdateConnectionCommand(repo, recorder)
+	admin := domain.Actor{UserID: "admin-1", CanManage: true}
+
+	_, err := cmd.Execute(context.Background(), UpdateConnectionInput{ConnectionID: "conn-1", Actor: admin, SharingPolicy: ptr(domain.SharingPolicyTenantAll)})
+	if !errors.Is(err, domain.ErrConnectionForbidden) {
+		t.Fatalf("expected ErrConnectionForbidden, got %v", err)
+	}
+
+	if _, err := cmd.Execute(context.Background(), UpdateConnectionInput{ConnectionID: "conn-1", Actor: admin, Paused: ptr(true)}); err != nil {
+		t.Fatalf("expected a manager to pause the connection, got %v", err)
+	}
+
+	// Another member cannot even see a private connection.
+	_, err = cmd.Execute(context.Background(), UpdateConnectionInput{ConnectionID: "conn-1", Actor: domain.Actor{UserID: "user-2"}, Paused: ptr(false)})
+	if !errors.Is(err, domain.ErrConnectionNotFound) {
+		t.Fatalf("expected ErrConnectionNotFound for another member, got %v", err)
+	}
+}
+
+func TestUpdateConnectionReplacesIngestionRules(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "user-1"
+	cmd := NewUpdateConnectionCommand(repo, recorder)
+	admin := domain.Actor{UserID: "admin-1", CanManage: true}
+	rules := domain.IngestionRules{AllowedSenders: []string{" @ACME.com ", "acme.com"}, MaxAgeDays: 30}
+
+	conn, err := cmd.Execute(context.Background(), UpdateConnectionInput{ConnectionID: "conn-1", Actor: admin, IngestionRules: &rules})
+	if err != nil {
+		t.Fatalf("expected a manager to configure the connection, got %v", err)
+	}
+	if len(conn.IngestionRules.AllowedSenders) != 1 || conn.IngestionRules.AllowedSenders[0] != "acme.com" {
+		t.Fatalf("expected normalized senders, got %v", conn.IngestionRules.AllowedSenders)
+	}
+
+	// Saving the same rules again is not a change.
+	if _, err := cmd.Execute(context.Background(), UpdateConnectionInput{ConnectionID: "conn-1", Actor: admin, IngestionRules: &rules}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if len(recorder.events) != 1 {
+		t.Fatalf("expected one update event, got %d", len(recorder.events))
+	}
+
+	invalid := domain.IngestionRules{SubjectPatterns: []string{"factura("}}
+	_, err = cmd.Execute(context.Background(), UpdateConnectionInput{ConnectionID: "conn-1", Actor: admin, IngestionRules: &invalid})
+	if !errors.Is(err, domain.ErrInvalidIngestionRules) {
+		t.Fatalf("expected ErrInvalidIngestionRules, got %v", err)
+	}
+}
+
+func TestUpdateConnectionCannotResumeConnectionThatRequiresReconnect(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "user-1"
+	repo.connections["conn-1"].Status = domain.ConnectionStatusRequiresReconnect
+
+	_, err := NewUpdateConnectionCommand(repo, recorder).Execute(context.Background(), UpdateConnectionInput{
+		ConnectionID: "conn-1",
+		Actor:        domain.Actor{UserID: "user-1"},
+		Paused:       ptr(false),
+	})
+	if !errors.Is(err, domain.ErrInvalidStatusTransition) || len(recorder.events) != 0 {
+		t.Fatalf("expected ErrInvalidStatusTransition without events, got %v", err)
+	}
+}
+
+func TestReconnectConnectionReplacesCredentialsInPlace(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	conn := repo.connections["conn-1"]
+	conn.OwnerUserID = "user-1"
+	conn.ProviderAccountEmail = "facturas@acme.test"
+	conn.Status = domain.ConnectionStatusRequiresReconnect
+	cmd := NewReconnectConnectionCommand(repo, NewCredentialsService(prefixCipher{}), recorder)
+
+	_, err := cmd.Execute(context.Background(), ReconnectConnectionInput{
+		ConnectionID:         "conn-1",
+		Actor:                domain.Actor{UserID: "user-1"},
+		ProviderAccountEmail: "otra@acme.test",
+		Credentials:          []byte("token"),
+	})
+	if !errors.Is(err, domain.ErrReconnectAccountMismatch) {
+		t.Fatalf("expected ErrReconnectAccountMismatch, got %v", err)
+	}
+
+	reconnected, err := cmd.Execute(context.Background(), ReconnectConnectionInput{
+		ConnectionID:         "conn-1",
+		Actor:                domain.Actor{UserID: "user-1"},
+		ProviderAccountEmail: "Facturas@acme.test",
+		Credentials:          []byte("token"),
+		GrantedScopes:        []string{"email"},
+	})
+	if err != nil {
+		t.Fatalf("reconnect failed: %v", err)
+	}
+
+	if len(repo.connections) != 1 || reconnected.ID != "conn-1" || reconnected.Status != domain.ConnectionStatusActive {
+		t.Fatalf("expected the same connection to be active again, got %+v", reconnected)
+	}
+	if string(reconnected.EncryptedCredentials) != "enc:token" {
+		t.Fatalf("expected the new credentials to be stored encrypted, got %q", reconnected.EncryptedCredentials)
+	}
+	if len(recorder.events) != 1 || recorder.events[0].Action != audit.ActionConnectionReconnected || recorder.events[0].ActorUserID != "user-1" {
+		t.Fatalf("expected a reconnect event by the owner, got %+v", recorder.events)
+	}
+}
+
+func TestReconnectConnectionIsOwnerOnly(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "user-1"
+	repo.connections["conn-1"].Status = domain.ConnectionStatusRequiresReconnect
+	cmd := NewReconnectConnectionCommand(repo, NewCredentialsService(prefixCipher{}), recorder)
+
+	err := cmd.Authorize(context.Background(), "conn-1", domain.Actor{UserID: "admin-1", CanManage: true})
+	if !errors.Is(err, domain.ErrConnectionForbidden) {
+		t.Fatalf("expected ErrConnectionForbidden, got %v", err)
+	}
+
+	repo.connections["conn-1"].Status = domain.ConnectionStatusActive
+	err = cmd.Authorize(context.Background(), "conn-1", domain.Actor{UserID: "user-1"})
+	if !errors.Is(err, domain.ErrReconnectNotRequired) {
+		t.Fatalf("expected ErrReconnectNotRequired, got %v", err)
+	}
+}
+
+// staleReads hands out copies and refreshes the stored token right after,
+// as the token manager may do while a command runs.
+type staleReads struct {
+	*memoryConnections
+}
+
+func (r staleReads) GetByID(ctx context.Context, id string) (*domain.Connection, error) {
+	read := *r.connections[id]
+	r.connections[id].EncryptedCredentials = []byte("enc:refreshed")
+	return &read, nil
+}
+
+func TestUpdateConnectionKeepsCredentialsRefreshedMeanwhile(t *testing.T) {
+	repo, recorder := newConnectionsFixture()
+	repo.connections["conn-1"].OwnerUserID = "user-1"
+	repo.connections["conn-1"].EncryptedCredentials = []byte("enc:stale")
+
+	_, err := NewUpdateConnectionCommand(staleReads{repo}, recorder).Execute(context.Background(), UpdateConnectionInput{
+		ConnectionID: "conn-1",
+		Actor:        domain.Actor{UserID: "user-1"},
+		Paused:       ptr(true),
+	})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	stored := repo.connections["conn-1"]
+	if stored.Status != domain.ConnectionStatusPaused || string(stored.EncryptedCredentials) != "enc:refreshed" || repo.upserts != 0 {
+		t.Fatalf("expected only the status written, got %s %q after %d upserts", stored.Status, stored.EncryptedCredentials, repo.upserts)
+	}
+}
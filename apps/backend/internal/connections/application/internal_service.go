@@ -4,13 +4,29 @@ import (
 	"context"
 
 	"github.com/bowerbird/internal/connections/application/queries"
+	"github.com/bowerbird/internal/connections/domain"
+	"golang.org/x/oauth2"
 )
 
 type ConnectionInfo = queries.ConnectionInfo
 
 type InternalService interface {
 	GetActiveConnections(ctx context.Context) ([]ConnectionInfo, error)
+	// FindActiveConnection reports found as false when the connection does
+	// not exist or is not active.
+	FindActiveConnection(ctx context.Context, connectionID string) (ConnectionInfo, bool, error)
 	DecryptCredentials(ctx context.Context, connectionID string) ([]byte, error)
+	// TokenSource yields valid provider tokens for the connection, refreshing
+	// and persisting them as needed. Once the grant is unusable it returns a
+	// *domain.RequiresReconnectError.
+	TokenSource(ctx context.Context, connectionID string) oauth2.TokenSource
 	MarkRequiresReconnect(ctx context.Context, connectionID, reason string) error
 	GetSharingPolicy(ctx context.Context, connectionID string) (string, error)
+	ListOwnedConnections(ctx context.Context, ownerUserID string) ([]ConnectionInfo, error)
+	RevokeCredentials(ctx context.Context, connectionID string) error
+	TransferConnection(ctx context.Context, connectionID, ownerUserID, reason string) error
+	DeleteConnection(ctx context.Context, connectionID string) error
+	// ListAccessibleConnections lists, whatever their status, the
+	// connections the actor may access in the given way.
+	ListAccessibleConnections(ctx context.Context, actor domain.Actor, access domain.Access) ([]ConnectionInfo, error)
 }
@@ -4,28 +4,48 @@ import (
 	"context"
 
 	"github.com/bowerbird/internal/connections/application/commands"
+	"github.com/bowerbird/internal/connections/application/ports"
 	"github.com/bowerbird/internal/connections/application/queries"
 	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
+	"golang.org/x/oauth2"
 )
 
 type internalService struct {
 	getActiveConnections *queries.GetActiveConnectionsQuery
+	findActiveConnection *queries.FindActiveConnectionQuery
 	decryptCredentials   *queries.DecryptCredentialsQuery
+	tokens               *commands.TokenManager
 	markReconnect        *commands.MarkRequiresReconnectCommand
 	getSharingPolicy     *queries.GetSharingPolicyQuery
+	listOwnedConnections *queries.ListOwnedConnectionsQuery
+	revokeCredentials    *commands.RevokeCredentialsCommand
+	transferConnection   *commands.TransferConnectionCommand
+	deleteConnection     *commands.DeleteConnectionCommand
+	listAccessible       *queries.ListAccessibleConnectionsQuery
 }
 
 func NewInternalService(
 	repo domain.Repository,
 	credentialsService *CredentialsService,
+	revoker ports.TokenRevoker,
+	refresher ports.TokenRefresher,
+	recorder audit.Recorder,
 ) InternalService {
-	app := NewApplication(repo, credentialsService)
+	app := NewApplication(repo, credentialsService, revoker, refresher, recorder)
 
 	return &internalService{
 		getActiveConnections: app.Queries.GetActiveConnections,
+		findActiveConnection: app.Queries.FindActiveConnection,
 		decryptCredentials:   app.Queries.DecryptCredentials,
+		tokens:               app.Commands.Tokens,
 		markReconnect:        app.Commands.MarkRequiresReconnect,
 		getSharingPolicy:     app.Queries.GetSharingPolicy,
+		listOwnedConnections: app.Queries.ListOwnedConnections,
+		revokeCredentials:    app.Commands.RevokeCredentials,
+		transferConnection:   app.Commands.TransferConnection,
+		deleteConnection:     app.Commands.DeleteConnection,
+		listAccessible:       app.Queries.ListAccessibleConnections,
 	}
 }
 
@@ -33,10 +53,18 @@ func (s *internalService) GetActiveConnections(ctx context.Context) ([]Connectio
 	return s.getActiveConnections.Execute(ctx)
 }
 
+func (s *internalService) FindActiveConnection(ctx context.Context, connectionID string) (ConnectionInfo, bool, error) {
+	return s.findActiveConnection.Execute(ctx, connectionID)
+}
+
 func (s *internalService) DecryptCredentials(ctx context.Context, connectionID string) ([]byte, error) {
 	return s.decryptCredentials.Execute(ctx, connectionID)
 }
 
+func (s *internalService) TokenSource(ctx context.Context, connectionID string) oauth2.TokenSource {
+	return s.tokens.TokenSource(ctx, connectionID)
+}
+
 func (s *internalService) MarkRequiresReconnect(ctx context.Context, connectionID, reason string) error {
 	return s.markReconnect.Execute(ctx, connectionID, reason)
 }
@@ -44,3 +72,23 @@ func (s *internalService) MarkRequiresReconnect(ctx context.Context, connectionI
 func (s *internalService) GetSharingPolicy(ctx context.Context, connectionID string) (string, error) {
 	return s.getSharingPolicy.Execute(ctx, connectionID)
 }
+
+func (s *internalService) ListOwnedConnections(ctx context.Context, ownerUserID string) ([]ConnectionInfo, error) {
+	return s.listOwnedConnections.Execute(ctx, ownerUserID)
+}
+
+func (s *internalService) RevokeCredentials(ctx context.Context, connectionID string) error {
+	return s.revokeCredentials.Execute(ctx, connectionID)
+}
+
+func (s *internalService) TransferConnection(ctx context.Context, connectionID, ownerUserID, reason string) error {
+	return s.transferConnection.Execute(ctx, connectionID, ownerUserID, reason)
+}
+
+func (s *internalService) DeleteConnection(ctx context.Context, connectionID string) error {
+	return s.deleteConnection.Execute(ctx, connectionID)
+}
+
+func (s *internalService) ListAccessibleConnections(ctx context.Context, actor domain.Actor, access domain.Access) ([]ConnectionInfo, error) {
+	return s.listAccessible.Execute(ctx, actor, access)
+}
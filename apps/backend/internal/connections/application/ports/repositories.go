@@ -2,12 +2,72 @@ package ports
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/bowerbird/internal/connections/domain"
+	"golang.org/x/oauth2"
 )
 
 type ConnectionRepository interface {
+	ListAll(ctx context.Context) ([]*domain.Connection, error)
 	ListActive(ctx context.Context) ([]*domain.Connection, error)
+	ListByOwner(ctx context.Context, ownerUserID string) ([]*domain.Connection, error)
 	GetByID(ctx context.Context, id string) (*domain.Connection, error)
 	Upsert(ctx context.Context, conn *domain.Connection) error
+	UpdateSettings(ctx context.Context, id string, settings domain.ConnectionSettings) error
+	UpdateGrant(ctx context.Context, conn *domain.Connection) (bool, error)
+	UpdateCredentials(ctx context.Context, id string, encryptedCredentials []byte, at time.Time) error
+	ReplaceCredentials(ctx context.Context, id string, current, replacement []byte) (bool, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// TenantDatabase is a provisioned tenant and the database that holds it.
+type TenantDatabase struct {
+	TenantID string
+	DBName   string
+	Status   string
+}
+
+// TenantLister lists the tenants whose databases hold data, whatever the
+// status of the organization.
+type TenantLister interface {
+	ListProvisionedTenants(ctx context.Context) ([]TenantDatabase, error)
+}
+
+// CredentialsRewrapper moves stored ciphertext under the active master key
+// and reports whether it changed. KeyID names the master key that seals
+// ciphertext, "" for the format before envelopes.
+type CredentialsRewrapper interface {
+	Rewrap(ciphertext []byte) ([]byte, bool, error)
+	KeyID(ciphertext []byte) string
+	ActiveKeyID() string
+}
+
+// KeyUsageRecorder keeps the records each master key seals, so a key is not
+// retired while stored records still need it.
+type KeyUsageRecorder interface {
+	RecordKeyUsage(ctx context.Context, scope string, usage map[string]int, complete bool) error
+}
+
+// TokenRevoker invalidates, at the provider, the OAuth grant behind the
+// decrypted credentials of a connection.
+type TokenRevoker interface {
+	Revoke(ctx context.Context, provider string, credentials []byte) error
+}
+
+// ErrGrantRevoked is returned by a TokenRefresher when the provider rejects
+// the refresh token itself (OAuth invalid_grant).
+var ErrGrantRevoked = errors.New("oauth grant revoked")
+
+// RefreshedToken is a token issued by a refresh. Scopes lists what the grant
+// covers now, as reported by the provider; it is empty when not reported.
+type RefreshedToken struct {
+	Token  *oauth2.Token
+	Scopes []string
+}
+
+// TokenRefresher exchanges a refresh token for a new access token.
+type TokenRefresher interface {
+	Refresh(ctx context.Context, provider string, token *oauth2.Token) (RefreshedToken, error)
 }
@@ -0,0 +1,35 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+)
+
+type FindActiveConnectionQuery struct {
+	repo ports.ConnectionRepository
+}
+
+func NewFindActiveConnectionQuery(repo ports.ConnectionRepository) *FindActiveConnectionQuery {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+
+	return &FindActiveConnectionQuery{repo: repo}
+}
+
+// Execute reports found as false when the connection does not exist or is
+// not active.
+func (q *FindActiveConnectionQuery) Execute(ctx context.Context, connectionID string) (ConnectionInfo, bool, error) {
+	conn, err := q.repo.GetByID(ctx, connectionID)
+	if err != nil {
+		return ConnectionInfo{}, false, fmt.Errorf("get connection %s: %w", connectionID, err)
+	}
+	if conn == nil || conn.Status != domain.ConnectionStatusActive {
+		return ConnectionInfo{}, false, nil
+	}
+
+	return newConnectionInfo(conn), true, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
 )
 
 type ConnectionInfo struct {
@@ -13,6 +14,24 @@ type ConnectionInfo struct {
 	ProviderAccountEmail string
 	OwnerUserID          string
 	SharingPolicy        string
+	Status               string
+	ReconnectReason      string
+	IngestionRules       domain.IngestionRules
+	WriteBack            domain.WriteBackSettings
+}
+
+func newConnectionInfo(c *domain.Connection) ConnectionInfo {
+	return ConnectionInfo{
+		ID:                   c.ID,
+		Provider:             c.Provider,
+		ProviderAccountEmail: c.ProviderAccountEmail,
+		OwnerUserID:          c.OwnerUserID,
+		SharingPolicy:        c.SharingPolicy,
+		Status:               c.Status,
+		ReconnectReason:      string(c.ReconnectReason),
+		IngestionRules:       c.IngestionRules,
+		WriteBack:            c.WriteBack,
+	}
 }
 
 type GetActiveConnectionsQuery struct {
@@ -35,13 +54,7 @@ func (q *GetActiveConnectionsQuery) Execute(ctx context.Context) ([]ConnectionIn
 
 	result := make([]ConnectionInfo, 0, len(connections))
 	for _, c := range connections {
-		result = append(result, ConnectionInfo{
-			ID:                   c.ID,
-			Provider:             c.Provider,
-			ProviderAccountEmail: c.ProviderAccountEmail,
-			OwnerUserID:          c.OwnerUserID,
-			SharingPolicy:        c.SharingPolicy,
-		})
+		result = append(result, newConnectionInfo(c))
 	}
 
 	return result, nil
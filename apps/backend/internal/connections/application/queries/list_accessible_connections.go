@@ -0,0 +1,39 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/domain"
+)
+
+type ListAccessibleConnectionsQuery struct {
+	repo ports.ConnectionRepository
+}
+
+func NewListAccessibleConnectionsQuery(repo ports.ConnectionRepository) *ListAccessibleConnectionsQuery {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+
+	return &ListAccessibleConnectionsQuery{repo: repo}
+}
+
+// Execute lists the connections, whatever their status, that the actor may
+// access in the given way.
+func (q *ListAccessibleConnectionsQuery) Execute(ctx context.Context, actor domain.Actor, access domain.Access) ([]ConnectionInfo, error) {
+	connections, err := q.repo.ListAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list connections: %w", err)
+	}
+
+	result := make([]ConnectionInfo, 0, len(connections))
+	for _, c := range connections {
+		if c.Allows(actor, access) {
+			result = append(result, newConnectionInfo(c))
+		}
+	}
+
+	return result, nil
+}
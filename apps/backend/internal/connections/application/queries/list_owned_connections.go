@@ -0,0 +1,35 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bowerbird/internal/connections/application/ports"
+)
+
+type ListOwnedConnectionsQuery struct {
+	repo ports.ConnectionRepository
+}
+
+func NewListOwnedConnectionsQuery(repo ports.ConnectionRepository) *ListOwnedConnectionsQuery {
+	if repo == nil {
+		panic("connection repository is required")
+	}
+
+	return &ListOwnedConnectionsQuery{repo: repo}
+}
+
+// Execute lists the user's connections whatever their status.
+func (q *ListOwnedConnectionsQuery) Execute(ctx context.Context, ownerUserID string) ([]ConnectionInfo, error) {
+	connections, err := q.repo.ListByOwner(ctx, ownerUserID)
+	if err != nil {
+		return nil, fmt.Errorf("list connections of %s: %w", ownerUserID, err)
+	}
+
+	result := make([]ConnectionInfo, 0, len(connections))
+	for _, c := range connections {
+		result = append(result, newConnectionInfo(c))
+	}
+
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package domain
+
+// ManagePermission is granted to tenant admins. It lets them see every
+// connection, pause, resume and delete it, but not read a private mailbox
+// or act on the owner's grant.
+const ManagePermission = "connections:manage"
+
+// Actor is who acts on a connection. UserID is empty for API keys, which act
+// through their scopes only and never as the owner of a mailbox.
+type Actor struct {
+	UserID    string
+	CanManage bool
+}
+
+// Access is something an actor does with a connection.
+type Access string
+
+const (
+	AccessView         Access = "view"
+	AccessReadMessages Access = "read_messages"
+	AccessSync         Access = "sync"
+	AccessChangeStatus Access = "change_status"
+	// AccessConfigure changes what the connection ingests.
+	AccessConfigure Access = "configure"
+	AccessShare     Access = "share"
+	AccessReconnect Access = "reconnect"
+	AccessDelete    Access = "delete"
+)
+
+// Allows holds the connection access rules. The owner may do anything; a
+// shared connection lets every member read and sync it; managers administer
+// connections, including what they ingest, without reaching into private
+// mail. Sharing and reconnecting change the owner's grant, so only the owner
+// may do them.
+func (c *Connection) Allows(actor Actor, access Access) bool {
+	if c == nil {
+		return false
+	}
+
+	owner := actor.UserID != "" && c.OwnerUserID == actor.UserID
+	if owner {
+		return true
+	}
+	shared := c.SharingPolicy == SharingPolicyTenantAll
+
+	switch access {
+	case AccessView:
+		return shared || actor.CanManage
+	case AccessReadMessages, AccessSync:
+		return shared
+	case AccessChangeStatus, AccessConfigure, AccessDelete:
+		return actor.CanManage
+	default:
+		return false
+	}
+}
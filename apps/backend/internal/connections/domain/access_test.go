@@ -0,0 +1,46 @@
+package domain
+
+import "testing"
+
+func TestConnectionAllows(t *testing.T) {
+	private := &Connection{OwnerUserID: "owner", SharingPolicy: SharingPolicyPrivate}
+	shared := &Connection{OwnerUserID: "owner", SharingPolicy: SharingPolicyTenantAll}
+
+	owner := Actor{UserID: "owner"}
+	member := Actor{UserID: "member"}
+	manager := Actor{UserID: "admin", CanManage: true}
+	apiKey := Actor{CanManage: true}
+
+	tests := []struct {
+		name       string
+		connection *Connection
+		actor      Actor
+		access     Access
+		want       bool
+	}{
+		{"owner reconnects private", private, owner, AccessReconnect, true},
+		{"owner shares private", private, owner, AccessShare, true},
+		{"member cannot see private", private, member, AccessView, false},
+		{"member cannot sync private", private, member, AccessSync, false},
+		{"member reads shared", shared, member, AccessReadMessages, true},
+		{"member syncs shared", shared, member, AccessSync, true},
+		{"member cannot pause shared", shared, member, AccessChangeStatus, false},
+		{"member cannot delete shared", shared, member, AccessDelete, false},
+		{"manager sees private", private, manager, AccessView, true},
+		{"manager cannot read private", private, manager, AccessReadMessages, false},
+		{"manager pauses private", private, manager, AccessChangeStatus, true},
+		{"manager deletes private", private, manager, AccessDelete, true},
+		{"manager cannot share private", private, manager, AccessShare, false},
+		{"manager cannot reconnect shared", shared, manager, AccessReconnect, false},
+		{"api key is never the owner", &Connection{SharingPolicy: SharingPolicyPrivate}, apiKey, AccessReadMessages, false},
+		{"nil connection", nil, owner, AccessView, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.connection.Allows(tt.actor, tt.access); got != tt.want {
+				t.Fatalf("Allows(%s) = %v, want %v", tt.access, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	maxIngestionRuleEntries = 50
+	maxSubjectPatternLength = 200
+	// MaxIngestionAgeDays caps max_age_days at ten years.
+	MaxIngestionAgeDays = 3650
+)
+
+var ErrInvalidIngestionRules = errors.New("invalid ingestion rules")
+
+// IngestionRules narrow which mail a connection syncs. The zero value syncs
+// everything the provider returns.
+type IngestionRules struct {
+	// AllowedSenders, when set, only admits these senders. Entries are an
+	// address ("billing@acme.com") or a domain ("acme.com"), which also
+	// covers its subdomains.
+	AllowedSenders []string `json:"allowed_senders,omitempty"`
+	// BlockedSenders are never admitted, even if allowed.
+	BlockedSenders []string `json:"blocked_senders,omitempty"`
+	// Labels scopes the sync to mail carrying any of these labels or
+	// folders, by name.
+	Labels []string `json:"labels,omitempty"`
+	// SubjectPatterns are case-insensitive regular expressions; when set,
+	// the subject has to match one of them.
+	SubjectPatterns    []string `json:"subject_patterns,omitempty"`
+	RequireAttachments bool     `json:"require_attachments,omitempty"`
+	// MaxAgeDays skips mail older than this many days; 0 disables it.
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+}
+
+// IngestionCandidate is what is known about a message before it is
+// downloaded. HasAttachments is nil until the message structure is known.
+type IngestionCandidate struct {
+	Sender         string
+	Subject        string
+	ReceivedAt     *time.Time
+	HasAttachments *bool
+}
+
+// Normalize trims, lowercases and deduplicates the sender and label lists.
+func (r IngestionRules) Normalize() IngestionRules {
+	r.AllowedSenders = normalizeSenders(r.AllowedSenders)
+	r.BlockedSenders = normalizeSenders(r.BlockedSenders)
+	r.Labels = normalizeList(r.Labels, strings.TrimSpace)
+	r.SubjectPatterns = normalizeList(r.SubjectPatterns, strings.TrimSpace)
+	return r
+}
+
+func (r IngestionRules) Validate() error {
+	for name, entries := range map[string][]string{
+		"allowed_senders":  r.AllowedSenders,
+		"blocked_senders":  r.BlockedSenders,
+		"labels":           r.Labels,
+		"subject_patterns": r.SubjectPatterns,
+	} {
+		if len(entries) > maxIngestionRuleEntries {
+			return fmt.Errorf("%w: %s accepts at most %d entries", ErrInvalidIngestionRules, name, maxIngestionRuleEntries)
+		}
+	}
+	for _, sender := range slices.Concat(r.AllowedSenders, r.BlockedSenders) {
+		if !validSenderEntry(sender) {
+			return fmt.Errorf("%w: %q is not an email address or a domain", ErrInvalidIngestionRules, sender)
+		}
+	}
+	for _, pattern := range r.SubjectPatterns {
+		if len(pattern) > maxSubjectPatternLength {
+			return fmt.Errorf("%w: subject patterns accept at most %d characters", ErrInvalidIngestionRules, maxSubjectPatternLength)
+		}
+		if _, err := compileSubjectPattern(pattern); err != nil {
+			return fmt.Errorf("%w: subject pattern %q: %v", ErrInvalidIngestionRules, pattern, err)
+		}
+	}
+	if r.MaxAgeDays < 0 || r.MaxAgeDays > MaxIngestionAgeDays {
+		return fmt.Errorf("%w: max_age_days must be between 0 and %d", ErrInvalidIngestionRules, MaxIngestionAgeDays)
+	}
+	return nil
+}
+
+func (r IngestionRules) IsZero() bool {
+	return r.Equal(IngestionRules{})
+}
+
+func (r IngestionRules) Equal(other IngestionRules) bool {
+	return slices.Equal(r.AllowedSenders, other.AllowedSenders) &&
+		slices.Equal(r.BlockedSenders, other.BlockedSenders) &&
+		slices.Equal(r.Labels, other.Labels) &&
+		slices.Equal(r.SubjectPatterns, other.SubjectPatterns) &&
+		r.RequireAttachments == other.RequireAttachments &&
+		r.MaxAgeDays == other.MaxAgeDays
+}
+
+// IngestionFilter applies IngestionRules to many messages with the subject
+// patterns compiled once.
+type IngestionFilter struct {
+	rules    IngestionRules
+	subjects []*regexp.Regexp
+}
+
+// Filter compiles the rules for matching. Validate is where invalid patterns
+// are reported; one that slipped through matches no subject.
+func (r IngestionRules) Filter() IngestionFilter {
+	filter := IngestionFilter{rules: r}
+	for _, pattern := range r.SubjectPatterns {
+		if re, err := compileSubjectPattern(pattern); err == nil {
+			filter.subjects = append(filter.subjects, re)
+		}
+	}
+	return filter
+}
+
+// ExclusionReason returns why the rules exclude the candidate, or "" when
+// they admit it. Labels are left to the provider query, since messages
+// carry label IDs rather than names.
+func (f IngestionFilter) ExclusionReason(candidate IngestionCandidate, now time.Time) string {
+	r := f.rules
+	sender := senderAddress(candidate.Sender)
+	if slices.ContainsFunc(r.BlockedSenders, func(entry string) bool { return senderMatches(entry, sender) }) {
+		return "blocked_sender"
+	}
+	if len(r.AllowedSenders) > 0 && !slices.ContainsFunc(r.AllowedSenders, func(entry string) bool { return senderMatches(entry, sender) }) {
+		return "sender_not_allowed"
+	}
+	if len(r.SubjectPatterns) > 0 && !slices.ContainsFunc(f.subjects, func(re *regexp.Regexp) bool { return re.MatchString(candidate.Subject) }) {
+		return "subject_not_matched"
+	}
+	if r.MaxAgeDays > 0 && candidate.ReceivedAt != nil && candidate.ReceivedAt.Before(now.AddDate(0, 0, -r.MaxAgeDays)) {
+		return "too_old"
+	}
+	if r.RequireAttachments && candidate.HasAttachments != nil && !*candidate.HasAttachments {
+		return "missing_attachments"
+	}
+	return ""
+}
+
+// UpdateIngestionRules replaces the rules after normalizing and validating
+// them.
+func (c *Connection) UpdateIngestionRules(rules IngestionRules, at time.Time) error {
+	if c == nil {
+		return ErrNilConnection
+	}
+	rules = rules.Normalize()
+	if err := rules.Validate(); err != nil {
+		return err
+	}
+	c.IngestionRules = rules
+	c.UpdatedAt = at.UTC()
+	return nil
+}
+
+// compileSubjectPattern matches subjects regardless of case.
+func compileSubjectPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("(?i)" + pattern)
+}
+
+func normalizeSenders(entries []string) []string {
+	return normalizeList(entries, func(entry string) string {
+		return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(entry)), "@")
+	})
+}
+
+func normalizeList(entries []string, normalize func(string) string) []string {
+	var normalized []string
+	for _, entry := range entries {
+		entry = normalize(entry)
+		if entry != "" && !slices.Contains(normalized, entry) {
+			normalized = append(normalized, entry)
+		}
+	}
+	return normalized
+}
+
+func validSenderEntry(entry string) bool {
+	if strings.Contains(entry, "@") {
+		_, err := mail.ParseAddress(entry)
+		return err == nil
+	}
+	return strings.Contains(entry, ".") && !strings.ContainsAny(entry, " <>\"")
+}
+
+func senderAddress(sender string) string {
+	if address, err := mail.ParseAddress(sender); err == nil {
+		return strings.ToLower(address.Address)
+	}
+	return strings.ToLower(strings.TrimSpace(sender))
+}
+
+func senderMatches(entry, address string) bool {
+	if strings.Contains(entry, "@") {
+		return entry == address
+	}
+	_, domain, ok := strings.Cut(address, "@")
+	return ok && (domain == entry || strings.HasSuffix(domain, "."+entry))
+}
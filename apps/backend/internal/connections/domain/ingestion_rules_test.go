@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIngestionRulesExclusionReason(t *testing.T) {
+	now := time.Date(2026, 5, 25, 12, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -40)
+	withoutAttachments := false
+	rules := IngestionRules{
+		AllowedSenders:     []string{"acme.com", "facturas@proveedor.co"},
+		BlockedSenders:     []string{"marketing.acme.com"},
+		SubjectPatterns:    []string{`factura\s+\d+`, "nota crédito"},
+		RequireAttachments: true,
+		MaxAgeDays:         30,
+	}.Normalize().Filter()
+
+	cases := []struct {
+		name      string
+		candidate IngestionCandidate
+		want      string
+	}{
+		{"admitted subdomain", IngestionCandidate{Sender: "Acme <billing@mail.acme.com>", Subject: "FACTURA 123"}, ""},
+		{"admitted address", IngestionCandidate{Sender: "facturas@proveedor.co", Subject: "Nota Crédito 9"}, ""},
+		{"blocked wins", IngestionCandidate{Sender: "news@marketing.acme.com", Subject: "Factura 1"}, "blocked_sender"},
+		{"personal mail", IngestionCandidate{Sender: "friend@gmail.com", Subject: "Factura 1"}, "sender_not_allowed"},
+		{"lookalike domain", IngestionCandidate{Sender: "billing@notacme.com", Subject: "Factura 1"}, "sender_not_allowed"},
+		{"subject", IngestionCandidate{Sender: "billing@acme.com", Subject: "Newsletter"}, "subject_not_matched"},
+		{"age", IngestionCandidate{Sender: "billing@acme.com", Subject: "Factura 1", ReceivedAt: &old}, "too_old"},
+		{"attachments", IngestionCandidate{Sender: "billing@acme.com", Subject: "Factura 1", HasAttachments: &withoutAttachments}, "missing_attachments"},
+	}
+	for _, tc := range cases {
+		if got := rules.ExclusionReason(tc.candidate, now); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+
+	if reason := (IngestionRules{}).Filter().ExclusionReason(IngestionCandidate{Sender: "anyone@example.com"}, now); reason != "" {
+		t.Fatalf("expected empty rules to admit everything, got %q", reason)
+	}
+
+	// Stored before validation existed: it matches nothing rather than failing.
+	invalid := IngestionRules{SubjectPatterns: []string{"factura("}}.Filter()
+	if reason := invalid.ExclusionReason(IngestionCandidate{Sender: "billing@acme.com", Subject: "factura("}, now); reason != "subject_not_matched" {
+		t.Fatalf("expected an invalid pattern to match nothing, got %q", reason)
+	}
+}
+
+func TestIngestionRulesValidate(t *testing.T) {
+	invalid := []IngestionRules{
+		{AllowedSenders: []string{"not a sender"}},
+		{BlockedSenders: []string{"acme"}},
+		{SubjectPatterns: []string{"factura("}},
+		{MaxAgeDays: -1},
+		{MaxAgeDays: MaxIngestionAgeDays + 1},
+	}
+	for _, rules := range invalid {
+		if err := rules.Normalize().Validate(); !errors.Is(err, ErrInvalidIngestionRules) {
+			t.Errorf("expected %+v to be rejected, got %v", rules, err)
+		}
+	}
+
+	valid := IngestionRules{AllowedSenders: []string{"@Acme.com", "Billing@Proveedor.co"}, Labels: []string{"Facturas"}, MaxAgeDays: 90}
+	if err := valid.Normalize().Validate(); err != nil {
+		t.Fatalf("expected valid rules, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -20,6 +21,15 @@ const (
 var (
 	ErrNilConnection        = errors.New("connection is nil")
 	ErrInvalidSharingPolicy = errors.New("invalid sharing policy")
+	ErrConnectionNotFound   = errors.New("connection not found")
+	ErrConnectionForbidden  = errors.New("not allowed to access this connection")
+	// ErrInvalidStatusTransition covers pausing or resuming a connection
+	// that needs to be reconnected first.
+	ErrInvalidStatusTransition = errors.New("invalid connection status transition")
+	ErrReconnectNotRequired    = errors.New("connection does not require reconnecting")
+	// ErrReconnectAccountMismatch means the user authorized a different
+	// mailbox than the one the connection belongs to.
+	ErrReconnectAccountMismatch = errors.New("reconnected account does not match the connection")
 )
 
 type Connection struct {
@@ -28,19 +38,43 @@ type Connection struct {
 	Provider             string
 	ProviderAccountEmail string
 	Status               string
+	// ReconnectReason is set while the status is requires_reconnect.
+	ReconnectReason      ReconnectReason
 	EncryptedCredentials []byte
 	GrantedScopes        []string
 	SharingPolicy        string
+	IngestionRules       IngestionRules
+	WriteBack            WriteBackSettings
 	RawData              []byte
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
 }
 
-func (c *Connection) MarkRequiresReconnect(reason string, at time.Time) error {
+// AuditSnapshot is the connection as recorded in the audit log; it leaves
+// the credentials and the provider payload out.
+func (c *Connection) AuditSnapshot() map[string]any {
+	if c == nil {
+		return nil
+	}
+	return map[string]any{
+		"owner_user_id":          c.OwnerUserID,
+		"provider":               c.Provider,
+		"provider_account_email": c.ProviderAccountEmail,
+		"status":                 c.Status,
+		"reconnect_reason":       string(c.ReconnectReason),
+		"granted_scopes":         c.GrantedScopes,
+		"sharing_policy":         c.SharingPolicy,
+		"ingestion_rules":        c.IngestionRules,
+		"write_back":             c.WriteBack,
+	}
+}
+
+func (c *Connection) MarkRequiresReconnect(reason ReconnectReason, at time.Time) error {
 	if c == nil {
 		return ErrNilConnection
 	}
 	c.Status = ConnectionStatusRequiresReconnect
+	c.ReconnectReason = reason
 	c.UpdatedAt = at.UTC()
 	return nil
 }
@@ -50,6 +84,72 @@ func (c *Connection) MarkActive(at time.Time) error {
 		return ErrNilConnection
 	}
 	c.Status = ConnectionStatusActive
+	c.ReconnectReason = ""
+	c.UpdatedAt = at.UTC()
+	return nil
+}
+
+// Pause stops syncing until the connection is resumed. A connection that
+// requires reconnecting has to be reconnected instead.
+func (c *Connection) Pause(at time.Time) error {
+	if c == nil {
+		return ErrNilConnection
+	}
+	switch c.Status {
+	case ConnectionStatusPaused:
+		return nil
+	case ConnectionStatusActive:
+		c.Status = ConnectionStatusPaused
+		c.UpdatedAt = at.UTC()
+		return nil
+	default:
+		return ErrInvalidStatusTransition
+	}
+}
+
+func (c *Connection) Resume(at time.Time) error {
+	if c == nil {
+		return ErrNilConnection
+	}
+	switch c.Status {
+	case ConnectionStatusActive:
+		return nil
+	case ConnectionStatusPaused:
+		return c.MarkActive(at)
+	default:
+		return ErrInvalidStatusTransition
+	}
+}
+
+// Reconnect replaces the credentials of a connection that lost its grant.
+// The new grant must belong to the same mailbox, so the connection keeps its
+// ID and synced history.
+func (c *Connection) Reconnect(providerAccountEmail string, encryptedCredentials []byte, grantedScopes []string, at time.Time) error {
+	if c == nil {
+		return ErrNilConnection
+	}
+	if c.Status != ConnectionStatusRequiresReconnect {
+		return ErrReconnectNotRequired
+	}
+	if !strings.EqualFold(c.ProviderAccountEmail, providerAccountEmail) {
+		return ErrReconnectAccountMismatch
+	}
+	c.EncryptedCredentials = encryptedCredentials
+	c.GrantedScopes = grantedScopes
+	return c.MarkActive(at)
+}
+
+// TransferTo hands a shared connection over to another member. The previous
+// owner's grant is dropped with the credentials, so the new owner has to
+// reconnect it before it syncs again.
+func (c *Connection) TransferTo(ownerUserID string, at time.Time) error {
+	if c == nil {
+		return ErrNilConnection
+	}
+	c.OwnerUserID = ownerUserID
+	c.EncryptedCredentials = []byte{}
+	c.Status = ConnectionStatusRequiresReconnect
+	c.ReconnectReason = ReconnectReasonOwnershipTransferred
 	c.UpdatedAt = at.UTC()
 	return nil
 }
@@ -66,11 +166,25 @@ func (c *Connection) UpdateSharingPolicy(policy string, at time.Time) error {
 	return nil
 }
 
+// ConnectionSettings are the settings an update changed; nil fields are
+// left as stored.
+type ConnectionSettings struct {
+	SharingPolicy  *string
+	Status         *string
+	IngestionRules *IngestionRules
+	WriteBack      *WriteBackSettings
+	UpdatedAt      time.Time
+}
+
 type Repository interface {
 	GetByID(ctx context.Context, id string) (*Connection, error)
 	ListAll(ctx context.Context) ([]*Connection, error)
 	ListActive(ctx context.Context) ([]*Connection, error)
 	ListByOwner(ctx context.Context, ownerUserID string) ([]*Connection, error)
 	Upsert(ctx context.Context, conn *Connection) error
+	UpdateSettings(ctx context.Context, id string, settings ConnectionSettings) error
+	UpdateGrant(ctx context.Context, conn *Connection) (bool, error)
+	UpdateCredentials(ctx context.Context, id string, encryptedCredentials []byte, at time.Time) error
+	ReplaceCredentials(ctx context.Context, id string, current, replacement []byte) (bool, error)
 	Delete(ctx context.Context, id string) error
 }
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ReconnectReason says why a connection requires reconnecting; the client
+// uses it to tell the owner what to do.
+type ReconnectReason string
+
+const (
+	// ReconnectReasonGrantRevoked: the provider rejected the refresh token,
+	// e.g. the user revoked access or the grant expired.
+	ReconnectReasonGrantRevoked ReconnectReason = "grant_revoked"
+	// ReconnectReasonMissingRefreshToken: the stored token cannot be
+	// refreshed once it expires.
+	ReconnectReasonMissingRefreshToken ReconnectReason = "missing_refresh_token"
+	// ReconnectReasonScopeDowngraded: the grant no longer covers the scopes
+	// the connection was created with.
+	ReconnectReasonScopeDowngraded ReconnectReason = "scope_downgraded"
+	// ReconnectReasonProviderRejected: the provider rejected a valid-looking
+	// access token.
+	ReconnectReasonProviderRejected ReconnectReason = "provider_rejected"
+	// ReconnectReasonOwnershipTransferred: the connection changed owner and
+	// the previous owner's grant was dropped.
+	ReconnectReasonOwnershipTransferred ReconnectReason = "ownership_transferred"
+)
+
+var ErrRequiresReconnect = errors.New("connection requires reconnecting")
+
+// ParseReconnectReason maps unknown reasons to provider_rejected.
+func ParseReconnectReason(reason string) ReconnectReason {
+	switch r := ReconnectReason(reason); r {
+	case ReconnectReasonGrantRevoked,
+		ReconnectReasonMissingRefreshToken,
+		ReconnectReasonScopeDowngraded,
+		ReconnectReasonProviderRejected,
+		ReconnectReasonOwnershipTransferred:
+		return r
+	default:
+		return ReconnectReasonProviderRejected
+	}
+}
+
+// RequiresReconnectError is returned when a connection's credentials cannot
+// be used until the owner reconnects it. It matches ErrRequiresReconnect.
+type RequiresReconnectError struct {
+	Reason ReconnectReason
+}
+
+func (e *RequiresReconnectError) Error() string {
+	return "connection requires reconnecting: " + string(e.Reason)
+}
+
+func (e *RequiresReconnectError) Is(target error) bool {
+	return target == ErrRequiresReconnect
+}
+
+// scopeAliases maps the short scopes requested at consent to the URLs the
+// provider reports back.
+var scopeAliases = map[string]string{
+	"email":   "https://www.googleapis.com/auth/userinfo.email",
+	"profile": "https://www.googleapis.com/auth/userinfo.profile",
+}
+
+func normalizeScope(scope string) string {
+	if alias, ok := scopeAliases[scope]; ok {
+		return alias
+	}
+	return scope
+}
+
+// MissingScopes returns the expected scopes the grant does not cover.
+func MissingScopes(expected, granted []string) []string {
+	normalized := make([]string, 0, len(granted))
+	for _, scope := range granted {
+		normalized = append(normalized, normalizeScope(strings.TrimSpace(scope)))
+	}
+
+	var missing []string
+	for _, scope := range expected {
+		if !slices.Contains(normalized, normalizeScope(scope)) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMissingScopes(t *testing.T) {
+	expected := []string{"email", "https://www.googleapis.com/auth/gmail.modify"}
+
+	if missing := MissingScopes(expected, []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/gmail.modify", "openid"}); len(missing) != 0 {
+		t.Fatalf("expected aliases and extra scopes to be accepted, got %v", missing)
+	}
+	if missing := MissingScopes(expected, []string{"email"}); !slices.Equal(missing, []string{"https://www.googleapis.com/auth/gmail.modify"}) {
+		t.Fatalf("expected the gmail scope to be missing, got %v", missing)
+	}
+}
+
+func TestReconnectReasonLifecycle(t *testing.T) {
+	conn := &Connection{Status: ConnectionStatusActive}
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := conn.MarkRequiresReconnect(ParseReconnectReason("status 401"), at); err != nil {
+		t.Fatalf("mark failed: %v", err)
+	}
+	if conn.ReconnectReason != ReconnectReasonProviderRejected {
+		t.Fatalf("expected unknown reasons to become provider_rejected, got %s", conn.ReconnectReason)
+	}
+
+	if err := conn.MarkActive(at); err != nil {
+		t.Fatalf("mark active failed: %v", err)
+	}
+	if conn.ReconnectReason != "" {
+		t.Fatalf("expected the reason to be cleared, got %s", conn.ReconnectReason)
+	}
+
+	if !errors.Is(&RequiresReconnectError{Reason: ReconnectReasonGrantRevoked}, ErrRequiresReconnect) {
+		t.Fatal("expected RequiresReconnectError to match ErrRequiresReconnect")
+	}
+}
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultProcessedLabel = "Bowerbird/Procesada"
+	DefaultErrorLabel     = "Bowerbird/Error"
+	maxWriteBackLabelLen  = 225
+)
+
+var ErrInvalidWriteBackSettings = errors.New("invalid write-back settings")
+
+// WriteBackSettings control how processed mail is marked in the mailbox.
+// The zero value leaves the mailbox untouched.
+type WriteBackSettings struct {
+	Enabled bool `json:"enabled"`
+	// ProcessedLabel goes on messages an invoice was extracted from. Mail
+	// that is not an invoice is left untouched.
+	ProcessedLabel string `json:"processed_label,omitempty"`
+	// ErrorLabel goes on messages that looked like invoices but failed.
+	ErrorLabel string `json:"error_label,omitempty"`
+	// Archive removes processed messages from the inbox. Failed messages
+	// stay in the inbox so they can be reviewed.
+	Archive bool `json:"archive,omitempty"`
+}
+
+// Normalize trims the labels and fills in the defaults when enabled.
+func (s WriteBackSettings) Normalize() WriteBackSettings {
+	s.ProcessedLabel = strings.TrimSpace(s.ProcessedLabel)
+	s.ErrorLabel = strings.TrimSpace(s.ErrorLabel)
+	if !s.Enabled {
+		return s
+	}
+	if s.ProcessedLabel == "" {
+		s.ProcessedLabel = DefaultProcessedLabel
+	}
+	if s.ErrorLabel == "" {
+		s.ErrorLabel = DefaultErrorLabel
+	}
+	return s
+}
+
+func (s WriteBackSettings) Validate() error {
+	for name, label := range map[string]string{
+		"processed_label": s.ProcessedLabel,
+		"error_label":     s.ErrorLabel,
+	} {
+		if len(label) > maxWriteBackLabelLen {
+			return fmt.Errorf("%w: %s accepts at most %d characters", ErrInvalidWriteBackSettings, name, maxWriteBackLabelLen)
+		}
+		if strings.HasPrefix(label, "/") || strings.HasSuffix(label, "/") || strings.Contains(label, "//") {
+			return fmt.Errorf("%w: %s %q is not a valid label name", ErrInvalidWriteBackSettings, name, label)
+		}
+	}
+	if s.Enabled && strings.EqualFold(s.ProcessedLabel, s.ErrorLabel) {
+		return fmt.Errorf("%w: processed_label and error_label must differ", ErrInvalidWriteBackSettings)
+	}
+	return nil
+}
+
+// LabelFor returns the label for a processing outcome; failed is true when
+// the message could not be processed.
+func (s WriteBackSettings) LabelFor(failed bool) string {
+	if failed {
+		return s.ErrorLabel
+	}
+	return s.ProcessedLabel
+}
+
+// UpdateWriteBack replaces the write-back settings after normalizing and
+// validating them.
+func (c *Connection) UpdateWriteBack(settings WriteBackSettings, at time.Time) error {
+	if c == nil {
+		return ErrNilConnection
+	}
+	settings = settings.Normalize()
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	c.WriteBack = settings
+	c.UpdatedAt = at.UTC()
+	return nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUpdateWriteBackFillsDefaultLabels(t *testing.T) {
+	conn := &Connection{ID: "conn-1"}
+	if err := conn.UpdateWriteBack(WriteBackSettings{Enabled: true, Archive: true}, time.Now()); err != nil {
+		t.Fatalf("update write-back: %v", err)
+	}
+
+	if conn.WriteBack.ProcessedLabel != DefaultProcessedLabel || conn.WriteBack.ErrorLabel != DefaultErrorLabel {
+		t.Fatalf("expected default labels, got %+v", conn.WriteBack)
+	}
+	if conn.WriteBack.LabelFor(true) != DefaultErrorLabel || conn.WriteBack.LabelFor(false) != DefaultProcessedLabel {
+		t.Fatalf("unexpected labels for outcomes: %+v", conn.WriteBack)
+	}
+}
+
+func TestWriteBackSettingsValidate(t *testing.T) {
+	invalid := []WriteBackSettings{
+		{Enabled: true, ProcessedLabel: "Bowerbird/", ErrorLabel: "Bowerbird/Error"},
+		{Enabled: true, ProcessedLabel: "Facturas//Procesadas"},
+		{Enabled: true, ProcessedLabel: "Facturas", ErrorLabel: "facturas"},
+	}
+	for _, settings := range invalid {
+		if err := settings.Normalize().Validate(); !errors.Is(err, ErrInvalidWriteBackSettings) {
+			t.Errorf("expected %+v to be rejected, got %v", settings, err)
+		}
+	}
+
+	if err := (WriteBackSettings{}).Normalize().Validate(); err != nil {
+		t.Fatalf("expected disabled settings to be valid, got %v", err)
+	}
+}
@@ -7,11 +7,19 @@ import (
 
 	eventsadapter "github.com/bowerbird/internal/connections/adapters/events"
 	httpV1 "github.com/bowerbird/internal/connections/adapters/http/v1"
+	oauthadapter "github.com/bowerbird/internal/connections/adapters/oauth"
 	repositorypostgres "github.com/bowerbird/internal/connections/adapters/repository/postgres"
 	"github.com/bowerbird/internal/connections/application"
+	"github.com/bowerbird/internal/connections/application/commands"
+	"github.com/bowerbird/internal/connections/application/ports"
+	"github.com/bowerbird/internal/connections/application/queries"
+	"github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/platform/audit"
 	"github.com/bowerbird/internal/platform/config"
+	cryptoPostgres "github.com/bowerbird/internal/platform/crypto/postgres"
 	"github.com/bowerbird/internal/platform/database"
 	"github.com/bowerbird/internal/platform/events"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
@@ -20,15 +28,42 @@ type internalService struct {
 	app *application.Application
 }
 
-func NewApplication(registry *database.Registry, cipher application.CredentialsCipher) *application.Application {
+func NewApplication(cfg config.Config, registry *database.Registry, cipher application.CredentialsCipher, recorder audit.Recorder) *application.Application {
 	if registry == nil {
 		panic("database registry is required")
 	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
 
 	connectionsRepo := repositorypostgres.NewPostgresRepository(registry)
 	credentialsService := application.NewCredentialsService(cipher)
 
-	return application.NewApplication(connectionsRepo, credentialsService)
+	refresher := oauthadapter.NewTokenRefresher(cfg.GoogleClientID, cfg.GoogleClientSecret, nil)
+
+	return application.NewApplication(connectionsRepo, credentialsService, oauthadapter.NewTokenRevoker(nil), refresher, recorder)
+}
+
+// NewCredentialsRewrapDueSubscriber rewraps every tenant's connection
+// credentials under the active master key on the scheduler's event.
+func NewCredentialsRewrapDueSubscriber(controlDB *pgxpool.Pool, registry *database.Registry, rewrapper ports.CredentialsRewrapper) *eventsadapter.OnCredentialsRewrapDue {
+	if controlDB == nil {
+		panic("control plane db pool is required")
+	}
+	if registry == nil {
+		panic("database registry is required")
+	}
+	if rewrapper == nil {
+		panic("credentials rewrapper is required")
+	}
+
+	command := commands.NewRewrapCredentialsCommand(
+		repositorypostgres.NewTenantLister(controlDB),
+		repositorypostgres.NewPostgresRepository(registry),
+		rewrapper,
+		cryptoPostgres.NewKeyUsageStore(controlDB),
+	)
+	return eventsadapter.NewOnCredentialsRewrapDue(command)
 }
 
 func NewInternalService(app *application.Application) application.InternalService {
@@ -43,6 +78,10 @@ func (s *internalService) GetActiveConnections(ctx context.Context) ([]applicati
 	return s.app.Queries.GetActiveConnections.Execute(ctx)
 }
 
+func (s *internalService) FindActiveConnection(ctx context.Context, connectionID string) (application.ConnectionInfo, bool, error) {
+	return s.app.Queries.FindActiveConnection.Execute(ctx, connectionID)
+}
+
 func (s *internalService) DecryptCredentials(ctx context.Context, connectionID string) ([]byte, error) {
 	return s.app.Queries.DecryptCredentials.Execute(ctx, connectionID)
 }
@@ -55,7 +94,31 @@ func (s *internalService) GetSharingPolicy(ctx context.Context, connectionID str
 	return s.app.Queries.GetSharingPolicy.Execute(ctx, connectionID)
 }
 
-func NewHTTPHandler(mux *http.ServeMux, cfg config.Config, registry *database.Registry, cipher application.CredentialsCipher, tokenValidator httpV1.TokenValidator, stateProtector httpV1.StateProtector, eventBus events.EventBus, authMiddleware func(http.Handler) http.Handler) *httpV1.Router {
+func (s *internalService) ListOwnedConnections(ctx context.Context, ownerUserID string) ([]application.ConnectionInfo, error) {
+	return s.app.Queries.ListOwnedConnections.Execute(ctx, ownerUserID)
+}
+
+func (s *internalService) RevokeCredentials(ctx context.Context, connectionID string) error {
+	return s.app.Commands.RevokeCredentials.Execute(ctx, connectionID)
+}
+
+func (s *internalService) TransferConnection(ctx context.Context, connectionID, ownerUserID, reason string) error {
+	return s.app.Commands.TransferConnection.Execute(ctx, connectionID, ownerUserID, reason)
+}
+
+func (s *internalService) DeleteConnection(ctx context.Context, connectionID string) error {
+	return s.app.Commands.DeleteConnection.Execute(ctx, connectionID)
+}
+
+func (s *internalService) TokenSource(ctx context.Context, connectionID string) oauth2.TokenSource {
+	return s.app.Commands.Tokens.TokenSource(ctx, connectionID)
+}
+
+func (s *internalService) ListAccessibleConnections(ctx context.Context, actor domain.Actor, access domain.Access) ([]application.ConnectionInfo, error) {
+	return s.app.Queries.ListAccessibleConnections.Execute(ctx, actor, access)
+}
+
+func NewHTTPHandler(mux *http.ServeMux, cfg config.Config, registry *database.Registry, cipher application.CredentialsCipher, tokenValidator httpV1.TokenValidator, stateProtector httpV1.StateProtector, eventBus events.EventBus, recorder audit.Recorder, authMiddleware func(http.Handler) http.Handler) *httpV1.Router {
 	if mux == nil {
 		panic("http mux is required")
 	}
@@ -65,6 +128,9 @@ func NewHTTPHandler(mux *http.ServeMux, cfg config.Config, registry *database.Re
 	if tokenValidator == nil {
 		panic("token validator is required")
 	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
 
 	repo := repositorypostgres.NewPostgresRepository(registry)
 	credentialsService := application.NewCredentialsService(cipher)
@@ -87,6 +153,10 @@ func NewHTTPHandler(mux *http.ServeMux, cfg config.Config, registry *database.Re
 
 	controller := httpV1.NewController(
 		repo,
+		queries.NewListAccessibleConnectionsQuery(repo),
+		commands.NewDeleteConnectionCommand(repo, commands.NewRevokeCredentialsCommand(repo, credentialsService, oauthadapter.NewTokenRevoker(nil)), recorder),
+		commands.NewUpdateConnectionCommand(repo, recorder),
+		commands.NewReconnectConnectionCommand(repo, credentialsService, recorder),
 		credentialsService,
 		googleConfig,
 		tokenValidator,
@@ -0,0 +1,70 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	AccountEmailRequestedSchemaVersion = "1.0"
+	AccountEmailRequestedSource        = "bowerbird.identity"
+	AccountEmailRequestedDetailType    = "AccountEmailRequested"
+)
+
+const (
+	AccountEmailKindVerification  = "email_verification"
+	AccountEmailKindPasswordReset = "password_reset"
+)
+
+// AccountEmailRequested asks the notification pipeline to send a transactional
+// account email. ActionURL embeds a single-use token, so consumers must not log it.
+type AccountEmailRequested struct {
+	EventID    string `json:"event_id"`
+	OccurredAt string `json:"occurred_at"`
+	Kind       string `json:"kind"`
+	UserID     string `json:"user_id"`
+	Email      string `json:"email"`
+	FirstName  string `json:"first_name,omitempty"`
+	ActionURL  string `json:"action_url"`
+	ExpiresAt  string `json:"expires_at"`
+}
+
+func (e AccountEmailRequested) Validate() error {
+	if e.EventID == "" {
+		return errors.New("event_id is required")
+	}
+	if e.Kind != AccountEmailKindVerification && e.Kind != AccountEmailKindPasswordReset {
+		return errors.New("kind must be email_verification or password_reset")
+	}
+	if e.UserID == "" {
+		return errors.New("user_id is required")
+	}
+	if e.Email == "" {
+		return errors.New("email is required")
+	}
+	if e.ActionURL == "" {
+		return errors.New("action_url is required")
+	}
+
+	return nil
+}
+
+func MarshalAccountEmailRequested(event AccountEmailRequested) ([]byte, error) {
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(event)
+}
+
+func UnmarshalAccountEmailRequested(data []byte) (AccountEmailRequested, error) {
+	var event AccountEmailRequested
+	if err := json.Unmarshal(data, &event); err != nil {
+		return AccountEmailRequested{}, err
+	}
+	if err := event.Validate(); err != nil {
+		return AccountEmailRequested{}, err
+	}
+
+	return event, nil
+}
@@ -0,0 +1,8 @@
+package events
+
+const (
+	// AccountErasureDueSource is set by the scheduled EventBridge rule that
+	// triggers the erasure of accounts whose grace period is over.
+	AccountErasureDueSource     = "bowerbird.scheduler"
+	AccountErasureDueDetailType = "AccountErasureDue"
+)
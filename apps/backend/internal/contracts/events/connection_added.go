@@ -3,6 +3,7 @@ package events
 import (
 	"encoding/json"
 	"errors"
+	"time"
 )
 
 const (
@@ -18,6 +19,9 @@ type ConnectionAdded struct {
 	ConnectionID         string `json:"connection_id"`
 	Provider             string `json:"provider"`
 	ProviderAccountEmail string `json:"provider_account_email"`
+	// BackfillSince is the date (YYYY-MM-DD) the owner asked the mail to be
+	// imported from, if any.
+	BackfillSince string `json:"backfill_since,omitempty"`
 }
 
 func (e ConnectionAdded) Validate() error {
@@ -33,6 +37,11 @@ func (e ConnectionAdded) Validate() error {
 	if e.Provider == "" {
 		return errors.New("provider is required")
 	}
+	if e.BackfillSince != "" {
+		if _, err := time.Parse(time.DateOnly, e.BackfillSince); err != nil {
+			return errors.New("backfill_since must be a date (YYYY-MM-DD)")
+		}
+	}
 
 	return nil
 }
@@ -0,0 +1,9 @@
+package events
+
+const (
+	// CredentialsRewrapDueSource is set by the EventBridge rule, scheduled or
+	// sent by an operator after a key rotation, that rewraps stored
+	// credentials under the active master key.
+	CredentialsRewrapDueSource     = "bowerbird.scheduler"
+	CredentialsRewrapDueDetailType = "CredentialsRewrapDue"
+)
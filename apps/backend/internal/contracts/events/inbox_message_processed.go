@@ -0,0 +1,77 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	InboxMessageProcessedSchemaVersion = "1.0"
+	InboxMessageProcessedSource        = "bowerbird.invoices"
+	InboxMessageProcessedDetailType    = "InboxMessageProcessed"
+)
+
+// Outcomes of processing an inbox message for invoices. Messages that are
+// not invoices have no outcome and are not reported.
+const (
+	// InboxMessageOutcomeProcessed: an invoice was extracted, now or by an
+	// earlier message.
+	InboxMessageOutcomeProcessed = "processed"
+	// InboxMessageOutcomeFailed: the message looked like an invoice but none
+	// could be extracted.
+	InboxMessageOutcomeFailed = "failed"
+)
+
+type InboxMessageProcessed struct {
+	EventID           string `json:"event_id"`
+	OccurredAt        string `json:"occurred_at"`
+	TenantSlug        string `json:"tenant_slug"`
+	ConnectionID      string `json:"connection_id"`
+	Provider          string `json:"provider"`
+	ProviderMessageID string `json:"provider_message_id"`
+	MessageInternalID string `json:"message_internal_id"`
+	Outcome           string `json:"outcome"`
+	// Reason says why the message was skipped or failed.
+	Reason          string `json:"reason,omitempty"`
+	InvoiceHeaderID string `json:"invoice_header_id,omitempty"`
+}
+
+func (e InboxMessageProcessed) Validate() error {
+	if e.EventID == "" {
+		return errors.New("event_id is required")
+	}
+	if e.TenantSlug == "" {
+		return errors.New("tenant_slug is required")
+	}
+	if e.ConnectionID == "" {
+		return errors.New("connection_id is required")
+	}
+	if e.ProviderMessageID == "" {
+		return errors.New("provider_message_id is required")
+	}
+	if e.Outcome != InboxMessageOutcomeProcessed && e.Outcome != InboxMessageOutcomeFailed {
+		return errors.New("outcome must be processed or failed")
+	}
+
+	return nil
+}
+
+func MarshalInboxMessageProcessed(event InboxMessageProcessed) ([]byte, error) {
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(event)
+}
+
+func UnmarshalInboxMessageProcessed(data []byte) (InboxMessageProcessed, error) {
+	var event InboxMessageProcessed
+	if err := json.Unmarshal(data, &event); err != nil {
+		return InboxMessageProcessed{}, err
+	}
+	if err := event.Validate(); err != nil {
+		return InboxMessageProcessed{}, err
+	}
+
+	return event, nil
+}
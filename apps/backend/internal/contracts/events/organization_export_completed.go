@@ -0,0 +1,67 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	OrganizationExportCompletedSchemaVersion = "1.0"
+	OrganizationExportCompletedSource        = "bowerbird.organization"
+	OrganizationExportCompletedDetailType    = "OrganizationExportCompleted"
+)
+
+// OrganizationExportCompleted asks the notification pipeline to tell the
+// requester that their data export can be downloaded. ActionURL points at the
+// frontend page that asks the API for a short-lived download link.
+type OrganizationExportCompleted struct {
+	EventID          string `json:"event_id"`
+	OccurredAt       string `json:"occurred_at"`
+	OrganizationID   string `json:"organization_id"`
+	OrganizationSlug string `json:"organization_slug"`
+	ExportID         string `json:"export_id"`
+	UserID           string `json:"user_id"`
+	Email            string `json:"email"`
+	SizeBytes        int64  `json:"size_bytes"`
+	ActionURL        string `json:"action_url"`
+}
+
+func (e OrganizationExportCompleted) Validate() error {
+	if e.EventID == "" {
+		return errors.New("event_id is required")
+	}
+	if e.OrganizationID == "" {
+		return errors.New("organization_id is required")
+	}
+	if e.ExportID == "" {
+		return errors.New("export_id is required")
+	}
+	if e.UserID == "" {
+		return errors.New("user_id is required")
+	}
+	if e.Email == "" {
+		return errors.New("email is required")
+	}
+
+	return nil
+}
+
+func MarshalOrganizationExportCompleted(event OrganizationExportCompleted) ([]byte, error) {
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(event)
+}
+
+func UnmarshalOrganizationExportCompleted(data []byte) (OrganizationExportCompleted, error) {
+	var event OrganizationExportCompleted
+	if err := json.Unmarshal(data, &event); err != nil {
+		return OrganizationExportCompleted{}, err
+	}
+	if err := event.Validate(); err != nil {
+		return OrganizationExportCompleted{}, err
+	}
+
+	return event, nil
+}
@@ -0,0 +1,8 @@
+package events
+
+const (
+	// OrganizationPurgeDueSource is set by the scheduled EventBridge rule that
+	// triggers the purge of organizations whose deletion grace period is over.
+	OrganizationPurgeDueSource     = "bowerbird.scheduler"
+	OrganizationPurgeDueDetailType = "OrganizationPurgeDue"
+)
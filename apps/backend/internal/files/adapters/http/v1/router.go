@@ -3,10 +3,18 @@ package v1
 import (
 	"net/http"
 
+	"github.com/bowerbird/internal/platform/auth"
 	"github.com/bowerbird/internal/platform/config"
 	"github.com/bowerbird/internal/platform/http/api"
 )
 
+const (
+	// FilesReadPermission is the RBAC code required to download files.
+	FilesReadPermission = "files:read"
+	// FilesWritePermission is the RBAC code required to upload files.
+	FilesWritePermission = "files:write"
+)
+
 type Router struct {
 	controller *Controller
 }
@@ -20,6 +28,11 @@ func NewRouter(controller *Controller) *Router {
 }
 
 func (h *Router) Register(mux *http.ServeMux, cfg config.Config, authMiddleware func(http.Handler) http.Handler) {
-	mux.Handle("POST /api/v1/files/uploads/presigned", authMiddleware(api.Wrap(h.controller.RequestUploadURL, cfg)))
-	mux.Handle("POST /api/v1/files/downloads/presigned", authMiddleware(api.Wrap(h.controller.RequestDownloadURL, cfg)))
+	// Members keep their session access; API keys and tenant-scoped tokens
+	// need the permission.
+	requireRead := auth.RequirePermission(FilesReadPermission, auth.AllowSessionTokens())
+	requireWrite := auth.RequirePermission(FilesWritePermission, auth.AllowSessionTokens())
+
+	mux.Handle("POST /api/v1/files/uploads/presigned", authMiddleware(requireWrite(api.Wrap(h.controller.RequestUploadURL, cfg))))
+	mux.Handle("POST /api/v1/files/downloads/presigned", authMiddleware(requireRead(api.Wrap(h.controller.RequestDownloadURL, cfg))))
 }
@@ -0,0 +1,88 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/files/application"
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/bowerbird/internal/platform/config"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type stubAPIKeys struct {
+	scopes []string
+}
+
+func (s stubAPIKeys) AuthenticateAPIKey(context.Context, string) (*auth.CustomClaims, error) {
+	return &auth.CustomClaims{
+		UserID:       "user-1",
+		APIKeyID:     "key-1",
+		TenantClaims: auth.TenantClaims{TenantID: "tenant-1", TenantSlug: "acme", Permissions: s.scopes},
+	}, nil
+}
+
+type presignFileStore struct {
+	platformStorage.FileStore
+
+	uploads []string
+}
+
+func (f *presignFileStore) PresignUpload(ctx context.Context, input platformStorage.PresignUploadInput) (*platformStorage.PresignUploadResult, error) {
+	f.uploads = append(f.uploads, input.Path)
+	return &platformStorage.PresignUploadResult{}, nil
+}
+
+func (f *presignFileStore) Exists(ctx context.Context, input platformStorage.ExistsFileInput) (bool, error) {
+	return true, nil
+}
+
+func (f *presignFileStore) PresignDownload(ctx context.Context, input platformStorage.PresignDownloadInput) (*platformStorage.PresignDownloadResult, error) {
+	return &platformStorage.PresignDownloadResult{}, nil
+}
+
+func serveWithKey(store *presignFileStore, target, body string, scopes ...string) *httptest.ResponseRecorder {
+	app := application.NewApplication(store)
+	gen := auth.NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	mux := http.NewServeMux()
+	NewRouter(NewController(app.Commands.RequestUploadURL, app.Commands.RequestDownloadURL)).
+		Register(mux, config.Config{}, auth.Middleware(gen, auth.WithAPIKeys(stubAPIKeys{scopes: scopes})))
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer bbk_key-1_secret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKeyRequestsUploadURL(t *testing.T) {
+	store := &presignFileStore{}
+	body := `{"filename":"factura.xml","content_type":"application/xml","module":"invoicing"}`
+
+	if rec := serveWithKey(store, "/api/v1/files/uploads/presigned", body, FilesReadPermission); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without files:write, got %d", rec.Code)
+	}
+
+	rec := serveWithKey(store, "/api/v1/files/uploads/presigned", body, FilesWritePermission)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with files:write, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(store.uploads) != 1 || !strings.HasPrefix(store.uploads[0], "1-day/tenants/tenant-1/uploads/invoicing/user-1/") {
+		t.Fatalf("expected an upload under the key's tenant, got %v", store.uploads)
+	}
+}
+
+func TestAPIKeyRequestsDownloadURL(t *testing.T) {
+	body := `{"key":"tenant/tenant-1/invoices/a.xml"}`
+
+	if rec := serveWithKey(&presignFileStore{}, "/api/v1/files/downloads/presigned", body, FilesWritePermission); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without files:read, got %d", rec.Code)
+	}
+	if rec := serveWithKey(&presignFileStore{}, "/api/v1/files/downloads/presigned", body, FilesReadPermission); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with files:read, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
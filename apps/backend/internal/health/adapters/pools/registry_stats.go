@@ -0,0 +1,32 @@
+package pools
+
+import (
+	"github.com/bowerbird/internal/health/domain"
+	"github.com/bowerbird/internal/platform/database"
+)
+
+type RegistryStatsReader struct {
+	registry *database.Registry
+}
+
+func NewRegistryStatsReader(registry *database.Registry) RegistryStatsReader {
+	return RegistryStatsReader{registry: registry}
+}
+
+func (r RegistryStatsReader) TenantPoolStats() domain.PoolStats {
+	stats := r.registry.Stats()
+
+	return domain.PoolStats{
+		OpenPools:       stats.OpenPools,
+		MaxPools:        stats.MaxPools,
+		MaxConnsPerPool: stats.MaxConnsPerPool,
+		TotalConns:      stats.TotalConns,
+		AcquiredConns:   stats.AcquiredConns,
+		IdleConns:       stats.IdleConns,
+		Hits:            stats.Hits,
+		Misses:          stats.Misses,
+		Evictions:       stats.Evictions,
+		Invalidations:   stats.Invalidations,
+		ConnectErrors:   stats.ConnectErrors,
+	}
+}
@@ -1,7 +1,16 @@
 package ports
 
-import "context"
+import (
+	"context"
+
+	"github.com/bowerbird/internal/health/domain"
+)
 
 type HealthRepository interface {
 	Ping(ctx context.Context) error
 }
+
+// PoolStatsReader reports the tenant database pools held by this process.
+type PoolStatsReader interface {
+	TenantPoolStats() domain.PoolStats
+}
@@ -8,20 +8,25 @@ import (
 )
 
 type CheckHealthQuery struct {
-	repo ports.HealthRepository
+	repo  ports.HealthRepository
+	pools ports.PoolStatsReader
 }
 
-func NewCheckHealthQuery(repo ports.HealthRepository) *CheckHealthQuery {
+func NewCheckHealthQuery(repo ports.HealthRepository, pools ports.PoolStatsReader) *CheckHealthQuery {
 	if repo == nil {
 		panic("repo is required")
 	}
-	return &CheckHealthQuery{repo: repo}
+	if pools == nil {
+		panic("pool stats reader is required")
+	}
+	return &CheckHealthQuery{repo: repo, pools: pools}
 }
 
 func (q *CheckHealthQuery) Execute(ctx context.Context) domain.Health {
+	pools := q.pools.TenantPoolStats()
 	if err := q.repo.Ping(ctx); err != nil {
-		return domain.Health{Status: domain.StatusDegraded}
+		return domain.Health{Status: domain.StatusDegraded, TenantPools: &pools}
 	}
 
-	return domain.Health{Status: domain.StatusOK}
+	return domain.Health{Status: domain.StatusOK, TenantPools: &pools}
 }
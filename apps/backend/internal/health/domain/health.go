@@ -8,5 +8,21 @@ const (
 )
 
 type Health struct {
-	Status Status `json:"status"`
+	Status      Status     `json:"status"`
+	TenantPools *PoolStats `json:"tenant_pools,omitempty"`
+}
+
+// PoolStats summarizes the tenant database pools held by this process.
+type PoolStats struct {
+	OpenPools       int    `json:"open_pools"`
+	MaxPools        int    `json:"max_pools"`
+	MaxConnsPerPool int32  `json:"max_conns_per_pool"`
+	TotalConns      int32  `json:"total_conns"`
+	AcquiredConns   int32  `json:"acquired_conns"`
+	IdleConns       int32  `json:"idle_conns"`
+	Hits            uint64 `json:"hits"`
+	Misses          uint64 `json:"misses"`
+	Evictions       uint64 `json:"evictions"`
+	Invalidations   uint64 `json:"invalidations"`
+	ConnectErrors   uint64 `json:"connect_errors"`
 }
@@ -4,23 +4,29 @@ import (
 	"net/http"
 
 	httpV1 "github.com/bowerbird/internal/health/adapters/http/v1"
+	healthPools "github.com/bowerbird/internal/health/adapters/pools"
 	healthRepo "github.com/bowerbird/internal/health/adapters/repository/postgres"
 	"github.com/bowerbird/internal/health/application"
 	"github.com/bowerbird/internal/health/application/queries"
 	"github.com/bowerbird/internal/platform/config"
+	"github.com/bowerbird/internal/platform/database"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewApplication(db *pgxpool.Pool) *application.Application {
+func NewApplication(db *pgxpool.Pool, tenantRegistry *database.Registry) *application.Application {
 	if db == nil {
 		panic("database pool is required")
 	}
+	if tenantRegistry == nil {
+		panic("tenant registry is required")
+	}
 
 	repo := healthRepo.NewPostgresRepository(db)
+	poolStats := healthPools.NewRegistryStatsReader(tenantRegistry)
 
 	return &application.Application{
 		Queries: application.Queries{
-			CheckHealth: queries.NewCheckHealthQuery(repo),
+			CheckHealth: queries.NewCheckHealthQuery(repo, poolStats),
 		},
 	}
 }
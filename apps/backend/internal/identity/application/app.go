@@ -2,8 +2,10 @@ package application
 
 import (
 	"github.com/bowerbird/internal/identity/application/commands"
+	"github.com/bowerbird/internal/identity/application/ports"
 	"github.com/bowerbird/internal/identity/application/queries"
 	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/audit"
 	"github.com/bowerbird/internal/platform/auth"
 )
 
@@ -13,24 +15,42 @@ type Application struct {
 }
 
 type Commands struct {
-	Auth          *commands.AuthService
-	LeaveTenant   *commands.LeaveTenantCommand
-	DeleteAccount *commands.DeleteAccountCommand
+	Auth                *commands.AuthService
+	ExchangeTenantToken *commands.ExchangeTenantTokenCommand
+	LeaveTenant         *commands.LeaveTenantCommand
+	RequestErasure      *commands.RequestAccountErasureCommand
+	CancelErasure       *commands.CancelAccountErasureCommand
+	SSO                 *commands.SSOService
 }
 
 type Queries struct {
 	ListUserTenants *queries.ListUserTenantsQuery
+	GetErasure      *queries.GetAccountErasureQuery
 }
 
-func NewApplication(repo domain.Repository, tokenGen *auth.TokenGenerator, appEnv string) *Application {
+type LocalAuthOptions = commands.LocalAuthOptions
+
+// SSOOptions configures organization single sign-on.
+type SSOOptions struct {
+	Client      ports.OIDCClient
+	CallbackURL string
+}
+
+func NewApplication(repo domain.Repository, erasures ports.AccountErasureRepository, tokenGen *auth.TokenGenerator, notifier ports.AccountNotifier, cipher ports.SecretCipher, recorder audit.Recorder, localAuth LocalAuthOptions, sso SSOOptions) *Application {
+	authService := commands.NewAuthService(repo, tokenGen, notifier, cipher, localAuth)
+
 	return &Application{
 		Commands: Commands{
-			Auth:          commands.NewAuthService(repo, tokenGen, appEnv),
-			LeaveTenant:   commands.NewLeaveTenantCommand(repo),
-			DeleteAccount: commands.NewDeleteAccountCommand(repo),
+			Auth:                authService,
+			ExchangeTenantToken: commands.NewExchangeTenantTokenCommand(repo, tokenGen),
+			LeaveTenant:         commands.NewLeaveTenantCommand(repo, recorder),
+			RequestErasure:      commands.NewRequestAccountErasureCommand(erasures),
+			CancelErasure:       commands.NewCancelAccountErasureCommand(erasures),
+			SSO:                 commands.NewSSOService(authService, sso.Client, sso.CallbackURL),
 		},
 		Queries: Queries{
 			ListUserTenants: queries.NewListUserTenantsQuery(repo),
+			GetErasure:      queries.NewGetAccountErasureQuery(erasures),
 		},
 	}
 }
@@ -5,3 +5,27 @@ import "github.com/bowerbird/internal/identity/application/commands"
 type AuthService = commands.AuthService
 
 var NewAuthService = commands.NewAuthService
+
+type ExchangeTenantTokenCommand = commands.ExchangeTenantTokenCommand
+
+type TenantTokenResult = commands.TenantTokenResult
+
+var (
+	ErrNotTenantMember = commands.ErrNotTenantMember
+	ErrMFARequired     = commands.ErrMFARequired
+)
+
+type RegisterLocalInput = commands.RegisterLocalInput
+
+type LoginResult = commands.LoginResult
+
+var (
+	ErrLocalAuthDisabled      = commands.ErrLocalAuthDisabled
+	ErrInvalidCredentials     = commands.ErrInvalidCredentials
+	ErrEmailNotVerified       = commands.ErrEmailNotVerified
+	ErrEmailAlreadyRegistered = commands.ErrEmailAlreadyRegistered
+)
+
+type SSOService = commands.SSOService
+
+type ConfigureSSOInput = commands.ConfigureSSOInput
@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+type RequestAccountErasureCommand struct {
+	repo        ports.AccountErasureRepository
+	gracePeriod time.Duration
+	now         func() time.Time
+}
+
+func NewRequestAccountErasureCommand(repo ports.AccountErasureRepository) *RequestAccountErasureCommand {
+	if repo == nil {
+		panic("account erasure repository is required")
+	}
+
+	return &RequestAccountErasureCommand{repo: repo, gracePeriod: domain.DefaultErasureGracePeriod, now: time.Now}
+}
+
+// Execute schedules the erasure of the user's account, or returns the one
+// already scheduled. Organizations cannot be left without an owner, so the
+// user must first hand them over or delete them.
+func (cmd *RequestAccountErasureCommand) Execute(ctx context.Context, userID string) (*domain.AccountErasure, error) {
+	existing, err := cmd.repo.FindOpenAccountErasure(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find open account erasure: %w", err)
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	owned, err := cmd.repo.FindOwnedOrganizations(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find owned organizations: %w", err)
+	}
+	if len(owned) > 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrAccountOwnsOrganizations, strings.Join(owned, ", "))
+	}
+
+	erasure := domain.NewAccountErasure(id.NewULID(), userID, cmd.now(), cmd.gracePeriod)
+	if err := cmd.repo.CreateAccountErasure(ctx, erasure); err != nil {
+		return nil, fmt.Errorf("create account erasure: %w", err)
+	}
+
+	return erasure, nil
+}
+
+type CancelAccountErasureCommand struct {
+	repo ports.AccountErasureRepository
+}
+
+func NewCancelAccountErasureCommand(repo ports.AccountErasureRepository) *CancelAccountErasureCommand {
+	if repo == nil {
+		panic("account erasure repository is required")
+	}
+
+	return &CancelAccountErasureCommand{repo: repo}
+}
+
+func (cmd *CancelAccountErasureCommand) Execute(ctx context.Context, userID string) error {
+	erasure, err := cmd.repo.FindOpenAccountErasure(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("find open account erasure: %w", err)
+	}
+	if erasure == nil {
+		return domain.ErrErasureNotFound
+	}
+	if err := erasure.Cancel(); err != nil {
+		return err
+	}
+
+	return cmd.repo.CancelAccountErasure(ctx, erasure.ID)
+}
@@ -4,8 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-
-	"golang.org/x/crypto/bcrypt"
+	"time"
 
 	"github.com/bowerbird/internal/identity/application/ports"
 	"github.com/bowerbird/internal/identity/domain"
@@ -13,69 +12,46 @@ import (
 	"github.com/bowerbird/internal/platform/id"
 )
 
-type AuthService struct {
-	repo         ports.Repository
-	tokenGen     *auth.TokenGenerator
-	localEnabled bool
-}
+var (
+	ErrLocalAuthDisabled      = errors.New("local auth is disabled in this environment")
+	ErrInvalidCredentials     = errors.New("invalid credentials")
+	ErrEmailNotVerified       = errors.New("email address has not been verified")
+	ErrEmailAlreadyRegistered = errors.New("email address is already registered")
+)
 
-func NewAuthService(repo ports.Repository, tokenGen *auth.TokenGenerator, appEnv string) *AuthService {
-	return &AuthService{
-		repo:         repo,
-		tokenGen:     tokenGen,
-		localEnabled: appEnv == "local" || appEnv == "development",
-	}
+// LocalAuthOptions controls the email/password flows. They are off by default
+// outside local environments and enabled per deployment through config.
+type LocalAuthOptions struct {
+	Enabled                  bool
+	RequireEmailVerification bool
+	Lockout                  domain.LoginLockoutPolicy
 }
 
-func (s *AuthService) RegisterLocal(ctx context.Context, email, password string) (*auth.TokenPair, error) {
-	if !s.localEnabled {
-		return nil, errors.New("local auth is disabled in this environment")
-	}
-
-	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
-	}
-
-	user := domain.NewUser(id.NewULID(), email, "Local", "User", "")
-	err = s.repo.CreateUser(ctx, user)
-	if err != nil {
-		return nil, err
-	}
-
-	identity := domain.NewUserIdentity(id.NewULID(), user.ID, "local", string(hashed))
-	err = s.repo.CreateUserIdentity(ctx, identity)
-	if err != nil {
-		return nil, err
-	}
-
-	return s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL)
+type AuthService struct {
+	repo      ports.Repository
+	tokenGen  *auth.TokenGenerator
+	notifier  ports.AccountNotifier
+	cipher    ports.SecretCipher
+	localAuth LocalAuthOptions
+	now       func() time.Time
 }
 
-func (s *AuthService) LoginLocal(ctx context.Context, email, password string) (*auth.TokenPair, error) {
-	if !s.localEnabled {
-		return nil, errors.New("local auth is disabled in this environment")
-	}
-
-	user, err := s.repo.FindUserByEmail(ctx, email)
-	if err != nil {
-		return nil, errors.New("invalid credentials")
-	}
-
-	identity, err := s.repo.FindUserIdentityByProvider(ctx, user.ID, "local")
-	if err != nil {
-		return nil, errors.New("invalid credentials")
+func NewAuthService(repo ports.Repository, tokenGen *auth.TokenGenerator, notifier ports.AccountNotifier, cipher ports.SecretCipher, localAuth LocalAuthOptions) *AuthService {
+	if localAuth.Lockout == (domain.LoginLockoutPolicy{}) {
+		localAuth.Lockout = domain.DefaultLoginLockoutPolicy()
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(identity.ProviderID), []byte(password))
-	if err != nil {
-		return nil, errors.New("invalid credentials")
+	return &AuthService{
+		repo:      repo,
+		tokenGen:  tokenGen,
+		notifier:  notifier,
+		cipher:    cipher,
+		localAuth: localAuth,
+		now:       time.Now,
 	}
-
-	return s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL)
 }
 
-func (s *AuthService) OAuthLogin(ctx context.Context, email, provider, providerID, name, pictureURL string) (*auth.TokenPair, error) {
+func (s *AuthService) OAuthLogin(ctx context.Context, email, provider, providerID, name, pictureURL string) (*LoginResult, error) {
 	var user *domain.User
 
 	firstName := name
@@ -88,6 +64,9 @@ func (s *AuthService) OAuthLogin(ctx context.Context, email, provider, providerI
 
 	if existingUser != nil {
 		user = existingUser
+		if err := s.claimUnverifiedAccount(ctx, user); err != nil {
+			return nil, err
+		}
 		_, err = s.repo.FindUserIdentityByProvider(ctx, user.ID, provider)
 		if err != nil {
 			if errors.Is(err, domain.ErrUserNotFound) {
@@ -102,6 +81,8 @@ func (s *AuthService) OAuthLogin(ctx context.Context, email, provider, providerI
 		}
 	} else {
 		user = domain.NewUser(id.NewULID(), email, firstName, lastName, pictureURL)
+		verifiedAt := s.now().UTC()
+		user.EmailVerifiedAt = &verifiedAt
 		err = s.repo.CreateUser(ctx, user)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create user: %w", err)
@@ -114,19 +95,53 @@ func (s *AuthService) OAuthLogin(ctx context.Context, email, provider, providerI
 		}
 	}
 
-	return s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL)
+	return s.completeLogin(ctx, user)
 }
 
+// RefreshToken keeps the MFA flag and SSO binding of the session. Sessions
+// started before the user enabled MFA cannot be refreshed, which signs out
+// every other device; SSO sessions rely on the provider's second factor.
 func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*auth.TokenPair, error) {
-	userID, err := s.tokenGen.ValidateRefreshToken(refreshToken)
+	session, err := s.tokenGen.ValidateRefreshSession(refreshToken)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := s.repo.FindUserByID(ctx, userID)
+	user, err := s.repo.FindUserByID(ctx, session.UserID)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL)
+	if !session.MFA && session.SSOTenantID == "" {
+		factor, err := s.repo.FindMFAFactor(ctx, user.ID)
+		if err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+			return nil, fmt.Errorf("failed to lookup mfa factor: %w", err)
+		}
+		if factor.IsConfirmed() {
+			return nil, auth.ErrInvalidToken
+		}
+	}
+
+	return s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL, session.Options()...)
+}
+
+// claimUnverifiedAccount runs when an OAuth provider vouches for an email that
+// only has an unverified local account. Whoever set that password never proved
+// they own the mailbox, so the password is dropped before the accounts merge.
+func (s *AuthService) claimUnverifiedAccount(ctx context.Context, user *domain.User) error {
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	if err := s.repo.DeleteUserIdentity(ctx, user.ID, domain.ProviderLocal); err != nil {
+		return fmt.Errorf("failed to drop unverified local credentials: %w", err)
+	}
+
+	now := s.now().UTC()
+	if err := s.repo.MarkEmailVerified(ctx, user.ID, now); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	user.EmailVerifiedAt = &now
+
+	return nil
 }
@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/auth"
+)
+
+var (
+	ErrNotTenantMember = errors.New("user is not a member of this organization")
+	ErrMFARequired     = errors.New("organization requires multi-factor authentication")
+)
+
+type TenantTokenResult struct {
+	Tokens      *auth.TokenPair
+	TenantID    string
+	TenantSlug  string
+	Role        string
+	Permissions []string
+}
+
+// ExchangeTenantTokenCommand trades a user access token for a short-lived
+// token scoped to one organization, so tenant routes can authorize from the
+// token claims instead of looking the membership up on every request.
+type ExchangeTenantTokenCommand struct {
+	repo     ports.Repository
+	tokenGen *auth.TokenGenerator
+}
+
+func NewExchangeTenantTokenCommand(repo ports.Repository, tokenGen *auth.TokenGenerator) *ExchangeTenantTokenCommand {
+	return &ExchangeTenantTokenCommand{repo: repo, tokenGen: tokenGen}
+}
+
+func (cmd *ExchangeTenantTokenCommand) Execute(ctx context.Context, user *auth.CustomClaims, tenantRef string) (*TenantTokenResult, error) {
+	membership, err := cmd.repo.FindTenantMembership(ctx, user.UserID, tenantRef)
+	if err != nil {
+		if errors.Is(err, domain.ErrMembershipNotFound) {
+			return nil, ErrNotTenantMember
+		}
+		return nil, fmt.Errorf("failed to resolve membership: %w", err)
+	}
+
+	// An SSO session proves identity only to the organization whose provider
+	// issued it; SSO-only organizations accept nothing else.
+	if user.SSOTenantID != "" && user.SSOTenantID != membership.TenantID {
+		return nil, domain.ErrSSOSessionScope
+	}
+	if membership.SSOOnly && user.SSOTenantID != membership.TenantID {
+		return nil, domain.ErrSSORequired
+	}
+
+	if membership.MFARequired && !user.MFA {
+		return nil, ErrMFARequired
+	}
+
+	permissions, err := cmd.repo.FindTenantUserPermissions(ctx, membership.DBName, user.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve permissions: %w", err)
+	}
+
+	scope := auth.TenantClaims{
+		TenantID:          membership.TenantID,
+		TenantSlug:        membership.Slug,
+		Role:              string(membership.Role),
+		Permissions:       permissions,
+		MembershipVersion: membership.Version,
+	}
+
+	tokens, err := cmd.tokenGen.GenerateTenantAccessToken(user, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TenantTokenResult{
+		Tokens:      tokens,
+		TenantID:    scope.TenantID,
+		TenantSlug:  scope.TenantSlug,
+		Role:        scope.Role,
+		Permissions: scope.Permissions,
+	}, nil
+}
@@ -5,28 +5,57 @@ import (
 	"fmt"
 
 	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/audit"
+	"github.com/bowerbird/internal/platform/tenant"
 )
 
 type LeaveTenantCommand struct {
-	repo ports.Repository
+	repo     ports.Repository
+	recorder audit.Recorder
 }
 
-func NewLeaveTenantCommand(repo ports.Repository) *LeaveTenantCommand {
-	return &LeaveTenantCommand{repo: repo}
+func NewLeaveTenantCommand(repo ports.Repository, recorder audit.Recorder) *LeaveTenantCommand {
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
+
+	return &LeaveTenantCommand{repo: repo, recorder: recorder}
 }
 
-func (cmd *LeaveTenantCommand) Execute(ctx context.Context, userID, tenantID string) error {
-	err := cmd.repo.RemoveTenantMembership(ctx, userID, tenantID)
+// Execute returns domain.ErrMembershipNotFound when the user is not an active
+// member of the tenant, so nobody else can write to its audit log.
+func (cmd *LeaveTenantCommand) Execute(ctx context.Context, userID, tenantRef string) error {
+	membership, err := cmd.repo.FindTenantMembership(ctx, userID, tenantRef)
 	if err != nil {
-		return fmt.Errorf("failed to leave tenant: %w", err)
+		return err
 	}
 
-	dbName, err := cmd.repo.GetTenantDBName(ctx, tenantID)
+	// The request is not scoped to the tenant being left, so the event is
+	// written to that tenant's log explicitly. It is recorded first, so a
+	// member never leaves without a trace.
+	err = cmd.recorder.Record(tenant.WithTenantID(ctx, membership.TenantID), audit.Event{
+		Action:     audit.ActionMemberLeft,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Before:     map[string]any{"membership": "active"},
+		After:      map[string]any{"membership": "removed"},
+	})
 	if err != nil {
-		return fmt.Errorf("left tenant in control plane but failed to resolve tenant db: %w", err)
+		return fmt.Errorf("failed to record audit event: %w", err)
 	}
-	if dbName != "" {
-		if err := cmd.repo.SoftDeleteTenantUserProfile(ctx, dbName, userID); err != nil {
+
+	removed, err := cmd.repo.RemoveTenantMembership(ctx, userID, membership.TenantID)
+	if err != nil {
+		return fmt.Errorf("failed to leave tenant: %w", err)
+	}
+	if !removed {
+		// A concurrent request removed it after the check above.
+		return domain.ErrMembershipNotFound
+	}
+
+	if membership.DBName != "" {
+		if err := cmd.repo.SoftDeleteTenantUserProfile(ctx, membership.DBName, userID); err != nil {
 			return fmt.Errorf("left tenant in control plane but failed to update tenant profile: %w", err)
 		}
 	}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/audit"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+type memoryMemberships struct {
+	ports.Repository
+
+	memberships map[string]*domain.TenantMembership
+	removed     []string
+	profiles    []string
+}
+
+func (r *memoryMemberships) FindTenantMembership(ctx context.Context, userID, tenantRef string) (*domain.TenantMembership, error) {
+	membership, ok := r.memberships[userID]
+	if !ok || (membership.TenantID != tenantRef && membership.Slug != tenantRef) {
+		return nil, domain.ErrMembershipNotFound
+	}
+	return membership, nil
+}
+
+func (r *memoryMemberships) RemoveTenantMembership(ctx context.Context, userID, tenantID string) (bool, error) {
+	if _, ok := r.memberships[userID]; !ok {
+		return false, nil
+	}
+	delete(r.memberships, userID)
+	r.removed = append(r.removed, tenantID)
+	return true, nil
+}
+
+func (r *memoryMemberships) SoftDeleteTenantUserProfile(ctx context.Context, dbName, userID string) error {
+	r.profiles = append(r.profiles, dbName)
+	return nil
+}
+
+type tenantRecordingAudit struct {
+	recordingAudit
+	tenants []string
+}
+
+func newLeaveFixture() (*memoryMemberships, *tenantRecordingAudit) {
+	repo := &memoryMemberships{memberships: map[string]*domain.TenantMembership{
+		"user-1": {UserID: "user-1", TenantID: "tenant-1", Slug: "acme", DBName: "db_acme"},
+	}}
+	return repo, &tenantRecordingAudit{}
+}
+
+func TestLeaveTenantRecordsInTheLeftTenant(t *testing.T) {
+	repo, recorder := newLeaveFixture()
+
+	if err := NewLeaveTenantCommand(repo, recorder).Execute(context.Background(), "user-1", "acme"); err != nil {
+		t.Fatalf("leave failed: %v", err)
+	}
+
+	if len(recorder.events) != 1 || len(recorder.tenants) != 1 || recorder.tenants[0] != "tenant-1" {
+		t.Fatalf("expected one event in tenant-1, got %+v in %v", recorder.events, recorder.tenants)
+	}
+	if len(repo.removed) != 1 || len(repo.profiles) != 1 || repo.profiles[0] != "db_acme" {
+		t.Fatalf("expected the membership and profile removed, got %v %v", repo.removed, repo.profiles)
+	}
+}
+
+func TestLeaveTenantRejectsNonMembers(t *testing.T) {
+	repo, recorder := newLeaveFixture()
+
+	err := NewLeaveTenantCommand(repo, recorder).Execute(context.Background(), "user-2", "acme")
+	if !errors.Is(err, domain.ErrMembershipNotFound) {
+		t.Fatalf("expected ErrMembershipNotFound, got %v", err)
+	}
+	if len(recorder.events) != 0 || len(repo.removed) != 0 {
+		t.Fatalf("expected nothing recorded or removed, got %+v %v", recorder.events, repo.removed)
+	}
+}
+
+func (r *tenantRecordingAudit) Record(ctx context.Context, event audit.Event) error {
+	tenantID, _ := tenant.TenantIDFromContext(ctx)
+	r.tenants = append(r.tenants, tenantID)
+	return r.recordingAudit.Record(ctx, event)
+}
@@ -0,0 +1,320 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+type RegisterLocalInput struct {
+	Email     string
+	Password  string
+	FirstName string
+	LastName  string
+}
+
+// RegisterLocalResult carries tokens only when the account can sign in right
+// away; otherwise the user has to follow the verification email first.
+type RegisterLocalResult struct {
+	Tokens               *auth.TokenPair
+	VerificationRequired bool
+}
+
+// timingEqualizerHash is compared against when the email is unknown, so a
+// login for a missing account costs the same bcrypt work as a wrong password.
+var timingEqualizerHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("bowerbird-timing-equalizer"), bcrypt.DefaultCost)
+	return hash
+})
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func (s *AuthService) RegisterLocal(ctx context.Context, input RegisterLocalInput) (*RegisterLocalResult, error) {
+	if !s.localAuth.Enabled {
+		return nil, ErrLocalAuthDisabled
+	}
+
+	email := normalizeEmail(input.Email)
+	if err := domain.ValidatePassword(input.Password, email); err != nil {
+		return nil, err
+	}
+
+	_, err := s.repo.FindUserByEmail(ctx, email)
+	if err == nil {
+		return nil, ErrEmailAlreadyRegistered
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to lookup user: %w", err)
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	firstName := strings.TrimSpace(input.FirstName)
+	if firstName == "" {
+		firstName, _, _ = strings.Cut(email, "@")
+	}
+
+	user := domain.NewUser(id.NewULID(), email, firstName, strings.TrimSpace(input.LastName), "")
+	if !s.localAuth.RequireEmailVerification {
+		verifiedAt := s.now().UTC()
+		user.EmailVerifiedAt = &verifiedAt
+	}
+	if err := s.repo.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+
+	identity := domain.NewLocalUserIdentity(id.NewULID(), user.ID, string(hashed))
+	if err := s.repo.CreateUserIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+
+	if !user.IsEmailVerified() {
+		if err := s.sendEmailVerification(ctx, user); err != nil {
+			return nil, err
+		}
+		return &RegisterLocalResult{VerificationRequired: true}, nil
+	}
+
+	tokens, err := s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RegisterLocalResult{Tokens: tokens}, nil
+}
+
+// LoginLocal checks the lockout before touching the password so a locked
+// account does not leak whether a guess was right.
+func (s *AuthService) LoginLocal(ctx context.Context, email, password, ipAddress string) (*LoginResult, error) {
+	if !s.localAuth.Enabled {
+		return nil, ErrLocalAuthDisabled
+	}
+
+	email = normalizeEmail(email)
+	now := s.now().UTC()
+
+	ipAddress, err := domain.NormalizeClientIP(ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	failures, err := s.repo.CountLoginFailures(ctx, email, ipAddress, now.Add(-s.localAuth.Lockout.Window))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check login lockout: %w", err)
+	}
+	if s.localAuth.Lockout.IsLocked(failures) {
+		return nil, domain.ErrLoginLocked
+	}
+
+	user, identity, err := s.findLocalCredentials(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := timingEqualizerHash()
+	if identity != nil {
+		hash = []byte(identity.PasswordHash)
+	}
+	passwordErr := bcrypt.CompareHashAndPassword(hash, []byte(password))
+
+	succeeded := identity != nil && passwordErr == nil
+	if err := s.recordLoginAttempt(ctx, email, ipAddress, succeeded, now); err != nil {
+		return nil, err
+	}
+	if !succeeded {
+		return nil, ErrInvalidCredentials
+	}
+
+	if s.localAuth.RequireEmailVerification && !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	return s.completeLogin(ctx, user)
+}
+
+// findLocalCredentials returns nil identity, not an error, for unknown emails
+// and accounts without a password so callers can fail uniformly.
+func (s *AuthService) findLocalCredentials(ctx context.Context, email string) (*domain.User, *domain.UserIdentity, error) {
+	user, err := s.repo.FindUserByEmail(ctx, email)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lookup user: %w", err)
+	}
+
+	identity, err := s.repo.FindUserIdentityByProvider(ctx, user.ID, domain.ProviderLocal)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return user, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lookup local identity: %w", err)
+	}
+	if identity.PasswordHash == "" {
+		return user, nil, nil
+	}
+
+	return user, identity, nil
+}
+
+// recordLoginAttempt fails closed: an attempt that cannot be counted must not
+// log anyone in, or the lockout could be bypassed.
+func (s *AuthService) recordLoginAttempt(ctx context.Context, email, ipAddress string, succeeded bool, now time.Time) error {
+	attempt, err := domain.NewLoginAttempt(email, ipAddress, succeeded, now)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.RecordLoginAttempt(ctx, attempt); err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	return nil
+}
+
+// VerifyEmail redeems an email verification token.
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) error {
+	now := s.now().UTC()
+	authToken, err := s.repo.ConsumeAuthToken(ctx, domain.HashAuthToken(token), domain.PurposeEmailVerification, now)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.MarkEmailVerified(ctx, authToken.UserID, now); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	return nil
+}
+
+// ResendEmailVerification is silent for unknown or already verified emails so
+// it cannot be used to enumerate accounts.
+func (s *AuthService) ResendEmailVerification(ctx context.Context, email string) error {
+	if !s.localAuth.Enabled {
+		return ErrLocalAuthDisabled
+	}
+
+	user, identity, err := s.findLocalCredentials(ctx, normalizeEmail(email))
+	if err != nil {
+		return err
+	}
+	if identity == nil || user.IsEmailVerified() {
+		return nil
+	}
+
+	return s.sendEmailVerification(ctx, user)
+}
+
+// RequestPasswordReset is silent for unknown emails. Users who only signed in
+// through OAuth can use it to add a password, since the link proves mailbox ownership.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	if !s.localAuth.Enabled {
+		return ErrLocalAuthDisabled
+	}
+
+	user, err := s.repo.FindUserByEmail(ctx, normalizeEmail(email))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lookup user: %w", err)
+	}
+
+	authToken, plaintext, err := domain.NewAuthToken(id.NewULID(), user.ID, domain.PurposePasswordReset, domain.PasswordResetTokenTTL, s.now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateAuthToken(ctx, authToken); err != nil {
+		return err
+	}
+
+	if err := s.notifier.SendPasswordReset(ctx, user, plaintext, authToken.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to send password reset email: %w", err)
+	}
+
+	return nil
+}
+
+// ResetPassword validates the new password against the policy before the
+// token is consumed, so a rejected password does not burn the link.
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if !s.localAuth.Enabled {
+		return ErrLocalAuthDisabled
+	}
+
+	now := s.now().UTC()
+	tokenHash := domain.HashAuthToken(token)
+
+	pending, err := s.repo.FindAuthToken(ctx, tokenHash, domain.PurposePasswordReset, now)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.repo.FindUserByID(ctx, pending.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrInvalidAuthToken
+		}
+		return err
+	}
+
+	if err := domain.ValidatePassword(newPassword, user.Email); err != nil {
+		return err
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if _, err := s.repo.ConsumeAuthToken(ctx, tokenHash, domain.PurposePasswordReset, now); err != nil {
+		return err
+	}
+
+	err = s.repo.UpdatePasswordHash(ctx, user.ID, string(hashed))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		err = s.repo.CreateUserIdentity(ctx, domain.NewLocalUserIdentity(id.NewULID(), user.ID, string(hashed)))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store new password: %w", err)
+	}
+
+	if err := s.repo.MarkEmailVerified(ctx, user.ID, now); err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+
+	// A successful reset proves ownership, so it also lifts the account lockout.
+	if err := s.repo.RecordLoginAttempt(ctx, domain.NewPasswordResetAttempt(user.Email, now)); err != nil {
+		return fmt.Errorf("failed to lift login lockout: %w", err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) sendEmailVerification(ctx context.Context, user *domain.User) error {
+	authToken, plaintext, err := domain.NewAuthToken(id.NewULID(), user.ID, domain.PurposeEmailVerification, domain.EmailVerificationTokenTTL, s.now().UTC())
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateAuthToken(ctx, authToken); err != nil {
+		return err
+	}
+
+	if err := s.notifier.SendEmailVerification(ctx, user, plaintext, authToken.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+// LoginResult carries either a session or, for accounts with MFA, the
+// challenge that has to be completed through VerifyMFA.
+type LoginResult struct {
+	Tokens       *auth.TokenPair
+	MFAChallenge *MFAChallenge
+}
+
+type MFAChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type MFAConfirmation struct {
+	RecoveryCodes []string
+	Tokens        *auth.TokenPair
+}
+
+type MFAStatus struct {
+	Enabled                bool
+	RecoveryCodesRemaining int
+}
+
+// completeLogin issues the session once the first factor has been checked, or
+// an MFA challenge when the user has a confirmed authenticator.
+func (s *AuthService) completeLogin(ctx context.Context, user *domain.User) (*LoginResult, error) {
+	factor, err := s.repo.FindMFAFactor(ctx, user.ID)
+	if err != nil && !errors.Is(err, domain.ErrMFANotEnrolled) {
+		return nil, fmt.Errorf("failed to lookup mfa factor: %w", err)
+	}
+
+	if factor.IsConfirmed() {
+		challenge, plaintext, err := domain.NewAuthToken(id.NewULID(), user.ID, domain.PurposeMFAChallenge, domain.MFAChallengeTTL, s.now().UTC())
+		if err != nil {
+			return nil, err
+		}
+		if err := s.repo.CreateAuthToken(ctx, challenge); err != nil {
+			return nil, err
+		}
+
+		return &LoginResult{MFAChallenge: &MFAChallenge{Token: plaintext, ExpiresAt: challenge.ExpiresAt}}, nil
+	}
+
+	tokens, err := s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{Tokens: tokens}, nil
+}
+
+// VerifyMFA completes a login with a TOTP or recovery code. The challenge
+// survives a wrong code so the user can retype it, up to
+// MaxMFAChallengeFailures; failures also count towards the same account and IP
+// lockout as passwords, and a locked account loses the challenge.
+func (s *AuthService) VerifyMFA(ctx context.Context, challengeToken, code, ipAddress string) (*auth.TokenPair, error) {
+	now := s.now().UTC()
+	challengeHash := domain.HashAuthToken(challengeToken)
+
+	challenge, err := s.repo.FindAuthToken(ctx, challengeHash, domain.PurposeMFAChallenge, now)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.FindUserByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrInvalidAuthToken
+		}
+		return nil, err
+	}
+
+	if err := s.checkMFACode(ctx, user, code, ipAddress, now); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidMFACode):
+			if failErr := s.repo.RecordAuthTokenFailure(ctx, challengeHash, domain.PurposeMFAChallenge, domain.MaxMFAChallengeFailures, now); failErr != nil {
+				return nil, failErr
+			}
+		case errors.Is(err, domain.ErrLoginLocked):
+			if _, consumeErr := s.repo.ConsumeAuthToken(ctx, challengeHash, domain.PurposeMFAChallenge, now); consumeErr != nil && !errors.Is(consumeErr, domain.ErrInvalidAuthToken) {
+				return nil, consumeErr
+			}
+		}
+		return nil, err
+	}
+
+	if _, err := s.repo.ConsumeAuthToken(ctx, challengeHash, domain.PurposeMFAChallenge, now); err != nil {
+		return nil, err
+	}
+
+	return s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL, auth.WithMFA())
+}
+
+// EnrollMFA starts (or restarts) a TOTP enrollment and returns the secret to
+// load into an authenticator app. It has no effect until ConfirmMFA.
+func (s *AuthService) EnrollMFA(ctx context.Context, userID string) (*MFAEnrollment, error) {
+	user, err := s.repo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := domain.NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := s.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt totp secret: %w", err)
+	}
+
+	factor := &domain.MFAFactor{UserID: user.ID, EncryptedSecret: encrypted, CreatedAt: s.now().UTC()}
+	if err := s.repo.SavePendingMFAFactor(ctx, factor); err != nil {
+		return nil, err
+	}
+
+	return &MFAEnrollment{Secret: secret, URI: domain.TOTPProvisioningURI(secret, user.Email)}, nil
+}
+
+// ConfirmMFA activates a pending enrollment with a first code from the app. It
+// returns the recovery codes, shown only once, and a new MFA session.
+func (s *AuthService) ConfirmMFA(ctx context.Context, userID, code string) (*MFAConfirmation, error) {
+	factor, err := s.repo.FindMFAFactor(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if factor.IsConfirmed() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := s.decryptSecret(factor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	step, err := domain.VerifyTOTP(secret, code, now, factor.LastUsedStep)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ConfirmMFAFactor(ctx, userID, step, hashes, now); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL, auth.WithMFA())
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAConfirmation{RecoveryCodes: codes, Tokens: tokens}, nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code, used or not.
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID, code, ipAddress string) ([]string, error) {
+	user, err := s.repo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	if err := s.checkMFACode(ctx, user, code, ipAddress, now); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, userID, hashes, now); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableMFA removes the authenticator and its recovery codes. A current code
+// is required so a hijacked session cannot silently drop the second factor.
+func (s *AuthService) DisableMFA(ctx context.Context, userID, code, ipAddress string) error {
+	user, err := s.repo.FindUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkMFACode(ctx, user, code, ipAddress, s.now().UTC()); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteMFAFactor(ctx, userID)
+}
+
+func (s *AuthService) MFAStatus(ctx context.Context, userID string) (*MFAStatus, error) {
+	factor, err := s.repo.FindMFAFactor(ctx, userID)
+	if errors.Is(err, domain.ErrMFANotEnrolled) {
+		return &MFAStatus{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !factor.IsConfirmed() {
+		return &MFAStatus{}, nil
+	}
+
+	remaining, err := s.repo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &MFAStatus{Enabled: true, RecoveryCodesRemaining: remaining}, nil
+}
+
+// checkMFACode accepts a TOTP code or an unused recovery code for a confirmed
+// factor, applying the login lockout and recording the attempt.
+func (s *AuthService) checkMFACode(ctx context.Context, user *domain.User, code, ipAddress string, now time.Time) error {
+	ipAddress, err := domain.NormalizeClientIP(ipAddress)
+	if err != nil {
+		return err
+	}
+
+	failures, err := s.repo.CountLoginFailures(ctx, user.Email, ipAddress, now.Add(-s.localAuth.Lockout.Window))
+	if err != nil {
+		return fmt.Errorf("failed to check login lockout: %w", err)
+	}
+	if s.localAuth.Lockout.IsLocked(failures) {
+		return domain.ErrLoginLocked
+	}
+
+	err = s.verifyMFACode(ctx, user.ID, code, now)
+	if err != nil && !errors.Is(err, domain.ErrInvalidMFACode) {
+		return err
+	}
+
+	if recordErr := s.recordLoginAttempt(ctx, user.Email, ipAddress, err == nil, now); recordErr != nil {
+		return recordErr
+	}
+
+	return err
+}
+
+func (s *AuthService) verifyMFACode(ctx context.Context, userID, code string, now time.Time) error {
+	factor, err := s.repo.FindMFAFactor(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !factor.IsConfirmed() {
+		return domain.ErrMFANotEnrolled
+	}
+
+	code = domain.NormalizeMFACode(code)
+	if !domain.IsTOTPCode(code) {
+		return s.repo.ConsumeRecoveryCode(ctx, userID, domain.HashRecoveryCode(code), now)
+	}
+
+	secret, err := s.decryptSecret(factor)
+	if err != nil {
+		return err
+	}
+
+	step, err := domain.VerifyTOTP(secret, code, now, factor.LastUsedStep)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.AdvanceMFAStep(ctx, userID, step)
+}
+
+func (s *AuthService) decryptSecret(factor *domain.MFAFactor) (string, error) {
+	secret, err := s.cipher.Decrypt(factor.EncryptedSecret)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+	return string(secret), nil
+}
+
+func newRecoveryCodes() ([]string, [][]byte, error) {
+	codes, err := domain.NewRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([][]byte, len(codes))
+	for i, code := range codes {
+		hashes[i] = domain.HashRecoveryCode(code)
+	}
+
+	return codes, hashes, nil
+}
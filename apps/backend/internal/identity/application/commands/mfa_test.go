@@ -0,0 +1,132 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/auth"
+)
+
+type memoryMFARepo struct {
+	ports.Repository
+
+	challenge      *domain.AuthToken
+	challengeFails int
+	attempts       []domain.LoginAttempt
+}
+
+func (r *memoryMFARepo) FindAuthToken(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, now time.Time) (*domain.AuthToken, error) {
+	if r.challenge == nil || r.challenge.ConsumedAt != nil || !bytes.Equal(r.challenge.TokenHash, tokenHash) {
+		return nil, domain.ErrInvalidAuthToken
+	}
+	return r.challenge, nil
+}
+
+func (r *memoryMFARepo) ConsumeAuthToken(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, now time.Time) (*domain.AuthToken, error) {
+	token, err := r.FindAuthToken(ctx, tokenHash, purpose, now)
+	if err != nil {
+		return nil, err
+	}
+	token.ConsumedAt = &now
+	return token, nil
+}
+
+func (r *memoryMFARepo) RecordAuthTokenFailure(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, maxFailures int, now time.Time) error {
+	if _, err := r.FindAuthToken(ctx, tokenHash, purpose, now); err != nil {
+		return nil
+	}
+	r.challengeFails++
+	if r.challengeFails >= maxFailures {
+		r.challenge.ConsumedAt = &now
+	}
+	return nil
+}
+
+func (r *memoryMFARepo) FindUserByID(ctx context.Context, userID string) (*domain.User, error) {
+	return &domain.User{ID: userID, Email: "ana@example.com"}, nil
+}
+
+func (r *memoryMFARepo) CountLoginFailures(ctx context.Context, email, ipAddress string, since time.Time) (domain.LoginFailures, error) {
+	var failures domain.LoginFailures
+	for _, attempt := range r.attempts {
+		if attempt.Succeeded {
+			continue
+		}
+		if attempt.Email == email {
+			failures.Account++
+		}
+		if attempt.IPAddress == ipAddress {
+			failures.IP++
+		}
+	}
+	return failures, nil
+}
+
+func (r *memoryMFARepo) RecordLoginAttempt(ctx context.Context, attempt domain.LoginAttempt) error {
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+func (r *memoryMFARepo) FindMFAFactor(ctx context.Context, userID string) (*domain.MFAFactor, error) {
+	confirmedAt := time.Now()
+	return &domain.MFAFactor{UserID: userID, ConfirmedAt: &confirmedAt}, nil
+}
+
+func (r *memoryMFARepo) ConsumeRecoveryCode(ctx context.Context, userID string, codeHash []byte, at time.Time) error {
+	return domain.ErrInvalidMFACode
+}
+
+func newMFAChallengeService(t *testing.T) (*AuthService, *memoryMFARepo, string) {
+	t.Helper()
+
+	challenge, plaintext, err := domain.NewAuthToken("challenge-1", "user-1", domain.PurposeMFAChallenge, domain.MFAChallengeTTL, time.Now())
+	if err != nil {
+		t.Fatalf("new challenge: %v", err)
+	}
+	repo := &memoryMFARepo{challenge: challenge}
+	tokenGen := auth.NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+
+	return NewAuthService(repo, tokenGen, nil, nil, LocalAuthOptions{Enabled: true}), repo, plaintext
+}
+
+func TestVerifyMFAInvalidatesChallengeAfterMaxFailures(t *testing.T) {
+	service, repo, challenge := newMFAChallengeService(t)
+	ctx := context.Background()
+
+	for i := 0; i < domain.MaxMFAChallengeFailures; i++ {
+		if _, err := service.VerifyMFA(ctx, challenge, "wrong-code", "203.0.113.9"); !errors.Is(err, domain.ErrInvalidMFACode) {
+			t.Fatalf("attempt %d: expected ErrInvalidMFACode, got %v", i+1, err)
+		}
+	}
+
+	if _, err := service.VerifyMFA(ctx, challenge, "wrong-code", "203.0.113.9"); !errors.Is(err, domain.ErrInvalidAuthToken) {
+		t.Fatalf("expected the challenge to be invalidated, got %v", err)
+	}
+	if len(repo.attempts) != domain.MaxMFAChallengeFailures {
+		t.Fatalf("expected every failure to count towards the lockout, got %d attempts", len(repo.attempts))
+	}
+	for _, attempt := range repo.attempts {
+		if attempt.Succeeded || attempt.Email != "ana@example.com" {
+			t.Fatalf("unexpected login attempt %+v", attempt)
+		}
+	}
+}
+
+func TestVerifyMFALockedAccountLosesChallenge(t *testing.T) {
+	service, repo, challenge := newMFAChallengeService(t)
+	for range domain.DefaultLoginLockoutPolicy().MaxAccountFailures {
+		repo.attempts = append(repo.attempts, domain.LoginAttempt{Email: "ana@example.com", IPAddress: "198.51.100.7"})
+	}
+
+	if _, err := service.VerifyMFA(context.Background(), challenge, "123456", "203.0.113.9"); !errors.Is(err, domain.ErrLoginLocked) {
+		t.Fatalf("expected ErrLoginLocked, got %v", err)
+	}
+	if repo.challenge.ConsumedAt == nil {
+		t.Fatal("expected the challenge of a locked account to be consumed")
+	}
+}
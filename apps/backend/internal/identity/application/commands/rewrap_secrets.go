@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bowerbird/internal/identity/application/ports"
+)
+
+// secretsKeyUsageScope names the identity secrets among the records counted
+// per master key.
+const secretsKeyUsageScope = "identity"
+
+// SecretsRewrapReport counts what a rewrap run did with the sealed secrets.
+type SecretsRewrapReport struct {
+	Rewrapped int
+	Unchanged int
+	// Skipped counts secrets replaced while being rewrapped; the new
+	// ciphertext is already under the active key.
+	Skipped int
+	Failed  int
+	// KeyUsage counts the secrets each master key seals after the run.
+	KeyUsage map[string]int
+}
+
+// RewrapSecretsCommand moves the TOTP secrets and SSO client secrets under
+// the active master key, the identity share of a credentials key rotation.
+// The SSO state travels in the login redirect and expires in minutes, so it
+// needs no rewrap.
+type RewrapSecretsCommand struct {
+	repo      ports.SealedSecretRepository
+	rewrapper ports.SecretRewrapper
+	usage     ports.KeyUsageRecorder
+}
+
+func NewRewrapSecretsCommand(repo ports.SealedSecretRepository, rewrapper ports.SecretRewrapper, usage ports.KeyUsageRecorder) *RewrapSecretsCommand {
+	if repo == nil {
+		panic("sealed secret repository is required")
+	}
+	if rewrapper == nil {
+		panic("secret rewrapper is required")
+	}
+	if usage == nil {
+		panic("key usage recorder is required")
+	}
+
+	return &RewrapSecretsCommand{repo: repo, rewrapper: rewrapper, usage: usage}
+}
+
+// Execute is idempotent and records the keys the secrets still use; only a
+// run without failures lets a retired key drop out of that record.
+func (cmd *RewrapSecretsCommand) Execute(ctx context.Context) (SecretsRewrapReport, error) {
+	// The active key seals whatever is written while the run goes on.
+	report := SecretsRewrapReport{KeyUsage: map[string]int{cmd.rewrapper.ActiveKeyID(): 0}}
+
+	secrets, err := cmd.repo.ListSealedSecrets(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	var errs []error
+	for _, secret := range secrets {
+		rewrapped, changed, err := cmd.rewrapper.Rewrap(secret.Ciphertext)
+		if err != nil {
+			report.Failed++
+			report.KeyUsage[cmd.rewrapper.KeyID(secret.Ciphertext)]++
+			errs = append(errs, fmt.Errorf("rewrap %s of %s: %w", secret.Kind, secret.OwnerID, err))
+			continue
+		}
+		if !changed {
+			report.Unchanged++
+			report.KeyUsage[cmd.rewrapper.KeyID(secret.Ciphertext)]++
+			continue
+		}
+
+		replaced, err := cmd.repo.ReplaceSealedSecret(ctx, secret, rewrapped)
+		if err != nil {
+			report.Failed++
+			report.KeyUsage[cmd.rewrapper.KeyID(secret.Ciphertext)]++
+			errs = append(errs, fmt.Errorf("store %s of %s: %w", secret.Kind, secret.OwnerID, err))
+			continue
+		}
+		report.KeyUsage[cmd.rewrapper.KeyID(rewrapped)]++
+		if !replaced {
+			report.Skipped++
+			continue
+		}
+		report.Rewrapped++
+	}
+
+	if err := cmd.usage.RecordKeyUsage(ctx, secretsKeyUsageScope, report.KeyUsage, len(errs) == 0); err != nil {
+		errs = append(errs, fmt.Errorf("record key usage: %w", err))
+	}
+
+	return report, errors.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bowerbird/internal/identity/domain"
+)
+
+type memorySecrets struct {
+	secrets []domain.SealedSecret
+}
+
+func (r *memorySecrets) ListSealedSecrets(ctx context.Context) ([]domain.SealedSecret, error) {
+	return append([]domain.SealedSecret(nil), r.secrets...), nil
+}
+
+func (r *memorySecrets) ReplaceSealedSecret(ctx context.Context, secret domain.SealedSecret, replacement []byte) (bool, error) {
+	for i, stored := range r.secrets {
+		if stored.Kind == secret.Kind && stored.OwnerID == secret.OwnerID && string(stored.Ciphertext) == string(secret.Ciphertext) {
+			r.secrets[i].Ciphertext = replacement
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// prefixRewrapper moves "old:" ciphertext, sealed by k1, to "new:", sealed by
+// the active k2, and fails on "bad:", sealed by a retired k0.
+type prefixRewrapper struct{}
+
+func (prefixRewrapper) Rewrap(ciphertext []byte) ([]byte, bool, error) {
+	switch {
+	case strings.HasPrefix(string(ciphertext), "bad:"):
+		return nil, false, errors.New("unknown master key")
+	case strings.HasPrefix(string(ciphertext), "old:"):
+		return []byte("new:" + string(ciphertext[len("old:"):])), true, nil
+	default:
+		return ciphertext, false, nil
+	}
+}
+
+func (prefixRewrapper) KeyID(ciphertext []byte) string {
+	prefix, _, _ := strings.Cut(string(ciphertext), ":")
+	return map[string]string{"bad": "k0", "old": "k1", "new": "k2"}[prefix]
+}
+
+func (prefixRewrapper) ActiveKeyID() string {
+	return "k2"
+}
+
+type memoryKeyUsage struct {
+	usage    map[string]int
+	complete bool
+}
+
+func (u *memoryKeyUsage) RecordKeyUsage(ctx context.Context, scope string, usage map[string]int, complete bool) error {
+	u.usage, u.complete = usage, complete
+	return nil
+}
+
+func TestRewrapSecretsRewrapsTOTPAndSSOSecrets(t *testing.T) {
+	repo := &memorySecrets{secrets: []domain.SealedSecret{
+		{Kind: domain.SealedSecretMFAFactor, OwnerID: "user-1", Ciphertext: []byte("old:totp")},
+		{Kind: domain.SealedSecretSSOClientSecret, OwnerID: "tenant-1", Ciphertext: []byte("old:sso")},
+		{Kind: domain.SealedSecretMFAFactor, OwnerID: "user-2", Ciphertext: []byte("new:totp")},
+	}}
+	usage := &memoryKeyUsage{}
+
+	report, err := NewRewrapSecretsCommand(repo, prefixRewrapper{}, usage).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("rewrap failed: %v", err)
+	}
+
+	for _, secret := range repo.secrets {
+		if !strings.HasPrefix(string(secret.Ciphertext), "new:") {
+			t.Fatalf("expected %s of %s under the active key, got %q", secret.Kind, secret.OwnerID, secret.Ciphertext)
+		}
+	}
+	if report.Rewrapped != 2 || report.Unchanged != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if !usage.complete || usage.usage["k2"] != 3 || len(usage.usage) != 1 {
+		t.Fatalf("expected a complete run leaving only k2, got %+v", usage)
+	}
+}
+
+func TestRewrapSecretsKeepsKeysOfFailedSecretsInUse(t *testing.T) {
+	repo := &memorySecrets{secrets: []domain.SealedSecret{
+		{Kind: domain.SealedSecretSSOClientSecret, OwnerID: "tenant-1", Ciphertext: []byte("bad:sso")},
+	}}
+	usage := &memoryKeyUsage{}
+
+	report, err := NewRewrapSecretsCommand(repo, prefixRewrapper{}, usage).Execute(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "tenant-1") || report.Failed != 1 {
+		t.Fatalf("expected the failing secret to be reported, got %+v, %v", report, err)
+	}
+	if usage.complete || usage.usage["k0"] != 1 {
+		t.Fatalf("expected k0 to stay in use after an incomplete run, got %+v", usage)
+	}
+}
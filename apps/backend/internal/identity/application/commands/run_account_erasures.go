@@ -0,0 +1,328 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	inboxApp "github.com/bowerbird/internal/inbox/application"
+	"github.com/bowerbird/internal/platform/audit"
+	"github.com/bowerbird/internal/platform/database"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+const (
+	// erasureStaleAfter is how long an erasure may stay running before
+	// another run takes it over, e.g. after a crash.
+	erasureStaleAfter = time.Hour
+	// maxErasureAttempts leaves an erasure that keeps failing to an operator.
+	maxErasureAttempts = 5
+	// transferReason is recorded on connections handed over to an owner.
+	transferReason = "owner_erased"
+)
+
+type RunAccountErasuresCommand struct {
+	repo        ports.AccountErasureRepository
+	connections connectionsApp.InternalService
+	inbox       inboxApp.InternalService
+	files       platformStorage.PrefixDeleter
+	anonymizer  audit.Anonymizer
+	recorder    audit.Recorder
+	now         func() time.Time
+}
+
+func NewRunAccountErasuresCommand(
+	repo ports.AccountErasureRepository,
+	connections connectionsApp.InternalService,
+	inbox inboxApp.InternalService,
+	files platformStorage.PrefixDeleter,
+	anonymizer audit.Anonymizer,
+	recorder audit.Recorder,
+) *RunAccountErasuresCommand {
+	if repo == nil {
+		panic("account erasure repository is required")
+	}
+	if connections == nil {
+		panic("connections internal service is required")
+	}
+	if inbox == nil {
+		panic("inbox internal service is required")
+	}
+	if files == nil {
+		panic("file purger is required")
+	}
+	if anonymizer == nil {
+		panic("audit anonymizer is required")
+	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
+
+	return &RunAccountErasuresCommand{
+		repo:        repo,
+		connections: connections,
+		inbox:       inbox,
+		files:       files,
+		anonymizer:  anonymizer,
+		recorder:    recorder,
+		now:         time.Now,
+	}
+}
+
+// Execute erases every account whose grace period is over and returns how
+// many were erased. Each step is idempotent, so a failed erasure is retried
+// on a later run; tenants it already erased are skipped.
+func (cmd *RunAccountErasuresCommand) Execute(ctx context.Context) (int, error) {
+	erasures, err := cmd.repo.ClaimDueAccountErasures(ctx, cmd.now().UTC(), erasureStaleAfter, maxErasureAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due account erasures: %w", err)
+	}
+
+	erased := 0
+	var errs []error
+	for _, erasure := range erasures {
+		report, err := cmd.erase(ctx, erasure)
+		if err != nil {
+			slog.Error("Account erasure failed", "erasure_id", erasure.ID, "user_id", erasure.UserID, "error", err)
+			if failErr := cmd.repo.FailAccountErasure(ctx, erasure.ID, err.Error()); failErr != nil {
+				err = errors.Join(err, fmt.Errorf("record failure: %w", failErr))
+			}
+			errs = append(errs, fmt.Errorf("erase %s: %w", erasure.UserID, err))
+			continue
+		}
+		if err := cmd.repo.CompleteAccountErasure(ctx, erasure.ID, report); err != nil {
+			errs = append(errs, fmt.Errorf("complete erasure %s: %w", erasure.ID, err))
+			continue
+		}
+		erased++
+	}
+
+	return erased, errors.Join(errs...)
+}
+
+func (cmd *RunAccountErasuresCommand) erase(ctx context.Context, erasure *domain.AccountErasure) (*domain.ErasureReport, error) {
+	// The user may have become an owner during the grace period.
+	owned, err := cmd.repo.FindOwnedOrganizations(ctx, erasure.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("find owned organizations: %w", err)
+	}
+	if len(owned) > 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrAccountOwnsOrganizations, strings.Join(owned, ", "))
+	}
+
+	tenants, err := cmd.repo.FindErasureTenants(ctx, erasure.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("find tenants: %w", err)
+	}
+
+	if len(erasure.Identifiers) == 0 {
+		identifiers, err := cmd.collectIdentifiers(ctx, erasure.UserID, tenants)
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.repo.SaveErasureIdentifiers(ctx, erasure.ID, identifiers); err != nil {
+			return nil, fmt.Errorf("save identifiers: %w", err)
+		}
+		erasure.Identifiers = identifiers
+	}
+
+	report := &domain.ErasureReport{UserID: erasure.UserID}
+	var errs []error
+	for _, t := range tenants {
+		if done, ok := erasure.ErasedTenant(t.TenantID); ok {
+			report.Tenants = append(report.Tenants, done)
+			continue
+		}
+
+		tenantReport, err := cmd.eraseTenant(ctx, erasure, t)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.TenantID, err))
+			continue
+		}
+		report.Tenants = append(report.Tenants, tenantReport)
+		if tenantReport.Skipped != "" {
+			continue
+		}
+		// A retry skips the tenants saved here, so account.erased is
+		// recorded once per tenant.
+		if err := cmd.repo.SaveErasureProgress(ctx, erasure.ID, report); err != nil {
+			return nil, fmt.Errorf("save progress: %w", err)
+		}
+	}
+	// The control plane goes last: the account must stay resolvable until
+	// every tenant is done, so a failed run can be retried.
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	report.ControlPlane, err = cmd.repo.EraseUser(ctx, erasure.UserID, domain.ErasedEmail(erasure.UserID))
+	if err != nil {
+		return nil, fmt.Errorf("erase control plane records: %w", err)
+	}
+	report.CompletedAt = cmd.now().UTC()
+
+	return report, nil
+}
+
+// collectIdentifiers gathers the addresses that identify the user: their
+// login email and the mailboxes they connected.
+func (cmd *RunAccountErasuresCommand) collectIdentifiers(ctx context.Context, userID string, tenants []domain.ErasureTenant) ([]string, error) {
+	user, err := cmd.repo.FindUserForErasure(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find user: %w", err)
+	}
+
+	identifiers := []string{user.Email}
+	for _, t := range tenants {
+		if t.Purged() || !t.Settled() {
+			continue
+		}
+		owned, err := cmd.connections.ListOwnedConnections(tenantContext(ctx, t), userID)
+		if err != nil {
+			return nil, fmt.Errorf("list connections in %s: %w", t.TenantID, err)
+		}
+		for _, conn := range owned {
+			if conn.ProviderAccountEmail != "" && !slices.Contains(identifiers, conn.ProviderAccountEmail) {
+				identifiers = append(identifiers, conn.ProviderAccountEmail)
+			}
+		}
+	}
+
+	return identifiers, nil
+}
+
+func (cmd *RunAccountErasuresCommand) eraseTenant(ctx context.Context, erasure *domain.AccountErasure, t domain.ErasureTenant) (domain.TenantErasureReport, error) {
+	report := domain.TenantErasureReport{TenantID: t.TenantID}
+	if t.Purged() {
+		report.Skipped = "organization " + t.Status
+		return report, nil
+	}
+	if !t.Settled() {
+		// Failing leaves the erasure pending, so it runs once the
+		// organization settles.
+		return report, fmt.Errorf("%w: %s", domain.ErrErasureTenantUnavailable, t.Status)
+	}
+
+	ctx = tenantContext(ctx, t)
+	userID := erasure.UserID
+
+	owner, err := cmd.repo.FindTenantOwner(ctx, t.TenantID, userID)
+	if err != nil {
+		return report, fmt.Errorf("find owner: %w", err)
+	}
+
+	owned, err := cmd.connections.ListOwnedConnections(ctx, userID)
+	if err != nil {
+		return report, fmt.Errorf("list connections: %w", err)
+	}
+	for _, conn := range owned {
+		// Revocation is best effort: a provider outage must not keep the
+		// credentials around, and they are deleted either way.
+		if err := cmd.connections.RevokeCredentials(ctx, conn.ID); err != nil {
+			slog.Warn("Connection token revocation failed", "tenant_id", t.TenantID, "connection_id", conn.ID, "error", err)
+			report.TokenRevocationsFailed++
+		} else {
+			report.TokensRevoked++
+		}
+
+		if conn.SharingPolicy == connectionsDomain.SharingPolicyTenantAll && owner != "" {
+			if err := cmd.connections.TransferConnection(ctx, conn.ID, owner, transferReason); err != nil {
+				return report, fmt.Errorf("transfer connection %s: %w", conn.ID, err)
+			}
+			report.ConnectionsTransferred++
+			report.TransferredTo = owner
+			continue
+		}
+
+		purged, err := cmd.inbox.PurgeConnectionMessages(ctx, conn.ID)
+		if err != nil {
+			return report, fmt.Errorf("purge messages of %s: %w", conn.ID, err)
+		}
+		report.MessagesDeleted += purged.Messages
+		report.AttachmentsDeleted += purged.Attachments
+		report.AttachmentsRetained += purged.RetainedAttachments
+
+		if err := cmd.connections.DeleteConnection(ctx, conn.ID); err != nil {
+			return report, fmt.Errorf("delete connection %s: %w", conn.ID, err)
+		}
+		report.ConnectionsDeleted++
+	}
+
+	for _, ref := range append([]string{t.TenantID, t.Slug}, t.FormerSlugs...) {
+		if ref == "" {
+			continue
+		}
+		deleted, err := cmd.files.DeletePrefix(ctx, platformStorage.DeletePrefixInput{
+			Prefix: "1-day/tenants/" + ref + "/uploads/",
+			Match:  uploadedBy(userID),
+		})
+		if err != nil {
+			return report, fmt.Errorf("delete uploads under %s: %w", ref, err)
+		}
+		report.UploadsDeleted += deleted
+	}
+
+	// Anonymizing after the steps also scrubs the addresses the events they
+	// recorded hold.
+	report.AuditEventsAnonymized, err = cmd.anonymizer.Anonymize(ctx, audit.Anonymization{
+		UserID:      userID,
+		Identifiers: erasure.Identifiers,
+		Replacement: domain.ErasedEmail(userID),
+	})
+	if err != nil {
+		return report, fmt.Errorf("anonymize audit events: %w", err)
+	}
+
+	if err := cmd.repo.AnonymizeTenantUserProfile(ctx, t.DBName, userID, domain.ErasedEmail(userID)); err != nil {
+		return report, fmt.Errorf("anonymize profile: %w", err)
+	}
+
+	// Unlike other audited actions this one is recorded last, as it reports
+	// the counts and holds no personal data; if it fails the tenant is not
+	// marked erased and is retried, so it is never completed without a trace.
+	err = cmd.recorder.Record(ctx, audit.Event{
+		Action:     audit.ActionAccountErased,
+		TargetType: audit.TargetUser,
+		TargetID:   userID,
+		Metadata: map[string]any{
+			"erasure_id":              erasure.ID,
+			"connections_deleted":     report.ConnectionsDeleted,
+			"connections_transferred": report.ConnectionsTransferred,
+			"messages_deleted":        report.MessagesDeleted,
+			"uploads_deleted":         report.UploadsDeleted,
+		},
+	})
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// tenantContext scopes ctx to the tenant's database whatever the status of
+// the organization: a suspended or archived one still holds the user's data.
+func tenantContext(ctx context.Context, t domain.ErasureTenant) context.Context {
+	return database.WithTenantDatabase(tenant.WithTenantID(ctx, t.TenantID), t.DBName)
+}
+
+// uploadedBy matches upload keys, <module>/<userID>/<name> below the
+// uploads prefix, that belong to the user.
+func uploadedBy(userID string) func(key string) bool {
+	return func(key string) bool {
+		_, rest, ok := strings.Cut(key, "/uploads/")
+		if !ok {
+			return false
+		}
+		parts := strings.Split(rest, "/")
+		return len(parts) >= 3 && parts[1] == userID
+	}
+}
@@ -0,0 +1,316 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	connectionsApp "github.com/bowerbird/internal/connections/application"
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	inboxApp "github.com/bowerbird/internal/inbox/application"
+	"github.com/bowerbird/internal/platform/audit"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+type memoryErasures struct {
+	ports.AccountErasureRepository
+
+	due         []*domain.AccountErasure
+	owned       []string
+	tenants     []domain.ErasureTenant
+	owners      map[string]string
+	identifiers []string
+	completed   *domain.ErasureReport
+	failed      string
+	erasedUser  string
+	profiles    []string
+}
+
+func (r *memoryErasures) ClaimDueAccountErasures(ctx context.Context, now time.Time, staleAfter time.Duration, maxAttempts int) ([]*domain.AccountErasure, error) {
+	return r.due, nil
+}
+
+func (r *memoryErasures) FindOwnedOrganizations(ctx context.Context, userID string) ([]string, error) {
+	return r.owned, nil
+}
+
+func (r *memoryErasures) FindErasureTenants(ctx context.Context, userID string) ([]domain.ErasureTenant, error) {
+	return r.tenants, nil
+}
+
+func (r *memoryErasures) FindUserForErasure(ctx context.Context, userID string) (*domain.User, error) {
+	return &domain.User{ID: userID, Email: "ana@example.com"}, nil
+}
+
+func (r *memoryErasures) SaveErasureIdentifiers(ctx context.Context, erasureID string, identifiers []string) error {
+	r.identifiers = identifiers
+	return nil
+}
+
+// SaveErasureProgress stores the report on the claimed erasure, as the next
+// claim would read it back.
+func (r *memoryErasures) SaveErasureProgress(ctx context.Context, erasureID string, report *domain.ErasureReport) error {
+	for _, erasure := range r.due {
+		if erasure.ID == erasureID {
+			saved := *report
+			saved.Tenants = slices.Clone(report.Tenants)
+			erasure.Report = &saved
+		}
+	}
+	return nil
+}
+
+func (r *memoryErasures) FindTenantOwner(ctx context.Context, tenantID, excludeUserID string) (string, error) {
+	return r.owners[tenantID], nil
+}
+
+func (r *memoryErasures) AnonymizeTenantUserProfile(ctx context.Context, tenantDBName, userID, email string) error {
+	r.profiles = append(r.profiles, tenantDBName)
+	return nil
+}
+
+func (r *memoryErasures) EraseUser(ctx context.Context, userID, email string) (domain.ControlPlaneErasureReport, error) {
+	r.erasedUser = email
+	return domain.ControlPlaneErasureReport{IdentitiesDeleted: 1}, nil
+}
+
+func (r *memoryErasures) CompleteAccountErasure(ctx context.Context, erasureID string, report *domain.ErasureReport) error {
+	r.completed = report
+	return nil
+}
+
+func (r *memoryErasures) FailAccountErasure(ctx context.Context, erasureID, message string) error {
+	r.failed = message
+	return nil
+}
+
+type fakeConnections struct {
+	connectionsApp.InternalService
+
+	owned       map[string][]connectionsApp.ConnectionInfo
+	revokeErr   error
+	revoked     []string
+	transferred map[string]string
+	deleted     []string
+}
+
+func (f *fakeConnections) ListOwnedConnections(ctx context.Context, ownerUserID string) ([]connectionsApp.ConnectionInfo, error) {
+	tenantID, _ := tenant.TenantIDFromContext(ctx)
+	return f.owned[tenantID], nil
+}
+
+func (f *fakeConnections) RevokeCredentials(ctx context.Context, connectionID string) error {
+	f.revoked = append(f.revoked, connectionID)
+	return f.revokeErr
+}
+
+func (f *fakeConnections) TransferConnection(ctx context.Context, connectionID, ownerUserID, reason string) error {
+	f.transferred[connectionID] = ownerUserID
+	return nil
+}
+
+func (f *fakeConnections) DeleteConnection(ctx context.Context, connectionID string) error {
+	f.deleted = append(f.deleted, connectionID)
+	return nil
+}
+
+type fakeInbox struct {
+	purged []string
+}
+
+func (f *fakeInbox) PurgeConnectionMessages(ctx context.Context, connectionID string) (inboxApp.MessagePurgeResult, error) {
+	f.purged = append(f.purged, connectionID)
+	return inboxApp.MessagePurgeResult{Messages: 3, Attachments: 2, RetainedAttachments: 1}, nil
+}
+
+type recordingPurger struct {
+	inputs []platformStorage.DeletePrefixInput
+}
+
+func (p *recordingPurger) DeletePrefix(ctx context.Context, input platformStorage.DeletePrefixInput) (int, error) {
+	p.inputs = append(p.inputs, input)
+	return 1, nil
+}
+
+type recordingAnonymizer struct {
+	anonymizations []audit.Anonymization
+}
+
+func (a *recordingAnonymizer) Anonymize(ctx context.Context, anonymization audit.Anonymization) (int, error) {
+	a.anonymizations = append(a.anonymizations, anonymization)
+	return 4, nil
+}
+
+type recordingAudit struct {
+	events []audit.Event
+}
+
+func (r *recordingAudit) Record(ctx context.Context, event audit.Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func newErasureFixture() (*memoryErasures, *fakeConnections, *fakeInbox, *recordingPurger, *recordingAnonymizer, *recordingAudit) {
+	repo := &memoryErasures{
+		due: []*domain.AccountErasure{{ID: "erasure-1", UserID: "user-1", Status: domain.ErasureStatusRunning}},
+		tenants: []domain.ErasureTenant{
+			{TenantID: "tenant-1", Slug: "acme", DBName: "db_acme", Status: "active"},
+			{TenantID: "tenant-2", Slug: "gone", DBName: "db_gone", Status: "deleting"},
+		},
+		owners: map[string]string{"tenant-1": "owner-1"},
+	}
+	connections := &fakeConnections{
+		owned: map[string][]connectionsApp.ConnectionInfo{
+			"tenant-1": {
+				{ID: "private-1", ProviderAccountEmail: "ana.personal@gmail.com", SharingPolicy: "private"},
+				{ID: "shared-1", ProviderAccountEmail: "facturas@acme.test", SharingPolicy: "tenant_all"},
+			},
+		},
+		transferred: map[string]string{},
+	}
+	return repo, connections, &fakeInbox{}, &recordingPurger{}, &recordingAnonymizer{}, &recordingAudit{}
+}
+
+func TestRunAccountErasuresErasesPrivateDataAndTransfersSharedConnections(t *testing.T) {
+	repo, connections, inbox, purger, anonymizer, recorder := newErasureFixture()
+
+	erased, err := NewRunAccountErasuresCommand(repo, connections, inbox, purger, anonymizer, recorder).Execute(context.Background())
+	if err != nil || erased != 1 {
+		t.Fatalf("expected one erased account, got %d, %v", erased, err)
+	}
+
+	if !slices.Equal(connections.revoked, []string{"private-1", "shared-1"}) {
+		t.Fatalf("expected both grants to be revoked, got %v", connections.revoked)
+	}
+	if !slices.Equal(inbox.purged, []string{"private-1"}) || !slices.Equal(connections.deleted, []string{"private-1"}) {
+		t.Fatalf("expected only the private connection to be purged and deleted, got %v %v", inbox.purged, connections.deleted)
+	}
+	if connections.transferred["shared-1"] != "owner-1" {
+		t.Fatalf("expected the shared connection to go to the owner, got %v", connections.transferred)
+	}
+
+	if len(purger.inputs) != 2 || purger.inputs[0].Prefix != "1-day/tenants/tenant-1/uploads/" || purger.inputs[1].Prefix != "1-day/tenants/acme/uploads/" {
+		t.Fatalf("expected the uploads under the tenant ID and slug, got %+v", purger.inputs)
+	}
+	match := purger.inputs[0].Match
+	if !match("1-day/tenants/tenant-1/uploads/invoices/user-1/a.pdf") || match("1-day/tenants/tenant-1/uploads/invoices/user-2/a.pdf") {
+		t.Fatal("expected the match to select the user's uploads only")
+	}
+
+	expectedIdentifiers := []string{"ana@example.com", "ana.personal@gmail.com", "facturas@acme.test"}
+	if !slices.Equal(repo.identifiers, expectedIdentifiers) {
+		t.Fatalf("expected identifiers %v, got %v", expectedIdentifiers, repo.identifiers)
+	}
+	if len(anonymizer.anonymizations) != 1 || anonymizer.anonymizations[0].Replacement != domain.ErasedEmail("user-1") {
+		t.Fatalf("expected the tenant's audit log to be anonymized, got %+v", anonymizer.anonymizations)
+	}
+	if len(recorder.events) != 1 || recorder.events[0].Action != audit.ActionAccountErased {
+		t.Fatalf("expected an account.erased event, got %+v", recorder.events)
+	}
+	if !slices.Equal(repo.profiles, []string{"db_acme"}) || repo.erasedUser != domain.ErasedEmail("user-1") {
+		t.Fatalf("expected the profile and user to be anonymized, got %v %q", repo.profiles, repo.erasedUser)
+	}
+
+	report := repo.completed
+	if report == nil || len(report.Tenants) != 2 {
+		t.Fatalf("expected a report covering both tenants, got %+v", report)
+	}
+	active := report.Tenants[0]
+	if active.ConnectionsDeleted != 1 || active.ConnectionsTransferred != 1 || active.TokensRevoked != 2 || active.MessagesDeleted != 3 || active.AttachmentsRetained != 1 || active.UploadsDeleted != 2 {
+		t.Fatalf("unexpected tenant report %+v", active)
+	}
+	if report.Tenants[1].Skipped == "" {
+		t.Fatalf("expected the organization being deleted to be skipped, got %+v", report.Tenants[1])
+	}
+}
+
+func TestRunAccountErasuresErasesInactiveOrganizations(t *testing.T) {
+	repo, connections, inbox, purger, anonymizer, recorder := newErasureFixture()
+	repo.tenants[0].Status = "suspended"
+
+	if _, err := NewRunAccountErasuresCommand(repo, connections, inbox, purger, anonymizer, recorder).Execute(context.Background()); err != nil {
+		t.Fatalf("erasure failed: %v", err)
+	}
+
+	if len(connections.deleted) != 1 || !slices.Equal(repo.profiles, []string{"db_acme"}) || repo.completed.Tenants[0].Skipped != "" {
+		t.Fatalf("expected the suspended organization to be erased, got %+v", repo.completed)
+	}
+}
+
+func TestRunAccountErasuresWaitsForOrganizationsInMaintenance(t *testing.T) {
+	repo, connections, inbox, purger, anonymizer, recorder := newErasureFixture()
+	repo.tenants[0].Status = "maintenance"
+
+	_, err := NewRunAccountErasuresCommand(repo, connections, inbox, purger, anonymizer, recorder).Execute(context.Background())
+	if !errors.Is(err, domain.ErrErasureTenantUnavailable) {
+		t.Fatalf("expected ErrErasureTenantUnavailable, got %v", err)
+	}
+	if repo.failed == "" || repo.completed != nil || repo.erasedUser != "" {
+		t.Fatal("expected the erasure to stay pending")
+	}
+}
+
+func TestRunAccountErasuresRetrySkipsTenantsAlreadyErased(t *testing.T) {
+	repo, connections, inbox, purger, anonymizer, recorder := newErasureFixture()
+	repo.tenants = append(repo.tenants, domain.ErasureTenant{TenantID: "tenant-3", Slug: "beta", DBName: "db_beta", Status: "maintenance"})
+	cmd := NewRunAccountErasuresCommand(repo, connections, inbox, purger, anonymizer, recorder)
+
+	if _, err := cmd.Execute(context.Background()); !errors.Is(err, domain.ErrErasureTenantUnavailable) {
+		t.Fatalf("expected ErrErasureTenantUnavailable, got %v", err)
+	}
+	repo.tenants[2].Status = "active"
+	if _, err := cmd.Execute(context.Background()); err != nil {
+		t.Fatalf("retry failed: %v", err)
+	}
+
+	erased := 0
+	for _, event := range recorder.events {
+		if event.Action == audit.ActionAccountErased {
+			erased++
+		}
+	}
+	if erased != 2 {
+		t.Fatalf("expected account.erased once per erased tenant, got %d", erased)
+	}
+	if !slices.Equal(repo.profiles, []string{"db_acme", "db_beta"}) {
+		t.Fatalf("expected each profile to be anonymized once, got %v", repo.profiles)
+	}
+	report := repo.completed
+	if report == nil || len(report.Tenants) != 3 || report.Tenants[0].ConnectionsDeleted != 1 {
+		t.Fatalf("expected the report to keep the first attempt's counts, got %+v", report)
+	}
+}
+
+func TestRunAccountErasuresDeletesSharedConnectionsWithoutOwner(t *testing.T) {
+	repo, connections, inbox, purger, anonymizer, recorder := newErasureFixture()
+	repo.owners = map[string]string{}
+	connections.revokeErr = errors.New("provider unavailable")
+
+	if _, err := NewRunAccountErasuresCommand(repo, connections, inbox, purger, anonymizer, recorder).Execute(context.Background()); err != nil {
+		t.Fatalf("erasure failed: %v", err)
+	}
+
+	if len(connections.transferred) != 0 || len(connections.deleted) != 2 {
+		t.Fatalf("expected both connections to be deleted, got transferred %v deleted %v", connections.transferred, connections.deleted)
+	}
+	if repo.completed.Tenants[0].TokenRevocationsFailed != 2 {
+		t.Fatalf("expected the failed revocations in the report, got %+v", repo.completed.Tenants[0])
+	}
+}
+
+func TestRunAccountErasuresFailsWhileUserOwnsOrganizations(t *testing.T) {
+	repo, connections, inbox, purger, anonymizer, recorder := newErasureFixture()
+	repo.owned = []string{"acme"}
+
+	_, err := NewRunAccountErasuresCommand(repo, connections, inbox, purger, anonymizer, recorder).Execute(context.Background())
+	if !errors.Is(err, domain.ErrAccountOwnsOrganizations) {
+		t.Fatalf("expected ErrAccountOwnsOrganizations, got %v", err)
+	}
+	if repo.failed == "" || repo.completed != nil || len(connections.revoked) != 0 {
+		t.Fatal("expected the erasure to fail before touching any data")
+	}
+}
@@ -0,0 +1,265 @@
+package commands
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+type ConfigureSSOInput struct {
+	TenantID string
+	Issuer   string
+	ClientID string
+	// ClientSecret may be left empty on updates that keep the issuer and
+	// client id, so admins do not have to re-enter it.
+	ClientSecret   string
+	AllowedDomains []string
+	SSOOnly        bool
+}
+
+// SSOService manages organization identity providers and signs users in
+// through them.
+type SSOService struct {
+	auth        *AuthService
+	oidc        ports.OIDCClient
+	callbackURL string
+}
+
+func NewSSOService(authService *AuthService, oidc ports.OIDCClient, callbackURL string) *SSOService {
+	return &SSOService{auth: authService, oidc: oidc, callbackURL: callbackURL}
+}
+
+// Configure creates or replaces the organization's connection. Enabling
+// SSO-only requires the admin to be signed in through it, which proves the
+// provider works before every other session is revoked.
+func (s *SSOService) Configure(ctx context.Context, actor *auth.CustomClaims, input ConfigureSSOInput) (*domain.SSOConnection, error) {
+	now := s.auth.now().UTC()
+
+	connection, err := domain.NewSSOConnection(input.TenantID, input.Issuer, input.ClientID, input.AllowedDomains, input.SSOOnly, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if connection.SSOOnly && actor.SSOTenantID != connection.TenantID {
+		return nil, domain.ErrSSOSessionRequired
+	}
+
+	existing, err := s.auth.repo.FindSSOConnection(ctx, connection.TenantID)
+	if err != nil && !errors.Is(err, domain.ErrSSONotConfigured) {
+		return nil, err
+	}
+
+	switch {
+	case input.ClientSecret != "":
+		connection.EncryptedClientSecret, err = s.auth.cipher.Encrypt([]byte(input.ClientSecret))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt client secret: %w", err)
+		}
+	case existing != nil && existing.Issuer == connection.Issuer && existing.ClientID == connection.ClientID:
+		connection.EncryptedClientSecret = existing.EncryptedClientSecret
+	default:
+		return nil, fmt.Errorf("%w: client secret is required", domain.ErrInvalidSSOConfig)
+	}
+
+	if existing != nil {
+		connection.CreatedAt = existing.CreatedAt
+	}
+
+	if err := s.oidc.Validate(ctx, connection.Issuer); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSSOConfig, err)
+	}
+
+	if err := s.auth.repo.SaveSSOConnection(ctx, connection); err != nil {
+		return nil, err
+	}
+
+	return connection, nil
+}
+
+func (s *SSOService) Get(ctx context.Context, tenantID string) (*domain.SSOConnection, error) {
+	return s.auth.repo.FindSSOConnection(ctx, tenantID)
+}
+
+func (s *SSOService) Delete(ctx context.Context, tenantID string) error {
+	return s.auth.repo.DeleteSSOConnection(ctx, tenantID)
+}
+
+// StartLogin returns the provider URL to redirect to and the binding value the
+// caller must keep in the browser until the callback.
+func (s *SSOService) StartLogin(ctx context.Context, tenantRef string) (string, string, error) {
+	connection, err := s.auth.repo.FindSSOConnection(ctx, tenantRef)
+	if err != nil {
+		return "", "", err
+	}
+
+	config, err := s.clientConfig(connection)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := domain.NewSSOLoginState(connection.TenantID, s.auth.now().UTC())
+	if err != nil {
+		return "", "", err
+	}
+
+	sealed, err := s.sealState(state)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.oidc.AuthCodeURL(ctx, config, sealed, state.Nonce, state.CodeVerifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	return authURL, state.Binding, nil
+}
+
+// CompleteLogin handles the provider callback. The session it issues is bound
+// to the organization, and carries MFA when the provider reports it; accounts
+// signing in through SSO are not challenged for their own authenticator.
+func (s *SSOService) CompleteLogin(ctx context.Context, sealedState, binding, code string) (*auth.TokenPair, error) {
+	state, err := s.openState(sealedState)
+	if err != nil {
+		return nil, err
+	}
+	if s.auth.now().After(state.ExpiresAt) || subtle.ConstantTimeCompare([]byte(state.Binding), []byte(binding)) != 1 {
+		return nil, domain.ErrInvalidSSOLoginState
+	}
+
+	connection, err := s.auth.repo.FindSSOConnection(ctx, state.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.clientConfig(connection)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.oidc.Authenticate(ctx, config, code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("sso authentication failed: %w", err)
+	}
+
+	if profile.Email == "" || (profile.EmailVerified != nil && !*profile.EmailVerified) {
+		return nil, ErrEmailNotVerified
+	}
+	if !connection.AllowsEmail(profile.Email) {
+		return nil, domain.ErrSSODomainNotAllowed
+	}
+
+	user, err := s.resolveUser(ctx, connection, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []auth.TokenOption{auth.WithSSOTenant(connection.TenantID)}
+	if profile.MFA {
+		opts = append(opts, auth.WithMFA())
+	}
+
+	return s.auth.tokenGen.GenerateTokens(user.ID, user.Email, user.FirstName, user.LastName, user.PictureURL, opts...)
+}
+
+// resolveUser finds the account linked to the provider subject, links an
+// existing member by email, or creates a new account. Accounts that are not
+// members are never linked: allowed domains are not verified, so the provider
+// could otherwise assert any address and take the account over.
+func (s *SSOService) resolveUser(ctx context.Context, connection *domain.SSOConnection, profile *domain.SSOProfile) (*domain.User, error) {
+	identity, err := s.auth.repo.FindUserIdentity(ctx, connection.Provider(), profile.Subject)
+	if err == nil {
+		return s.auth.repo.FindUserByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	user, err := s.auth.repo.FindUserByEmail(ctx, profile.Email)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, fmt.Errorf("failed to lookup user: %w", err)
+	}
+
+	if user != nil {
+		if _, err := s.auth.repo.FindTenantMembership(ctx, user.ID, connection.TenantID); err != nil {
+			if errors.Is(err, domain.ErrMembershipNotFound) {
+				return nil, domain.ErrSSOAccountNotLinked
+			}
+			return nil, err
+		}
+		if err := s.auth.claimUnverifiedAccount(ctx, user); err != nil {
+			return nil, err
+		}
+	} else {
+		user = domain.NewUser(id.NewULID(), profile.Email, profile.Name, "", "")
+		verifiedAt := s.auth.now().UTC()
+		user.EmailVerifiedAt = &verifiedAt
+		if err := s.auth.repo.CreateUser(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to create user: %w", err)
+		}
+	}
+
+	identity = domain.NewUserIdentity(id.NewULID(), user.ID, connection.Provider(), profile.Subject)
+	if err := s.auth.repo.CreateUserIdentity(ctx, identity); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return user, nil
+}
+
+func (s *SSOService) clientConfig(connection *domain.SSOConnection) (ports.OIDCClientConfig, error) {
+	secret, err := s.auth.cipher.Decrypt(connection.EncryptedClientSecret)
+	if err != nil {
+		return ports.OIDCClientConfig{}, fmt.Errorf("failed to decrypt client secret: %w", err)
+	}
+
+	return ports.OIDCClientConfig{
+		Issuer:       connection.Issuer,
+		ClientID:     connection.ClientID,
+		ClientSecret: string(secret),
+		RedirectURL:  s.callbackURL,
+	}, nil
+}
+
+// sealState encrypts the login state into the OAuth state parameter, so the
+// flow needs no server-side storage and the provider cannot read or alter it.
+func (s *SSOService) sealState(state *domain.SSOLoginState) (string, error) {
+	payload, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := s.auth.cipher.Encrypt(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to seal sso state: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func (s *SSOService) openState(sealed string) (*domain.SSOLoginState, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, domain.ErrInvalidSSOLoginState
+	}
+
+	payload, err := s.auth.cipher.Decrypt(raw)
+	if err != nil {
+		return nil, domain.ErrInvalidSSOLoginState
+	}
+
+	var state domain.SSOLoginState
+	if err := json.Unmarshal(payload, &state); err != nil || state.TenantID == "" {
+		return nil, domain.ErrInvalidSSOLoginState
+	}
+
+	return &state, nil
+}
@@ -4,21 +4,25 @@ import (
 	"context"
 
 	"github.com/bowerbird/internal/identity/application/commands"
-	"github.com/bowerbird/internal/identity/application/ports"
 	"github.com/bowerbird/internal/identity/application/queries"
+	"github.com/bowerbird/internal/identity/domain"
 )
 
 type IdentityService struct {
 	listUserTenants *queries.ListUserTenantsQuery
 	leaveTenant     *commands.LeaveTenantCommand
-	deleteAccount   *commands.DeleteAccountCommand
+	requestErasure  *commands.RequestAccountErasureCommand
+	cancelErasure   *commands.CancelAccountErasureCommand
+	getErasure      *queries.GetAccountErasureQuery
 }
 
-func NewIdentityService(repo ports.Repository) *IdentityService {
+func NewIdentityService(app *Application) *IdentityService {
 	return &IdentityService{
-		listUserTenants: queries.NewListUserTenantsQuery(repo),
-		leaveTenant:     commands.NewLeaveTenantCommand(repo),
-		deleteAccount:   commands.NewDeleteAccountCommand(repo),
+		listUserTenants: app.Queries.ListUserTenants,
+		leaveTenant:     app.Commands.LeaveTenant,
+		requestErasure:  app.Commands.RequestErasure,
+		cancelErasure:   app.Commands.CancelErasure,
+		getErasure:      app.Queries.GetErasure,
 	}
 }
 
@@ -32,6 +36,15 @@ func (s *IdentityService) LeaveTenant(ctx context.Context, userID, tenantID stri
 	return s.leaveTenant.Execute(ctx, userID, tenantID)
 }
 
-func (s *IdentityService) DeleteAccount(ctx context.Context, userID string) error {
-	return s.deleteAccount.Execute(ctx, userID)
+// RequestAccountErasure schedules the erasure of the account after the grace period.
+func (s *IdentityService) RequestAccountErasure(ctx context.Context, userID string) (*domain.AccountErasure, error) {
+	return s.requestErasure.Execute(ctx, userID)
+}
+
+func (s *IdentityService) CancelAccountErasure(ctx context.Context, userID string) error {
+	return s.cancelErasure.Execute(ctx, userID)
+}
+
+func (s *IdentityService) GetAccountErasure(ctx context.Context, userID string) (*domain.AccountErasure, error) {
+	return s.getErasure.Execute(ctx, userID)
 }
@@ -0,0 +1,7 @@
+package ports
+
+// SecretCipher protects secrets stored at rest, such as TOTP seeds.
+type SecretCipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerbird/internal/identity/domain"
+)
+
+// AccountErasureRepository tracks erasure requests in the control plane and
+// erases the user's records there and in tenant profiles.
+type AccountErasureRepository interface {
+	CreateAccountErasure(ctx context.Context, erasure *domain.AccountErasure) error
+	FindOpenAccountErasure(ctx context.Context, userID string) (*domain.AccountErasure, error)
+	FindLatestAccountErasure(ctx context.Context, userID string) (*domain.AccountErasure, error)
+	CancelAccountErasure(ctx context.Context, erasureID string) error
+	// ClaimDueAccountErasures moves due erasures, failed ones under the
+	// attempt limit and runs stuck for longer than staleAfter to running.
+	ClaimDueAccountErasures(ctx context.Context, now time.Time, staleAfter time.Duration, maxAttempts int) ([]*domain.AccountErasure, error)
+	SaveErasureIdentifiers(ctx context.Context, erasureID string, identifiers []string) error
+	// SaveErasureProgress stores the report of the tenants erased so far.
+	SaveErasureProgress(ctx context.Context, erasureID string, report *domain.ErasureReport) error
+	CompleteAccountErasure(ctx context.Context, erasureID string, report *domain.ErasureReport) error
+	FailAccountErasure(ctx context.Context, erasureID, message string) error
+
+	// FindUserForErasure also returns users whose account is already erased.
+	FindUserForErasure(ctx context.Context, userID string) (*domain.User, error)
+	// FindOwnedOrganizations lists the slugs of the organizations the user
+	// owns that are not already scheduled for deletion.
+	FindOwnedOrganizations(ctx context.Context, userID string) ([]string, error)
+	// FindErasureTenants includes the organizations the user has left.
+	FindErasureTenants(ctx context.Context, userID string) ([]domain.ErasureTenant, error)
+	// FindTenantOwner returns another owner of the organization, or "".
+	FindTenantOwner(ctx context.Context, tenantID, excludeUserID string) (string, error)
+	AnonymizeTenantUserProfile(ctx context.Context, tenantDBName, userID, email string) error
+	EraseUser(ctx context.Context, userID, email string) (domain.ControlPlaneErasureReport, error)
+}
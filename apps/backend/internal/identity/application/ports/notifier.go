@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerbird/internal/identity/domain"
+)
+
+// AccountNotifier delivers the single-use tokens of the local credentials flows.
+type AccountNotifier interface {
+	SendEmailVerification(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error
+	SendPasswordReset(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error
+}
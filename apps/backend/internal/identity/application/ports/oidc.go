@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/identity/domain"
+)
+
+// OIDCClientConfig identifies the relying party at an organization's provider.
+type OIDCClientConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// OIDCClient runs the authorization code flow (with PKCE and nonce) against
+// an OpenID provider.
+type OIDCClient interface {
+	// Validate checks that the issuer publishes usable discovery metadata.
+	Validate(ctx context.Context, issuer string) error
+	AuthCodeURL(ctx context.Context, config OIDCClientConfig, state, nonce, codeVerifier string) (string, error)
+	// Authenticate redeems the code and returns the verified ID token claims.
+	Authenticate(ctx context.Context, config OIDCClientConfig, code, codeVerifier, nonce string) (*domain.SSOProfile, error)
+}
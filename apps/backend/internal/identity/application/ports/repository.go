@@ -2,6 +2,7 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/bowerbird/internal/identity/domain"
 )
@@ -13,8 +14,36 @@ type Repository interface {
 	FindUserIdentityByProvider(ctx context.Context, userID, provider string) (*domain.UserIdentity, error)
 	FindUserByID(ctx context.Context, userID string) (*domain.User, error)
 	FindTenantMemberships(ctx context.Context, userID string) ([]*domain.TenantMembership, error)
-	RemoveTenantMembership(ctx context.Context, userID, tenantID string) error
+	FindTenantMembership(ctx context.Context, userID, tenantRef string) (*domain.TenantMembership, error)
+	FindTenantUserPermissions(ctx context.Context, dbName, userID string) ([]string, error)
+	// RemoveTenantMembership reports whether an active membership was removed.
+	RemoveTenantMembership(ctx context.Context, userID, tenantID string) (bool, error)
 	GetTenantDBName(ctx context.Context, tenantID string) (string, error)
 	SoftDeleteTenantUserProfile(ctx context.Context, dbName, userID string) error
 	SoftDeleteUser(ctx context.Context, userID string) error
+
+	MarkEmailVerified(ctx context.Context, userID string, at time.Time) error
+	UpdatePasswordHash(ctx context.Context, userID, passwordHash string) error
+	DeleteUserIdentity(ctx context.Context, userID, provider string) error
+	CreateAuthToken(ctx context.Context, token *domain.AuthToken) error
+	FindAuthToken(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, now time.Time) (*domain.AuthToken, error)
+	ConsumeAuthToken(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, now time.Time) (*domain.AuthToken, error)
+	RecordAuthTokenFailure(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, maxFailures int, now time.Time) error
+	RecordLoginAttempt(ctx context.Context, attempt domain.LoginAttempt) error
+	CountLoginFailures(ctx context.Context, email, ipAddress string, since time.Time) (domain.LoginFailures, error)
+
+	FindMFAFactor(ctx context.Context, userID string) (*domain.MFAFactor, error)
+	SavePendingMFAFactor(ctx context.Context, factor *domain.MFAFactor) error
+	ConfirmMFAFactor(ctx context.Context, userID string, step int64, recoveryCodeHashes [][]byte, at time.Time) error
+	AdvanceMFAStep(ctx context.Context, userID string, step int64) error
+	DeleteMFAFactor(ctx context.Context, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes [][]byte, at time.Time) error
+	ConsumeRecoveryCode(ctx context.Context, userID string, codeHash []byte, at time.Time) error
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+
+	FindUserIdentity(ctx context.Context, provider, providerID string) (*domain.UserIdentity, error)
+
+	FindSSOConnection(ctx context.Context, tenantRef string) (*domain.SSOConnection, error)
+	SaveSSOConnection(ctx context.Context, connection *domain.SSOConnection) error
+	DeleteSSOConnection(ctx context.Context, tenantID string) error
 }
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/identity/domain"
+)
+
+// SealedSecretRepository reads and rewrites the TOTP secrets and SSO client
+// secrets stored in the control plane.
+type SealedSecretRepository interface {
+	ListSealedSecrets(ctx context.Context) ([]domain.SealedSecret, error)
+	// ReplaceSealedSecret swaps the ciphertext only while it is unchanged and
+	// reports whether it did.
+	ReplaceSealedSecret(ctx context.Context, secret domain.SealedSecret, replacement []byte) (bool, error)
+}
+
+// SecretRewrapper moves sealed secrets under the active master key and
+// reports whether they changed. KeyID names the master key that seals
+// ciphertext, "" for the format before envelopes.
+type SecretRewrapper interface {
+	Rewrap(ciphertext []byte) ([]byte, bool, error)
+	KeyID(ciphertext []byte) string
+	ActiveKeyID() string
+}
+
+// KeyUsageRecorder keeps the records each master key seals, so a key is not
+// retired while stored secrets still need it.
+type KeyUsageRecorder interface {
+	RecordKeyUsage(ctx context.Context, scope string, usage map[string]int, complete bool) error
+}
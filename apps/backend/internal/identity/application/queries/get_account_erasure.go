@@ -0,0 +1,34 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+)
+
+type GetAccountErasureQuery struct {
+	repo ports.AccountErasureRepository
+}
+
+func NewGetAccountErasureQuery(repo ports.AccountErasureRepository) *GetAccountErasureQuery {
+	if repo == nil {
+		panic("account erasure repository is required")
+	}
+
+	return &GetAccountErasureQuery{repo: repo}
+}
+
+// Execute returns the user's most recent erasure request.
+func (q *GetAccountErasureQuery) Execute(ctx context.Context, userID string) (*domain.AccountErasure, error) {
+	erasure, err := q.repo.FindLatestAccountErasure(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("find account erasure: %w", err)
+	}
+	if erasure == nil {
+		return nil, domain.ErrErasureNotFound
+	}
+
+	return erasure, nil
+}
@@ -11,6 +11,8 @@ type TenantMembershipDTO struct {
 	TenantID string `json:"tenant_id"`
 	Name     string `json:"name"`
 	Role     string `json:"role"`
+	// MFARequired tells the client to enroll or verify MFA before switching to the organization.
+	MFARequired bool `json:"mfa_required"`
 }
 
 type ListUserTenantsQuery struct {
@@ -30,9 +32,10 @@ func (q *ListUserTenantsQuery) Execute(ctx context.Context, userID string) ([]Te
 	dtos := make([]TenantMembershipDTO, len(memberships))
 	for i, m := range memberships {
 		dtos[i] = TenantMembershipDTO{
-			TenantID: m.TenantID,
-			Name:     m.Name,
-			Role:     string(m.Role),
+			TenantID:    m.TenantID,
+			Name:        m.Name,
+			Role:        string(m.Role),
+			MFARequired: m.MFARequired,
 		}
 	}
 
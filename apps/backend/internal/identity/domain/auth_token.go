@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidAuthToken = errors.New("invalid or expired token")
+
+// AuthTokenPurpose scopes a one-time token so a verification link can never be replayed as a reset link.
+type AuthTokenPurpose string
+
+const (
+	PurposeEmailVerification AuthTokenPurpose = "email_verification"
+	PurposePasswordReset     AuthTokenPurpose = "password_reset"
+	// PurposeMFAChallenge links the two steps of a login for accounts with MFA.
+	PurposeMFAChallenge AuthTokenPurpose = "mfa_challenge"
+)
+
+const (
+	EmailVerificationTokenTTL = 48 * time.Hour
+	PasswordResetTokenTTL     = time.Hour
+)
+
+// AuthToken is a single-use token delivered by email. Only its SHA-256 is persisted.
+type AuthToken struct {
+	ID         string
+	UserID     string
+	Purpose    AuthTokenPurpose
+	TokenHash  []byte
+	ExpiresAt  time.Time
+	ConsumedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// NewAuthToken returns the token to store and the plaintext value to send.
+func NewAuthToken(id, userID string, purpose AuthTokenPurpose, ttl time.Duration, now time.Time) (*AuthToken, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("generate auth token: %w", err)
+	}
+	plaintext := base64.RawURLEncoding.EncodeToString(raw)
+
+	return &AuthToken{
+		ID:        id,
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: HashAuthToken(plaintext),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, plaintext, nil
+}
+
+func HashAuthToken(plaintext string) []byte {
+	sum := sha256.Sum256([]byte(plaintext))
+	return sum[:]
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultErasureGracePeriod matches the organization deletion grace period:
+// the account keeps working until then and the request can be cancelled.
+const DefaultErasureGracePeriod = 30 * 24 * time.Hour
+
+const (
+	ErasureStatusScheduled = "scheduled"
+	ErasureStatusRunning   = "running"
+	ErasureStatusCompleted = "completed"
+	ErasureStatusFailed    = "failed"
+	ErasureStatusCancelled = "cancelled"
+)
+
+var (
+	ErrErasureNotFound          = errors.New("account erasure not found")
+	ErrErasureNotCancellable    = errors.New("account erasure can no longer be cancelled")
+	ErrAccountOwnsOrganizations = errors.New("account owns organizations that are not being deleted")
+	ErrErasureTenantUnavailable = errors.New("organization is being provisioned or migrated")
+)
+
+// AccountErasure is a request to erase a user's personal data. Identifiers
+// holds the addresses to scrub from audit logs; it is captured before any
+// data is deleted, so a retried run still knows them, and cleared once the
+// erasure completes.
+type AccountErasure struct {
+	ID           string
+	UserID       string
+	Status       string
+	RequestedAt  time.Time
+	ScheduledFor time.Time
+	StartedAt    *time.Time
+	CompletedAt  *time.Time
+	Attempts     int
+	Identifiers  []string
+	Report       *ErasureReport
+	Error        string
+}
+
+func NewAccountErasure(id, userID string, now time.Time, gracePeriod time.Duration) *AccountErasure {
+	now = now.UTC()
+	return &AccountErasure{
+		ID:           id,
+		UserID:       userID,
+		Status:       ErasureStatusScheduled,
+		RequestedAt:  now,
+		ScheduledFor: now.Add(gracePeriod),
+	}
+}
+
+// Cancel stops a scheduled erasure; once it has started, data may already
+// be gone.
+func (e *AccountErasure) Cancel() error {
+	if e.Status != ErasureStatusScheduled {
+		return ErrErasureNotCancellable
+	}
+	e.Status = ErasureStatusCancelled
+	return nil
+}
+
+// ErasedTenant returns the report of a tenant an earlier attempt already
+// erased, so a retry neither processes nor records it again.
+func (e *AccountErasure) ErasedTenant(tenantID string) (TenantErasureReport, bool) {
+	if e.Report == nil {
+		return TenantErasureReport{}, false
+	}
+	for _, t := range e.Report.Tenants {
+		if t.TenantID == tenantID && t.Skipped == "" {
+			return t, true
+		}
+	}
+	return TenantErasureReport{}, false
+}
+
+// ErasureReport summarizes what an erasure removed, transferred or
+// anonymized. It holds counts and IDs only, never the erased data.
+type ErasureReport struct {
+	UserID       string                    `json:"user_id"`
+	CompletedAt  time.Time                 `json:"completed_at"`
+	Tenants      []TenantErasureReport     `json:"tenants"`
+	ControlPlane ControlPlaneErasureReport `json:"control_plane"`
+}
+
+type TenantErasureReport struct {
+	TenantID string `json:"tenant_id"`
+	// Skipped explains why the tenant's data was not processed, e.g. the
+	// organization is being deleted as a whole.
+	Skipped                string `json:"skipped,omitempty"`
+	ConnectionsDeleted     int    `json:"connections_deleted"`
+	ConnectionsTransferred int    `json:"connections_transferred"`
+	TransferredTo          string `json:"transferred_to,omitempty"`
+	TokensRevoked          int    `json:"tokens_revoked"`
+	TokenRevocationsFailed int    `json:"token_revocations_failed"`
+	MessagesDeleted        int    `json:"messages_deleted"`
+	AttachmentsDeleted     int    `json:"attachments_deleted"`
+	AttachmentsRetained    int    `json:"attachments_retained"`
+	UploadsDeleted         int    `json:"uploads_deleted"`
+	AuditEventsAnonymized  int    `json:"audit_events_anonymized"`
+}
+
+type ControlPlaneErasureReport struct {
+	IdentitiesDeleted    int `json:"identities_deleted"`
+	MembershipsRemoved   int `json:"memberships_removed"`
+	LoginAttemptsDeleted int `json:"login_attempts_deleted"`
+}
+
+// ErasureTenant is an organization the user belongs or belonged to.
+type ErasureTenant struct {
+	TenantID    string
+	Slug        string
+	FormerSlugs []string
+	DBName      string
+	Status      string
+}
+
+// Purged reports whether the organization is being deleted as a whole, so
+// its purge takes the user's data with it.
+func (t ErasureTenant) Purged() bool {
+	return t.Status == "deleting"
+}
+
+// Settled reports whether the tenant database can be erased. While it is
+// being provisioned, migrated or recovered from a failed provisioning, its
+// data may be copied or dropped under the erasure, which has to wait.
+func (t ErasureTenant) Settled() bool {
+	switch t.Status {
+	case "provisioning", "failed", "maintenance":
+		return false
+	default:
+		return true
+	}
+}
+
+// ErasedEmail is the placeholder address an erased user keeps, unique per
+// user so the unique email constraints still hold.
+func ErasedEmail(userID string) string {
+	return "erased-" + userID + "@erased.invalid"
+}
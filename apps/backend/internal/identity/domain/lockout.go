@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+var (
+	ErrLoginLocked = errors.New("too many failed login attempts")
+	// ErrInvalidClientIP rejects attempts whose client address could not be
+	// resolved; without it they would escape the per-IP lockout.
+	ErrInvalidClientIP = errors.New("client ip address is invalid")
+)
+
+// passwordResetAttemptSource stands in for the client address of the
+// successful attempt a password reset records.
+const passwordResetAttemptSource = "password-reset"
+
+// LoginLockoutPolicy bounds failed local logins per account and per client IP
+// within a sliding window. The IP limit is higher so a shared office NAT does
+// not lock everyone out after a single user's typos.
+type LoginLockoutPolicy struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	Window             time.Duration
+}
+
+func DefaultLoginLockoutPolicy() LoginLockoutPolicy {
+	return LoginLockoutPolicy{
+		MaxAccountFailures: 5,
+		MaxIPFailures:      20,
+		Window:             15 * time.Minute,
+	}
+}
+
+// LoginFailures counts recent failures. Account failures only include those
+// after the account's last successful login.
+type LoginFailures struct {
+	Account int
+	IP      int
+}
+
+func (p LoginLockoutPolicy) IsLocked(failures LoginFailures) bool {
+	return failures.Account >= p.MaxAccountFailures || failures.IP >= p.MaxIPFailures
+}
+
+// LoginAttempt is one local login outcome, kept for lockout accounting.
+type LoginAttempt struct {
+	Email       string
+	IPAddress   string
+	Succeeded   bool
+	AttemptedAt time.Time
+}
+
+// NormalizeClientIP returns the canonical form of a client address, which
+// always fits login_attempts.ip_address.
+func NormalizeClientIP(raw string) (string, error) {
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return "", ErrInvalidClientIP
+	}
+	return ip.String(), nil
+}
+
+func NewLoginAttempt(email, ipAddress string, succeeded bool, attemptedAt time.Time) (LoginAttempt, error) {
+	ipAddress, err := NormalizeClientIP(ipAddress)
+	if err != nil {
+		return LoginAttempt{}, err
+	}
+
+	return LoginAttempt{
+		Email:       email,
+		IPAddress:   ipAddress,
+		Succeeded:   succeeded,
+		AttemptedAt: attemptedAt,
+	}, nil
+}
+
+// NewPasswordResetAttempt is the successful attempt that lifts the account
+// lockout once a reset link proved mailbox ownership.
+func NewPasswordResetAttempt(email string, attemptedAt time.Time) LoginAttempt {
+	return LoginAttempt{
+		Email:       email,
+		IPAddress:   passwordResetAttemptSource,
+		Succeeded:   true,
+		AttemptedAt: attemptedAt,
+	}
+}
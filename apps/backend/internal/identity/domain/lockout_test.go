@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewLoginAttemptNormalizesClientIP(t *testing.T) {
+	now := time.Now()
+
+	attempt, err := NewLoginAttempt("a@b.co", "2001:DB8:0:0:0:0:0:1", false, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if attempt.IPAddress != "2001:db8::1" {
+		t.Fatalf("expected canonical ipv6, got %q", attempt.IPAddress)
+	}
+
+	for _, raw := range []string{"", "unknown", "203.0.113.9, 10.0.0.1", strings.Repeat("1", 80)} {
+		if _, err := NewLoginAttempt("a@b.co", raw, false, now); !errors.Is(err, ErrInvalidClientIP) {
+			t.Fatalf("expected ErrInvalidClientIP for %q, got %v", raw, err)
+		}
+	}
+}
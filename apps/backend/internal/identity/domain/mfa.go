@@ -0,0 +1,174 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMFANotEnrolled    = errors.New("mfa is not enrolled")
+	ErrMFAAlreadyEnabled = errors.New("mfa is already enabled")
+	ErrInvalidMFACode    = errors.New("invalid mfa code")
+)
+
+const (
+	TOTPIssuer = "Bowerbird"
+	// RFC 6238 defaults, the only parameters every authenticator app supports.
+	totpDigits      = 6
+	totpPeriod      = 30
+	totpSecretBytes = 20
+	// One step of skew on each side tolerates clocks up to 30s apart.
+	totpSkewSteps = 1
+
+	RecoveryCodeCount = 10
+	recoveryCodeBytes = 5
+
+	// MFAChallengeTTL bounds how long the second step of a login can take.
+	MFAChallengeTTL = 5 * time.Minute
+	// MaxMFAChallengeFailures wrong codes invalidate a challenge, so guessing
+	// needs a new password check every few tries.
+	MaxMFAChallengeFailures = 3
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// MFAFactor is a user's TOTP authenticator. It only protects the account once
+// confirmed; an unconfirmed factor is an enrollment in progress. LastUsedStep
+// rejects a code that was already accepted, even inside its validity window.
+type MFAFactor struct {
+	UserID          string
+	EncryptedSecret []byte
+	ConfirmedAt     *time.Time
+	LastUsedStep    int64
+	CreatedAt       time.Time
+}
+
+func (f *MFAFactor) IsConfirmed() bool {
+	return f != nil && f.ConfirmedAt != nil
+}
+
+// NewTOTPSecret returns a random base32 secret as expected by authenticator apps.
+func NewTOTPSecret() (string, error) {
+	raw := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("generate totp secret: %w", err)
+	}
+
+	return totpEncoding.EncodeToString(raw), nil
+}
+
+// TOTPProvisioningURI builds the otpauth:// URI rendered as a QR code during enrollment.
+func TOTPProvisioningURI(secret, accountName string) string {
+	label := url.PathEscape(TOTPIssuer + ":" + accountName)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", TOTPIssuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPStep returns the RFC 6238 time step for t.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// GenerateTOTP computes the code for a time step.
+func GenerateTOTP(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("decode totp secret: %w", err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000), nil
+}
+
+// VerifyTOTP checks code against the steps around now and returns the matched
+// step. Steps at or before lastUsedStep are rejected to prevent replays.
+func VerifyTOTP(secret, code string, now time.Time, lastUsedStep int64) (int64, error) {
+	code = NormalizeMFACode(code)
+	if !IsTOTPCode(code) {
+		return 0, ErrInvalidMFACode
+	}
+
+	current := TOTPStep(now)
+	for step := current - totpSkewSteps; step <= current+totpSkewSteps; step++ {
+		if step <= lastUsedStep {
+			continue
+		}
+
+		expected, err := GenerateTOTP(secret, step)
+		if err != nil {
+			return 0, err
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, nil
+		}
+	}
+
+	return 0, ErrInvalidMFACode
+}
+
+// NormalizeMFACode strips the separators users tend to type or paste.
+func NormalizeMFACode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
+
+// IsTOTPCode reports whether a normalized code has the shape of a TOTP code
+// rather than a recovery code.
+func IsTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRecoveryCodes returns single-use fallback codes formatted as xxxx-xxxx.
+// Only their hashes are stored, so they are shown to the user once.
+func NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	for range RecoveryCodeCount {
+		raw := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+
+		encoded := strings.ToLower(totpEncoding.EncodeToString(raw))
+		codes = append(codes, encoded[:4]+"-"+encoded[4:])
+	}
+
+	return codes, nil
+}
+
+func HashRecoveryCode(code string) []byte {
+	sum := sha256.Sum256([]byte(NormalizeMFACode(code)))
+	return sum[:]
+}
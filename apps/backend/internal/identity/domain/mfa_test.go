@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B secret ("12345678901234567890"), truncated to 6 digits.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateTOTPMatchesRFCVectors(t *testing.T) {
+	cases := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+	}
+
+	for unix, want := range cases {
+		got, err := GenerateTOTP(rfcSecret, TOTPStep(time.Unix(unix, 0)))
+		if err != nil {
+			t.Fatalf("generate totp failed: %v", err)
+		}
+		if got != want {
+			t.Fatalf("at %d expected %s, got %s", unix, want, got)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := TOTPStep(now)
+
+	step, err := VerifyTOTP(rfcSecret, "081 804", now, 0)
+	if err != nil || step != current {
+		t.Fatalf("expected current step %d, got %d (%v)", current, step, err)
+	}
+
+	previous, _ := GenerateTOTP(rfcSecret, current-1)
+	if _, err := VerifyTOTP(rfcSecret, previous, now, 0); err != nil {
+		t.Fatalf("expected one step of skew to be accepted: %v", err)
+	}
+
+	if _, err := VerifyTOTP(rfcSecret, "081804", now, current); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("expected replayed code to be rejected, got %v", err)
+	}
+
+	stale, _ := GenerateTOTP(rfcSecret, current-2)
+	if _, err := VerifyTOTP(rfcSecret, stale, now, 0); !errors.Is(err, ErrInvalidMFACode) {
+		t.Fatalf("expected code outside the window to be rejected, got %v", err)
+	}
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatalf("new secret failed: %v", err)
+	}
+
+	uri, err := url.Parse(TOTPProvisioningURI(secret, "ana@example.com"))
+	if err != nil {
+		t.Fatalf("invalid uri: %v", err)
+	}
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Bowerbird:ana@example.com" {
+		t.Fatalf("unexpected uri: %s", uri)
+	}
+	if uri.Query().Get("secret") != secret || uri.Query().Get("issuer") != TOTPIssuer {
+		t.Fatalf("unexpected query: %s", uri.RawQuery)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes()
+	if err != nil {
+		t.Fatalf("new recovery codes failed: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount {
+		t.Fatalf("expected %d codes, got %d", RecoveryCodeCount, len(codes))
+	}
+
+	code := codes[0]
+	if IsTOTPCode(NormalizeMFACode(code)) {
+		t.Fatalf("recovery code %q must not look like a totp code", code)
+	}
+	if !bytes.Equal(HashRecoveryCode(code), HashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(code, "-", ""))+" ")) {
+		t.Fatalf("expected hash to ignore case and separators")
+	}
+}
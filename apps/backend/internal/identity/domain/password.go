@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrWeakPassword = errors.New("password does not meet the strength policy")
+
+const (
+	MinPasswordLength = 12
+	// bcrypt silently ignores anything past 72 bytes, so longer passwords are rejected.
+	MaxPasswordBytes = 72
+)
+
+// commonPasswords is a short deny-list of passwords that pass the length and
+// character rules but show up first in credential-stuffing lists.
+var commonPasswords = map[string]struct{}{
+	"password1234":  {},
+	"password123!":  {},
+	"qwerty123456":  {},
+	"123456789012":  {},
+	"iloveyou1234":  {},
+	"welcome12345":  {},
+	"admin1234567":  {},
+	"contraseña123": {},
+	"bowerbird123":  {},
+}
+
+// PasswordPolicyError lists every rule a candidate password breaks.
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return ErrWeakPassword.Error() + ": " + strings.Join(e.Violations, "; ")
+}
+
+func (e *PasswordPolicyError) Unwrap() error {
+	return ErrWeakPassword
+}
+
+// ValidatePassword enforces the local-credentials policy: 12 to 72 bytes, at
+// least one letter and one digit or symbol, not a common password and not
+// derived from the account email.
+func ValidatePassword(password, email string) error {
+	var violations []string
+
+	if len([]rune(password)) < MinPasswordLength {
+		violations = append(violations, "must be at least 12 characters long")
+	}
+	if len(password) > MaxPasswordBytes {
+		violations = append(violations, "must be at most 72 bytes long")
+	}
+
+	var hasLetter, hasOther bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r), unicode.IsPunct(r), unicode.IsSymbol(r), unicode.IsSpace(r):
+			hasOther = true
+		}
+	}
+	if !hasLetter || !hasOther {
+		violations = append(violations, "must contain letters and at least one digit or symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	if _, common := commonPasswords[lowered]; common {
+		violations = append(violations, "is too common")
+	}
+
+	if local, _, ok := strings.Cut(strings.ToLower(email), "@"); ok && len(local) >= 4 && strings.Contains(lowered, local) {
+		violations = append(violations, "must not contain the email address")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidatePassword(t *testing.T) {
+	cases := []struct {
+		name     string
+		password string
+		ok       bool
+	}{
+		{"strong", "correct horse 42", true},
+		{"too short", "short1!", false},
+		{"letters only", "onlylettershere", false},
+		{"digits only", "123456789012345", false},
+		{"common", "Password1234", false},
+		{"contains email", "maria.garcia-2024", false},
+		{"too long", strings.Repeat("a1", 37), false},
+	}
+
+	for _, tc := range cases {
+		err := ValidatePassword(tc.password, "maria.garcia@example.com")
+		if tc.ok && err != nil {
+			t.Fatalf("%s: expected valid password, got %v", tc.name, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrWeakPassword) {
+			t.Fatalf("%s: expected ErrWeakPassword, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestLoginLockoutPolicy(t *testing.T) {
+	policy := DefaultLoginLockoutPolicy()
+
+	if policy.IsLocked(LoginFailures{Account: 4, IP: 19}) {
+		t.Fatalf("expected unlocked below both limits")
+	}
+	if !policy.IsLocked(LoginFailures{Account: 5}) {
+		t.Fatalf("expected account lockout at the account limit")
+	}
+	if !policy.IsLocked(LoginFailures{IP: 20}) {
+		t.Fatalf("expected ip lockout at the ip limit")
+	}
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 type Repository interface {
@@ -13,17 +14,46 @@ type Repository interface {
 	FindUserIdentity(ctx context.Context, provider, providerID string) (*UserIdentity, error)
 	FindUserIdentityByProvider(ctx context.Context, userID, provider string) (*UserIdentity, error)
 	CreateUserIdentity(ctx context.Context, identity *UserIdentity) error
+	DeleteUserIdentity(ctx context.Context, userID, provider string) error
+
+	// Local Credentials (Control Plane)
+	MarkEmailVerified(ctx context.Context, userID string, at time.Time) error
+	UpdatePasswordHash(ctx context.Context, userID, passwordHash string) error
+	CreateAuthToken(ctx context.Context, token *AuthToken) error
+	FindAuthToken(ctx context.Context, tokenHash []byte, purpose AuthTokenPurpose, now time.Time) (*AuthToken, error)
+	ConsumeAuthToken(ctx context.Context, tokenHash []byte, purpose AuthTokenPurpose, now time.Time) (*AuthToken, error)
+	RecordAuthTokenFailure(ctx context.Context, tokenHash []byte, purpose AuthTokenPurpose, maxFailures int, now time.Time) error
+	RecordLoginAttempt(ctx context.Context, attempt LoginAttempt) error
+	CountLoginFailures(ctx context.Context, email, ipAddress string, since time.Time) (LoginFailures, error)
+
+	// Multi-Factor Authentication (Control Plane)
+	FindMFAFactor(ctx context.Context, userID string) (*MFAFactor, error)
+	SavePendingMFAFactor(ctx context.Context, factor *MFAFactor) error
+	ConfirmMFAFactor(ctx context.Context, userID string, step int64, recoveryCodeHashes [][]byte, at time.Time) error
+	AdvanceMFAStep(ctx context.Context, userID string, step int64) error
+	DeleteMFAFactor(ctx context.Context, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes [][]byte, at time.Time) error
+	ConsumeRecoveryCode(ctx context.Context, userID string, codeHash []byte, at time.Time) error
+	CountRecoveryCodes(ctx context.Context, userID string) (int, error)
+
+	// Single Sign-On (Control Plane)
+	FindSSOConnection(ctx context.Context, tenantRef string) (*SSOConnection, error)
+	SaveSSOConnection(ctx context.Context, connection *SSOConnection) error
+	DeleteSSOConnection(ctx context.Context, tenantID string) error
 
 	// Tenant Membership (Control Plane)
 	FindTenantMemberships(ctx context.Context, userID string) ([]*TenantMembership, error)
+	FindTenantMembership(ctx context.Context, userID, tenantRef string) (*TenantMembership, error)
 	AddTenantMembership(ctx context.Context, membership *TenantMembership) error
-	RemoveTenantMembership(ctx context.Context, userID, tenantID string) error
+	// RemoveTenantMembership reports whether an active membership was removed.
+	RemoveTenantMembership(ctx context.Context, userID, tenantID string) (bool, error)
 
 	// Tenant Profile Operations (Tenant DB)
 	// Requires standard injection of the tenant's pgxpool
 	CreateTenantUserProfile(ctx context.Context, tenantDBName string, profile *TenantUserProfile) error
 	UpdateTenantUserProfile(ctx context.Context, tenantDBName string, profile *TenantUserProfile) error
 	SoftDeleteTenantUserProfile(ctx context.Context, tenantDBName string, userID string) error
+	FindTenantUserPermissions(ctx context.Context, tenantDBName string, userID string) ([]string, error)
 
 	// Administrative
 	SoftDeleteUser(ctx context.Context, userID string) error
@@ -0,0 +1,15 @@
+package domain
+
+// Kinds of secret sealed with the credentials cipher in the control plane.
+const (
+	SealedSecretMFAFactor       = "mfa_factor"
+	SealedSecretSSOClientSecret = "sso_client_secret"
+)
+
+// SealedSecret is a stored secret as the cipher sealed it. OwnerID is the
+// user of an MFA factor or the tenant of an SSO connection.
+type SealedSecret struct {
+	Kind       string
+	OwnerID    string
+	Ciphertext []byte
+}
@@ -0,0 +1,154 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSSONotConfigured     = errors.New("sso is not configured for this organization")
+	ErrInvalidSSOConfig     = errors.New("invalid sso configuration")
+	ErrSSODomainNotAllowed  = errors.New("email domain is not allowed for this organization's sso")
+	ErrSSORequired          = errors.New("organization requires signing in through its sso")
+	ErrSSOSessionRequired   = errors.New("sign in through the organization's sso before enforcing it")
+	ErrSSOSessionScope      = errors.New("sso sessions only grant access to the organization that issued them")
+	ErrSSOAccountNotLinked  = errors.New("an account with this email exists and is not a member of the organization")
+	ErrInvalidSSOLoginState = errors.New("invalid or expired sso login")
+)
+
+// SSOLoginTTL bounds how long the user can spend at the identity provider.
+const SSOLoginTTL = 10 * time.Minute
+
+// SSOConnection is an organization's OpenID Connect identity provider. Only
+// emails in AllowedDomains can sign in through it. With SSOOnly the
+// organization only accepts sessions started through this provider; members
+// keep their other logins for the rest of their organizations, since domains
+// are not verified and must not lock accounts out platform-wide.
+type SSOConnection struct {
+	TenantID              string
+	TenantSlug            string
+	Issuer                string
+	ClientID              string
+	EncryptedClientSecret []byte
+	AllowedDomains        []string
+	SSOOnly               bool
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// NewSSOConnection validates and normalizes a configuration. The secret is
+// encrypted by the caller.
+func NewSSOConnection(tenantID, issuer, clientID string, allowedDomains []string, ssoOnly bool, now time.Time) (*SSOConnection, error) {
+	issuer = strings.TrimRight(strings.TrimSpace(issuer), "/")
+	parsed, err := url.Parse(issuer)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && !isLoopback(parsed.Hostname())) {
+		return nil, fmt.Errorf("%w: issuer must be an https url", ErrInvalidSSOConfig)
+	}
+
+	clientID = strings.TrimSpace(clientID)
+	if clientID == "" {
+		return nil, fmt.Errorf("%w: client id is required", ErrInvalidSSOConfig)
+	}
+
+	domains := make([]string, 0, len(allowedDomains))
+	for _, domain := range allowedDomains {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain == "" || strings.ContainsAny(domain, "@/ ") || !strings.Contains(domain, ".") {
+			return nil, fmt.Errorf("%w: invalid domain %q", ErrInvalidSSOConfig, domain)
+		}
+		if !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("%w: at least one allowed domain is required", ErrInvalidSSOConfig)
+	}
+
+	return &SSOConnection{
+		TenantID:       tenantID,
+		Issuer:         issuer,
+		ClientID:       clientID,
+		AllowedDomains: domains,
+		SSOOnly:        ssoOnly,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// AllowsEmail reports whether the email belongs to one of the allowed domains.
+func (c *SSOConnection) AllowsEmail(email string) bool {
+	return slices.Contains(c.AllowedDomains, EmailDomain(email))
+}
+
+// Provider is the user_identities provider for accounts linked through this
+// connection; subjects are only unique per identity provider.
+func (c *SSOConnection) Provider() string {
+	return SSOProvider(c.TenantID)
+}
+
+func SSOProvider(tenantID string) string {
+	return "sso:" + tenantID
+}
+
+// EmailDomain returns the lower-cased domain of an email address.
+func EmailDomain(email string) string {
+	_, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if !ok {
+		return ""
+	}
+	return domain
+}
+
+// isLoopback allows plain http issuers for local development stand-ins.
+func isLoopback(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// SSOProfile is the identity asserted by the provider after a login.
+type SSOProfile struct {
+	Subject string
+	Email   string
+	// EmailVerified is nil when the provider does not state it.
+	EmailVerified *bool
+	Name          string
+	// MFA is set when the provider reports a second factor in the amr claim.
+	MFA bool
+}
+
+// SSOLoginState travels through the identity provider inside the OAuth state
+// parameter, sealed by the application. Binding is also set as a cookie so a
+// callback cannot be replayed in another browser.
+type SSOLoginState struct {
+	TenantID     string    `json:"tenant_id"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	Binding      string    `json:"binding"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// NewSSOLoginState starts a login with a fresh nonce, PKCE verifier and
+// browser binding.
+func NewSSOLoginState(tenantID string, now time.Time) (*SSOLoginState, error) {
+	values := make([]string, 3)
+	for i := range values {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate sso login state: %w", err)
+		}
+		values[i] = base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	return &SSOLoginState{
+		TenantID:     tenantID,
+		Nonce:        values[0],
+		CodeVerifier: values[1],
+		Binding:      values[2],
+		ExpiresAt:    now.Add(SSOLoginTTL),
+	}, nil
+}
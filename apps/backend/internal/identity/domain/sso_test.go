@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewSSOConnection(t *testing.T) {
+	now := time.Now()
+
+	connection, err := NewSSOConnection("tenant-1", " https://login.acme.com/ ", "client", []string{"Acme.com", "@acme.es", "acme.com"}, true, now)
+	if err != nil {
+		t.Fatalf("expected valid connection, got %v", err)
+	}
+	if connection.Issuer != "https://login.acme.com" {
+		t.Fatalf("expected trimmed issuer, got %q", connection.Issuer)
+	}
+	if len(connection.AllowedDomains) != 2 || connection.AllowedDomains[0] != "acme.com" || connection.AllowedDomains[1] != "acme.es" {
+		t.Fatalf("expected normalized domains, got %v", connection.AllowedDomains)
+	}
+	if connection.Provider() != "sso:tenant-1" {
+		t.Fatalf("unexpected provider %q", connection.Provider())
+	}
+
+	if !connection.AllowsEmail("Ana@ACME.com") {
+		t.Fatalf("expected allowed domain to match case-insensitively")
+	}
+	if connection.AllowsEmail("ana@sub.acme.com") || connection.AllowsEmail("ana@evil-acme.com") {
+		t.Fatalf("expected only exact domains to match")
+	}
+
+	invalid := []struct {
+		name     string
+		issuer   string
+		clientID string
+		domains  []string
+	}{
+		{"plain http", "http://login.acme.com", "client", []string{"acme.com"}},
+		{"http outside loopback", "http://evil.com", "client", []string{"acme.com"}},
+		{"missing client id", "https://login.acme.com", " ", []string{"acme.com"}},
+		{"no domains", "https://login.acme.com", "client", nil},
+		{"email instead of domain", "https://login.acme.com", "client", []string{"ana@acme.com"}},
+	}
+	for _, tc := range invalid {
+		if _, err := NewSSOConnection("tenant-1", tc.issuer, tc.clientID, tc.domains, false, now); !errors.Is(err, ErrInvalidSSOConfig) {
+			t.Fatalf("%s: expected ErrInvalidSSOConfig, got %v", tc.name, err)
+		}
+	}
+
+	if _, err := NewSSOConnection("tenant-1", "http://127.0.0.1:8081", "client", []string{"acme.com"}, false, now); err != nil {
+		t.Fatalf("expected loopback issuer to be accepted, got %v", err)
+	}
+}
+
+func TestNewSSOLoginState(t *testing.T) {
+	now := time.Now()
+
+	first, err := NewSSOLoginState("tenant-1", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := NewSSOLoginState("tenant-1", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if first.Nonce == second.Nonce || first.CodeVerifier == second.CodeVerifier || first.Binding == second.Binding {
+		t.Fatalf("expected fresh random values per login")
+	}
+	// RFC 7636 requires verifiers of 43 to 128 characters.
+	if len(first.CodeVerifier) < 43 || len(first.CodeVerifier) > 128 {
+		t.Fatalf("unexpected code verifier length %d", len(first.CodeVerifier))
+	}
+	if !first.ExpiresAt.Equal(now.Add(SSOLoginTTL)) {
+		t.Fatalf("unexpected expiry %v", first.ExpiresAt)
+	}
+}
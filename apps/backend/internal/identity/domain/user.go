@@ -8,6 +8,7 @@ import (
 var (
 	ErrUserNotFound          = errors.New("user not found")
 	ErrIdentityAlreadyExists = errors.New("user identity already exists")
+	ErrMembershipNotFound    = errors.New("tenant membership not found")
 )
 
 // User represents the identity in the Control Plane
@@ -17,18 +18,25 @@ type User struct {
 	FirstName  string
 	LastName   string
 	PictureURL string
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
-	DeletedAt  *time.Time
+	// EmailVerifiedAt is nil until the user proves ownership of the address.
+	// OAuth providers vouch for it, so those users are verified on creation.
+	EmailVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
 }
 
+const ProviderLocal = "local"
+
 // UserIdentity represents a linked authentication provider for a user
 type UserIdentity struct {
 	ID         string
 	UserID     string
 	Provider   string
 	ProviderID string
-	CreatedAt  time.Time
+	// PasswordHash holds the bcrypt hash for the local provider only.
+	PasswordHash string
+	CreatedAt    time.Time
 }
 
 // TenantMembershipRole defines the role of a user in a tenant
@@ -42,10 +50,17 @@ const (
 
 // TenantMembership represents a user's association with a tenant
 type TenantMembership struct {
-	UserID    string
-	TenantID  string
-	Name      string // Tenant name
-	Role      TenantMembershipRole
+	UserID   string
+	TenantID string
+	Name     string // Tenant name
+	Slug     string
+	DBName   string
+	Role     TenantMembershipRole
+	Version  int64 // Bumped on every role change or removal
+	// MFARequired is the organization's policy: members need an MFA session to act in it.
+	MFARequired bool
+	// SSOOnly means only sessions started through the organization's SSO can act in it.
+	SSOOnly   bool
 	CreatedAt time.Time
 	DeletedAt *time.Time
 }
@@ -88,6 +103,19 @@ func NewUserIdentity(id, userID, provider, providerID string) *UserIdentity {
 	}
 }
 
+// NewLocalUserIdentity creates the email/password identity. The provider id is
+// the user id, since there is no external subject to link to.
+func NewLocalUserIdentity(id, userID, passwordHash string) *UserIdentity {
+	identity := NewUserIdentity(id, userID, ProviderLocal, userID)
+	identity.PasswordHash = passwordHash
+	return identity
+}
+
+// IsEmailVerified reports whether the user has confirmed their email address.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 // NewTenantMembership creates a new TenantMembership
 func NewTenantMembership(userID, tenantID string, role TenantMembershipRole) *TenantMembership {
 	return &TenantMembership{
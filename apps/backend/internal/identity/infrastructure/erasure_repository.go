@@ -0,0 +1,328 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const accountErasureColumns = `id, user_id, status, requested_at, scheduled_for, started_at, completed_at, attempts, identifiers, report, COALESCE(error, '')`
+
+func (r *PostgresRepository) CreateAccountErasure(ctx context.Context, erasure *domain.AccountErasure) error {
+	query := `INSERT INTO account_erasures (id, user_id, status, requested_at, scheduled_for) VALUES ($1, $2, $3, $4, $5)`
+	_, err := r.controlDB.Exec(ctx, query, erasure.ID, erasure.UserID, erasure.Status, erasure.RequestedAt, erasure.ScheduledFor)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return fmt.Errorf("account erasure already open for %s: %w", erasure.UserID, err)
+		}
+		return fmt.Errorf("failed to create account erasure: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) FindOpenAccountErasure(ctx context.Context, userID string) (*domain.AccountErasure, error) {
+	query := `SELECT ` + accountErasureColumns + ` FROM account_erasures WHERE user_id = $1 AND status IN ('scheduled', 'running')`
+	return r.findAccountErasure(ctx, query, userID)
+}
+
+func (r *PostgresRepository) FindLatestAccountErasure(ctx context.Context, userID string) (*domain.AccountErasure, error) {
+	query := `SELECT ` + accountErasureColumns + ` FROM account_erasures WHERE user_id = $1 ORDER BY requested_at DESC LIMIT 1`
+	return r.findAccountErasure(ctx, query, userID)
+}
+
+func (r *PostgresRepository) findAccountErasure(ctx context.Context, query string, args ...any) (*domain.AccountErasure, error) {
+	erasure, err := scanAccountErasure(r.controlDB.QueryRow(ctx, query, args...))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find account erasure: %w", err)
+	}
+	return erasure, nil
+}
+
+// CancelAccountErasure only cancels a still scheduled erasure, so a run that
+// claimed it in the meantime wins.
+func (r *PostgresRepository) CancelAccountErasure(ctx context.Context, erasureID string) error {
+	tag, err := r.controlDB.Exec(ctx, `UPDATE account_erasures SET status = 'cancelled', completed_at = CURRENT_TIMESTAMP WHERE id = $1 AND status = 'scheduled'`, erasureID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel account erasure: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrErasureNotCancellable
+	}
+	return nil
+}
+
+func (r *PostgresRepository) ClaimDueAccountErasures(ctx context.Context, now time.Time, staleAfter time.Duration, maxAttempts int) ([]*domain.AccountErasure, error) {
+	query := `
+		UPDATE account_erasures
+		SET status = 'running', started_at = $1, attempts = attempts + 1, error = NULL
+		WHERE id IN (
+			SELECT id FROM account_erasures
+			WHERE scheduled_for <= $1
+			  AND attempts < $3
+			  AND (status IN ('scheduled', 'failed') OR (status = 'running' AND started_at < $2))
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + accountErasureColumns
+	rows, err := r.controlDB.Query(ctx, query, now, now.Add(-staleAfter), maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim due account erasures: %w", err)
+	}
+	defer rows.Close()
+
+	var erasures []*domain.AccountErasure
+	for rows.Next() {
+		erasure, err := scanAccountErasure(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan account erasure: %w", err)
+		}
+		erasures = append(erasures, erasure)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating account erasures: %w", err)
+	}
+	return erasures, nil
+}
+
+func (r *PostgresRepository) SaveErasureIdentifiers(ctx context.Context, erasureID string, identifiers []string) error {
+	payload, err := json.Marshal(identifiers)
+	if err != nil {
+		return fmt.Errorf("failed to marshal erasure identifiers: %w", err)
+	}
+	_, err = r.controlDB.Exec(ctx, `UPDATE account_erasures SET identifiers = $2 WHERE id = $1`, erasureID, payload)
+	if err != nil {
+		return fmt.Errorf("failed to save erasure identifiers: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) SaveErasureProgress(ctx context.Context, erasureID string, report *domain.ErasureReport) error {
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal erasure report: %w", err)
+	}
+	if _, err := r.controlDB.Exec(ctx, `UPDATE account_erasures SET report = $2 WHERE id = $1`, erasureID, payload); err != nil {
+		return fmt.Errorf("failed to save erasure progress: %w", err)
+	}
+	return nil
+}
+
+// CompleteAccountErasure stores the report and drops the identifiers, the
+// last personal data the erasure held on to.
+func (r *PostgresRepository) CompleteAccountErasure(ctx context.Context, erasureID string, report *domain.ErasureReport) error {
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to marshal erasure report: %w", err)
+	}
+	query := `UPDATE account_erasures SET status = 'completed', completed_at = CURRENT_TIMESTAMP, report = $2, identifiers = NULL, error = NULL WHERE id = $1`
+	if _, err := r.controlDB.Exec(ctx, query, erasureID, payload); err != nil {
+		return fmt.Errorf("failed to complete account erasure: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) FailAccountErasure(ctx context.Context, erasureID, message string) error {
+	if _, err := r.controlDB.Exec(ctx, `UPDATE account_erasures SET status = 'failed', error = $2 WHERE id = $1`, erasureID, message); err != nil {
+		return fmt.Errorf("failed to record account erasure failure: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) FindUserForErasure(ctx context.Context, userID string) (*domain.User, error) {
+	query := `SELECT id, email, first_name, last_name, created_at, updated_at, deleted_at FROM users WHERE id = $1`
+	var user domain.User
+	err := r.controlDB.QueryRow(ctx, query, userID).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *PostgresRepository) FindOwnedOrganizations(ctx context.Context, userID string) ([]string, error) {
+	query := `
+		SELECT t.slug
+		FROM tenant_memberships m
+		JOIN tenants t ON m.tenant_id = t.id
+		WHERE m.user_id = $1 AND m.role = 'OWNER' AND m.deleted_at IS NULL
+		  AND t.status NOT IN ('pending_deletion', 'deleting')
+		ORDER BY t.slug
+	`
+	rows, err := r.controlDB.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query owned organizations: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *PostgresRepository) FindErasureTenants(ctx context.Context, userID string) ([]domain.ErasureTenant, error) {
+	query := `
+		SELECT t.id, t.slug, t.db_name, t.status,
+		       ARRAY(SELECT s.slug FROM tenant_slug_redirects s WHERE s.tenant_id = t.id ORDER BY s.slug)
+		FROM tenant_memberships m
+		JOIN tenants t ON m.tenant_id = t.id
+		WHERE m.user_id = $1
+		ORDER BY t.id
+	`
+	rows, err := r.controlDB.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query erasure tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []domain.ErasureTenant
+	for rows.Next() {
+		var t domain.ErasureTenant
+		if err := rows.Scan(&t.TenantID, &t.Slug, &t.DBName, &t.Status, &t.FormerSlugs); err != nil {
+			return nil, fmt.Errorf("failed to scan erasure tenant: %w", err)
+		}
+		tenants = append(tenants, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating erasure tenants: %w", err)
+	}
+	return tenants, nil
+}
+
+func (r *PostgresRepository) FindTenantOwner(ctx context.Context, tenantID, excludeUserID string) (string, error) {
+	query := `
+		SELECT user_id FROM tenant_memberships
+		WHERE tenant_id = $1 AND user_id <> $2 AND role = 'OWNER' AND deleted_at IS NULL
+		ORDER BY created_at
+		LIMIT 1
+	`
+	var ownerID string
+	err := r.controlDB.QueryRow(ctx, query, tenantID, excludeUserID).Scan(&ownerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find tenant owner: %w", err)
+	}
+	return ownerID, nil
+}
+
+// AnonymizeTenantUserProfile keeps the row, which role assignments and
+// records reference, but leaves nothing that identifies the person.
+func (r *PostgresRepository) AnonymizeTenantUserProfile(ctx context.Context, tenantDBName, userID, email string) error {
+	ctx = database.WithTenantDatabase(ctx, tenantDBName)
+	pool, err := r.registry.GetPoolByDBName(ctx, tenantDBName)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	query := `
+		UPDATE users
+		SET email = $2, first_name = NULL, last_name = NULL, picture_url = NULL,
+		    deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if _, err := pool.Exec(ctx, query, userID, email); err != nil {
+		return fmt.Errorf("failed to anonymize tenant user profile: %w", err)
+	}
+	return nil
+}
+
+// EraseUser deletes the user's credentials and sign-in history and
+// anonymizes the user row. The ID stays so that the records referencing it
+// remain consistent without pointing at a person.
+func (r *PostgresRepository) EraseUser(ctx context.Context, userID, email string) (domain.ControlPlaneErasureReport, error) {
+	var report domain.ControlPlaneErasureReport
+
+	tx, err := r.controlDB.Begin(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to begin user erasure: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var currentEmail string
+	if err := tx.QueryRow(ctx, `SELECT email FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&currentEmail); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return report, domain.ErrUserNotFound
+		}
+		return report, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM user_identities WHERE user_id = $1`, userID)
+	if err != nil {
+		return report, fmt.Errorf("failed to delete user identities: %w", err)
+	}
+	report.IdentitiesDeleted = int(tag.RowsAffected())
+
+	for _, statement := range []string{
+		`DELETE FROM auth_tokens WHERE user_id = $1`,
+		`DELETE FROM user_recovery_codes WHERE user_id = $1`,
+		`DELETE FROM user_mfa_factors WHERE user_id = $1`,
+	} {
+		if _, err := tx.Exec(ctx, statement, userID); err != nil {
+			return report, fmt.Errorf("failed to delete user credentials: %w", err)
+		}
+	}
+
+	tag, err = tx.Exec(ctx, `DELETE FROM login_attempts WHERE email = $1`, currentEmail)
+	if err != nil {
+		return report, fmt.Errorf("failed to delete login attempts: %w", err)
+	}
+	report.LoginAttemptsDeleted = int(tag.RowsAffected())
+
+	tag, err = tx.Exec(ctx, `UPDATE tenant_memberships SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE user_id = $1 AND deleted_at IS NULL`, userID)
+	if err != nil {
+		return report, fmt.Errorf("failed to remove memberships: %w", err)
+	}
+	report.MembershipsRemoved = int(tag.RowsAffected())
+
+	if _, err := tx.Exec(ctx, revokeCreatedAPIKeysQuery, userID); err != nil {
+		return report, fmt.Errorf("failed to revoke api keys: %w", err)
+	}
+
+	query := `
+		UPDATE users
+		SET email = $2, first_name = '', last_name = '', picture_url = NULL, email_verified_at = NULL,
+		    deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if _, err := tx.Exec(ctx, query, userID, email); err != nil {
+		return report, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return report, fmt.Errorf("failed to commit user erasure: %w", err)
+	}
+	return report, nil
+}
+
+func scanAccountErasure(row pgx.Row) (*domain.AccountErasure, error) {
+	var erasure domain.AccountErasure
+	var identifiers, report []byte
+	err := row.Scan(
+		&erasure.ID, &erasure.UserID, &erasure.Status, &erasure.RequestedAt, &erasure.ScheduledFor,
+		&erasure.StartedAt, &erasure.CompletedAt, &erasure.Attempts, &identifiers, &report, &erasure.Error,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(identifiers) > 0 {
+		if err := json.Unmarshal(identifiers, &erasure.Identifiers); err != nil {
+			return nil, fmt.Errorf("unmarshal erasure identifiers: %w", err)
+		}
+	}
+	if len(report) > 0 {
+		erasure.Report = &domain.ErasureReport{}
+		if err := json.Unmarshal(report, erasure.Report); err != nil {
+			return nil, fmt.Errorf("unmarshal erasure report: %w", err)
+		}
+	}
+	return &erasure, nil
+}
@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	contractEvents "github.com/bowerbird/internal/contracts/events"
+	"github.com/bowerbird/internal/identity/domain"
+	platformEvents "github.com/bowerbird/internal/platform/events"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+// EventNotifier hands account emails to the notification pipeline through the
+// event bus; the links point at the frontend pages that redeem the tokens.
+type EventNotifier struct {
+	eventBus    platformEvents.EventBus
+	frontendURL string
+}
+
+func NewEventNotifier(eventBus platformEvents.EventBus, frontendURL string) *EventNotifier {
+	if eventBus == nil {
+		panic("event bus is required")
+	}
+
+	return &EventNotifier{eventBus: eventBus, frontendURL: frontendURL}
+}
+
+func (n *EventNotifier) SendEmailVerification(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	return n.publish(ctx, contractEvents.AccountEmailKindVerification, user, "/verify-email", token, expiresAt)
+}
+
+func (n *EventNotifier) SendPasswordReset(ctx context.Context, user *domain.User, token string, expiresAt time.Time) error {
+	return n.publish(ctx, contractEvents.AccountEmailKindPasswordReset, user, "/reset-password", token, expiresAt)
+}
+
+func (n *EventNotifier) publish(ctx context.Context, kind string, user *domain.User, path, token string, expiresAt time.Time) error {
+	event := contractEvents.AccountEmailRequested{
+		EventID:    id.NewULID(),
+		OccurredAt: time.Now().UTC().Format(time.RFC3339Nano),
+		Kind:       kind,
+		UserID:     user.ID,
+		Email:      user.Email,
+		FirstName:  user.FirstName,
+		ActionURL:  n.frontendURL + path + "?token=" + url.QueryEscape(token),
+		ExpiresAt:  expiresAt.UTC().Format(time.RFC3339),
+	}
+
+	payload, err := contractEvents.MarshalAccountEmailRequested(event)
+	if err != nil {
+		return err
+	}
+
+	return n.eventBus.Publish(ctx, platformEvents.BusinessEvent{
+		Source:     contractEvents.AccountEmailRequestedSource,
+		DetailType: contractEvents.AccountEmailRequestedDetailType,
+		Detail:     payload,
+	})
+}
@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"slices"
+
+	"github.com/bowerbird/internal/identity/application/ports"
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/oidc"
+)
+
+// OIDCClient adapts the platform OpenID client to the identity ports.
+type OIDCClient struct {
+	client *oidc.Client
+}
+
+func NewOIDCClient(client *oidc.Client) *OIDCClient {
+	if client == nil {
+		panic("oidc client is required")
+	}
+
+	return &OIDCClient{client: client}
+}
+
+func (c *OIDCClient) Validate(ctx context.Context, issuer string) error {
+	_, err := c.client.Discover(ctx, issuer)
+	return err
+}
+
+func (c *OIDCClient) AuthCodeURL(ctx context.Context, config ports.OIDCClientConfig, state, nonce, codeVerifier string) (string, error) {
+	provider, err := c.client.Discover(ctx, config.Issuer)
+	if err != nil {
+		return "", err
+	}
+
+	oauthConfig := provider.OAuth2Config(config.ClientID, config.ClientSecret, config.RedirectURL)
+	return provider.AuthCodeURL(oauthConfig, state, nonce, codeVerifier), nil
+}
+
+func (c *OIDCClient) Authenticate(ctx context.Context, config ports.OIDCClientConfig, code, codeVerifier, nonce string) (*domain.SSOProfile, error) {
+	provider, err := c.client.Discover(ctx, config.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthConfig := provider.OAuth2Config(config.ClientID, config.ClientSecret, config.RedirectURL)
+	rawIDToken, err := provider.Exchange(ctx, oauthConfig, code, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := provider.VerifyIDToken(ctx, rawIDToken, config.ClientID, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SSOProfile{
+		Subject:       token.Subject,
+		Email:         token.Email,
+		EmailVerified: token.EmailVerified,
+		Name:          token.Name,
+		MFA:           slices.Contains(token.AuthMethods, "mfa"),
+	}, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/auth"
 	"github.com/bowerbird/internal/platform/database"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -24,10 +26,10 @@ func NewPostgresRepository(controlDB *pgxpool.Pool, registry *database.Registry)
 }
 
 func (r *PostgresRepository) FindUserByEmail(ctx context.Context, email string) (*domain.User, error) {
-	query := `SELECT id, email, first_name, last_name, picture_url, created_at, updated_at, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL`
+	query := `SELECT id, email, first_name, last_name, picture_url, email_verified_at, created_at, updated_at, deleted_at FROM users WHERE email = $1 AND deleted_at IS NULL`
 	var user domain.User
 	var pictureURL *string
-	err := r.controlDB.QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &pictureURL, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	err := r.controlDB.QueryRow(ctx, query, email).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &pictureURL, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -41,10 +43,10 @@ func (r *PostgresRepository) FindUserByEmail(ctx context.Context, email string)
 }
 
 func (r *PostgresRepository) FindUserByID(ctx context.Context, id string) (*domain.User, error) {
-	query := `SELECT id, email, first_name, last_name, picture_url, created_at, updated_at, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL`
+	query := `SELECT id, email, first_name, last_name, picture_url, email_verified_at, created_at, updated_at, deleted_at FROM users WHERE id = $1 AND deleted_at IS NULL`
 	var user domain.User
 	var pictureURL *string
-	err := r.controlDB.QueryRow(ctx, query, id).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &pictureURL, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
+	err := r.controlDB.QueryRow(ctx, query, id).Scan(&user.ID, &user.Email, &user.FirstName, &user.LastName, &pictureURL, &user.EmailVerifiedAt, &user.CreatedAt, &user.UpdatedAt, &user.DeletedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -58,12 +60,12 @@ func (r *PostgresRepository) FindUserByID(ctx context.Context, id string) (*doma
 }
 
 func (r *PostgresRepository) CreateUser(ctx context.Context, user *domain.User) error {
-	query := `INSERT INTO users (id, email, first_name, last_name, picture_url, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	query := `INSERT INTO users (id, email, first_name, last_name, picture_url, email_verified_at, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 	var pictureURL *string
 	if user.PictureURL != "" {
 		pictureURL = &user.PictureURL
 	}
-	_, err := r.controlDB.Exec(ctx, query, user.ID, user.Email, user.FirstName, user.LastName, pictureURL, user.CreatedAt, user.UpdatedAt)
+	_, err := r.controlDB.Exec(ctx, query, user.ID, user.Email, user.FirstName, user.LastName, pictureURL, user.EmailVerifiedAt, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -71,10 +73,10 @@ func (r *PostgresRepository) CreateUser(ctx context.Context, user *domain.User)
 }
 
 func (r *PostgresRepository) FindUserIdentity(ctx context.Context, provider, providerID string) (*domain.UserIdentity, error) {
-	query := `SELECT id, user_id, provider, provider_id, created_at FROM user_identities WHERE provider = $1 AND provider_id = $2`
+	query := `SELECT id, user_id, provider, provider_id, COALESCE(password_hash, ''), created_at FROM user_identities WHERE provider = $1 AND provider_id = $2`
 	var identity domain.UserIdentity
 	err := r.controlDB.QueryRow(ctx, query, provider, providerID).Scan(
-		&identity.ID, &identity.UserID, &identity.Provider, &identity.ProviderID, &identity.CreatedAt,
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.ProviderID, &identity.PasswordHash, &identity.CreatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -86,10 +88,10 @@ func (r *PostgresRepository) FindUserIdentity(ctx context.Context, provider, pro
 }
 
 func (r *PostgresRepository) FindUserIdentityByProvider(ctx context.Context, userID, provider string) (*domain.UserIdentity, error) {
-	query := `SELECT id, user_id, provider, provider_id, created_at FROM user_identities WHERE user_id = $1 AND provider = $2`
+	query := `SELECT id, user_id, provider, provider_id, COALESCE(password_hash, ''), created_at FROM user_identities WHERE user_id = $1 AND provider = $2`
 	var identity domain.UserIdentity
 	err := r.controlDB.QueryRow(ctx, query, userID, provider).Scan(
-		&identity.ID, &identity.UserID, &identity.Provider, &identity.ProviderID, &identity.CreatedAt,
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.ProviderID, &identity.PasswordHash, &identity.CreatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -101,17 +103,350 @@ func (r *PostgresRepository) FindUserIdentityByProvider(ctx context.Context, use
 }
 
 func (r *PostgresRepository) CreateUserIdentity(ctx context.Context, identity *domain.UserIdentity) error {
-	query := `INSERT INTO user_identities (id, user_id, provider, provider_id, created_at) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.controlDB.Exec(ctx, query, identity.ID, identity.UserID, identity.Provider, identity.ProviderID, identity.CreatedAt)
+	query := `INSERT INTO user_identities (id, user_id, provider, provider_id, password_hash, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	var passwordHash *string
+	if identity.PasswordHash != "" {
+		passwordHash = &identity.PasswordHash
+	}
+	_, err := r.controlDB.Exec(ctx, query, identity.ID, identity.UserID, identity.Provider, identity.ProviderID, passwordHash, identity.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create user identity: %w", err)
 	}
 	return nil
 }
 
+func (r *PostgresRepository) DeleteUserIdentity(ctx context.Context, userID, provider string) error {
+	query := `DELETE FROM user_identities WHERE user_id = $1 AND provider = $2`
+	_, err := r.controlDB.Exec(ctx, query, userID, provider)
+	if err != nil {
+		return fmt.Errorf("failed to delete user identity: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) MarkEmailVerified(ctx context.Context, userID string, at time.Time) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, $1), updated_at = $1 WHERE id = $2`
+	_, err := r.controlDB.Exec(ctx, query, at, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) UpdatePasswordHash(ctx context.Context, userID, passwordHash string) error {
+	query := `UPDATE user_identities SET password_hash = $1 WHERE user_id = $2 AND provider = 'local'`
+	tag, err := r.controlDB.Exec(ctx, query, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update password hash: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) CreateAuthToken(ctx context.Context, token *domain.AuthToken) error {
+	query := `INSERT INTO auth_tokens (id, user_id, purpose, token_hash, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.controlDB.Exec(ctx, query, token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create auth token: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) FindAuthToken(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, now time.Time) (*domain.AuthToken, error) {
+	query := `
+		SELECT id, user_id, purpose, token_hash, expires_at, consumed_at, created_at
+		FROM auth_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > $3
+	`
+	return r.scanAuthToken(r.controlDB.QueryRow(ctx, query, tokenHash, purpose, now))
+}
+
+// ConsumeAuthToken marks the token used in the same statement that checks it,
+// so two concurrent requests cannot both redeem it.
+func (r *PostgresRepository) ConsumeAuthToken(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, now time.Time) (*domain.AuthToken, error) {
+	query := `
+		UPDATE auth_tokens SET consumed_at = $3
+		WHERE token_hash = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > $3
+		RETURNING id, user_id, purpose, token_hash, expires_at, consumed_at, created_at
+	`
+	return r.scanAuthToken(r.controlDB.QueryRow(ctx, query, tokenHash, purpose, now))
+}
+
+// RecordAuthTokenFailure counts a wrong answer to the token and consumes it
+// once maxFailures is reached, in one statement so concurrent guesses cannot
+// overshoot the limit.
+func (r *PostgresRepository) RecordAuthTokenFailure(ctx context.Context, tokenHash []byte, purpose domain.AuthTokenPurpose, maxFailures int, now time.Time) error {
+	query := `
+		UPDATE auth_tokens
+		SET failed_attempts = failed_attempts + 1,
+			consumed_at = CASE WHEN failed_attempts + 1 >= $3 THEN $4 ELSE consumed_at END
+		WHERE token_hash = $1 AND purpose = $2 AND consumed_at IS NULL
+	`
+	if _, err := r.controlDB.Exec(ctx, query, tokenHash, purpose, maxFailures, now); err != nil {
+		return fmt.Errorf("failed to record auth token failure: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) scanAuthToken(row pgx.Row) (*domain.AuthToken, error) {
+	var token domain.AuthToken
+	err := row.Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.ExpiresAt, &token.ConsumedAt, &token.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrInvalidAuthToken
+		}
+		return nil, fmt.Errorf("failed to find auth token: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *PostgresRepository) RecordLoginAttempt(ctx context.Context, attempt domain.LoginAttempt) error {
+	query := `INSERT INTO login_attempts (email, ip_address, succeeded, attempted_at) VALUES ($1, $2, $3, $4)`
+	_, err := r.controlDB.Exec(ctx, query, attempt.Email, attempt.IPAddress, attempt.Succeeded, attempt.AttemptedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) CountLoginFailures(ctx context.Context, email, ipAddress string, since time.Time) (domain.LoginFailures, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM login_attempts
+			 WHERE email = $1 AND NOT succeeded
+			   AND attempted_at > GREATEST($3, COALESCE((SELECT MAX(attempted_at) FROM login_attempts WHERE email = $1 AND succeeded), $3))),
+			(SELECT COUNT(*) FROM login_attempts
+			 WHERE ip_address = $2 AND NOT succeeded AND attempted_at > $3)
+	`
+	var failures domain.LoginFailures
+	err := r.controlDB.QueryRow(ctx, query, email, ipAddress, since).Scan(&failures.Account, &failures.IP)
+	if err != nil {
+		return domain.LoginFailures{}, fmt.Errorf("failed to count login failures: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *PostgresRepository) FindMFAFactor(ctx context.Context, userID string) (*domain.MFAFactor, error) {
+	query := `SELECT user_id, encrypted_secret, confirmed_at, last_used_step, created_at FROM user_mfa_factors WHERE user_id = $1`
+	var factor domain.MFAFactor
+	err := r.controlDB.QueryRow(ctx, query, userID).Scan(
+		&factor.UserID, &factor.EncryptedSecret, &factor.ConfirmedAt, &factor.LastUsedStep, &factor.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMFANotEnrolled
+		}
+		return nil, fmt.Errorf("failed to find mfa factor: %w", err)
+	}
+	return &factor, nil
+}
+
+// SavePendingMFAFactor starts or restarts an enrollment. A confirmed factor is
+// never overwritten; it has to be disabled first.
+func (r *PostgresRepository) SavePendingMFAFactor(ctx context.Context, factor *domain.MFAFactor) error {
+	query := `
+		INSERT INTO user_mfa_factors (user_id, encrypted_secret, confirmed_at, last_used_step, created_at)
+		VALUES ($1, $2, NULL, 0, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET encrypted_secret = EXCLUDED.encrypted_secret, last_used_step = 0, created_at = EXCLUDED.created_at
+		WHERE user_mfa_factors.confirmed_at IS NULL
+	`
+	tag, err := r.controlDB.Exec(ctx, query, factor.UserID, factor.EncryptedSecret, factor.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save mfa factor: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+	return nil
+}
+
+// ConfirmMFAFactor activates the factor and stores its recovery codes in one transaction.
+func (r *PostgresRepository) ConfirmMFAFactor(ctx context.Context, userID string, step int64, recoveryCodeHashes [][]byte, at time.Time) error {
+	tx, err := r.controlDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE user_mfa_factors SET confirmed_at = $2, last_used_step = $3
+		WHERE user_id = $1 AND confirmed_at IS NULL AND last_used_step < $3
+	`
+	tag, err := tx.Exec(ctx, query, userID, at, step)
+	if err != nil {
+		return fmt.Errorf("failed to confirm mfa factor: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes, at); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AdvanceMFAStep records an accepted TOTP step. It fails when the step was
+// already used, which also settles two concurrent logins with the same code.
+func (r *PostgresRepository) AdvanceMFAStep(ctx context.Context, userID string, step int64) error {
+	query := `UPDATE user_mfa_factors SET last_used_step = $2 WHERE user_id = $1 AND last_used_step < $2`
+	tag, err := r.controlDB.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to record mfa step: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (r *PostgresRepository) DeleteMFAFactor(ctx context.Context, userID string) error {
+	tx, err := r.controlDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_mfa_factors WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete mfa factor: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codeHashes [][]byte, at time.Time) error {
+	tx, err := r.controlDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, codeHashes, at); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, codeHashes [][]byte, at time.Time) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		query := `INSERT INTO user_recovery_codes (user_id, code_hash, created_at) VALUES ($1, $2, $3)`
+		if _, err := tx.Exec(ctx, query, userID, hash, at); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ConsumeRecoveryCode(ctx context.Context, userID string, codeHash []byte, at time.Time) error {
+	query := `UPDATE user_recovery_codes SET used_at = $3 WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	tag, err := r.controlDB.Exec(ctx, query, userID, codeHash, at)
+	if err != nil {
+		return fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+func (r *PostgresRepository) CountRecoveryCodes(ctx context.Context, userID string) (int, error) {
+	var remaining int
+	query := `SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL`
+	if err := r.controlDB.QueryRow(ctx, query, userID).Scan(&remaining); err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return remaining, nil
+}
+
+const ssoConnectionColumns = `s.tenant_id, t.slug, s.issuer, s.client_id, s.encrypted_client_secret, s.allowed_domains, s.sso_only, s.created_at, s.updated_at`
+
+func scanSSOConnection(row pgx.Row) (*domain.SSOConnection, error) {
+	var c domain.SSOConnection
+	err := row.Scan(&c.TenantID, &c.TenantSlug, &c.Issuer, &c.ClientID, &c.EncryptedClientSecret, &c.AllowedDomains, &c.SSOOnly, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// FindSSOConnection resolves an active organization's SSO connection by tenant id or slug.
+func (r *PostgresRepository) FindSSOConnection(ctx context.Context, tenantRef string) (*domain.SSOConnection, error) {
+	query := `
+		SELECT ` + ssoConnectionColumns + `
+		FROM sso_connections s
+		JOIN tenants t ON s.tenant_id = t.id
+		WHERE (t.id = $1 OR t.slug = $1 OR t.id = (SELECT tenant_id FROM tenant_slug_redirects WHERE slug = $1)) AND t.status = 'active'
+	`
+	c, err := scanSSOConnection(r.controlDB.QueryRow(ctx, query, tenantRef))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSSONotConfigured
+		}
+		return nil, fmt.Errorf("failed to find sso connection: %w", err)
+	}
+	return c, nil
+}
+
+func (r *PostgresRepository) SaveSSOConnection(ctx context.Context, c *domain.SSOConnection) error {
+	query := `
+		INSERT INTO sso_connections (tenant_id, issuer, client_id, encrypted_client_secret, allowed_domains, sso_only, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET issuer = EXCLUDED.issuer,
+		    client_id = EXCLUDED.client_id,
+		    encrypted_client_secret = EXCLUDED.encrypted_client_secret,
+		    allowed_domains = EXCLUDED.allowed_domains,
+		    sso_only = EXCLUDED.sso_only,
+		    updated_at = EXCLUDED.updated_at
+	`
+	tx, err := r.controlDB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, query, c.TenantID, c.Issuer, c.ClientID, c.EncryptedClientSecret, c.AllowedDomains, c.SSOOnly, c.CreatedAt, c.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save sso connection: %w", err)
+	}
+
+	// Tenant tokens issued from non-SSO sessions must stop working as soon as
+	// the organization becomes SSO-only.
+	if c.SSOOnly {
+		if _, err := tx.Exec(ctx, `UPDATE tenant_memberships SET version = version + 1 WHERE tenant_id = $1 AND deleted_at IS NULL`, c.TenantID); err != nil {
+			return fmt.Errorf("failed to revoke tenant sessions: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) DeleteSSOConnection(ctx context.Context, tenantID string) error {
+	tag, err := r.controlDB.Exec(ctx, `DELETE FROM sso_connections WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete sso connection: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSSONotConfigured
+	}
+	return nil
+}
+
 func (r *PostgresRepository) FindTenantMemberships(ctx context.Context, userID string) ([]*domain.TenantMembership, error) {
 	query := `
-		SELECT m.user_id, m.tenant_id, t.organization_name, m.role, m.created_at, m.deleted_at 
+		SELECT m.user_id, m.tenant_id, t.organization_name, m.role, t.mfa_required, m.created_at, m.deleted_at
 		FROM tenant_memberships m
 		JOIN tenants t ON m.tenant_id = t.id
 		WHERE m.user_id = $1 AND m.deleted_at IS NULL AND t.status = 'active'
@@ -125,7 +460,7 @@ func (r *PostgresRepository) FindTenantMemberships(ctx context.Context, userID s
 	var memberships []*domain.TenantMembership
 	for rows.Next() {
 		var m domain.TenantMembership
-		if err := rows.Scan(&m.UserID, &m.TenantID, &m.Name, &m.Role, &m.CreatedAt, &m.DeletedAt); err != nil {
+		if err := rows.Scan(&m.UserID, &m.TenantID, &m.Name, &m.Role, &m.MFARequired, &m.CreatedAt, &m.DeletedAt); err != nil {
 			return nil, fmt.Errorf("failed to scan tenant membership: %w", err)
 		}
 		memberships = append(memberships, &m)
@@ -136,6 +471,47 @@ func (r *PostgresRepository) FindTenantMemberships(ctx context.Context, userID s
 	return memberships, nil
 }
 
+// FindTenantMembership resolves an active membership by tenant id or slug.
+func (r *PostgresRepository) FindTenantMembership(ctx context.Context, userID, tenantRef string) (*domain.TenantMembership, error) {
+	query := `
+		SELECT m.user_id, m.tenant_id, t.organization_name, t.slug, t.db_name, m.role, m.version, t.mfa_required,
+		       COALESCE(s.sso_only, FALSE), m.created_at, m.deleted_at
+		FROM tenant_memberships m
+		JOIN tenants t ON m.tenant_id = t.id
+		LEFT JOIN sso_connections s ON s.tenant_id = t.id
+		WHERE m.user_id = $1 AND (t.id = $2 OR t.slug = $2 OR t.id = (SELECT tenant_id FROM tenant_slug_redirects WHERE slug = $2)) AND m.deleted_at IS NULL AND t.status = 'active'
+	`
+	var m domain.TenantMembership
+	err := r.controlDB.QueryRow(ctx, query, userID, tenantRef).Scan(
+		&m.UserID, &m.TenantID, &m.Name, &m.Slug, &m.DBName, &m.Role, &m.Version, &m.MFARequired, &m.SSOOnly, &m.CreatedAt, &m.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrMembershipNotFound
+		}
+		return nil, fmt.Errorf("failed to find tenant membership: %w", err)
+	}
+	return &m, nil
+}
+
+// VerifyMembership implements auth.MembershipVerifier.
+func (r *PostgresRepository) VerifyMembership(ctx context.Context, userID, tenantRef string) (*auth.Membership, error) {
+	membership, err := r.FindTenantMembership(ctx, userID, tenantRef)
+	if err != nil {
+		if errors.Is(err, domain.ErrMembershipNotFound) {
+			return nil, auth.ErrMembershipNotFound
+		}
+		return nil, err
+	}
+
+	return &auth.Membership{
+		TenantID:    membership.TenantID,
+		Version:     membership.Version,
+		MFARequired: membership.MFARequired,
+		SSOOnly:     membership.SSOOnly,
+	}, nil
+}
+
 func (r *PostgresRepository) AddTenantMembership(ctx context.Context, membership *domain.TenantMembership) error {
 	query := `INSERT INTO tenant_memberships (user_id, tenant_id, role, created_at) VALUES ($1, $2, $3, $4)`
 	_, err := r.controlDB.Exec(ctx, query, membership.UserID, membership.TenantID, membership.Role, membership.CreatedAt)
@@ -145,15 +521,29 @@ func (r *PostgresRepository) AddTenantMembership(ctx context.Context, membership
 	return nil
 }
 
-func (r *PostgresRepository) RemoveTenantMembership(ctx context.Context, userID, tenantID string) error {
-	query := `UPDATE tenant_memberships SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND tenant_id = $2 AND deleted_at IS NULL`
-	_, err := r.controlDB.Exec(ctx, query, userID, tenantID)
-	if err != nil {
-		return fmt.Errorf("failed to remove tenant membership: %w", err)
-	}
-	return nil
+func (r *PostgresRepository) RemoveTenantMembership(ctx context.Context, userID, tenantID string) (bool, error) {
+	removed := false
+	err := pgx.BeginFunc(ctx, r.controlDB, func(tx pgx.Tx) error {
+		query := `UPDATE tenant_memberships SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE user_id = $1 AND tenant_id = $2 AND deleted_at IS NULL`
+		tag, err := tx.Exec(ctx, query, userID, tenantID)
+		if err != nil {
+			return fmt.Errorf("failed to remove tenant membership: %w", err)
+		}
+		removed = tag.RowsAffected() > 0
+
+		// API keys act as their creator, so they end with the membership.
+		if _, err := tx.Exec(ctx, revokeCreatedAPIKeysQuery+` AND tenant_id = $2`, userID, tenantID); err != nil {
+			return fmt.Errorf("failed to revoke api keys: %w", err)
+		}
+		return nil
+	})
+	return removed, err
 }
 
+// revokeCreatedAPIKeysQuery revokes the API keys a user created, which stop
+// working once the user leaves the organization.
+const revokeCreatedAPIKeysQuery = `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE created_by = $1 AND revoked_at IS NULL`
+
 func (r *PostgresRepository) SoftDeleteUser(ctx context.Context, userID string) error {
 	query := `UPDATE users SET deleted_at = CURRENT_TIMESTAMP, email = CONCAT(email, '-deleted-', id), first_name = 'Deleted', last_name = 'User' WHERE id = $1 AND deleted_at IS NULL`
 	_, err := r.controlDB.Exec(ctx, query, userID)
@@ -162,12 +552,17 @@ func (r *PostgresRepository) SoftDeleteUser(ctx context.Context, userID string)
 	}
 
 	// Also soft delete all memberships
-	memQuery := `UPDATE tenant_memberships SET deleted_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND deleted_at IS NULL`
-	_, err = r.controlDB.Exec(ctx, memQuery, userID)
+	memQuery := `UPDATE tenant_memberships SET deleted_at = CURRENT_TIMESTAMP, version = version + 1 WHERE user_id = $1 AND deleted_at IS NULL`
+	if _, err := r.controlDB.Exec(ctx, memQuery, userID); err != nil {
+		return err
+	}
+
+	_, err = r.controlDB.Exec(ctx, revokeCreatedAPIKeysQuery, userID)
 	return err
 }
 
 func (r *PostgresRepository) SoftDeleteTenantUserProfile(ctx context.Context, tenantDBName string, userID string) error {
+	ctx = database.WithTenantDatabase(ctx, tenantDBName)
 	pool, err := r.registry.GetPoolByDBName(ctx, tenantDBName)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant db pool: %w", err)
@@ -181,6 +576,42 @@ func (r *PostgresRepository) SoftDeleteTenantUserProfile(ctx context.Context, te
 	return nil
 }
 
+// FindTenantUserPermissions resolves the RBAC permission codes granted to the user through their tenant roles.
+func (r *PostgresRepository) FindTenantUserPermissions(ctx context.Context, tenantDBName string, userID string) ([]string, error) {
+	ctx = database.WithTenantDatabase(ctx, tenantDBName)
+	pool, err := r.registry.GetPoolByDBName(ctx, tenantDBName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	query := `
+		SELECT DISTINCT p.code
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1
+		ORDER BY p.code
+	`
+	rows, err := pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant user permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var codes []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant user permission: %w", err)
+		}
+		codes = append(codes, code)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenant user permissions: %w", err)
+	}
+	return codes, nil
+}
+
 func (r *PostgresRepository) GetTenantDBName(ctx context.Context, tenantID string) (string, error) {
 	query := `SELECT db_name FROM tenants WHERE id = $1`
 	var dbName string
@@ -196,6 +627,7 @@ func (r *PostgresRepository) CreateTenantUserProfile(ctx context.Context, tenant
 	// Wait, the interface says CreateTenantUserProfile(ctx context.Context, tenantDBName string, profile *TenantUserProfile) error
 	// We can't use registry.GetPool(ctx) because we might not have the slug in the context, but we can do a workaround
 	// Let's assume there's a function we'll add to registry: GetPoolByDBName
+	ctx = database.WithTenantDatabase(ctx, tenantDBName)
 	pool, err := r.registry.GetPoolByDBName(ctx, tenantDBName)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant db pool: %w", err)
@@ -210,6 +642,7 @@ func (r *PostgresRepository) CreateTenantUserProfile(ctx context.Context, tenant
 }
 
 func (r *PostgresRepository) UpdateTenantUserProfile(ctx context.Context, tenantDBName string, profile *domain.TenantUserProfile) error {
+	ctx = database.WithTenantDatabase(ctx, tenantDBName)
 	pool, err := r.registry.GetPoolByDBName(ctx, tenantDBName)
 	if err != nil {
 		return fmt.Errorf("failed to get tenant db pool: %w", err)
@@ -222,3 +655,49 @@ func (r *PostgresRepository) UpdateTenantUserProfile(ctx context.Context, tenant
 	}
 	return nil
 }
+
+func (r *PostgresRepository) ListSealedSecrets(ctx context.Context) ([]domain.SealedSecret, error) {
+	query := `
+		SELECT $1::TEXT, user_id, encrypted_secret FROM user_mfa_factors
+		UNION ALL
+		SELECT $2::TEXT, tenant_id, encrypted_client_secret FROM sso_connections
+	`
+	rows, err := r.controlDB.Query(ctx, query, domain.SealedSecretMFAFactor, domain.SealedSecretSSOClientSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sealed secrets: %w", err)
+	}
+	defer rows.Close()
+
+	var secrets []domain.SealedSecret
+	for rows.Next() {
+		var secret domain.SealedSecret
+		if err := rows.Scan(&secret.Kind, &secret.OwnerID, &secret.Ciphertext); err != nil {
+			return nil, fmt.Errorf("failed to scan sealed secret: %w", err)
+		}
+		secrets = append(secrets, secret)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sealed secrets: %w", err)
+	}
+	return secrets, nil
+}
+
+// ReplaceSealedSecret only swaps a secret that still holds the ciphertext it
+// was read with, so an enrollment or SSO update meanwhile is kept.
+func (r *PostgresRepository) ReplaceSealedSecret(ctx context.Context, secret domain.SealedSecret, replacement []byte) (bool, error) {
+	var query string
+	switch secret.Kind {
+	case domain.SealedSecretMFAFactor:
+		query = `UPDATE user_mfa_factors SET encrypted_secret = $3 WHERE user_id = $1 AND encrypted_secret = $2`
+	case domain.SealedSecretSSOClientSecret:
+		query = `UPDATE sso_connections SET encrypted_client_secret = $3 WHERE tenant_id = $1 AND encrypted_client_secret = $2`
+	default:
+		return false, fmt.Errorf("unknown sealed secret kind %q", secret.Kind)
+	}
+
+	tag, err := r.controlDB.Exec(ctx, query, secret.OwnerID, secret.Ciphertext, replacement)
+	if err != nil {
+		return false, fmt.Errorf("failed to replace sealed secret: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	awsEvents "github.com/aws/aws-lambda-go/events"
+	contractEvents "github.com/bowerbird/internal/contracts/events"
+	"github.com/bowerbird/internal/identity/application/commands"
+)
+
+// OnAccountErasureDue erases accounts when the scheduler fires. The event
+// carries no payload: every run erases whatever is due.
+type OnAccountErasureDue struct {
+	command *commands.RunAccountErasuresCommand
+}
+
+func NewOnAccountErasureDue(command *commands.RunAccountErasuresCommand) *OnAccountErasureDue {
+	return &OnAccountErasureDue{command: command}
+}
+
+func (h *OnAccountErasureDue) DetailType() string {
+	return contractEvents.AccountErasureDueDetailType
+}
+
+func (h *OnAccountErasureDue) HandleEventBridge(ctx context.Context, _ awsEvents.CloudWatchEvent) error {
+	erased, err := h.command.Execute(ctx)
+	if erased > 0 {
+		slog.Info("Accounts erased", "count", erased)
+	}
+
+	return err
+}
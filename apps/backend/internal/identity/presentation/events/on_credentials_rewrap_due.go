@@ -0,0 +1,37 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	awsEvents "github.com/aws/aws-lambda-go/events"
+	contractEvents "github.com/bowerbird/internal/contracts/events"
+	"github.com/bowerbird/internal/identity/application/commands"
+)
+
+// OnCredentialsRewrapDue rewraps the TOTP and SSO client secrets under the
+// active master key, next to the connections module rewrapping credentials.
+type OnCredentialsRewrapDue struct {
+	command *commands.RewrapSecretsCommand
+}
+
+func NewOnCredentialsRewrapDue(command *commands.RewrapSecretsCommand) *OnCredentialsRewrapDue {
+	return &OnCredentialsRewrapDue{command: command}
+}
+
+func (h *OnCredentialsRewrapDue) DetailType() string {
+	return contractEvents.CredentialsRewrapDueDetailType
+}
+
+func (h *OnCredentialsRewrapDue) HandleEventBridge(ctx context.Context, _ awsEvents.CloudWatchEvent) error {
+	report, err := h.command.Execute(ctx)
+	slog.Info("Identity secrets rewrapped",
+		"rewrapped", report.Rewrapped,
+		"unchanged", report.Unchanged,
+		"skipped", report.Skipped,
+		"failed", report.Failed,
+		"key_usage", report.KeyUsage,
+	)
+
+	return err
+}
@@ -2,11 +2,14 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/bowerbird/internal/identity/application"
+	"github.com/bowerbird/internal/identity/domain"
 	"github.com/bowerbird/internal/platform/auth"
 	"github.com/bowerbird/internal/platform/config"
 	appErrors "github.com/bowerbird/internal/platform/errors"
@@ -15,43 +18,69 @@ import (
 )
 
 type AuthHandler struct {
-	authService     *application.AuthService
-	identityService *application.IdentityService
-	googleConfig    *oauth2.Config
-	microsoftConfig *oauth2.Config
-	frontendURL     string
+	authService         *application.AuthService
+	exchangeTenantToken *application.ExchangeTenantTokenCommand
+	identityService     *application.IdentityService
+	ssoService          *application.SSOService
+	googleConfig        *oauth2.Config
+	microsoftConfig     *oauth2.Config
+	frontendURL         string
 }
 
 func NewAuthHandler(
 	authService *application.AuthService,
+	exchangeTenantToken *application.ExchangeTenantTokenCommand,
 	identityService *application.IdentityService,
+	ssoService *application.SSOService,
 	googleConfig *oauth2.Config,
 	microsoftConfig *oauth2.Config,
 	frontendURL string,
 ) *AuthHandler {
 	return &AuthHandler{
-		authService:     authService,
-		identityService: identityService,
-		googleConfig:    googleConfig,
-		microsoftConfig: microsoftConfig,
-		frontendURL:     frontendURL,
+		authService:         authService,
+		exchangeTenantToken: exchangeTenantToken,
+		identityService:     identityService,
+		ssoService:          ssoService,
+		googleConfig:        googleConfig,
+		microsoftConfig:     microsoftConfig,
+		frontendURL:         frontendURL,
 	}
 }
 
 func (h *AuthHandler) Register(mux *http.ServeMux, authMiddleware func(http.Handler) http.Handler, cfg config.Config) {
 	mux.HandleFunc("POST /api/v1/auth/register-local", api.Wrap(h.RegisterLocal, cfg))
 	mux.HandleFunc("POST /api/v1/auth/login-local", api.Wrap(h.LoginLocal, cfg))
+	mux.HandleFunc("POST /api/v1/auth/verify-email", api.Wrap(h.VerifyEmail, cfg))
+	mux.HandleFunc("POST /api/v1/auth/verify-email/resend", api.Wrap(h.ResendEmailVerification, cfg))
+	mux.HandleFunc("POST /api/v1/auth/password-reset", api.Wrap(h.RequestPasswordReset, cfg))
+	mux.HandleFunc("POST /api/v1/auth/password-reset/confirm", api.Wrap(h.ResetPassword, cfg))
+	mux.HandleFunc("POST /api/v1/auth/mfa/verify", api.Wrap(h.VerifyMFA, cfg))
 	mux.HandleFunc("POST /api/v1/auth/refresh", api.Wrap(h.RefreshToken, cfg))
 	mux.HandleFunc("POST /api/v1/auth/logout", api.Wrap(h.Logout, cfg))
 	mux.HandleFunc("GET /api/v1/auth/google/login", api.Wrap(h.OAuthGoogleLogin, cfg))
 	mux.HandleFunc("GET /api/v1/auth/google/callback", api.Wrap(h.OAuthGoogleCallback, cfg))
 	mux.HandleFunc("GET /api/v1/auth/microsoft/login", api.Wrap(h.OAuthMicrosoftLogin, cfg))
 	mux.HandleFunc("GET /api/v1/auth/microsoft/callback", api.Wrap(h.OAuthMicrosoftCallback, cfg))
+	mux.HandleFunc("GET /api/v1/auth/sso/callback", api.Wrap(h.SSOCallback, cfg))
+	mux.HandleFunc("GET /api/v1/auth/sso/{tenant}/login", api.Wrap(h.SSOLogin, cfg))
 
 	// Protected routes
+	mux.Handle("POST /api/v1/auth/tenant-token", authMiddleware(api.Wrap(h.ExchangeTenantToken, cfg)))
+	mux.Handle("GET /api/v1/auth/mfa", authMiddleware(api.Wrap(h.GetMFAStatus, cfg)))
+	mux.Handle("DELETE /api/v1/auth/mfa", authMiddleware(api.Wrap(h.DisableMFA, cfg)))
+	mux.Handle("POST /api/v1/auth/mfa/totp", authMiddleware(api.Wrap(h.EnrollTOTP, cfg)))
+	mux.Handle("POST /api/v1/auth/mfa/totp/confirm", authMiddleware(api.Wrap(h.ConfirmTOTP, cfg)))
+	mux.Handle("POST /api/v1/auth/mfa/recovery-codes", authMiddleware(api.Wrap(h.RegenerateRecoveryCodes, cfg)))
 	mux.Handle("GET /api/v1/identity/tenants", authMiddleware(api.Wrap(h.ListUserTenants, cfg)))
 	mux.Handle("POST /api/v1/identity/tenants/{tenant_id}/leave", authMiddleware(api.Wrap(h.LeaveTenant, cfg)))
 	mux.Handle("DELETE /api/v1/identity/account", authMiddleware(api.Wrap(h.DeleteAccount, cfg)))
+	mux.Handle("GET /api/v1/identity/account/erasure", authMiddleware(api.Wrap(h.GetAccountErasure, cfg)))
+	mux.Handle("DELETE /api/v1/identity/account/erasure", authMiddleware(api.Wrap(h.CancelAccountErasure, cfg)))
+
+	requireManageSSO := auth.RequirePermission(SSOManagePermission)
+	mux.Handle("GET /api/v1/organization/sso", authMiddleware(requireManageSSO(api.Wrap(h.GetSSOConfiguration, cfg))))
+	mux.Handle("PUT /api/v1/organization/sso", authMiddleware(requireManageSSO(api.Wrap(h.ConfigureSSO, cfg))))
+	mux.Handle("DELETE /api/v1/organization/sso", authMiddleware(requireManageSSO(api.Wrap(h.DeleteSSOConfiguration, cfg))))
 }
 
 type LocalAuthRequest struct {
@@ -59,11 +88,85 @@ type LocalAuthRequest struct {
 	Password string `json:"password"`
 }
 
+type RegisterLocalRequest struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type RegisterPendingResponse struct {
+	VerificationRequired bool `json:"verification_required"`
+}
+
 type AuthResponse struct {
 	AccessToken string `json:"access_token"`
 	ExpiresIn   int    `json:"expires_in"`
 }
 
+// MFAChallengeResponse replaces AuthResponse when the account has MFA enabled:
+// the client must send a code together with mfa_token to /auth/mfa/verify.
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+type TOTPEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TOTPConfirmationResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	AccessToken   string   `json:"access_token"`
+	ExpiresIn     int      `json:"expires_in"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TenantTokenRequest struct {
+	TenantID string `json:"tenant_id"` // Tenant ULID or slug
+}
+
+type TenantTokenResponse struct {
+	AccessToken string   `json:"access_token"`
+	ExpiresIn   int      `json:"expires_in"`
+	TenantID    string   `json:"tenant_id"`
+	TenantSlug  string   `json:"tenant_slug"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
 func (h *AuthHandler) setRefreshTokenCookie(w http.ResponseWriter, token string) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "refresh_token",
@@ -77,20 +180,32 @@ func (h *AuthHandler) setRefreshTokenCookie(w http.ResponseWriter, token string)
 }
 
 func (h *AuthHandler) RegisterLocal(w http.ResponseWriter, r *http.Request) error {
-	var req LocalAuthRequest
+	var req RegisterLocalRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request")
 	}
+	if req.Email == "" || req.Password == "" {
+		return appErrors.New(appErrors.CodeValidation, "email and password are required")
+	}
 
-	tokens, err := h.authService.RegisterLocal(r.Context(), req.Email, req.Password)
+	result, err := h.authService.RegisterLocal(r.Context(), application.RegisterLocalInput{
+		Email:     req.Email,
+		Password:  req.Password,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+	})
 	if err != nil {
-		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to register")
+		return mapLocalAuthError(err, "failed to register")
 	}
 
-	h.setRefreshTokenCookie(w, tokens.RefreshToken)
+	if result.VerificationRequired {
+		return api.Success(w, http.StatusAccepted, RegisterPendingResponse{VerificationRequired: true})
+	}
+
+	h.setRefreshTokenCookie(w, result.Tokens.RefreshToken)
 	return api.Success(w, http.StatusOK, AuthResponse{
-		AccessToken: tokens.AccessToken,
-		ExpiresIn:   tokens.ExpiresIn,
+		AccessToken: result.Tokens.AccessToken,
+		ExpiresIn:   result.Tokens.ExpiresIn,
 	})
 }
 
@@ -100,18 +215,112 @@ func (h *AuthHandler) LoginLocal(w http.ResponseWriter, r *http.Request) error {
 		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request")
 	}
 
-	tokens, err := h.authService.LoginLocal(r.Context(), req.Email, req.Password)
+	result, err := h.authService.LoginLocal(r.Context(), req.Email, req.Password, api.ClientIPFromContext(r.Context()))
 	if err != nil {
-		return appErrors.Wrap(err, appErrors.CodeUnauthorized, "invalid credentials")
+		return mapLocalAuthError(err, "invalid credentials")
 	}
 
-	h.setRefreshTokenCookie(w, tokens.RefreshToken)
+	if result.MFAChallenge != nil {
+		return api.Success(w, http.StatusAccepted, MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    result.MFAChallenge.Token,
+			ExpiresIn:   int(time.Until(result.MFAChallenge.ExpiresAt).Seconds()),
+		})
+	}
+
+	h.setRefreshTokenCookie(w, result.Tokens.RefreshToken)
 	return api.Success(w, http.StatusOK, AuthResponse{
-		AccessToken: tokens.AccessToken,
-		ExpiresIn:   tokens.ExpiresIn,
+		AccessToken: result.Tokens.AccessToken,
+		ExpiresIn:   result.Tokens.ExpiresIn,
 	})
 }
 
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) error {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		return appErrors.New(appErrors.CodeValidation, "token is required")
+	}
+
+	if err := h.authService.VerifyEmail(r.Context(), req.Token); err != nil {
+		return mapLocalAuthError(err, "failed to verify email")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *AuthHandler) ResendEmailVerification(w http.ResponseWriter, r *http.Request) error {
+	var req EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		return appErrors.New(appErrors.CodeValidation, "email is required")
+	}
+
+	if err := h.authService.ResendEmailVerification(r.Context(), req.Email); err != nil {
+		return mapLocalAuthError(err, "failed to resend verification email")
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
+
+func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) error {
+	var req EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		return appErrors.New(appErrors.CodeValidation, "email is required")
+	}
+
+	if err := h.authService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		return mapLocalAuthError(err, "failed to request password reset")
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) error {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" || req.Password == "" {
+		return appErrors.New(appErrors.CodeValidation, "token and password are required")
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		return mapLocalAuthError(err, "failed to reset password")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func mapLocalAuthError(err error, fallback string) error {
+	var policyErr *domain.PasswordPolicyError
+	switch {
+	case errors.As(err, &policyErr):
+		return appErrors.Wrap(err, appErrors.CodeValidation, policyErr.Error())
+	case errors.Is(err, application.ErrLocalAuthDisabled):
+		return appErrors.Wrap(err, appErrors.CodeNotImplemented, "local auth is disabled")
+	case errors.Is(err, application.ErrInvalidCredentials):
+		return appErrors.Wrap(err, appErrors.CodeUnauthorized, "invalid credentials")
+	case errors.Is(err, application.ErrEmailNotVerified):
+		return appErrors.Wrap(err, appErrors.CodeForbidden, "email address has not been verified")
+	case errors.Is(err, application.ErrEmailAlreadyRegistered):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "email address is already registered")
+	case errors.Is(err, domain.ErrInvalidClientIP):
+		return appErrors.Wrap(err, appErrors.CodeForbidden, "client address could not be determined")
+	case errors.Is(err, domain.ErrLoginLocked):
+		return appErrors.Wrap(err, appErrors.CodeTooManyRequests, "too many failed login attempts, try again later")
+	case errors.Is(err, domain.ErrInvalidAuthToken):
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid or expired token")
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		return appErrors.Wrap(err, appErrors.CodeUnauthorized, "invalid mfa code")
+	case errors.Is(err, domain.ErrMFANotEnrolled):
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "mfa is not enrolled")
+	case errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "mfa is already enabled")
+	}
+
+	return appErrors.Wrap(err, appErrors.CodeInternal, fallback)
+}
+
 func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) error {
 	cookie, err := r.Cookie("refresh_token")
 	if err != nil {
@@ -201,18 +410,29 @@ func (h *AuthHandler) OAuthGoogleCallback(w http.ResponseWriter, r *http.Request
 
 	slog.Info("Fetched Identity Google user info", "email", userInfo.Email, "provider_id", userInfo.ID)
 
-	tokens, err := h.authService.OAuthLogin(r.Context(), userInfo.Email, "google", userInfo.ID, userInfo.Name, userInfo.Picture)
+	result, err := h.authService.OAuthLogin(r.Context(), userInfo.Email, "google", userInfo.ID, userInfo.Name, userInfo.Picture)
 	if err != nil {
 		return redirectOnError("oauth login failed")
 	}
 
-	slog.Info("Identity Google login successful", "email", userInfo.Email)
+	slog.Info("Identity Google login successful", "email", userInfo.Email, "mfa_challenge", result.MFAChallenge != nil)
 
-	h.setRefreshTokenCookie(w, tokens.RefreshToken)
-	http.Redirect(w, r, h.frontendURL+"/lobby", http.StatusTemporaryRedirect)
+	h.completeOAuthLogin(w, r, result)
 	return nil
 }
 
+// completeOAuthLogin sends the browser to the lobby with a session, or to the
+// MFA step with the challenge token when the account has MFA enabled.
+func (h *AuthHandler) completeOAuthLogin(w http.ResponseWriter, r *http.Request, result *application.LoginResult) {
+	if result.MFAChallenge != nil {
+		http.Redirect(w, r, h.frontendURL+"/login/mfa?mfa_token="+url.QueryEscape(result.MFAChallenge.Token), http.StatusTemporaryRedirect)
+		return
+	}
+
+	h.setRefreshTokenCookie(w, result.Tokens.RefreshToken)
+	http.Redirect(w, r, h.frontendURL+"/lobby", http.StatusTemporaryRedirect)
+}
+
 func (h *AuthHandler) OAuthMicrosoftLogin(w http.ResponseWriter, r *http.Request) error {
 	slog.Info("Starting Identity Microsoft login flow", "state", "state-token")
 	if h.microsoftConfig == nil {
@@ -269,23 +489,218 @@ func (h *AuthHandler) OAuthMicrosoftCallback(w http.ResponseWriter, r *http.Requ
 
 	slog.Info("Fetched Identity Microsoft user info", "email", userInfo.Email, "provider_id", userInfo.ID)
 
-	tokens, err := h.authService.OAuthLogin(r.Context(), userInfo.Email, "microsoft", userInfo.ID, userInfo.Name, "")
+	result, err := h.authService.OAuthLogin(r.Context(), userInfo.Email, "microsoft", userInfo.ID, userInfo.Name, "")
 	if err != nil {
 		return redirectOnError("oauth login failed")
 	}
 
-	slog.Info("Identity Microsoft login successful", "email", userInfo.Email)
+	slog.Info("Identity Microsoft login successful", "email", userInfo.Email, "mfa_challenge", result.MFAChallenge != nil)
 
-	h.setRefreshTokenCookie(w, tokens.RefreshToken)
-	http.Redirect(w, r, h.frontendURL+"/lobby", http.StatusTemporaryRedirect)
+	h.completeOAuthLogin(w, r, result)
 	return nil
 }
 
-func (h *AuthHandler) ListUserTenants(w http.ResponseWriter, r *http.Request) error {
+func (h *AuthHandler) ExchangeTenantToken(w http.ResponseWriter, r *http.Request) error {
 	claims, ok := auth.ClaimsFromContext(r.Context())
 	if !ok {
 		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
 	}
+	if claims.APIKeyID != "" {
+		return appErrors.New(appErrors.CodeForbidden, "api keys cannot be exchanged for user tokens")
+	}
+
+	var req TenantTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request")
+	}
+	if req.TenantID == "" {
+		return appErrors.New(appErrors.CodeValidation, "tenant_id is required")
+	}
+
+	result, err := h.exchangeTenantToken.Execute(r.Context(), claims, req.TenantID)
+	if err != nil {
+		if errors.Is(err, application.ErrNotTenantMember) {
+			return appErrors.Wrap(err, appErrors.CodeForbidden, "not a member of this organization")
+		}
+		if errors.Is(err, application.ErrMFARequired) {
+			return appErrors.Wrap(err, appErrors.CodeMFARequired, "this organization requires multi-factor authentication")
+		}
+		if errors.Is(err, domain.ErrSSORequired) {
+			return appErrors.Wrap(err, appErrors.CodeSSORequired, "this organization requires signing in through its sso")
+		}
+		if errors.Is(err, domain.ErrSSOSessionScope) {
+			return appErrors.Wrap(err, appErrors.CodeSSORequired, "this session was started through another organization's sso")
+		}
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to issue tenant token")
+	}
+
+	permissions := result.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return api.Success(w, http.StatusOK, TenantTokenResponse{
+		AccessToken: result.Tokens.AccessToken,
+		ExpiresIn:   result.Tokens.ExpiresIn,
+		TenantID:    result.TenantID,
+		TenantSlug:  result.TenantSlug,
+		Role:        result.Role,
+		Permissions: permissions,
+	})
+}
+
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) error {
+	var req VerifyMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.MFAToken == "" || req.Code == "" {
+		return appErrors.New(appErrors.CodeValidation, "mfa_token and code are required")
+	}
+
+	tokens, err := h.authService.VerifyMFA(r.Context(), req.MFAToken, req.Code, api.ClientIPFromContext(r.Context()))
+	if err != nil {
+		return mapLocalAuthError(err, "failed to verify mfa code")
+	}
+
+	h.setRefreshTokenCookie(w, tokens.RefreshToken)
+	return api.Success(w, http.StatusOK, AuthResponse{
+		AccessToken: tokens.AccessToken,
+		ExpiresIn:   tokens.ExpiresIn,
+	})
+}
+
+// mfaUserClaims returns the caller's claims. MFA belongs to a person, so API
+// keys cannot manage it.
+func mfaUserClaims(r *http.Request) (*auth.CustomClaims, error) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return nil, appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+	if claims.APIKeyID != "" {
+		return nil, appErrors.New(appErrors.CodeForbidden, "api keys cannot manage mfa")
+	}
+
+	return claims, nil
+}
+
+func decodeMFACode(r *http.Request) (string, error) {
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		return "", appErrors.New(appErrors.CodeValidation, "code is required")
+	}
+	return req.Code, nil
+}
+
+func (h *AuthHandler) GetMFAStatus(w http.ResponseWriter, r *http.Request) error {
+	claims, err := mfaUserClaims(r)
+	if err != nil {
+		return err
+	}
+
+	status, err := h.authService.MFAStatus(r.Context(), claims.UserID)
+	if err != nil {
+		return mapLocalAuthError(err, "failed to get mfa status")
+	}
+
+	return api.Success(w, http.StatusOK, MFAStatusResponse{
+		Enabled:                status.Enabled,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	})
+}
+
+func (h *AuthHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) error {
+	claims, err := mfaUserClaims(r)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := h.authService.EnrollMFA(r.Context(), claims.UserID)
+	if err != nil {
+		return mapLocalAuthError(err, "failed to start mfa enrollment")
+	}
+
+	return api.Success(w, http.StatusOK, TOTPEnrollmentResponse{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	})
+}
+
+func (h *AuthHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) error {
+	claims, err := mfaUserClaims(r)
+	if err != nil {
+		return err
+	}
+	code, err := decodeMFACode(r)
+	if err != nil {
+		return err
+	}
+
+	confirmation, err := h.authService.ConfirmMFA(r.Context(), claims.UserID, code)
+	if err != nil {
+		return mapLocalAuthError(err, "failed to confirm mfa enrollment")
+	}
+
+	h.setRefreshTokenCookie(w, confirmation.Tokens.RefreshToken)
+	return api.Success(w, http.StatusOK, TOTPConfirmationResponse{
+		RecoveryCodes: confirmation.RecoveryCodes,
+		AccessToken:   confirmation.Tokens.AccessToken,
+		ExpiresIn:     confirmation.Tokens.ExpiresIn,
+	})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) error {
+	claims, err := mfaUserClaims(r)
+	if err != nil {
+		return err
+	}
+	code, err := decodeMFACode(r)
+	if err != nil {
+		return err
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(r.Context(), claims.UserID, code, api.ClientIPFromContext(r.Context()))
+	if err != nil {
+		return mapLocalAuthError(err, "failed to regenerate recovery codes")
+	}
+
+	return api.Success(w, http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (h *AuthHandler) DisableMFA(w http.ResponseWriter, r *http.Request) error {
+	claims, err := mfaUserClaims(r)
+	if err != nil {
+		return err
+	}
+	code, err := decodeMFACode(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.authService.DisableMFA(r.Context(), claims.UserID, code, api.ClientIPFromContext(r.Context())); err != nil {
+		return mapLocalAuthError(err, "failed to disable mfa")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// accountUserClaims returns the caller's claims. Memberships and the account
+// belong to a person, so API keys cannot act on them.
+func accountUserClaims(r *http.Request) (*auth.CustomClaims, error) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return nil, appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+	if claims.APIKeyID != "" {
+		return nil, appErrors.New(appErrors.CodeForbidden, "api keys cannot manage the account")
+	}
+
+	return claims, nil
+}
+
+func (h *AuthHandler) ListUserTenants(w http.ResponseWriter, r *http.Request) error {
+	claims, err := accountUserClaims(r)
+	if err != nil {
+		return err
+	}
 
 	tenants, err := h.identityService.ListUserTenants(r.Context(), claims.UserID)
 	if err != nil {
@@ -296,9 +711,9 @@ func (h *AuthHandler) ListUserTenants(w http.ResponseWriter, r *http.Request) er
 }
 
 func (h *AuthHandler) LeaveTenant(w http.ResponseWriter, r *http.Request) error {
-	claims, ok := auth.ClaimsFromContext(r.Context())
-	if !ok {
-		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	claims, err := accountUserClaims(r)
+	if err != nil {
+		return err
 	}
 
 	tenantID := r.PathValue("tenant_id")
@@ -306,8 +721,10 @@ func (h *AuthHandler) LeaveTenant(w http.ResponseWriter, r *http.Request) error
 		return appErrors.New(appErrors.CodeValidation, "tenant_id is required")
 	}
 
-	err := h.identityService.LeaveTenant(r.Context(), claims.UserID, tenantID)
-	if err != nil {
+	if err := h.identityService.LeaveTenant(r.Context(), claims.UserID, tenantID); err != nil {
+		if errors.Is(err, domain.ErrMembershipNotFound) {
+			return appErrors.Wrap(err, appErrors.CodeNotFound, "tenant membership not found")
+		}
 		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to leave tenant")
 	}
 
@@ -315,16 +732,77 @@ func (h *AuthHandler) LeaveTenant(w http.ResponseWriter, r *http.Request) error
 	return nil
 }
 
+// DeleteAccount schedules the erasure of the account. The session stays
+// valid during the grace period so the user can still cancel it.
 func (h *AuthHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) error {
-	claims, ok := auth.ClaimsFromContext(r.Context())
-	if !ok {
-		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	claims, err := accountUserClaims(r)
+	if err != nil {
+		return err
+	}
+
+	erasure, err := h.identityService.RequestAccountErasure(r.Context(), claims.UserID)
+	if err != nil {
+		return mapErasureError(err, "failed to schedule account erasure")
+	}
+
+	return api.Success(w, http.StatusAccepted, newAccountErasureResponse(erasure))
+}
+
+func (h *AuthHandler) GetAccountErasure(w http.ResponseWriter, r *http.Request) error {
+	claims, err := accountUserClaims(r)
+	if err != nil {
+		return err
 	}
 
-	err := h.identityService.DeleteAccount(r.Context(), claims.UserID)
+	erasure, err := h.identityService.GetAccountErasure(r.Context(), claims.UserID)
+	if err != nil {
+		return mapErasureError(err, "failed to get account erasure")
+	}
+
+	return api.Success(w, http.StatusOK, newAccountErasureResponse(erasure))
+}
+
+func (h *AuthHandler) CancelAccountErasure(w http.ResponseWriter, r *http.Request) error {
+	claims, err := accountUserClaims(r)
 	if err != nil {
-		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to delete account")
+		return err
+	}
+
+	if err := h.identityService.CancelAccountErasure(r.Context(), claims.UserID); err != nil {
+		return mapErasureError(err, "failed to cancel account erasure")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type AccountErasureResponse struct {
+	ID           string     `json:"id"`
+	Status       string     `json:"status"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+}
+
+func newAccountErasureResponse(erasure *domain.AccountErasure) AccountErasureResponse {
+	return AccountErasureResponse{
+		ID:           erasure.ID,
+		Status:       erasure.Status,
+		RequestedAt:  erasure.RequestedAt,
+		ScheduledFor: erasure.ScheduledFor,
+		CompletedAt:  erasure.CompletedAt,
+	}
+}
+
+func mapErasureError(err error, fallback string) error {
+	switch {
+	case errors.Is(err, domain.ErrAccountOwnsOrganizations):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "transfer ownership of or delete your organizations before erasing your account")
+	case errors.Is(err, domain.ErrErasureNotFound):
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "no account erasure requested")
+	case errors.Is(err, domain.ErrErasureNotCancellable):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "account erasure is already in progress")
 	}
 
-	return h.Logout(w, r)
+	return appErrors.Wrap(err, appErrors.CodeInternal, fallback)
 }
@@ -0,0 +1,206 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/bowerbird/internal/identity/application"
+	"github.com/bowerbird/internal/identity/domain"
+	"github.com/bowerbird/internal/platform/auth"
+	appErrors "github.com/bowerbird/internal/platform/errors"
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+// SSOManagePermission is the RBAC code required to configure the organization's SSO.
+const SSOManagePermission = "settings:write"
+
+const (
+	ssoBindingCookie = "sso_binding"
+	ssoCookiePath    = "/api/v1/auth/sso"
+)
+
+type ConfigureSSORequest struct {
+	Issuer         string   `json:"issuer"`
+	ClientID       string   `json:"client_id"`
+	ClientSecret   string   `json:"client_secret"`
+	AllowedDomains []string `json:"allowed_domains"`
+	SSOOnly        bool     `json:"sso_only"`
+}
+
+// SSOConnectionResponse never includes the client secret.
+type SSOConnectionResponse struct {
+	Issuer         string    `json:"issuer"`
+	ClientID       string    `json:"client_id"`
+	AllowedDomains []string  `json:"allowed_domains"`
+	SSOOnly        bool      `json:"sso_only"`
+	LoginURL       string    `json:"login_url"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func newSSOConnectionResponse(connection *domain.SSOConnection, tenantSlug string) SSOConnectionResponse {
+	return SSOConnectionResponse{
+		Issuer:         connection.Issuer,
+		ClientID:       connection.ClientID,
+		AllowedDomains: connection.AllowedDomains,
+		SSOOnly:        connection.SSOOnly,
+		LoginURL:       ssoCookiePath + "/" + tenantSlug + "/login",
+		CreatedAt:      connection.CreatedAt,
+		UpdatedAt:      connection.UpdatedAt,
+	}
+}
+
+// SSOLogin sends the browser to the organization's identity provider. The
+// binding cookie ties the callback to this browser.
+func (h *AuthHandler) SSOLogin(w http.ResponseWriter, r *http.Request) error {
+	tenantRef := r.PathValue("tenant")
+
+	authURL, binding, err := h.ssoService.StartLogin(r.Context(), tenantRef)
+	if err != nil {
+		slog.Error("Identity SSO login failed to start", "tenant", tenantRef, "error", err)
+		http.Redirect(w, r, h.frontendURL+"/login?error=sso_failed", http.StatusTemporaryRedirect)
+		return nil
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoBindingCookie,
+		Value:    binding,
+		HttpOnly: true,
+		Secure:   true,
+		// Lax so the cookie comes back on the top-level redirect from the provider.
+		SameSite: http.SameSiteLaxMode,
+		Path:     ssoCookiePath,
+		MaxAge:   int(domain.SSOLoginTTL.Seconds()),
+	})
+	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
+	return nil
+}
+
+func (h *AuthHandler) SSOCallback(w http.ResponseWriter, r *http.Request) error {
+	http.SetCookie(w, &http.Cookie{
+		Name:     ssoBindingCookie,
+		Value:    "",
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		Path:     ssoCookiePath,
+		MaxAge:   -1,
+	})
+
+	redirectOnError := func(reason string, err error) error {
+		slog.Error("Identity SSO login callback failed", "reason", reason, "error", err)
+		http.Redirect(w, r, h.frontendURL+"/login?error="+reason, http.StatusTemporaryRedirect)
+		return nil
+	}
+
+	if providerErr := r.FormValue("error"); providerErr != "" {
+		return redirectOnError("sso_failed", errors.New(providerErr))
+	}
+
+	binding, err := r.Cookie(ssoBindingCookie)
+	if err != nil {
+		return redirectOnError("sso_failed", err)
+	}
+
+	tokens, err := h.ssoService.CompleteLogin(r.Context(), r.FormValue("state"), binding.Value, r.FormValue("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSSODomainNotAllowed), errors.Is(err, application.ErrEmailNotVerified):
+			return redirectOnError("sso_domain_not_allowed", err)
+		case errors.Is(err, domain.ErrSSOAccountNotLinked):
+			return redirectOnError("sso_account_not_linked", err)
+		default:
+			return redirectOnError("sso_failed", err)
+		}
+	}
+
+	h.setRefreshTokenCookie(w, tokens.RefreshToken)
+	http.Redirect(w, r, h.frontendURL+"/lobby", http.StatusTemporaryRedirect)
+	return nil
+}
+
+// ssoAdminClaims returns the caller's tenant claims. SSO settings can lock
+// every member out, so API keys cannot change them.
+func ssoAdminClaims(r *http.Request) (*auth.CustomClaims, error) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok || !claims.IsTenantScoped() {
+		return nil, appErrors.New(appErrors.CodeUnauthorized, "a tenant-scoped token is required")
+	}
+	if claims.APIKeyID != "" {
+		return nil, appErrors.New(appErrors.CodeForbidden, "api keys cannot manage sso")
+	}
+
+	return claims, nil
+}
+
+func (h *AuthHandler) GetSSOConfiguration(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ssoAdminClaims(r)
+	if err != nil {
+		return err
+	}
+
+	connection, err := h.ssoService.Get(r.Context(), claims.TenantID)
+	if err != nil {
+		return mapSSOError(err, "failed to get sso configuration")
+	}
+
+	return api.Success(w, http.StatusOK, newSSOConnectionResponse(connection, claims.TenantSlug))
+}
+
+func (h *AuthHandler) ConfigureSSO(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ssoAdminClaims(r)
+	if err != nil {
+		return err
+	}
+
+	var req ConfigureSSORequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	connection, err := h.ssoService.Configure(r.Context(), claims, application.ConfigureSSOInput{
+		TenantID:       claims.TenantID,
+		Issuer:         req.Issuer,
+		ClientID:       req.ClientID,
+		ClientSecret:   req.ClientSecret,
+		AllowedDomains: req.AllowedDomains,
+		SSOOnly:        req.SSOOnly,
+	})
+	if err != nil {
+		return mapSSOError(err, "failed to configure sso")
+	}
+
+	return api.Success(w, http.StatusOK, newSSOConnectionResponse(connection, claims.TenantSlug))
+}
+
+func (h *AuthHandler) DeleteSSOConfiguration(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ssoAdminClaims(r)
+	if err != nil {
+		return err
+	}
+
+	if err := h.ssoService.Delete(r.Context(), claims.TenantID); err != nil {
+		return mapSSOError(err, "failed to delete sso configuration")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func mapSSOError(err error, fallback string) error {
+	switch {
+	case errors.Is(err, domain.ErrSSONotConfigured):
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "sso is not configured for this organization")
+	case errors.Is(err, domain.ErrInvalidSSOConfig):
+		return appErrors.Wrap(err, appErrors.CodeValidation, err.Error())
+	case errors.Is(err, domain.ErrSSOSessionRequired):
+		return appErrors.Wrap(err, appErrors.CodeSSORequired, "sign in through the organization's sso before making it mandatory")
+	}
+
+	return appErrors.Wrap(err, appErrors.CodeInternal, fallback)
+}
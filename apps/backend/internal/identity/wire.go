@@ -4,19 +4,30 @@ import (
 	"net/http"
 	"strings"
 
+	connectionsApp "github.com/bowerbird/internal/connections/application"
 	"github.com/bowerbird/internal/identity/application"
+	"github.com/bowerbird/internal/identity/application/commands"
+	"github.com/bowerbird/internal/identity/application/ports"
 	identityinfra "github.com/bowerbird/internal/identity/infrastructure"
+	identityevents "github.com/bowerbird/internal/identity/presentation/events"
 	identityhttp "github.com/bowerbird/internal/identity/presentation/http"
+	inboxApp "github.com/bowerbird/internal/inbox/application"
+	"github.com/bowerbird/internal/platform/audit"
+	auditPostgres "github.com/bowerbird/internal/platform/audit/postgres"
 	"github.com/bowerbird/internal/platform/auth"
 	"github.com/bowerbird/internal/platform/config"
+	cryptoPostgres "github.com/bowerbird/internal/platform/crypto/postgres"
 	"github.com/bowerbird/internal/platform/database"
+	"github.com/bowerbird/internal/platform/events"
+	"github.com/bowerbird/internal/platform/oidc"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"golang.org/x/oauth2/microsoft"
 )
 
-func NewApplication(cfg config.Config, controlDB *pgxpool.Pool, tenantRegistry *database.Registry, tokenGen *auth.TokenGenerator) *application.Application {
+func NewApplication(cfg config.Config, controlDB *pgxpool.Pool, tenantRegistry *database.Registry, tokenGen *auth.TokenGenerator, eventBus events.EventBus, secretCipher ports.SecretCipher, recorder audit.Recorder) *application.Application {
 	if controlDB == nil {
 		panic("control plane db pool is required")
 	}
@@ -26,10 +37,26 @@ func NewApplication(cfg config.Config, controlDB *pgxpool.Pool, tenantRegistry *
 	if tokenGen == nil {
 		panic("token generator is required")
 	}
+	if eventBus == nil {
+		panic("event bus is required")
+	}
+	if secretCipher == nil {
+		panic("secret cipher is required")
+	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
 
 	identityRepo := identityinfra.NewPostgresRepository(controlDB, tenantRegistry)
+	notifier := identityinfra.NewEventNotifier(eventBus, strings.TrimRight(cfg.FrontendURL, "/"))
 
-	return application.NewApplication(identityRepo, tokenGen, cfg.AppEnv)
+	return application.NewApplication(identityRepo, identityRepo, tokenGen, notifier, secretCipher, recorder, application.LocalAuthOptions{
+		Enabled:                  cfg.LocalAuthEnabled,
+		RequireEmailVerification: cfg.RequireEmailVerification,
+	}, application.SSOOptions{
+		Client:      identityinfra.NewOIDCClient(oidc.NewClient(nil)),
+		CallbackURL: strings.TrimRight(cfg.BackendURL, "/") + "/api/v1/auth/sso/callback",
+	})
 }
 
 func NewHTTPHandler(mux *http.ServeMux, app *application.Application, controlDB *pgxpool.Pool, tenantRegistry *database.Registry, authMiddleware func(http.Handler) http.Handler, cfg config.Config) *identityhttp.AuthHandler {
@@ -46,8 +73,6 @@ func NewHTTPHandler(mux *http.ServeMux, app *application.Application, controlDB
 		panic("tenant registry is required")
 	}
 
-	repo := identityinfra.NewPostgresRepository(controlDB, tenantRegistry)
-
 	var googleConfig *oauth2.Config
 	if cfg.GoogleClientID != "" && cfg.GoogleClientSecret != "" {
 		googleConfig = &oauth2.Config{
@@ -72,7 +97,9 @@ func NewHTTPHandler(mux *http.ServeMux, app *application.Application, controlDB
 
 	handler := identityhttp.NewAuthHandler(
 		app.Commands.Auth,
-		application.NewIdentityService(repo),
+		app.Commands.ExchangeTenantToken,
+		application.NewIdentityService(app),
+		app.Commands.SSO,
 		googleConfig,
 		microsoftConfig,
 		strings.TrimRight(cfg.FrontendURL, "/"),
@@ -81,3 +108,63 @@ func NewHTTPHandler(mux *http.ServeMux, app *application.Application, controlDB
 
 	return handler
 }
+
+// NewAccountErasureDueSubscriber runs due account erasures on the scheduler's
+// event. It only needs the data stores, so the event lambda can build it
+// without the rest of the identity application.
+func NewAccountErasureDueSubscriber(
+	controlDB *pgxpool.Pool,
+	tenantRegistry *database.Registry,
+	connectionsService connectionsApp.InternalService,
+	inboxService inboxApp.InternalService,
+	filePurger platformStorage.PrefixDeleter,
+	recorder audit.Recorder,
+) *identityevents.OnAccountErasureDue {
+	if controlDB == nil {
+		panic("control plane db pool is required")
+	}
+	if tenantRegistry == nil {
+		panic("tenant registry is required")
+	}
+
+	command := commands.NewRunAccountErasuresCommand(
+		identityinfra.NewPostgresRepository(controlDB, tenantRegistry),
+		connectionsService,
+		inboxService,
+		filePurger,
+		auditPostgres.NewStore(tenantRegistry),
+		recorder,
+	)
+	return identityevents.NewOnAccountErasureDue(command)
+}
+
+// NewCredentialsRewrapDueSubscriber rewraps the TOTP and SSO client secrets
+// under the active master key on the scheduler's event.
+func NewCredentialsRewrapDueSubscriber(controlDB *pgxpool.Pool, tenantRegistry *database.Registry, rewrapper ports.SecretRewrapper) *identityevents.OnCredentialsRewrapDue {
+	if controlDB == nil {
+		panic("control plane db pool is required")
+	}
+	if tenantRegistry == nil {
+		panic("tenant registry is required")
+	}
+	if rewrapper == nil {
+		panic("secret rewrapper is required")
+	}
+
+	command := commands.NewRewrapSecretsCommand(
+		identityinfra.NewPostgresRepository(controlDB, tenantRegistry),
+		rewrapper,
+		cryptoPostgres.NewKeyUsageStore(controlDB),
+	)
+	return identityevents.NewOnCredentialsRewrapDue(command)
+}
+
+// NewMembershipVerifier lets auth.Middleware reject tenant-scoped tokens once the membership they carry has changed,
+// and sessions that do not meet the organization's MFA or SSO-only policy.
+func NewMembershipVerifier(controlDB *pgxpool.Pool, tenantRegistry *database.Registry) auth.MembershipVerifier {
+	if controlDB == nil {
+		panic("control plane db pool is required")
+	}
+
+	return identityinfra.NewPostgresRepository(controlDB, tenantRegistry)
+}
@@ -1,9 +1,13 @@
 package v1
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"time"
 
+	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
 	inboxCommands "github.com/bowerbird/internal/inbox/application/commands"
 	inboxQueries "github.com/bowerbird/internal/inbox/application/queries"
 	"github.com/bowerbird/internal/inbox/domain"
@@ -17,6 +21,9 @@ type Controller struct {
 	listMessagesUseCase        *inboxQueries.ListMessagesQuery
 	getMessageQuery            *inboxQueries.GetMessageQuery
 	syncAllAccountsCommand     *inboxCommands.SyncAllAccountsCommand
+	startHistoricalImport      *inboxCommands.StartHistoricalImportCommand
+	listHistoricalImports      *inboxQueries.ListHistoricalImportsQuery
+	listSyncRuns               *inboxQueries.ListSyncRunsQuery
 }
 
 func NewController(
@@ -24,12 +31,18 @@ func NewController(
 	listMessagesUseCase *inboxQueries.ListMessagesQuery,
 	getMessageUseCase *inboxQueries.GetMessageQuery,
 	syncAllAccountsCommand *inboxCommands.SyncAllAccountsCommand,
+	startHistoricalImport *inboxCommands.StartHistoricalImportCommand,
+	listHistoricalImports *inboxQueries.ListHistoricalImportsQuery,
+	listSyncRuns *inboxQueries.ListSyncRunsQuery,
 ) *Controller {
 	return &Controller{
 		listAccountSyncStatusQuery: listAccountHealthUseCase,
 		listMessagesUseCase:        listMessagesUseCase,
 		getMessageQuery:            getMessageUseCase,
 		syncAllAccountsCommand:     syncAllAccountsCommand,
+		startHistoricalImport:      startHistoricalImport,
+		listHistoricalImports:      listHistoricalImports,
+		listSyncRuns:               listSyncRuns,
 	}
 }
 
@@ -43,7 +56,7 @@ func (c *Controller) Sync(w http.ResponseWriter, r *http.Request) error {
 		return appErrors.New(appErrors.CodeInternal, "sync command not configured")
 	}
 
-	if err := c.syncAllAccountsCommand.Execute(r.Context(), claims.UserID); err != nil {
+	if err := c.syncAllAccountsCommand.Execute(r.Context(), connectionsApp.ActorFromClaims(claims)); err != nil {
 		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to execute sync all accounts command")
 	}
 
@@ -51,7 +64,12 @@ func (c *Controller) Sync(w http.ResponseWriter, r *http.Request) error {
 }
 
 func (c *Controller) ListAccountSyncStatus(w http.ResponseWriter, r *http.Request) error {
-	statuses, err := c.listAccountSyncStatusQuery.Execute(r.Context())
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+
+	statuses, err := c.listAccountSyncStatusQuery.Execute(r.Context(), connectionsApp.ActorFromClaims(claims))
 	if err != nil {
 		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to list account sync statuses")
 	}
@@ -64,7 +82,12 @@ func (c *Controller) ListAccountSyncStatus(w http.ResponseWriter, r *http.Reques
 }
 
 func (c *Controller) ListMessages(w http.ResponseWriter, r *http.Request) error {
-	messages, err := c.listMessagesUseCase.Execute(r.Context())
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+
+	messages, err := c.listMessagesUseCase.Execute(r.Context(), connectionsApp.ActorFromClaims(claims))
 	if err != nil {
 		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to list messages")
 	}
@@ -77,12 +100,17 @@ func (c *Controller) ListMessages(w http.ResponseWriter, r *http.Request) error
 }
 
 func (c *Controller) GetMessage(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+
 	messageID := r.PathValue("messageID")
 	if messageID == "" {
 		return appErrors.New(appErrors.CodeValidation, "message id is required")
 	}
 
-	message, err := c.getMessageQuery.Execute(r.Context(), messageID)
+	message, err := c.getMessageQuery.Execute(r.Context(), connectionsApp.ActorFromClaims(claims), messageID)
 	if err != nil {
 		if errors.Is(err, domain.ErrInboxMessageNotFound) {
 			return appErrors.Wrap(err, appErrors.CodeNotFound, "message not found")
@@ -93,3 +121,113 @@ func (c *Controller) GetMessage(w http.ResponseWriter, r *http.Request) error {
 
 	return api.Success(w, http.StatusOK, message)
 }
+
+type startHistoricalImportRequest struct {
+	// Since and Until are dates (YYYY-MM-DD); Until defaults to now and is
+	// inclusive.
+	Since string `json:"since"`
+	Until string `json:"until,omitempty"`
+}
+
+func (r startHistoricalImportRequest) dates() (time.Time, time.Time, error) {
+	since, err := time.Parse(time.DateOnly, r.Since)
+	if err != nil {
+		return time.Time{}, time.Time{}, errors.New("since must be a date (YYYY-MM-DD)")
+	}
+
+	var until time.Time
+	if r.Until != "" {
+		until, err = time.Parse(time.DateOnly, r.Until)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("until must be a date (YYYY-MM-DD)")
+		}
+		until = until.AddDate(0, 0, 1)
+	}
+
+	return since, until, nil
+}
+
+// StartHistoricalImport backfills the mail a connection received in the
+// given range. The import runs in the background; its progress is listed by
+// ListHistoricalImports.
+func (c *Controller) StartHistoricalImport(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+
+	if c.startHistoricalImport == nil {
+		return appErrors.New(appErrors.CodeInternal, "historical import not configured")
+	}
+
+	var req startHistoricalImportRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	since, until, err := req.dates()
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	actor := connectionsApp.ActorFromClaims(claims)
+	historicalImport, err := c.startHistoricalImport.Execute(r.Context(), inboxCommands.StartHistoricalImportInput{
+		ConnectionID: r.PathValue("id"),
+		Actor:        &actor,
+		Since:        since,
+		Until:        until,
+	})
+	if err != nil {
+		return mapHistoricalImportError(err, "failed to start historical import")
+	}
+
+	return api.Success(w, http.StatusAccepted, inboxQueries.ToHistoricalImportView(historicalImport))
+}
+
+func (c *Controller) ListHistoricalImports(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+
+	imports, err := c.listHistoricalImports.Execute(r.Context(), connectionsApp.ActorFromClaims(claims), r.PathValue("id"))
+	if err != nil {
+		return mapHistoricalImportError(err, "failed to list historical imports")
+	}
+
+	return api.Success(w, http.StatusOK, imports)
+}
+
+// ListSyncRuns returns the latest sync runs of a connection with their
+// stats.
+func (c *Controller) ListSyncRuns(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+
+	runs, err := c.listSyncRuns.Execute(r.Context(), connectionsApp.ActorFromClaims(claims), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, connectionsDomain.ErrConnectionNotFound) {
+			return appErrors.Wrap(err, appErrors.CodeNotFound, "connection not found")
+		}
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to list sync runs")
+	}
+
+	return api.Success(w, http.StatusOK, runs)
+}
+
+func mapHistoricalImportError(err error, fallback string) error {
+	switch {
+	case errors.Is(err, connectionsDomain.ErrConnectionNotFound):
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "connection not found")
+	case errors.Is(err, domain.ErrHistoricalImportInProgress):
+		return appErrors.Wrap(err, appErrors.CodeConflict, err.Error())
+	case errors.Is(err, domain.ErrInvalidHistoricalImportRange):
+		return appErrors.Wrap(err, appErrors.CodeValidation, err.Error())
+	}
+
+	return appErrors.Wrap(err, appErrors.CodeInternal, fallback)
+}
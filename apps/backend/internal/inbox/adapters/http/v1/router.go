@@ -24,4 +24,7 @@ func (h *Router) Register(mux *http.ServeMux, cfg config.Config, authMiddleware
 	mux.Handle("GET /api/v1/inbox/messages", authMiddleware(api.Wrap(h.controller.ListMessages, cfg)))
 	mux.Handle("GET /api/v1/inbox/messages/{messageID}", authMiddleware(api.Wrap(h.controller.GetMessage, cfg)))
 	mux.Handle("POST /api/v1/inbox/sync", authMiddleware(api.Wrap(h.controller.Sync, cfg)))
+	mux.Handle("GET /api/v1/inbox/connections/{id}/historical-imports", authMiddleware(api.Wrap(h.controller.ListHistoricalImports, cfg)))
+	mux.Handle("POST /api/v1/inbox/connections/{id}/historical-imports", authMiddleware(api.Wrap(h.controller.StartHistoricalImport, cfg)))
+	mux.Handle("GET /api/v1/inbox/connections/{id}/sync-runs", authMiddleware(api.Wrap(h.controller.ListSyncRuns, cfg)))
 }
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+
+	awsEvents "github.com/aws/aws-lambda-go/events"
+	commands "github.com/bowerbird/internal/inbox/application/commands"
+	contractJobs "github.com/bowerbird/internal/inbox/contracts/jobs"
+)
+
+type ProcessHistoricalImportChunkRequested struct {
+	command *commands.RunHistoricalImportChunkCommand
+}
+
+func NewProcessHistoricalImportChunkRequested(command *commands.RunHistoricalImportChunkCommand) *ProcessHistoricalImportChunkRequested {
+	if command == nil {
+		panic("command is required")
+	}
+
+	return &ProcessHistoricalImportChunkRequested{command: command}
+}
+
+func (h *ProcessHistoricalImportChunkRequested) JobType() string {
+	return contractJobs.HistoricalImportChunkRequestedType
+}
+
+func (h *ProcessHistoricalImportChunkRequested) HandleSQS(ctx context.Context, message awsEvents.SQSMessage) error {
+	decoded, err := contractJobs.UnmarshalHistoricalImportChunkRequested([]byte(message.Body))
+	if err != nil {
+		return err
+	}
+
+	return h.command.Execute(ctx, decoded.ImportID)
+}
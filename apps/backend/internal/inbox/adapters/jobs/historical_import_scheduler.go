@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	contractJobs "github.com/bowerbird/internal/inbox/contracts/jobs"
+	platformJobs "github.com/bowerbird/internal/platform/jobs"
+)
+
+// QueueHistoricalImportScheduler runs historical import chunks through the
+// job queue. The tenant travels with the context.
+type QueueHistoricalImportScheduler struct {
+	queue platformJobs.Queue
+	now   func() time.Time
+}
+
+func NewQueueHistoricalImportScheduler(queue platformJobs.Queue) *QueueHistoricalImportScheduler {
+	if queue == nil {
+		panic("job queue is required")
+	}
+
+	return &QueueHistoricalImportScheduler{queue: queue, now: time.Now}
+}
+
+// ScheduleHistoricalImportChunk queues the chunk after delay. The queue caps
+// it at platformJobs.MaxDelay; a chunk woken early waits again.
+func (s *QueueHistoricalImportScheduler) ScheduleHistoricalImportChunk(ctx context.Context, importID string, delay time.Duration) error {
+	payload, err := contractJobs.MarshalHistoricalImportChunkRequested(contractJobs.HistoricalImportChunkRequested{
+		ImportID:    importID,
+		RequestedAt: s.now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.queue.Dispatch(ctx, platformJobs.Job{
+		Type:    contractJobs.HistoricalImportChunkRequestedType,
+		Payload: payload,
+		Delay:   delay,
+	})
+}
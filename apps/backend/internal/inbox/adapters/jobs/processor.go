@@ -0,0 +1,10 @@
+package jobs
+
+import (
+	"github.com/bowerbird/internal/inbox/adapters/jobs/handlers"
+	commands "github.com/bowerbird/internal/inbox/application/commands"
+)
+
+func NewHistoricalImportChunkRequestedProcessor(command *commands.RunHistoricalImportChunkCommand) *handlers.ProcessHistoricalImportChunkRequested {
+	return handlers.NewProcessHistoricalImportChunkRequested(command)
+}
@@ -7,9 +7,10 @@ import (
 
 	"github.com/bowerbird/internal/inbox/adapters/provider/gmail"
 	"github.com/bowerbird/internal/inbox/domain"
+	"golang.org/x/oauth2"
 )
 
-type BuildClientFunc func(ctx context.Context, credentialsJSON []byte) (domain.MailProviderClient, error)
+type BuildClientFunc func(ctx context.Context, tokens oauth2.TokenSource) (domain.MailProviderClient, error)
 
 type Factory struct {
 	builders map[string]BuildClientFunc
@@ -19,10 +20,10 @@ func NewFactory() *Factory {
 	return &Factory{builders: map[string]BuildClientFunc{}}
 }
 
-func NewDefaultFactory(gmailOAuthConfig gmail.OAuthConfig) *Factory {
+func NewDefaultFactory() *Factory {
 	factory := NewFactory()
-	factory.Register(domain.ProviderGmail, func(ctx context.Context, credentialsJSON []byte) (domain.MailProviderClient, error) {
-		return gmail.NewOAuthHTTPClient(ctx, gmailOAuthConfig, credentialsJSON)
+	factory.Register(domain.ProviderGmail, func(ctx context.Context, tokens oauth2.TokenSource) (domain.MailProviderClient, error) {
+		return gmail.NewOAuthHTTPClient(ctx, tokens)
 	})
 	return factory
 }
@@ -32,14 +33,14 @@ func (f *Factory) Register(provider string, builder BuildClientFunc) {
 	f.builders[normalizedProvider] = builder
 }
 
-func (f *Factory) Build(ctx context.Context, provider string, credentialsJSON []byte) (domain.MailProviderClient, error) {
+func (f *Factory) Build(ctx context.Context, provider string, tokens oauth2.TokenSource) (domain.MailProviderClient, error) {
 	normalizedProvider := strings.ToLower(strings.TrimSpace(provider))
 	builder, ok := f.builders[normalizedProvider]
 	if !ok {
 		return nil, fmt.Errorf("mail provider %q is not supported", provider)
 	}
 
-	client, err := builder(ctx, credentialsJSON)
+	client, err := builder(ctx, tokens)
 	if err != nil {
 		return nil, fmt.Errorf("build provider client for %s: %w", provider, err)
 	}
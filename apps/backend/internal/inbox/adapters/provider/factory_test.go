@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/bowerbird/internal/inbox/domain"
+	"golang.org/x/oauth2"
 )
 
 type fakeClient struct{}
@@ -22,6 +23,10 @@ func (c fakeClient) DownloadAttachment(ctx context.Context, userID, messageID, a
 	return nil, nil
 }
 
+func (c fakeClient) GetMessageMetadata(ctx context.Context, userID, messageID string) (*domain.MailMessage, error) {
+	return nil, nil
+}
+
 func (c fakeClient) DownloadMessageAttachments(ctx context.Context, userID, messageID string, refs []domain.MailAttachmentRef) ([]domain.DownloadedMailAttachment, error) {
 	return nil, nil
 }
@@ -34,13 +39,17 @@ func (c fakeClient) AddLabelToMessage(ctx context.Context, userID, messageID, la
 	return nil
 }
 
+func (c fakeClient) ArchiveMessage(ctx context.Context, userID, messageID string) error {
+	return nil
+}
+
 func TestFactoryBuildUsesRegisteredProvider(t *testing.T) {
 	f := NewFactory()
-	f.Register(domain.ProviderGmail, func(ctx context.Context, credentialsJSON []byte) (domain.MailProviderClient, error) {
+	f.Register(domain.ProviderGmail, func(ctx context.Context, tokens oauth2.TokenSource) (domain.MailProviderClient, error) {
 		return fakeClient{}, nil
 	})
 
-	client, err := f.Build(context.Background(), "GMAIL", []byte(`{"refresh_token":"x"}`))
+	client, err := f.Build(context.Background(), "GMAIL", oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "x"}))
 	if err != nil {
 		t.Fatalf("build failed: %v", err)
 	}
@@ -52,7 +61,7 @@ func TestFactoryBuildUsesRegisteredProvider(t *testing.T) {
 
 func TestFactoryBuildFailsForUnsupportedProvider(t *testing.T) {
 	f := NewFactory()
-	if _, err := f.Build(context.Background(), "yahoo", []byte(`{}`)); err == nil {
+	if _, err := f.Build(context.Background(), "yahoo", nil); err == nil {
 		t.Fatal("expected unsupported provider error")
 	}
 }
@@ -60,11 +69,11 @@ func TestFactoryBuildFailsForUnsupportedProvider(t *testing.T) {
 func TestFactoryBuildPropagatesBuilderError(t *testing.T) {
 	f := NewFactory()
 	want := errors.New("boom")
-	f.Register("gmail", func(ctx context.Context, credentialsJSON []byte) (domain.MailProviderClient, error) {
+	f.Register("gmail", func(ctx context.Context, tokens oauth2.TokenSource) (domain.MailProviderClient, error) {
 		return nil, want
 	})
 
-	_, err := f.Build(context.Background(), "gmail", []byte(`{}`))
+	_, err := f.Build(context.Background(), "gmail", nil)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -15,13 +15,19 @@ import (
 	"time"
 
 	"github.com/bowerbird/internal/inbox/domain"
+	"golang.org/x/sync/errgroup"
 )
 
 const defaultBaseURL = "https://gmail.googleapis.com"
 
+// maxParallelAttachmentDownloads bounds the attachments of one message
+// downloaded at once.
+const maxParallelAttachmentDownloads = 4
+
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
+	pacer      *requestPacer
 }
 
 var _ domain.MailProviderClient = (*Client)(nil)
@@ -34,9 +40,19 @@ func NewClient(httpClient *http.Client) *Client {
 	return &Client{
 		httpClient: httpClient,
 		baseURL:    defaultBaseURL,
+		pacer:      newRequestPacer(DefaultRequestsPerSecond),
 	}
 }
 
+// do sends the request once the pacer lets it through.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if err := c.pacer.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	return c.httpClient.Do(req)
+}
+
 func (c *Client) SetBaseURL(baseURL string) {
 	c.baseURL = strings.TrimRight(baseURL, "/")
 }
@@ -68,7 +84,7 @@ func (c *Client) ListMessages(ctx context.Context, opts domain.ListMessagesOptio
 		return nil, "", fmt.Errorf("build list messages request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("list messages request failed: %w", err)
 	}
@@ -131,17 +147,46 @@ func (c *Client) requestStatusError(prefix string, resp *http.Response) error {
 }
 
 func (c *Client) GetMessage(ctx context.Context, userID, messageID string) (*domain.MailMessage, error) {
+	payload, err := c.fetchMessage(ctx, userID, messageID, url.Values{"format": {"full"}})
+	if err != nil {
+		return nil, err
+	}
+
+	msg := messageFromPayload(payload)
+	msg.PlainTextBody = extractPlainTextBody(payload.Payload)
+	msg.HTMLBody = extractHTMLBody(payload.Payload)
+	msg.Payload = mapPayloadPart(payload.Payload)
+	msg.Attachments = extractAttachments(payload.Payload)
+
+	return msg, nil
+}
+
+// GetMessageMetadata fetches the headers, labels and dates of a message
+// without its body or attachment list.
+func (c *Client) GetMessageMetadata(ctx context.Context, userID, messageID string) (*domain.MailMessage, error) {
+	payload, err := c.fetchMessage(ctx, userID, messageID, url.Values{
+		"format":          {"metadata"},
+		"metadataHeaders": {"From", "Subject", "Date"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return messageFromPayload(payload), nil
+}
+
+func (c *Client) fetchMessage(ctx context.Context, userID, messageID string, values url.Values) (*gmailMessageResponse, error) {
 	if userID == "" {
 		userID = "me"
 	}
 
-	endpoint := fmt.Sprintf("%s/gmail/v1/users/%s/messages/%s?format=full", c.baseURL, url.PathEscape(userID), url.PathEscape(messageID))
+	endpoint := fmt.Sprintf("%s/gmail/v1/users/%s/messages/%s?%s", c.baseURL, url.PathEscape(userID), url.PathEscape(messageID), values.Encode())
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("build get message request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("get message request failed: %w", err)
 	}
@@ -156,7 +201,10 @@ func (c *Client) GetMessage(ctx context.Context, userID, messageID string) (*dom
 		return nil, fmt.Errorf("decode get message response: %w", err)
 	}
 
-	attachments := extractAttachments(payload.Payload)
+	return &payload, nil
+}
+
+func messageFromPayload(payload *gmailMessageResponse) *domain.MailMessage {
 	headers := payloadHeaders(payload.Payload)
 	receivedAt := parseMailDate(headerValue(headers, "date"))
 
@@ -172,25 +220,19 @@ func (c *Client) GetMessage(ctx context.Context, userID, messageID string) (*dom
 		receivedAt = internalDate
 	}
 
-	msg := &domain.MailMessage{
-		ID:            payload.ID,
-		ThreadID:      payload.ThreadID,
-		LabelIDs:      payload.LabelIDs,
-		Subject:       headerValue(headers, "subject"),
-		Sender:        headerValue(headers, "from"),
-		Snippet:       payload.Snippet,
-		PlainTextBody: extractPlainTextBody(payload.Payload),
-		HTMLBody:      extractHTMLBody(payload.Payload),
-		Headers:       mapHeaders(headers),
-		Payload:       mapPayloadPart(payload.Payload),
-		HistoryID:     payload.HistoryID,
-		SizeEstimate:  payload.SizeEstimate,
-		ReceivedAt:    receivedAt,
-		InternalDate:  internalDate,
-		Attachments:   attachments,
+	return &domain.MailMessage{
+		ID:           payload.ID,
+		ThreadID:     payload.ThreadID,
+		LabelIDs:     payload.LabelIDs,
+		Subject:      headerValue(headers, "subject"),
+		Sender:       headerValue(headers, "from"),
+		Snippet:      payload.Snippet,
+		Headers:      mapHeaders(headers),
+		HistoryID:    payload.HistoryID,
+		SizeEstimate: payload.SizeEstimate,
+		ReceivedAt:   receivedAt,
+		InternalDate: internalDate,
 	}
-
-	return msg, nil
 }
 
 func (c *Client) DownloadAttachment(ctx context.Context, userID, messageID, attachmentID string) ([]byte, error) {
@@ -204,14 +246,14 @@ func (c *Client) DownloadAttachment(ctx context.Context, userID, messageID, atta
 		return nil, fmt.Errorf("build download attachment request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("download attachment request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("download attachment request failed with status %d", resp.StatusCode)
+		return nil, c.requestStatusError("download attachment request failed", resp)
 	}
 
 	var payload struct {
@@ -229,27 +271,46 @@ func (c *Client) DownloadAttachment(ctx context.Context, userID, messageID, atta
 	return decoded, nil
 }
 
+// DownloadMessageAttachments downloads the attachments in parallel and
+// returns them in the order of refs.
 func (c *Client) DownloadMessageAttachments(ctx context.Context, userID, messageID string, refs []domain.MailAttachmentRef) ([]domain.DownloadedMailAttachment, error) {
-	results := make([]domain.DownloadedMailAttachment, 0, len(refs))
-	for _, ref := range refs {
+	downloaded := make([]*domain.DownloadedMailAttachment, len(refs))
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(maxParallelAttachmentDownloads)
+	for i, ref := range refs {
 		if ref.AttachmentID == "" {
 			continue
 		}
 
-		data, err := c.DownloadAttachment(ctx, userID, messageID, ref.AttachmentID)
-		if err != nil {
-			return nil, err
-		}
+		group.Go(func() error {
+			data, err := c.DownloadAttachment(groupCtx, userID, messageID, ref.AttachmentID)
+			if err != nil {
+				return err
+			}
 
-		results = append(results, domain.DownloadedMailAttachment{
-			MailAttachmentRef: ref,
-			Data:              data,
+			downloaded[i] = &domain.DownloadedMailAttachment{
+				MailAttachmentRef: ref,
+				Data:              data,
+			}
+			return nil
 		})
 	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.DownloadedMailAttachment, 0, len(refs))
+	for _, attachment := range downloaded {
+		if attachment != nil {
+			results = append(results, *attachment)
+		}
+	}
 
 	return results, nil
 }
 
+// CreateLabel creates the label, or returns the ID of the existing label
+// with that name.
 func (c *Client) CreateLabel(ctx context.Context, userID, labelName string) (string, error) {
 	if userID == "" {
 		userID = "me"
@@ -273,19 +334,17 @@ func (c *Client) CreateLabel(ctx context.Context, userID, labelName string) (str
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("create label request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusConflict {
+		return c.findLabel(ctx, userID, labelName)
+	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		// Attempt to read body for error details
-		var errDetails string
-		if b, _ := json.Marshal(resp.Body); len(b) > 0 {
-			errDetails = string(b) // just roughly
-		}
-		return "", fmt.Errorf("create label request failed with status %d: %s", resp.StatusCode, errDetails)
+		return "", c.requestStatusError("create label request failed", resp)
 	}
 
 	var result struct {
@@ -298,13 +357,58 @@ func (c *Client) CreateLabel(ctx context.Context, userID, labelName string) (str
 	return result.ID, nil
 }
 
-func (c *Client) AddLabelToMessage(ctx context.Context, userID, messageID, labelID string) error {
-	if userID == "" {
-		userID = "me"
+func (c *Client) findLabel(ctx context.Context, userID, labelName string) (string, error) {
+	endpoint := fmt.Sprintf("%s/gmail/v1/users/%s/labels", c.baseURL, url.PathEscape(userID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("build list labels request: %w", err)
 	}
 
-	payload := map[string]interface{}{
+	resp, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("list labels request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", c.requestStatusError("list labels request failed", resp)
+	}
+
+	var result struct {
+		Labels []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decode list labels response: %w", err)
+	}
+
+	for _, label := range result.Labels {
+		if strings.EqualFold(label.Name, labelName) {
+			return label.ID, nil
+		}
+	}
+
+	return "", fmt.Errorf("label %q already exists but was not listed", labelName)
+}
+
+func (c *Client) AddLabelToMessage(ctx context.Context, userID, messageID, labelID string) error {
+	return c.modifyMessage(ctx, userID, messageID, map[string]interface{}{
 		"addLabelIds": []string{labelID},
+	})
+}
+
+// ArchiveMessage takes the message out of the inbox; it keeps its labels.
+func (c *Client) ArchiveMessage(ctx context.Context, userID, messageID string) error {
+	return c.modifyMessage(ctx, userID, messageID, map[string]interface{}{
+		"removeLabelIds": []string{"INBOX"},
+	})
+}
+
+func (c *Client) modifyMessage(ctx context.Context, userID, messageID string, payload map[string]interface{}) error {
+	if userID == "" {
+		userID = "me"
 	}
 
 	bodyBytes, err := json.Marshal(payload)
@@ -319,14 +423,14 @@ func (c *Client) AddLabelToMessage(ctx context.Context, userID, messageID, label
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return fmt.Errorf("modify message request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("modify message request failed with status %d", resp.StatusCode)
+		return c.requestStatusError("modify message request failed", resp)
 	}
 
 	return nil
@@ -3,6 +3,7 @@ package gmail
 import (
 	"context"
 	"encoding/base64"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -14,6 +15,7 @@ import (
 	"github.com/bowerbird/internal/inbox/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func TestListMessagesWithIncrementalQuery(t *testing.T) {
@@ -105,6 +107,77 @@ func TestGetMessageExtractsHeadersAndAttachments(t *testing.T) {
 	require.Len(t, msg.Attachments, 2)
 }
 
+func TestGetMessageMetadataRequestsHeadersOnly(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "metadata", r.URL.Query().Get("format"))
+		assert.Equal(t, []string{"From", "Subject", "Date"}, r.URL.Query()["metadataHeaders"])
+
+		_, _ = w.Write([]byte(`{
+			"id":"m1",
+			"threadId":"t1",
+			"labelIds":["INBOX"],
+			"internalDate":"1716633600000",
+			"payload":{
+				"headers":[
+					{"name":"Subject","value":"Factura Electronica"},
+					{"name":"From","value":"proveedor@example.com"}
+				]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	client.SetBaseURL(server.URL)
+
+	msg, err := client.GetMessageMetadata(context.Background(), "me", "m1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Factura Electronica", msg.Subject)
+	assert.Equal(t, "proveedor@example.com", msg.Sender)
+	assert.Equal(t, []string{"INBOX"}, msg.LabelIDs)
+	require.NotNil(t, msg.ReceivedAt)
+	assert.Empty(t, msg.Attachments)
+}
+
+func TestCreateLabelReturnsExistingLabelOnConflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/gmail/v1/users/me/labels", r.URL.Path)
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(`{"error":{"code":409,"message":"Label name exists or conflicts"}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"labels":[{"id":"INBOX","name":"INBOX"},{"id":"Label_3","name":"Bowerbird/Procesada"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	client.SetBaseURL(server.URL)
+
+	labelID, err := client.CreateLabel(context.Background(), "me", "Bowerbird/Procesada")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Label_3", labelID)
+}
+
+func TestArchiveMessageRemovesInboxLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/gmail/v1/users/me/messages/m1/modify", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"removeLabelIds":["INBOX"]}`, string(body))
+
+		_, _ = w.Write([]byte(`{"id":"m1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	client.SetBaseURL(server.URL)
+
+	require.NoError(t, client.ArchiveMessage(context.Background(), "me", "m1"))
+}
+
 func TestGetMessageFromGoldenResponseWithAttachments(t *testing.T) {
 	fixture := loadFixture(t, "gmail_message_with_attachments.golden.json")
 
@@ -277,6 +350,51 @@ func TestDownloadAttachmentDecodesBase64URLData(t *testing.T) {
 	assert.Equal(t, "xml-content", string(data))
 }
 
+func TestDownloadMessageAttachmentsKeepsOrderAndSkipsMissingIDs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attachmentID := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		_, _ = w.Write([]byte(`{"data":"` + base64.URLEncoding.EncodeToString([]byte("data-"+attachmentID)) + `"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.Client())
+	client.SetBaseURL(server.URL)
+
+	downloaded, err := client.DownloadMessageAttachments(context.Background(), "me", "m1", []domain.MailAttachmentRef{
+		{AttachmentID: "att-1"}, {AttachmentID: ""}, {AttachmentID: "att-2"}, {AttachmentID: "att-3"}, {AttachmentID: "att-4"}, {AttachmentID: "att-5"},
+	})
+	require.NoError(t, err)
+	require.Len(t, downloaded, 5)
+	for i, attachment := range downloaded {
+		assert.Equal(t, "data-"+attachment.AttachmentID, string(attachment.Data))
+		if i > 0 {
+			assert.Less(t, downloaded[i-1].AttachmentID, attachment.AttachmentID)
+		}
+	}
+}
+
+func TestRequestPacerSpacesRequestsAfterBurst(t *testing.T) {
+	pacer := newRequestPacer(100)
+
+	started := time.Now()
+	for range requestBurst + 5 {
+		require.NoError(t, pacer.Wait(context.Background()))
+	}
+
+	assert.GreaterOrEqual(t, time.Since(started), 40*time.Millisecond)
+}
+
+func TestRequestPacerStopsWaitingWhenContextIsCancelled(t *testing.T) {
+	pacer := newRequestPacer(1)
+	for range requestBurst + 1 {
+		_ = pacer.Wait(context.Background())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, pacer.Wait(ctx), context.Canceled)
+}
+
 func TestDownloadAttachmentFromGoldenResponse(t *testing.T) {
 	fixture := loadFixture(t, "gmail_message_attachment.golden.json")
 
@@ -295,15 +413,26 @@ func TestDownloadAttachmentFromGoldenResponse(t *testing.T) {
 	assert.Equal(t, []byte{'P', 'K', 0x03, 0x04}, data[:4])
 }
 
-func TestNewOAuthHTTPClientValidatesInputs(t *testing.T) {
-	_, err := NewOAuthHTTPClient(context.Background(), OAuthConfig{}, []byte(`{"access_token":"a"}`))
+func TestNewOAuthHTTPClientRequiresTokenSource(t *testing.T) {
+	_, err := NewOAuthHTTPClient(context.Background(), nil)
 	require.Error(t, err)
+}
 
-	_, err = NewOAuthHTTPClient(context.Background(), OAuthConfig{ClientID: "id", ClientSecret: "secret"}, nil)
-	require.Error(t, err)
+func TestNewOAuthHTTPClientAuthorizesRequestsWithTokenSource(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{"messages":[]}`))
+	}))
+	defer server.Close()
 
-	_, err = NewOAuthHTTPClient(context.Background(), OAuthConfig{ClientID: "id", ClientSecret: "secret"}, []byte("not-json"))
-	require.Error(t, err)
+	client, err := NewOAuthHTTPClient(context.Background(), oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "access", TokenType: "Bearer"}))
+	require.NoError(t, err)
+	client.SetBaseURL(server.URL)
+
+	_, _, err = client.ListMessages(context.Background(), domain.ListMessagesOptions{UserID: "me"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer access", authorization)
 }
 
 func loadFixture(t *testing.T, fileName string) string {
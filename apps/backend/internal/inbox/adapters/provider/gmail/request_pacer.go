@@ -0,0 +1,62 @@
+package gmail
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestsPerSecond keeps a client below Gmail's per-user quota
+	// of 250 units per second; reading a message or an attachment costs 5.
+	DefaultRequestsPerSecond = 40
+	// requestBurst lets a few requests through at once before pacing starts.
+	requestBurst = 10
+)
+
+// requestPacer spaces out the requests of one client, which the sync shares
+// across its workers, so they stay under the provider's quota together.
+type requestPacer struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRequestPacer(requestsPerSecond int) *requestPacer {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+
+	return &requestPacer{interval: time.Second / time.Duration(requestsPerSecond)}
+}
+
+// Wait blocks until the next request may be sent.
+func (p *requestPacer) Wait(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	earliest := now.Add(-requestBurst * p.interval)
+	if p.next.Before(earliest) {
+		p.next = earliest
+	}
+	at := p.next
+	p.next = p.next.Add(p.interval)
+	p.mu.Unlock()
+
+	wait := at.Sub(now)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,240 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/inbox/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const historicalImportColumns = `
+	id, connection_id, requested_by, since, until, status, progress, window_start, page_token,
+	consecutive_failures, next_attempt_at, rate_limited_until, lease_until, error,
+	created_at, updated_at, started_at, completed_at
+`
+
+func (r *PostgresRepository) CreateHistoricalImport(ctx context.Context, historicalImport *domain.HistoricalImport) error {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	progress, err := json.Marshal(historicalImport.Progress)
+	if err != nil {
+		return fmt.Errorf("failed to encode historical import progress: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO inbox_historical_imports (`+historicalImportColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+	`,
+		historicalImport.ID,
+		historicalImport.ConnectionID,
+		nullIfEmpty(historicalImport.RequestedBy),
+		historicalImport.Since,
+		historicalImport.Until,
+		historicalImport.Status,
+		progress,
+		historicalImport.WindowStart,
+		historicalImport.PageToken,
+		historicalImport.ConsecutiveFailures,
+		historicalImport.NextAttemptAt,
+		historicalImport.RateLimitedUntil,
+		historicalImport.LeaseUntil,
+		nullIfEmpty(historicalImport.Error),
+		historicalImport.CreatedAt,
+		historicalImport.UpdatedAt,
+		historicalImport.StartedAt,
+		historicalImport.CompletedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrHistoricalImportInProgress
+		}
+		return fmt.Errorf("failed to create historical import: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) GetHistoricalImport(ctx context.Context, importID string) (*domain.HistoricalImport, error) {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	row := pool.QueryRow(ctx, `SELECT `+historicalImportColumns+` FROM inbox_historical_imports WHERE id = $1`, importID)
+	historicalImport, err := scanHistoricalImport(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical import: %w", err)
+	}
+
+	return historicalImport, nil
+}
+
+func (r *PostgresRepository) ListHistoricalImports(ctx context.Context, connectionIDs []string) ([]*domain.HistoricalImport, error) {
+	if len(connectionIDs) == 0 {
+		return []*domain.HistoricalImport{}, nil
+	}
+
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT `+historicalImportColumns+`
+		FROM inbox_historical_imports
+		WHERE connection_id = ANY($1)
+		ORDER BY created_at DESC
+	`, connectionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list historical imports: %w", err)
+	}
+	defer rows.Close()
+
+	imports := []*domain.HistoricalImport{}
+	for rows.Next() {
+		historicalImport, err := scanHistoricalImport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan historical import: %w", err)
+		}
+		imports = append(imports, historicalImport)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list historical imports: %w", err)
+	}
+
+	return imports, nil
+}
+
+func (r *PostgresRepository) ClaimHistoricalImport(ctx context.Context, importID string, now, leaseUntil time.Time) (*domain.HistoricalImport, error) {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	row := pool.QueryRow(ctx, `
+		UPDATE inbox_historical_imports
+		SET lease_until = $3
+		WHERE id = $1
+		  AND status IN ('pending', 'running')
+		  AND (lease_until IS NULL OR lease_until < $2)
+		RETURNING `+historicalImportColumns, importID, now, leaseUntil)
+	historicalImport, err := scanHistoricalImport(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim historical import: %w", err)
+	}
+
+	return historicalImport, nil
+}
+
+func (r *PostgresRepository) SaveHistoricalImport(ctx context.Context, historicalImport *domain.HistoricalImport) error {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	progress, err := json.Marshal(historicalImport.Progress)
+	if err != nil {
+		return fmt.Errorf("failed to encode historical import progress: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		UPDATE inbox_historical_imports
+		SET status = $2,
+			progress = $3,
+			window_start = $4,
+			page_token = $5,
+			consecutive_failures = $6,
+			next_attempt_at = $7,
+			rate_limited_until = $8,
+			lease_until = $9,
+			error = $10,
+			updated_at = $11,
+			started_at = $12,
+			completed_at = $13
+		WHERE id = $1
+	`,
+		historicalImport.ID,
+		historicalImport.Status,
+		progress,
+		historicalImport.WindowStart,
+		historicalImport.PageToken,
+		historicalImport.ConsecutiveFailures,
+		historicalImport.NextAttemptAt,
+		historicalImport.RateLimitedUntil,
+		historicalImport.LeaseUntil,
+		nullIfEmpty(historicalImport.Error),
+		historicalImport.UpdatedAt,
+		historicalImport.StartedAt,
+		historicalImport.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save historical import: %w", err)
+	}
+
+	return nil
+}
+
+func scanHistoricalImport(row pgx.Row) (*domain.HistoricalImport, error) {
+	var historicalImport domain.HistoricalImport
+	var requestedBy, errorText *string
+	var progress []byte
+	err := row.Scan(
+		&historicalImport.ID,
+		&historicalImport.ConnectionID,
+		&requestedBy,
+		&historicalImport.Since,
+		&historicalImport.Until,
+		&historicalImport.Status,
+		&progress,
+		&historicalImport.WindowStart,
+		&historicalImport.PageToken,
+		&historicalImport.ConsecutiveFailures,
+		&historicalImport.NextAttemptAt,
+		&historicalImport.RateLimitedUntil,
+		&historicalImport.LeaseUntil,
+		&errorText,
+		&historicalImport.CreatedAt,
+		&historicalImport.UpdatedAt,
+		&historicalImport.StartedAt,
+		&historicalImport.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if requestedBy != nil {
+		historicalImport.RequestedBy = *requestedBy
+	}
+	if errorText != nil {
+		historicalImport.Error = *errorText
+	}
+	if len(progress) > 0 {
+		if err := json.Unmarshal(progress, &historicalImport.Progress); err != nil {
+			return nil, fmt.Errorf("failed to decode historical import progress: %w", err)
+		}
+	}
+
+	return &historicalImport, nil
+}
+
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
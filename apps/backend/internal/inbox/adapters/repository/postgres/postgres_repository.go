@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -26,17 +27,30 @@ func (r *PostgresRepository) GetSyncCursor(ctx context.Context, connectionID str
 	}
 
 	query := `
-		SELECT connection_id, last_synced_at, last_error, status
+		SELECT connection_id, last_synced_at, last_error, status,
+			consecutive_failures, last_succeeded_at, last_failed_at, last_error_code,
+			error_counts, next_attempt_at, rate_limited_until, suspended_at, checkpoint
 		FROM inbox_sync_cursors
 		WHERE connection_id = $1
 	`
 	var cursor domain.SyncCursor
 	var status string
+	var lastErrorCode *string
+	var errorCounts, checkpoint []byte
 	err = pool.QueryRow(ctx, query, connectionID).Scan(
 		&cursor.ConnectionID,
 		&cursor.LastSyncedAt,
 		&cursor.LastError,
 		&status,
+		&cursor.Health.ConsecutiveFailures,
+		&cursor.Health.LastSucceededAt,
+		&cursor.Health.LastFailedAt,
+		&lastErrorCode,
+		&errorCounts,
+		&cursor.Health.NextAttemptAt,
+		&cursor.Health.RateLimitedUntil,
+		&cursor.Health.SuspendedAt,
+		&checkpoint,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -49,6 +63,19 @@ func (r *PostgresRepository) GetSyncCursor(ctx context.Context, connectionID str
 	if !cursor.Status.IsValid() {
 		cursor.Status = domain.SyncCursorStatusIdle
 	}
+	if lastErrorCode != nil {
+		cursor.Health.LastErrorCode = *lastErrorCode
+	}
+	if len(errorCounts) > 0 {
+		if err := json.Unmarshal(errorCounts, &cursor.Health.ErrorCounts); err != nil {
+			return nil, fmt.Errorf("failed to decode sync error counts: %w", err)
+		}
+	}
+	if len(checkpoint) > 0 {
+		if err := json.Unmarshal(checkpoint, &cursor.Checkpoint); err != nil {
+			return nil, fmt.Errorf("failed to decode sync checkpoint: %w", err)
+		}
+	}
 
 	return &cursor, nil
 }
@@ -60,14 +87,49 @@ func (r *PostgresRepository) UpsertSyncCursor(ctx context.Context, cursor *domai
 	}
 
 	query := `
-		INSERT INTO inbox_sync_cursors (connection_id, last_synced_at, last_error, status)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO inbox_sync_cursors (
+			connection_id, last_synced_at, last_error, status,
+			consecutive_failures, last_succeeded_at, last_failed_at, last_error_code,
+			error_counts, next_attempt_at, rate_limited_until, suspended_at, checkpoint
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (connection_id) DO UPDATE SET
 			last_synced_at = EXCLUDED.last_synced_at,
 			last_error = EXCLUDED.last_error,
-			status = EXCLUDED.status
+			status = EXCLUDED.status,
+			consecutive_failures = EXCLUDED.consecutive_failures,
+			last_succeeded_at = EXCLUDED.last_succeeded_at,
+			last_failed_at = EXCLUDED.last_failed_at,
+			last_error_code = EXCLUDED.last_error_code,
+			error_counts = EXCLUDED.error_counts,
+			next_attempt_at = EXCLUDED.next_attempt_at,
+			rate_limited_until = EXCLUDED.rate_limited_until,
+			suspended_at = EXCLUDED.suspended_at,
+			checkpoint = EXCLUDED.checkpoint
 	`
-	_, err = pool.Exec(ctx, query, cursor.ConnectionID, cursor.LastSyncedAt, cursor.LastError, cursor.Status.String())
+	health := cursor.Health
+	errorCounts, err := json.Marshal(health.ErrorCounts)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync error counts: %w", err)
+	}
+	if health.ErrorCounts == nil {
+		errorCounts = []byte("{}")
+	}
+	var lastErrorCode *string
+	if health.LastErrorCode != "" {
+		lastErrorCode = &health.LastErrorCode
+	}
+	var checkpoint []byte
+	if cursor.Checkpoint != nil {
+		if checkpoint, err = json.Marshal(cursor.Checkpoint); err != nil {
+			return fmt.Errorf("failed to encode sync checkpoint: %w", err)
+		}
+	}
+	_, err = pool.Exec(ctx, query,
+		cursor.ConnectionID, cursor.LastSyncedAt, cursor.LastError, cursor.Status.String(),
+		health.ConsecutiveFailures, health.LastSucceededAt, health.LastFailedAt, lastErrorCode,
+		errorCounts, health.NextAttemptAt, health.RateLimitedUntil, health.SuspendedAt, checkpoint,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to upsert sync cursor: %w", err)
 	}
@@ -184,7 +246,117 @@ func (r *PostgresRepository) UpsertMessageAttachment(ctx context.Context, attach
 	return inserted, nil
 }
 
-func (r *PostgresRepository) ListMessageViews(ctx context.Context) ([]inboxPorts.MessageListView, error) {
+// ListConnectionAttachments flags the attachments whose object an invoice
+// points at as its source document.
+func (r *PostgresRepository) ListConnectionAttachments(ctx context.Context, connectionID string) ([]domain.StoredAttachment, error) {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	query := `
+		SELECT a.s3_key, EXISTS (SELECT 1 FROM invoice_headers h WHERE h.document_ref_s3_key = a.s3_key)
+		FROM email_attachments a
+		JOIN email_messages m ON m.id = a.message_id
+		WHERE m.account_id = $1
+	`
+	rows, err := pool.Query(ctx, query, connectionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list connection attachments: %w", err)
+	}
+	defer rows.Close()
+
+	var attachments []domain.StoredAttachment
+	for rows.Next() {
+		var attachment domain.StoredAttachment
+		if err := rows.Scan(&attachment.S3Key, &attachment.ReferencedByInvoice); err != nil {
+			return nil, fmt.Errorf("failed to scan connection attachment: %w", err)
+		}
+		attachments = append(attachments, attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list connection attachments: %w", err)
+	}
+
+	return attachments, nil
+}
+
+// DeleteConnectionMessages deletes the messages of the connection; their
+// attachment rows go with them through the foreign key.
+func (r *PostgresRepository) DeleteConnectionMessages(ctx context.Context, connectionID string) (int, error) {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	tag, err := pool.Exec(ctx, `DELETE FROM email_messages WHERE account_id = $1`, connectionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete connection messages: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *PostgresRepository) GetLabelID(ctx context.Context, connectionID, labelName string) (string, error) {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	var labelID string
+	err = pool.QueryRow(ctx, `
+		SELECT label_id FROM inbox_label_cache
+		WHERE connection_id = $1 AND label_name = $2
+	`, connectionID, labelName).Scan(&labelID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get cached label: %w", err)
+	}
+
+	return labelID, nil
+}
+
+func (r *PostgresRepository) SaveLabelID(ctx context.Context, connectionID, labelName, labelID string) error {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO inbox_label_cache (connection_id, label_name, label_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (connection_id, label_name) DO UPDATE SET
+			label_id = EXCLUDED.label_id,
+			created_at = NOW()
+	`, connectionID, labelName, labelID)
+	if err != nil {
+		return fmt.Errorf("failed to cache label: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) DeleteLabelID(ctx context.Context, connectionID, labelName string) error {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `DELETE FROM inbox_label_cache WHERE connection_id = $1 AND label_name = $2`, connectionID, labelName)
+	if err != nil {
+		return fmt.Errorf("failed to delete cached label: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListMessageViews(ctx context.Context, accountIDs []string) ([]inboxPorts.MessageListView, error) {
+	if len(accountIDs) == 0 {
+		return []inboxPorts.MessageListView{}, nil
+	}
+
 	pool, err := r.registry.GetPool(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
@@ -205,10 +377,11 @@ func (r *PostgresRepository) ListMessageViews(ctx context.Context) ([]inboxPorts
 			EXISTS(SELECT 1 FROM email_attachments a WHERE a.message_id = m.id AND a.filename ILIKE '%.pdf') AS has_pdf
 		FROM email_messages m
 		JOIN connections c ON m.account_id = c.id
+		WHERE m.account_id = ANY($1)
 		ORDER BY received_at DESC NULLS LAST
 	`
 
-	rows, err := pool.Query(ctx, query)
+	rows, err := pool.Query(ctx, query, accountIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list message views: %w", err)
 	}
@@ -243,7 +416,11 @@ func (r *PostgresRepository) ListMessageViews(ctx context.Context) ([]inboxPorts
 	return messages, nil
 }
 
-func (r *PostgresRepository) GetMessageViewByID(ctx context.Context, messageID string) (*inboxPorts.MessageDetailView, error) {
+func (r *PostgresRepository) GetMessageViewByID(ctx context.Context, messageID string, accountIDs []string) (*inboxPorts.MessageDetailView, error) {
+	if len(accountIDs) == 0 {
+		return nil, domain.ErrInboxMessageNotFound
+	}
+
 	pool, err := r.registry.GetPool(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
@@ -272,11 +449,11 @@ func (r *PostgresRepository) GetMessageViewByID(ctx context.Context, messageID s
 			EXISTS(SELECT 1 FROM email_attachments a WHERE a.message_id = m.id AND a.filename ILIKE '%.pdf') AS has_pdf
 		FROM email_messages m
 		JOIN connections c ON m.account_id = c.id
-		WHERE m.id = $1
+		WHERE m.id = $1 AND m.account_id = ANY($2)
 	`
 
 	var msg inboxPorts.MessageDetailView
-	err = pool.QueryRow(ctx, query, messageID).Scan(
+	err = pool.QueryRow(ctx, query, messageID, accountIDs).Scan(
 		&msg.ID,
 		&msg.Provider,
 		&msg.AccountID,
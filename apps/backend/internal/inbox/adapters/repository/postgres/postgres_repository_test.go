@@ -10,6 +10,9 @@ import (
 func TestPostgresRepositoryImplementsRepository(t *testing.T) {
 	var _ domain.SyncCursorRepository = (*PostgresRepository)(nil)
 	var _ domain.MessageRepository = (*PostgresRepository)(nil)
+	var _ domain.MessagePurgeRepository = (*PostgresRepository)(nil)
+	var _ domain.LabelCacheRepository = (*PostgresRepository)(nil)
+	var _ domain.SyncRunRepository = (*PostgresRepository)(nil)
 	var _ inboxPorts.MessageQueryRepository = (*PostgresRepository)(nil)
 }
 
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bowerbird/internal/inbox/domain"
+)
+
+func (r *PostgresRepository) RecordSyncRun(ctx context.Context, run *domain.SyncRun) error {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	stats, err := json.Marshal(run.Stats)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync run stats: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO inbox_sync_runs (id, connection_id, status, resumed, stats, error, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`,
+		run.ID,
+		run.ConnectionID,
+		run.Status,
+		run.Resumed,
+		stats,
+		nullIfEmpty(run.Error),
+		run.StartedAt,
+		run.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record sync run: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		DELETE FROM inbox_sync_runs
+		WHERE connection_id = $1 AND started_at < $2
+	`, run.ConnectionID, run.StartedAt.Add(-domain.SyncRunRetention))
+	if err != nil {
+		return fmt.Errorf("failed to prune sync runs: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ListSyncRuns(ctx context.Context, connectionID string, limit int) ([]*domain.SyncRun, error) {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, connection_id, status, resumed, stats, error, started_at, finished_at
+		FROM inbox_sync_runs
+		WHERE connection_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2
+	`, connectionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sync runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*domain.SyncRun{}
+	for rows.Next() {
+		var run domain.SyncRun
+		var stats []byte
+		var errorText *string
+		if err := rows.Scan(&run.ID, &run.ConnectionID, &run.Status, &run.Resumed, &stats, &errorText, &run.StartedAt, &run.FinishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan sync run: %w", err)
+		}
+		if errorText != nil {
+			run.Error = *errorText
+		}
+		if len(stats) > 0 {
+			if err := json.Unmarshal(stats, &run.Stats); err != nil {
+				return nil, fmt.Errorf("failed to decode sync run stats: %w", err)
+			}
+		}
+		runs = append(runs, &run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list sync runs: %w", err)
+	}
+
+	return runs, nil
+}
@@ -11,12 +11,21 @@ type Application struct {
 }
 
 type Commands struct {
-	SyncAccount     *commands.SyncAccountCommand
-	SyncAllAccounts *commands.SyncAllAccountsCommand
+	SyncAccount      *commands.SyncAccountCommand
+	SyncAllAccounts  *commands.SyncAllAccountsCommand
+	WriteBackMessage *commands.WriteBackMessageCommand
+
+	StartHistoricalImport    *commands.StartHistoricalImportCommand
+	RunHistoricalImportChunk *commands.RunHistoricalImportChunkCommand
+	// PurgeConnectionMessages is only reached through InternalService.
+	PurgeConnectionMessages *commands.PurgeConnectionMessagesCommand
 }
 
 type Queries struct {
 	ListAccountHealth *queries.ListAccountHealthQuery
 	ListMessages      *queries.ListMessagesQuery
 	GetMessage        *queries.GetMessageQuery
+
+	ListHistoricalImports *queries.ListHistoricalImportsQuery
+	ListSyncRuns          *queries.ListSyncRunsQuery
 }
@@ -0,0 +1,312 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/inbox/domain"
+	"github.com/bowerbird/internal/platform/id"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+// HistoricalImportScheduler queues the next chunk of an import.
+type HistoricalImportScheduler interface {
+	ScheduleHistoricalImportChunk(ctx context.Context, importID string, delay time.Duration) error
+}
+
+type StartHistoricalImportInput struct {
+	ConnectionID string
+	// Actor is nil when the system starts the import, e.g. for the range
+	// picked while connecting the mailbox.
+	Actor *connectionsDomain.Actor
+	Since time.Time
+	// Until defaults to now.
+	Until time.Time
+}
+
+// StartHistoricalImportCommand records an import and queues its first chunk.
+type StartHistoricalImportCommand struct {
+	imports            domain.HistoricalImportRepository
+	connectionsService connectionsApp.InternalService
+	scheduler          HistoricalImportScheduler
+	idGenerator        func() string
+}
+
+func NewStartHistoricalImportCommand(
+	imports domain.HistoricalImportRepository,
+	connectionsService connectionsApp.InternalService,
+	scheduler HistoricalImportScheduler,
+) *StartHistoricalImportCommand {
+	if imports == nil {
+		panic("start historical import command: historical import repository is required")
+	}
+
+	if connectionsService == nil {
+		panic("start historical import command: connections service is required")
+	}
+
+	if scheduler == nil {
+		panic("start historical import command: scheduler is required")
+	}
+
+	return &StartHistoricalImportCommand{
+		imports:            imports,
+		connectionsService: connectionsService,
+		scheduler:          scheduler,
+		idGenerator:        id.NewULID,
+	}
+}
+
+func (c *StartHistoricalImportCommand) Execute(ctx context.Context, input StartHistoricalImportInput) (*domain.HistoricalImport, error) {
+	if input.ConnectionID == "" {
+		return nil, errors.New("connection id is required")
+	}
+
+	requestedBy := ""
+	if input.Actor != nil {
+		// A connection the actor may not configure is reported as not found.
+		configurable, err := c.connectionsService.ListAccessibleConnections(ctx, *input.Actor, connectionsDomain.AccessConfigure)
+		if err != nil {
+			return nil, fmt.Errorf("list configurable connections: %w", err)
+		}
+		if !slices.ContainsFunc(configurable, func(conn connectionsApp.ConnectionInfo) bool { return conn.ID == input.ConnectionID }) {
+			return nil, connectionsDomain.ErrConnectionNotFound
+		}
+		requestedBy = input.Actor.UserID
+	}
+
+	now := time.Now().UTC()
+	until := input.Until
+	if until.IsZero() {
+		until = now
+	}
+
+	historicalImport, err := domain.NewHistoricalImport(c.idGenerator(), input.ConnectionID, requestedBy, input.Since, until, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.imports.CreateHistoricalImport(ctx, historicalImport); err != nil {
+		return nil, err
+	}
+
+	if err := c.scheduler.ScheduleHistoricalImportChunk(ctx, historicalImport.ID, 0); err != nil {
+		return nil, fmt.Errorf("schedule historical import: %w", err)
+	}
+
+	return historicalImport, nil
+}
+
+// RunHistoricalImportChunkCommand lists and imports a few pages of an import
+// and queues the next chunk. Messages go through the same pipeline and worker
+// pool as the incremental sync, so mail it already brought in is not
+// duplicated.
+type RunHistoricalImportChunkCommand struct {
+	imports            domain.HistoricalImportRepository
+	cursorRepo         domain.SyncCursorRepository
+	connectionsService connectionsApp.InternalService
+	providerFactory    ProviderClientFactory
+	sync               *SyncAccountCommand
+	scheduler          HistoricalImportScheduler
+	logger             *slog.Logger
+	// config
+	pagesPerChunk int
+	pageSize      int
+}
+
+func NewRunHistoricalImportChunkCommand(
+	imports domain.HistoricalImportRepository,
+	cursorRepo domain.SyncCursorRepository,
+	connectionsService connectionsApp.InternalService,
+	providerFactory ProviderClientFactory,
+	sync *SyncAccountCommand,
+	scheduler HistoricalImportScheduler,
+) *RunHistoricalImportChunkCommand {
+	if imports == nil {
+		panic("run historical import chunk command: historical import repository is required")
+	}
+
+	if cursorRepo == nil {
+		panic("run historical import chunk command: sync cursor repository is required")
+	}
+
+	if connectionsService == nil {
+		panic("run historical import chunk command: connections service is required")
+	}
+
+	if providerFactory == nil {
+		panic("run historical import chunk command: provider factory is required")
+	}
+
+	if sync == nil {
+		panic("run historical import chunk command: sync account command is required")
+	}
+
+	if scheduler == nil {
+		panic("run historical import chunk command: scheduler is required")
+	}
+
+	return &RunHistoricalImportChunkCommand{
+		imports:            imports,
+		cursorRepo:         cursorRepo,
+		connectionsService: connectionsService,
+		providerFactory:    providerFactory,
+		sync:               sync,
+		scheduler:          scheduler,
+		logger:             slog.Default(),
+		pagesPerChunk:      5,
+		pageSize:           100,
+	}
+}
+
+func (c *RunHistoricalImportChunkCommand) Execute(ctx context.Context, importID string) error {
+	tenantID, err := tenant.TenantIDFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	historicalImport, err := c.imports.ClaimHistoricalImport(ctx, importID, now, now.Add(domain.HistoricalImportLease))
+	if err != nil {
+		return err
+	}
+	if historicalImport == nil {
+		// Finished, or another run holds it; a redelivered job lands here.
+		c.logger.Info("historical import chunk skipped", "tenant_id", tenantID, "import_id", importID)
+		return nil
+	}
+
+	if wait := c.waitFor(ctx, historicalImport, now); wait > 0 {
+		return c.reschedule(ctx, historicalImport, wait)
+	}
+
+	account, found, err := c.connectionsService.FindActiveConnection(ctx, historicalImport.ConnectionID)
+	if err != nil {
+		return c.release(ctx, historicalImport, err)
+	}
+	if !found {
+		historicalImport.Fail("connection is not active", now)
+		return c.imports.SaveHistoricalImport(ctx, historicalImport)
+	}
+
+	historicalImport.Start(now)
+	if err := c.runPages(ctx, tenantID, account, historicalImport); err != nil {
+		return c.recordFailure(ctx, tenantID, account, historicalImport, err)
+	}
+
+	if historicalImport.Done() {
+		historicalImport.Complete(time.Now())
+		c.logger.Info("historical import completed", "tenant_id", tenantID, "import_id", historicalImport.ID, "messages_imported", historicalImport.Progress.MessagesImported)
+		return c.imports.SaveHistoricalImport(ctx, historicalImport)
+	}
+
+	return c.reschedule(ctx, historicalImport, 0)
+}
+
+// waitFor returns how long the chunk has to wait after a failure or while
+// the provider rate limits the connection.
+func (c *RunHistoricalImportChunkCommand) waitFor(ctx context.Context, historicalImport *domain.HistoricalImport, now time.Time) time.Duration {
+	var wait time.Duration
+	if historicalImport.NextAttemptAt != nil {
+		wait = historicalImport.NextAttemptAt.Sub(now)
+	}
+
+	cursor, err := c.cursorRepo.GetSyncCursor(ctx, historicalImport.ConnectionID)
+	if err == nil && cursor != nil && cursor.Health.RateLimitedUntil != nil {
+		wait = max(wait, cursor.Health.RateLimitedUntil.Sub(now))
+	}
+
+	return wait
+}
+
+func (c *RunHistoricalImportChunkCommand) runPages(ctx context.Context, tenantID string, account connectionsApp.ConnectionInfo, historicalImport *domain.HistoricalImport) error {
+	tokens := c.connectionsService.TokenSource(ctx, account.ID)
+	if _, err := tokens.Token(); err != nil {
+		return fmt.Errorf("get account token: %w", err)
+	}
+
+	mailClient, err := c.providerFactory.Build(ctx, account.Provider, tokens)
+	if err != nil {
+		return fmt.Errorf("build provider client: %w", err)
+	}
+
+	for page := 0; page < c.pagesPerChunk && !historicalImport.Done(); page++ {
+		start, end := historicalImport.Window()
+		refs, nextPageToken, err := mailClient.ListMessages(ctx, domain.ListMessagesOptions{
+			UserID:     "me",
+			Query:      joinQuery(windowQuery(start, end), ingestionQuery(account.IngestionRules)),
+			PageToken:  historicalImport.PageToken,
+			MaxResults: c.pageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("list provider messages: %w", err)
+		}
+
+		results, err := c.sync.processMessages(ctx, tenantID, account, refs, mailClient)
+		if err != nil {
+			return err
+		}
+
+		imported, skipped := 0, 0
+		for _, result := range results {
+			if result.skipped() {
+				skipped++
+				continue
+			}
+			imported++
+		}
+
+		historicalImport.RecordPage(nextPageToken, len(refs), imported, skipped, time.Now())
+		if err := c.imports.SaveHistoricalImport(ctx, historicalImport); err != nil {
+			return fmt.Errorf("save historical import checkpoint: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *RunHistoricalImportChunkCommand) recordFailure(ctx context.Context, tenantID string, account connectionsApp.ConnectionInfo, historicalImport *domain.HistoricalImport, err error) error {
+	err = classifySyncError(account, err)
+	now := time.Now().UTC()
+
+	if shouldMarkRequiresReconnect(err) {
+		_ = c.connectionsService.MarkRequiresReconnect(ctx, account.ID, string(reconnectReason(err)))
+		historicalImport.Fail(err.Error(), now)
+		return c.imports.SaveHistoricalImport(ctx, historicalImport)
+	}
+
+	if !historicalImport.RecordFailure(err.Error(), syncFailure(err), now) {
+		c.logger.Warn("historical import failed", "tenant_id", tenantID, "import_id", historicalImport.ID, "error", err)
+		return c.imports.SaveHistoricalImport(ctx, historicalImport)
+	}
+
+	return c.reschedule(ctx, historicalImport, historicalImport.NextAttemptAt.Sub(now))
+}
+
+// reschedule saves the checkpoint, releases the lease and queues the next
+// chunk.
+func (c *RunHistoricalImportChunkCommand) reschedule(ctx context.Context, historicalImport *domain.HistoricalImport, delay time.Duration) error {
+	historicalImport.Release()
+	if err := c.imports.SaveHistoricalImport(ctx, historicalImport); err != nil {
+		return err
+	}
+
+	return c.scheduler.ScheduleHistoricalImportChunk(ctx, historicalImport.ID, max(delay, 0))
+}
+
+// release gives the import back so the retried job can claim it.
+func (c *RunHistoricalImportChunkCommand) release(ctx context.Context, historicalImport *domain.HistoricalImport, err error) error {
+	historicalImport.Release()
+	_ = c.imports.SaveHistoricalImport(ctx, historicalImport)
+	return err
+}
+
+func windowQuery(start, end time.Time) string {
+	return fmt.Sprintf("after:%d before:%d", start.Unix(), end.Unix())
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/inbox/domain"
+)
+
+var errMessageExcluded = errors.New("message excluded by ingestion rules")
+
+// ingestionQuery narrows the provider search with the rules it can express.
+// Senders, subjects and age are checked again on each message's metadata,
+// since the provider matches them loosely.
+func ingestionQuery(rules connectionsDomain.IngestionRules) string {
+	var terms []string
+	if term := anyOf("label", rules.Labels); term != "" {
+		terms = append(terms, term)
+	}
+	if term := anyOf("from", rules.AllowedSenders); term != "" {
+		terms = append(terms, term)
+	}
+	for _, sender := range rules.BlockedSenders {
+		terms = append(terms, "-from:"+searchValue(sender))
+	}
+	if rules.RequireAttachments {
+		terms = append(terms, "has:attachment")
+	}
+	if rules.MaxAgeDays > 0 {
+		terms = append(terms, fmt.Sprintf("newer_than:%dd", rules.MaxAgeDays))
+	}
+	return strings.Join(terms, " ")
+}
+
+// checksMetadata reports whether the rules need a message's headers before
+// it is downloaded.
+func checksMetadata(rules connectionsDomain.IngestionRules) bool {
+	return len(rules.AllowedSenders) > 0 ||
+		len(rules.BlockedSenders) > 0 ||
+		len(rules.SubjectPatterns) > 0 ||
+		rules.MaxAgeDays > 0
+}
+
+func ingestionCandidate(message *domain.MailMessage, withAttachments bool) connectionsDomain.IngestionCandidate {
+	candidate := connectionsDomain.IngestionCandidate{
+		Sender:     message.Sender,
+		Subject:    message.Subject,
+		ReceivedAt: message.InternalDate,
+	}
+	if candidate.ReceivedAt == nil {
+		candidate.ReceivedAt = message.ReceivedAt
+	}
+	if withAttachments {
+		hasAttachments := len(message.Attachments) > 0
+		candidate.HasAttachments = &hasAttachments
+	}
+	return candidate
+}
+
+func anyOf(operator string, values []string) string {
+	switch len(values) {
+	case 0:
+		return ""
+	case 1:
+		return operator + ":" + searchValue(values[0])
+	}
+
+	terms := make([]string, 0, len(values))
+	for _, value := range values {
+		terms = append(terms, operator+":"+searchValue(value))
+	}
+	return "{" + strings.Join(terms, " ") + "}"
+}
+
+func searchValue(value string) string {
+	value = strings.ReplaceAll(value, `"`, "")
+	if strings.ContainsAny(value, " {}()") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+func joinQuery(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"testing"
+
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+)
+
+func TestIngestionQuery(t *testing.T) {
+	rules := connectionsDomain.IngestionRules{
+		AllowedSenders:     []string{"acme.com", "facturas@proveedor.co"},
+		BlockedSenders:     []string{"marketing.acme.com"},
+		Labels:             []string{"Facturas Proveedores"},
+		SubjectPatterns:    []string{"factura"},
+		RequireAttachments: true,
+		MaxAgeDays:         30,
+	}
+
+	want := `label:"Facturas Proveedores" {from:acme.com from:facturas@proveedor.co} -from:marketing.acme.com has:attachment newer_than:30d`
+	if got := ingestionQuery(rules); got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got := joinQuery("after:1716633600", ingestionQuery(connectionsDomain.IngestionRules{})); got != "after:1716633600" {
+		t.Fatalf("expected empty rules to leave the query alone, got %q", got)
+	}
+}
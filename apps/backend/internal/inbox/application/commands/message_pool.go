@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"context"
+	"errors"
+
+	connectionsApp "github.com/bowerbird/internal/connections/application"
+	"github.com/bowerbird/internal/inbox/domain"
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultMessageConcurrency is how many messages of one connection a sync
+// processes at once. The provider client paces the requests they share.
+const DefaultMessageConcurrency = 4
+
+// messageOutcome is what processing a message did, also when it failed.
+type messageOutcome struct {
+	// created is false for messages already in the inbox.
+	created         bool
+	bytesDownloaded int64
+}
+
+// messageResult is the outcome of one listed message.
+type messageResult struct {
+	// processed is false for messages never started because the run stopped.
+	processed bool
+	outcome   messageOutcome
+	err       error
+}
+
+// skipped reports a message left out for good, which does not hold the
+// cursor back.
+func (r messageResult) skipped() bool {
+	return errors.Is(r.err, errPayloadRejected) || errors.Is(r.err, errMessageExcluded)
+}
+
+func (r messageResult) rejected() bool {
+	return errors.Is(r.err, errPayloadRejected)
+}
+
+func (r messageResult) settled() bool {
+	return r.processed && (r.err == nil || r.skipped())
+}
+
+// processMessages runs refs through processSingleMessage with at most
+// messageConcurrency in flight, starting them in the order given. The first
+// error that is not a skipped message stops the run; messages in flight are
+// cancelled and the rest never start. Results follow the order of refs.
+func (c *SyncAccountCommand) processMessages(
+	ctx context.Context,
+	tenantID string,
+	account connectionsApp.ConnectionInfo,
+	refs []domain.MessageRef,
+	client domain.MailProviderClient,
+) ([]messageResult, error) {
+	results := make([]messageResult, len(refs))
+	// Compiled once for the whole page; the workers share it.
+	filter := account.IngestionRules.Filter()
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(c.messageConcurrency, 1))
+
+	for i, ref := range refs {
+		if groupCtx.Err() != nil {
+			break
+		}
+
+		group.Go(func() error {
+			// A message waiting for a worker when the run stopped never starts.
+			if groupCtx.Err() != nil {
+				return nil
+			}
+
+			outcome, err := c.processSingleMessage(groupCtx, tenantID, account, filter, ref, client)
+			results[i] = messageResult{processed: true, outcome: outcome, err: err}
+			if err != nil && !results[i].skipped() {
+				return err
+			}
+			return nil
+		})
+	}
+
+	return results, group.Wait()
+}
+
+// settledIDs returns the messages that need no retry.
+func settledIDs(refs []domain.MessageRef, results []messageResult) []string {
+	var ids []string
+	for i, result := range results {
+		if result.settled() {
+			ids = append(ids, refs[i].ID)
+		}
+	}
+
+	return ids
+}
+
+// recordResults adds the results of a page to the stats of the run.
+func recordResults(stats *domain.SyncRunStats, results []messageResult) {
+	for _, result := range results {
+		if !result.processed {
+			continue
+		}
+
+		stats.MessagesSeen++
+		stats.BytesDownloaded += result.outcome.bytesDownloaded
+		switch {
+		case result.rejected():
+			stats.MessagesRejected++
+		case result.skipped():
+			stats.MessagesSkipped++
+		case result.err == nil && result.outcome.created:
+			stats.MessagesNew++
+		}
+	}
+}
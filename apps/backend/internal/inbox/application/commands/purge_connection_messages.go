@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bowerbird/internal/inbox/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type PurgeConnectionMessagesResult struct {
+	Messages            int
+	Attachments         int
+	RetainedAttachments int
+}
+
+type PurgeConnectionMessagesCommand struct {
+	repo  domain.MessagePurgeRepository
+	files platformStorage.FileDeleter
+}
+
+func NewPurgeConnectionMessagesCommand(repo domain.MessagePurgeRepository, files platformStorage.FileDeleter) *PurgeConnectionMessagesCommand {
+	if repo == nil {
+		panic("message purge repository is required")
+	}
+	if files == nil {
+		panic("file deleter is required")
+	}
+
+	return &PurgeConnectionMessagesCommand{repo: repo, files: files}
+}
+
+// Execute deletes the attachment objects first and the rows last, so a
+// failed purge still lists what is left when it is retried.
+func (cmd *PurgeConnectionMessagesCommand) Execute(ctx context.Context, connectionID string) (PurgeConnectionMessagesResult, error) {
+	var result PurgeConnectionMessagesResult
+
+	attachments, err := cmd.repo.ListConnectionAttachments(ctx, connectionID)
+	if err != nil {
+		return result, err
+	}
+
+	var keys []string
+	for _, attachment := range attachments {
+		if attachment.ReferencedByInvoice {
+			result.RetainedAttachments++
+			continue
+		}
+		keys = append(keys, attachment.S3Key)
+	}
+
+	result.Attachments, err = cmd.files.DeleteFiles(ctx, platformStorage.DeleteFilesInput{Paths: keys})
+	if err != nil {
+		return result, fmt.Errorf("delete attachments of %s: %w", connectionID, err)
+	}
+
+	result.Messages, err = cmd.repo.DeleteConnectionMessages(ctx, connectionID)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
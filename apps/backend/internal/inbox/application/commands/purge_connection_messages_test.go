@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/bowerbird/internal/inbox/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type memoryMessagePurge struct {
+	attachments []domain.StoredAttachment
+	messages    int
+}
+
+func (r *memoryMessagePurge) ListConnectionAttachments(ctx context.Context, connectionID string) ([]domain.StoredAttachment, error) {
+	return r.attachments, nil
+}
+
+func (r *memoryMessagePurge) DeleteConnectionMessages(ctx context.Context, connectionID string) (int, error) {
+	deleted := r.messages
+	r.messages = 0
+	return deleted, nil
+}
+
+type recordingFileDeleter struct {
+	deleted []string
+}
+
+func (d *recordingFileDeleter) DeleteFiles(ctx context.Context, input platformStorage.DeleteFilesInput) (int, error) {
+	d.deleted = append(d.deleted, input.Paths...)
+	return len(input.Paths), nil
+}
+
+func TestPurgeConnectionMessagesKeepsInvoiceDocuments(t *testing.T) {
+	repo := &memoryMessagePurge{
+		messages: 2,
+		attachments: []domain.StoredAttachment{
+			{S3Key: "tenant/t/inbox/c/messages/m1/attachments/a1.pdf"},
+			{S3Key: "tenant/t/inbox/c/messages/m1/attachments/a2.xml", ReferencedByInvoice: true},
+			{S3Key: "tenant/t/inbox/c/messages/m2/attachments/a3.png"},
+		},
+	}
+	files := &recordingFileDeleter{}
+
+	result, err := NewPurgeConnectionMessagesCommand(repo, files).Execute(context.Background(), "c")
+	if err != nil {
+		t.Fatalf("purge failed: %v", err)
+	}
+
+	if result.Messages != 2 || result.Attachments != 2 || result.RetainedAttachments != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if slices.Contains(files.deleted, "tenant/t/inbox/c/messages/m1/attachments/a2.xml") {
+		t.Fatalf("invoice source document must be kept, deleted %v", files.deleted)
+	}
+}
@@ -7,43 +7,58 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"path/filepath"
 	"strings"
 	"time"
 
 	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
 	"github.com/bowerbird/internal/inbox/domain"
 	platformEvents "github.com/bowerbird/internal/platform/events"
 	"github.com/bowerbird/internal/platform/id"
 	platformStorage "github.com/bowerbird/internal/platform/storage"
 	"github.com/bowerbird/internal/platform/tenant"
+	"golang.org/x/oauth2"
 )
 
 type ProviderClientFactory interface {
-	Build(ctx context.Context, provider string, credentialsJSON []byte) (domain.MailProviderClient, error)
+	Build(ctx context.Context, provider string, tokens oauth2.TokenSource) (domain.MailProviderClient, error)
 }
 
 type SyncAccountCommand struct {
 	cursorRepo         domain.SyncCursorRepository
 	messageRepo        domain.MessageRepository
+	syncRuns           domain.SyncRunRepository
 	connectionsService connectionsApp.InternalService
 	providerFactory    ProviderClientFactory
 	eventBus           platformEvents.EventBus
 	fileStore          platformStorage.FileStore
 	idGenerator        func() string
+	logger             *slog.Logger
 	// config
+	initialSyncDays    int
+	messageConcurrency int
 	perMessageTimeout  time.Duration
 	maxRawMessageBytes int
 	maxAttachmentBytes int64
 }
 
+// DefaultInitialSyncDays is how far back the first sync of a connection
+// reaches; older mail is brought in by a historical import.
+const DefaultInitialSyncDays = 10
+
 type SyncAccountCommandInput struct {
 	AccountID string
+	// Force runs the sync even while the connection is backing off, e.g.
+	// right after the owner (re)connects it.
+	Force bool
 }
 
 func NewSyncAccountCommand(
 	cursorRepo domain.SyncCursorRepository,
 	messageRepo domain.MessageRepository,
+	syncRuns domain.SyncRunRepository,
 	connectionsService connectionsApp.InternalService,
 	providerFactory ProviderClientFactory,
 	eventBus platformEvents.EventBus,
@@ -57,6 +72,10 @@ func NewSyncAccountCommand(
 		panic("sync account command: message repository is required")
 	}
 
+	if syncRuns == nil {
+		panic("sync account command: sync run repository is required")
+	}
+
 	if connectionsService == nil {
 		panic("sync account command: connections service is required")
 	}
@@ -76,17 +95,39 @@ func NewSyncAccountCommand(
 	return &SyncAccountCommand{
 		cursorRepo:         cursorRepo,
 		messageRepo:        messageRepo,
+		syncRuns:           syncRuns,
 		connectionsService: connectionsService,
 		providerFactory:    providerFactory,
 		eventBus:           eventBus,
 		fileStore:          fileStore,
 		idGenerator:        id.NewULID,
+		logger:             slog.Default(),
+		initialSyncDays:    DefaultInitialSyncDays,
+		messageConcurrency: DefaultMessageConcurrency,
 		perMessageTimeout:  60 * time.Second,
 		maxRawMessageBytes: 128 * 1024 * 1024, // 128MB
 		maxAttachmentBytes: 128 * 1024 * 1024, // 128MB
 	}
 }
 
+// WithInitialSyncDays overrides how far back the first sync of a connection
+// reaches.
+func (c *SyncAccountCommand) WithInitialSyncDays(days int) *SyncAccountCommand {
+	if days > 0 {
+		c.initialSyncDays = days
+	}
+	return c
+}
+
+// WithMessageConcurrency overrides how many messages of a connection are
+// processed at once.
+func (c *SyncAccountCommand) WithMessageConcurrency(concurrency int) *SyncAccountCommand {
+	if concurrency > 0 {
+		c.messageConcurrency = concurrency
+	}
+	return c
+}
+
 func (c *SyncAccountCommand) Execute(ctx context.Context, input SyncAccountCommandInput) error {
 	tenantID, err := tenant.TenantIDFromContext(ctx)
 	if err != nil {
@@ -98,54 +139,83 @@ func (c *SyncAccountCommand) Execute(ctx context.Context, input SyncAccountComma
 		return err
 	}
 
-	cursor, err := c.ensureCursor(ctx, account.ID)
+	cursor, err := c.ensureCursor(ctx, account.ID, input.Force)
 	if err != nil {
+		if errors.Is(err, domain.ErrSyncDeferred) {
+			c.logger.Info("connection sync deferred", "tenant_id", tenantID, "account_id", account.ID, "reason", err)
+		}
 		return err
 	}
 
-	if err := c.syncAccount(ctx, tenantID, account, cursor); err != nil {
+	run, err := domain.NewSyncRun(c.idGenerator(), account.ID, cursor.Checkpoint != nil, time.Now())
+	if err != nil {
+		return fmt.Errorf("new sync run: %w", err)
+	}
+	defer c.recordRun(ctx, tenantID, run)
+
+	if err := c.syncAccount(ctx, tenantID, account, cursor, &run.Stats); err != nil {
 		err = classifySyncError(account, err)
 
-		cursor.MarkSyncFailed(err.Error())
+		wasSuspended := cursor.Health.SuspendedAt != nil
+		cursor.RecordSyncFailure(err.Error(), syncFailure(err), time.Now().UTC())
 		_ = c.cursorRepo.UpsertSyncCursor(ctx, cursor)
+		run.Fail(err.Error(), cursor.Status == domain.SyncCursorStatusPartiallySynced, time.Now())
+		if !wasSuspended && cursor.Health.SuspendedAt != nil {
+			c.logger.Warn("connection sync suspended", "tenant_id", tenantID, "account_id", account.ID, "consecutive_failures", cursor.Health.ConsecutiveFailures, "error_code", cursor.Health.LastErrorCode)
+		}
 
 		if shouldMarkRequiresReconnect(err) {
-			_ = c.connectionsService.MarkRequiresReconnect(ctx, account.ID, err.Error())
+			_ = c.connectionsService.MarkRequiresReconnect(ctx, account.ID, string(reconnectReason(err)))
 		}
 
 		return err
 	}
 
+	run.Succeed(time.Now())
 	return nil
 }
 
+// recordRun keeps the run in the history; failing to do so does not fail
+// the sync.
+func (c *SyncAccountCommand) recordRun(ctx context.Context, tenantID string, run *domain.SyncRun) {
+	if run.FinishedAt.IsZero() {
+		// The run ended before the sync finished, e.g. on a panic.
+		return
+	}
+
+	if err := c.syncRuns.RecordSyncRun(ctx, run); err != nil {
+		c.logger.Warn("failed to record sync run", "tenant_id", tenantID, "account_id", run.ConnectionID, "error", err)
+	}
+}
+
 func (c *SyncAccountCommand) resolveActiveAccount(ctx context.Context, accountID string) (connectionsApp.ConnectionInfo, error) {
 	if accountID == "" {
 		return connectionsApp.ConnectionInfo{}, errors.New("account id is required")
 	}
 
-	accounts, err := c.connectionsService.GetActiveConnections(ctx)
+	account, found, err := c.connectionsService.FindActiveConnection(ctx, accountID)
 	if err != nil {
-		return connectionsApp.ConnectionInfo{}, fmt.Errorf("list active accounts: %w", err)
+		return connectionsApp.ConnectionInfo{}, fmt.Errorf("find active account: %w", err)
 	}
-
-	for _, account := range accounts {
-		if account.ID == accountID {
-			return account, nil
-		}
+	if !found {
+		return connectionsApp.ConnectionInfo{}, fmt.Errorf("active account not found: %s", accountID)
 	}
 
-	return connectionsApp.ConnectionInfo{}, fmt.Errorf("active account not found: %s", accountID)
+	return account, nil
 }
 
-func (c *SyncAccountCommand) ensureCursor(ctx context.Context, accountID string) (*domain.SyncCursor, error) {
+func (c *SyncAccountCommand) ensureCursor(ctx context.Context, accountID string, force bool) (*domain.SyncCursor, error) {
 	cursor, err := c.cursorRepo.GetSyncCursor(ctx, accountID)
 	if err != nil {
 		return nil, err
 	}
 
+	if cursor != nil && !force && !cursor.Health.CanSyncAt(time.Now()) {
+		return nil, fmt.Errorf("%w until %s", domain.ErrSyncDeferred, cursor.Health.NextAttemptAt.UTC().Format(time.RFC3339))
+	}
+
 	if cursor == nil {
-		initialSyncStart := time.Now().UTC().AddDate(0, 0, -10)
+		initialSyncStart := time.Now().UTC().AddDate(0, 0, -c.initialSyncDays)
 		cursor, err = domain.NewSyncCursor(accountID, &initialSyncStart)
 		if err != nil {
 			return nil, fmt.Errorf("new sync cursor: %w", err)
@@ -160,58 +230,70 @@ func (c *SyncAccountCommand) ensureCursor(ctx context.Context, accountID string)
 	return cursor, nil
 }
 
-func (c *SyncAccountCommand) syncAccount(ctx context.Context, tenantID string, account connectionsApp.ConnectionInfo, cursor *domain.SyncCursor) error {
-	credentialsJSON, err := c.connectionsService.DecryptCredentials(ctx, account.ID)
-	if err != nil {
-		return fmt.Errorf("decrypt account credentials: %w", err)
+func (c *SyncAccountCommand) syncAccount(ctx context.Context, tenantID string, account connectionsApp.ConnectionInfo, cursor *domain.SyncCursor, stats *domain.SyncRunStats) error {
+	tokens := c.connectionsService.TokenSource(ctx, account.ID)
+	// Fetching the token up front fails the sync before any provider call
+	// when the grant can no longer be used.
+	if _, err := tokens.Token(); err != nil {
+		return fmt.Errorf("get account token: %w", err)
 	}
 
-	mailClient, err := c.providerFactory.Build(ctx, account.Provider, credentialsJSON)
+	mailClient, err := c.providerFactory.Build(ctx, account.Provider, tokens)
 	if err != nil {
 		return fmt.Errorf("build provider client: %w", err)
 	}
 
-	query := incrementalQuery(cursor.LastSyncedAt)
-	pageToken := ""
+	// The checkpoint is saved after every page, so a failed sync resumes
+	// where it stopped instead of listing the whole range again.
+	checkpoint := cursor.BeginSync(time.Now())
+	query := joinQuery(incrementalQuery(cursor.LastSyncedAt), untilQuery(checkpoint.Until), ingestionQuery(account.IngestionRules))
+	checkpoint.ListWith(query, time.Now())
 	for {
 		refs, nextPageToken, err := mailClient.ListMessages(ctx, domain.ListMessagesOptions{
 			UserID:     "me",
-			Query:      query,
-			PageToken:  pageToken,
+			Query:      checkpoint.Query,
+			PageToken:  checkpoint.PageToken,
 			MaxResults: 100,
 		})
 		if err != nil {
 			return fmt.Errorf("list provider messages: %w", err)
 		}
+		stats.PagesListed++
 
-		for _, ref := range refs {
-			if err := c.processSingleMessage(ctx, tenantID, account, ref, mailClient); err != nil {
-				if errors.Is(err, errPayloadRejected) {
-					continue
-				}
-
-				return err
-			}
+		pending := checkpoint.Pending(refs)
+		results, err := c.processMessages(ctx, tenantID, account, pending, mailClient)
+		recordResults(stats, results)
+		if err != nil {
+			checkpoint.RecordProcessed(settledIDs(pending, results)...)
+			return err
 		}
 
-		pageToken = nextPageToken
-		if pageToken == "" {
+		if nextPageToken == "" {
 			break
 		}
+
+		checkpoint.NextPage(nextPageToken)
+		if err := c.cursorRepo.UpsertSyncCursor(ctx, cursor); err != nil {
+			return fmt.Errorf("save sync checkpoint: %w", err)
+		}
 	}
 
-	now := time.Now().UTC()
-	cursor.MarkSyncSucceeded(now)
+	if cursor.MarkSyncSucceeded(time.Now()) {
+		c.logger.Info("connection sync resumed", "tenant_id", tenantID, "account_id", account.ID)
+	}
 	return c.cursorRepo.UpsertSyncCursor(ctx, cursor)
 }
 
+// processSingleMessage also reports what it did for the stats of the run,
+// including when it fails.
 func (c *SyncAccountCommand) processSingleMessage(
 	ctx context.Context,
 	tenantID string,
 	account connectionsApp.ConnectionInfo,
+	filter connectionsDomain.IngestionFilter,
 	ref domain.MessageRef,
 	client domain.MailProviderClient,
-) (retErr error) {
+) (outcome messageOutcome, retErr error) {
 	defer func() {
 		if recovered := recover(); recovered != nil {
 			retErr = fmt.Errorf("panic while processing provider message %s: %v: %w", ref.ID, recovered, errPayloadRejected)
@@ -221,21 +303,38 @@ func (c *SyncAccountCommand) processSingleMessage(
 	messageCtx, cancel := context.WithTimeout(ctx, c.perMessageTimeout)
 	defer cancel()
 
+	// Rules are checked on the headers first, so excluded mail is never
+	// downloaded, and again once attachments are known.
+	if checksMetadata(account.IngestionRules) {
+		metadata, err := client.GetMessageMetadata(messageCtx, "me", ref.ID)
+		if err != nil {
+			return outcome, fmt.Errorf("get provider message metadata %s: %w", ref.ID, err)
+		}
+		if reason := filter.ExclusionReason(ingestionCandidate(metadata, false), time.Now()); reason != "" {
+			return outcome, fmt.Errorf("provider message %s: %s: %w", ref.ID, reason, errMessageExcluded)
+		}
+	}
+
 	message, err := client.GetMessage(messageCtx, "me", ref.ID)
 	if err != nil {
-		return fmt.Errorf("get provider message %s: %w", ref.ID, err)
+		return outcome, fmt.Errorf("get provider message %s: %w", ref.ID, err)
 	}
 
 	if err := c.validateMessagePayload(message); err != nil {
-		return fmt.Errorf("validate provider message %s: %w", ref.ID, err)
+		return outcome, fmt.Errorf("validate provider message %s: %w", ref.ID, err)
+	}
+
+	if reason := filter.ExclusionReason(ingestionCandidate(message, true), time.Now()); reason != "" {
+		return outcome, fmt.Errorf("provider message %s: %s: %w", ref.ID, reason, errMessageExcluded)
 	}
 
 	rawData, err := json.Marshal(message)
 	if err != nil {
-		return fmt.Errorf("marshal provider message: %w", err)
+		return outcome, fmt.Errorf("marshal provider message: %w", err)
 	}
+	outcome.bytesDownloaded = int64(len(rawData))
 	if len(rawData) > c.maxRawMessageBytes {
-		return fmt.Errorf("raw provider message size %d exceeds max %d: %w", len(rawData), c.maxRawMessageBytes, errPayloadRejected)
+		return outcome, fmt.Errorf("raw provider message size %d exceeds max %d: %w", len(rawData), c.maxRawMessageBytes, errPayloadRejected)
 	}
 
 	now := time.Now().UTC()
@@ -249,16 +348,19 @@ func (c *SyncAccountCommand) processSingleMessage(
 		UpdatedAt:       now,
 	})
 	if err != nil {
-		return fmt.Errorf("build internal message: %w", err)
+		return outcome, fmt.Errorf("build internal message: %w", err)
 	}
 
-	if _, err := c.messageRepo.UpsertInboxMessage(ctx, inboxMessage); err != nil {
-		return fmt.Errorf("save internal message: %w", err)
+	created, err := c.messageRepo.UpsertInboxMessage(ctx, inboxMessage)
+	if err != nil {
+		return outcome, fmt.Errorf("save internal message: %w", err)
 	}
+	outcome.created = created
 
 	var attachmentRefs []domain.AttachmentRef
 	if len(message.Attachments) > 0 {
-		attachmentRefs, err = c.syncMessageAttachments(
+		var attachmentBytes int64
+		attachmentRefs, attachmentBytes, err = c.syncMessageAttachments(
 			messageCtx,
 			tenantID,
 			account.ID,
@@ -267,16 +369,17 @@ func (c *SyncAccountCommand) processSingleMessage(
 			message.Attachments,
 			client,
 		)
+		outcome.bytesDownloaded += attachmentBytes
 		if err != nil {
-			return err
+			return outcome, err
 		}
 	}
 
 	if err := c.publishInboxMessageReceivedEvent(ctx, tenantID, account, message, inboxMessage, attachmentRefs); err != nil {
-		return fmt.Errorf("publish inbox message received event: %w", err)
+		return outcome, fmt.Errorf("publish inbox message received event: %w", err)
 	}
 
-	return nil
+	return outcome, nil
 }
 
 func (c *SyncAccountCommand) publishInboxMessageReceivedEvent(ctx context.Context, tenantID string, account connectionsApp.ConnectionInfo, mailMessage *domain.MailMessage, inboxMessage *domain.InboxMessage, attachmentRefs []domain.AttachmentRef) error {
@@ -338,16 +441,22 @@ func (c *SyncAccountCommand) syncMessageAttachments(
 	providerMessageID string,
 	attachments []domain.MailAttachmentRef,
 	client domain.MailProviderClient,
-) ([]domain.AttachmentRef, error) {
+) ([]domain.AttachmentRef, int64, error) {
+	downloaded, err := client.DownloadMessageAttachments(ctx, "me", providerMessageID, attachments)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get provider attachments of %s: %w", providerMessageID, err)
+	}
+
 	var refs []domain.AttachmentRef
+	var downloadedBytes int64
+	for _, download := range downloaded {
+		downloadedBytes += int64(len(download.Data))
+	}
 	now := time.Now().UTC()
-	for _, att := range attachments {
-		data, err := client.DownloadAttachment(ctx, "me", providerMessageID, att.AttachmentID)
-		if err != nil {
-			return refs, fmt.Errorf("get provider attachment %s: %w", att.AttachmentID, err)
-		}
+	for _, download := range downloaded {
+		att, data := download.MailAttachmentRef, download.Data
 		if c.maxAttachmentBytes > 0 && int64(len(data)) > c.maxAttachmentBytes {
-			return refs, fmt.Errorf("attachment payload size %d exceeds max %d: %w", len(data), c.maxAttachmentBytes, errPayloadRejected)
+			return refs, downloadedBytes, fmt.Errorf("attachment payload size %d exceeds max %d: %w", len(data), c.maxAttachmentBytes, errPayloadRejected)
 		}
 
 		hash := sha256.Sum256(data)
@@ -371,7 +480,7 @@ func (c *SyncAccountCommand) syncMessageAttachments(
 			},
 		})
 		if err != nil {
-			return refs, fmt.Errorf("store attachment %s: %w", att.AttachmentID, err)
+			return refs, downloadedBytes, fmt.Errorf("store attachment %s: %w", att.AttachmentID, err)
 		}
 
 		sizeBytes := int64(len(data))
@@ -387,11 +496,11 @@ func (c *SyncAccountCommand) syncMessageAttachments(
 			UpdatedAt: now,
 		})
 		if err != nil {
-			return refs, fmt.Errorf("build message attachment %s: %w", att.AttachmentID, err)
+			return refs, downloadedBytes, fmt.Errorf("build message attachment %s: %w", att.AttachmentID, err)
 		}
 
 		if _, err := c.messageRepo.UpsertMessageAttachment(ctx, attachment); err != nil {
-			return refs, fmt.Errorf("save message attachment %s: %w", att.AttachmentID, err)
+			return refs, downloadedBytes, fmt.Errorf("save message attachment %s: %w", att.AttachmentID, err)
 		}
 
 		refs = append(refs, domain.AttachmentRef{
@@ -402,7 +511,7 @@ func (c *SyncAccountCommand) syncMessageAttachments(
 		})
 	}
 
-	return refs, nil
+	return refs, downloadedBytes, nil
 }
 
 func pointerIfNotEmpty(value string) *string {
@@ -447,3 +556,7 @@ func incrementalQuery(lastSyncedAt *time.Time) string {
 
 	return fmt.Sprintf("after:%d", lastSyncedAt.Unix())
 }
+
+func untilQuery(until time.Time) string {
+	return fmt.Sprintf("before:%d", until.Unix())
+}
@@ -8,6 +8,7 @@ import (
 
 	connections "github.com/bowerbird/internal/connections/application"
 	"github.com/bowerbird/internal/connections/domain"
+	inboxDomain "github.com/bowerbird/internal/inbox/domain"
 	"github.com/bowerbird/internal/platform/tenant"
 )
 
@@ -38,15 +39,24 @@ func NewSyncAllAccountsCommand(
 	}
 }
 
-func (c *SyncAllAccountsCommand) Execute(ctx context.Context, requestorUserID string) error {
+// Execute dispatches a sync of every active connection the requestor may
+// sync.
+func (c *SyncAllAccountsCommand) Execute(ctx context.Context, requestor domain.Actor) error {
 	tenantID, err := tenant.TenantIDFromContext(ctx)
 	if err != nil {
 		return err
 	}
 
-	accounts, err := c.connectionsService.GetActiveConnections(ctx)
+	accessible, err := c.connectionsService.ListAccessibleConnections(ctx, requestor, domain.AccessSync)
 	if err != nil {
-		return fmt.Errorf("list active accounts: %w", err)
+		return fmt.Errorf("list syncable accounts: %w", err)
+	}
+
+	accounts := make([]connections.ConnectionInfo, 0, len(accessible))
+	for _, account := range accessible {
+		if account.Status == domain.ConnectionStatusActive {
+			accounts = append(accounts, account)
+		}
 	}
 
 	if len(accounts) == 0 {
@@ -58,16 +68,18 @@ func (c *SyncAllAccountsCommand) Execute(ctx context.Context, requestorUserID st
 
 	var dispatchErr error
 	for _, account := range accounts {
-		if account.SharingPolicy == domain.SharingPolicyPrivate && account.OwnerUserID != requestorUserID {
-			continue
-		}
-
 		err := c.jobDispatcher.DispatchSyncAccount(ctx, SyncAccountJob{
 			TenantID:  tenantID,
 			AccountID: account.ID,
 			Provider:  account.Provider,
 		})
 
+		// Connections backing off or suspended are skipped, not failed.
+		if errors.Is(err, inboxDomain.ErrSyncDeferred) {
+			c.logger.Info("sync deferred for account", "tenant_id", tenantID, "account_id", account.ID, "reason", err)
+			continue
+		}
+
 		if err != nil {
 			dispatchErr = errors.Join(dispatchErr, fmt.Errorf("dispatch account %s: %w", account.ID, err))
 			c.logger.Error("failed to dispatch sync account job", "tenant_slug", tenantID, "account_id", account.ID, "error", err)
@@ -6,8 +6,11 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/inbox/domain"
 	appErrors "github.com/bowerbird/internal/platform/errors"
 )
 
@@ -37,6 +40,15 @@ func classifySyncError(account connectionsApp.ConnectionInfo, err error) error {
 		})
 	}
 
+	var reconnectErr *connectionsDomain.RequiresReconnectError
+	if errors.As(err, &reconnectErr) {
+		return appErrors.WrapSync(err, appErrors.CodeSyncReauthRequired, detail, appErrors.SyncErrorOptions{
+			Provider:       normalizeProviderForMeta(account.Provider),
+			AccountEmail:   accountEmail,
+			RequiresReauth: true,
+		})
+	}
+
 	errText := strings.ToLower(err.Error())
 	statusCode := parseStatusCode(errText)
 	retryAfterSeconds := parseRetryAfterSeconds(errText)
@@ -82,6 +94,16 @@ func shouldMarkRequiresReconnect(err error) bool {
 	return syncErr.Code == appErrors.CodeSyncReauthRequired
 }
 
+// reconnectReason keeps the reason the token manager found; a reauth error
+// seen only in a provider response is recorded as provider_rejected.
+func reconnectReason(err error) connectionsDomain.ReconnectReason {
+	var reconnectErr *connectionsDomain.RequiresReconnectError
+	if errors.As(err, &reconnectErr) && reconnectErr.Reason != "" {
+		return reconnectErr.Reason
+	}
+	return connectionsDomain.ReconnectReasonProviderRejected
+}
+
 func syncErrorCode(err error) string {
 	var syncErr *appErrors.SyncError
 	if errors.As(err, &syncErr) {
@@ -91,6 +113,19 @@ func syncErrorCode(err error) string {
 	return appErrors.CodeSyncInternal
 }
 
+// syncFailure describes a classified sync error for the health model.
+func syncFailure(err error) domain.SyncFailure {
+	failure := domain.SyncFailure{Code: syncErrorCode(err)}
+
+	var syncErr *appErrors.SyncError
+	if errors.As(err, &syncErr) {
+		failure.RetryAfter = time.Duration(syncErr.RetryAfterSeconds) * time.Second
+		failure.RateLimited = syncErr.Code == appErrors.CodeSyncRateLimited
+	}
+
+	return failure
+}
+
 func parseStatusCode(errText string) int {
 	matches := statusCodePattern.FindStringSubmatch(errText)
 	if len(matches) != 2 {
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	connectionsApp "github.com/bowerbird/internal/connections/application"
+	"github.com/bowerbird/internal/inbox/domain"
+)
+
+type WriteBackMessageInput struct {
+	ConnectionID      string
+	ProviderMessageID string
+	// Failed is set when the message looked like an invoice but none could
+	// be extracted.
+	Failed bool
+}
+
+// WriteBackMessageCommand labels a message in the mailbox once invoicing is
+// done with it and, if the connection asks for it, archives it.
+type WriteBackMessageCommand struct {
+	labelCache         domain.LabelCacheRepository
+	connectionsService connectionsApp.InternalService
+	providerFactory    ProviderClientFactory
+	logger             *slog.Logger
+}
+
+func NewWriteBackMessageCommand(
+	labelCache domain.LabelCacheRepository,
+	connectionsService connectionsApp.InternalService,
+	providerFactory ProviderClientFactory,
+) *WriteBackMessageCommand {
+	if labelCache == nil {
+		panic("write back message command: label cache repository is required")
+	}
+
+	if connectionsService == nil {
+		panic("write back message command: connections service is required")
+	}
+
+	if providerFactory == nil {
+		panic("write back message command: provider factory is required")
+	}
+
+	return &WriteBackMessageCommand{
+		labelCache:         labelCache,
+		connectionsService: connectionsService,
+		providerFactory:    providerFactory,
+		logger:             slog.Default(),
+	}
+}
+
+func (c *WriteBackMessageCommand) Execute(ctx context.Context, input WriteBackMessageInput) error {
+	if input.ConnectionID == "" || input.ProviderMessageID == "" {
+		return errors.New("connection id and provider message id are required")
+	}
+
+	account, found, err := c.connectionsService.FindActiveConnection(ctx, input.ConnectionID)
+	if err != nil {
+		return err
+	}
+	// A paused or disconnected mailbox is left alone; there is no token to
+	// write with, and the owner asked for it not to be touched.
+	if !found {
+		c.logger.Info("write back skipped: connection is not active", "account_id", input.ConnectionID, "message_id", input.ProviderMessageID)
+		return nil
+	}
+
+	settings := account.WriteBack.Normalize()
+	if !settings.Enabled {
+		return nil
+	}
+
+	client, err := c.providerFactory.Build(ctx, account.Provider, c.connectionsService.TokenSource(ctx, account.ID))
+	if err != nil {
+		return fmt.Errorf("build provider client: %w", err)
+	}
+
+	if err := c.addLabel(ctx, client, account.ID, input.ProviderMessageID, settings.LabelFor(input.Failed)); err != nil {
+		return err
+	}
+
+	// Failed messages stay in the inbox so someone looks at them.
+	if settings.Archive && !input.Failed {
+		if err := client.ArchiveMessage(ctx, "me", input.ProviderMessageID); err != nil {
+			return fmt.Errorf("archive message %s: %w", input.ProviderMessageID, err)
+		}
+	}
+
+	return nil
+}
+
+// addLabel applies the label, resolving its ID through the cache. A cached ID
+// may be stale if the user deleted the label, so a failure with a cached ID
+// drops it and tries once more with a fresh one.
+func (c *WriteBackMessageCommand) addLabel(ctx context.Context, client domain.MailProviderClient, connectionID, messageID, labelName string) error {
+	labelID, err := c.labelCache.GetLabelID(ctx, connectionID, labelName)
+	if err != nil {
+		return err
+	}
+
+	if labelID != "" {
+		err := client.AddLabelToMessage(ctx, "me", messageID, labelID)
+		if err == nil {
+			return nil
+		}
+		c.logger.Warn("cached label rejected, resolving it again", "account_id", connectionID, "label", labelName, "error", err)
+		if err := c.labelCache.DeleteLabelID(ctx, connectionID, labelName); err != nil {
+			return err
+		}
+	}
+
+	labelID, err = client.CreateLabel(ctx, "me", labelName)
+	if err != nil {
+		return fmt.Errorf("create label %q: %w", labelName, err)
+	}
+	if err := c.labelCache.SaveLabelID(ctx, connectionID, labelName, labelID); err != nil {
+		return err
+	}
+
+	if err := client.AddLabelToMessage(ctx, "me", messageID, labelID); err != nil {
+		return fmt.Errorf("label message %s: %w", messageID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+	inboxCommands "github.com/bowerbird/internal/inbox/application/commands"
+	"github.com/bowerbird/internal/inbox/domain"
+	"github.com/bowerbird/internal/platform/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartHistoricalImportCommand_RequiresConfigureAccess(t *testing.T) {
+	imports := newFakeHistoricalImportRepo()
+	scheduler := &fakeHistoricalImportScheduler{}
+	connectionsSvc := &fakeConnectionsInternalService{
+		accessibleConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail"}},
+	}
+
+	cmd := inboxCommands.NewStartHistoricalImportCommand(imports, connectionsSvc, scheduler)
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+	since := time.Now().UTC().AddDate(0, -2, 0)
+
+	_, err := cmd.Execute(ctx, inboxCommands.StartHistoricalImportInput{ConnectionID: "acc-2", Actor: &connectionsDomain.Actor{UserID: "user-1"}, Since: since})
+	require.ErrorIs(t, err, connectionsDomain.ErrConnectionNotFound)
+
+	historicalImport, err := cmd.Execute(ctx, inboxCommands.StartHistoricalImportInput{ConnectionID: "acc-1", Actor: &connectionsDomain.Actor{UserID: "user-1"}, Since: since})
+	require.NoError(t, err)
+
+	assert.Equal(t, connectionsDomain.AccessConfigure, connectionsSvc.requestedAccess)
+	assert.Equal(t, "user-1", historicalImport.RequestedBy)
+	assert.Equal(t, []scheduledChunk{{importID: historicalImport.ID}}, scheduler.scheduled)
+}
+
+func TestRunHistoricalImportChunkCommand_ImportsWindowsAndCompletes(t *testing.T) {
+	now := time.Now().UTC()
+	imports := newFakeHistoricalImportRepo()
+	historicalImport, err := domain.NewHistoricalImport("imp-1", "acc-1", "", now.AddDate(0, 0, -10), now, now)
+	require.NoError(t, err)
+	imports.imports["imp-1"] = historicalImport
+
+	repo := newFakeInboxRepo()
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail", ProviderAccountEmail: "user@gmail.com"}},
+	}
+	providerClient := &fakeProviderClient{
+		refs: []domain.MessageRef{{ID: "m-1"}},
+		messages: map[string]*domain.MailMessage{
+			"m-1": {ID: "m-1", ThreadID: "t-1", Subject: "Factura", Sender: "Sender <sender@example.com>", PlainTextBody: "adjunta"},
+		},
+	}
+	providerFactory := &fakeProviderFactory{client: providerClient}
+	scheduler := &fakeHistoricalImportScheduler{}
+
+	sync := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, providerFactory, &fakeInboxEventPublisher{}, &fakeFileStore{})
+	cmd := inboxCommands.NewRunHistoricalImportChunkCommand(imports, repo, connectionsSvc, providerFactory, sync, scheduler)
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	require.NoError(t, cmd.Execute(ctx, "imp-1"))
+
+	firstEnd := historicalImport.Since.Add(domain.HistoricalImportWindow)
+	assert.Equal(t, []string{
+		fmt.Sprintf("after:%d before:%d", historicalImport.Since.Unix(), firstEnd.Unix()),
+		fmt.Sprintf("after:%d before:%d", firstEnd.Unix(), historicalImport.Until.Unix()),
+	}, providerClient.listQueries)
+
+	saved := imports.imports["imp-1"]
+	assert.Equal(t, domain.HistoricalImportStatusCompleted, saved.Status)
+	assert.Equal(t, domain.HistoricalImportProgress{WindowsTotal: 2, WindowsDone: 2, MessagesListed: 2, MessagesImported: 2}, saved.Progress)
+	assert.Nil(t, saved.LeaseUntil)
+	assert.Empty(t, scheduler.scheduled)
+	assert.Len(t, repo.upsertedMessages, 2)
+
+	// A redelivered job finds the import finished.
+	require.NoError(t, cmd.Execute(ctx, "imp-1"))
+	assert.Len(t, providerClient.listQueries, 2)
+}
+
+func TestRunHistoricalImportChunkCommand_WaitsOutRateLimits(t *testing.T) {
+	now := time.Now().UTC()
+	imports := newFakeHistoricalImportRepo()
+	historicalImport, err := domain.NewHistoricalImport("imp-1", "acc-1", "", now.AddDate(0, 0, -30), now, now)
+	require.NoError(t, err)
+	imports.imports["imp-1"] = historicalImport
+
+	repo := newFakeInboxRepo()
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail", ProviderAccountEmail: "user@gmail.com"}},
+	}
+	providerClient := &fakeProviderClient{listErr: errors.New("googleapi: got HTTP response code 429 with body: rate limit exceeded")}
+	providerFactory := &fakeProviderFactory{client: providerClient}
+	scheduler := &fakeHistoricalImportScheduler{}
+
+	sync := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, providerFactory, &fakeInboxEventPublisher{}, &fakeFileStore{})
+	cmd := inboxCommands.NewRunHistoricalImportChunkCommand(imports, repo, connectionsSvc, providerFactory, sync, scheduler)
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	require.NoError(t, cmd.Execute(ctx, "imp-1"))
+
+	saved := imports.imports["imp-1"]
+	assert.Equal(t, domain.HistoricalImportStatusRunning, saved.Status)
+	assert.Zero(t, saved.ConsecutiveFailures)
+	require.NotNil(t, saved.RateLimitedUntil)
+	assert.Nil(t, saved.LeaseUntil)
+	require.Len(t, scheduler.scheduled, 1)
+	assert.InDelta(t, (2 * time.Minute).Seconds(), scheduler.scheduled[0].delay.Seconds(), 5)
+
+	// Woken early, the chunk waits again without calling the provider.
+	require.NoError(t, cmd.Execute(ctx, "imp-1"))
+	assert.Len(t, providerClient.listQueries, 1)
+	require.Len(t, scheduler.scheduled, 2)
+	assert.Positive(t, scheduler.scheduled[1].delay)
+}
+
+type fakeHistoricalImportRepo struct {
+	imports map[string]*domain.HistoricalImport
+}
+
+func newFakeHistoricalImportRepo() *fakeHistoricalImportRepo {
+	return &fakeHistoricalImportRepo{imports: map[string]*domain.HistoricalImport{}}
+}
+
+func (f *fakeHistoricalImportRepo) CreateHistoricalImport(ctx context.Context, historicalImport *domain.HistoricalImport) error {
+	for _, existing := range f.imports {
+		if existing.ConnectionID == historicalImport.ConnectionID && existing.InProgress() {
+			return domain.ErrHistoricalImportInProgress
+		}
+	}
+	cloned := *historicalImport
+	f.imports[historicalImport.ID] = &cloned
+	return nil
+}
+
+func (f *fakeHistoricalImportRepo) GetHistoricalImport(ctx context.Context, importID string) (*domain.HistoricalImport, error) {
+	return f.imports[importID], nil
+}
+
+func (f *fakeHistoricalImportRepo) ListHistoricalImports(ctx context.Context, connectionIDs []string) ([]*domain.HistoricalImport, error) {
+	var imports []*domain.HistoricalImport
+	for _, historicalImport := range f.imports {
+		imports = append(imports, historicalImport)
+	}
+	return imports, nil
+}
+
+func (f *fakeHistoricalImportRepo) ClaimHistoricalImport(ctx context.Context, importID string, now, leaseUntil time.Time) (*domain.HistoricalImport, error) {
+	historicalImport, ok := f.imports[importID]
+	if !ok || !historicalImport.InProgress() || (historicalImport.LeaseUntil != nil && !historicalImport.LeaseUntil.Before(now)) {
+		return nil, nil
+	}
+	historicalImport.LeaseUntil = &leaseUntil
+	cloned := *historicalImport
+	return &cloned, nil
+}
+
+func (f *fakeHistoricalImportRepo) SaveHistoricalImport(ctx context.Context, historicalImport *domain.HistoricalImport) error {
+	cloned := *historicalImport
+	f.imports[historicalImport.ID] = &cloned
+	return nil
+}
+
+type scheduledChunk struct {
+	importID string
+	delay    time.Duration
+}
+
+type fakeHistoricalImportScheduler struct {
+	scheduled []scheduledChunk
+}
+
+func (f *fakeHistoricalImportScheduler) ScheduleHistoricalImportChunk(ctx context.Context, importID string, delay time.Duration) error {
+	f.scheduled = append(f.scheduled, scheduledChunk{importID: importID, delay: delay})
+	return nil
+}
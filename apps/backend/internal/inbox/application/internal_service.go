@@ -0,0 +1,14 @@
+package application
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/inbox/application/commands"
+)
+
+type MessagePurgeResult = commands.PurgeConnectionMessagesResult
+
+// InternalService is what other modules may do with the inbox.
+type InternalService interface {
+	PurgeConnectionMessages(ctx context.Context, connectionID string) (MessagePurgeResult, error)
+}
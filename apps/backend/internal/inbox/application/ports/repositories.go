@@ -35,7 +35,9 @@ type MessageDetailView struct {
 	HasPDF           bool
 }
 
+// MessageQueryRepository only returns messages of the given accounts, the
+// ones the caller may read.
 type MessageQueryRepository interface {
-	ListMessageViews(ctx context.Context) ([]MessageListView, error)
-	GetMessageViewByID(ctx context.Context, messageID string) (*MessageDetailView, error)
+	ListMessageViews(ctx context.Context, accountIDs []string) ([]MessageListView, error)
+	GetMessageViewByID(ctx context.Context, messageID string, accountIDs []string) (*MessageDetailView, error)
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 
+	connections "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
 	"github.com/bowerbird/internal/inbox/application/ports"
 	"github.com/bowerbird/internal/inbox/domain"
 )
@@ -26,15 +28,23 @@ type MessageDetail struct {
 }
 
 type GetMessageQuery struct {
-	repo ports.MessageQueryRepository
+	repo               ports.MessageQueryRepository
+	connectionsService connections.InternalService
 }
 
-func NewGetMessageQuery(repo ports.MessageQueryRepository) *GetMessageQuery {
-	return &GetMessageQuery{repo: repo}
+func NewGetMessageQuery(repo ports.MessageQueryRepository, connectionsService connections.InternalService) *GetMessageQuery {
+	return &GetMessageQuery{repo: repo, connectionsService: connectionsService}
 }
 
-func (q *GetMessageQuery) Execute(ctx context.Context, messageID string) (*MessageDetail, error) {
-	msg, err := q.repo.GetMessageViewByID(ctx, messageID)
+// Execute reports a message of a connection the actor may not read as not
+// found.
+func (q *GetMessageQuery) Execute(ctx context.Context, actor connectionsDomain.Actor, messageID string) (*MessageDetail, error) {
+	accountIDs, err := readableAccountIDs(ctx, q.connectionsService, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := q.repo.GetMessageViewByID(ctx, messageID, accountIDs)
 	if err != nil {
 		return nil, err
 	}
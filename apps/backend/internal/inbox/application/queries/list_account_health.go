@@ -2,17 +2,32 @@ package queries
 
 import (
 	"context"
+	"time"
 
 	"github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
 	"github.com/bowerbird/internal/inbox/domain"
 )
 
 type AccountSyncStatus struct {
-	ID           string  `json:"id"`
-	Provider     string  `json:"provider"`
-	EmailAddress string  `json:"email_address"`
-	Status       string  `json:"status"` // the sync status
-	LastSyncedAt *string `json:"last_synced_at,omitempty"`
+	ID           string        `json:"id"`
+	Provider     string        `json:"provider"`
+	EmailAddress string        `json:"email_address"`
+	Status       string        `json:"status"` // the sync status
+	LastSyncedAt *string       `json:"last_synced_at,omitempty"`
+	Health       AccountHealth `json:"health"`
+}
+
+type AccountHealth struct {
+	Status              string         `json:"status"`
+	ConsecutiveFailures int            `json:"consecutive_failures"`
+	LastSucceededAt     *string        `json:"last_succeeded_at,omitempty"`
+	LastFailedAt        *string        `json:"last_failed_at,omitempty"`
+	LastErrorCode       string         `json:"last_error_code,omitempty"`
+	ErrorCounts         map[string]int `json:"error_counts"`
+	NextAttemptAt       *string        `json:"next_attempt_at,omitempty"`
+	RateLimitedUntil    *string        `json:"rate_limited_until,omitempty"`
+	SuspendedAt         *string        `json:"suspended_at,omitempty"`
 }
 
 type ListAccountHealthQuery struct {
@@ -24,14 +39,20 @@ func NewListAccountHealthQuery(repo domain.SyncCursorRepository, connectionsServ
 	return &ListAccountHealthQuery{repo: repo, connectionsService: connectionsService}
 }
 
-func (q *ListAccountHealthQuery) Execute(ctx context.Context) ([]AccountSyncStatus, error) {
-	connections, err := q.connectionsService.GetActiveConnections(ctx)
+// Execute reports the active connections the actor may see.
+func (q *ListAccountHealthQuery) Execute(ctx context.Context, actor connectionsDomain.Actor) ([]AccountSyncStatus, error) {
+	connections, err := q.connectionsService.ListAccessibleConnections(ctx, actor, connectionsDomain.AccessView)
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
 	summaries := make([]AccountSyncStatus, 0, len(connections))
 	for _, conn := range connections {
+		if conn.Status != connectionsDomain.ConnectionStatusActive {
+			continue
+		}
+
 		cursor, err := q.repo.GetSyncCursor(ctx, conn.ID)
 		if err != nil {
 			return nil, err
@@ -39,13 +60,12 @@ func (q *ListAccountHealthQuery) Execute(ctx context.Context) ([]AccountSyncStat
 
 		var lastSyncedAt *string
 		status := domain.SyncCursorStatusIdle.String()
+		var health domain.ConnectionHealth
 
 		if cursor != nil {
 			status = cursor.Status.String()
-			if cursor.LastSyncedAt != nil {
-				t := cursor.LastSyncedAt.Format("2006-01-02T15:04:05Z07:00")
-				lastSyncedAt = &t
-			}
+			lastSyncedAt = formatTime(cursor.LastSyncedAt)
+			health = cursor.Health
 		}
 
 		summaries = append(summaries, AccountSyncStatus{
@@ -54,8 +74,36 @@ func (q *ListAccountHealthQuery) Execute(ctx context.Context) ([]AccountSyncStat
 			EmailAddress: conn.ProviderAccountEmail,
 			Status:       status,
 			LastSyncedAt: lastSyncedAt,
+			Health:       toAccountHealth(health, now),
 		})
 	}
 
 	return summaries, nil
 }
+
+func toAccountHealth(health domain.ConnectionHealth, now time.Time) AccountHealth {
+	errorCounts := health.ErrorCounts
+	if errorCounts == nil {
+		errorCounts = map[string]int{}
+	}
+
+	return AccountHealth{
+		Status:              string(health.Status(now)),
+		ConsecutiveFailures: health.ConsecutiveFailures,
+		LastSucceededAt:     formatTime(health.LastSucceededAt),
+		LastFailedAt:        formatTime(health.LastFailedAt),
+		LastErrorCode:       health.LastErrorCode,
+		ErrorCounts:         errorCounts,
+		NextAttemptAt:       formatTime(health.NextAttemptAt),
+		RateLimitedUntil:    formatTime(health.RateLimitedUntil),
+		SuspendedAt:         formatTime(health.SuspendedAt),
+	}
+}
+
+func formatTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
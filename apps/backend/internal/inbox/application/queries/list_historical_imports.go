@@ -0,0 +1,75 @@
+package queries
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/inbox/domain"
+)
+
+type HistoricalImportView struct {
+	ID            string                          `json:"id"`
+	ConnectionID  string                          `json:"connection_id"`
+	Since         string                          `json:"since"`
+	Until         string                          `json:"until"`
+	Status        string                          `json:"status"`
+	Progress      domain.HistoricalImportProgress `json:"progress"`
+	Error         string                          `json:"error,omitempty"`
+	NextAttemptAt *string                         `json:"next_attempt_at,omitempty"`
+	CreatedAt     string                          `json:"created_at"`
+	StartedAt     *string                         `json:"started_at,omitempty"`
+	CompletedAt   *string                         `json:"completed_at,omitempty"`
+}
+
+type ListHistoricalImportsQuery struct {
+	repo               domain.HistoricalImportRepository
+	connectionsService application.InternalService
+}
+
+func NewListHistoricalImportsQuery(repo domain.HistoricalImportRepository, connectionsService application.InternalService) *ListHistoricalImportsQuery {
+	return &ListHistoricalImportsQuery{repo: repo, connectionsService: connectionsService}
+}
+
+// Execute lists the imports of a connection, newest first. A connection the
+// actor may not see is reported as not found.
+func (q *ListHistoricalImportsQuery) Execute(ctx context.Context, actor connectionsDomain.Actor, connectionID string) ([]HistoricalImportView, error) {
+	visible, err := q.connectionsService.ListAccessibleConnections(ctx, actor, connectionsDomain.AccessView)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(visible, func(conn application.ConnectionInfo) bool { return conn.ID == connectionID }) {
+		return nil, connectionsDomain.ErrConnectionNotFound
+	}
+
+	imports, err := q.repo.ListHistoricalImports(ctx, []string{connectionID})
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]HistoricalImportView, 0, len(imports))
+	for _, historicalImport := range imports {
+		views = append(views, ToHistoricalImportView(historicalImport))
+	}
+
+	return views, nil
+}
+
+// ToHistoricalImportView renders an import for the API.
+func ToHistoricalImportView(historicalImport *domain.HistoricalImport) HistoricalImportView {
+	return HistoricalImportView{
+		ID:            historicalImport.ID,
+		ConnectionID:  historicalImport.ConnectionID,
+		Since:         historicalImport.Since.Format(time.RFC3339),
+		Until:         historicalImport.Until.Format(time.RFC3339),
+		Status:        historicalImport.Status,
+		Progress:      historicalImport.Progress,
+		Error:         historicalImport.Error,
+		NextAttemptAt: formatTime(historicalImport.NextAttemptAt),
+		CreatedAt:     historicalImport.CreatedAt.Format(time.RFC3339),
+		StartedAt:     formatTime(historicalImport.StartedAt),
+		CompletedAt:   formatTime(historicalImport.CompletedAt),
+	}
+}
@@ -2,7 +2,10 @@ package queries
 
 import (
 	"context"
+	"fmt"
 
+	connections "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
 	"github.com/bowerbird/internal/inbox/application/ports"
 )
 
@@ -21,15 +24,21 @@ type MessageSummary struct {
 }
 
 type ListMessagesQuery struct {
-	repo ports.MessageQueryRepository
+	repo               ports.MessageQueryRepository
+	connectionsService connections.InternalService
 }
 
-func NewListMessagesQuery(repo ports.MessageQueryRepository) *ListMessagesQuery {
-	return &ListMessagesQuery{repo: repo}
+func NewListMessagesQuery(repo ports.MessageQueryRepository, connectionsService connections.InternalService) *ListMessagesQuery {
+	return &ListMessagesQuery{repo: repo, connectionsService: connectionsService}
 }
 
-func (q *ListMessagesQuery) Execute(ctx context.Context) ([]MessageSummary, error) {
-	messages, err := q.repo.ListMessageViews(ctx)
+func (q *ListMessagesQuery) Execute(ctx context.Context, actor connectionsDomain.Actor) ([]MessageSummary, error) {
+	accountIDs, err := readableAccountIDs(ctx, q.connectionsService, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := q.repo.ListMessageViews(ctx, accountIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -53,3 +62,17 @@ func (q *ListMessagesQuery) Execute(ctx context.Context) ([]MessageSummary, erro
 
 	return summaries, nil
 }
+
+// readableAccountIDs lists the connections whose messages the actor may read.
+func readableAccountIDs(ctx context.Context, connectionsService connections.InternalService, actor connectionsDomain.Actor) ([]string, error) {
+	accessible, err := connectionsService.ListAccessibleConnections(ctx, actor, connectionsDomain.AccessReadMessages)
+	if err != nil {
+		return nil, fmt.Errorf("list readable connections: %w", err)
+	}
+
+	ids := make([]string, 0, len(accessible))
+	for _, conn := range accessible {
+		ids = append(ids, conn.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,67 @@
+package queries
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+	"github.com/bowerbird/internal/inbox/domain"
+)
+
+// syncRunsLimit is how many of the latest runs are listed.
+const syncRunsLimit = 50
+
+type SyncRunView struct {
+	ID           string              `json:"id"`
+	ConnectionID string              `json:"connection_id"`
+	Status       string              `json:"status"`
+	Resumed      bool                `json:"resumed"`
+	Stats        domain.SyncRunStats `json:"stats"`
+	Error        string              `json:"error,omitempty"`
+	StartedAt    string              `json:"started_at"`
+	FinishedAt   string              `json:"finished_at"`
+}
+
+type ListSyncRunsQuery struct {
+	repo               domain.SyncRunRepository
+	connectionsService application.InternalService
+}
+
+func NewListSyncRunsQuery(repo domain.SyncRunRepository, connectionsService application.InternalService) *ListSyncRunsQuery {
+	return &ListSyncRunsQuery{repo: repo, connectionsService: connectionsService}
+}
+
+// Execute lists the latest sync runs of a connection, newest first. A
+// connection the actor may not see is reported as not found.
+func (q *ListSyncRunsQuery) Execute(ctx context.Context, actor connectionsDomain.Actor, connectionID string) ([]SyncRunView, error) {
+	visible, err := q.connectionsService.ListAccessibleConnections(ctx, actor, connectionsDomain.AccessView)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(visible, func(conn application.ConnectionInfo) bool { return conn.ID == connectionID }) {
+		return nil, connectionsDomain.ErrConnectionNotFound
+	}
+
+	runs, err := q.repo.ListSyncRuns(ctx, connectionID, syncRunsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]SyncRunView, 0, len(runs))
+	for _, run := range runs {
+		views = append(views, SyncRunView{
+			ID:           run.ID,
+			ConnectionID: run.ConnectionID,
+			Status:       run.Status,
+			Resumed:      run.Resumed,
+			Stats:        run.Stats,
+			Error:        run.Error,
+			StartedAt:    run.StartedAt.Format(time.RFC3339),
+			FinishedAt:   run.FinishedAt.Format(time.RFC3339),
+		})
+	}
+
+	return views, nil
+}
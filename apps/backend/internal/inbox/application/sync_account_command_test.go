@@ -3,12 +3,15 @@ package application_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
 	inboxCommands "github.com/bowerbird/internal/inbox/application/commands"
 	"github.com/bowerbird/internal/inbox/domain"
 	platformEvents "github.com/bowerbird/internal/platform/events"
@@ -16,6 +19,7 @@ import (
 	"github.com/bowerbird/internal/platform/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func TestSyncAccountCommand_RequiresAccountID(t *testing.T) {
@@ -25,7 +29,7 @@ func TestSyncAccountCommand_RequiresAccountID(t *testing.T) {
 	publisher := &fakeInboxEventPublisher{}
 	attachmentStore := &fakeFileStore{}
 
-	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
 
 	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{})
@@ -42,7 +46,7 @@ func TestSyncAccountCommand_FailsWhenAccountIsNotActive(t *testing.T) {
 	publisher := &fakeInboxEventPublisher{}
 	attachmentStore := &fakeFileStore{}
 
-	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
 
 	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
@@ -60,26 +64,40 @@ func TestSyncAccountCommand_CreatesCursorForLastTenDaysWhenMissing(t *testing.T)
 	publisher := &fakeInboxEventPublisher{}
 	attachmentStore := &fakeFileStore{}
 
-	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
 
 	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
 	require.NoError(t, err)
 	require.Len(t, providerClient.listQueries, 1)
 
-	query := providerClient.listQueries[0]
-	require.True(t, strings.HasPrefix(query, "after:"))
-	queryTs, convErr := strconv.ParseInt(strings.TrimPrefix(query, "after:"), 10, 64)
-	require.NoError(t, convErr)
-
-	expected := time.Now().UTC().AddDate(0, 0, -10)
-	assert.WithinDuration(t, expected, time.Unix(queryTs, 0).UTC(), 5*time.Second)
+	after, before := parseSyncQuery(t, providerClient.listQueries[0])
+	assert.WithinDuration(t, time.Now().UTC().AddDate(0, 0, -10), after, 5*time.Second)
+	assert.WithinDuration(t, time.Now().UTC(), before, 5*time.Second)
 
 	require.Len(t, repo.upsertedCursors, 2)
 	assert.Equal(t, domain.SyncCursorStatusSyncing, repo.upsertedCursors[0].Status)
 	assert.Equal(t, domain.SyncCursorStatusIdle, repo.upsertedCursors[1].Status)
 }
 
+func TestSyncAccountCommand_InitialSyncWindowIsConfigurable(t *testing.T) {
+	repo := newFakeInboxRepo()
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail", ProviderAccountEmail: "user@gmail.com"}},
+	}
+	providerClient := &fakeProviderClient{}
+
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, &fakeInboxEventPublisher{}, &fakeFileStore{}).
+		WithInitialSyncDays(30)
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	require.NoError(t, cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"}))
+	require.Len(t, providerClient.listQueries, 1)
+
+	after, _ := parseSyncQuery(t, providerClient.listQueries[0])
+	assert.WithinDuration(t, time.Now().UTC().AddDate(0, 0, -30), after, 5*time.Second)
+}
+
 func TestSyncAccountCommand_UsesExistingCursorWithoutResettingRange(t *testing.T) {
 	previousSync := time.Date(2026, 5, 2, 8, 30, 0, 0, time.UTC)
 	repo := newFakeInboxRepo()
@@ -95,15 +113,15 @@ func TestSyncAccountCommand_UsesExistingCursorWithoutResettingRange(t *testing.T
 	providerClient := &fakeProviderClient{}
 	publisher := &fakeInboxEventPublisher{}
 	attachmentStore := &fakeFileStore{}
-	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
 
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
 	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
 	require.NoError(t, err)
 
-	expectedQuery := "after:" + toUnixString(previousSync)
+	expectedQuery := "after:" + toUnixString(previousSync) + " before:"
 	require.NotEmpty(t, providerClient.listQueries)
-	assert.Equal(t, expectedQuery, providerClient.listQueries[0])
+	assert.True(t, strings.HasPrefix(providerClient.listQueries[0], expectedQuery), providerClient.listQueries[0])
 }
 
 func TestSyncAccountCommand_ContinuesAfterPayloadRejected(t *testing.T) {
@@ -131,17 +149,23 @@ func TestSyncAccountCommand_ContinuesAfterPayloadRejected(t *testing.T) {
 	publisher := &fakeInboxEventPublisher{}
 	attachmentStore := &fakeFileStore{}
 
-	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
 
 	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
 	require.NoError(t, err)
 	require.Len(t, repo.upsertedMessages, 1)
-	assert.Equal(t, []string{"m-invalid", "m-valid"}, providerClient.getMessageCalls)
+	assert.ElementsMatch(t, []string{"m-invalid", "m-valid"}, providerClient.getMessageCalls)
 
 	persisted := repo.upsertedMessages[0]
 	require.NotNil(t, persisted.SenderEmail)
 	assert.Equal(t, "sender@example.com", *persisted.SenderEmail)
+
+	require.Len(t, repo.syncRuns, 1)
+	assert.Equal(t, domain.SyncRunStatusSucceeded, repo.syncRuns[0].Status)
+	assert.Equal(t, 2, repo.syncRuns[0].Stats.MessagesSeen)
+	assert.Equal(t, 1, repo.syncRuns[0].Stats.MessagesNew)
+	assert.Equal(t, 1, repo.syncRuns[0].Stats.MessagesRejected)
 }
 
 func TestSyncAccountCommand_UsesProviderMessageIDForAttachmentDownload(t *testing.T) {
@@ -167,7 +191,7 @@ func TestSyncAccountCommand_UsesProviderMessageIDForAttachmentDownload(t *testin
 	publisher := &fakeInboxEventPublisher{}
 	attachmentStore := &fakeFileStore{}
 
-	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
 
 	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
@@ -203,12 +227,12 @@ func TestSyncAccountCommand_FailsWhenAttachmentDownloadFails(t *testing.T) {
 	publisher := &fakeInboxEventPublisher{}
 	attachmentStore := &fakeFileStore{}
 
-	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
 
 	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
 	require.Error(t, err)
-	assert.ErrorContains(t, err, "get provider attachment att-1")
+	assert.ErrorContains(t, err, "get provider attachments of provider-msg-1")
 }
 
 func TestSyncAccountCommand_ReauthMarksReconnect(t *testing.T) {
@@ -220,27 +244,289 @@ func TestSyncAccountCommand_ReauthMarksReconnect(t *testing.T) {
 	publisher := &fakeInboxEventPublisher{}
 	attachmentStore := &fakeFileStore{}
 
-	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, publisher, attachmentStore)
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
 
 	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
 	require.Error(t, err)
 	assert.Equal(t, 1, connectionsSvc.markReconnectCalls)
+	assert.Equal(t, string(connectionsDomain.ReconnectReasonProviderRejected), connectionsSvc.markReconnectReason)
 
 	cursor := repo.cursors["acc-1"]
 	require.NotNil(t, cursor)
 	assert.Equal(t, domain.SyncCursorStatusError, cursor.Status)
 }
 
+func TestSyncAccountCommand_UnusableGrantKeepsTokenManagerReason(t *testing.T) {
+	repo := newFakeInboxRepo()
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail", ProviderAccountEmail: "user@gmail.com"}},
+		tokenErr:          &connectionsDomain.RequiresReconnectError{Reason: connectionsDomain.ReconnectReasonScopeDowngraded},
+	}
+	providerClient := &fakeProviderClient{}
+
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, &fakeInboxEventPublisher{}, &fakeFileStore{})
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
+	require.Error(t, err)
+	assert.Empty(t, providerClient.listQueries)
+	assert.Equal(t, string(connectionsDomain.ReconnectReasonScopeDowngraded), connectionsSvc.markReconnectReason)
+	assert.Equal(t, domain.SyncCursorStatusError, repo.cursors["acc-1"].Status)
+}
+
+func TestSyncAccountCommand_RecordsFailureAndDefersUntilBackOffElapses(t *testing.T) {
+	repo := newFakeInboxRepo()
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail", ProviderAccountEmail: "user@gmail.com"}},
+	}
+	providerClient := &fakeProviderClient{listErr: errors.New("list failed with status 429 retry-after=300")}
+
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, &fakeInboxEventPublisher{}, &fakeFileStore{})
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
+	require.Error(t, err)
+
+	health := repo.cursors["acc-1"].Health
+	assert.Equal(t, domain.HealthStatusRateLimited, health.Status(time.Now()))
+	assert.Equal(t, 1, health.ErrorCounts["ERR_SYNC_RATE_LIMITED"])
+	require.NotNil(t, health.NextAttemptAt)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), *health.NextAttemptAt, 5*time.Second)
+
+	err = cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
+	require.ErrorIs(t, err, domain.ErrSyncDeferred)
+	assert.Len(t, providerClient.listQueries, 1)
+
+	providerClient.listErr = nil
+	err = cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1", Force: true})
+	require.NoError(t, err)
+	assert.Equal(t, domain.HealthStatusHealthy, repo.cursors["acc-1"].Health.Status(time.Now()))
+}
+
+func TestSyncAccountCommand_AppliesIngestionRulesBeforeDownloading(t *testing.T) {
+	repo := newFakeInboxRepo()
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{
+			ID:                   "acc-1",
+			Provider:             "gmail",
+			ProviderAccountEmail: "user@gmail.com",
+			IngestionRules: connectionsDomain.IngestionRules{
+				AllowedSenders:     []string{"acme.com"},
+				SubjectPatterns:    []string{"factura"},
+				RequireAttachments: true,
+			},
+		}},
+	}
+	attachment := []domain.MailAttachmentRef{{AttachmentID: "att-1", Filename: "factura.xml", MimeType: "application/xml", Size: 10}}
+	providerClient := &fakeProviderClient{
+		refs: []domain.MessageRef{{ID: "personal"}, {ID: "no-attachment"}, {ID: "invoice"}},
+		messages: map[string]*domain.MailMessage{
+			"personal":      {ID: "personal", ThreadID: "t1", Subject: "Factura del gimnasio", Sender: "Friend <friend@gmail.com>", PlainTextBody: "hola"},
+			"no-attachment": {ID: "no-attachment", ThreadID: "t2", Subject: "Tu factura", Sender: "billing@acme.com", PlainTextBody: "hola"},
+			"invoice":       {ID: "invoice", ThreadID: "t3", Subject: "FACTURA 123", Sender: "Acme <billing@mail.acme.com>", PlainTextBody: "hola", Attachments: attachment},
+		},
+	}
+
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, &fakeInboxEventPublisher{}, &fakeFileStore{})
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
+	require.NoError(t, err)
+
+	require.Len(t, providerClient.listQueries, 1)
+	assert.Contains(t, providerClient.listQueries[0], "from:acme.com has:attachment")
+	assert.ElementsMatch(t, []string{"personal", "no-attachment", "invoice"}, providerClient.getMetadataCalls)
+	assert.ElementsMatch(t, []string{"no-attachment", "invoice"}, providerClient.getMessageCalls, "excluded senders are never downloaded")
+	require.Len(t, repo.upsertedMessages, 1)
+	assert.Len(t, providerClient.downloadAttachmentCalls, 1)
+}
+
+func TestSyncAccountCommand_ResumesFromCheckpointAfterFailure(t *testing.T) {
+	repo := newFakeInboxRepo()
+	previousSync := time.Now().UTC().Add(-48 * time.Hour).Truncate(time.Second)
+	repo.cursors["acc-1"] = &domain.SyncCursor{ConnectionID: "acc-1", LastSyncedAt: &previousSync, Status: domain.SyncCursorStatusIdle}
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail", ProviderAccountEmail: "user@gmail.com"}},
+	}
+	message := func(id string) *domain.MailMessage {
+		return &domain.MailMessage{ID: id, ThreadID: "t-" + id, Subject: id, Sender: "a@example.com", PlainTextBody: "hola"}
+	}
+	providerClient := &fakeProviderClient{
+		// m-broken cannot be fetched until the provider recovers.
+		pages: [][]domain.MessageRef{{{ID: "m-4"}, {ID: "m-3"}}, {{ID: "m-2"}, {ID: "m-broken"}}},
+		messages: map[string]*domain.MailMessage{
+			"m-4": message("m-4"), "m-3": message("m-3"), "m-2": message("m-2"),
+		},
+	}
+
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: providerClient}, &fakeInboxEventPublisher{}, &fakeFileStore{}).
+		WithMessageConcurrency(1)
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	require.Error(t, cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"}))
+
+	cursor := repo.cursors["acc-1"]
+	assert.Equal(t, domain.SyncCursorStatusPartiallySynced, cursor.Status)
+	assert.Equal(t, previousSync, *cursor.LastSyncedAt, "the cursor only moves once the whole range is synced")
+	require.NotNil(t, cursor.Checkpoint)
+	assert.Equal(t, "page-1", cursor.Checkpoint.PageToken)
+	assert.Equal(t, []string{"m-2"}, cursor.Checkpoint.ProcessedIDs)
+	until := cursor.Checkpoint.Until
+
+	require.Len(t, repo.syncRuns, 1)
+	assert.Equal(t, domain.SyncRunStatusPartial, repo.syncRuns[0].Status)
+	assert.False(t, repo.syncRuns[0].Resumed)
+	assert.Equal(t, 4, repo.syncRuns[0].Stats.MessagesSeen)
+	assert.Equal(t, 3, repo.syncRuns[0].Stats.MessagesNew)
+	assert.Positive(t, repo.syncRuns[0].Stats.BytesDownloaded)
+
+	providerClient.messages["m-broken"] = message("m-broken")
+	providerClient.listPageTokens, providerClient.getMessageCalls = nil, nil
+	require.NoError(t, cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1", Force: true}))
+
+	assert.Equal(t, []string{"page-1"}, providerClient.listPageTokens, "the sync resumes from the saved page")
+	assert.Equal(t, []string{"m-broken"}, providerClient.getMessageCalls)
+	cursor = repo.cursors["acc-1"]
+	assert.Equal(t, domain.SyncCursorStatusIdle, cursor.Status)
+	assert.Nil(t, cursor.Checkpoint)
+	assert.Equal(t, until, *cursor.LastSyncedAt)
+
+	require.Len(t, repo.syncRuns, 2)
+	assert.Equal(t, domain.SyncRunStatusSucceeded, repo.syncRuns[1].Status)
+	assert.True(t, repo.syncRuns[1].Resumed)
+	assert.Equal(t, 1, repo.syncRuns[1].Stats.MessagesSeen)
+}
+
+func TestSyncAccountCommand_ProcessesAtMostMessageConcurrencyAtOnce(t *testing.T) {
+	refs := make([]domain.MessageRef, 0, 10)
+	messages := map[string]*domain.MailMessage{}
+	for i := range 10 {
+		id := fmt.Sprintf("m-%d", i)
+		refs = append(refs, domain.MessageRef{ID: id})
+		messages[id] = &domain.MailMessage{ID: id, Subject: "ok", Sender: "Sender <sender@example.com>", PlainTextBody: "normal"}
+	}
+	client := newBlockingProviderClient(&fakeProviderClient{refs: refs, messages: messages})
+	repo := newFakeInboxRepo()
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail", ProviderAccountEmail: "user@gmail.com"}},
+	}
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: client}, &fakeInboxEventPublisher{}, &fakeFileStore{}).
+		WithMessageConcurrency(3)
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"}) }()
+
+	require.Eventually(t, func() bool { return client.started() == 3 }, time.Second, time.Millisecond)
+	// The pool is full: no other message starts while these three block.
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 3, client.started())
+	close(client.release)
+
+	require.NoError(t, <-done)
+	assert.Equal(t, 10, client.started())
+	assert.Equal(t, 3, client.maxInFlight)
+	assert.Len(t, repo.upsertedMessages, 10)
+}
+
+func TestSyncAccountCommand_FailureStopsMessagesNotStarted(t *testing.T) {
+	refs := []domain.MessageRef{{ID: "m-missing"}, {ID: "m-1"}, {ID: "m-2"}, {ID: "m-3"}, {ID: "m-4"}}
+	messages := map[string]*domain.MailMessage{}
+	for _, ref := range refs[1:] {
+		messages[ref.ID] = &domain.MailMessage{ID: ref.ID, Subject: "ok", Sender: "Sender <sender@example.com>", PlainTextBody: "normal"}
+	}
+	// m-missing fails once m-1 is in flight; m-1 blocks until the failure
+	// cancels it.
+	client := newBlockingProviderClient(&fakeProviderClient{refs: refs, messages: messages})
+	client.failFast = "m-missing"
+	repo := newFakeInboxRepo()
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail", ProviderAccountEmail: "user@gmail.com"}},
+	}
+	cmd := inboxCommands.NewSyncAccountCommand(repo, repo, repo, connectionsSvc, &fakeProviderFactory{client: client}, &fakeInboxEventPublisher{}, &fakeFileStore{}).
+		WithMessageConcurrency(2)
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	err := cmd.Execute(ctx, inboxCommands.SyncAccountCommandInput{AccountID: "acc-1"})
+	require.Error(t, err)
+	assert.ElementsMatch(t, []string{"m-missing", "m-1"}, client.calls)
+	assert.Empty(t, repo.upsertedMessages)
+}
+
+// blockingProviderClient holds every GetMessage until release is closed or
+// the run is cancelled, and counts the calls in flight. The failFast message
+// is not held: it fails as soon as another call blocks.
+type blockingProviderClient struct {
+	*fakeProviderClient
+
+	release     chan struct{}
+	blocking    chan struct{}
+	blockOnce   sync.Once
+	failFast    string
+	calls       []string
+	inFlight    int
+	maxInFlight int
+}
+
+func newBlockingProviderClient(client *fakeProviderClient) *blockingProviderClient {
+	return &blockingProviderClient{fakeProviderClient: client, release: make(chan struct{}), blocking: make(chan struct{})}
+}
+
+func (f *blockingProviderClient) started() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.calls)
+}
+
+func (f *blockingProviderClient) GetMessage(ctx context.Context, userID, messageID string) (*domain.MailMessage, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, messageID)
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}()
+
+	if messageID == f.failFast {
+		<-f.blocking
+		return f.fakeProviderClient.GetMessage(ctx, userID, messageID)
+	}
+
+	f.blockOnce.Do(func() { close(f.blocking) })
+	select {
+	case <-f.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return f.fakeProviderClient.GetMessage(ctx, userID, messageID)
+}
+
+func parseSyncQuery(t *testing.T, query string) (time.Time, time.Time) {
+	t.Helper()
+	var after, before int64
+	_, err := fmt.Sscanf(query, "after:%d before:%d", &after, &before)
+	require.NoError(t, err, query)
+	return time.Unix(after, 0).UTC(), time.Unix(before, 0).UTC()
+}
+
 func toUnixString(v time.Time) string {
 	return strconv.FormatInt(v.Unix(), 10)
 }
 
+// The sync processes messages concurrently, so the fakes it calls lock.
 type fakeInboxRepo struct {
+	mu                  sync.Mutex
 	cursors             map[string]*domain.SyncCursor
 	upsertedCursors     []*domain.SyncCursor
 	upsertedMessages    []*domain.InboxMessage
 	upsertedAttachments []*domain.MessageAttachment
+	syncRuns            []*domain.SyncRun
+	// existingMessageIDs are already in the inbox.
+	existingMessageIDs map[string]bool
 }
 
 func newFakeInboxRepo() *fakeInboxRepo {
@@ -248,10 +534,14 @@ func newFakeInboxRepo() *fakeInboxRepo {
 }
 
 func (f *fakeInboxRepo) GetSyncCursor(ctx context.Context, connectionID string) (*domain.SyncCursor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	return f.cursors[connectionID], nil
 }
 
 func (f *fakeInboxRepo) UpsertSyncCursor(ctx context.Context, cursor *domain.SyncCursor) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	cloned := *cursor
 	f.cursors[cursor.ConnectionID] = &cloned
 	f.upsertedCursors = append(f.upsertedCursors, &cloned)
@@ -259,33 +549,79 @@ func (f *fakeInboxRepo) UpsertSyncCursor(ctx context.Context, cursor *domain.Syn
 }
 
 func (f *fakeInboxRepo) UpsertInboxMessage(ctx context.Context, msg *domain.InboxMessage) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.upsertedMessages = append(f.upsertedMessages, msg)
-	return true, nil
+	return !f.existingMessageIDs[msg.ProviderMessageID], nil
 }
 
 func (f *fakeInboxRepo) UpsertMessageAttachment(ctx context.Context, attachment *domain.MessageAttachment) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.upsertedAttachments = append(f.upsertedAttachments, attachment)
 	return true, nil
 }
 
+func (f *fakeInboxRepo) RecordSyncRun(ctx context.Context, run *domain.SyncRun) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	cloned := *run
+	f.syncRuns = append(f.syncRuns, &cloned)
+	return nil
+}
+
+func (f *fakeInboxRepo) ListSyncRuns(ctx context.Context, connectionID string, limit int) ([]*domain.SyncRun, error) {
+	return f.syncRuns, nil
+}
+
 type fakeConnectionsInternalService struct {
-	activeConnections  []connectionsApp.ConnectionInfo
-	markReconnectCalls int
+	connectionsApp.InternalService
+
+	activeConnections     []connectionsApp.ConnectionInfo
+	accessibleConnections []connectionsApp.ConnectionInfo
+	markReconnectCalls    int
+	markReconnectReason   string
+	tokenErr              error
+	requestedActor        connectionsDomain.Actor
+	requestedAccess       connectionsDomain.Access
 }
 
-func (f *fakeConnectionsInternalService) GetActiveConnections(ctx context.Context) ([]connectionsApp.ConnectionInfo, error) {
-	return f.activeConnections, nil
+func (f *fakeConnectionsInternalService) ListAccessibleConnections(ctx context.Context, actor connectionsDomain.Actor, access connectionsDomain.Access) ([]connectionsApp.ConnectionInfo, error) {
+	f.requestedActor = actor
+	f.requestedAccess = access
+	return f.accessibleConnections, nil
 }
 
-func (f *fakeConnectionsInternalService) DecryptCredentials(ctx context.Context, connectionID string) ([]byte, error) {
-	return []byte(`{"access_token":"masked"}`), nil
+func (f *fakeConnectionsInternalService) FindActiveConnection(ctx context.Context, connectionID string) (connectionsApp.ConnectionInfo, bool, error) {
+	for _, connection := range f.activeConnections {
+		if connection.ID == connectionID {
+			return connection, true, nil
+		}
+	}
+	return connectionsApp.ConnectionInfo{}, false, nil
+}
+
+func (f *fakeConnectionsInternalService) TokenSource(ctx context.Context, connectionID string) oauth2.TokenSource {
+	return fakeTokenSource{err: f.tokenErr}
 }
 
 func (f *fakeConnectionsInternalService) MarkRequiresReconnect(ctx context.Context, connectionID, reason string) error {
 	f.markReconnectCalls++
+	f.markReconnectReason = reason
 	return nil
 }
 
+type fakeTokenSource struct {
+	err error
+}
+
+func (s fakeTokenSource) Token() (*oauth2.Token, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &oauth2.Token{AccessToken: "masked"}, nil
+}
+
 func (f *fakeConnectionsInternalService) GetSharingPolicy(ctx context.Context, connectionID string) (string, error) {
 	return "private", nil
 }
@@ -295,7 +631,7 @@ type fakeProviderFactory struct {
 	err    error
 }
 
-func (f *fakeProviderFactory) Build(ctx context.Context, provider string, credentialsJSON []byte) (domain.MailProviderClient, error) {
+func (f *fakeProviderFactory) Build(ctx context.Context, provider string, tokens oauth2.TokenSource) (domain.MailProviderClient, error) {
 	if f.err != nil {
 		return nil, f.err
 	}
@@ -303,24 +639,51 @@ func (f *fakeProviderFactory) Build(ctx context.Context, provider string, creden
 }
 
 type fakeProviderClient struct {
-	refs                    []domain.MessageRef
+	mu   sync.Mutex
+	refs []domain.MessageRef
+	// pages, when set, are listed one by one with tokens "page-<n>".
+	pages                   [][]domain.MessageRef
+	listPageTokens          []string
 	messages                map[string]*domain.MailMessage
 	listErr                 error
 	listQueries             []string
 	getMessageCalls         []string
+	getMetadataCalls        []string
 	downloadAttachmentCalls []attachmentDownloadCall
 	downloadAttachmentErr   error
+	// labelIDs maps the labels in the mailbox to their IDs.
+	labelIDs      map[string]string
+	createdLabels []string
+	labelledWith  map[string][]string
+	addLabelErrs  []error
+	archivedIDs   []string
 }
 
 func (f *fakeProviderClient) ListMessages(ctx context.Context, opts domain.ListMessagesOptions) ([]domain.MessageRef, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.listQueries = append(f.listQueries, opts.Query)
+	f.listPageTokens = append(f.listPageTokens, opts.PageToken)
 	if f.listErr != nil {
 		return nil, "", f.listErr
 	}
+	if f.pages != nil {
+		page := 0
+		if opts.PageToken != "" {
+			page, _ = strconv.Atoi(strings.TrimPrefix(opts.PageToken, "page-"))
+		}
+		nextPageToken := ""
+		if page+1 < len(f.pages) {
+			nextPageToken = "page-" + strconv.Itoa(page+1)
+		}
+		return f.pages[page], nextPageToken, nil
+	}
 	return f.refs, "", nil
 }
 
 func (f *fakeProviderClient) GetMessage(ctx context.Context, userID, messageID string) (*domain.MailMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.getMessageCalls = append(f.getMessageCalls, messageID)
 	message, ok := f.messages[messageID]
 	if !ok {
@@ -329,7 +692,20 @@ func (f *fakeProviderClient) GetMessage(ctx context.Context, userID, messageID s
 	return message, nil
 }
 
+func (f *fakeProviderClient) GetMessageMetadata(ctx context.Context, userID, messageID string) (*domain.MailMessage, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.getMetadataCalls = append(f.getMetadataCalls, messageID)
+	message, ok := f.messages[messageID]
+	if !ok {
+		return nil, errors.New("message not found")
+	}
+	return &domain.MailMessage{ID: message.ID, Subject: message.Subject, Sender: message.Sender, InternalDate: message.InternalDate}, nil
+}
+
 func (f *fakeProviderClient) DownloadAttachment(ctx context.Context, userID, messageID, attachmentID string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.downloadAttachmentCalls = append(f.downloadAttachmentCalls, attachmentDownloadCall{
 		messageID:    messageID,
 		attachmentID: attachmentID,
@@ -341,31 +717,74 @@ func (f *fakeProviderClient) DownloadAttachment(ctx context.Context, userID, mes
 }
 
 func (f *fakeProviderClient) DownloadMessageAttachments(ctx context.Context, userID, messageID string, refs []domain.MailAttachmentRef) ([]domain.DownloadedMailAttachment, error) {
-	return nil, nil
+	downloaded := make([]domain.DownloadedMailAttachment, 0, len(refs))
+	for _, ref := range refs {
+		data, err := f.DownloadAttachment(ctx, userID, messageID, ref.AttachmentID)
+		if err != nil {
+			return nil, err
+		}
+		downloaded = append(downloaded, domain.DownloadedMailAttachment{MailAttachmentRef: ref, Data: data})
+	}
+	return downloaded, nil
 }
 
 func (f *fakeProviderClient) CreateLabel(ctx context.Context, userID, labelName string) (string, error) {
-	return "", nil
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.createdLabels = append(f.createdLabels, labelName)
+	if f.labelIDs == nil {
+		f.labelIDs = map[string]string{}
+	}
+	if _, ok := f.labelIDs[labelName]; !ok {
+		f.labelIDs[labelName] = "Label_" + strconv.Itoa(len(f.labelIDs)+1)
+	}
+	return f.labelIDs[labelName], nil
 }
 
 func (f *fakeProviderClient) AddLabelToMessage(ctx context.Context, userID, messageID, labelID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.addLabelErrs) > 0 {
+		err := f.addLabelErrs[0]
+		f.addLabelErrs = f.addLabelErrs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	if f.labelledWith == nil {
+		f.labelledWith = map[string][]string{}
+	}
+	f.labelledWith[messageID] = append(f.labelledWith[messageID], labelID)
+	return nil
+}
+
+func (f *fakeProviderClient) ArchiveMessage(ctx context.Context, userID, messageID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.archivedIDs = append(f.archivedIDs, messageID)
 	return nil
 }
 
 type fakeInboxEventPublisher struct {
+	mu        sync.Mutex
 	published []platformEvents.BusinessEvent
 }
 
 func (f *fakeInboxEventPublisher) Publish(ctx context.Context, event platformEvents.BusinessEvent) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.published = append(f.published, event)
 	return nil
 }
 
 type fakeFileStore struct {
+	mu     sync.Mutex
 	inputs []platformStorage.WriteFileIfAbsentInput
 }
 
 func (f *fakeFileStore) WriteFileIfAbsent(ctx context.Context, input platformStorage.WriteFileIfAbsentInput) (*platformStorage.WriteFileIfAbsentResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	f.inputs = append(f.inputs, input)
 	return &platformStorage.WriteFileIfAbsentResult{
 		Written:   true,
@@ -3,10 +3,13 @@ package application_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 
 	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
 	inboxCommands "github.com/bowerbird/internal/inbox/application/commands"
+	"github.com/bowerbird/internal/inbox/domain"
 	"github.com/bowerbird/internal/platform/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,16 +17,16 @@ import (
 
 func TestSyncAllConnectionsCommand_DispatchesJobPerActiveAccount(t *testing.T) {
 	connectionsService := &fakeConnectionsInternalService{
-		activeConnections: []connectionsApp.ConnectionInfo{
-			{ID: "acc-1", Provider: "gmail"},
-			{ID: "acc-2", Provider: "outlook"},
+		accessibleConnections: []connectionsApp.ConnectionInfo{
+			{ID: "acc-1", Provider: "gmail", Status: connectionsDomain.ConnectionStatusActive},
+			{ID: "acc-2", Provider: "outlook", Status: connectionsDomain.ConnectionStatusActive},
 		},
 	}
 	jobDispatcher := &fakeSyncAccountJobDispatcher{}
 	cmd := inboxCommands.NewSyncAllAccountsCommand(connectionsService, jobDispatcher)
 
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
-	err := cmd.Execute(ctx, "user-1")
+	err := cmd.Execute(ctx, connectionsDomain.Actor{UserID: "user-1"})
 
 	require.NoError(t, err)
 	require.Len(t, jobDispatcher.jobs, 2)
@@ -39,52 +42,72 @@ func TestSyncAllConnectionsCommand_NoActiveAccountsDoesNothing(t *testing.T) {
 	cmd := inboxCommands.NewSyncAllAccountsCommand(connectionsService, jobDispatcher)
 
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
-	err := cmd.Execute(ctx, "user-1")
+	err := cmd.Execute(ctx, connectionsDomain.Actor{UserID: "user-1"})
 
 	require.NoError(t, err)
 	assert.Len(t, jobDispatcher.jobs, 0)
 }
 
+func TestSyncAllConnectionsCommand_SkipsDeferredAccounts(t *testing.T) {
+	connectionsService := &fakeConnectionsInternalService{
+		accessibleConnections: []connectionsApp.ConnectionInfo{
+			{ID: "acc-1", Provider: "gmail", Status: connectionsDomain.ConnectionStatusActive},
+			{ID: "acc-2", Provider: "gmail", Status: connectionsDomain.ConnectionStatusActive},
+		},
+	}
+	jobDispatcher := &fakeSyncAccountJobDispatcher{deferredAccountID: "acc-1"}
+	cmd := inboxCommands.NewSyncAllAccountsCommand(connectionsService, jobDispatcher)
+
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+	err := cmd.Execute(ctx, connectionsDomain.Actor{UserID: "user-1"})
+
+	require.NoError(t, err)
+	assert.Len(t, jobDispatcher.jobs, 2)
+}
+
 func TestSyncAllConnectionsCommand_ReturnsDispatchErrors(t *testing.T) {
 	connectionsService := &fakeConnectionsInternalService{
-		activeConnections: []connectionsApp.ConnectionInfo{
-			{ID: "acc-1", Provider: "gmail"},
-			{ID: "acc-2", Provider: "gmail"},
+		accessibleConnections: []connectionsApp.ConnectionInfo{
+			{ID: "acc-1", Provider: "gmail", Status: connectionsDomain.ConnectionStatusActive},
+			{ID: "acc-2", Provider: "gmail", Status: connectionsDomain.ConnectionStatusActive},
 		},
 	}
 	jobDispatcher := &fakeSyncAccountJobDispatcher{failAccountID: "acc-1"}
 	cmd := inboxCommands.NewSyncAllAccountsCommand(connectionsService, jobDispatcher)
 
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
-	err := cmd.Execute(ctx, "user-1")
+	err := cmd.Execute(ctx, connectionsDomain.Actor{UserID: "user-1"})
 
 	require.Error(t, err)
 	assert.Len(t, jobDispatcher.jobs, 2)
 }
 
-func TestSyncAllConnectionsCommand_SkipsPrivateAccountsFromOtherUsers(t *testing.T) {
+func TestSyncAllConnectionsCommand_SyncsActiveConnectionsTheRequestorMaySync(t *testing.T) {
 	connectionsService := &fakeConnectionsInternalService{
-		activeConnections: []connectionsApp.ConnectionInfo{
-			{ID: "acc-private-me", Provider: "gmail", SharingPolicy: "private", OwnerUserID: "user-1"},
-			{ID: "acc-private-other", Provider: "gmail", SharingPolicy: "private", OwnerUserID: "user-2"},
-			{ID: "acc-shared", Provider: "gmail", SharingPolicy: "organization", OwnerUserID: "user-2"},
+		accessibleConnections: []connectionsApp.ConnectionInfo{
+			{ID: "acc-private-me", Provider: "gmail", Status: connectionsDomain.ConnectionStatusActive},
+			{ID: "acc-paused", Provider: "gmail", Status: connectionsDomain.ConnectionStatusPaused},
+			{ID: "acc-shared", Provider: "gmail", Status: connectionsDomain.ConnectionStatusActive},
 		},
 	}
 	jobDispatcher := &fakeSyncAccountJobDispatcher{}
 	cmd := inboxCommands.NewSyncAllAccountsCommand(connectionsService, jobDispatcher)
 
 	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
-	err := cmd.Execute(ctx, "user-1")
+	err := cmd.Execute(ctx, connectionsDomain.Actor{UserID: "user-1"})
 
 	require.NoError(t, err)
+	assert.Equal(t, connectionsDomain.AccessSync, connectionsService.requestedAccess)
+	assert.Equal(t, "user-1", connectionsService.requestedActor.UserID)
 	require.Len(t, jobDispatcher.jobs, 2)
 	assert.Equal(t, "acc-private-me", jobDispatcher.jobs[0].AccountID)
 	assert.Equal(t, "acc-shared", jobDispatcher.jobs[1].AccountID)
 }
 
 type fakeSyncAccountJobDispatcher struct {
-	jobs          []inboxCommands.SyncAccountJob
-	failAccountID string
+	jobs              []inboxCommands.SyncAccountJob
+	failAccountID     string
+	deferredAccountID string
 }
 
 func (f *fakeSyncAccountJobDispatcher) DispatchSyncAccount(ctx context.Context, job inboxCommands.SyncAccountJob) error {
@@ -92,5 +115,8 @@ func (f *fakeSyncAccountJobDispatcher) DispatchSyncAccount(ctx context.Context,
 	if job.AccountID == f.failAccountID {
 		return errors.New("dispatch failed")
 	}
+	if job.AccountID == f.deferredAccountID {
+		return fmt.Errorf("%w until later", domain.ErrSyncDeferred)
+	}
 	return nil
 }
@@ -0,0 +1,107 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	connectionsApp "github.com/bowerbird/internal/connections/application"
+	connectionsDomain "github.com/bowerbird/internal/connections/domain"
+	inboxCommands "github.com/bowerbird/internal/inbox/application/commands"
+	"github.com/bowerbird/internal/platform/tenant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBackMessageCommand_LabelsAndArchivesProcessedMessages(t *testing.T) {
+	labels := newFakeLabelCache()
+	providerClient := &fakeProviderClient{}
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{
+			ID:        "acc-1",
+			Provider:  "gmail",
+			WriteBack: connectionsDomain.WriteBackSettings{Enabled: true, Archive: true},
+		}},
+	}
+
+	cmd := inboxCommands.NewWriteBackMessageCommand(labels, connectionsSvc, &fakeProviderFactory{client: providerClient})
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	require.NoError(t, cmd.Execute(ctx, inboxCommands.WriteBackMessageInput{ConnectionID: "acc-1", ProviderMessageID: "msg-1"}))
+	require.NoError(t, cmd.Execute(ctx, inboxCommands.WriteBackMessageInput{ConnectionID: "acc-1", ProviderMessageID: "msg-2"}))
+	require.NoError(t, cmd.Execute(ctx, inboxCommands.WriteBackMessageInput{ConnectionID: "acc-1", ProviderMessageID: "msg-3", Failed: true}))
+
+	// Each label is resolved once and then served from the cache.
+	assert.Equal(t, []string{connectionsDomain.DefaultProcessedLabel, connectionsDomain.DefaultErrorLabel}, providerClient.createdLabels)
+	processedID := labels.ids["acc-1/"+connectionsDomain.DefaultProcessedLabel]
+	errorID := labels.ids["acc-1/"+connectionsDomain.DefaultErrorLabel]
+	assert.Equal(t, []string{processedID}, providerClient.labelledWith["msg-1"])
+	assert.Equal(t, []string{processedID}, providerClient.labelledWith["msg-2"])
+	assert.Equal(t, []string{errorID}, providerClient.labelledWith["msg-3"])
+	assert.Equal(t, []string{"msg-1", "msg-2"}, providerClient.archivedIDs)
+}
+
+func TestWriteBackMessageCommand_ResolvesStaleCachedLabelAgain(t *testing.T) {
+	labels := newFakeLabelCache()
+	labels.ids["acc-1/Facturas"] = "Label_deleted"
+	providerClient := &fakeProviderClient{
+		labelIDs:     map[string]string{"Facturas": "Label_7"},
+		addLabelErrs: []error{errors.New("invalid label")},
+	}
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{
+			ID:        "acc-1",
+			Provider:  "gmail",
+			WriteBack: connectionsDomain.WriteBackSettings{Enabled: true, ProcessedLabel: "Facturas"},
+		}},
+	}
+
+	cmd := inboxCommands.NewWriteBackMessageCommand(labels, connectionsSvc, &fakeProviderFactory{client: providerClient})
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	require.NoError(t, cmd.Execute(ctx, inboxCommands.WriteBackMessageInput{ConnectionID: "acc-1", ProviderMessageID: "msg-1"}))
+
+	assert.Equal(t, "Label_7", labels.ids["acc-1/Facturas"])
+	assert.Equal(t, []string{"Label_7"}, providerClient.labelledWith["msg-1"])
+	assert.Empty(t, providerClient.archivedIDs)
+}
+
+func TestWriteBackMessageCommand_LeavesMailboxAloneWhenDisabledOrInactive(t *testing.T) {
+	labels := newFakeLabelCache()
+	providerClient := &fakeProviderClient{}
+	connectionsSvc := &fakeConnectionsInternalService{
+		activeConnections: []connectionsApp.ConnectionInfo{{ID: "acc-1", Provider: "gmail"}},
+	}
+
+	cmd := inboxCommands.NewWriteBackMessageCommand(labels, connectionsSvc, &fakeProviderFactory{client: providerClient})
+	ctx := tenant.WithTenantID(context.Background(), "tenant-a")
+
+	require.NoError(t, cmd.Execute(ctx, inboxCommands.WriteBackMessageInput{ConnectionID: "acc-1", ProviderMessageID: "msg-1"}))
+	require.NoError(t, cmd.Execute(ctx, inboxCommands.WriteBackMessageInput{ConnectionID: "acc-paused", ProviderMessageID: "msg-2"}))
+
+	assert.Empty(t, providerClient.createdLabels)
+	assert.Empty(t, providerClient.labelledWith)
+	assert.Empty(t, providerClient.archivedIDs)
+}
+
+type fakeLabelCache struct {
+	ids map[string]string
+}
+
+func newFakeLabelCache() *fakeLabelCache {
+	return &fakeLabelCache{ids: map[string]string{}}
+}
+
+func (f *fakeLabelCache) GetLabelID(ctx context.Context, connectionID, labelName string) (string, error) {
+	return f.ids[connectionID+"/"+labelName], nil
+}
+
+func (f *fakeLabelCache) SaveLabelID(ctx context.Context, connectionID, labelName, labelID string) error {
+	f.ids[connectionID+"/"+labelName] = labelID
+	return nil
+}
+
+func (f *fakeLabelCache) DeleteLabelID(ctx context.Context, connectionID, labelName string) error {
+	delete(f.ids, connectionID+"/"+labelName)
+	return nil
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	HistoricalImportChunkRequestedType = "InboxHistoricalImportChunkRequested"
+)
+
+// HistoricalImportChunkRequested runs the next chunk of a historical import.
+// Duplicate deliveries are harmless: a chunk only runs while it holds the
+// import's lease, and finished imports are skipped.
+type HistoricalImportChunkRequested struct {
+	ImportID    string `json:"import_id"`
+	RequestedAt string `json:"requested_at"`
+}
+
+func (j HistoricalImportChunkRequested) Validate() error {
+	if j.ImportID == "" {
+		return errors.New("import_id is required")
+	}
+
+	return nil
+}
+
+func MarshalHistoricalImportChunkRequested(job HistoricalImportChunkRequested) ([]byte, error) {
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(job)
+}
+
+func UnmarshalHistoricalImportChunkRequested(data []byte) (HistoricalImportChunkRequested, error) {
+	var job HistoricalImportChunkRequested
+	if err := json.Unmarshal(data, &job); err != nil {
+		return HistoricalImportChunkRequested{}, err
+	}
+
+	if err := job.Validate(); err != nil {
+		return HistoricalImportChunkRequested{}, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+type HealthStatus string
+
+const (
+	// HealthStatusHealthy: the last sync succeeded.
+	HealthStatusHealthy HealthStatus = "healthy"
+	// HealthStatusDegraded: recent syncs failed; retries back off.
+	HealthStatusDegraded HealthStatus = "degraded"
+	// HealthStatusRateLimited: the provider asked to wait before retrying.
+	HealthStatusRateLimited HealthStatus = "rate_limited"
+	// HealthStatusSuspended: too many consecutive failures; only an
+	// occasional probe sync runs until one succeeds.
+	HealthStatusSuspended HealthStatus = "suspended"
+)
+
+const (
+	// SuspendAfterFailures suspends a connection after this many
+	// consecutive failures.
+	SuspendAfterFailures = 8
+
+	healthBaseBackoff      = time.Minute
+	healthMaxBackoff       = 2 * time.Hour
+	suspendedProbeInterval = 6 * time.Hour
+)
+
+var ErrSyncDeferred = errors.New("connection sync deferred")
+
+// SyncFailure describes a failed sync for the health model.
+type SyncFailure struct {
+	Code string
+	// RetryAfter is the wait the provider asked for, if any.
+	RetryAfter  time.Duration
+	RateLimited bool
+}
+
+// ConnectionHealth tracks how syncs of a connection have been going and
+// when the next one may run.
+type ConnectionHealth struct {
+	ConsecutiveFailures int
+	LastSucceededAt     *time.Time
+	LastFailedAt        *time.Time
+	LastErrorCode       string
+	// ErrorCounts counts failures by error code since the connection was
+	// first synced.
+	ErrorCounts      map[string]int
+	NextAttemptAt    *time.Time
+	RateLimitedUntil *time.Time
+	SuspendedAt      *time.Time
+}
+
+// Status reports the health of the connection at the given time.
+func (h ConnectionHealth) Status(at time.Time) HealthStatus {
+	switch {
+	case h.SuspendedAt != nil:
+		return HealthStatusSuspended
+	case h.RateLimitedUntil != nil && at.Before(*h.RateLimitedUntil):
+		return HealthStatusRateLimited
+	case h.ConsecutiveFailures > 0:
+		return HealthStatusDegraded
+	default:
+		return HealthStatusHealthy
+	}
+}
+
+// CanSyncAt reports whether a sync may run at the given time.
+func (h ConnectionHealth) CanSyncAt(at time.Time) bool {
+	return h.NextAttemptAt == nil || !at.Before(*h.NextAttemptAt)
+}
+
+// RecordFailure schedules the next attempt. Rate limits wait as long as the
+// provider asked and do not count towards suspension; other failures back
+// off exponentially until the connection is suspended.
+func (h *ConnectionHealth) RecordFailure(failure SyncFailure, at time.Time) {
+	at = at.UTC()
+	h.LastFailedAt = &at
+	h.LastErrorCode = failure.Code
+	if h.ErrorCounts == nil {
+		h.ErrorCounts = map[string]int{}
+	}
+	if failure.Code != "" {
+		h.ErrorCounts[failure.Code]++
+	}
+
+	if failure.RateLimited {
+		until := at.Add(failure.RetryAfter)
+		h.RateLimitedUntil = &until
+		h.scheduleAt(until)
+		return
+	}
+
+	h.ConsecutiveFailures++
+	if h.ConsecutiveFailures >= SuspendAfterFailures {
+		if h.SuspendedAt == nil {
+			h.SuspendedAt = &at
+		}
+		h.scheduleAt(at.Add(max(suspendedProbeInterval, failure.RetryAfter)))
+		return
+	}
+
+	backoff := healthBaseBackoff << (h.ConsecutiveFailures - 1)
+	h.scheduleAt(at.Add(max(min(backoff, healthMaxBackoff), failure.RetryAfter)))
+}
+
+// RecordSuccess clears failures, back-off and suspension. It reports whether
+// the connection was suspended, i.e. whether it has just been resumed.
+func (h *ConnectionHealth) RecordSuccess(at time.Time) bool {
+	resumed := h.SuspendedAt != nil
+
+	at = at.UTC()
+	h.LastSucceededAt = &at
+	h.ConsecutiveFailures = 0
+	h.NextAttemptAt = nil
+	h.RateLimitedUntil = nil
+	h.SuspendedAt = nil
+
+	return resumed
+}
+
+func (h *ConnectionHealth) scheduleAt(next time.Time) {
+	h.NextAttemptAt = &next
+}
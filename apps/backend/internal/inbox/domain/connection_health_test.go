@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConnectionHealthBacksOffAndSuspends(t *testing.T) {
+	now := time.Date(2026, 5, 25, 12, 0, 0, 0, time.UTC)
+	var health ConnectionHealth
+
+	health.RecordFailure(SyncFailure{Code: "ERR_SYNC_PROVIDER_TEMPORARY"}, now)
+	if health.Status(now) != HealthStatusDegraded {
+		t.Fatalf("expected degraded after a failure, got %s", health.Status(now))
+	}
+	if health.CanSyncAt(now.Add(59*time.Second)) || !health.CanSyncAt(now.Add(time.Minute)) {
+		t.Fatalf("expected a one minute back-off, next attempt at %v", health.NextAttemptAt)
+	}
+
+	health.RecordFailure(SyncFailure{Code: "ERR_SYNC_PROVIDER_TEMPORARY"}, now)
+	if !health.NextAttemptAt.Equal(now.Add(2 * time.Minute)) {
+		t.Fatalf("expected the back-off to double, got %v", health.NextAttemptAt)
+	}
+
+	for health.ConsecutiveFailures < SuspendAfterFailures {
+		health.RecordFailure(SyncFailure{Code: "ERR_SYNC_INTERNAL"}, now)
+	}
+	if health.Status(now) != HealthStatusSuspended || health.SuspendedAt == nil {
+		t.Fatalf("expected suspension after %d failures, got %s", SuspendAfterFailures, health.Status(now))
+	}
+	if !health.NextAttemptAt.Equal(now.Add(suspendedProbeInterval)) {
+		t.Fatalf("expected a probe in %s, got %v", suspendedProbeInterval, health.NextAttemptAt)
+	}
+	if health.ErrorCounts["ERR_SYNC_PROVIDER_TEMPORARY"] != 2 || health.ErrorCounts["ERR_SYNC_INTERNAL"] != SuspendAfterFailures-2 {
+		t.Fatalf("expected failures counted by code, got %v", health.ErrorCounts)
+	}
+
+	if !health.RecordSuccess(now.Add(suspendedProbeInterval)) {
+		t.Fatal("expected a successful probe to resume the connection")
+	}
+	if health.Status(now) != HealthStatusHealthy || !health.CanSyncAt(now) || health.ConsecutiveFailures != 0 {
+		t.Fatalf("expected a healthy connection after success, got %+v", health)
+	}
+}
+
+func TestConnectionHealthRateLimitWaitsWithoutCountingTowardsSuspension(t *testing.T) {
+	now := time.Date(2026, 5, 25, 12, 0, 0, 0, time.UTC)
+	var health ConnectionHealth
+
+	for range SuspendAfterFailures {
+		health.RecordFailure(SyncFailure{Code: "ERR_SYNC_RATE_LIMITED", RetryAfter: 5 * time.Minute, RateLimited: true}, now)
+	}
+
+	if health.Status(now) != HealthStatusRateLimited || health.ConsecutiveFailures != 0 {
+		t.Fatalf("expected rate limited without suspension, got %s after %d failures", health.Status(now), health.ConsecutiveFailures)
+	}
+	if health.CanSyncAt(now.Add(4*time.Minute)) || !health.CanSyncAt(now.Add(5*time.Minute)) {
+		t.Fatalf("expected to wait as long as the provider asked, next attempt at %v", health.NextAttemptAt)
+	}
+	if health.Status(now.Add(5*time.Minute)) != HealthStatusHealthy {
+		t.Fatalf("expected the rate limit to lapse, got %s", health.Status(now.Add(5*time.Minute)))
+	}
+}
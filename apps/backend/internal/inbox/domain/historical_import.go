@@ -0,0 +1,208 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	HistoricalImportStatusPending   = "pending"
+	HistoricalImportStatusRunning   = "running"
+	HistoricalImportStatusCompleted = "completed"
+	HistoricalImportStatusFailed    = "failed"
+)
+
+const (
+	// HistoricalImportWindow is the span of mail listed by one chunk; the
+	// import advances one window at a time, oldest first.
+	HistoricalImportWindow = 7 * 24 * time.Hour
+	// MaxHistoricalImportDays caps how far back an import may reach.
+	MaxHistoricalImportDays = 3 * 366
+	// HistoricalImportMaxFailures fails the import after this many
+	// consecutive failed chunks. Rate limits do not count.
+	HistoricalImportMaxFailures = 5
+	// HistoricalImportLease is how long a run holds the import; a run that
+	// dies leaves it claimable again once the lease runs out.
+	HistoricalImportLease = 15 * time.Minute
+
+	historicalImportBaseBackoff = time.Minute
+)
+
+var (
+	ErrHistoricalImportNotFound     = errors.New("historical import not found")
+	ErrHistoricalImportInProgress   = errors.New("a historical import is already in progress for this connection")
+	ErrInvalidHistoricalImportRange = errors.New("invalid historical import range")
+)
+
+// HistoricalImportProgress is updated after every page so the owner can
+// follow the import.
+type HistoricalImportProgress struct {
+	WindowsTotal     int `json:"windows_total"`
+	WindowsDone      int `json:"windows_done"`
+	MessagesListed   int `json:"messages_listed"`
+	MessagesImported int `json:"messages_imported"`
+	// MessagesSkipped were excluded by the ingestion rules or rejected.
+	MessagesSkipped int `json:"messages_skipped"`
+}
+
+// HistoricalImport backfills the mail of a connection received between Since
+// and Until. It runs apart from the incremental sync, in chunks that each
+// pick up from the checkpoint the previous one saved.
+type HistoricalImport struct {
+	ID           string
+	ConnectionID string
+	RequestedBy  string
+	Since        time.Time
+	Until        time.Time
+	Status       string
+	Progress     HistoricalImportProgress
+	// WindowStart and PageToken are the checkpoint: the window being listed
+	// and the provider page to continue from.
+	WindowStart         time.Time
+	PageToken           string
+	ConsecutiveFailures int
+	// NextAttemptAt holds the next chunk back after a failure or a rate
+	// limit.
+	NextAttemptAt    *time.Time
+	RateLimitedUntil *time.Time
+	LeaseUntil       *time.Time
+	Error            string
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	StartedAt        *time.Time
+	CompletedAt      *time.Time
+}
+
+func NewHistoricalImport(id, connectionID, requestedBy string, since, until, now time.Time) (*HistoricalImport, error) {
+	if id == "" || connectionID == "" {
+		return nil, errors.New("historical import id and connection id are required")
+	}
+
+	since, until, now = since.UTC(), until.UTC(), now.UTC()
+	if until.After(now) {
+		until = now
+	}
+	if !since.Before(until) {
+		return nil, fmt.Errorf("%w: since must be before until", ErrInvalidHistoricalImportRange)
+	}
+	if since.Before(now.AddDate(0, 0, -MaxHistoricalImportDays)) {
+		return nil, fmt.Errorf("%w: imports reach back at most %d days", ErrInvalidHistoricalImportRange, MaxHistoricalImportDays)
+	}
+
+	windows := int((until.Sub(since) + HistoricalImportWindow - 1) / HistoricalImportWindow)
+	return &HistoricalImport{
+		ID:           id,
+		ConnectionID: connectionID,
+		RequestedBy:  requestedBy,
+		Since:        since,
+		Until:        until,
+		Status:       HistoricalImportStatusPending,
+		Progress:     HistoricalImportProgress{WindowsTotal: windows},
+		WindowStart:  since,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// InProgress reports whether the import still has chunks to run.
+func (i *HistoricalImport) InProgress() bool {
+	return i.Status == HistoricalImportStatusPending || i.Status == HistoricalImportStatusRunning
+}
+
+// Window returns the range of the chunk to run next.
+func (i *HistoricalImport) Window() (time.Time, time.Time) {
+	end := i.WindowStart.Add(HistoricalImportWindow)
+	if end.After(i.Until) {
+		end = i.Until
+	}
+	return i.WindowStart, end
+}
+
+// Done reports whether every window has been listed.
+func (i *HistoricalImport) Done() bool {
+	return !i.WindowStart.Before(i.Until)
+}
+
+// Start marks the import as running the first time a chunk runs.
+func (i *HistoricalImport) Start(at time.Time) {
+	at = at.UTC()
+	if i.StartedAt == nil {
+		i.StartedAt = &at
+	}
+	i.Status = HistoricalImportStatusRunning
+	i.UpdatedAt = at
+}
+
+// RecordPage moves the checkpoint past a listed page. An empty next page
+// token closes the current window.
+func (i *HistoricalImport) RecordPage(nextPageToken string, listed, imported, skipped int, at time.Time) {
+	i.Progress.MessagesListed += listed
+	i.Progress.MessagesImported += imported
+	i.Progress.MessagesSkipped += skipped
+	i.ConsecutiveFailures = 0
+	i.NextAttemptAt = nil
+	i.RateLimitedUntil = nil
+	i.Error = ""
+
+	i.PageToken = nextPageToken
+	if nextPageToken == "" {
+		_, end := i.Window()
+		i.WindowStart = end
+		i.Progress.WindowsDone++
+	}
+	i.UpdatedAt = at.UTC()
+}
+
+// Release gives up the lease so the next chunk can claim the import.
+func (i *HistoricalImport) Release() {
+	i.LeaseUntil = nil
+}
+
+// Complete closes an import whose windows have all been listed.
+func (i *HistoricalImport) Complete(at time.Time) {
+	at = at.UTC()
+	i.Status = HistoricalImportStatusCompleted
+	i.CompletedAt = &at
+	i.NextAttemptAt = nil
+	i.LeaseUntil = nil
+	i.UpdatedAt = at
+}
+
+// Fail closes the import for good; the checkpoint is kept for reference.
+func (i *HistoricalImport) Fail(message string, at time.Time) {
+	at = at.UTC()
+	i.Status = HistoricalImportStatusFailed
+	i.Error = message
+	i.CompletedAt = &at
+	i.NextAttemptAt = nil
+	i.LeaseUntil = nil
+	i.UpdatedAt = at
+}
+
+// RecordFailure schedules the chunk again. Rate limits wait as long as the
+// provider asked; other failures back off and, after
+// HistoricalImportMaxFailures in a row, fail the import. It reports whether
+// the import can still go on.
+func (i *HistoricalImport) RecordFailure(message string, failure SyncFailure, at time.Time) bool {
+	at = at.UTC()
+	i.Error = message
+	i.UpdatedAt = at
+
+	if failure.RateLimited {
+		until := at.Add(failure.RetryAfter)
+		i.RateLimitedUntil = &until
+		i.NextAttemptAt = &until
+		return true
+	}
+
+	i.ConsecutiveFailures++
+	if i.ConsecutiveFailures >= HistoricalImportMaxFailures {
+		i.Fail(message, at)
+		return false
+	}
+
+	next := at.Add(max(historicalImportBaseBackoff<<(i.ConsecutiveFailures-1), failure.RetryAfter))
+	i.NextAttemptAt = &next
+	return true
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHistoricalImportWalksWindowsOldestFirst(t *testing.T) {
+	now := time.Date(2026, 5, 25, 12, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -10)
+
+	historicalImport, err := NewHistoricalImport("imp-1", "acc-1", "user-1", since, now.AddDate(0, 0, 3), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !historicalImport.Until.Equal(now) || historicalImport.Progress.WindowsTotal != 2 {
+		t.Fatalf("expected the range clamped to now in two windows, got until %v and %d windows", historicalImport.Until, historicalImport.Progress.WindowsTotal)
+	}
+
+	start, end := historicalImport.Window()
+	if !start.Equal(since) || !end.Equal(since.Add(HistoricalImportWindow)) {
+		t.Fatalf("expected the first window to start at since, got %v - %v", start, end)
+	}
+
+	historicalImport.RecordPage("page-2", 100, 90, 10, now)
+	if historicalImport.PageToken != "page-2" || historicalImport.Progress.WindowsDone != 0 {
+		t.Fatalf("expected to stay in the window while pages remain, got %+v", historicalImport)
+	}
+
+	historicalImport.RecordPage("", 20, 20, 0, now)
+	start, end = historicalImport.Window()
+	if !start.Equal(since.Add(HistoricalImportWindow)) || !end.Equal(now) || historicalImport.Progress.WindowsDone != 1 {
+		t.Fatalf("expected the last window to end at until, got %v - %v", start, end)
+	}
+
+	historicalImport.RecordPage("", 5, 5, 0, now)
+	if !historicalImport.Done() {
+		t.Fatal("expected the import to be done after the last window")
+	}
+	if historicalImport.Progress.MessagesListed != 125 || historicalImport.Progress.MessagesImported != 115 || historicalImport.Progress.MessagesSkipped != 10 {
+		t.Fatalf("expected progress to add up, got %+v", historicalImport.Progress)
+	}
+}
+
+func TestHistoricalImportRejectsInvalidRanges(t *testing.T) {
+	now := time.Date(2026, 5, 25, 12, 0, 0, 0, time.UTC)
+
+	if _, err := NewHistoricalImport("imp-1", "acc-1", "", now, now.AddDate(0, 0, -1), now); !errors.Is(err, ErrInvalidHistoricalImportRange) {
+		t.Fatalf("expected an inverted range to be rejected, got %v", err)
+	}
+	if _, err := NewHistoricalImport("imp-1", "acc-1", "", now.AddDate(0, 0, -MaxHistoricalImportDays-1), now, now); !errors.Is(err, ErrInvalidHistoricalImportRange) {
+		t.Fatalf("expected a range beyond %d days to be rejected, got %v", MaxHistoricalImportDays, err)
+	}
+}
+
+func TestHistoricalImportBacksOffAndFails(t *testing.T) {
+	now := time.Date(2026, 5, 25, 12, 0, 0, 0, time.UTC)
+	historicalImport, err := NewHistoricalImport("imp-1", "acc-1", "", now.AddDate(0, 0, -30), now, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !historicalImport.RecordFailure("rate limited", SyncFailure{RateLimited: true, RetryAfter: 5 * time.Minute}, now) {
+		t.Fatal("expected a rate limit to keep the import going")
+	}
+	if historicalImport.ConsecutiveFailures != 0 || !historicalImport.NextAttemptAt.Equal(now.Add(5*time.Minute)) {
+		t.Fatalf("expected to wait as long as the provider asked without counting a failure, got %+v", historicalImport)
+	}
+
+	for i := 1; i < HistoricalImportMaxFailures; i++ {
+		if !historicalImport.RecordFailure("boom", SyncFailure{}, now) {
+			t.Fatalf("expected failure %d to keep the import going", i)
+		}
+	}
+	if !historicalImport.NextAttemptAt.Equal(now.Add(8 * time.Minute)) {
+		t.Fatalf("expected the back-off to double, got %v", historicalImport.NextAttemptAt)
+	}
+
+	if historicalImport.RecordFailure("boom", SyncFailure{}, now) {
+		t.Fatal("expected the import to fail after too many failures")
+	}
+	if historicalImport.Status != HistoricalImportStatusFailed || historicalImport.InProgress() {
+		t.Fatalf("expected a failed import, got %s", historicalImport.Status)
+	}
+}
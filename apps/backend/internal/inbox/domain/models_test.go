@@ -36,6 +36,59 @@ func TestInboxSyncCursorMarkSyncSucceeded(t *testing.T) {
 	}
 }
 
+func TestInboxSyncCursorResumesFromCheckpoint(t *testing.T) {
+	now := time.Date(2026, 5, 25, 12, 0, 0, 0, time.UTC)
+	cursor := &SyncCursor{ConnectionID: "conn-1", Status: SyncCursorStatusIdle}
+
+	checkpoint := cursor.BeginSync(now)
+	checkpoint.ListWith("after:1", now)
+	checkpoint.NextPage("page-2")
+	checkpoint.RecordProcessed("m-1")
+	cursor.MarkSyncFailed("provider timeout")
+	if cursor.Status != SyncCursorStatusPartiallySynced {
+		t.Fatalf("expected a failure after progress to be partially synced, got %s", cursor.Status)
+	}
+
+	resumed := cursor.BeginSync(now.Add(time.Hour))
+	resumed.ListWith("after:1", now.Add(time.Hour))
+	if !resumed.Until.Equal(now) || resumed.PageToken != "page-2" {
+		t.Fatalf("expected to resume page-2 of the range up to %v, got %#v", now, resumed)
+	}
+	if pending := resumed.Pending([]MessageRef{{ID: "m-1"}, {ID: "m-2"}}); len(pending) != 1 || pending[0].ID != "m-2" {
+		t.Fatalf("expected only m-2 pending, got %#v", pending)
+	}
+
+	cursor.MarkSyncSucceeded(now.Add(2 * time.Hour))
+	if cursor.Checkpoint != nil || !cursor.LastSyncedAt.Equal(now) {
+		t.Fatalf("expected the cursor at the end of the range and no checkpoint, got %v %#v", cursor.LastSyncedAt, cursor.Checkpoint)
+	}
+}
+
+func TestInboxSyncCheckpointStartsOverWhenStaleOrQueryChanges(t *testing.T) {
+	now := time.Date(2026, 5, 25, 12, 0, 0, 0, time.UTC)
+	cursor := &SyncCursor{ConnectionID: "conn-1"}
+
+	checkpoint := cursor.BeginSync(now)
+	checkpoint.ListWith("after:1", now)
+	checkpoint.NextPage("page-2")
+	checkpoint.ListWith("after:1 from:acme.com", now)
+	if checkpoint.PageToken != "" || checkpoint.PagesDone != 0 {
+		t.Fatalf("expected a new query to start from the first page, got %#v", checkpoint)
+	}
+
+	checkpoint.NextPage("page-2")
+	stale := cursor.BeginSync(now.Add(SyncCheckpointMaxAge + time.Minute))
+	if stale.PageToken != "" || !stale.Until.Equal(now) {
+		t.Fatalf("expected a stale checkpoint to keep its range and start over, got %#v", stale)
+	}
+
+	cursor.Checkpoint = &SyncCheckpoint{Until: now, StartedAt: now}
+	cursor.MarkSyncFailed("provider timeout")
+	if cursor.Status != SyncCursorStatusError {
+		t.Fatalf("expected a failure without progress to be an error, got %s", cursor.Status)
+	}
+}
+
 func TestInboxSyncCursorMarkSyncing(t *testing.T) {
 	cursor := &SyncCursor{ConnectionID: "conn-1", Status: SyncCursorStatusIdle}
 
@@ -80,8 +80,15 @@ type DownloadedMailAttachment struct {
 type MailProviderClient interface {
 	ListMessages(ctx context.Context, opts ListMessagesOptions) ([]MessageRef, string, error)
 	GetMessage(ctx context.Context, userID, messageID string) (*MailMessage, error)
+	// GetMessageMetadata returns the headers, labels and dates of a message
+	// without its body or attachments.
+	GetMessageMetadata(ctx context.Context, userID, messageID string) (*MailMessage, error)
 	DownloadAttachment(ctx context.Context, userID, messageID, attachmentID string) ([]byte, error)
 	DownloadMessageAttachments(ctx context.Context, userID, messageID string, refs []MailAttachmentRef) ([]DownloadedMailAttachment, error)
+	// CreateLabel returns the ID of the label with that name, creating it if
+	// needed.
 	CreateLabel(ctx context.Context, userID, labelName string) (string, error)
 	AddLabelToMessage(ctx context.Context, userID, messageID, labelID string) error
+	// ArchiveMessage moves the message out of the inbox.
+	ArchiveMessage(ctx context.Context, userID, messageID string) error
 }
@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
@@ -19,7 +20,56 @@ type SyncCursorRepository interface {
 	UpsertSyncCursor(ctx context.Context, cursor *SyncCursor) error
 }
 
+// SyncRunRepository keeps the history of sync runs of each connection.
+type SyncRunRepository interface {
+	// RecordSyncRun stores a finished run and drops the runs of the
+	// connection older than SyncRunRetention.
+	RecordSyncRun(ctx context.Context, run *SyncRun) error
+	// ListSyncRuns returns the latest runs of a connection, newest first.
+	ListSyncRuns(ctx context.Context, connectionID string, limit int) ([]*SyncRun, error)
+}
+
 type MessageRepository interface {
 	UpsertInboxMessage(ctx context.Context, msg *InboxMessage) (bool, error)
 	UpsertMessageAttachment(ctx context.Context, attachment *MessageAttachment) (bool, error)
 }
+
+// StoredAttachment is an attachment object kept in the file store.
+// Attachments an invoice was extracted from are accounting records and must
+// outlive the message.
+type StoredAttachment struct {
+	S3Key               string
+	ReferencedByInvoice bool
+}
+
+// MessagePurgeRepository removes everything synced from one connection.
+type MessagePurgeRepository interface {
+	ListConnectionAttachments(ctx context.Context, connectionID string) ([]StoredAttachment, error)
+	DeleteConnectionMessages(ctx context.Context, connectionID string) (int, error)
+}
+
+// LabelCacheRepository keeps, per connection, the provider IDs of the labels
+// written back to the mailbox.
+type LabelCacheRepository interface {
+	// GetLabelID returns "" when the label is not cached.
+	GetLabelID(ctx context.Context, connectionID, labelName string) (string, error)
+	SaveLabelID(ctx context.Context, connectionID, labelName, labelID string) error
+	DeleteLabelID(ctx context.Context, connectionID, labelName string) error
+}
+
+// HistoricalImportRepository stores the historical imports of each
+// connection. At most one import per connection is in progress.
+type HistoricalImportRepository interface {
+	// CreateHistoricalImport returns ErrHistoricalImportInProgress when the
+	// connection already has an import in progress.
+	CreateHistoricalImport(ctx context.Context, historicalImport *HistoricalImport) error
+	// GetHistoricalImport returns nil when the import does not exist.
+	GetHistoricalImport(ctx context.Context, importID string) (*HistoricalImport, error)
+	ListHistoricalImports(ctx context.Context, connectionIDs []string) ([]*HistoricalImport, error)
+	// ClaimHistoricalImport leases an import in progress to one run until
+	// leaseUntil. It returns nil when the import is finished or another run
+	// holds it.
+	ClaimHistoricalImport(ctx context.Context, importID string, now, leaseUntil time.Time) (*HistoricalImport, error)
+	// SaveHistoricalImport stores the checkpoint, progress and lease.
+	SaveHistoricalImport(ctx context.Context, historicalImport *HistoricalImport) error
+}
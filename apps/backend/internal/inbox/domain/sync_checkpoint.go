@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// SyncCheckpointMaxAge is how long a checkpoint's page token is trusted;
+// older checkpoints start over from the first page of the same range.
+const SyncCheckpointMaxAge = 24 * time.Hour
+
+// SyncCheckpoint is where an interrupted sync picks up. It is saved in the
+// cursor after every page and cleared when a sync lists its last one.
+type SyncCheckpoint struct {
+	// Until bounds the mail the sync lists, so the pages stay the same
+	// across runs; mail received later waits for the next sync.
+	Until time.Time `json:"until"`
+	// Query is the provider query the pages belong to.
+	Query string `json:"query"`
+	// PageToken is the page being processed; empty for the first one.
+	PageToken string `json:"page_token,omitempty"`
+	// ProcessedIDs are the messages of that page already imported or
+	// skipped.
+	ProcessedIDs []string  `json:"processed_ids,omitempty"`
+	PagesDone    int       `json:"pages_done"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// HasProgress reports whether any message of the range has been processed.
+func (c *SyncCheckpoint) HasProgress() bool {
+	return c != nil && (c.PagesDone > 0 || len(c.ProcessedIDs) > 0)
+}
+
+// ListWith sets the query the pages are listed with. Page tokens belong to
+// one query, so a different one, e.g. after the ingestion rules changed,
+// starts over from the first page.
+func (c *SyncCheckpoint) ListWith(query string, at time.Time) {
+	if c.Query == query {
+		return
+	}
+
+	c.Query = query
+	c.restart(at)
+}
+
+// Pending drops the messages of the page already processed.
+func (c *SyncCheckpoint) Pending(refs []MessageRef) []MessageRef {
+	if len(c.ProcessedIDs) == 0 {
+		return refs
+	}
+
+	return slices.DeleteFunc(slices.Clone(refs), func(ref MessageRef) bool {
+		return slices.Contains(c.ProcessedIDs, ref.ID)
+	})
+}
+
+// RecordProcessed keeps messages of the current page that need no retry.
+func (c *SyncCheckpoint) RecordProcessed(messageIDs ...string) {
+	c.ProcessedIDs = append(c.ProcessedIDs, messageIDs...)
+}
+
+// NextPage moves the checkpoint past a fully processed page.
+func (c *SyncCheckpoint) NextPage(pageToken string) {
+	c.PageToken = pageToken
+	c.ProcessedIDs = nil
+	c.PagesDone++
+}
+
+func (c *SyncCheckpoint) restart(at time.Time) {
+	c.PageToken = ""
+	c.ProcessedIDs = nil
+	c.PagesDone = 0
+	c.StartedAt = at.UTC()
+}
@@ -8,6 +8,9 @@ const (
 	SyncCursorStatusSyncing SyncCursorStatus = "syncing"
 	SyncCursorStatusIdle    SyncCursorStatus = "idle"
 	SyncCursorStatusError   SyncCursorStatus = "error"
+	// SyncCursorStatusPartiallySynced is a failed sync that saved a
+	// checkpoint; the next one resumes from it.
+	SyncCursorStatusPartiallySynced SyncCursorStatus = "partially_synced"
 )
 
 type SyncCursor struct {
@@ -15,6 +18,9 @@ type SyncCursor struct {
 	LastSyncedAt *time.Time
 	LastError    *string
 	Status       SyncCursorStatus
+	Health       ConnectionHealth
+	// Checkpoint is set while a sync is unfinished.
+	Checkpoint *SyncCheckpoint
 }
 
 func NewSyncCursor(connectionID string, initialSyncedAt *time.Time) (*SyncCursor, error) {
@@ -41,7 +47,7 @@ func (s SyncCursorStatus) String() string {
 
 func (s SyncCursorStatus) IsValid() bool {
 	switch s {
-	case SyncCursorStatusSyncing, SyncCursorStatusIdle, SyncCursorStatusError:
+	case SyncCursorStatusSyncing, SyncCursorStatusIdle, SyncCursorStatusError, SyncCursorStatusPartiallySynced:
 		return true
 	default:
 		return false
@@ -54,12 +60,44 @@ func (c *SyncCursor) MarkSyncing() {
 
 func (c *SyncCursor) MarkSyncFailed(failure string) {
 	c.Status = SyncCursorStatusError
+	if c.Checkpoint.HasProgress() {
+		c.Status = SyncCursorStatusPartiallySynced
+	}
 	c.LastError = &failure
 }
 
-func (c *SyncCursor) MarkSyncSucceeded(at time.Time) {
+// RecordSyncFailure marks the sync failed and feeds the health model.
+func (c *SyncCursor) RecordSyncFailure(message string, failure SyncFailure, at time.Time) {
+	c.MarkSyncFailed(message)
+	c.Health.RecordFailure(failure, at)
+}
+
+// BeginSync returns the checkpoint the sync continues from, opening one up
+// to now when the previous sync finished. A checkpoint older than
+// SyncCheckpointMaxAge keeps its range but starts over from the first page.
+func (c *SyncCursor) BeginSync(now time.Time) *SyncCheckpoint {
+	now = now.UTC()
+	if c.Checkpoint == nil {
+		c.Checkpoint = &SyncCheckpoint{Until: now, StartedAt: now}
+	} else if now.Sub(c.Checkpoint.StartedAt) > SyncCheckpointMaxAge {
+		c.Checkpoint.restart(now)
+	}
+
+	return c.Checkpoint
+}
+
+// MarkSyncSucceeded reports whether the connection was suspended and has
+// just been resumed. The cursor moves to the end of the checkpoint's range,
+// if any, and the checkpoint is cleared.
+func (c *SyncCursor) MarkSyncSucceeded(at time.Time) bool {
 	c.Status = SyncCursorStatusIdle
 	c.LastError = nil
 	syncedAt := at.UTC()
+	if c.Checkpoint != nil {
+		syncedAt = c.Checkpoint.Until
+	}
 	c.LastSyncedAt = &syncedAt
+	c.Checkpoint = nil
+
+	return c.Health.RecordSuccess(at)
 }
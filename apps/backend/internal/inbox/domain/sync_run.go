@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	SyncRunStatusSucceeded = "succeeded"
+	// SyncRunStatusPartial is a failed run that left a checkpoint to resume
+	// from.
+	SyncRunStatusPartial = "partial"
+	SyncRunStatusFailed  = "failed"
+)
+
+// SyncRunRetention is how long the history of sync runs is kept.
+const SyncRunRetention = 30 * 24 * time.Hour
+
+// SyncRunStats counts what one run did with the messages it listed.
+type SyncRunStats struct {
+	PagesListed  int `json:"pages_listed"`
+	MessagesSeen int `json:"messages_seen"`
+	// MessagesNew were not in the inbox yet.
+	MessagesNew int `json:"messages_new"`
+	// MessagesSkipped were excluded by the ingestion rules.
+	MessagesSkipped int `json:"messages_skipped"`
+	// MessagesRejected had a payload that failed validation.
+	MessagesRejected int   `json:"messages_rejected"`
+	BytesDownloaded  int64 `json:"bytes_downloaded"`
+}
+
+// SyncRun is one execution of the incremental sync of a connection.
+type SyncRun struct {
+	ID           string
+	ConnectionID string
+	Status       string
+	// Resumed runs continued from the checkpoint of an earlier one.
+	Resumed    bool
+	Stats      SyncRunStats
+	Error      string
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+func NewSyncRun(id, connectionID string, resumed bool, startedAt time.Time) (*SyncRun, error) {
+	if id == "" || connectionID == "" {
+		return nil, errors.New("sync run id and connection id are required")
+	}
+
+	return &SyncRun{
+		ID:           id,
+		ConnectionID: connectionID,
+		Resumed:      resumed,
+		StartedAt:    startedAt.UTC(),
+	}, nil
+}
+
+// Succeed closes a run that synced the whole range.
+func (r *SyncRun) Succeed(at time.Time) {
+	r.Status = SyncRunStatusSucceeded
+	r.Error = ""
+	r.FinishedAt = at.UTC()
+}
+
+// Fail closes a run that stopped early; partial tells whether the cursor
+// kept a checkpoint with progress.
+func (r *SyncRun) Fail(message string, partial bool, at time.Time) {
+	r.Status = SyncRunStatusFailed
+	if partial {
+		r.Status = SyncRunStatusPartial
+	}
+	r.Error = message
+	r.FinishedAt = at.UTC()
+}
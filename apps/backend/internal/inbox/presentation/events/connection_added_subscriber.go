@@ -2,19 +2,24 @@ package events
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"time"
 
 	awsevents "github.com/aws/aws-lambda-go/events"
 	contractevents "github.com/bowerbird/internal/contracts/events"
 	inboxCommands "github.com/bowerbird/internal/inbox/application/commands"
+	"github.com/bowerbird/internal/inbox/domain"
 	"github.com/bowerbird/internal/platform/tenant"
 )
 
 type ConnectionAddedSubscriber struct {
-	command *inboxCommands.SyncAccountCommand
+	command               *inboxCommands.SyncAccountCommand
+	startHistoricalImport *inboxCommands.StartHistoricalImportCommand
 }
 
-func NewConnectionAddedSubscriber(command *inboxCommands.SyncAccountCommand) *ConnectionAddedSubscriber {
-	return &ConnectionAddedSubscriber{command: command}
+func NewConnectionAddedSubscriber(command *inboxCommands.SyncAccountCommand, startHistoricalImport *inboxCommands.StartHistoricalImportCommand) *ConnectionAddedSubscriber {
+	return &ConnectionAddedSubscriber{command: command, startHistoricalImport: startHistoricalImport}
 }
 
 func (s *ConnectionAddedSubscriber) DetailType() string {
@@ -32,5 +37,38 @@ func (s *ConnectionAddedSubscriber) HandleEventBridge(ctx context.Context, event
 	}
 
 	msgCtx := tenant.WithTenantID(ctx, decoded.TenantSlug)
-	return s.command.Execute(msgCtx, inboxCommands.SyncAccountCommandInput{AccountID: decoded.ConnectionID})
+	if err := s.startBackfill(msgCtx, decoded); err != nil {
+		return err
+	}
+
+	// A (re)connect is an owner action, so it skips any back-off.
+	return s.command.Execute(msgCtx, inboxCommands.SyncAccountCommandInput{AccountID: decoded.ConnectionID, Force: true})
+}
+
+// startBackfill starts the import of the range the owner picked while
+// connecting. It runs before the first sync so a failing sync does not lose
+// it; a redelivered event finds the import already in progress.
+func (s *ConnectionAddedSubscriber) startBackfill(ctx context.Context, event contractevents.ConnectionAdded) error {
+	if event.BackfillSince == "" || s.startHistoricalImport == nil {
+		return nil
+	}
+
+	since, err := time.Parse(time.DateOnly, event.BackfillSince)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.startHistoricalImport.Execute(ctx, inboxCommands.StartHistoricalImportInput{
+		ConnectionID: event.ConnectionID,
+		Since:        since,
+	})
+	if errors.Is(err, domain.ErrHistoricalImportInProgress) {
+		return nil
+	}
+	if errors.Is(err, domain.ErrInvalidHistoricalImportRange) {
+		slog.Warn("backfill range rejected", "tenant_id", event.TenantSlug, "connection_id", event.ConnectionID, "since", event.BackfillSince, "error", err)
+		return nil
+	}
+
+	return err
 }
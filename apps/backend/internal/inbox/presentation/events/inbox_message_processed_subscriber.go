@@ -0,0 +1,40 @@
+package events
+
+import (
+	"context"
+
+	awsevents "github.com/aws/aws-lambda-go/events"
+	contractevents "github.com/bowerbird/internal/contracts/events"
+	inboxCommands "github.com/bowerbird/internal/inbox/application/commands"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+type InboxMessageProcessedSubscriber struct {
+	command *inboxCommands.WriteBackMessageCommand
+}
+
+func NewInboxMessageProcessedSubscriber(command *inboxCommands.WriteBackMessageCommand) *InboxMessageProcessedSubscriber {
+	return &InboxMessageProcessedSubscriber{command: command}
+}
+
+func (s *InboxMessageProcessedSubscriber) DetailType() string {
+	return contractevents.InboxMessageProcessedDetailType
+}
+
+func (s *InboxMessageProcessedSubscriber) HandleEventBridge(ctx context.Context, event awsevents.CloudWatchEvent) error {
+	if s.command == nil {
+		return nil
+	}
+
+	decoded, err := contractevents.UnmarshalInboxMessageProcessed(event.Detail)
+	if err != nil {
+		return err
+	}
+
+	msgCtx := tenant.WithTenantID(ctx, decoded.TenantSlug)
+	return s.command.Execute(msgCtx, inboxCommands.WriteBackMessageInput{
+		ConnectionID:      decoded.ConnectionID,
+		ProviderMessageID: decoded.ProviderMessageID,
+		Failed:            decoded.Outcome == contractevents.InboxMessageOutcomeFailed,
+	})
+}
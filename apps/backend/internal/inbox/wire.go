@@ -1,12 +1,13 @@
 package inbox
 
 import (
+	"context"
 	"net/http"
 
 	connectionsApp "github.com/bowerbird/internal/connections/application"
 	httpV1 "github.com/bowerbird/internal/inbox/adapters/http/v1"
+	inboxJobs "github.com/bowerbird/internal/inbox/adapters/jobs"
 	"github.com/bowerbird/internal/inbox/adapters/provider"
-	"github.com/bowerbird/internal/inbox/adapters/provider/gmail"
 	inboxRepo "github.com/bowerbird/internal/inbox/adapters/repository/postgres"
 	"github.com/bowerbird/internal/inbox/application"
 	"github.com/bowerbird/internal/inbox/application/commands"
@@ -15,6 +16,7 @@ import (
 	"github.com/bowerbird/internal/platform/config"
 	"github.com/bowerbird/internal/platform/database"
 	"github.com/bowerbird/internal/platform/events"
+	platformJobs "github.com/bowerbird/internal/platform/jobs"
 	platformStorage "github.com/bowerbird/internal/platform/storage"
 )
 
@@ -23,20 +25,30 @@ func NewApplication(
 	connectionsService connectionsApp.InternalService,
 	eventBus events.EventBus,
 	fileStore platformStorage.FileStore,
+	fileDeleter platformStorage.FileDeleter,
+	jobQueue platformJobs.Queue,
 	registry *database.Registry,
 ) *application.Application {
 	if connectionsService == nil {
 		panic("connections internal service is required")
 	}
 
+	if jobQueue == nil {
+		panic("job queue is required")
+	}
+
 	if registry == nil {
 		panic("database registry is required")
 	}
 
 	inboxRepository := inboxRepo.NewPostgresRepository(registry)
+	historicalImportScheduler := inboxJobs.NewQueueHistoricalImportScheduler(jobQueue)
 
 	var syncAccountCommand *commands.SyncAccountCommand
 	var syncAllAccountsCommand *commands.SyncAllAccountsCommand
+	var writeBackMessageCommand *commands.WriteBackMessageCommand
+	var startHistoricalImportCommand *commands.StartHistoricalImportCommand
+	var runHistoricalImportChunkCommand *commands.RunHistoricalImportChunkCommand
 
 	if cfg.GoogleClientID != "" && cfg.GoogleClientSecret != "" {
 		if eventBus == nil {
@@ -47,37 +59,72 @@ func NewApplication(
 			panic("file store is required for inbox sync")
 		}
 
-		providerFactory := provider.NewDefaultFactory(gmail.OAuthConfig{
-			ClientID:     cfg.GoogleClientID,
-			ClientSecret: cfg.GoogleClientSecret,
-		})
+		providerFactory := provider.NewDefaultFactory()
 
 		syncAccountCommand = commands.NewSyncAccountCommand(
+			inboxRepository,
 			inboxRepository,
 			inboxRepository,
 			connectionsService,
 			providerFactory,
 			eventBus,
 			fileStore,
-		)
+		).
+			WithInitialSyncDays(cfg.InboxInitialSyncDays).
+			WithMessageConcurrency(cfg.InboxSyncConcurrency)
 
 		syncAccountJobDispatcher := commands.NewInlineSyncAccountJobDispatcher(syncAccountCommand)
 		syncAllAccountsCommand = commands.NewSyncAllAccountsCommand(connectionsService, syncAccountJobDispatcher)
+		writeBackMessageCommand = commands.NewWriteBackMessageCommand(inboxRepository, connectionsService, providerFactory)
+		startHistoricalImportCommand = commands.NewStartHistoricalImportCommand(inboxRepository, connectionsService, historicalImportScheduler)
+		runHistoricalImportChunkCommand = commands.NewRunHistoricalImportChunkCommand(
+			inboxRepository,
+			inboxRepository,
+			connectionsService,
+			providerFactory,
+			syncAccountCommand,
+			historicalImportScheduler,
+		)
 	}
 
 	return &application.Application{
 		Commands: application.Commands{
-			SyncAccount:     syncAccountCommand,
-			SyncAllAccounts: syncAllAccountsCommand,
+			SyncAccount:      syncAccountCommand,
+			SyncAllAccounts:  syncAllAccountsCommand,
+			WriteBackMessage: writeBackMessageCommand,
+
+			StartHistoricalImport:    startHistoricalImportCommand,
+			RunHistoricalImportChunk: runHistoricalImportChunkCommand,
+
+			PurgeConnectionMessages: commands.NewPurgeConnectionMessagesCommand(inboxRepository, fileDeleter),
 		},
 		Queries: application.Queries{
 			ListAccountHealth: queries.NewListAccountHealthQuery(inboxRepository, connectionsService),
-			ListMessages:      queries.NewListMessagesQuery(inboxRepository),
-			GetMessage:        queries.NewGetMessageQuery(inboxRepository),
+			ListMessages:      queries.NewListMessagesQuery(inboxRepository, connectionsService),
+			GetMessage:        queries.NewGetMessageQuery(inboxRepository, connectionsService),
+
+			ListHistoricalImports: queries.NewListHistoricalImportsQuery(inboxRepository, connectionsService),
+			ListSyncRuns:          queries.NewListSyncRunsQuery(inboxRepository, connectionsService),
 		},
 	}
 }
 
+type internalService struct {
+	app *application.Application
+}
+
+func NewInternalService(app *application.Application) application.InternalService {
+	if app == nil {
+		panic("inbox application is required")
+	}
+
+	return &internalService{app: app}
+}
+
+func (s *internalService) PurgeConnectionMessages(ctx context.Context, connectionID string) (application.MessagePurgeResult, error) {
+	return s.app.Commands.PurgeConnectionMessages.Execute(ctx, connectionID)
+}
+
 func NewHTTPHandler(mux *http.ServeMux, app *application.Application, authMiddleware func(http.Handler) http.Handler, cfg config.Config) *httpV1.Router {
 	if mux == nil {
 		panic("http mux is required")
@@ -92,6 +139,9 @@ func NewHTTPHandler(mux *http.ServeMux, app *application.Application, authMiddle
 		app.Queries.ListMessages,
 		app.Queries.GetMessage,
 		app.Commands.SyncAllAccounts,
+		app.Commands.StartHistoricalImport,
+		app.Queries.ListHistoricalImports,
+		app.Queries.ListSyncRuns,
 	)
 	handler := httpV1.NewRouter(controller)
 	handler.Register(mux, cfg, authMiddleware)
@@ -104,5 +154,27 @@ func NewConnectionAddedSubscriber(app *application.Application) *eventsV1.Connec
 		panic("inbox application is required")
 	}
 
-	return eventsV1.NewConnectionAddedSubscriber(app.Commands.SyncAccount)
+	return eventsV1.NewConnectionAddedSubscriber(app.Commands.SyncAccount, app.Commands.StartHistoricalImport)
+}
+
+func NewInboxMessageProcessedSubscriber(app *application.Application) *eventsV1.InboxMessageProcessedSubscriber {
+	if app == nil {
+		panic("inbox application is required")
+	}
+
+	return eventsV1.NewInboxMessageProcessedSubscriber(app.Commands.WriteBackMessage)
+}
+
+// NewHistoricalImportProcessor returns nil while inbox sync is not
+// configured; the job handler skips nil processors.
+func NewHistoricalImportProcessor(app *application.Application) platformJobs.SQSProcessor {
+	if app == nil {
+		panic("inbox application is required")
+	}
+
+	if app.Commands.RunHistoricalImportChunk == nil {
+		return nil
+	}
+
+	return inboxJobs.NewHistoricalImportChunkRequestedProcessor(app.Commands.RunHistoricalImportChunk)
 }
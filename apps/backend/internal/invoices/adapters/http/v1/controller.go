@@ -2,10 +2,12 @@ package v1
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/bowerbird/internal/invoices/application"
 	"github.com/bowerbird/internal/invoices/application/commands"
+	"github.com/bowerbird/internal/invoices/domain"
 	appErrors "github.com/bowerbird/internal/platform/errors"
 	"github.com/bowerbird/internal/platform/http/api"
 )
@@ -52,3 +54,29 @@ func (c *Controller) QueueInvoiceExtractionFromUploadedFiles(w http.ResponseWrit
 	resp := newQueueInvoiceExtractionResponse(result)
 	return api.Success(w, http.StatusAccepted, resp)
 }
+
+func (c *Controller) ListInvoices(w http.ResponseWriter, r *http.Request) error {
+	filter, err := parseInvoiceFilter(r.URL.Query())
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, err.Error())
+	}
+
+	page, err := c.app.Queries.ListInvoices.Execute(r.Context(), filter)
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to list invoices")
+	}
+
+	return api.Success(w, http.StatusOK, newInvoicesResponse(page))
+}
+
+func (c *Controller) GetInvoice(w http.ResponseWriter, r *http.Request) error {
+	detail, err := c.app.Queries.GetInvoiceByID.Execute(r.Context(), r.PathValue("id"))
+	if errors.Is(err, domain.ErrInvoiceNotFound) {
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "invoice not found")
+	}
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to get invoice")
+	}
+
+	return api.Success(w, http.StatusOK, newInvoiceResponse(detail))
+}
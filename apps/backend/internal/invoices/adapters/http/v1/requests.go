@@ -2,8 +2,12 @@ package v1
 
 import (
 	"fmt"
+	"net/url"
 	"path"
+	"strconv"
 	"strings"
+
+	"github.com/bowerbird/internal/invoices/application/ports"
 )
 
 const queueInvoiceExtractionDataType = "queue-invoice-extraction"
@@ -116,3 +120,17 @@ func validateFileExtensionMatchesType(value, expectedType string) error {
 
 	return nil
 }
+
+func parseInvoiceFilter(values url.Values) (ports.InvoiceFilter, error) {
+	filter := ports.InvoiceFilter{Cursor: values.Get("cursor")}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return ports.InvoiceFilter{}, fmt.Errorf("limit must be a positive integer")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
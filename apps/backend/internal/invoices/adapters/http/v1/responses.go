@@ -1,6 +1,12 @@
 package v1
 
-import "github.com/bowerbird/internal/invoices/application/commands"
+import (
+	"time"
+
+	"github.com/bowerbird/internal/invoices/application/commands"
+	"github.com/bowerbird/internal/invoices/application/queries"
+	"github.com/bowerbird/internal/invoices/domain"
+)
 
 type jsonApiResponse[T any] struct {
 	Data jsonApiDocument[T] `json:"data"`
@@ -31,3 +37,92 @@ func newQueueInvoiceExtractionResponse(result *commands.QueueInvoiceExtractionFr
 		},
 	}
 }
+
+const invoiceDataType = "invoice"
+
+type invoiceAttributes struct {
+	CUFE             string             `json:"cufe"`
+	InvoiceNumber    string             `json:"invoice_number"`
+	IssuerName       string             `json:"issuer_name"`
+	IssuerTaxID      string             `json:"issuer_tax_id"`
+	ReceiverName     string             `json:"receiver_name"`
+	ReceiverTaxID    string             `json:"receiver_tax_id"`
+	CurrencyCode     string             `json:"currency_code"`
+	IssueDate        *time.Time         `json:"issue_date,omitempty"`
+	DueDate          *time.Time         `json:"due_date,omitempty"`
+	PaymentCode      string             `json:"payment_code"`
+	Subtotal         float64            `json:"subtotal"`
+	TaxTotal         float64            `json:"tax_total"`
+	GrandTotal       float64            `json:"grand_total"`
+	DocumentKey      string             `json:"document_key,omitempty"`
+	ExtractionSource string             `json:"extraction_source"`
+	SourceMessageID  string             `json:"source_message_id,omitempty"`
+	Lines            []invoiceLineEntry `json:"lines,omitempty"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+type invoiceLineEntry struct {
+	LineNumber   int     `json:"line_number"`
+	ItemCode     string  `json:"item_code"`
+	Description  string  `json:"description"`
+	Quantity     float64 `json:"quantity"`
+	UnitPrice    float64 `json:"unit_price"`
+	LineTaxTotal float64 `json:"line_tax_total"`
+	LineTotal    float64 `json:"line_total"`
+}
+
+type invoicesResponse struct {
+	Data       []jsonApiDocument[invoiceAttributes] `json:"data"`
+	NextCursor string                               `json:"next_cursor,omitempty"`
+}
+
+func newInvoiceDocument(header domain.InvoiceHeaderRecord) jsonApiDocument[invoiceAttributes] {
+	return jsonApiDocument[invoiceAttributes]{
+		Type: invoiceDataType,
+		ID:   header.ID,
+		Attributes: invoiceAttributes{
+			CUFE:             header.CUFE,
+			InvoiceNumber:    header.InvoiceNumber,
+			IssuerName:       header.IssuerName,
+			IssuerTaxID:      header.IssuerTaxID,
+			ReceiverName:     header.ReceiverName,
+			ReceiverTaxID:    header.ReceiverTaxID,
+			CurrencyCode:     header.CurrencyCode,
+			IssueDate:        header.IssueDate,
+			DueDate:          header.DueDate,
+			PaymentCode:      header.PaymentCode,
+			Subtotal:         header.Subtotal,
+			TaxTotal:         header.TaxTotal,
+			GrandTotal:       header.GrandTotal,
+			DocumentKey:      header.DocumentRefS3Key,
+			ExtractionSource: header.ExtractionSource,
+			SourceMessageID:  header.SourceMessageID,
+			CreatedAt:        header.CreatedAt,
+		},
+	}
+}
+
+func newInvoicesResponse(page *queries.InvoicePage) invoicesResponse {
+	resp := invoicesResponse{Data: make([]jsonApiDocument[invoiceAttributes], 0, len(page.Invoices)), NextCursor: page.NextCursor}
+	for _, header := range page.Invoices {
+		resp.Data = append(resp.Data, newInvoiceDocument(header))
+	}
+	return resp
+}
+
+func newInvoiceResponse(detail *queries.InvoiceDetail) jsonApiResponse[invoiceAttributes] {
+	doc := newInvoiceDocument(*detail.Header)
+	doc.Attributes.Lines = make([]invoiceLineEntry, 0, len(detail.Lines))
+	for _, line := range detail.Lines {
+		doc.Attributes.Lines = append(doc.Attributes.Lines, invoiceLineEntry{
+			LineNumber:   line.LineNumber,
+			ItemCode:     line.ItemCode,
+			Description:  line.Description,
+			Quantity:     line.Quantity,
+			UnitPrice:    line.UnitPrice,
+			LineTaxTotal: line.LineTaxTotal,
+			LineTotal:    line.LineTotal,
+		})
+	}
+	return jsonApiResponse[invoiceAttributes]{Data: doc}
+}
@@ -3,10 +3,18 @@ package v1
 import (
 	"net/http"
 
+	"github.com/bowerbird/internal/platform/auth"
 	"github.com/bowerbird/internal/platform/config"
 	"github.com/bowerbird/internal/platform/http/api"
 )
 
+const (
+	// InvoicesReadPermission is the RBAC code required to list and fetch invoices.
+	InvoicesReadPermission = "invoices:read"
+	// InvoicesWritePermission is the RBAC code required to queue extractions.
+	InvoicesWritePermission = "invoices:write"
+)
+
 type Router struct {
 	controller *Controller
 }
@@ -20,5 +28,12 @@ func NewRouter(controller *Controller) *Router {
 }
 
 func (h *Router) Register(mux *http.ServeMux, cfg config.Config, authMiddleware func(http.Handler) http.Handler) {
-	mux.Handle("POST /api/v1/invoicing/extractions", authMiddleware(api.Wrap(h.controller.QueueInvoiceExtractionFromUploadedFiles, cfg)))
+	// Members keep their session access; API keys and tenant-scoped tokens
+	// need the permission.
+	requireRead := auth.RequirePermission(InvoicesReadPermission, auth.AllowSessionTokens())
+	requireWrite := auth.RequirePermission(InvoicesWritePermission, auth.AllowSessionTokens())
+
+	mux.Handle("POST /api/v1/invoicing/extractions", authMiddleware(requireWrite(api.Wrap(h.controller.QueueInvoiceExtractionFromUploadedFiles, cfg))))
+	mux.Handle("GET /api/v1/invoicing/invoices", authMiddleware(requireRead(api.Wrap(h.controller.ListInvoices, cfg))))
+	mux.Handle("GET /api/v1/invoicing/invoices/{id}", authMiddleware(requireRead(api.Wrap(h.controller.GetInvoice, cfg))))
 }
@@ -0,0 +1,136 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/invoices/application"
+	"github.com/bowerbird/internal/invoices/application/commands"
+	"github.com/bowerbird/internal/invoices/application/ports"
+	"github.com/bowerbird/internal/invoices/application/queries"
+	"github.com/bowerbird/internal/invoices/domain"
+	"github.com/bowerbird/internal/platform/audit"
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/bowerbird/internal/platform/config"
+	"github.com/bowerbird/internal/platform/jobs"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+type stubAPIKeys struct {
+	scopes []string
+}
+
+func (s stubAPIKeys) AuthenticateAPIKey(context.Context, string) (*auth.CustomClaims, error) {
+	return &auth.CustomClaims{
+		UserID:       "user-1",
+		APIKeyID:     "key-1",
+		TenantClaims: auth.TenantClaims{TenantID: "tenant-1", TenantSlug: "acme", Permissions: s.scopes},
+	}, nil
+}
+
+type memoryInvoices struct {
+	invoices []domain.InvoiceHeaderRecord
+	tenantID string
+}
+
+func (r *memoryInvoices) ListInvoices(ctx context.Context, filter ports.InvoiceFilter) ([]domain.InvoiceHeaderRecord, error) {
+	r.tenantID, _ = tenant.TenantIDFromContext(ctx)
+	return r.invoices, nil
+}
+
+func (r *memoryInvoices) GetInvoice(ctx context.Context, id string) (*domain.InvoiceHeaderRecord, []domain.InvoiceLineRecord, error) {
+	for _, invoice := range r.invoices {
+		if invoice.ID == id {
+			return &invoice, []domain.InvoiceLineRecord{{LineNumber: 1, Description: "Papelería", LineTotal: 100}}, nil
+		}
+	}
+	return nil, nil, domain.ErrInvoiceNotFound
+}
+
+type queueSpy struct {
+	jobs []jobs.Job
+}
+
+func (q *queueSpy) Dispatch(ctx context.Context, job jobs.Job) error {
+	q.jobs = append(q.jobs, job)
+	return nil
+}
+
+type recorderStub struct{}
+
+func (recorderStub) Record(context.Context, audit.Event) error { return nil }
+
+func newKeyMux(t *testing.T, repo *memoryInvoices, queue *queueSpy, scopes ...string) *http.ServeMux {
+	t.Helper()
+
+	app := &application.Application{
+		Commands: application.Commands{
+			QueueInvoiceExtractionFromFiles: commands.NewQueueInvoiceExtractionFromFilesCommand(queue, recorderStub{}),
+		},
+		Queries: application.Queries{
+			ListInvoices:   queries.NewListInvoicesQuery(repo),
+			GetInvoiceByID: queries.NewGetInvoiceByIDQuery(repo),
+		},
+	}
+	gen := auth.NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	mux := http.NewServeMux()
+	NewRouter(NewController(app)).Register(mux, config.Config{}, auth.Middleware(gen, auth.WithAPIKeys(stubAPIKeys{scopes: scopes})))
+	return mux
+}
+
+func serveWithKey(mux *http.ServeMux, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer bbk_key-1_secret")
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKeyListsAndFetchesInvoices(t *testing.T) {
+	repo := &memoryInvoices{invoices: []domain.InvoiceHeaderRecord{{ID: "inv-1", CUFE: "cufe-1", GrandTotal: 119}}}
+	mux := newKeyMux(t, repo, &queueSpy{}, InvoicesReadPermission)
+
+	rec := serveWithKey(mux, http.MethodGet, "/api/v1/invoicing/invoices?limit=10", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 listing invoices, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list invoicesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].ID != "inv-1" || repo.tenantID != "tenant-1" {
+		t.Fatalf("expected the key's tenant invoices, got %+v in %q", list.Data, repo.tenantID)
+	}
+
+	rec = serveWithKey(mux, http.MethodGet, "/api/v1/invoicing/invoices/inv-1", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"Papelería"`) {
+		t.Fatalf("expected the invoice with its lines, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := serveWithKey(mux, http.MethodGet, "/api/v1/invoicing/invoices/missing", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown invoice, got %d", rec.Code)
+	}
+}
+
+func TestAPIKeyQueuesExtractionOnlyWithWriteScope(t *testing.T) {
+	body := `{"data":{"type":"queue-invoice-extraction","attributes":{"files":[{"name":"a.xml","path":"uploads/a.xml","mime_type":"application/xml"}]}}}`
+
+	queue := &queueSpy{}
+	readOnly := newKeyMux(t, &memoryInvoices{}, queue, InvoicesReadPermission)
+	if rec := serveWithKey(readOnly, http.MethodPost, "/api/v1/invoicing/extractions", body); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without invoices:write, got %d", rec.Code)
+	}
+
+	writer := newKeyMux(t, &memoryInvoices{}, queue, InvoicesWritePermission)
+	if rec := serveWithKey(writer, http.MethodPost, "/api/v1/invoicing/extractions", body); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 with invoices:write, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(queue.jobs) != 1 {
+		t.Fatalf("expected one queued extraction, got %d", len(queue.jobs))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	invoicingCommands "github.com/bowerbird/internal/invoices/application/commands"
 	contractJobs "github.com/bowerbird/internal/invoices/contracts/jobs"
 	"github.com/bowerbird/internal/invoices/domain"
+	platformEvents "github.com/bowerbird/internal/platform/events"
 	platformStorage "github.com/bowerbird/internal/platform/storage"
 	"github.com/bowerbird/internal/platform/tenant"
 )
@@ -66,6 +67,12 @@ func (e *processorXMLExtractor) ParseInvoiceXML(data []byte) (*domain.InvoiceDoc
 	}, nil
 }
 
+type processorEventBus struct{}
+
+func (b *processorEventBus) Publish(ctx context.Context, event platformEvents.BusinessEvent) error {
+	return nil
+}
+
 type processorLLMExtractor struct{}
 
 func (e *processorLLMExtractor) ExtractFromPDF(ctx context.Context, pdfData []byte) (*domain.InvoiceDocument, error) {
@@ -73,7 +80,7 @@ func (e *processorLLMExtractor) ExtractFromPDF(ctx context.Context, pdfData []by
 }
 
 func TestProcessInvoiceExtractionRequestedHandlesMessage(t *testing.T) {
-	cmd := invoicingCommands.NewProcessInvoiceExtractionJobCommand(&processorFileStore{}, &processorXMLExtractor{}, &processorLLMExtractor{}, &processorRepo{})
+	cmd := invoicingCommands.NewProcessInvoiceExtractionJobCommand(&processorFileStore{}, &processorXMLExtractor{}, &processorLLMExtractor{}, &processorRepo{}, &processorEventBus{})
 	processor := NewProcessInvoiceExtractionRequested(cmd)
 
 	detail, err := contractJobs.MarshalInvoiceExtractionRequested(contractJobs.InvoiceExtractionRequested{
@@ -95,7 +102,7 @@ func TestProcessInvoiceExtractionRequestedHandlesMessage(t *testing.T) {
 }
 
 func TestProcessInvoiceExtractionRequestedRequiresTenantInContext(t *testing.T) {
-	cmd := invoicingCommands.NewProcessInvoiceExtractionJobCommand(&processorFileStore{}, &processorXMLExtractor{}, &processorLLMExtractor{}, &processorRepo{})
+	cmd := invoicingCommands.NewProcessInvoiceExtractionJobCommand(&processorFileStore{}, &processorXMLExtractor{}, &processorLLMExtractor{}, &processorRepo{}, &processorEventBus{})
 	processor := NewProcessInvoiceExtractionRequested(cmd)
 
 	detail, err := contractJobs.MarshalInvoiceExtractionRequested(contractJobs.InvoiceExtractionRequested{
@@ -2,11 +2,13 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/bowerbird/internal/invoices/application/ports"
 	"github.com/bowerbird/internal/invoices/domain"
 	"github.com/bowerbird/internal/platform/database"
+	"github.com/jackc/pgx/v5"
 )
 
 type PostgresRepository struct {
@@ -142,4 +144,106 @@ func (r *PostgresRepository) PersistInvoiceAtomic(ctx context.Context, header do
 	return nil
 }
 
-var _ ports.InvoiceRepository = (*PostgresRepository)(nil)
+const invoiceHeaderColumns = `
+	id, COALESCE(source_message_id, ''), cufe, COALESCE(invoice_number, ''),
+	COALESCE(issuer_name, ''), COALESCE(issuer_tax_id, ''), COALESCE(receiver_name, ''), COALESCE(receiver_tax_id, ''),
+	COALESCE(currency_code, ''), issue_date, due_date, COALESCE(payment_code, ''),
+	COALESCE(subtotal, 0), COALESCE(tax_total, 0), COALESCE(grand_total, 0),
+	COALESCE(document_ref_s3_key, ''), extraction_source, created_at, updated_at`
+
+func scanInvoiceHeader(row pgx.Row) (domain.InvoiceHeaderRecord, error) {
+	var h domain.InvoiceHeaderRecord
+	err := row.Scan(
+		&h.ID, &h.SourceMessageID, &h.CUFE, &h.InvoiceNumber,
+		&h.IssuerName, &h.IssuerTaxID, &h.ReceiverName, &h.ReceiverTaxID,
+		&h.CurrencyCode, &h.IssueDate, &h.DueDate, &h.PaymentCode,
+		&h.Subtotal, &h.TaxTotal, &h.GrandTotal,
+		&h.DocumentRefS3Key, &h.ExtractionSource, &h.CreatedAt, &h.UpdatedAt,
+	)
+	return h, err
+}
+
+// ListInvoices pages by ID, which as a ULID follows creation order.
+func (r *PostgresRepository) ListInvoices(ctx context.Context, filter ports.InvoiceFilter) ([]domain.InvoiceHeaderRecord, error) {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant db pool: %w", err)
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT `+invoiceHeaderColumns+`
+		FROM invoice_headers
+		WHERE $1 = '' OR id < $1
+		ORDER BY id DESC
+		LIMIT $2
+	`, filter.Cursor, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("list invoices: %w", err)
+	}
+	defer rows.Close()
+
+	var invoices []domain.InvoiceHeaderRecord
+	for rows.Next() {
+		header, err := scanInvoiceHeader(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan invoice: %w", err)
+		}
+		invoices = append(invoices, header)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating invoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+func (r *PostgresRepository) GetInvoice(ctx context.Context, id string) (*domain.InvoiceHeaderRecord, []domain.InvoiceLineRecord, error) {
+	pool, err := r.registry.GetPool(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get tenant db pool: %w", err)
+	}
+
+	header, err := scanInvoiceHeader(pool.QueryRow(ctx, `SELECT `+invoiceHeaderColumns+` FROM invoice_headers WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, domain.ErrInvoiceNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("get invoice: %w", err)
+	}
+
+	rows, err := pool.Query(ctx, `
+		SELECT id, invoice_header_id, line_number, COALESCE(item_code, ''), COALESCE(description, ''),
+			COALESCE(quantity, 0), COALESCE(unit_price, 0), COALESCE(line_tax_total, 0), COALESCE(line_total, 0),
+			created_at, updated_at
+		FROM invoice_lines
+		WHERE invoice_header_id = $1
+		ORDER BY line_number
+	`, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list invoice lines: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []domain.InvoiceLineRecord
+	for rows.Next() {
+		var l domain.InvoiceLineRecord
+		if err := rows.Scan(
+			&l.ID, &l.InvoiceHeaderID, &l.LineNumber, &l.ItemCode, &l.Description,
+			&l.Quantity, &l.UnitPrice, &l.LineTaxTotal, &l.LineTotal,
+			&l.CreatedAt, &l.UpdatedAt,
+		); err != nil {
+			return nil, nil, fmt.Errorf("scan invoice line: %w", err)
+		}
+		lines = append(lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating invoice lines: %w", err)
+	}
+
+	return &header, lines, nil
+}
+
+var (
+	_ ports.InvoiceRepository     = (*PostgresRepository)(nil)
+	_ ports.InvoiceReadRepository = (*PostgresRepository)(nil)
+)
@@ -13,9 +13,11 @@ import (
 	"github.com/bowerbird/internal/invoices/application/ports"
 	contractJobs "github.com/bowerbird/internal/invoices/contracts/jobs"
 	"github.com/bowerbird/internal/invoices/domain"
+	platformEvents "github.com/bowerbird/internal/platform/events"
 	"github.com/bowerbird/internal/platform/id"
 	"github.com/bowerbird/internal/platform/jobs"
 	platformStorage "github.com/bowerbird/internal/platform/storage"
+	"github.com/bowerbird/internal/platform/tenant"
 )
 
 type CreateInvoicesFromInboxMessageCommand struct {
@@ -34,6 +36,9 @@ func NewCreateInvoicesFromInboxMessageCommand(jobQueue jobs.Queue) *CreateInvoic
 	}
 }
 
+// Execute queues the extraction of a message that looks like an invoice.
+// Other messages are not reported, so the mailbox write-back leaves them
+// alone.
 func (cmd *CreateInvoicesFromInboxMessageCommand) Execute(ctx context.Context, event contractEvents.InboxMessageReceived) error {
 	if !hasInvoiceKeyword(event.Subject, event.Body) {
 		cmd.logger.Info("invoicing event skipped: missing invoice keyword", "tenant_slug", event.TenantID, "message_id", event.MessageInternalID)
@@ -55,6 +60,7 @@ func (cmd *CreateInvoicesFromInboxMessageCommand) Execute(ctx context.Context, e
 		Source:   "inbox-message",
 		Files:    mapAttachmentRefs(event.AttachmentRefs),
 		QueuedAt: cmd.now().UTC().Format(time.RFC3339Nano),
+		Message:  sourceMessageOf(event),
 	}
 
 	payload, err := contractJobs.MarshalInvoiceExtractionRequested(job)
@@ -74,6 +80,15 @@ func (cmd *CreateInvoicesFromInboxMessageCommand) Execute(ctx context.Context, e
 	return nil
 }
 
+func sourceMessageOf(event contractEvents.InboxMessageReceived) *contractJobs.SourceMessage {
+	return &contractJobs.SourceMessage{
+		ConnectionID:      event.AccountID,
+		Provider:          event.Provider,
+		ProviderMessageID: event.ProviderMessageID,
+		MessageInternalID: event.MessageInternalID,
+	}
+}
+
 type ProcessInvoiceExtractionJobStatus string
 
 const (
@@ -105,7 +120,10 @@ type ProcessInvoiceExtractionJobCommand struct {
 	llmExtractor ports.InvoiceLLMExtractor
 	repo         ports.InvoiceRepository
 	create       *CreateInvoiceCommand
+	eventBus     platformEvents.EventBus
 	logger       *slog.Logger
+	now          func() time.Time
+	newID        func() string
 }
 
 func NewProcessInvoiceExtractionJobCommand(
@@ -113,6 +131,7 @@ func NewProcessInvoiceExtractionJobCommand(
 	xmlExtractor ports.InvoiceXMLExtractor,
 	llmExtractor ports.InvoiceLLMExtractor,
 	repo ports.InvoiceRepository,
+	eventBus platformEvents.EventBus,
 ) *ProcessInvoiceExtractionJobCommand {
 	if fileStore == nil {
 		panic("file store is required")
@@ -126,6 +145,9 @@ func NewProcessInvoiceExtractionJobCommand(
 	if repo == nil {
 		panic("invoice repository is required")
 	}
+	if eventBus == nil {
+		panic("event bus is required")
+	}
 
 	return &ProcessInvoiceExtractionJobCommand{
 		fileStore:    fileStore,
@@ -134,11 +156,37 @@ func NewProcessInvoiceExtractionJobCommand(
 		llmExtractor: llmExtractor,
 		repo:         repo,
 		create:       NewCreateInvoiceCommand(repo),
+		eventBus:     eventBus,
 		logger:       slog.Default(),
+		now:          time.Now,
+		newID:        id.NewULID,
 	}
 }
 
+// Execute extracts the invoice and, for files from an inbox message, reports
+// the outcome so it can be written back to the mailbox.
 func (cmd *ProcessInvoiceExtractionJobCommand) Execute(ctx context.Context, input contractJobs.InvoiceExtractionRequested) (*ProcessInvoiceExtractionJobResult, error) {
+	result, err := cmd.process(ctx, input)
+	if err != nil || input.Message == nil {
+		return result, err
+	}
+
+	outcome, ok := outcomeOf(result)
+	if !ok {
+		return result, nil
+	}
+	tenantSlug, err := tenant.TenantIDFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := publishMessageOutcome(ctx, cmd.eventBus, tenantSlug, cmd.newID(), cmd.now(), *input.Message, outcome); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (cmd *ProcessInvoiceExtractionJobCommand) process(ctx context.Context, input contractJobs.InvoiceExtractionRequested) (*ProcessInvoiceExtractionJobResult, error) {
 	attachments, err := cmd.downloadAttachments(ctx, input.Files)
 	if err != nil {
 		return nil, err
@@ -10,8 +10,10 @@ import (
 	contractevents "github.com/bowerbird/internal/contracts/events"
 	contractJobs "github.com/bowerbird/internal/invoices/contracts/jobs"
 	"github.com/bowerbird/internal/invoices/domain"
+	platformEvents "github.com/bowerbird/internal/platform/events"
 	"github.com/bowerbird/internal/platform/jobs"
 	platformStorage "github.com/bowerbird/internal/platform/storage"
+	"github.com/bowerbird/internal/platform/tenant"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +27,27 @@ func (p *fakeBusinessPublisher) Dispatch(ctx context.Context, job jobs.Job) erro
 	return nil
 }
 
+type fakeEventBus struct {
+	published []platformEvents.BusinessEvent
+}
+
+func (b *fakeEventBus) Publish(ctx context.Context, event platformEvents.BusinessEvent) error {
+	b.published = append(b.published, event)
+	return nil
+}
+
+func (b *fakeEventBus) processedEvents(t *testing.T) []contractevents.InboxMessageProcessed {
+	t.Helper()
+	var decoded []contractevents.InboxMessageProcessed
+	for _, event := range b.published {
+		require.Equal(t, contractevents.InboxMessageProcessedDetailType, event.DetailType)
+		processed, err := contractevents.UnmarshalInboxMessageProcessed(event.Detail)
+		require.NoError(t, err)
+		decoded = append(decoded, processed)
+	}
+	return decoded
+}
+
 func TestCheckQueuesInvoiceExtractionJob(t *testing.T) {
 	publisher := &fakeBusinessPublisher{}
 	uc := NewCreateInvoicesFromInboxMessageCommand(publisher)
@@ -49,6 +72,13 @@ func TestCheckQueuesInvoiceExtractionJob(t *testing.T) {
 	var queued contractJobs.InvoiceExtractionRequested
 	require.NoError(t, json.Unmarshal(publisher.jobs[0].Payload, &queued))
 	assert.Equal(t, "inbox-message", queued.Source)
+	require.NotNil(t, queued.Message)
+	assert.Equal(t, contractJobs.SourceMessage{
+		ConnectionID:      "acc_1",
+		Provider:          "gmail",
+		ProviderMessageID: "provider_msg_1",
+		MessageInternalID: "m_1",
+	}, *queued.Message)
 }
 
 func TestCheckSkipsNonCandidates(t *testing.T) {
@@ -157,7 +187,7 @@ func TestExtractSkipsWhenMessageAlreadyProcessed(t *testing.T) {
 	llmExtractor := &fakeLLMExtractor{}
 	repo := &fakeInvoiceRepo{messageProcessed: true}
 
-	uc := NewProcessInvoiceExtractionJobCommand(store, xmlExtractor, llmExtractor, repo)
+	uc := NewProcessInvoiceExtractionJobCommand(store, xmlExtractor, llmExtractor, repo, &fakeEventBus{})
 	res, err := uc.Execute(context.Background(), contractJobs.InvoiceExtractionRequested{
 		JobID:  "job-1",
 		Source: "msg-1",
@@ -178,7 +208,7 @@ func TestExtractUsesXMLFirstAndSkipsWhenCUFEExists(t *testing.T) {
 	llmExtractor := &fakeLLMExtractor{invoice: &domain.InvoiceDocument{CUFE: "LLM-CUFE"}}
 	repo := &fakeInvoiceRepo{cufeExists: true}
 
-	uc := NewProcessInvoiceExtractionJobCommand(store, xmlExtractor, llmExtractor, repo)
+	uc := NewProcessInvoiceExtractionJobCommand(store, xmlExtractor, llmExtractor, repo, &fakeEventBus{})
 	res, err := uc.Execute(context.Background(), contractJobs.InvoiceExtractionRequested{
 		JobID:  "job-1",
 		Source: "msg-1",
@@ -207,7 +237,7 @@ func TestExtractFallsBackToLLMAndReturnsReady(t *testing.T) {
 	}}
 	repo := &fakeInvoiceRepo{}
 
-	uc := NewProcessInvoiceExtractionJobCommand(store, xmlExtractor, llmExtractor, repo)
+	uc := NewProcessInvoiceExtractionJobCommand(store, xmlExtractor, llmExtractor, repo, &fakeEventBus{})
 	uc.create.newID = func() string { return "id_1" }
 	res, err := uc.Execute(context.Background(), contractJobs.InvoiceExtractionRequested{
 		JobID:  "job-1",
@@ -224,6 +254,50 @@ func TestExtractFallsBackToLLMAndReturnsReady(t *testing.T) {
 	assert.Len(t, repo.persistedHeaders, 1)
 }
 
+func TestExtractDoesNotReportMessagesWithoutInvoiceDocuments(t *testing.T) {
+	store := &fakeExtractFileStore{data: map[string][]byte{"k1": []byte("meeting notes")}}
+	eventBus := &fakeEventBus{}
+
+	uc := NewProcessInvoiceExtractionJobCommand(store, &fakeXMLExtractor{}, &fakeLLMExtractor{}, &fakeInvoiceRepo{}, eventBus)
+	ctx := tenant.WithTenantID(context.Background(), "tenant_1")
+	res, err := uc.Execute(ctx, contractJobs.InvoiceExtractionRequested{
+		JobID:   "job-1",
+		Source:  "msg-1",
+		Files:   []contractJobs.File{{Path: "k1", Filename: "factura.txt"}},
+		Message: &contractJobs.SourceMessage{ConnectionID: "acc_1", Provider: "gmail", ProviderMessageID: "provider_msg_1", MessageInternalID: "m_1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, SkipReasonNoSupportedDocument, res.SkipReason)
+	assert.Empty(t, eventBus.published)
+}
+
+func TestExtractReportsOutcomeOfInboxMessage(t *testing.T) {
+	store := &fakeExtractFileStore{data: map[string][]byte{"k1": []byte("%PDF-1.4 file")}}
+	llmExtractor := &fakeLLMExtractor{err: errors.New("model unavailable")}
+	eventBus := &fakeEventBus{}
+
+	uc := NewProcessInvoiceExtractionJobCommand(store, &fakeXMLExtractor{}, llmExtractor, &fakeInvoiceRepo{}, eventBus)
+	uc.newID = func() string { return "evt_1" }
+	ctx := tenant.WithTenantID(context.Background(), "tenant_1")
+	res, err := uc.Execute(ctx, contractJobs.InvoiceExtractionRequested{
+		JobID:  "job-1",
+		Source: "msg-1",
+		Files: []contractJobs.File{
+			{Path: "k1", Filename: "inv.pdf"},
+		},
+		Message: &contractJobs.SourceMessage{ConnectionID: "acc_1", Provider: "gmail", ProviderMessageID: "provider_msg_1", MessageInternalID: "m_1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, SkipReasonExtractionFailed, res.SkipReason)
+
+	processed := eventBus.processedEvents(t)
+	require.Len(t, processed, 1)
+	assert.Equal(t, "evt_1", processed[0].EventID)
+	assert.Equal(t, "tenant_1", processed[0].TenantSlug)
+	assert.Equal(t, "acc_1", processed[0].ConnectionID)
+	assert.Equal(t, contractevents.InboxMessageOutcomeFailed, processed[0].Outcome)
+}
+
 type fakeInvoiceWriteRepo struct {
 	called bool
 	header domain.InvoiceHeaderRecord
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	contractEvents "github.com/bowerbird/internal/contracts/events"
+	contractJobs "github.com/bowerbird/internal/invoices/contracts/jobs"
+	platformEvents "github.com/bowerbird/internal/platform/events"
+)
+
+// messageOutcome is what became of an inbox message; the inbox writes it
+// back to the mailbox.
+type messageOutcome struct {
+	Outcome         string
+	Reason          string
+	InvoiceHeaderID string
+}
+
+func publishMessageOutcome(ctx context.Context, eventBus platformEvents.EventBus, tenantSlug, eventID string, at time.Time, message contractJobs.SourceMessage, outcome messageOutcome) error {
+	payload, err := contractEvents.MarshalInboxMessageProcessed(contractEvents.InboxMessageProcessed{
+		EventID:           eventID,
+		OccurredAt:        at.UTC().Format(time.RFC3339Nano),
+		TenantSlug:        tenantSlug,
+		ConnectionID:      message.ConnectionID,
+		Provider:          message.Provider,
+		ProviderMessageID: message.ProviderMessageID,
+		MessageInternalID: message.MessageInternalID,
+		Outcome:           outcome.Outcome,
+		Reason:            outcome.Reason,
+		InvoiceHeaderID:   outcome.InvoiceHeaderID,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal inbox message processed event: %w", err)
+	}
+
+	err = eventBus.Publish(ctx, platformEvents.BusinessEvent{
+		Source:     contractEvents.InboxMessageProcessedSource,
+		DetailType: contractEvents.InboxMessageProcessedDetailType,
+		Detail:     payload,
+	})
+	if err != nil {
+		return fmt.Errorf("publish inbox message processed event: %w", err)
+	}
+
+	return nil
+}
+
+// outcomeOf maps an extraction result onto the message outcome. Duplicates
+// of an invoice already extracted were processed; a failed extraction is an
+// error. A message without invoice documents has no outcome, so it is left
+// alone in the mailbox.
+func outcomeOf(result *ProcessInvoiceExtractionJobResult) (messageOutcome, bool) {
+	switch {
+	case result.Status == ProcessInvoiceExtractionJobStatusReady:
+		return messageOutcome{Outcome: contractEvents.InboxMessageOutcomeProcessed, InvoiceHeaderID: result.HeaderID}, true
+	case result.SkipReason == SkipReasonExtractionFailed:
+		return messageOutcome{Outcome: contractEvents.InboxMessageOutcomeFailed, Reason: string(result.SkipReason)}, true
+	case result.SkipReason == SkipReasonNoSupportedDocument:
+		return messageOutcome{}, false
+	default:
+		return messageOutcome{Outcome: contractEvents.InboxMessageOutcomeProcessed, Reason: string(result.SkipReason)}, true
+	}
+}
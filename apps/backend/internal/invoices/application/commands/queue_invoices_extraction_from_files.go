@@ -5,6 +5,7 @@ import (
 	"time"
 
 	contractJobs "github.com/bowerbird/internal/invoices/contracts/jobs"
+	"github.com/bowerbird/internal/platform/audit"
 	"github.com/bowerbird/internal/platform/id"
 	"github.com/bowerbird/internal/platform/jobs"
 )
@@ -26,17 +27,22 @@ type QueueInvoiceExtractionFromFilesResult struct {
 
 type QueueInvoiceExtractionFromFilesCommand struct {
 	jobQueue jobs.Queue
+	recorder audit.Recorder
 	now      func() time.Time
 	newID    func() string
 }
 
-func NewQueueInvoiceExtractionFromFilesCommand(jobQueue jobs.Queue) *QueueInvoiceExtractionFromFilesCommand {
+func NewQueueInvoiceExtractionFromFilesCommand(jobQueue jobs.Queue, recorder audit.Recorder) *QueueInvoiceExtractionFromFilesCommand {
 	if jobQueue == nil {
 		panic("job queue is required")
 	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
 
 	return &QueueInvoiceExtractionFromFilesCommand{
 		jobQueue: jobQueue,
+		recorder: recorder,
 		now:      time.Now,
 		newID:    id.NewULID,
 	}
@@ -44,12 +50,14 @@ func NewQueueInvoiceExtractionFromFilesCommand(jobQueue jobs.Queue) *QueueInvoic
 
 func (cmd *QueueInvoiceExtractionFromFilesCommand) Execute(ctx context.Context, input QueueInvoiceExtractionFromFilesInput) (*QueueInvoiceExtractionFromFilesResult, error) {
 	files := make([]contractJobs.File, 0, len(input.Files))
+	paths := make([]string, 0, len(input.Files))
 	for _, file := range input.Files {
 		files = append(files, contractJobs.File{
 			Path:     file.Path,
 			Filename: file.Name,
 			MimeType: file.MimeType,
 		})
+		paths = append(paths, file.Path)
 	}
 
 	jobID := cmd.newID()
@@ -65,6 +73,17 @@ func (cmd *QueueInvoiceExtractionFromFilesCommand) Execute(ctx context.Context,
 		return nil, err
 	}
 
+	// Recorded before dispatching, so no extraction runs untraced.
+	err = cmd.recorder.Record(ctx, audit.Event{
+		Action:     audit.ActionInvoiceExtractionQueued,
+		TargetType: audit.TargetInvoiceExtraction,
+		TargetID:   jobID,
+		Metadata:   map[string]any{"files": paths},
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	err = cmd.jobQueue.Dispatch(ctx, jobs.Job{
 		Type:    contractJobs.InvoiceExtractionRequestedType,
 		Payload: payload,
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	contractJobs "github.com/bowerbird/internal/invoices/contracts/jobs"
+	"github.com/bowerbird/internal/platform/audit"
 	"github.com/bowerbird/internal/platform/jobs"
 	"github.com/bowerbird/internal/platform/tenant"
 	"github.com/stretchr/testify/assert"
@@ -22,9 +23,19 @@ func (p *requestInvoiceExtractionPublisherSpy) Dispatch(ctx context.Context, job
 	return nil
 }
 
+type auditRecorderSpy struct {
+	events []audit.Event
+}
+
+func (r *auditRecorderSpy) Record(ctx context.Context, event audit.Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
 func TestQueueInvoiceExtractionFromUploadedFilesCommandQueuesJob(t *testing.T) {
 	publisher := &requestInvoiceExtractionPublisherSpy{}
-	cmd := NewQueueInvoiceExtractionFromFilesCommand(publisher)
+	recorder := &auditRecorderSpy{}
+	cmd := NewQueueInvoiceExtractionFromFilesCommand(publisher, recorder)
 	cmd.newID = func() string { return "evt_123" }
 	cmd.now = func() time.Time { return time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC) }
 	ctx := context.Background()
@@ -47,4 +58,8 @@ func TestQueueInvoiceExtractionFromUploadedFilesCommandQueuesJob(t *testing.T) {
 	assert.Equal(t, "files-uploaded-by-user", queued.Source)
 	require.Len(t, queued.Files, 2)
 	assert.Equal(t, "PDF", queued.Files[0].MimeType)
+
+	require.Len(t, recorder.events, 1)
+	assert.Equal(t, audit.ActionInvoiceExtractionQueued, recorder.events[0].Action)
+	assert.Equal(t, "evt_123", recorder.events[0].TargetID)
 }
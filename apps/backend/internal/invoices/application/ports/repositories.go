@@ -15,3 +15,16 @@ type InvoiceRepository interface {
 	ExistsInvoiceBySourceMessageID(ctx context.Context, sourceMessageID string) (bool, error)
 	ExistsInvoiceByCUFE(ctx context.Context, cufe string) (bool, error)
 }
+
+// InvoiceFilter selects one page of invoices, newest first.
+type InvoiceFilter struct {
+	// Cursor is the ID of the last invoice of the previous page.
+	Cursor string
+	Limit  int
+}
+
+type InvoiceReadRepository interface {
+	ListInvoices(ctx context.Context, filter InvoiceFilter) ([]domain.InvoiceHeaderRecord, error)
+	// GetInvoice returns domain.ErrInvoiceNotFound when there is no such invoice.
+	GetInvoice(ctx context.Context, id string) (*domain.InvoiceHeaderRecord, []domain.InvoiceLineRecord, error)
+}
@@ -1,3 +1,34 @@
 package queries
 
-type GetInvoiceByIDQuery struct{}
+import (
+	"context"
+
+	"github.com/bowerbird/internal/invoices/application/ports"
+	"github.com/bowerbird/internal/invoices/domain"
+)
+
+type InvoiceDetail struct {
+	Header *domain.InvoiceHeaderRecord
+	Lines  []domain.InvoiceLineRecord
+}
+
+type GetInvoiceByIDQuery struct {
+	repo ports.InvoiceReadRepository
+}
+
+func NewGetInvoiceByIDQuery(repo ports.InvoiceReadRepository) *GetInvoiceByIDQuery {
+	if repo == nil {
+		panic("invoice repository is required")
+	}
+
+	return &GetInvoiceByIDQuery{repo: repo}
+}
+
+func (q *GetInvoiceByIDQuery) Execute(ctx context.Context, id string) (*InvoiceDetail, error) {
+	header, lines, err := q.repo.GetInvoice(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &InvoiceDetail{Header: header, Lines: lines}, nil
+}
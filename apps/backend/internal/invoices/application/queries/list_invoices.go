@@ -0,0 +1,50 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/invoices/application/ports"
+	"github.com/bowerbird/internal/invoices/domain"
+)
+
+// InvoicesMaxPageSize bounds a single listing page.
+const InvoicesMaxPageSize = 100
+
+type InvoicePage struct {
+	Invoices   []domain.InvoiceHeaderRecord
+	NextCursor string
+}
+
+type ListInvoicesQuery struct {
+	repo ports.InvoiceReadRepository
+}
+
+func NewListInvoicesQuery(repo ports.InvoiceReadRepository) *ListInvoicesQuery {
+	if repo == nil {
+		panic("invoice repository is required")
+	}
+
+	return &ListInvoicesQuery{repo: repo}
+}
+
+// Execute returns one page, newest first. One extra invoice is read to know
+// whether another page follows.
+func (q *ListInvoicesQuery) Execute(ctx context.Context, filter ports.InvoiceFilter) (*InvoicePage, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > InvoicesMaxPageSize {
+		limit = InvoicesMaxPageSize
+	}
+	filter.Limit = limit + 1
+
+	invoices, err := q.repo.ListInvoices(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &InvoicePage{Invoices: invoices}
+	if len(invoices) > limit {
+		page.Invoices = invoices[:limit]
+		page.NextCursor = page.Invoices[limit-1].ID
+	}
+	return page, nil
+}
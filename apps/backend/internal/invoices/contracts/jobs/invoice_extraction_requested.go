@@ -15,11 +15,21 @@ type File struct {
 	MimeType string `json:"mime_type"`
 }
 
+// SourceMessage identifies the inbox message the files came from, so the
+// outcome can be reported back to its mailbox.
+type SourceMessage struct {
+	ConnectionID      string `json:"connection_id"`
+	Provider          string `json:"provider"`
+	ProviderMessageID string `json:"provider_message_id"`
+	MessageInternalID string `json:"message_internal_id"`
+}
+
 type InvoiceExtractionRequested struct {
-	JobID    string `json:"job_id"`
-	Source   string `json:"source"`
-	Files    []File `json:"files"`
-	QueuedAt string `json:"requested_at"`
+	JobID    string         `json:"job_id"`
+	Source   string         `json:"source"`
+	Files    []File         `json:"files"`
+	QueuedAt string         `json:"requested_at"`
+	Message  *SourceMessage `json:"message,omitempty"`
 }
 
 func (j InvoiceExtractionRequested) Validate() error {
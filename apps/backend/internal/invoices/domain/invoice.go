@@ -14,6 +14,7 @@ var (
 	ErrMissingReceiver  = errors.New("missing receiver data")
 	ErrMissingLineItems = errors.New("missing invoice line items")
 	ErrMissingInvoiceID = errors.New("missing invoice id")
+	ErrInvoiceNotFound  = errors.New("invoice not found")
 )
 
 type Party struct {
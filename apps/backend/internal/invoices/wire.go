@@ -10,6 +10,8 @@ import (
 	invoicingRepo "github.com/bowerbird/internal/invoices/adapters/repository/postgres"
 	"github.com/bowerbird/internal/invoices/application"
 	"github.com/bowerbird/internal/invoices/application/commands"
+	"github.com/bowerbird/internal/invoices/application/queries"
+	"github.com/bowerbird/internal/platform/audit"
 	"github.com/bowerbird/internal/platform/config"
 	"github.com/bowerbird/internal/platform/database"
 	"github.com/bowerbird/internal/platform/events"
@@ -23,6 +25,7 @@ func NewApplication(
 	jobQueue jobs.Queue,
 	fileStore platformStorage.FileStore,
 	registry *database.Registry,
+	recorder audit.Recorder,
 ) *application.Application {
 	if eventBus == nil {
 		panic("event bus is required")
@@ -36,6 +39,9 @@ func NewApplication(
 	if registry == nil {
 		panic("database registry is required")
 	}
+	if recorder == nil {
+		panic("audit recorder is required")
+	}
 	if cfg.GeminiAPIKey == "" {
 		panic("gemini api key is required")
 	}
@@ -55,15 +61,20 @@ func NewApplication(
 	return &application.Application{
 		Commands: application.Commands{
 			CreateInvoicesFromInboxMessage:  commands.NewCreateInvoicesFromInboxMessageCommand(jobQueue),
-			QueueInvoiceExtractionFromFiles: commands.NewQueueInvoiceExtractionFromFilesCommand(jobQueue),
+			QueueInvoiceExtractionFromFiles: commands.NewQueueInvoiceExtractionFromFilesCommand(jobQueue, recorder),
 			ProcessInvoiceExtractionJob: commands.NewProcessInvoiceExtractionJobCommand(
 				fileStore,
 				xmlExtractor,
 				llmExtractor,
 				invoiceRepository,
+				eventBus,
 			),
 			CreateInvoice: commands.NewCreateInvoiceCommand(invoiceRepository),
 		},
+		Queries: application.Queries{
+			ListInvoices:   queries.NewListInvoicesQuery(invoiceRepository),
+			GetInvoiceByID: queries.NewGetInvoiceByIDQuery(invoiceRepository),
+		},
 	}
 }
 
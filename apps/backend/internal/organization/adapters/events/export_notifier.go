@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	contractEvents "github.com/bowerbird/internal/contracts/events"
+	"github.com/bowerbird/internal/organization/domain"
+	platformEvents "github.com/bowerbird/internal/platform/events"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+// ExportNotifier hands ready exports to the notification pipeline through the
+// event bus. The link never carries the presigned URL, which expires long
+// before the email is read.
+type ExportNotifier struct {
+	eventBus    platformEvents.EventBus
+	frontendURL string
+}
+
+func NewExportNotifier(eventBus platformEvents.EventBus, frontendURL string) *ExportNotifier {
+	if eventBus == nil {
+		panic("event bus is required")
+	}
+
+	return &ExportNotifier{eventBus: eventBus, frontendURL: frontendURL}
+}
+
+func (n *ExportNotifier) ExportReady(ctx context.Context, org *domain.Organization, export *domain.DataExport) error {
+	event := contractEvents.OrganizationExportCompleted{
+		EventID:          id.NewULID(),
+		OccurredAt:       time.Now().UTC().Format(time.RFC3339Nano),
+		OrganizationID:   org.ID,
+		OrganizationSlug: org.Slug,
+		ExportID:         export.ID,
+		UserID:           export.RequestedBy,
+		Email:            export.RequestedByEmail,
+		SizeBytes:        export.SizeBytes,
+		ActionURL:        n.frontendURL + "/" + url.PathEscape(org.Slug) + "/settings/exports/" + url.PathEscape(export.ID),
+	}
+
+	payload, err := contractEvents.MarshalOrganizationExportCompleted(event)
+	if err != nil {
+		return err
+	}
+
+	return n.eventBus.Publish(ctx, platformEvents.BusinessEvent{
+		Source:     contractEvents.OrganizationExportCompletedSource,
+		DetailType: contractEvents.OrganizationExportCompletedDetailType,
+		Detail:     payload,
+	})
+}
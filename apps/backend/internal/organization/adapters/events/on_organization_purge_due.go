@@ -0,0 +1,33 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	awsEvents "github.com/aws/aws-lambda-go/events"
+	contractEvents "github.com/bowerbird/internal/contracts/events"
+	"github.com/bowerbird/internal/organization/application/commands"
+)
+
+// OnOrganizationPurgeDue purges organizations when the scheduler fires. The
+// event carries no payload: every run purges whatever is due.
+type OnOrganizationPurgeDue struct {
+	command *commands.PurgeOrganizationsCommand
+}
+
+func NewOnOrganizationPurgeDue(command *commands.PurgeOrganizationsCommand) *OnOrganizationPurgeDue {
+	return &OnOrganizationPurgeDue{command: command}
+}
+
+func (h *OnOrganizationPurgeDue) DetailType() string {
+	return contractEvents.OrganizationPurgeDueDetailType
+}
+
+func (h *OnOrganizationPurgeDue) HandleEventBridge(ctx context.Context, _ awsEvents.CloudWatchEvent) error {
+	purged, err := h.command.Execute(ctx)
+	if purged > 0 {
+		slog.Info("Organizations purged", "count", purged)
+	}
+
+	return err
+}
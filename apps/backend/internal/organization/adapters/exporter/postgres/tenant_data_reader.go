@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// exportDataset selects one table as JSON objects plus the storage keys its
+// rows reference. Columns are listed explicitly so that secrets, such as
+// connection credentials, never reach a bundle.
+type exportDataset struct {
+	name  string
+	query string
+}
+
+// exportDatasets are read in this order, parents before children, so an
+// import can insert them as they come.
+var exportDatasets = []exportDataset{
+	{"users", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT id, email, first_name, last_name, picture_url, created_at, updated_at, deleted_at FROM users
+		) t ORDER BY t.id`},
+	{"permissions", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT id, code, description, created_at FROM permissions
+		) t ORDER BY t.id`},
+	{"roles", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT id, name, description, is_system, created_at FROM roles
+		) t ORDER BY t.id`},
+	{"role_permissions", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT role_id, permission_id, created_at FROM role_permissions
+		) t ORDER BY t.role_id, t.permission_id`},
+	{"user_roles", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT user_id, role_id, created_at FROM user_roles
+		) t ORDER BY t.user_id, t.role_id`},
+	{"connections", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT id, owner_user_id, provider, email_address, status, sharing_policy, granted_scopes, created_at, updated_at FROM connections
+		) t ORDER BY t.id`},
+	{"inbox_sync_cursors", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT connection_id, last_synced_at, last_error, status FROM inbox_sync_cursors
+		) t ORDER BY t.connection_id`},
+	{"email_messages", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT id, account_id, provider_message_id, provider_thread_id, subject, sender_email, received_at,
+			       sync_status, raw_data, created_at, updated_at
+			FROM email_messages
+		) t ORDER BY t.id`},
+	{"email_attachments", `
+		SELECT row_to_json(t)::text, ARRAY[t.s3_key] FROM (
+			SELECT id, message_id, filename, mime_type, size_bytes, sha256, s3_key, raw_data, created_at, updated_at
+			FROM email_attachments
+		) t ORDER BY t.id`},
+	{"invoice_headers", `
+		SELECT row_to_json(t)::text, ARRAY_REMOVE(ARRAY[t.document_ref_s3_key], NULL) FROM (
+			SELECT id, source_message_id, cufe, invoice_number, issuer_name, issuer_tax_id, receiver_name, receiver_tax_id,
+			       currency_code, issue_date, due_date, payment_code, subtotal, tax_total, grand_total,
+			       document_ref_s3_key, extraction_source, raw_data, created_at, updated_at
+			FROM invoice_headers
+		) t ORDER BY t.id`},
+	{"invoice_lines", `
+		SELECT row_to_json(t)::text, ARRAY[]::text[] FROM (
+			SELECT id, invoice_header_id, line_number, item_code, description, quantity, unit_price,
+			       line_tax_total, line_total, raw_data, created_at, updated_at
+			FROM invoice_lines
+		) t ORDER BY t.id`},
+}
+
+// TenantDataReader reads the exportable tables of a tenant database.
+type TenantDataReader struct {
+	registry *database.Registry
+}
+
+func NewTenantDataReader(registry *database.Registry) *TenantDataReader {
+	return &TenantDataReader{registry: registry}
+}
+
+// ReadTenantData reads every dataset in one read-only snapshot, so rows
+// written while the export runs cannot leave a child without its parent.
+// Rows are handed over as they arrive from the server.
+func (r *TenantDataReader) ReadTenantData(ctx context.Context, location domain.DatabaseLocation, visit func(dataset string, rows ports.ExportRows) error) (uint, error) {
+	ctx = database.WithTenantDatabase(ctx, location.DBName)
+	pool, err := r.registry.GetPoolByDBName(ctx, location.DBName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	tx, err := pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return 0, fmt.Errorf("begin export snapshot: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var version int64
+	if err := tx.QueryRow(ctx, `SELECT version FROM schema_migrations LIMIT 1`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("read schema version: %w", err)
+	}
+
+	for _, dataset := range exportDatasets {
+		if err := readDataset(ctx, tx, dataset, visit); err != nil {
+			return 0, fmt.Errorf("read %s: %w", dataset.name, err)
+		}
+	}
+
+	return uint(version), nil
+}
+
+func readDataset(ctx context.Context, tx pgx.Tx, dataset exportDataset, visit func(dataset string, rows ports.ExportRows) error) error {
+	rows, err := tx.Query(ctx, dataset.query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	cursor := &exportRows{rows: rows}
+	if err := visit(dataset.name, cursor); err != nil {
+		return err
+	}
+	return cursor.Err()
+}
+
+// exportRows scans one row at a time, so a dataset is never held in memory.
+type exportRows struct {
+	rows    pgx.Rows
+	current domain.ExportRecord
+	err     error
+}
+
+func (c *exportRows) Next() bool {
+	if c.err != nil || !c.rows.Next() {
+		return false
+	}
+
+	var record string
+	var fileKeys []string
+	if err := c.rows.Scan(&record, &fileKeys); err != nil {
+		c.err = err
+		return false
+	}
+	c.current = domain.ExportRecord{Data: json.RawMessage(record), FileKeys: fileKeys}
+	return true
+}
+
+func (c *exportRows) Record() domain.ExportRecord {
+	return c.current
+}
+
+func (c *exportRows) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.rows.Err()
+}
@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bowerbird/internal/organization/application/commands"
+	"github.com/bowerbird/internal/organization/application/queries"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/auth"
+	appErrors "github.com/bowerbird/internal/platform/errors"
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+// APIKeyManagePermission is the RBAC code required to list, create and revoke API keys.
+const APIKeyManagePermission = "settings:write"
+
+type APIKeyController struct {
+	createCommand *commands.CreateAPIKeyCommand
+	revokeCommand *commands.RevokeAPIKeyCommand
+	listQuery     *queries.ListAPIKeysQuery
+}
+
+func NewAPIKeyController(createCommand *commands.CreateAPIKeyCommand, revokeCommand *commands.RevokeAPIKeyCommand, listQuery *queries.ListAPIKeysQuery) *APIKeyController {
+	if createCommand == nil {
+		panic("create api key command is required")
+	}
+	if revokeCommand == nil {
+		panic("revoke api key command is required")
+	}
+	if listQuery == nil {
+		panic("list api keys query is required")
+	}
+
+	return &APIKeyController{
+		createCommand: createCommand,
+		revokeCommand: revokeCommand,
+		listQuery:     listQuery,
+	}
+}
+
+// managingClaims returns the caller's claims. API keys cannot manage API keys,
+// otherwise a leaked key could mint long-lived replacements for itself.
+func managingClaims(r *http.Request) (*auth.CustomClaims, error) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok || !claims.IsTenantScoped() {
+		return nil, appErrors.New(appErrors.CodeUnauthorized, "a tenant-scoped token is required")
+	}
+	if claims.APIKeyID != "" {
+		return nil, appErrors.New(appErrors.CodeForbidden, "api keys cannot manage api keys")
+	}
+
+	return claims, nil
+}
+
+func (c *APIKeyController) ListAPIKeys(w http.ResponseWriter, r *http.Request) error {
+	claims, err := managingClaims(r)
+	if err != nil {
+		return err
+	}
+
+	keys, err := c.listQuery.Execute(r.Context(), claims.TenantID)
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to list api keys")
+	}
+
+	resp := make([]apiKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, newAPIKeyResponse(key))
+	}
+
+	return api.Success(w, http.StatusOK, resp)
+}
+
+func (c *APIKeyController) CreateAPIKey(w http.ResponseWriter, r *http.Request) error {
+	claims, err := managingClaims(r)
+	if err != nil {
+		return err
+	}
+
+	var req createAPIKeyRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	if err := req.Validate(); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	result, err := c.createCommand.Execute(r.Context(), commands.CreateAPIKeyInput{
+		TenantID:           claims.TenantID,
+		Name:               req.Name,
+		Scopes:             req.Scopes,
+		ExpiresAt:          req.ExpiresAt,
+		CreatedBy:          claims.UserID,
+		CreatorPermissions: claims.Permissions,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidAPIKeyScope):
+			return appErrors.Wrap(err, appErrors.CodeForbidden, err.Error())
+		case errors.Is(err, domain.ErrInvalidAPIKeyName), errors.Is(err, domain.ErrInvalidAPIKeyExpiry):
+			return appErrors.Wrap(err, appErrors.CodeValidation, err.Error())
+		}
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to create api key")
+	}
+
+	return api.Success(w, http.StatusCreated, createdAPIKeyResponse{
+		apiKeyResponse: newAPIKeyResponse(result.Key),
+		Token:          result.Token,
+	})
+}
+
+func (c *APIKeyController) RevokeAPIKey(w http.ResponseWriter, r *http.Request) error {
+	claims, err := managingClaims(r)
+	if err != nil {
+		return err
+	}
+
+	keyID := r.PathValue("id")
+	if keyID == "" {
+		return appErrors.New(appErrors.CodeValidation, "id is required")
+	}
+
+	if err := c.revokeCommand.Execute(r.Context(), claims.TenantID, keyID); err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return appErrors.Wrap(err, appErrors.CodeNotFound, "api key not found")
+		}
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to revoke api key")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/queries"
+	"github.com/bowerbird/internal/platform/auth"
+	appErrors "github.com/bowerbird/internal/platform/errors"
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+// AuditReadPermission is the RBAC code required to list and export the audit log.
+const AuditReadPermission = "audit:read"
+
+type AuditController struct {
+	listQuery   *queries.ListAuditEventsQuery
+	exportQuery *queries.ExportAuditEventsQuery
+}
+
+func NewAuditController(listQuery *queries.ListAuditEventsQuery, exportQuery *queries.ExportAuditEventsQuery) *AuditController {
+	if listQuery == nil {
+		panic("list audit events query is required")
+	}
+	if exportQuery == nil {
+		panic("export audit events query is required")
+	}
+
+	return &AuditController{listQuery: listQuery, exportQuery: exportQuery}
+}
+
+func (c *AuditController) ListAuditEvents(w http.ResponseWriter, r *http.Request) error {
+	if err := requireTenantScope(r); err != nil {
+		return err
+	}
+
+	filter, err := parseAuditEventFilter(r.URL.Query())
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, err.Error())
+	}
+
+	page, err := c.listQuery.Execute(r.Context(), filter)
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to list audit events")
+	}
+
+	resp := auditEventsResponse{Data: make([]auditEventResponse, 0, len(page.Events)), NextCursor: page.NextCursor}
+	for _, entry := range page.Events {
+		resp.Data = append(resp.Data, newAuditEventResponse(entry))
+	}
+
+	return api.Success(w, http.StatusOK, resp)
+}
+
+// ExportAuditEvents streams the matching events as CSV, newest first. The
+// X-Audit-Export-Truncated header tells the caller to narrow the filters.
+func (c *AuditController) ExportAuditEvents(w http.ResponseWriter, r *http.Request) error {
+	if err := requireTenantScope(r); err != nil {
+		return err
+	}
+
+	filter, err := parseAuditEventFilter(r.URL.Query())
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, err.Error())
+	}
+
+	events, truncated, err := c.exportQuery.Execute(r.Context(), filter)
+	if err != nil {
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to export audit events")
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-events-`+time.Now().UTC().Format("20060102T150405Z")+`.csv"`)
+	w.Header().Set("X-Audit-Export-Truncated", strconv.FormatBool(truncated))
+	w.WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"id", "occurred_at", "actor_type", "actor_user_id", "actor_api_key_id", "action", "target_type", "target_id", "ip_address", "user_agent", "trace_id", "changes", "metadata"})
+	for _, entry := range events {
+		_ = writer.Write([]string{
+			entry.ID,
+			entry.OccurredAt.Format(time.RFC3339),
+			entry.ActorType,
+			entry.ActorUserID,
+			entry.ActorAPIKeyID,
+			entry.Action,
+			entry.TargetType,
+			entry.TargetID,
+			entry.IPAddress,
+			entry.UserAgent,
+			entry.TraceID,
+			jsonCell(entry.Changes),
+			jsonCell(entry.Metadata),
+		})
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+func requireTenantScope(r *http.Request) error {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok || !claims.IsTenantScoped() {
+		return appErrors.New(appErrors.CodeUnauthorized, "a tenant-scoped token is required")
+	}
+
+	return nil
+}
+
+func jsonCell[V any](value map[string]V) string {
+	if len(value) == 0 {
+		return ""
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(raw)
+}
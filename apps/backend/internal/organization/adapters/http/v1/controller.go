@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/bowerbird/internal/organization/application"
+	"github.com/bowerbird/internal/organization/domain"
 	"github.com/bowerbird/internal/platform/auth"
 	appErrors "github.com/bowerbird/internal/platform/errors"
 	"github.com/bowerbird/internal/platform/http/api"
@@ -67,6 +68,12 @@ func (c *Controller) CreateOrganization(w http.ResponseWriter, r *http.Request)
 
 	resp := newOrganizationResponse(org)
 
+	// Provisioning usually continues in the background; clients poll
+	// GET /api/v1/organizations/{id}/provisioning until it completes.
+	if org.Status == domain.StatusProvisioning {
+		return api.Success(w, http.StatusAccepted, resp)
+	}
+
 	return api.Success(w, http.StatusCreated, resp)
 }
 
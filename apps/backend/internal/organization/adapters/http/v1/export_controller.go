@@ -0,0 +1,112 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/commands"
+	"github.com/bowerbird/internal/organization/application/queries"
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+type ExportController struct {
+	requestCommand *commands.RequestDataExportCommand
+	listQuery      *queries.ListDataExportsQuery
+	getQuery       *queries.GetDataExportQuery
+	downloadQuery  *queries.DownloadDataExportQuery
+}
+
+func NewExportController(
+	requestCommand *commands.RequestDataExportCommand,
+	listQuery *queries.ListDataExportsQuery,
+	getQuery *queries.GetDataExportQuery,
+	downloadQuery *queries.DownloadDataExportQuery,
+) *ExportController {
+	if requestCommand == nil {
+		panic("request data export command is required")
+	}
+	if listQuery == nil {
+		panic("list data exports query is required")
+	}
+	if getQuery == nil {
+		panic("get data export query is required")
+	}
+	if downloadQuery == nil {
+		panic("download data export query is required")
+	}
+
+	return &ExportController{
+		requestCommand: requestCommand,
+		listQuery:      listQuery,
+		getQuery:       getQuery,
+		downloadQuery:  downloadQuery,
+	}
+}
+
+// RequestExport answers 202; the export is built in the background and its
+// status can be polled with GetExport.
+func (c *ExportController) RequestExport(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	export, err := c.requestCommand.Execute(r.Context(), r.PathValue("id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to request data export")
+	}
+
+	return api.Success(w, http.StatusAccepted, newDataExportResponse(export))
+}
+
+func (c *ExportController) ListExports(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	exports, err := c.listQuery.Execute(r.Context(), r.PathValue("id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to list data exports")
+	}
+
+	resp := make([]dataExportResponse, 0, len(exports))
+	for _, export := range exports {
+		resp = append(resp, newDataExportResponse(export))
+	}
+
+	return api.Success(w, http.StatusOK, resp)
+}
+
+func (c *ExportController) GetExport(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	export, err := c.getQuery.Execute(r.Context(), r.PathValue("id"), r.PathValue("export_id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to get data export")
+	}
+
+	return api.Success(w, http.StatusOK, newDataExportResponse(export))
+}
+
+// DownloadExport returns a short-lived link instead of streaming the bundle
+// through the API.
+func (c *ExportController) DownloadExport(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	link, err := c.downloadQuery.Execute(r.Context(), r.PathValue("id"), r.PathValue("export_id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to get data export download link")
+	}
+
+	return api.Success(w, http.StatusOK, dataExportDownloadResponse{
+		URL:       link.URL,
+		ExpiresAt: link.ExpiresAt.Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/commands"
+	"github.com/bowerbird/internal/organization/application/queries"
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+type ImportController struct {
+	createCommand *commands.CreateDataImportCommand
+	startCommand  *commands.StartDataImportCommand
+	listQuery     *queries.ListDataImportsQuery
+	getQuery      *queries.GetDataImportQuery
+}
+
+func NewImportController(
+	createCommand *commands.CreateDataImportCommand,
+	startCommand *commands.StartDataImportCommand,
+	listQuery *queries.ListDataImportsQuery,
+	getQuery *queries.GetDataImportQuery,
+) *ImportController {
+	if createCommand == nil {
+		panic("create data import command is required")
+	}
+	if startCommand == nil {
+		panic("start data import command is required")
+	}
+	if listQuery == nil {
+		panic("list data imports query is required")
+	}
+	if getQuery == nil {
+		panic("get data import query is required")
+	}
+
+	return &ImportController{
+		createCommand: createCommand,
+		startCommand:  startCommand,
+		listQuery:     listQuery,
+		getQuery:      getQuery,
+	}
+}
+
+// CreateImport registers an import and returns the link to upload the
+// bundle to; StartImport runs it once the upload is done.
+func (c *ImportController) CreateImport(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	dataImport, upload, err := c.createCommand.Execute(r.Context(), r.PathValue("id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to create data import")
+	}
+
+	return api.Success(w, http.StatusCreated, createdDataImportResponse{
+		dataImportResponse: newDataImportResponse(dataImport),
+		Upload: dataImportUploadResponse{
+			URL:       upload.URL,
+			Method:    upload.Method,
+			Headers:   upload.Headers,
+			ExpiresAt: upload.ExpiresAt.Format(time.RFC3339),
+		},
+	})
+}
+
+// StartImport answers 202; progress can be polled with GetImport.
+func (c *ImportController) StartImport(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	dataImport, err := c.startCommand.Execute(r.Context(), r.PathValue("id"), r.PathValue("import_id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to start data import")
+	}
+
+	return api.Success(w, http.StatusAccepted, newDataImportResponse(dataImport))
+}
+
+func (c *ImportController) ListImports(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	imports, err := c.listQuery.Execute(r.Context(), r.PathValue("id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to list data imports")
+	}
+
+	resp := make([]dataImportResponse, 0, len(imports))
+	for _, dataImport := range imports {
+		resp = append(resp, newDataImportResponse(dataImport))
+	}
+
+	return api.Success(w, http.StatusOK, resp)
+}
+
+func (c *ImportController) GetImport(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	dataImport, err := c.getQuery.Execute(r.Context(), r.PathValue("id"), r.PathValue("import_id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to get data import")
+	}
+
+	return api.Success(w, http.StatusOK, newDataImportResponse(dataImport))
+}
@@ -0,0 +1,182 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bowerbird/internal/organization/application/commands"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/auth"
+	appErrors "github.com/bowerbird/internal/platform/errors"
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+type LifecycleController struct {
+	updateCommand         *commands.UpdateOrganizationCommand
+	changeStatusCommand   *commands.ChangeOrganizationStatusCommand
+	deleteCommand         *commands.DeleteOrganizationCommand
+	cancelDeletionCommand *commands.CancelOrganizationDeletionCommand
+}
+
+func NewLifecycleController(
+	updateCommand *commands.UpdateOrganizationCommand,
+	changeStatusCommand *commands.ChangeOrganizationStatusCommand,
+	deleteCommand *commands.DeleteOrganizationCommand,
+	cancelDeletionCommand *commands.CancelOrganizationDeletionCommand,
+) *LifecycleController {
+	if updateCommand == nil {
+		panic("update organization command is required")
+	}
+	if changeStatusCommand == nil {
+		panic("change organization status command is required")
+	}
+	if deleteCommand == nil {
+		panic("delete organization command is required")
+	}
+	if cancelDeletionCommand == nil {
+		panic("cancel organization deletion command is required")
+	}
+
+	return &LifecycleController{
+		updateCommand:         updateCommand,
+		changeStatusCommand:   changeStatusCommand,
+		deleteCommand:         deleteCommand,
+		cancelDeletionCommand: cancelDeletionCommand,
+	}
+}
+
+func (c *LifecycleController) UpdateOrganization(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	var req updateOrganizationRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	if err := req.Validate(); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	org, err := c.updateCommand.Execute(r.Context(), commands.UpdateOrganizationInput{
+		OrganizationID: r.PathValue("id"),
+		ActorID:        claims.UserID,
+		Name:           req.Name,
+		Slug:           req.Slug,
+	})
+	if err != nil {
+		return mapLifecycleError(err, "failed to update organization")
+	}
+
+	return api.Success(w, http.StatusOK, newOrganizationResponse(org))
+}
+
+func (c *LifecycleController) SuspendOrganization(w http.ResponseWriter, r *http.Request) error {
+	return c.changeStatus(w, r, domain.StatusSuspended)
+}
+
+func (c *LifecycleController) ArchiveOrganization(w http.ResponseWriter, r *http.Request) error {
+	return c.changeStatus(w, r, domain.StatusArchived)
+}
+
+func (c *LifecycleController) ReactivateOrganization(w http.ResponseWriter, r *http.Request) error {
+	return c.changeStatus(w, r, domain.StatusActive)
+}
+
+func (c *LifecycleController) changeStatus(w http.ResponseWriter, r *http.Request, status string) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	org, err := c.changeStatusCommand.Execute(r.Context(), commands.ChangeOrganizationStatusInput{
+		OrganizationID: r.PathValue("id"),
+		ActorID:        claims.UserID,
+		Status:         status,
+	})
+	if err != nil {
+		return mapLifecycleError(err, "failed to change organization status")
+	}
+
+	return api.Success(w, http.StatusOK, newOrganizationResponse(org))
+}
+
+// DeleteOrganization schedules the deletion; the response carries the date
+// after which the organization's data is purged.
+func (c *LifecycleController) DeleteOrganization(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	org, err := c.deleteCommand.Execute(r.Context(), r.PathValue("id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to delete organization")
+	}
+
+	return api.Success(w, http.StatusAccepted, newOrganizationResponse(org))
+}
+
+func (c *LifecycleController) CancelDeletion(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	org, err := c.cancelDeletionCommand.Execute(r.Context(), r.PathValue("id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to cancel organization deletion")
+	}
+
+	return api.Success(w, http.StatusOK, newOrganizationResponse(org))
+}
+
+// ownerClaims returns the caller's claims. Lifecycle changes can take the
+// organization offline, so API keys cannot make them.
+func ownerClaims(r *http.Request) (*auth.CustomClaims, error) {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok {
+		return nil, appErrors.New(appErrors.CodeUnauthorized, "unauthorized")
+	}
+	if claims.APIKeyID != "" {
+		return nil, appErrors.New(appErrors.CodeForbidden, "api keys cannot change the organization lifecycle")
+	}
+
+	return claims, nil
+}
+
+func mapLifecycleError(err error, fallback string) error {
+	switch {
+	case errors.Is(err, domain.ErrOrganizationNotFound):
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "organization not found")
+	case errors.Is(err, domain.ErrNotOrganizationOwner):
+		return appErrors.Wrap(err, appErrors.CodeForbidden, "only the organization owner can do this")
+	case errors.Is(err, domain.ErrInvalidStatusTransition):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "the organization status does not allow this operation")
+	case errors.Is(err, domain.ErrExportNotFound):
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "export not found")
+	case errors.Is(err, domain.ErrExportInProgress):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "an export is already in progress")
+	case errors.Is(err, domain.ErrExportNotReady):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "the export is not ready for download")
+	case errors.Is(err, domain.ErrImportNotFound):
+		return appErrors.Wrap(err, appErrors.CodeNotFound, "import not found")
+	case errors.Is(err, domain.ErrImportInProgress):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "an import is already in progress")
+	case errors.Is(err, domain.ErrImportNotAwaitingUpload):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "the import has already been started")
+	case errors.Is(err, domain.ErrImportBundleMissing):
+		return appErrors.Wrap(err, appErrors.CodeValidation, "upload the export bundle before starting the import")
+	case errors.Is(err, domain.ErrSlugAlreadyExists):
+		return appErrors.Wrap(err, appErrors.CodeConflict, "slug already exists")
+	case errors.Is(err, domain.ErrInvalidSlug), errors.Is(err, domain.ErrInvalidOrganizationName):
+		return appErrors.Wrap(err, appErrors.CodeValidation, err.Error())
+	}
+
+	return appErrors.Wrap(err, appErrors.CodeInternal, fallback)
+}
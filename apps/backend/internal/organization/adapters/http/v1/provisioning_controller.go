@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/bowerbird/internal/organization/application/commands"
+	"github.com/bowerbird/internal/organization/application/queries"
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+type ProvisioningController struct {
+	statusQuery     *queries.GetProvisioningStatusQuery
+	resumeCommand   *commands.ResumeProvisioningCommand
+	rollbackCommand *commands.RollbackProvisioningCommand
+}
+
+func NewProvisioningController(
+	statusQuery *queries.GetProvisioningStatusQuery,
+	resumeCommand *commands.ResumeProvisioningCommand,
+	rollbackCommand *commands.RollbackProvisioningCommand,
+) *ProvisioningController {
+	if statusQuery == nil {
+		panic("get provisioning status query is required")
+	}
+	if resumeCommand == nil {
+		panic("resume provisioning command is required")
+	}
+	if rollbackCommand == nil {
+		panic("rollback provisioning command is required")
+	}
+
+	return &ProvisioningController{
+		statusQuery:     statusQuery,
+		resumeCommand:   resumeCommand,
+		rollbackCommand: rollbackCommand,
+	}
+}
+
+// GetProvisioningStatus is polled after creating an organization until its
+// status leaves provisioning.
+func (c *ProvisioningController) GetProvisioningStatus(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	org, err := c.statusQuery.Execute(r.Context(), r.PathValue("id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to get provisioning status")
+	}
+
+	return api.Success(w, http.StatusOK, newProvisioningStatusResponse(org))
+}
+
+func (c *ProvisioningController) ResumeProvisioning(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	org, err := c.resumeCommand.Execute(r.Context(), r.PathValue("id"), claims.UserID)
+	if err != nil {
+		return mapLifecycleError(err, "failed to resume provisioning")
+	}
+
+	return api.Success(w, http.StatusAccepted, newProvisioningStatusResponse(org))
+}
+
+func (c *ProvisioningController) RollbackProvisioning(w http.ResponseWriter, r *http.Request) error {
+	claims, err := ownerClaims(r)
+	if err != nil {
+		return err
+	}
+
+	if err := c.rollbackCommand.Execute(r.Context(), r.PathValue("id"), claims.UserID); err != nil {
+		return mapLifecycleError(err, "failed to roll back provisioning")
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
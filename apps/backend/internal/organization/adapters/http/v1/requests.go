@@ -2,7 +2,12 @@ package v1
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/bowerbird/internal/platform/audit"
 )
 
 type createOrganizationRequest struct {
@@ -21,3 +26,87 @@ func (r createOrganizationRequest) Validate() error {
 
 	return nil
 }
+
+type updateOrganizationRequest struct {
+	Name *string `json:"name"`
+	Slug *string `json:"slug"`
+}
+
+func (r updateOrganizationRequest) Validate() error {
+	if r.Name == nil && r.Slug == nil {
+		return fmt.Errorf("name or slug is required")
+	}
+
+	return nil
+}
+
+type createAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (r createAPIKeyRequest) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if len(r.Name) > 100 {
+		return fmt.Errorf("name must be at most 100 characters")
+	}
+
+	if len(r.Scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+
+	return nil
+}
+
+type updateSecurityPolicyRequest struct {
+	MFARequired *bool `json:"mfa_required"`
+}
+
+func (r updateSecurityPolicyRequest) Validate() error {
+	if r.MFARequired == nil {
+		return fmt.Errorf("mfa_required is required")
+	}
+
+	return nil
+}
+
+// parseAuditEventFilter reads the audit event filters from the query string.
+// from and to are RFC 3339 timestamps; to is exclusive.
+func parseAuditEventFilter(values url.Values) (audit.Filter, error) {
+	filter := audit.Filter{
+		Action:     values.Get("action"),
+		ActorID:    values.Get("actor_id"),
+		TargetType: values.Get("target_type"),
+		TargetID:   values.Get("target_id"),
+		Cursor:     values.Get("cursor"),
+	}
+
+	for name, dst := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		raw := values.Get(name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return audit.Filter{}, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+		}
+		*dst = parsed
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return audit.Filter{}, fmt.Errorf("from must be before to")
+	}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return audit.Filter{}, fmt.Errorf("limit must be a positive integer")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
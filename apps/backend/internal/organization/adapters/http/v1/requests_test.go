@@ -1,6 +1,10 @@
 package v1
 
-import "testing"
+import (
+	"net/url"
+	"testing"
+	"time"
+)
 
 func TestCreateOrganizationRequestValidateSuccess(t *testing.T) {
 	req := createOrganizationRequest{
@@ -28,3 +32,37 @@ func TestCreateOrganizationRequestValidateMissingSlug(t *testing.T) {
 		t.Fatal("expected validation error, got nil")
 	}
 }
+
+func TestParseAuditEventFilter(t *testing.T) {
+	values := url.Values{
+		"action":  {"connection.deleted"},
+		"from":    {"2026-01-01T00:00:00Z"},
+		"to":      {"2026-02-01T00:00:00Z"},
+		"limit":   {"25"},
+		"cursor":  {"01JW58TAT9M0N4R8M1P3Q6R9Y0"},
+		"unknown": {"ignored"},
+	}
+
+	filter, err := parseAuditEventFilter(values)
+	if err != nil {
+		t.Fatalf("expected valid filter, got error: %v", err)
+	}
+	if filter.Action != "connection.deleted" || filter.Limit != 25 || filter.Cursor != "01JW58TAT9M0N4R8M1P3Q6R9Y0" {
+		t.Fatalf("unexpected filter: %+v", filter)
+	}
+	if !filter.From.Equal(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected from: %v", filter.From)
+	}
+}
+
+func TestParseAuditEventFilterRejectsInvalidValues(t *testing.T) {
+	for _, values := range []url.Values{
+		{"from": {"yesterday"}},
+		{"from": {"2026-02-01T00:00:00Z"}, "to": {"2026-01-01T00:00:00Z"}},
+		{"limit": {"0"}},
+	} {
+		if _, err := parseAuditEventFilter(values); err == nil {
+			t.Fatalf("expected validation error for %v", values)
+		}
+	}
+}
@@ -1,25 +1,227 @@
 package v1
 
-import "github.com/bowerbird/internal/organization/domain"
+import (
+	"time"
+
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/audit"
+)
 
 type organizationResponse struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	Slug            string `json:"slug"`
-	Status          string `json:"status"`
-	CreatedAt       string `json:"created_at"`
-	MembersCount    int    `json:"members_count,omitempty"`
-	CurrentUserRole string `json:"current_user_role,omitempty"`
+	ID                  string  `json:"id"`
+	Name                string  `json:"name"`
+	Slug                string  `json:"slug"`
+	Status              string  `json:"status"`
+	MFARequired         bool    `json:"mfa_required"`
+	CreatedAt           string  `json:"created_at"`
+	DeletionScheduledAt *string `json:"deletion_scheduled_at,omitempty"`
+	MembersCount        int     `json:"members_count,omitempty"`
+	CurrentUserRole     string  `json:"current_user_role,omitempty"`
 }
 
 func newOrganizationResponse(org *domain.Organization) organizationResponse {
 	return organizationResponse{
-		ID:              org.ID,
-		Name:            org.Name,
-		Slug:            org.Slug,
-		Status:          org.Status,
-		CreatedAt:       org.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		MembersCount:    org.MembersCount,
-		CurrentUserRole: org.CurrentUserRole,
+		ID:                  org.ID,
+		Name:                org.Name,
+		Slug:                org.Slug,
+		Status:              org.Status,
+		MFARequired:         org.MFARequired,
+		CreatedAt:           org.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		DeletionScheduledAt: formatOptionalTime(org.DeletionScheduledAt),
+		MembersCount:        org.MembersCount,
+		CurrentUserRole:     org.CurrentUserRole,
+	}
+}
+
+type provisioningStatusResponse struct {
+	ID             string   `json:"id"`
+	Slug           string   `json:"slug"`
+	Status         string   `json:"status"`
+	CompletedSteps []string `json:"completed_steps"`
+	Error          string   `json:"error,omitempty"`
+}
+
+func newProvisioningStatusResponse(org *domain.Organization) provisioningStatusResponse {
+	resp := provisioningStatusResponse{
+		ID:             org.ID,
+		Slug:           org.Slug,
+		Status:         org.Status,
+		CompletedSteps: domain.CompletedProvisioningSteps(org.ProvisioningStep),
+	}
+	// The last error stays recorded while a resumed provisioning runs.
+	if org.Status == domain.StatusFailed {
+		resp.Error = org.ProvisioningError
+	}
+
+	return resp
+}
+
+type apiKeyResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"created_by"`
+	CreatedAt  string   `json:"created_at"`
+	ExpiresAt  *string  `json:"expires_at"`
+	LastUsedAt *string  `json:"last_used_at"`
+	RevokedAt  *string  `json:"revoked_at"`
+}
+
+// createdAPIKeyResponse is the only response that ever carries the plaintext token.
+type createdAPIKeyResponse struct {
+	apiKeyResponse
+	Token string `json:"token"`
+}
+
+func newAPIKeyResponse(key *domain.APIKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.DisplayPrefix(),
+		Scopes:     key.Scopes,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt.Format(time.RFC3339),
+		ExpiresAt:  formatOptionalTime(key.ExpiresAt),
+		LastUsedAt: formatOptionalTime(key.LastUsedAt),
+		RevokedAt:  formatOptionalTime(key.RevokedAt),
+	}
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
+type securityPolicyResponse struct {
+	MFARequired bool `json:"mfa_required"`
+}
+
+type dataExportResponse struct {
+	ID          string  `json:"id"`
+	Status      string  `json:"status"`
+	RequestedBy string  `json:"requested_by"`
+	SizeBytes   int64   `json:"size_bytes,omitempty"`
+	SHA256      string  `json:"sha256,omitempty"`
+	Error       string  `json:"error,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	StartedAt   *string `json:"started_at"`
+	CompletedAt *string `json:"completed_at"`
+}
+
+func newDataExportResponse(export *domain.DataExport) dataExportResponse {
+	return dataExportResponse{
+		ID:          export.ID,
+		Status:      export.Status,
+		RequestedBy: export.RequestedBy,
+		SizeBytes:   export.SizeBytes,
+		SHA256:      export.SHA256,
+		Error:       export.Error,
+		CreatedAt:   export.CreatedAt.Format(time.RFC3339),
+		StartedAt:   formatOptionalTime(export.StartedAt),
+		CompletedAt: formatOptionalTime(export.CompletedAt),
+	}
+}
+
+type dataExportDownloadResponse struct {
+	URL       string `json:"url"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type dataImportProgressResponse struct {
+	Step            string `json:"step,omitempty"`
+	RecordsTotal    int    `json:"records_total"`
+	RecordsImported int    `json:"records_imported"`
+	FilesTotal      int    `json:"files_total"`
+	FilesImported   int    `json:"files_imported"`
+	RemappedIDs     int    `json:"remapped_ids"`
+}
+
+type dataImportResponse struct {
+	ID                   string                     `json:"id"`
+	Status               string                     `json:"status"`
+	RequestedBy          string                     `json:"requested_by"`
+	SourceOrganizationID string                     `json:"source_organization_id,omitempty"`
+	Progress             dataImportProgressResponse `json:"progress"`
+	Error                string                     `json:"error,omitempty"`
+	CreatedAt            string                     `json:"created_at"`
+	StartedAt            *string                    `json:"started_at"`
+	CompletedAt          *string                    `json:"completed_at"`
+}
+
+func newDataImportResponse(dataImport *domain.DataImport) dataImportResponse {
+	return dataImportResponse{
+		ID:                   dataImport.ID,
+		Status:               dataImport.Status,
+		RequestedBy:          dataImport.RequestedBy,
+		SourceOrganizationID: dataImport.Progress.SourceOrganizationID,
+		Progress: dataImportProgressResponse{
+			Step:            dataImport.Progress.Step,
+			RecordsTotal:    dataImport.Progress.RecordsTotal,
+			RecordsImported: dataImport.Progress.RecordsImported,
+			FilesTotal:      dataImport.Progress.FilesTotal,
+			FilesImported:   dataImport.Progress.FilesImported,
+			RemappedIDs:     dataImport.Progress.RemappedIDs,
+		},
+		Error:       dataImport.Error,
+		CreatedAt:   dataImport.CreatedAt.Format(time.RFC3339),
+		StartedAt:   formatOptionalTime(dataImport.StartedAt),
+		CompletedAt: formatOptionalTime(dataImport.CompletedAt),
 	}
 }
+
+type dataImportUploadResponse struct {
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	ExpiresAt string            `json:"expires_at"`
+}
+
+// createdDataImportResponse carries the only link the bundle can be uploaded to.
+type createdDataImportResponse struct {
+	dataImportResponse
+	Upload dataImportUploadResponse `json:"upload"`
+}
+
+type auditEventResponse struct {
+	ID            string                  `json:"id"`
+	OccurredAt    string                  `json:"occurred_at"`
+	ActorType     string                  `json:"actor_type"`
+	ActorUserID   string                  `json:"actor_user_id,omitempty"`
+	ActorAPIKeyID string                  `json:"actor_api_key_id,omitempty"`
+	Action        string                  `json:"action"`
+	TargetType    string                  `json:"target_type,omitempty"`
+	TargetID      string                  `json:"target_id,omitempty"`
+	IPAddress     string                  `json:"ip_address,omitempty"`
+	UserAgent     string                  `json:"user_agent,omitempty"`
+	TraceID       string                  `json:"trace_id,omitempty"`
+	Changes       map[string]audit.Change `json:"changes,omitempty"`
+	Metadata      map[string]any          `json:"metadata,omitempty"`
+}
+
+func newAuditEventResponse(entry audit.Entry) auditEventResponse {
+	return auditEventResponse{
+		ID:            entry.ID,
+		OccurredAt:    entry.OccurredAt.Format(time.RFC3339),
+		ActorType:     entry.ActorType,
+		ActorUserID:   entry.ActorUserID,
+		ActorAPIKeyID: entry.ActorAPIKeyID,
+		Action:        entry.Action,
+		TargetType:    entry.TargetType,
+		TargetID:      entry.TargetID,
+		IPAddress:     entry.IPAddress,
+		UserAgent:     entry.UserAgent,
+		TraceID:       entry.TraceID,
+		Changes:       entry.Changes,
+		Metadata:      entry.Metadata,
+	}
+}
+
+type auditEventsResponse struct {
+	Data       []auditEventResponse `json:"data"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
@@ -3,23 +3,95 @@ package v1
 import (
 	"net/http"
 
+	"github.com/bowerbird/internal/platform/auth"
 	"github.com/bowerbird/internal/platform/config"
 	"github.com/bowerbird/internal/platform/http/api"
 )
 
 type Router struct {
-	controller *Controller
+	controller             *Controller
+	apiKeyController       *APIKeyController
+	securityController     *SecurityController
+	lifecycleController    *LifecycleController
+	provisioningController *ProvisioningController
+	exportController       *ExportController
+	importController       *ImportController
+	auditController        *AuditController
 }
 
-func NewRouter(controller *Controller) *Router {
+func NewRouter(controller *Controller, apiKeyController *APIKeyController, securityController *SecurityController, lifecycleController *LifecycleController, provisioningController *ProvisioningController, exportController *ExportController, importController *ImportController, auditController *AuditController) *Router {
 	if controller == nil {
 		panic("organization controller is required")
 	}
+	if apiKeyController == nil {
+		panic("api key controller is required")
+	}
+	if securityController == nil {
+		panic("security controller is required")
+	}
+	if lifecycleController == nil {
+		panic("lifecycle controller is required")
+	}
+	if provisioningController == nil {
+		panic("provisioning controller is required")
+	}
+	if exportController == nil {
+		panic("export controller is required")
+	}
+	if importController == nil {
+		panic("import controller is required")
+	}
+	if auditController == nil {
+		panic("audit controller is required")
+	}
 
-	return &Router{controller: controller}
+	return &Router{
+		controller:             controller,
+		apiKeyController:       apiKeyController,
+		securityController:     securityController,
+		lifecycleController:    lifecycleController,
+		provisioningController: provisioningController,
+		exportController:       exportController,
+		importController:       importController,
+		auditController:        auditController,
+	}
 }
 
 func (h *Router) Register(mux *http.ServeMux, cfg config.Config, authMiddleware func(http.Handler) http.Handler) {
 	mux.Handle("POST /api/v1/organizations", authMiddleware(api.Wrap(h.controller.CreateOrganization, cfg)))
 	mux.Handle("GET /api/v1/organizations/{id}", authMiddleware(api.Wrap(h.controller.GetOrganization, cfg)))
+
+	// Lifecycle routes are owner-only; the commands check ownership.
+	mux.Handle("PATCH /api/v1/organizations/{id}", authMiddleware(api.Wrap(h.lifecycleController.UpdateOrganization, cfg)))
+	mux.Handle("POST /api/v1/organizations/{id}/suspend", authMiddleware(api.Wrap(h.lifecycleController.SuspendOrganization, cfg)))
+	mux.Handle("POST /api/v1/organizations/{id}/archive", authMiddleware(api.Wrap(h.lifecycleController.ArchiveOrganization, cfg)))
+	mux.Handle("POST /api/v1/organizations/{id}/reactivate", authMiddleware(api.Wrap(h.lifecycleController.ReactivateOrganization, cfg)))
+	mux.Handle("DELETE /api/v1/organizations/{id}", authMiddleware(api.Wrap(h.lifecycleController.DeleteOrganization, cfg)))
+	mux.Handle("POST /api/v1/organizations/{id}/cancel-deletion", authMiddleware(api.Wrap(h.lifecycleController.CancelDeletion, cfg)))
+
+	mux.Handle("GET /api/v1/organizations/{id}/provisioning", authMiddleware(api.Wrap(h.provisioningController.GetProvisioningStatus, cfg)))
+	mux.Handle("POST /api/v1/organizations/{id}/provisioning/resume", authMiddleware(api.Wrap(h.provisioningController.ResumeProvisioning, cfg)))
+	mux.Handle("POST /api/v1/organizations/{id}/provisioning/rollback", authMiddleware(api.Wrap(h.provisioningController.RollbackProvisioning, cfg)))
+
+	// Exports and imports carry every member's data, so they are owner-only as well.
+	mux.Handle("POST /api/v1/organizations/{id}/exports", authMiddleware(api.Wrap(h.exportController.RequestExport, cfg)))
+	mux.Handle("GET /api/v1/organizations/{id}/exports", authMiddleware(api.Wrap(h.exportController.ListExports, cfg)))
+	mux.Handle("GET /api/v1/organizations/{id}/exports/{export_id}", authMiddleware(api.Wrap(h.exportController.GetExport, cfg)))
+	mux.Handle("GET /api/v1/organizations/{id}/exports/{export_id}/download", authMiddleware(api.Wrap(h.exportController.DownloadExport, cfg)))
+	mux.Handle("POST /api/v1/organizations/{id}/imports", authMiddleware(api.Wrap(h.importController.CreateImport, cfg)))
+	mux.Handle("GET /api/v1/organizations/{id}/imports", authMiddleware(api.Wrap(h.importController.ListImports, cfg)))
+	mux.Handle("GET /api/v1/organizations/{id}/imports/{import_id}", authMiddleware(api.Wrap(h.importController.GetImport, cfg)))
+	mux.Handle("POST /api/v1/organizations/{id}/imports/{import_id}/start", authMiddleware(api.Wrap(h.importController.StartImport, cfg)))
+
+	requireManageKeys := auth.RequirePermission(APIKeyManagePermission)
+	mux.Handle("GET /api/v1/organization/api-keys", authMiddleware(requireManageKeys(api.Wrap(h.apiKeyController.ListAPIKeys, cfg))))
+	mux.Handle("POST /api/v1/organization/api-keys", authMiddleware(requireManageKeys(api.Wrap(h.apiKeyController.CreateAPIKey, cfg))))
+	mux.Handle("DELETE /api/v1/organization/api-keys/{id}", authMiddleware(requireManageKeys(api.Wrap(h.apiKeyController.RevokeAPIKey, cfg))))
+
+	requireManageSecurity := auth.RequirePermission(SecurityPolicyManagePermission)
+	mux.Handle("PUT /api/v1/organization/security", authMiddleware(requireManageSecurity(api.Wrap(h.securityController.UpdateSecurityPolicy, cfg))))
+
+	requireReadAudit := auth.RequirePermission(AuditReadPermission)
+	mux.Handle("GET /api/v1/organization/audit-events", authMiddleware(requireReadAudit(api.Wrap(h.auditController.ListAuditEvents, cfg))))
+	mux.Handle("GET /api/v1/organization/audit-events/export", authMiddleware(requireReadAudit(api.Wrap(h.auditController.ExportAuditEvents, cfg))))
 }
@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/bowerbird/internal/organization/application/commands"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/auth"
+	appErrors "github.com/bowerbird/internal/platform/errors"
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+// SecurityPolicyManagePermission is the RBAC code required to change the organization's security policy.
+const SecurityPolicyManagePermission = "settings:write"
+
+type SecurityController struct {
+	updateCommand *commands.UpdateSecurityPolicyCommand
+}
+
+func NewSecurityController(updateCommand *commands.UpdateSecurityPolicyCommand) *SecurityController {
+	if updateCommand == nil {
+		panic("update security policy command is required")
+	}
+
+	return &SecurityController{updateCommand: updateCommand}
+}
+
+func (c *SecurityController) UpdateSecurityPolicy(w http.ResponseWriter, r *http.Request) error {
+	claims, ok := auth.ClaimsFromContext(r.Context())
+	if !ok || !claims.IsTenantScoped() {
+		return appErrors.New(appErrors.CodeUnauthorized, "a tenant-scoped token is required")
+	}
+	if claims.APIKeyID != "" {
+		return appErrors.New(appErrors.CodeForbidden, "api keys cannot change the security policy")
+	}
+
+	var req updateSecurityPolicyRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	if err := req.Validate(); err != nil {
+		return appErrors.Wrap(err, appErrors.CodeValidation, "invalid request body")
+	}
+
+	err := c.updateCommand.Execute(r.Context(), commands.UpdateSecurityPolicyInput{
+		TenantID:    claims.TenantID,
+		MFARequired: *req.MFARequired,
+		ActorHasMFA: claims.MFA,
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrMFASessionRequired) {
+			return appErrors.Wrap(err, appErrors.CodeMFARequired, "enable mfa on your own account before requiring it")
+		}
+		return appErrors.Wrap(err, appErrors.CodeInternal, "failed to update security policy")
+	}
+
+	return api.Success(w, http.StatusOK, securityPolicyResponse{MFARequired: *req.MFARequired})
+}
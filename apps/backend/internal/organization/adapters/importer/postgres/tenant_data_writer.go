@@ -0,0 +1,179 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// insertBatchSize bounds how many rows go to the server in one round trip.
+const insertBatchSize = 500
+
+// TenantDataWriter restores export bundles into tenant databases.
+type TenantDataWriter struct {
+	registry *database.Registry
+}
+
+func NewTenantDataWriter(registry *database.Registry) *TenantDataWriter {
+	return &TenantDataWriter{registry: registry}
+}
+
+func (w *TenantDataWriter) BeginImport(ctx context.Context, location domain.DatabaseLocation) (ports.TenantImport, error) {
+	ctx = database.WithTenantDatabase(ctx, location.DBName)
+	pool, err := w.registry.GetPoolByDBName(ctx, location.DBName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant db pool: %w", err)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin import: %w", err)
+	}
+
+	return &tenantImport{tx: tx, columns: map[string][]string{}}, nil
+}
+
+type tenantImport struct {
+	tx      pgx.Tx
+	columns map[string][]string
+}
+
+func (i *tenantImport) SchemaVersion(ctx context.Context) (uint, error) {
+	var version int64
+	if err := i.tx.QueryRow(ctx, `SELECT version FROM schema_migrations LIMIT 1`).Scan(&version); err != nil {
+		return 0, err
+	}
+	return uint(version), nil
+}
+
+func (i *tenantImport) TargetKeys(ctx context.Context) (*domain.ImportTargetKeys, error) {
+	keys := &domain.ImportTargetKeys{IDs: map[string]map[string]bool{}, Natural: map[string]map[string]string{}}
+	for _, spec := range domain.ImportDatasets {
+		if spec.IDColumn == "" {
+			continue
+		}
+
+		natural := "NULL::text"
+		if spec.NaturalKey != "" {
+			natural = pgx.Identifier{spec.NaturalKey}.Sanitize()
+		}
+		query := fmt.Sprintf(`SELECT %s, %s FROM %s`,
+			pgx.Identifier{spec.IDColumn}.Sanitize(), natural, pgx.Identifier{spec.Name}.Sanitize())
+
+		rows, err := i.tx.Query(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("read %s keys: %w", spec.Name, err)
+		}
+		ids, values := map[string]bool{}, map[string]string{}
+		for rows.Next() {
+			var id string
+			var value *string
+			if err := rows.Scan(&id, &value); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan %s keys: %w", spec.Name, err)
+			}
+			ids[id] = true
+			if value != nil {
+				values[*value] = id
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("read %s keys: %w", spec.Name, err)
+		}
+
+		keys.IDs[spec.Name] = ids
+		if spec.NaturalKey != "" {
+			keys.Natural[spec.Name] = values
+		}
+	}
+
+	return keys, nil
+}
+
+// Insert writes records through json_populate_record, so each value is
+// converted by Postgres to its column type. Columns the table does not have,
+// e.g. ones dropped since the bundle was made, are ignored; columns the
+// bundle lacks take their defaults.
+func (i *tenantImport) Insert(ctx context.Context, spec domain.ImportDatasetSpec, records []domain.ImportRecord) error {
+	columns, err := i.tableColumns(ctx, spec.Name)
+	if err != nil {
+		return err
+	}
+
+	table := pgx.Identifier{spec.Name}.Sanitize()
+	conflict := ""
+	if spec.Pivot {
+		conflict = " ON CONFLICT DO NOTHING"
+	}
+
+	for start := 0; start < len(records); start += insertBatchSize {
+		batch := &pgx.Batch{}
+		for _, record := range records[start:min(start+insertBatchSize, len(records))] {
+			var selected []string
+			for column := range record {
+				if slices.Contains(columns, column) {
+					selected = append(selected, pgx.Identifier{column}.Sanitize())
+				}
+			}
+			if len(selected) == 0 {
+				return fmt.Errorf("%w: %s record has no known column", domain.ErrInvalidImportBundle, spec.Name)
+			}
+			slices.Sort(selected)
+
+			payload, err := json.Marshal(record)
+			if err != nil {
+				return fmt.Errorf("encode %s record: %w", spec.Name, err)
+			}
+			list := strings.Join(selected, ", ")
+			batch.Queue(fmt.Sprintf(`INSERT INTO %s (%s) SELECT %s FROM json_populate_record(NULL::%s, $1::json)%s`,
+				table, list, list, table, conflict), payload)
+		}
+
+		if err := i.tx.SendBatch(ctx, batch).Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *tenantImport) tableColumns(ctx context.Context, table string) ([]string, error) {
+	if columns, ok := i.columns[table]; ok {
+		return columns, nil
+	}
+
+	rows, err := i.tx.Query(ctx, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1
+	`, table)
+	if err != nil {
+		return nil, fmt.Errorf("read %s columns: %w", table, err)
+	}
+	columns, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("read %s columns: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist", table)
+	}
+
+	i.columns[table] = columns
+	return columns, nil
+}
+
+func (i *tenantImport) Commit(ctx context.Context) error {
+	return i.tx.Commit(ctx)
+}
+
+// Rollback is a no-op once the import is committed.
+func (i *tenantImport) Rollback(ctx context.Context) error {
+	return i.tx.Rollback(ctx)
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	contractJobs "github.com/bowerbird/internal/organization/contracts/jobs"
+	platformJobs "github.com/bowerbird/internal/platform/jobs"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+// QueueExportScheduler builds data exports through the job queue.
+type QueueExportScheduler struct {
+	queue platformJobs.Queue
+	now   func() time.Time
+}
+
+func NewQueueExportScheduler(queue platformJobs.Queue) *QueueExportScheduler {
+	if queue == nil {
+		panic("job queue is required")
+	}
+
+	return &QueueExportScheduler{queue: queue, now: time.Now}
+}
+
+func (s *QueueExportScheduler) ScheduleExport(ctx context.Context, organizationID, exportID string) error {
+	payload, err := contractJobs.MarshalOrganizationExportRequested(contractJobs.OrganizationExportRequested{
+		OrganizationID: organizationID,
+		ExportID:       exportID,
+		RequestedAt:    s.now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.queue.Dispatch(tenant.WithTenantID(ctx, organizationID), platformJobs.Job{
+		Type:    contractJobs.OrganizationExportRequestedType,
+		Payload: payload,
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+
+	awsEvents "github.com/aws/aws-lambda-go/events"
+	commands "github.com/bowerbird/internal/organization/application/commands"
+	contractJobs "github.com/bowerbird/internal/organization/contracts/jobs"
+)
+
+type ProcessOrganizationExportRequested struct {
+	command *commands.RunDataExportCommand
+}
+
+func NewProcessOrganizationExportRequested(command *commands.RunDataExportCommand) *ProcessOrganizationExportRequested {
+	if command == nil {
+		panic("command is required")
+	}
+
+	return &ProcessOrganizationExportRequested{command: command}
+}
+
+func (h *ProcessOrganizationExportRequested) JobType() string {
+	return contractJobs.OrganizationExportRequestedType
+}
+
+func (h *ProcessOrganizationExportRequested) HandleSQS(ctx context.Context, message awsEvents.SQSMessage) error {
+	decoded, err := contractJobs.UnmarshalOrganizationExportRequested([]byte(message.Body))
+	if err != nil {
+		return err
+	}
+
+	return h.command.Execute(ctx, decoded.ExportID)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+
+	awsEvents "github.com/aws/aws-lambda-go/events"
+	commands "github.com/bowerbird/internal/organization/application/commands"
+	contractJobs "github.com/bowerbird/internal/organization/contracts/jobs"
+)
+
+type ProcessOrganizationImportRequested struct {
+	command *commands.RunDataImportCommand
+}
+
+func NewProcessOrganizationImportRequested(command *commands.RunDataImportCommand) *ProcessOrganizationImportRequested {
+	if command == nil {
+		panic("command is required")
+	}
+
+	return &ProcessOrganizationImportRequested{command: command}
+}
+
+func (h *ProcessOrganizationImportRequested) JobType() string {
+	return contractJobs.OrganizationImportRequestedType
+}
+
+func (h *ProcessOrganizationImportRequested) HandleSQS(ctx context.Context, message awsEvents.SQSMessage) error {
+	decoded, err := contractJobs.UnmarshalOrganizationImportRequested([]byte(message.Body))
+	if err != nil {
+		return err
+	}
+
+	return h.command.Execute(ctx, decoded.ImportID)
+}
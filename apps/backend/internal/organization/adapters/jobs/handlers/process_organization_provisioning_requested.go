@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+
+	awsEvents "github.com/aws/aws-lambda-go/events"
+	commands "github.com/bowerbird/internal/organization/application/commands"
+	contractJobs "github.com/bowerbird/internal/organization/contracts/jobs"
+)
+
+type ProcessOrganizationProvisioningRequested struct {
+	command *commands.ProvisionOrganizationCommand
+}
+
+func NewProcessOrganizationProvisioningRequested(command *commands.ProvisionOrganizationCommand) *ProcessOrganizationProvisioningRequested {
+	if command == nil {
+		panic("command is required")
+	}
+
+	return &ProcessOrganizationProvisioningRequested{command: command}
+}
+
+func (h *ProcessOrganizationProvisioningRequested) JobType() string {
+	return contractJobs.OrganizationProvisioningRequestedType
+}
+
+func (h *ProcessOrganizationProvisioningRequested) HandleSQS(ctx context.Context, message awsEvents.SQSMessage) error {
+	decoded, err := contractJobs.UnmarshalOrganizationProvisioningRequested([]byte(message.Body))
+	if err != nil {
+		return err
+	}
+
+	return h.command.Execute(ctx, decoded.OrganizationID)
+}
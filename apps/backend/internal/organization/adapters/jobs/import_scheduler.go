@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	contractJobs "github.com/bowerbird/internal/organization/contracts/jobs"
+	platformJobs "github.com/bowerbird/internal/platform/jobs"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+// QueueImportScheduler restores data imports through the job queue.
+type QueueImportScheduler struct {
+	queue platformJobs.Queue
+	now   func() time.Time
+}
+
+func NewQueueImportScheduler(queue platformJobs.Queue) *QueueImportScheduler {
+	if queue == nil {
+		panic("job queue is required")
+	}
+
+	return &QueueImportScheduler{queue: queue, now: time.Now}
+}
+
+func (s *QueueImportScheduler) ScheduleImport(ctx context.Context, organizationID, importID string) error {
+	payload, err := contractJobs.MarshalOrganizationImportRequested(contractJobs.OrganizationImportRequested{
+		OrganizationID: organizationID,
+		ImportID:       importID,
+		RequestedAt:    s.now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.queue.Dispatch(tenant.WithTenantID(ctx, organizationID), platformJobs.Job{
+		Type:    contractJobs.OrganizationImportRequestedType,
+		Payload: payload,
+	})
+}
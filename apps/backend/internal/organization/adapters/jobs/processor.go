@@ -0,0 +1,18 @@
+package jobs
+
+import (
+	"github.com/bowerbird/internal/organization/adapters/jobs/handlers"
+	commands "github.com/bowerbird/internal/organization/application/commands"
+)
+
+func NewOrganizationProvisioningRequestedProcessor(command *commands.ProvisionOrganizationCommand) *handlers.ProcessOrganizationProvisioningRequested {
+	return handlers.NewProcessOrganizationProvisioningRequested(command)
+}
+
+func NewOrganizationExportRequestedProcessor(command *commands.RunDataExportCommand) *handlers.ProcessOrganizationExportRequested {
+	return handlers.NewProcessOrganizationExportRequested(command)
+}
+
+func NewOrganizationImportRequestedProcessor(command *commands.RunDataImportCommand) *handlers.ProcessOrganizationImportRequested {
+	return handlers.NewProcessOrganizationImportRequested(command)
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	contractJobs "github.com/bowerbird/internal/organization/contracts/jobs"
+	platformJobs "github.com/bowerbird/internal/platform/jobs"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+// QueueProvisioningScheduler provisions organizations through the job queue.
+type QueueProvisioningScheduler struct {
+	queue platformJobs.Queue
+	now   func() time.Time
+}
+
+func NewQueueProvisioningScheduler(queue platformJobs.Queue) *QueueProvisioningScheduler {
+	if queue == nil {
+		panic("job queue is required")
+	}
+
+	return &QueueProvisioningScheduler{queue: queue, now: time.Now}
+}
+
+func (s *QueueProvisioningScheduler) ScheduleProvisioning(ctx context.Context, organizationID string) error {
+	payload, err := contractJobs.MarshalOrganizationProvisioningRequested(contractJobs.OrganizationProvisioningRequested{
+		OrganizationID: organizationID,
+		RequestedAt:    s.now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		return err
+	}
+
+	// The queue routes jobs by tenant; the organization is that tenant even
+	// though its database does not exist yet.
+	return s.queue.Dispatch(tenant.WithTenantID(ctx, organizationID), platformJobs.Job{
+		Type:    contractJobs.OrganizationProvisioningRequestedType,
+		Payload: payload,
+	})
+}
@@ -2,17 +2,26 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"net/url"
 	"regexp"
 
 	"github.com/bowerbird/internal/organization/domain"
 	"github.com/bowerbird/internal/platform/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var validDBName = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 
+// duplicateDatabaseCode is the Postgres error code for an existing database.
+const duplicateDatabaseCode = "42P04"
+
+// ownerRoleName is the system role seeded by the tenant migrations that holds
+// every permission.
+const ownerRoleName = "admin"
+
 type PostgresProvisioner struct {
 	pool          *pgxpool.Pool
 	baseURL       string
@@ -27,9 +36,38 @@ func NewPostgresProvisioner(pool *pgxpool.Pool, baseURL string, migrationsDir st
 	}
 }
 
-func (p *PostgresProvisioner) CreateDatabase(ctx context.Context, dbName string) error {
-	if !validDBName.MatchString(dbName) {
-		return fmt.Errorf("invalid database name: %s", dbName)
+// CreateDatabase creates the tenant database or, in a shared database, the
+// shared database when missing and then the tenant schema.
+func (p *PostgresProvisioner) CreateDatabase(ctx context.Context, location domain.DatabaseLocation) error {
+	if err := validateLocation(location); err != nil {
+		return err
+	}
+
+	if err := p.createPhysicalDatabase(ctx, location.Database()); err != nil {
+		return err
+	}
+	if !location.Shared() {
+		return nil
+	}
+
+	return p.withDatabase(ctx, location.SharedDatabase, func(pool *pgxpool.Pool) error {
+		query := fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s", pgx.Identifier{location.DBName}.Sanitize())
+		if _, err := pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("execute create schema: %w", err)
+		}
+		return nil
+	})
+}
+
+func (p *PostgresProvisioner) createPhysicalDatabase(ctx context.Context, dbName string) error {
+	// Postgres has no CREATE DATABASE IF NOT EXISTS, so resumed provisioning
+	// checks first and tolerates losing a race with another attempt.
+	var exists bool
+	if err := p.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = $1)`, dbName).Scan(&exists); err != nil {
+		return fmt.Errorf("check database exists: %w", err)
+	}
+	if exists {
+		return nil
 	}
 
 	// CREATE DATABASE cannot run inside a transaction block or as a prepared statement easily in pgx
@@ -37,22 +75,57 @@ func (p *PostgresProvisioner) CreateDatabase(ctx context.Context, dbName string)
 	query := fmt.Sprintf("CREATE DATABASE %s", dbName)
 	_, err := p.pool.Exec(ctx, query)
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == duplicateDatabaseCode {
+			return nil
+		}
 		return fmt.Errorf("execute create database: %w", err)
 	}
 
 	return nil
 }
 
-func (p *PostgresProvisioner) MigrateDatabase(ctx context.Context, dbName string) error {
-	u, err := url.Parse(p.baseURL)
-	if err != nil {
-		return fmt.Errorf("parse base url: %w", err)
+// DropDatabase removes the tenant database, disconnecting any open sessions,
+// or only the tenant schema when the database is shared. It is a no-op when
+// the database or schema does not exist, so purges can be retried.
+func (p *PostgresProvisioner) DropDatabase(ctx context.Context, location domain.DatabaseLocation) error {
+	if err := validateLocation(location); err != nil {
+		return err
 	}
 
-	// Point the URL to the newly created tenant database
-	u.Path = "/" + dbName
-	tenantDBURL := u.String()
+	if !location.Shared() {
+		query := fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", location.DBName)
+		if _, err := p.pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("execute drop database: %w", err)
+		}
+		return nil
+	}
 
+	var exists bool
+	if err := p.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pg_database WHERE datname = $1)`, location.SharedDatabase).Scan(&exists); err != nil {
+		return fmt.Errorf("check database exists: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	return p.withDatabase(ctx, location.SharedDatabase, func(pool *pgxpool.Pool) error {
+		query := fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", pgx.Identifier{location.DBName}.Sanitize())
+		if _, err := pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("execute drop schema: %w", err)
+		}
+		return nil
+	})
+}
+
+func (p *PostgresProvisioner) MigrateDatabase(ctx context.Context, location domain.DatabaseLocation) error {
+	tenantDBURL, err := tenantURL(p.baseURL, location)
+	if err != nil {
+		return err
+	}
+
+	// golang-migrate keeps schema_migrations in the first schema of the
+	// search_path, so every shared tenant tracks its own version.
 	err = database.RunMigrations(tenantDBURL, p.migrationsDir)
 	if err != nil {
 		return fmt.Errorf("run tenant migrations: %w", err)
@@ -61,20 +134,16 @@ func (p *PostgresProvisioner) MigrateDatabase(ctx context.Context, dbName string
 	return nil
 }
 
-func (p *PostgresProvisioner) SeedOwner(ctx context.Context, dbName string, owner domain.OwnerData) error {
-	u, err := url.Parse(p.baseURL)
+func (p *PostgresProvisioner) SeedOwner(ctx context.Context, location domain.DatabaseLocation, owner domain.OwnerData) error {
+	tenantDBURL, err := tenantURL(p.baseURL, location)
 	if err != nil {
-		return fmt.Errorf("parse base url: %w", err)
+		return err
 	}
 
-	// Point the URL to the newly created tenant database
-	u.Path = "/" + dbName
-	tenantDBURL := u.String()
-
 	// Connect specifically to the new tenant's database
 	tenantPool, err := pgxpool.New(ctx, tenantDBURL)
 	if err != nil {
-		return fmt.Errorf("connect to tenant db %s: %w", dbName, err)
+		return fmt.Errorf("connect to tenant db %s: %w", location.DBName, err)
 	}
 	defer tenantPool.Close()
 
@@ -90,14 +159,70 @@ func (p *PostgresProvisioner) SeedOwner(ctx context.Context, dbName string, owne
 	_, err = tx.Exec(ctx, `
 		INSERT INTO users (id, email, first_name, last_name, picture_url) 
 		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO NOTHING
 	`, owner.ID, owner.Email, owner.FirstName, owner.LastName, owner.AvatarURL)
 	if err != nil {
 		return fmt.Errorf("insert owner user profile: %w", err)
 	}
 
+	// 2. Grant the owner the system admin role, the only source of
+	// permissions for tenant-scoped tokens
+	var adminRoleID string
+	if err := tx.QueryRow(ctx, `SELECT id FROM roles WHERE name = $1`, ownerRoleName).Scan(&adminRoleID); err != nil {
+		return fmt.Errorf("find admin role: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO user_roles (user_id, role_id)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, role_id) DO NOTHING
+	`, owner.ID, adminRoleID)
+	if err != nil {
+		return fmt.Errorf("assign owner admin role: %w", err)
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return fmt.Errorf("commit tenant tx: %w", err)
 	}
 
 	return nil
 }
+
+// withDatabase runs fn on a short-lived pool connected to dbName.
+func (p *PostgresProvisioner) withDatabase(ctx context.Context, dbName string, fn func(*pgxpool.Pool) error) error {
+	dbURL, err := database.TenantURL(p.baseURL, dbName, "")
+	if err != nil {
+		return fmt.Errorf("build database url: %w", err)
+	}
+
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		return fmt.Errorf("connect to db %s: %w", dbName, err)
+	}
+	defer pool.Close()
+
+	return fn(pool)
+}
+
+func tenantURL(baseURL string, location domain.DatabaseLocation) (string, error) {
+	schema := ""
+	if location.Shared() {
+		schema = location.DBName
+	}
+
+	tenantDBURL, err := database.TenantURL(baseURL, location.Database(), schema)
+	if err != nil {
+		return "", fmt.Errorf("build tenant db url: %w", err)
+	}
+	return tenantDBURL, nil
+}
+
+func validateLocation(location domain.DatabaseLocation) error {
+	if !validDBName.MatchString(location.DBName) {
+		return fmt.Errorf("invalid database name: %s", location.DBName)
+	}
+	if location.Shared() && !validDBName.MatchString(location.SharedDatabase) {
+		return fmt.Errorf("invalid shared database name: %s", location.SharedDatabase)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/bowerbird/internal/platform/database"
+	"github.com/bowerbird/internal/platform/database/databasetest"
+	"github.com/bowerbird/internal/platform/id"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestProvisionedOwnerPassesRequirePermission(t *testing.T) {
+	ctx := context.Background()
+	admin := databasetest.AdminPool(t)
+	serverURL := databasetest.ServerURL(t)
+
+	provisioner := NewPostgresProvisioner(admin, serverURL, databasetest.MigrationsDir(t, "tenant"))
+	location := domain.DatabaseLocation{DBName: "test_" + strings.ToLower(id.NewULID())}
+	t.Cleanup(func() {
+		if err := provisioner.DropDatabase(context.Background(), location); err != nil {
+			t.Errorf("drop tenant database: %v", err)
+		}
+	})
+
+	owner := domain.OwnerData{ID: id.NewULID(), Email: "owner@example.com", FirstName: "Ana"}
+	if err := provisioner.CreateDatabase(ctx, location); err != nil {
+		t.Fatalf("create database: %v", err)
+	}
+	if err := provisioner.MigrateDatabase(ctx, location); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	// Seeding twice, as a resumed provisioning does, must stay idempotent.
+	for range 2 {
+		if err := provisioner.SeedOwner(ctx, location, owner); err != nil {
+			t.Fatalf("seed owner: %v", err)
+		}
+	}
+
+	tenantURL, err := database.TenantURL(serverURL, location.DBName, "")
+	if err != nil {
+		t.Fatalf("build tenant url: %v", err)
+	}
+	tenantPool, err := pgxpool.New(ctx, tenantURL)
+	if err != nil {
+		t.Fatalf("connect to tenant database: %v", err)
+	}
+	defer tenantPool.Close()
+
+	// Same resolution as the tenant token exchange.
+	rows, err := tenantPool.Query(ctx, `
+		SELECT DISTINCT p.code
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1
+	`, owner.ID)
+	if err != nil {
+		t.Fatalf("query owner permissions: %v", err)
+	}
+	var permissions []string
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			t.Fatalf("scan permission: %v", err)
+		}
+		permissions = append(permissions, code)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("read permissions: %v", err)
+	}
+
+	claims := &auth.CustomClaims{
+		UserID:       owner.ID,
+		TenantClaims: auth.TenantClaims{TenantID: "tenant-1", Role: "OWNER", Permissions: permissions},
+	}
+	handler := auth.RequirePermission("settings:write")(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/organization/security", nil)
+	req = req.WithContext(auth.WithClaims(req.Context(), claims))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected the provisioned owner to pass RequirePermission, got %d with permissions %v", rec.Code, permissions)
+	}
+}
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lastUsedResolution throttles last_used_at writes so a busy integration does
+// not turn every authenticated request into an UPDATE.
+const lastUsedResolution = time.Minute
+
+// creatorActiveColumn tells whether the key's creator still has an active
+// membership in the key's organization.
+const creatorActiveColumn = `EXISTS (
+	SELECT 1 FROM tenant_memberships m
+	WHERE m.user_id = k.created_by AND m.tenant_id = k.tenant_id AND m.deleted_at IS NULL
+)`
+
+type APIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIKeyRepository(pool *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{pool: pool}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, tenant_id, name, secret_hash, scopes, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.pool.Exec(ctx, query,
+		key.ID,
+		key.TenantID,
+		key.Name,
+		key.SecretHash,
+		key.Scopes,
+		key.CreatedBy,
+		key.ExpiresAt,
+		key.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) ListByTenant(ctx context.Context, tenantID string) ([]*domain.APIKey, error) {
+	query := `
+		SELECT k.id, k.tenant_id, t.slug, k.name, k.secret_hash, k.scopes, k.created_by, ` + creatorActiveColumn + `, k.expires_at, k.last_used_at, k.revoked_at, k.created_at
+		FROM api_keys k
+		JOIN tenants t ON t.id = k.tenant_id
+		WHERE k.tenant_id = $1
+		ORDER BY k.created_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*domain.APIKey, error) {
+	query := `
+		SELECT k.id, k.tenant_id, t.slug, k.name, k.secret_hash, k.scopes, k.created_by, ` + creatorActiveColumn + `, k.expires_at, k.last_used_at, k.revoked_at, k.created_at
+		FROM api_keys k
+		JOIN tenants t ON t.id = k.tenant_id
+		WHERE k.id = $1 AND t.status = 'active'
+	`
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return key, nil
+}
+
+func (r *APIKeyRepository) Revoke(ctx context.Context, tenantID, id string, at time.Time) error {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND tenant_id = $3 AND revoked_at IS NULL`
+	tag, err := r.pool.Exec(ctx, query, at, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $1 WHERE id = $2 AND (last_used_at IS NULL OR last_used_at < $3)`
+	_, err := r.pool.Exec(ctx, query, at, id, at.Add(-lastUsedResolution))
+	if err != nil {
+		return fmt.Errorf("failed to update api key last_used_at: %w", err)
+	}
+	return nil
+}
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	var key domain.APIKey
+	err := row.Scan(
+		&key.ID,
+		&key.TenantID,
+		&key.TenantSlug,
+		&key.Name,
+		&key.SecretHash,
+		&key.Scopes,
+		&key.CreatedBy,
+		&key.CreatorActive,
+		&key.ExpiresAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const exportColumns = `
+	e.id, e.tenant_id, e.requested_by, COALESCE(u.email, ''), e.status, COALESCE(e.file_path, ''),
+	COALESCE(e.size_bytes, 0), COALESCE(e.sha256, ''), COALESCE(e.error, ''), e.created_at, e.started_at, e.completed_at
+`
+
+type ExportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewExportRepository(pool *pgxpool.Pool) *ExportRepository {
+	return &ExportRepository{pool: pool}
+}
+
+func (r *ExportRepository) Create(ctx context.Context, export *domain.DataExport) error {
+	query := `
+		INSERT INTO organization_exports (id, tenant_id, requested_by, status, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.pool.Exec(ctx, query, export.ID, export.OrganizationID, export.RequestedBy, export.Status, export.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create data export: %w", err)
+	}
+	return nil
+}
+
+func (r *ExportRepository) Get(ctx context.Context, organizationID, exportID string) (*domain.DataExport, error) {
+	query := `
+		SELECT ` + exportColumns + `
+		FROM organization_exports e
+		LEFT JOIN users u ON u.id = e.requested_by
+		WHERE e.id = $1 AND e.tenant_id = $2
+	`
+	return r.get(ctx, query, exportID, organizationID)
+}
+
+func (r *ExportRepository) GetByID(ctx context.Context, exportID string) (*domain.DataExport, error) {
+	query := `
+		SELECT ` + exportColumns + `
+		FROM organization_exports e
+		LEFT JOIN users u ON u.id = e.requested_by
+		WHERE e.id = $1
+	`
+	return r.get(ctx, query, exportID)
+}
+
+func (r *ExportRepository) get(ctx context.Context, query string, args ...any) (*domain.DataExport, error) {
+	export, err := scanExport(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrExportNotFound
+		}
+		return nil, fmt.Errorf("failed to get data export: %w", err)
+	}
+	return export, nil
+}
+
+func (r *ExportRepository) ListByOrganization(ctx context.Context, organizationID string) ([]*domain.DataExport, error) {
+	query := `
+		SELECT ` + exportColumns + `
+		FROM organization_exports e
+		LEFT JOIN users u ON u.id = e.requested_by
+		WHERE e.tenant_id = $1
+		ORDER BY e.created_at DESC
+	`
+	rows, err := r.pool.Query(ctx, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data exports: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []*domain.DataExport{}
+	for rows.Next() {
+		export, err := scanExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data export: %w", err)
+		}
+		exports = append(exports, export)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating data exports: %w", err)
+	}
+	return exports, nil
+}
+
+func (r *ExportRepository) Claim(ctx context.Context, exportID string) (bool, error) {
+	query := `UPDATE organization_exports SET status = $1, started_at = NOW() WHERE id = $2 AND status = $3`
+	tag, err := r.pool.Exec(ctx, query, domain.ExportStatusRunning, exportID, domain.ExportStatusPending)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim data export: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *ExportRepository) Complete(ctx context.Context, exportID, filePath string, sizeBytes int64, sha256 string) error {
+	query := `
+		UPDATE organization_exports
+		SET status = $1, file_path = $2, size_bytes = $3, sha256 = $4, error = NULL, completed_at = NOW()
+		WHERE id = $5
+	`
+	_, err := r.pool.Exec(ctx, query, domain.ExportStatusCompleted, filePath, sizeBytes, sha256, exportID)
+	if err != nil {
+		return fmt.Errorf("failed to complete data export: %w", err)
+	}
+	return nil
+}
+
+func (r *ExportRepository) Fail(ctx context.Context, exportID, cause string) error {
+	query := `UPDATE organization_exports SET status = $1, error = $2, completed_at = NOW() WHERE id = $3`
+	_, err := r.pool.Exec(ctx, query, domain.ExportStatusFailed, cause, exportID)
+	if err != nil {
+		return fmt.Errorf("failed to mark data export as failed: %w", err)
+	}
+	return nil
+}
+
+func scanExport(row pgx.Row) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := row.Scan(
+		&export.ID,
+		&export.OrganizationID,
+		&export.RequestedBy,
+		&export.RequestedByEmail,
+		&export.Status,
+		&export.FilePath,
+		&export.SizeBytes,
+		&export.SHA256,
+		&export.Error,
+		&export.CreatedAt,
+		&export.StartedAt,
+		&export.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
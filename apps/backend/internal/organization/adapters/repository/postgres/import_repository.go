@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const importColumns = `
+	id, tenant_id, requested_by, status, file_path, COALESCE(source_organization_id, ''), COALESCE(step, ''),
+	records_total, records_imported, files_total, files_imported, remapped_ids, COALESCE(error, ''),
+	created_at, started_at, completed_at
+`
+
+type ImportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewImportRepository(pool *pgxpool.Pool) *ImportRepository {
+	return &ImportRepository{pool: pool}
+}
+
+func (r *ImportRepository) Create(ctx context.Context, dataImport *domain.DataImport) error {
+	query := `
+		INSERT INTO organization_imports (id, tenant_id, requested_by, status, file_path, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.pool.Exec(ctx, query, dataImport.ID, dataImport.OrganizationID, dataImport.RequestedBy, dataImport.Status, dataImport.FilePath, dataImport.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create data import: %w", err)
+	}
+	return nil
+}
+
+func (r *ImportRepository) Get(ctx context.Context, organizationID, importID string) (*domain.DataImport, error) {
+	query := `SELECT ` + importColumns + ` FROM organization_imports WHERE id = $1 AND tenant_id = $2`
+	return r.get(ctx, query, importID, organizationID)
+}
+
+func (r *ImportRepository) GetByID(ctx context.Context, importID string) (*domain.DataImport, error) {
+	query := `SELECT ` + importColumns + ` FROM organization_imports WHERE id = $1`
+	return r.get(ctx, query, importID)
+}
+
+func (r *ImportRepository) get(ctx context.Context, query string, args ...any) (*domain.DataImport, error) {
+	dataImport, err := scanImport(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrImportNotFound
+		}
+		return nil, fmt.Errorf("failed to get data import: %w", err)
+	}
+	return dataImport, nil
+}
+
+func (r *ImportRepository) ListByOrganization(ctx context.Context, organizationID string) ([]*domain.DataImport, error) {
+	query := `SELECT ` + importColumns + ` FROM organization_imports WHERE tenant_id = $1 ORDER BY created_at DESC`
+	rows, err := r.pool.Query(ctx, query, organizationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query data imports: %w", err)
+	}
+	defer rows.Close()
+
+	imports := []*domain.DataImport{}
+	for rows.Next() {
+		dataImport, err := scanImport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data import: %w", err)
+		}
+		imports = append(imports, dataImport)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating data imports: %w", err)
+	}
+	return imports, nil
+}
+
+func (r *ImportRepository) MarkUploaded(ctx context.Context, importID string) (bool, error) {
+	return r.transition(ctx, importID, domain.ImportStatusAwaitingUpload, domain.ImportStatusPending, "")
+}
+
+func (r *ImportRepository) Claim(ctx context.Context, importID string) (bool, error) {
+	return r.transition(ctx, importID, domain.ImportStatusPending, domain.ImportStatusRunning, ", started_at = NOW()")
+}
+
+func (r *ImportRepository) transition(ctx context.Context, importID, from, to, set string) (bool, error) {
+	query := `UPDATE organization_imports SET status = $1` + set + ` WHERE id = $2 AND status = $3`
+	tag, err := r.pool.Exec(ctx, query, to, importID, from)
+	if err != nil {
+		return false, fmt.Errorf("failed to move data import to %s: %w", to, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *ImportRepository) UpdateProgress(ctx context.Context, importID string, progress domain.ImportProgress) error {
+	query := `
+		UPDATE organization_imports
+		SET step = $1, source_organization_id = NULLIF($2, ''), records_total = $3, records_imported = $4,
+		    files_total = $5, files_imported = $6, remapped_ids = $7
+		WHERE id = $8
+	`
+	_, err := r.pool.Exec(ctx, query, progress.Step, progress.SourceOrganizationID, progress.RecordsTotal, progress.RecordsImported,
+		progress.FilesTotal, progress.FilesImported, progress.RemappedIDs, importID)
+	if err != nil {
+		return fmt.Errorf("failed to update data import progress: %w", err)
+	}
+	return nil
+}
+
+func (r *ImportRepository) Complete(ctx context.Context, importID string, progress domain.ImportProgress) error {
+	if err := r.UpdateProgress(ctx, importID, progress); err != nil {
+		return err
+	}
+
+	query := `UPDATE organization_imports SET status = $1, error = NULL, completed_at = NOW() WHERE id = $2`
+	_, err := r.pool.Exec(ctx, query, domain.ImportStatusCompleted, importID)
+	if err != nil {
+		return fmt.Errorf("failed to complete data import: %w", err)
+	}
+	return nil
+}
+
+func (r *ImportRepository) Fail(ctx context.Context, importID, cause string) error {
+	query := `UPDATE organization_imports SET status = $1, error = $2, completed_at = NOW() WHERE id = $3`
+	_, err := r.pool.Exec(ctx, query, domain.ImportStatusFailed, cause, importID)
+	if err != nil {
+		return fmt.Errorf("failed to mark data import as failed: %w", err)
+	}
+	return nil
+}
+
+func scanImport(row pgx.Row) (*domain.DataImport, error) {
+	var dataImport domain.DataImport
+	err := row.Scan(
+		&dataImport.ID,
+		&dataImport.OrganizationID,
+		&dataImport.RequestedBy,
+		&dataImport.Status,
+		&dataImport.FilePath,
+		&dataImport.Progress.SourceOrganizationID,
+		&dataImport.Progress.Step,
+		&dataImport.Progress.RecordsTotal,
+		&dataImport.Progress.RecordsImported,
+		&dataImport.Progress.FilesTotal,
+		&dataImport.Progress.FilesImported,
+		&dataImport.Progress.RemappedIDs,
+		&dataImport.Error,
+		&dataImport.CreatedAt,
+		&dataImport.StartedAt,
+		&dataImport.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &dataImport, nil
+}
@@ -2,9 +2,14 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/bowerbird/internal/organization/domain"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -17,9 +22,17 @@ func NewPostgresRepository(pool *pgxpool.Pool) *PostgresRepository {
 }
 
 func (r *PostgresRepository) Create(ctx context.Context, org *domain.Organization) error {
+	var owner []byte
+	if org.ProvisioningOwner != nil {
+		var err error
+		if owner, err = json.Marshal(org.ProvisioningOwner); err != nil {
+			return fmt.Errorf("marshal provisioning owner: %w", err)
+		}
+	}
+
 	query := `
-		INSERT INTO tenants (id, organization_name, slug, db_name, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO tenants (id, organization_name, slug, db_name, status, isolation_mode, shared_database, provisioning_owner, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10)
 	`
 	_, err := r.pool.Exec(ctx, query,
 		org.ID,
@@ -27,6 +40,9 @@ func (r *PostgresRepository) Create(ctx context.Context, org *domain.Organizatio
 		org.Slug,
 		org.DBName,
 		org.Status,
+		org.IsolationMode,
+		org.SharedDatabase,
+		owner,
 		org.CreatedAt,
 		org.UpdatedAt,
 	)
@@ -34,41 +50,62 @@ func (r *PostgresRepository) Create(ctx context.Context, org *domain.Organizatio
 	return err
 }
 
+// ExistsBySlug also counts former slugs, so a new organization cannot take
+// over the links of a renamed one.
 func (r *PostgresRepository) ExistsBySlug(ctx context.Context, slug string) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM tenants WHERE slug = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM tenants WHERE slug = $1) OR EXISTS(SELECT 1 FROM tenant_slug_redirects WHERE slug = $1)`
 	err := r.pool.QueryRow(ctx, query, slug).Scan(&exists)
 	return exists, err
 }
 
 func (r *PostgresRepository) GetByID(ctx context.Context, id, userID string) (*domain.Organization, error) {
 	query := `
-		SELECT t.id, t.organization_name, t.slug, t.db_name, t.status, t.created_at, t.updated_at,
-		       (SELECT COUNT(*) FROM tenant_memberships WHERE tenant_id = t.id) as members_count,
+		SELECT t.id, t.organization_name, t.slug, t.db_name, t.isolation_mode, COALESCE(t.shared_database, ''),
+		       t.status, t.mfa_required, t.deletion_scheduled_at,
+		       t.provisioning_step, COALESCE(t.provisioning_error, ''), t.provisioning_owner, t.created_at, t.updated_at,
+		       (SELECT COUNT(*) FROM tenant_memberships WHERE tenant_id = t.id AND deleted_at IS NULL) as members_count,
 			   tm.role
 		FROM tenants t
-		LEFT JOIN tenant_memberships tm ON tm.tenant_id = t.id AND tm.user_id = $2
+		LEFT JOIN tenant_memberships tm ON tm.tenant_id = t.id AND tm.user_id = $2 AND tm.deleted_at IS NULL
 		WHERE t.id = $1
 	`
 	org := &domain.Organization{}
 	var role *string
+	var owner []byte
 	err := r.pool.QueryRow(ctx, query, id, userID).Scan(
 		&org.ID,
 		&org.Name,
 		&org.Slug,
 		&org.DBName,
+		&org.IsolationMode,
+		&org.SharedDatabase,
 		&org.Status,
+		&org.MFARequired,
+		&org.DeletionScheduledAt,
+		&org.ProvisioningStep,
+		&org.ProvisioningError,
+		&owner,
 		&org.CreatedAt,
 		&org.UpdatedAt,
 		&org.MembersCount,
 		&role,
 	)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOrganizationNotFound
+		}
 		return nil, err
 	}
 	if role != nil {
 		org.CurrentUserRole = *role
 	}
+	if owner != nil {
+		org.ProvisioningOwner = &domain.OwnerData{}
+		if err := json.Unmarshal(owner, org.ProvisioningOwner); err != nil {
+			return nil, fmt.Errorf("unmarshal provisioning owner: %w", err)
+		}
+	}
 	return org, nil
 }
 
@@ -79,7 +116,288 @@ func (r *PostgresRepository) UpdateStatus(ctx context.Context, organizationID, s
 }
 
 func (r *PostgresRepository) AddMembership(ctx context.Context, userID, tenantID, role string) error {
-	query := `INSERT INTO tenant_memberships (user_id, tenant_id, role) VALUES ($1, $2, $3)`
+	query := `INSERT INTO tenant_memberships (user_id, tenant_id, role) VALUES ($1, $2, $3) ON CONFLICT (user_id, tenant_id) DO NOTHING`
 	_, err := r.pool.Exec(ctx, query, userID, tenantID, role)
 	return err
 }
+
+func (r *PostgresRepository) CompleteProvisioningStep(ctx context.Context, organizationID, step string) error {
+	query := `UPDATE tenants SET provisioning_step = $1, provisioning_error = NULL, updated_at = $2 WHERE id = $3`
+	_, err := r.pool.Exec(ctx, query, step, time.Now().UTC(), organizationID)
+	return err
+}
+
+func (r *PostgresRepository) FailProvisioning(ctx context.Context, organizationID, cause string) error {
+	query := `UPDATE tenants SET status = $1, provisioning_error = $2, updated_at = $3 WHERE id = $4`
+	_, err := r.pool.Exec(ctx, query, domain.StatusFailed, cause, time.Now().UTC(), organizationID)
+	return err
+}
+
+func (r *PostgresRepository) SetMFARequired(ctx context.Context, organizationID string, required bool) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE tenants SET mfa_required = $1, updated_at = $2 WHERE id = $3`
+	if _, err := tx.Exec(ctx, query, required, time.Now().UTC(), organizationID); err != nil {
+		return err
+	}
+
+	// Tenant tokens issued before the policy carry no MFA guarantee; bumping
+	// the membership versions makes the auth middleware reject them now.
+	if required {
+		query = `UPDATE tenant_memberships SET version = version + 1 WHERE tenant_id = $1 AND deleted_at IS NULL`
+		if _, err := tx.Exec(ctx, query, organizationID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) Rename(ctx context.Context, organizationID, name string, event *domain.AuditEvent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE tenants SET organization_name = $1, updated_at = $2 WHERE id = $3`
+	tag, err := tx.Exec(ctx, query, name, time.Now().UTC(), organizationID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOrganizationNotFound
+	}
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) ChangeSlug(ctx context.Context, organizationID, oldSlug, newSlug string, event *domain.AuditEvent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var taken bool
+	query := `
+		SELECT EXISTS(SELECT 1 FROM tenants WHERE slug = $1 AND id <> $2)
+		    OR EXISTS(SELECT 1 FROM tenant_slug_redirects WHERE slug = $1 AND tenant_id <> $2)
+	`
+	if err := tx.QueryRow(ctx, query, newSlug, organizationID).Scan(&taken); err != nil {
+		return err
+	}
+	if taken {
+		return domain.ErrSlugAlreadyExists
+	}
+
+	// Taking back a former slug removes its redirect.
+	if _, err := tx.Exec(ctx, `DELETE FROM tenant_slug_redirects WHERE slug = $1`, newSlug); err != nil {
+		return err
+	}
+
+	query = `INSERT INTO tenant_slug_redirects (slug, tenant_id, created_at) VALUES ($1, $2, $3) ON CONFLICT (slug) DO NOTHING`
+	if _, err := tx.Exec(ctx, query, oldSlug, organizationID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	query = `UPDATE tenants SET slug = $1, updated_at = $2 WHERE id = $3 AND slug = $4`
+	tag, err := tx.Exec(ctx, query, newSlug, time.Now().UTC(), organizationID, oldSlug)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrSlugAlreadyExists
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOrganizationNotFound
+	}
+
+	// Tenant tokens carry the slug; revoke them so clients pick up the new one.
+	query = `UPDATE tenant_memberships SET version = version + 1 WHERE tenant_id = $1 AND deleted_at IS NULL`
+	if _, err := tx.Exec(ctx, query, organizationID); err != nil {
+		return err
+	}
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) ListSlugRedirects(ctx context.Context, organizationID string) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT slug FROM tenant_slug_redirects WHERE tenant_id = $1 ORDER BY created_at`, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+func (r *PostgresRepository) ChangeStatus(ctx context.Context, organizationID string, from []string, status string, event *domain.AuditEvent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE tenants SET status = $1, updated_at = $2 WHERE id = $3 AND status = ANY($4)`
+	tag, err := tx.Exec(ctx, query, status, time.Now().UTC(), organizationID, from)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidStatusTransition
+	}
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) ScheduleDeletion(ctx context.Context, organizationID string, from []string, purgeAt time.Time, event *domain.AuditEvent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE tenants
+		SET status_before_deletion = status, status = $1, deletion_scheduled_at = $2, updated_at = $3
+		WHERE id = $4 AND status = ANY($5)
+	`
+	tag, err := tx.Exec(ctx, query, domain.StatusPendingDeletion, purgeAt, time.Now().UTC(), organizationID, from)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrInvalidStatusTransition
+	}
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) CancelDeletion(ctx context.Context, organizationID string, event *domain.AuditEvent) (string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE tenants
+		SET status = COALESCE(status_before_deletion, $1), status_before_deletion = NULL, deletion_scheduled_at = NULL, updated_at = $2
+		WHERE id = $3 AND status = $4
+		RETURNING status
+	`
+	var status string
+	err = tx.QueryRow(ctx, query, domain.StatusArchived, time.Now().UTC(), organizationID, domain.StatusPendingDeletion).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", domain.ErrInvalidStatusTransition
+		}
+		return "", err
+	}
+	if event != nil {
+		event.Details["restored_status"] = status
+	}
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return "", err
+	}
+
+	return status, tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) ClaimDueDeletions(ctx context.Context, now time.Time, staleAfter time.Duration) ([]*domain.Organization, error) {
+	query := `
+		UPDATE tenants
+		SET status = $1, updated_at = $2
+		WHERE (status = $3 AND deletion_scheduled_at <= $2)
+		   OR (status = $1 AND updated_at <= $4)
+		RETURNING id, organization_name, slug, db_name, isolation_mode, COALESCE(shared_database, ''), status,
+		          deletion_scheduled_at, created_at, updated_at
+	`
+	rows, err := r.pool.Query(ctx, query, domain.StatusDeleting, now, domain.StatusPendingDeletion, now.Add(-staleAfter))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var organizations []*domain.Organization
+	for rows.Next() {
+		org := &domain.Organization{}
+		if err := rows.Scan(&org.ID, &org.Name, &org.Slug, &org.DBName, &org.IsolationMode, &org.SharedDatabase, &org.Status, &org.DeletionScheduledAt, &org.CreatedAt, &org.UpdatedAt); err != nil {
+			return nil, err
+		}
+		organizations = append(organizations, org)
+	}
+	return organizations, rows.Err()
+}
+
+func (r *PostgresRepository) Purge(ctx context.Context, organizationID string, event *domain.AuditEvent) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM tenant_memberships WHERE tenant_id = $1`, organizationID); err != nil {
+		return fmt.Errorf("delete memberships: %w", err)
+	}
+	// API keys, SSO connections, slug redirects, exports and imports cascade with the tenant.
+	if _, err := tx.Exec(ctx, `DELETE FROM tenants WHERE id = $1`, organizationID); err != nil {
+		return fmt.Errorf("delete tenant: %w", err)
+	}
+	// The audit events have no foreign key, so they outlive the tenant.
+	if err := insertAuditEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresRepository) RecordAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	return insertAuditEvent(ctx, r.pool, event)
+}
+
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// insertAuditEvent writes the event, if any, through db so callers can keep it
+// in the transaction of the change it records.
+func insertAuditEvent(ctx context.Context, db execer, event *domain.AuditEvent) error {
+	if event == nil {
+		return nil
+	}
+	details, err := json.Marshal(event.Details)
+	if err != nil {
+		return err
+	}
+
+	var actor *string
+	if event.ActorUserID != "" {
+		actor = &event.ActorUserID
+	}
+
+	query := `
+		INSERT INTO organization_audit_events (id, tenant_id, actor_user_id, action, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := db.Exec(ctx, query, event.ID, event.OrganizationID, actor, event.Action, details, event.CreatedAt); err != nil {
+		return fmt.Errorf("record audit event %s: %w", event.Action, err)
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/bowerbird/internal/organization/application/commands"
 	"github.com/bowerbird/internal/organization/application/ports"
 	"github.com/bowerbird/internal/organization/application/queries"
+	"github.com/bowerbird/internal/organization/domain"
 )
 
 type Application struct {
@@ -12,20 +13,110 @@ type Application struct {
 }
 
 type Commands struct {
-	CreateOrganization *commands.CreateOrganizationCommand
+	CreateOrganization    *commands.CreateOrganizationCommand
+	UpdateSecurityPolicy  *commands.UpdateSecurityPolicyCommand
+	CreateAPIKey          *commands.CreateAPIKeyCommand
+	RevokeAPIKey          *commands.RevokeAPIKeyCommand
+	UpdateOrganization    *commands.UpdateOrganizationCommand
+	ChangeStatus          *commands.ChangeOrganizationStatusCommand
+	DeleteOrganization    *commands.DeleteOrganizationCommand
+	CancelDeletion        *commands.CancelOrganizationDeletionCommand
+	PurgeOrganizations    *commands.PurgeOrganizationsCommand
+	ProvisionOrganization *commands.ProvisionOrganizationCommand
+	ResumeProvisioning    *commands.ResumeProvisioningCommand
+	RollbackProvisioning  *commands.RollbackProvisioningCommand
+	RequestDataExport     *commands.RequestDataExportCommand
+	RunDataExport         *commands.RunDataExportCommand
+	CreateDataImport      *commands.CreateDataImportCommand
+	StartDataImport       *commands.StartDataImportCommand
+	RunDataImport         *commands.RunDataImportCommand
 }
 
 type Queries struct {
-	GetOrganization *queries.GetOrganizationQuery
+	GetOrganization       *queries.GetOrganizationQuery
+	ListAPIKeys           *queries.ListAPIKeysQuery
+	AuthenticateAPIKey    *queries.AuthenticateAPIKeyQuery
+	GetProvisioningStatus *queries.GetProvisioningStatusQuery
+	ListDataExports       *queries.ListDataExportsQuery
+	GetDataExport         *queries.GetDataExportQuery
+	DownloadDataExport    *queries.DownloadDataExportQuery
+	ListDataImports       *queries.ListDataImportsQuery
+	GetDataImport         *queries.GetDataImportQuery
+	ListAuditEvents       *queries.ListAuditEventsQuery
+	ExportAuditEvents     *queries.ExportAuditEventsQuery
 }
 
-func NewApplication(repo ports.OrganizationRepository, provisioner ports.Provisioner) *Application {
+// ExportDependencies groups what data exports need. A nil Scheduler runs
+// exports inline, like provisioning.
+type ExportDependencies struct {
+	Repository ports.ExportRepository
+	Reader     ports.TenantDataReader
+	Files      ports.ExportFileStore
+	Scheduler  ports.ExportScheduler
+	Notifier   ports.ExportNotifier
+}
+
+// ImportDependencies groups what data imports need. A nil Scheduler runs
+// imports inline.
+type ImportDependencies struct {
+	Repository ports.ImportRepository
+	Writer     ports.TenantDataWriter
+	Files      ports.ImportFileStore
+	Scheduler  ports.ImportScheduler
+}
+
+// NewApplication builds the organization application. A nil scheduler runs
+// provisioning inline, for tools without a job queue.
+func NewApplication(repo ports.OrganizationRepository, provisioner ports.Provisioner, apiKeys ports.APIKeyRepository, files ports.FilePurger, scheduler ports.ProvisioningScheduler, pools ports.TenantPools, isolation domain.IsolationPolicy, exports ExportDependencies, imports ImportDependencies, auditLog ports.AuditLog) *Application {
+	provision := commands.NewProvisionOrganizationCommand(repo, provisioner)
+	if scheduler == nil {
+		scheduler = commands.NewInlineProvisioningScheduler(provision)
+	}
+
+	runExport := commands.NewRunDataExportCommand(repo, exports.Repository, exports.Reader, exports.Files, exports.Notifier)
+	exportScheduler := exports.Scheduler
+	if exportScheduler == nil {
+		exportScheduler = commands.NewInlineExportScheduler(runExport)
+	}
+
+	runImport := commands.NewRunDataImportCommand(repo, imports.Repository, imports.Writer, imports.Files)
+	importScheduler := imports.Scheduler
+	if importScheduler == nil {
+		importScheduler = commands.NewInlineImportScheduler(runImport)
+	}
+
 	return &Application{
 		Commands: Commands{
-			CreateOrganization: commands.NewCreateOrganizationCommand(repo, provisioner),
+			CreateOrganization:    commands.NewCreateOrganizationCommand(repo, scheduler, isolation),
+			UpdateSecurityPolicy:  commands.NewUpdateSecurityPolicyCommand(repo),
+			CreateAPIKey:          commands.NewCreateAPIKeyCommand(apiKeys),
+			RevokeAPIKey:          commands.NewRevokeAPIKeyCommand(apiKeys),
+			UpdateOrganization:    commands.NewUpdateOrganizationCommand(repo),
+			ChangeStatus:          commands.NewChangeOrganizationStatusCommand(repo, pools),
+			DeleteOrganization:    commands.NewDeleteOrganizationCommand(repo, pools, domain.DefaultDeletionGracePeriod),
+			CancelDeletion:        commands.NewCancelOrganizationDeletionCommand(repo),
+			PurgeOrganizations:    commands.NewPurgeOrganizationsCommand(repo, provisioner, files, pools),
+			ProvisionOrganization: provision,
+			ResumeProvisioning:    commands.NewResumeProvisioningCommand(repo, scheduler),
+			RollbackProvisioning:  commands.NewRollbackProvisioningCommand(repo, provisioner),
+			RequestDataExport:     commands.NewRequestDataExportCommand(repo, exports.Repository, exportScheduler),
+			RunDataExport:         runExport,
+			CreateDataImport:      commands.NewCreateDataImportCommand(repo, imports.Repository, imports.Files),
+			StartDataImport:       commands.NewStartDataImportCommand(repo, imports.Repository, imports.Files, importScheduler),
+			RunDataImport:         runImport,
 		},
 		Queries: Queries{
-			GetOrganization: queries.NewGetOrganizationQuery(repo),
+			GetOrganization:       queries.NewGetOrganizationQuery(repo),
+			ListAPIKeys:           queries.NewListAPIKeysQuery(apiKeys),
+			AuthenticateAPIKey:    queries.NewAuthenticateAPIKeyQuery(apiKeys),
+			GetProvisioningStatus: queries.NewGetProvisioningStatusQuery(repo),
+			ListDataExports:       queries.NewListDataExportsQuery(repo, exports.Repository),
+			GetDataExport:         queries.NewGetDataExportQuery(repo, exports.Repository),
+			DownloadDataExport:    queries.NewDownloadDataExportQuery(repo, exports.Repository, exports.Files),
+			ListDataImports:       queries.NewListDataImportsQuery(repo, imports.Repository),
+			GetDataImport:         queries.NewGetDataImportQuery(repo, imports.Repository),
+			ListAuditEvents:       queries.NewListAuditEventsQuery(auditLog),
+			ExportAuditEvents:     queries.NewExportAuditEventsQuery(auditLog),
 		},
 	}
 }
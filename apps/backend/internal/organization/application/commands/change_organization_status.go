@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type ChangeOrganizationStatusInput struct {
+	OrganizationID string
+	ActorID        string
+	// Status is StatusSuspended, StatusArchived or StatusActive (reactivation).
+	Status string
+}
+
+var statusActions = map[string]string{
+	domain.StatusSuspended: domain.ActionSuspended,
+	domain.StatusArchived:  domain.ActionArchived,
+	domain.StatusActive:    domain.ActionReactivated,
+}
+
+type ChangeOrganizationStatusCommand struct {
+	repo  ports.OrganizationRepository
+	pools ports.TenantPools
+	now   func() time.Time
+}
+
+func NewChangeOrganizationStatusCommand(repo ports.OrganizationRepository, pools ports.TenantPools) *ChangeOrganizationStatusCommand {
+	return &ChangeOrganizationStatusCommand{repo: repo, pools: pools, now: time.Now}
+}
+
+// Execute suspends, archives or reactivates the organization. Any status other
+// than active already blocks tenant tokens and tenant database access; the
+// pools cached by other processes close once their lookup expires.
+func (cmd *ChangeOrganizationStatusCommand) Execute(ctx context.Context, input ChangeOrganizationStatusInput) (*domain.Organization, error) {
+	action, ok := statusActions[input.Status]
+	if !ok {
+		return nil, domain.ErrInvalidStatusTransition
+	}
+
+	org, err := requireOwner(ctx, cmd.repo, input.OrganizationID, input.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := org.CanTransition(input.Status); err != nil {
+		return nil, err
+	}
+
+	// The repository re-checks the source status, so concurrent changes cannot
+	// skip the transition rules.
+	details := map[string]any{"from": org.Status, "to": input.Status}
+	event := newAuditEvent(org.ID, input.ActorID, action, details, cmd.now().UTC())
+	if err := cmd.repo.ChangeStatus(ctx, org.ID, domain.TransitionSources(input.Status), input.Status, event); err != nil {
+		return nil, err
+	}
+	if input.Status != domain.StatusActive {
+		cmd.pools.Invalidate(org.DBName)
+	}
+
+	return cmd.repo.GetByID(ctx, org.ID, input.ActorID)
+}
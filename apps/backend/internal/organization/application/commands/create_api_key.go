@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+type CreateAPIKeyInput struct {
+	TenantID  string
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+	CreatedBy string
+	// CreatorPermissions are the RBAC codes of the tenant-scoped token making
+	// the request; requested scopes must be a subset of them.
+	CreatorPermissions []string
+}
+
+type CreateAPIKeyResult struct {
+	Key   *domain.APIKey
+	Token string
+}
+
+type CreateAPIKeyCommand struct {
+	repo ports.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewCreateAPIKeyCommand(repo ports.APIKeyRepository) *CreateAPIKeyCommand {
+	return &CreateAPIKeyCommand{repo: repo, now: time.Now}
+}
+
+func (cmd *CreateAPIKeyCommand) Execute(ctx context.Context, input CreateAPIKeyInput) (*CreateAPIKeyResult, error) {
+	scopes, err := domain.ValidateAPIKeyScopes(input.Scopes, input.CreatorPermissions)
+	if err != nil {
+		return nil, err
+	}
+
+	key, token, err := domain.NewAPIKey(id.NewULID(), input.TenantID, input.Name, input.CreatedBy, scopes, input.ExpiresAt, cmd.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.repo.Create(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store api key: %w", err)
+	}
+
+	return &CreateAPIKeyResult{Key: key, Token: token}, nil
+}
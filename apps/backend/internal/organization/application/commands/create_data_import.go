@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/id"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+// importUploadTTL is how long the owner has to upload the bundle.
+const importUploadTTL = time.Hour
+
+type CreateDataImportCommand struct {
+	repo    ports.OrganizationRepository
+	imports ports.ImportRepository
+	files   ports.ImportFileStore
+	now     func() time.Time
+}
+
+func NewCreateDataImportCommand(repo ports.OrganizationRepository, imports ports.ImportRepository, files ports.ImportFileStore) *CreateDataImportCommand {
+	return &CreateDataImportCommand{repo: repo, imports: imports, files: files, now: time.Now}
+}
+
+// Execute registers an import and returns where to upload its bundle. The
+// import runs once StartDataImportCommand confirms the upload.
+func (cmd *CreateDataImportCommand) Execute(ctx context.Context, organizationID, actorID string) (*domain.DataImport, *platformStorage.PresignUploadResult, error) {
+	org, err := requireOwner(ctx, cmd.repo, organizationID, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := org.CanImport(); err != nil {
+		return nil, nil, err
+	}
+
+	dataImport := &domain.DataImport{
+		ID:             id.NewULID(),
+		OrganizationID: org.ID,
+		RequestedBy:    actorID,
+		Status:         domain.ImportStatusAwaitingUpload,
+		CreatedAt:      cmd.now().UTC(),
+	}
+	dataImport.FilePath = domain.ImportPath(org.ID, dataImport.ID)
+
+	if err := cmd.imports.Create(ctx, dataImport); err != nil {
+		return nil, nil, fmt.Errorf("failed to register data import: %w", err)
+	}
+
+	upload, err := cmd.files.PresignUpload(ctx, platformStorage.PresignUploadInput{
+		Path:        dataImport.FilePath,
+		ContentType: "application/zip",
+		Metadata:    map[string]string{"organization-id": org.ID, "import-id": dataImport.ID},
+		ExpiresIn:   importUploadTTL,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to presign bundle upload: %w", err)
+	}
+
+	return dataImport, upload, nil
+}
@@ -10,7 +10,7 @@ import (
 	"github.com/bowerbird/internal/platform/id"
 )
 
-var ErrSlugAlreadyExists = errors.New("organization slug already exists")
+var ErrSlugAlreadyExists = domain.ErrSlugAlreadyExists
 
 type CreateOrganizationInput struct {
 	Name           string
@@ -23,31 +23,33 @@ type CreateOrganizationInput struct {
 }
 
 type CreateOrganizationCommand struct {
-	repo        ports.OrganizationRepository
-	provisioner ports.Provisioner
+	repo      ports.OrganizationRepository
+	scheduler ports.ProvisioningScheduler
+	isolation domain.IsolationPolicy
 }
 
-func NewCreateOrganizationCommand(repo ports.OrganizationRepository, provisioner ports.Provisioner) *CreateOrganizationCommand {
-	return &CreateOrganizationCommand{repo: repo, provisioner: provisioner}
-}
-
-func (cmd *CreateOrganizationCommand) failProvisioning(ctx context.Context, orgID, slug string, cause error, step string) error {
-	if markErr := cmd.repo.UpdateStatus(ctx, orgID, domain.StatusFailed); markErr != nil {
-		return errors.Join(
-			fmt.Errorf("failed to %s for %s: %w", step, slug, cause),
-			fmt.Errorf("failed to mark organization as failed: %w", markErr),
-		)
-	}
-
-	return fmt.Errorf("failed to %s for %s: %w", step, slug, cause)
+// NewCreateOrganizationCommand isolates new organizations as the policy says;
+// existing ones keep their mode until they are promoted.
+func NewCreateOrganizationCommand(repo ports.OrganizationRepository, scheduler ports.ProvisioningScheduler, isolation domain.IsolationPolicy) *CreateOrganizationCommand {
+	return &CreateOrganizationCommand{repo: repo, scheduler: scheduler, isolation: isolation}
 }
 
+// Execute registers the organization and schedules its provisioning. The
+// returned organization is still provisioning unless the scheduler runs inline.
 func (cmd *CreateOrganizationCommand) Execute(ctx context.Context, input CreateOrganizationInput) (*domain.Organization, error) {
 	org, err := domain.NewOrganization(input.Name, input.Slug)
 	if err != nil {
 		return nil, fmt.Errorf("invalid organization data: %w", err)
 	}
 	org.ID = id.NewULID()
+	cmd.isolation.Apply(org)
+	org.ProvisioningOwner = &domain.OwnerData{
+		ID:        input.OwnerID,
+		Email:     input.OwnerEmail,
+		FirstName: input.OwnerFirstName,
+		LastName:  input.OwnerLastName,
+		AvatarURL: input.OwnerAvatarURL,
+	}
 
 	exists, err := cmd.repo.ExistsBySlug(ctx, org.Slug)
 	if err != nil {
@@ -61,32 +63,26 @@ func (cmd *CreateOrganizationCommand) Execute(ctx context.Context, input CreateO
 		return nil, fmt.Errorf("failed to register organization in control plane: %w", err)
 	}
 
-	if err := cmd.provisioner.CreateDatabase(ctx, org.DBName); err != nil {
-		return nil, cmd.failProvisioning(ctx, org.ID, org.Slug, err, "provision database")
+	if err := cmd.scheduler.ScheduleProvisioning(ctx, org.ID); err != nil {
+		return nil, cmd.failScheduling(ctx, org, err)
 	}
 
-	if err := cmd.provisioner.MigrateDatabase(ctx, org.DBName); err != nil {
-		return nil, cmd.failProvisioning(ctx, org.ID, org.Slug, err, "migrate database")
-	}
-	owner := domain.OwnerData{
-		ID:        input.OwnerID,
-		Email:     input.OwnerEmail,
-		FirstName: input.OwnerFirstName,
-		LastName:  input.OwnerLastName,
-		AvatarURL: input.OwnerAvatarURL,
-	}
-	if err := cmd.provisioner.SeedOwner(ctx, org.DBName, owner); err != nil {
-		return nil, cmd.failProvisioning(ctx, org.ID, org.Slug, err, "seed owner")
-	}
+	return cmd.repo.GetByID(ctx, org.ID, input.OwnerID)
+}
+
+// failScheduling marks the organization failed unless provisioning already
+// ran and recorded its own failure, so the owner can resume it.
+func (cmd *CreateOrganizationCommand) failScheduling(ctx context.Context, org *domain.Organization, cause error) error {
+	err := fmt.Errorf("failed to provision organization %s: %w", org.Slug, cause)
 
-	if err := cmd.repo.AddMembership(ctx, input.OwnerID, org.ID, "OWNER"); err != nil {
-		return nil, cmd.failProvisioning(ctx, org.ID, org.Slug, err, "add owner membership")
+	current, getErr := cmd.repo.GetByID(ctx, org.ID, "")
+	if getErr == nil && current.Status != domain.StatusProvisioning {
+		return err
 	}
 
-	if err := cmd.repo.UpdateStatus(ctx, org.ID, domain.StatusActive); err != nil {
-		return nil, fmt.Errorf("failed to mark organization as active: %w", err)
+	if markErr := cmd.repo.FailProvisioning(ctx, org.ID, cause.Error()); markErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to mark organization as failed: %w", markErr))
 	}
-	org.Status = domain.StatusActive
 
-	return org, nil
+	return err
 }
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type DeleteOrganizationCommand struct {
+	repo        ports.OrganizationRepository
+	pools       ports.TenantPools
+	gracePeriod time.Duration
+	now         func() time.Time
+}
+
+func NewDeleteOrganizationCommand(repo ports.OrganizationRepository, pools ports.TenantPools, gracePeriod time.Duration) *DeleteOrganizationCommand {
+	if gracePeriod <= 0 {
+		gracePeriod = domain.DefaultDeletionGracePeriod
+	}
+
+	return &DeleteOrganizationCommand{repo: repo, pools: pools, gracePeriod: gracePeriod, now: time.Now}
+}
+
+// Execute schedules the organization for deletion. It goes offline at once;
+// its data is purged by PurgeOrganizationsCommand once the grace period ends.
+func (cmd *DeleteOrganizationCommand) Execute(ctx context.Context, organizationID, actorID string) (*domain.Organization, error) {
+	org, err := requireOwner(ctx, cmd.repo, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := org.CanTransition(domain.StatusPendingDeletion); err != nil {
+		return nil, err
+	}
+
+	now := cmd.now().UTC()
+	purgeAt := now.Add(cmd.gracePeriod)
+	details := map[string]any{"from": org.Status, "purge_at": purgeAt}
+	event := newAuditEvent(org.ID, actorID, domain.ActionDeletionScheduled, details, now)
+	if err := cmd.repo.ScheduleDeletion(ctx, org.ID, domain.TransitionSources(domain.StatusPendingDeletion), purgeAt, event); err != nil {
+		return nil, err
+	}
+	cmd.pools.Invalidate(org.DBName)
+
+	return cmd.repo.GetByID(ctx, org.ID, actorID)
+}
+
+type CancelOrganizationDeletionCommand struct {
+	repo ports.OrganizationRepository
+	now  func() time.Time
+}
+
+func NewCancelOrganizationDeletionCommand(repo ports.OrganizationRepository) *CancelOrganizationDeletionCommand {
+	return &CancelOrganizationDeletionCommand{repo: repo, now: time.Now}
+}
+
+// Execute restores an organization during its grace period to the status it
+// had when deletion was requested.
+func (cmd *CancelOrganizationDeletionCommand) Execute(ctx context.Context, organizationID, actorID string) (*domain.Organization, error) {
+	org, err := requireOwner(ctx, cmd.repo, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repository adds the restored status to the details.
+	event := newAuditEvent(org.ID, actorID, domain.ActionDeletionCancelled, map[string]any{}, cmd.now().UTC())
+	if _, err := cmd.repo.CancelDeletion(ctx, org.ID, event); err != nil {
+		return nil, err
+	}
+
+	return cmd.repo.GetByID(ctx, org.ID, actorID)
+}
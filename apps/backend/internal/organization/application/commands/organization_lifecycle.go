@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+// requireOwner loads the organization and checks that the actor owns it.
+// Lifecycle operations can take the organization offline for every member,
+// so they are not delegated through RBAC permissions.
+func requireOwner(ctx context.Context, repo ports.OrganizationRepository, organizationID, actorID string) (*domain.Organization, error) {
+	org, err := repo.GetByID(ctx, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if org.CurrentUserRole != domain.RoleOwner {
+		return nil, domain.ErrNotOrganizationOwner
+	}
+
+	return org, nil
+}
+
+// newAuditEvent builds the event a lifecycle change hands to the repository,
+// which stores both in one transaction: a lifecycle change must never go
+// unrecorded.
+func newAuditEvent(organizationID, actorID, action string, details map[string]any, now time.Time) *domain.AuditEvent {
+	return &domain.AuditEvent{
+		ID:             id.NewULID(),
+		OrganizationID: organizationID,
+		ActorUserID:    actorID,
+		Action:         action,
+		Details:        details,
+		CreatedAt:      now,
+	}
+}
+
+// recordAudit stores an event on its own, for requests whose state lives
+// outside the organization repository. It fails the operation when the event
+// cannot be stored.
+func recordAudit(ctx context.Context, repo ports.OrganizationRepository, organizationID, actorID, action string, details map[string]any, now time.Time) error {
+	event := newAuditEvent(organizationID, actorID, action, details, now)
+	if err := repo.RecordAuditEvent(ctx, event); err != nil {
+		return fmt.Errorf("failed to record audit event %s: %w", action, err)
+	}
+
+	return nil
+}
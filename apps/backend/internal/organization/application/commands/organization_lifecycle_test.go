@@ -0,0 +1,192 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type lifecycleRepo struct {
+	ports.OrganizationRepository
+
+	org       *domain.Organization
+	redirects []string
+	due       []*domain.Organization
+
+	// changeErr fails a change, and with it the event recorded alongside.
+	changeErr     error
+	statusChanges []string
+	purged        []string
+	audit         []*domain.AuditEvent
+}
+
+func (r *lifecycleRepo) GetByID(ctx context.Context, id, userID string) (*domain.Organization, error) {
+	if r.org == nil || r.org.ID != id {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	return r.org, nil
+}
+
+func (r *lifecycleRepo) ChangeStatus(ctx context.Context, organizationID string, from []string, status string, event *domain.AuditEvent) error {
+	if !slices.Contains(from, r.org.Status) {
+		return domain.ErrInvalidStatusTransition
+	}
+	if r.changeErr != nil {
+		return r.changeErr
+	}
+	r.statusChanges = append(r.statusChanges, status)
+	r.record(event)
+	return nil
+}
+
+func (r *lifecycleRepo) ListSlugRedirects(ctx context.Context, organizationID string) ([]string, error) {
+	return r.redirects, nil
+}
+
+func (r *lifecycleRepo) ClaimDueDeletions(ctx context.Context, now time.Time, staleAfter time.Duration) ([]*domain.Organization, error) {
+	return r.due, nil
+}
+
+func (r *lifecycleRepo) Purge(ctx context.Context, organizationID string, event *domain.AuditEvent) error {
+	if r.changeErr != nil {
+		return r.changeErr
+	}
+	r.purged = append(r.purged, organizationID)
+	r.record(event)
+	return nil
+}
+
+func (r *lifecycleRepo) record(event *domain.AuditEvent) {
+	if event != nil {
+		r.audit = append(r.audit, event)
+	}
+}
+
+type lifecycleProvisioner struct {
+	ports.Provisioner
+
+	dropErr error
+	dropped []string
+}
+
+func (p *lifecycleProvisioner) DropDatabase(ctx context.Context, location domain.DatabaseLocation) error {
+	if p.dropErr != nil {
+		return p.dropErr
+	}
+	p.dropped = append(p.dropped, location.DBName)
+	return nil
+}
+
+type recordingPools struct {
+	invalidated []string
+}
+
+func (p *recordingPools) Invalidate(dbName string) {
+	p.invalidated = append(p.invalidated, dbName)
+}
+
+type recordingPurger struct {
+	prefixes []string
+}
+
+func (p *recordingPurger) DeletePrefix(ctx context.Context, input platformStorage.DeletePrefixInput) (int, error) {
+	p.prefixes = append(p.prefixes, input.Prefix)
+	return 1, nil
+}
+
+func TestChangeOrganizationStatusRequiresOwner(t *testing.T) {
+	repo := &lifecycleRepo{org: &domain.Organization{ID: "org-1", Status: domain.StatusActive, CurrentUserRole: "ADMIN"}}
+	cmd := NewChangeOrganizationStatusCommand(repo, &recordingPools{})
+
+	_, err := cmd.Execute(context.Background(), ChangeOrganizationStatusInput{OrganizationID: "org-1", ActorID: "user-1", Status: domain.StatusSuspended})
+	if !errors.Is(err, domain.ErrNotOrganizationOwner) {
+		t.Fatalf("expected ErrNotOrganizationOwner, got %v", err)
+	}
+	if len(repo.statusChanges) != 0 || len(repo.audit) != 0 {
+		t.Fatal("expected no changes for a non-owner")
+	}
+}
+
+func TestSuspendOrganizationRecordsAuditEvent(t *testing.T) {
+	repo := &lifecycleRepo{org: &domain.Organization{ID: "org-1", DBName: "tenant_acme", Status: domain.StatusActive, CurrentUserRole: domain.RoleOwner}}
+	pools := &recordingPools{}
+	cmd := NewChangeOrganizationStatusCommand(repo, pools)
+
+	if _, err := cmd.Execute(context.Background(), ChangeOrganizationStatusInput{OrganizationID: "org-1", ActorID: "user-1", Status: domain.StatusSuspended}); err != nil {
+		t.Fatalf("suspend failed: %v", err)
+	}
+
+	if !slices.Equal(repo.statusChanges, []string{domain.StatusSuspended}) {
+		t.Fatalf("expected suspension, got %v", repo.statusChanges)
+	}
+	if len(repo.audit) != 1 || repo.audit[0].Action != domain.ActionSuspended || repo.audit[0].ActorUserID != "user-1" {
+		t.Fatalf("expected suspension audit event, got %+v", repo.audit)
+	}
+	if !slices.Equal(pools.invalidated, []string{"tenant_acme"}) {
+		t.Fatalf("expected tenant pools to be invalidated, got %v", pools.invalidated)
+	}
+}
+
+func TestSuspendOrganizationRecordsNothingWhenTheChangeFails(t *testing.T) {
+	repo := &lifecycleRepo{
+		org:       &domain.Organization{ID: "org-1", Status: domain.StatusActive, CurrentUserRole: domain.RoleOwner},
+		changeErr: errors.New("connection reset"),
+	}
+
+	_, err := NewChangeOrganizationStatusCommand(repo, &recordingPools{}).Execute(context.Background(), ChangeOrganizationStatusInput{OrganizationID: "org-1", ActorID: "user-1", Status: domain.StatusSuspended})
+	if err == nil {
+		t.Fatal("expected the change to fail")
+	}
+	if len(repo.statusChanges) != 0 || len(repo.audit) != 0 {
+		t.Fatalf("expected neither the change nor its event, got %v %+v", repo.statusChanges, repo.audit)
+	}
+}
+
+func TestPurgeOrganizationsDropsDatabaseFilesAndRecords(t *testing.T) {
+	org := &domain.Organization{ID: "org-1", Slug: "acme", DBName: "tenant_acme", Status: domain.StatusDeleting}
+	repo := &lifecycleRepo{due: []*domain.Organization{org}, redirects: []string{"old-acme"}}
+	provisioner := &lifecycleProvisioner{}
+	purger := &recordingPurger{}
+
+	purged, err := NewPurgeOrganizationsCommand(repo, provisioner, purger, &recordingPools{}).Execute(context.Background())
+	if err != nil {
+		t.Fatalf("purge failed: %v", err)
+	}
+
+	if purged != 1 {
+		t.Fatalf("expected 1 purged organization, got %d", purged)
+	}
+	if !slices.Equal(provisioner.dropped, []string{"tenant_acme"}) {
+		t.Fatalf("expected tenant database to be dropped, got %v", provisioner.dropped)
+	}
+	if !slices.Equal(purger.prefixes, domain.StoragePrefixes("org-1", "acme", "old-acme")) {
+		t.Fatalf("unexpected purged prefixes %v", purger.prefixes)
+	}
+	if !slices.Equal(repo.purged, []string{"org-1"}) {
+		t.Fatalf("expected control plane records to be purged, got %v", repo.purged)
+	}
+	if len(repo.audit) != 1 || repo.audit[0].Action != domain.ActionPurged {
+		t.Fatalf("expected purge audit event, got %+v", repo.audit)
+	}
+}
+
+func TestPurgeOrganizationsKeepsRecordsWhenDatabaseDropFails(t *testing.T) {
+	org := &domain.Organization{ID: "org-1", Slug: "acme", DBName: "tenant_acme", Status: domain.StatusDeleting}
+	repo := &lifecycleRepo{due: []*domain.Organization{org}}
+	provisioner := &lifecycleProvisioner{dropErr: errors.New("database is busy")}
+
+	purged, err := NewPurgeOrganizationsCommand(repo, provisioner, &recordingPurger{}, &recordingPools{}).Execute(context.Background())
+	if err == nil {
+		t.Fatal("expected purge error")
+	}
+
+	if purged != 0 || len(repo.purged) != 0 {
+		t.Fatal("expected control plane records to be kept for a retry")
+	}
+}
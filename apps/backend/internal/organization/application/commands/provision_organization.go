@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type ProvisionOrganizationCommand struct {
+	repo        ports.OrganizationRepository
+	provisioner ports.Provisioner
+}
+
+func NewProvisionOrganizationCommand(repo ports.OrganizationRepository, provisioner ports.Provisioner) *ProvisionOrganizationCommand {
+	return &ProvisionOrganizationCommand{repo: repo, provisioner: provisioner}
+}
+
+// Execute runs the provisioning steps left after the last completed one and
+// activates the organization. A failing step marks the organization failed so
+// its owner can resume or roll it back; organizations that are not
+// provisioning are left alone, which makes duplicate jobs harmless.
+func (cmd *ProvisionOrganizationCommand) Execute(ctx context.Context, organizationID string) error {
+	org, err := cmd.repo.GetByID(ctx, organizationID, "")
+	if err != nil {
+		if errors.Is(err, domain.ErrOrganizationNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load organization: %w", err)
+	}
+	if org.Status != domain.StatusProvisioning {
+		return nil
+	}
+
+	for _, step := range domain.PendingProvisioningSteps(org.ProvisioningStep) {
+		if err := cmd.runStep(ctx, org, step); err != nil {
+			return cmd.failProvisioning(ctx, org, step, err)
+		}
+		if err := cmd.repo.CompleteProvisioningStep(ctx, org.ID, step); err != nil {
+			return fmt.Errorf("failed to record provisioning step %s: %w", step, err)
+		}
+	}
+
+	if err := cmd.repo.UpdateStatus(ctx, org.ID, domain.StatusActive); err != nil {
+		return fmt.Errorf("failed to mark organization as active: %w", err)
+	}
+
+	return nil
+}
+
+func (cmd *ProvisionOrganizationCommand) runStep(ctx context.Context, org *domain.Organization, step string) error {
+	switch step {
+	case domain.ProvisioningStepDBCreated:
+		return cmd.provisioner.CreateDatabase(ctx, org.Location())
+	case domain.ProvisioningStepMigrated:
+		return cmd.provisioner.MigrateDatabase(ctx, org.Location())
+	case domain.ProvisioningStepOwnerSeeded:
+		if org.ProvisioningOwner == nil {
+			return errors.New("organization has no provisioning owner")
+		}
+		return cmd.provisioner.SeedOwner(ctx, org.Location(), *org.ProvisioningOwner)
+	case domain.ProvisioningStepMembershipAdded:
+		if org.ProvisioningOwner == nil {
+			return errors.New("organization has no provisioning owner")
+		}
+		return cmd.repo.AddMembership(ctx, org.ProvisioningOwner.ID, org.ID, domain.RoleOwner)
+	}
+
+	return fmt.Errorf("unknown provisioning step %s", step)
+}
+
+func (cmd *ProvisionOrganizationCommand) failProvisioning(ctx context.Context, org *domain.Organization, step string, cause error) error {
+	err := fmt.Errorf("failed to %s for %s: %w", stepDescriptions[step], org.Slug, cause)
+	if markErr := cmd.repo.FailProvisioning(ctx, org.ID, err.Error()); markErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to mark organization as failed: %w", markErr))
+	}
+
+	return err
+}
+
+var stepDescriptions = map[string]string{
+	domain.ProvisioningStepDBCreated:       "provision database",
+	domain.ProvisioningStepMigrated:        "migrate database",
+	domain.ProvisioningStepOwnerSeeded:     "seed owner",
+	domain.ProvisioningStepMembershipAdded: "add owner membership",
+}
+
+// InlineProvisioningScheduler provisions in the caller's goroutine, for
+// tools such as the seed that have no job queue.
+type InlineProvisioningScheduler struct {
+	command *ProvisionOrganizationCommand
+}
+
+func NewInlineProvisioningScheduler(command *ProvisionOrganizationCommand) *InlineProvisioningScheduler {
+	return &InlineProvisioningScheduler{command: command}
+}
+
+func (s *InlineProvisioningScheduler) ScheduleProvisioning(ctx context.Context, organizationID string) error {
+	return s.command.Execute(ctx, organizationID)
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type provisioningRepo struct {
+	ports.OrganizationRepository
+
+	org         *domain.Organization
+	memberships []string
+	audit       []string
+}
+
+func (r *provisioningRepo) GetByID(ctx context.Context, id, userID string) (*domain.Organization, error) {
+	if r.org == nil || r.org.ID != id {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	org := *r.org
+	return &org, nil
+}
+
+func (r *provisioningRepo) AddMembership(ctx context.Context, userID, tenantID, role string) error {
+	r.memberships = append(r.memberships, userID+":"+role)
+	return nil
+}
+
+func (r *provisioningRepo) CompleteProvisioningStep(ctx context.Context, organizationID, step string) error {
+	r.org.ProvisioningStep = step
+	r.org.ProvisioningError = ""
+	return nil
+}
+
+func (r *provisioningRepo) FailProvisioning(ctx context.Context, organizationID, cause string) error {
+	r.org.Status = domain.StatusFailed
+	r.org.ProvisioningError = cause
+	return nil
+}
+
+func (r *provisioningRepo) UpdateStatus(ctx context.Context, organizationID, status string) error {
+	r.org.Status = status
+	return nil
+}
+
+func (r *provisioningRepo) ChangeStatus(ctx context.Context, organizationID string, from []string, status string, event *domain.AuditEvent) error {
+	if !slices.Contains(from, r.org.Status) {
+		return domain.ErrInvalidStatusTransition
+	}
+	r.org.Status = status
+	if event != nil {
+		r.audit = append(r.audit, event.Action)
+	}
+	return nil
+}
+
+func (r *provisioningRepo) RecordAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	r.audit = append(r.audit, event.Action)
+	return nil
+}
+
+type stepProvisioner struct {
+	ports.Provisioner
+
+	migrateErr error
+	calls      []string
+}
+
+func (p *stepProvisioner) CreateDatabase(ctx context.Context, location domain.DatabaseLocation) error {
+	p.calls = append(p.calls, "create")
+	return nil
+}
+
+func (p *stepProvisioner) MigrateDatabase(ctx context.Context, location domain.DatabaseLocation) error {
+	p.calls = append(p.calls, "migrate")
+	return p.migrateErr
+}
+
+func (p *stepProvisioner) SeedOwner(ctx context.Context, location domain.DatabaseLocation, owner domain.OwnerData) error {
+	p.calls = append(p.calls, "seed")
+	return nil
+}
+
+func newProvisioningOrganization(status, step string) *domain.Organization {
+	return &domain.Organization{
+		ID:                "org-1",
+		Slug:              "acme",
+		DBName:            "tenant_acme",
+		Status:            status,
+		ProvisioningStep:  step,
+		ProvisioningOwner: &domain.OwnerData{ID: "user-1", Email: "owner@acme.com"},
+	}
+}
+
+func TestProvisionOrganizationResumesAfterLastCompletedStep(t *testing.T) {
+	repo := &provisioningRepo{org: newProvisioningOrganization(domain.StatusProvisioning, domain.ProvisioningStepMigrated)}
+	provisioner := &stepProvisioner{}
+
+	if err := NewProvisionOrganizationCommand(repo, provisioner).Execute(context.Background(), "org-1"); err != nil {
+		t.Fatalf("provision failed: %v", err)
+	}
+
+	if !slices.Equal(provisioner.calls, []string{"seed"}) {
+		t.Fatalf("expected only the owner to be seeded, got %v", provisioner.calls)
+	}
+	if !slices.Equal(repo.memberships, []string{"user-1:" + domain.RoleOwner}) {
+		t.Fatalf("expected owner membership, got %v", repo.memberships)
+	}
+	if repo.org.Status != domain.StatusActive || repo.org.ProvisioningStep != domain.ProvisioningStepMembershipAdded {
+		t.Fatalf("expected active organization with every step done, got %s at %q", repo.org.Status, repo.org.ProvisioningStep)
+	}
+}
+
+func TestProvisionOrganizationRecordsFailedStep(t *testing.T) {
+	repo := &provisioningRepo{org: newProvisioningOrganization(domain.StatusProvisioning, "")}
+	provisioner := &stepProvisioner{migrateErr: errors.New("dirty database")}
+
+	err := NewProvisionOrganizationCommand(repo, provisioner).Execute(context.Background(), "org-1")
+	if err == nil {
+		t.Fatal("expected provisioning error")
+	}
+
+	if repo.org.Status != domain.StatusFailed {
+		t.Fatalf("expected failed organization, got %s", repo.org.Status)
+	}
+	if repo.org.ProvisioningStep != domain.ProvisioningStepDBCreated {
+		t.Fatalf("expected database step to stay completed, got %q", repo.org.ProvisioningStep)
+	}
+	if repo.org.ProvisioningError == "" {
+		t.Fatal("expected the failure cause to be recorded")
+	}
+}
+
+func TestProvisionOrganizationSkipsOrganizationsNotProvisioning(t *testing.T) {
+	repo := &provisioningRepo{org: newProvisioningOrganization(domain.StatusFailed, "")}
+	provisioner := &stepProvisioner{}
+
+	if err := NewProvisionOrganizationCommand(repo, provisioner).Execute(context.Background(), "org-1"); err != nil {
+		t.Fatalf("expected duplicate job to be ignored, got %v", err)
+	}
+	if len(provisioner.calls) != 0 {
+		t.Fatalf("expected no provisioning calls, got %v", provisioner.calls)
+	}
+}
+
+func TestResumeProvisioningRequiresRequester(t *testing.T) {
+	repo := &provisioningRepo{org: newProvisioningOrganization(domain.StatusFailed, domain.ProvisioningStepDBCreated)}
+	provision := NewProvisionOrganizationCommand(repo, &stepProvisioner{})
+	cmd := NewResumeProvisioningCommand(repo, NewInlineProvisioningScheduler(provision))
+
+	if _, err := cmd.Execute(context.Background(), "org-1", "user-2"); !errors.Is(err, domain.ErrNotOrganizationOwner) {
+		t.Fatalf("expected ErrNotOrganizationOwner, got %v", err)
+	}
+
+	org, err := cmd.Execute(context.Background(), "org-1", "user-1")
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if org.Status != domain.StatusActive {
+		t.Fatalf("expected resumed organization to be active, got %s", org.Status)
+	}
+	if !slices.Equal(repo.audit, []string{domain.ActionProvisioningResumed}) {
+		t.Fatalf("expected resume audit event, got %v", repo.audit)
+	}
+}
@@ -0,0 +1,83 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+// purgeStaleAfter is how long a purge may stay in progress before another
+// run takes it over, e.g. after a crash.
+const purgeStaleAfter = time.Hour
+
+type PurgeOrganizationsCommand struct {
+	repo        ports.OrganizationRepository
+	provisioner ports.Provisioner
+	files       ports.FilePurger
+	pools       ports.TenantPools
+	now         func() time.Time
+}
+
+func NewPurgeOrganizationsCommand(repo ports.OrganizationRepository, provisioner ports.Provisioner, files ports.FilePurger, pools ports.TenantPools) *PurgeOrganizationsCommand {
+	return &PurgeOrganizationsCommand{repo: repo, provisioner: provisioner, files: files, pools: pools, now: time.Now}
+}
+
+// Execute permanently deletes every organization whose grace period is over
+// and returns how many were purged. Each step is idempotent, so a failed
+// purge is retried whole on a later run.
+func (cmd *PurgeOrganizationsCommand) Execute(ctx context.Context) (int, error) {
+	organizations, err := cmd.repo.ClaimDueDeletions(ctx, cmd.now().UTC(), purgeStaleAfter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim due deletions: %w", err)
+	}
+
+	purged := 0
+	var errs []error
+	for _, org := range organizations {
+		if err := cmd.purge(ctx, org); err != nil {
+			slog.Error("Organization purge failed", "organization_id", org.ID, "error", err)
+			errs = append(errs, fmt.Errorf("purge %s: %w", org.ID, err))
+			continue
+		}
+		purged++
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+func (cmd *PurgeOrganizationsCommand) purge(ctx context.Context, org *domain.Organization) error {
+	formerSlugs, err := cmd.repo.ListSlugRedirects(ctx, org.ID)
+	if err != nil {
+		return fmt.Errorf("list slug redirects: %w", err)
+	}
+
+	// DropDatabase forces other sessions out; closing ours first keeps the
+	// pool from reconnecting to a database that is about to disappear.
+	cmd.pools.Invalidate(org.DBName)
+	if err := cmd.provisioner.DropDatabase(ctx, org.Location()); err != nil {
+		return fmt.Errorf("drop database: %w", err)
+	}
+
+	objects := 0
+	for _, prefix := range domain.StoragePrefixes(org.ID, append([]string{org.Slug}, formerSlugs...)...) {
+		deleted, err := cmd.files.DeletePrefix(ctx, platformStorage.DeletePrefixInput{Prefix: prefix})
+		if err != nil {
+			return fmt.Errorf("delete files under %s: %w", prefix, err)
+		}
+		objects += deleted
+	}
+
+	details := map[string]any{"slug": org.Slug, "db_name": org.DBName, "objects_deleted": objects}
+	event := newAuditEvent(org.ID, "", domain.ActionPurged, details, cmd.now().UTC())
+	if err := cmd.repo.Purge(ctx, org.ID, event); err != nil {
+		return fmt.Errorf("delete control plane records: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+// requireProvisioningOwner loads an organization whose provisioning failed.
+// Until provisioning completes there is no membership, so the user who
+// requested the organization is its owner.
+func requireProvisioningOwner(ctx context.Context, repo ports.OrganizationRepository, organizationID, actorID string) (*domain.Organization, error) {
+	org, err := repo.GetByID(ctx, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsProvisioningOwner(actorID) {
+		return nil, domain.ErrNotOrganizationOwner
+	}
+	if org.Status != domain.StatusFailed {
+		return nil, domain.ErrInvalidStatusTransition
+	}
+
+	return org, nil
+}
+
+type ResumeProvisioningCommand struct {
+	repo      ports.OrganizationRepository
+	scheduler ports.ProvisioningScheduler
+	now       func() time.Time
+}
+
+func NewResumeProvisioningCommand(repo ports.OrganizationRepository, scheduler ports.ProvisioningScheduler) *ResumeProvisioningCommand {
+	return &ResumeProvisioningCommand{repo: repo, scheduler: scheduler, now: time.Now}
+}
+
+// Execute schedules a failed provisioning again, from the step that failed.
+func (cmd *ResumeProvisioningCommand) Execute(ctx context.Context, organizationID, actorID string) (*domain.Organization, error) {
+	org, err := requireProvisioningOwner(ctx, cmd.repo, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{"completed_step": org.ProvisioningStep, "last_error": org.ProvisioningError}
+	event := newAuditEvent(org.ID, actorID, domain.ActionProvisioningResumed, details, cmd.now().UTC())
+	if err := cmd.repo.ChangeStatus(ctx, org.ID, []string{domain.StatusFailed}, domain.StatusProvisioning, event); err != nil {
+		return nil, err
+	}
+
+	if err := cmd.scheduler.ScheduleProvisioning(ctx, org.ID); err != nil {
+		if markErr := cmd.repo.FailProvisioning(ctx, org.ID, err.Error()); markErr != nil {
+			return nil, markErr
+		}
+		return nil, err
+	}
+
+	return cmd.repo.GetByID(ctx, org.ID, actorID)
+}
+
+type RollbackProvisioningCommand struct {
+	repo        ports.OrganizationRepository
+	provisioner ports.Provisioner
+	now         func() time.Time
+}
+
+func NewRollbackProvisioningCommand(repo ports.OrganizationRepository, provisioner ports.Provisioner) *RollbackProvisioningCommand {
+	return &RollbackProvisioningCommand{repo: repo, provisioner: provisioner, now: time.Now}
+}
+
+// Execute removes everything a failed provisioning created, which frees the
+// slug for a new attempt.
+func (cmd *RollbackProvisioningCommand) Execute(ctx context.Context, organizationID, actorID string) error {
+	org, err := requireProvisioningOwner(ctx, cmd.repo, organizationID, actorID)
+	if err != nil {
+		return err
+	}
+
+	// Claiming the organization first keeps a concurrent resume from running
+	// against a database that is being dropped.
+	if err := cmd.repo.ChangeStatus(ctx, org.ID, []string{domain.StatusFailed}, domain.StatusDeleting, nil); err != nil {
+		return err
+	}
+
+	if err := cmd.provisioner.DropDatabase(ctx, org.Location()); err != nil {
+		return cmd.failRollback(ctx, org.ID, err)
+	}
+
+	details := map[string]any{"slug": org.Slug, "completed_step": org.ProvisioningStep, "last_error": org.ProvisioningError}
+	event := newAuditEvent(org.ID, actorID, domain.ActionProvisioningRolledBack, details, cmd.now().UTC())
+	if err := cmd.repo.Purge(ctx, org.ID, event); err != nil {
+		return cmd.failRollback(ctx, org.ID, err)
+	}
+
+	return nil
+}
+
+// failRollback returns the organization to failed so the rollback can be retried.
+func (cmd *RollbackProvisioningCommand) failRollback(ctx context.Context, organizationID string, cause error) error {
+	err := fmt.Errorf("failed to roll back provisioning: %w", cause)
+	if markErr := cmd.repo.FailProvisioning(ctx, organizationID, err.Error()); markErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to mark organization as failed: %w", markErr))
+	}
+
+	return err
+}
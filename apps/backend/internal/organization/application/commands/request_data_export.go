@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/id"
+)
+
+type RequestDataExportCommand struct {
+	repo      ports.OrganizationRepository
+	exports   ports.ExportRepository
+	scheduler ports.ExportScheduler
+	now       func() time.Time
+}
+
+func NewRequestDataExportCommand(repo ports.OrganizationRepository, exports ports.ExportRepository, scheduler ports.ExportScheduler) *RequestDataExportCommand {
+	return &RequestDataExportCommand{repo: repo, exports: exports, scheduler: scheduler, now: time.Now}
+}
+
+// Execute registers an export of the organization's data and schedules it.
+// Only one export runs at a time per organization.
+func (cmd *RequestDataExportCommand) Execute(ctx context.Context, organizationID, actorID string) (*domain.DataExport, error) {
+	org, err := requireOwner(ctx, cmd.repo, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := org.CanExport(); err != nil {
+		return nil, err
+	}
+
+	now := cmd.now().UTC()
+	existing, err := cmd.exports.ListByOrganization(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data exports: %w", err)
+	}
+	for _, export := range existing {
+		if export.InProgress(now) {
+			return nil, domain.ErrExportInProgress
+		}
+	}
+
+	export := &domain.DataExport{
+		ID:             id.NewULID(),
+		OrganizationID: org.ID,
+		RequestedBy:    actorID,
+		Status:         domain.ExportStatusPending,
+		CreatedAt:      now,
+	}
+	if err := cmd.exports.Create(ctx, export); err != nil {
+		return nil, fmt.Errorf("failed to register data export: %w", err)
+	}
+
+	details := map[string]any{"export_id": export.ID}
+	if err := recordAudit(ctx, cmd.repo, org.ID, actorID, domain.ActionDataExportRequested, details, now); err != nil {
+		return nil, err
+	}
+
+	if err := cmd.scheduler.ScheduleExport(ctx, org.ID, export.ID); err != nil {
+		err = fmt.Errorf("failed to schedule data export: %w", err)
+		if failErr := cmd.exports.Fail(ctx, export.ID, err.Error()); failErr != nil {
+			return nil, errors.Join(err, failErr)
+		}
+		return nil, err
+	}
+
+	return cmd.exports.Get(ctx, org.ID, export.ID)
+}
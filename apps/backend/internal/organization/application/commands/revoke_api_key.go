@@ -0,0 +1,21 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+)
+
+type RevokeAPIKeyCommand struct {
+	repo ports.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewRevokeAPIKeyCommand(repo ports.APIKeyRepository) *RevokeAPIKeyCommand {
+	return &RevokeAPIKeyCommand{repo: repo, now: time.Now}
+}
+
+func (cmd *RevokeAPIKeyCommand) Execute(ctx context.Context, tenantID, keyID string) error {
+	return cmd.repo.Revoke(ctx, tenantID, keyID, cmd.now().UTC())
+}
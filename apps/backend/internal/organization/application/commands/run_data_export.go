@@ -0,0 +1,285 @@
+package commands
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type RunDataExportCommand struct {
+	repo     ports.OrganizationRepository
+	exports  ports.ExportRepository
+	reader   ports.TenantDataReader
+	files    ports.ExportFileStore
+	notifier ports.ExportNotifier
+	now      func() time.Time
+}
+
+func NewRunDataExportCommand(repo ports.OrganizationRepository, exports ports.ExportRepository, reader ports.TenantDataReader, files ports.ExportFileStore, notifier ports.ExportNotifier) *RunDataExportCommand {
+	return &RunDataExportCommand{repo: repo, exports: exports, reader: reader, files: files, notifier: notifier, now: time.Now}
+}
+
+// Execute builds the export bundle, stores it and notifies the requester.
+// Exports that are no longer pending are skipped, which makes duplicate jobs
+// harmless; a failure marks the export failed so the owner can request a new one.
+func (cmd *RunDataExportCommand) Execute(ctx context.Context, exportID string) error {
+	claimed, err := cmd.exports.Claim(ctx, exportID)
+	if err != nil {
+		return fmt.Errorf("failed to claim data export: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	export, err := cmd.exports.GetByID(ctx, exportID)
+	if err != nil {
+		return fmt.Errorf("failed to load data export: %w", err)
+	}
+
+	org, err := cmd.repo.GetByID(ctx, export.OrganizationID, "")
+	if err != nil {
+		return cmd.fail(ctx, export, fmt.Errorf("load organization: %w", err))
+	}
+
+	if err := cmd.store(ctx, org, export); err != nil {
+		return cmd.fail(ctx, export, err)
+	}
+
+	if err := cmd.exports.Complete(ctx, export.ID, export.FilePath, export.SizeBytes, export.SHA256); err != nil {
+		return fmt.Errorf("failed to complete data export: %w", err)
+	}
+	export.Status = domain.ExportStatusCompleted
+
+	// The bundle is ready either way; the owner can still find it in the list.
+	if err := cmd.notifier.ExportReady(ctx, org, export); err != nil {
+		slog.Error("Data export notification failed", "organization_id", org.ID, "export_id", export.ID, "error", err)
+	}
+
+	return nil
+}
+
+// store uploads the bundle while it is built: build writes the archive into
+// a pipe that the upload reads part by part, so neither the records nor the
+// files are held in memory. On success it fills the export's path, size and
+// checksum.
+func (cmd *RunDataExportCommand) store(ctx context.Context, org *domain.Organization, export *domain.DataExport) error {
+	formerSlugs, err := cmd.repo.ListSlugRedirects(ctx, org.ID)
+	if err != nil {
+		return fmt.Errorf("list slug redirects: %w", err)
+	}
+	prefixes := domain.StoragePrefixes(org.ID, append([]string{org.Slug}, formerSlugs...)...)
+
+	pr, pw := io.Pipe()
+	built := make(chan error, 1)
+	go func() {
+		err := cmd.build(ctx, org, export, prefixes, pw)
+		pw.CloseWithError(err)
+		built <- err
+	}()
+
+	path := domain.ExportPath(org.ID, export.ID)
+	sum := sha256.New()
+	result, uploadErr := cmd.files.UploadFile(ctx, platformStorage.UploadFileInput{
+		Path:        path,
+		Body:        io.TeeReader(pr, sum),
+		ContentType: "application/zip",
+		Metadata:    map[string]string{"organization-id": org.ID, "export-id": export.ID},
+	})
+	// Unblocks build if the upload stopped before the end of the bundle.
+	pr.CloseWithError(uploadErr)
+	if err := <-built; err != nil {
+		return err
+	}
+	if uploadErr != nil {
+		return fmt.Errorf("store bundle: %w", uploadErr)
+	}
+
+	export.FilePath, export.SizeBytes, export.SHA256 = path, result.SizeBytes, hex.EncodeToString(sum.Sum(nil))
+	return nil
+}
+
+// build writes every dataset as JSON Lines, then the files they reference,
+// and finally the manifest with the checksum of every entry.
+func (cmd *RunDataExportCommand) build(ctx context.Context, org *domain.Organization, export *domain.DataExport, prefixes []string, w io.Writer) error {
+	manifest := domain.ExportManifest{
+		FormatVersion:    domain.ExportFormatVersion,
+		ExportID:         export.ID,
+		OrganizationID:   org.ID,
+		OrganizationSlug: org.Slug,
+		OrganizationName: org.Name,
+		GeneratedAt:      cmd.now().UTC(),
+		Entries:          []domain.ExportManifestEntry{},
+		MissingFiles:     []string{},
+	}
+	archive := zip.NewWriter(w)
+
+	var fileKeys []string
+	seen := map[string]bool{}
+	version, err := cmd.reader.ReadTenantData(ctx, org.Location(), func(dataset string, rows ports.ExportRows) error {
+		entry := domain.ExportManifestEntry{Path: domain.DatasetPath(dataset), Kind: domain.ExportEntryDataset, Dataset: dataset}
+		out, err := createArchiveEntry(archive, entry.Path)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			record := rows.Record()
+			if _, err := out.Write(record.Data); err != nil {
+				return err
+			}
+			if _, err := out.Write([]byte{'\n'}); err != nil {
+				return err
+			}
+			entry.Records++
+
+			for _, key := range record.FileKeys {
+				if key != "" && !seen[key] {
+					seen[key] = true
+					fileKeys = append(fileKeys, key)
+				}
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		out.seal(&entry)
+		manifest.Entries = append(manifest.Entries, entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("read tenant data: %w", err)
+	}
+	manifest.SchemaVersion = version
+
+	for _, key := range fileKeys {
+		entry := domain.ExportManifestEntry{Path: domain.ExportFilePath(key), Kind: domain.ExportEntryFile, Source: key}
+		copied, err := cmd.copyFile(ctx, archive, &entry, prefixes)
+		if err != nil {
+			return err
+		}
+		if !copied {
+			manifest.MissingFiles = append(manifest.MissingFiles, key)
+			continue
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	out, err := createArchiveEntry(archive, domain.ExportManifestPath)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(manifestJSON); err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("close bundle: %w", err)
+	}
+	return nil
+}
+
+// copyFile streams a stored file into the bundle. It reports false when the
+// file is no longer stored, so one lost attachment does not block the whole
+// export, and for keys outside the organization's prefixes, which a record
+// must never be able to pull into the bundle.
+func (cmd *RunDataExportCommand) copyFile(ctx context.Context, archive *zip.Writer, entry *domain.ExportManifestEntry, prefixes []string) (bool, error) {
+	key := entry.Source
+	if !domain.ExportableFileKey(key, prefixes) {
+		slog.Warn("Data export skipped a file outside the organization's storage", "key", key)
+		return false, nil
+	}
+
+	exists, err := cmd.files.Exists(ctx, platformStorage.ExistsFileInput{Path: key})
+	if err != nil {
+		return false, fmt.Errorf("check file %s: %w", key, err)
+	}
+	if !exists {
+		return false, nil
+	}
+
+	body, err := cmd.files.OpenFile(ctx, platformStorage.ReadFileInput{Path: key})
+	if err != nil {
+		return false, fmt.Errorf("read file %s: %w", key, err)
+	}
+	defer body.Close()
+
+	out, err := createArchiveEntry(archive, entry.Path)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(out, body); err != nil {
+		return false, fmt.Errorf("copy file %s: %w", key, err)
+	}
+	out.seal(entry)
+	return true, nil
+}
+
+func (cmd *RunDataExportCommand) fail(ctx context.Context, export *domain.DataExport, cause error) error {
+	err := fmt.Errorf("data export %s failed: %w", export.ID, cause)
+	if failErr := cmd.exports.Fail(ctx, export.ID, cause.Error()); failErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to mark data export as failed: %w", failErr))
+	}
+
+	return err
+}
+
+// archiveEntry writes one entry of the bundle and tracks its size and
+// checksum for the manifest.
+type archiveEntry struct {
+	path string
+	w    io.Writer
+	hash hash.Hash
+	size int64
+}
+
+func createArchiveEntry(archive *zip.Writer, path string) (*archiveEntry, error) {
+	w, err := archive.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("add %s to bundle: %w", path, err)
+	}
+	return &archiveEntry{path: path, w: w, hash: sha256.New()}, nil
+}
+
+func (e *archiveEntry) Write(p []byte) (int, error) {
+	n, err := e.w.Write(p)
+	e.hash.Write(p[:n])
+	e.size += int64(n)
+	if err != nil {
+		return n, fmt.Errorf("write %s to bundle: %w", e.path, err)
+	}
+	return n, nil
+}
+
+// seal records the entry's size and checksum.
+func (e *archiveEntry) seal(entry *domain.ExportManifestEntry) {
+	entry.SizeBytes, entry.SHA256 = e.size, hex.EncodeToString(e.hash.Sum(nil))
+}
+
+// InlineExportScheduler runs exports in the caller's goroutine, for tools
+// such as the seed that have no job queue.
+type InlineExportScheduler struct {
+	command *RunDataExportCommand
+}
+
+func NewInlineExportScheduler(command *RunDataExportCommand) *InlineExportScheduler {
+	return &InlineExportScheduler{command: command}
+}
+
+func (s *InlineExportScheduler) ScheduleExport(ctx context.Context, organizationID, exportID string) error {
+	return s.command.Execute(ctx, exportID)
+}
@@ -0,0 +1,351 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type memoryExports struct {
+	ports.ExportRepository
+
+	exports  map[string]*domain.DataExport
+	created  []*domain.DataExport
+	failures []string
+}
+
+func (r *memoryExports) Create(ctx context.Context, export *domain.DataExport) error {
+	r.exports[export.ID] = export
+	r.created = append(r.created, export)
+	return nil
+}
+
+func (r *memoryExports) Get(ctx context.Context, organizationID, exportID string) (*domain.DataExport, error) {
+	export, ok := r.exports[exportID]
+	if !ok || export.OrganizationID != organizationID {
+		return nil, domain.ErrExportNotFound
+	}
+	return export, nil
+}
+
+func (r *memoryExports) GetByID(ctx context.Context, exportID string) (*domain.DataExport, error) {
+	export, ok := r.exports[exportID]
+	if !ok {
+		return nil, domain.ErrExportNotFound
+	}
+	return export, nil
+}
+
+func (r *memoryExports) ListByOrganization(ctx context.Context, organizationID string) ([]*domain.DataExport, error) {
+	var exports []*domain.DataExport
+	for _, export := range r.exports {
+		if export.OrganizationID == organizationID {
+			exports = append(exports, export)
+		}
+	}
+	return exports, nil
+}
+
+func (r *memoryExports) Claim(ctx context.Context, exportID string) (bool, error) {
+	export := r.exports[exportID]
+	if export.Status != domain.ExportStatusPending {
+		return false, nil
+	}
+	export.Status = domain.ExportStatusRunning
+	return true, nil
+}
+
+func (r *memoryExports) Complete(ctx context.Context, exportID, filePath string, sizeBytes int64, sha256 string) error {
+	export := r.exports[exportID]
+	export.Status, export.FilePath, export.SizeBytes, export.SHA256 = domain.ExportStatusCompleted, filePath, sizeBytes, sha256
+	return nil
+}
+
+func (r *memoryExports) Fail(ctx context.Context, exportID, cause string) error {
+	r.exports[exportID].Status = domain.ExportStatusFailed
+	r.failures = append(r.failures, cause)
+	return nil
+}
+
+// tenantSnapshot lists the records of each dataset; FileKeys[i] is the file
+// referenced by Records[i].
+type tenantSnapshot struct {
+	SchemaVersion uint
+	Datasets      []exportedDataset
+}
+
+type exportedDataset struct {
+	Name     string
+	Records  []json.RawMessage
+	FileKeys []string
+}
+
+type staticTenantData struct {
+	data *tenantSnapshot
+	err  error
+}
+
+func (r *staticTenantData) ReadTenantData(ctx context.Context, location domain.DatabaseLocation, visit func(dataset string, rows ports.ExportRows) error) (uint, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	for _, dataset := range r.data.Datasets {
+		rows := &sliceRows{}
+		for i, record := range dataset.Records {
+			exported := domain.ExportRecord{Data: record}
+			if i < len(dataset.FileKeys) {
+				exported.FileKeys = []string{dataset.FileKeys[i]}
+			}
+			rows.records = append(rows.records, exported)
+		}
+		if err := visit(dataset.Name, rows); err != nil {
+			return 0, err
+		}
+	}
+	return r.data.SchemaVersion, nil
+}
+
+type sliceRows struct {
+	records []domain.ExportRecord
+	next    int
+}
+
+func (r *sliceRows) Next() bool {
+	r.next++
+	return r.next <= len(r.records)
+}
+
+func (r *sliceRows) Record() domain.ExportRecord {
+	return r.records[r.next-1]
+}
+
+func (r *sliceRows) Err() error {
+	return nil
+}
+
+type memoryFiles struct {
+	files map[string][]byte
+}
+
+func (s *memoryFiles) Exists(ctx context.Context, input platformStorage.ExistsFileInput) (bool, error) {
+	_, ok := s.files[input.Path]
+	return ok, nil
+}
+
+func (s *memoryFiles) OpenFile(ctx context.Context, input platformStorage.ReadFileInput) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(s.files[input.Path])), nil
+}
+
+func (s *memoryFiles) UploadFile(ctx context.Context, input platformStorage.UploadFileInput) (*platformStorage.UploadFileResult, error) {
+	data, err := io.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+	s.files[input.Path] = data
+	return &platformStorage.UploadFileResult{SizeBytes: int64(len(data))}, nil
+}
+
+func (s *memoryFiles) WriteFileIfAbsent(ctx context.Context, input platformStorage.WriteFileIfAbsentInput) (*platformStorage.WriteFileIfAbsentResult, error) {
+	s.files[input.Path] = input.Data
+	return &platformStorage.WriteFileIfAbsentResult{}, nil
+}
+
+func (s *memoryFiles) PresignDownload(ctx context.Context, input platformStorage.PresignDownloadInput) (*platformStorage.PresignDownloadResult, error) {
+	return &platformStorage.PresignDownloadResult{URL: "https://files.test/" + input.Path}, nil
+}
+
+func (s *memoryFiles) PresignUpload(ctx context.Context, input platformStorage.PresignUploadInput) (*platformStorage.PresignUploadResult, error) {
+	return &platformStorage.PresignUploadResult{URL: "https://files.test/" + input.Path, Method: "PUT"}, nil
+}
+
+type recordingExportNotifier struct {
+	ready []string
+}
+
+func (n *recordingExportNotifier) ExportReady(ctx context.Context, org *domain.Organization, export *domain.DataExport) error {
+	n.ready = append(n.ready, export.ID)
+	return nil
+}
+
+func newExportFixture() (*lifecycleRepo, *memoryExports) {
+	repo := &lifecycleRepo{org: &domain.Organization{ID: "org-1", Slug: "acme", Name: "Acme", DBName: "tenant_acme", Status: domain.StatusActive, CurrentUserRole: domain.RoleOwner}}
+	exports := &memoryExports{exports: map[string]*domain.DataExport{
+		"exp-1": {ID: "exp-1", OrganizationID: "org-1", RequestedBy: "user-1", Status: domain.ExportStatusPending, CreatedAt: time.Now()},
+	}}
+	return repo, exports
+}
+
+func TestRunDataExportWritesBundleWithManifestChecksums(t *testing.T) {
+	repo, exports := newExportFixture()
+	reader := &staticTenantData{data: &tenantSnapshot{SchemaVersion: 7, Datasets: []exportedDataset{
+		{Name: "users", Records: []json.RawMessage{json.RawMessage(`{"id":"user-1"}`)}},
+		{
+			Name:     "email_attachments",
+			Records:  []json.RawMessage{json.RawMessage(`{"id":"att-1"}`), json.RawMessage(`{"id":"att-2"}`), json.RawMessage(`{"id":"att-3"}`)},
+			FileKeys: []string{"tenant/acme/inbox/a.pdf", "tenant/acme/inbox/a.pdf", "tenant/acme/inbox/lost.pdf"},
+		},
+	}}}
+	files := &memoryFiles{files: map[string][]byte{"tenant/acme/inbox/a.pdf": []byte("%PDF-1.7")}}
+	notifier := &recordingExportNotifier{}
+	cmd := NewRunDataExportCommand(repo, exports, reader, files, notifier)
+
+	if err := cmd.Execute(context.Background(), "exp-1"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	export := exports.exports["exp-1"]
+	if export.Status != domain.ExportStatusCompleted || export.FilePath != domain.ExportPath("org-1", "exp-1") {
+		t.Fatalf("expected completed export stored under the tenant prefix, got %+v", export)
+	}
+	bundle := files.files[export.FilePath]
+	sum := sha256.Sum256(bundle)
+	if export.SHA256 != hex.EncodeToString(sum[:]) || export.SizeBytes != int64(len(bundle)) {
+		t.Fatal("expected the export to record the bundle checksum and size")
+	}
+	if !slices.Equal(notifier.ready, []string{"exp-1"}) {
+		t.Fatalf("expected the requester to be notified, got %v", notifier.ready)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatalf("bundle is not a zip: %v", err)
+	}
+	contents := map[string][]byte{}
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		contents[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+
+	var manifest domain.ExportManifest
+	if err := json.Unmarshal(contents[domain.ExportManifestPath], &manifest); err != nil {
+		t.Fatalf("invalid manifest: %v", err)
+	}
+	if manifest.SchemaVersion != 7 || manifest.OrganizationSlug != "acme" {
+		t.Fatalf("unexpected manifest header: %+v", manifest)
+	}
+	if !slices.Equal(manifest.MissingFiles, []string{"tenant/acme/inbox/lost.pdf"}) {
+		t.Fatalf("expected the lost file to be listed as missing, got %v", manifest.MissingFiles)
+	}
+	if len(manifest.Entries) != 3 {
+		t.Fatalf("expected two datasets and one file, got %+v", manifest.Entries)
+	}
+	for _, entry := range manifest.Entries {
+		sum := sha256.Sum256(contents[entry.Path])
+		if entry.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Fatalf("checksum mismatch for %s", entry.Path)
+		}
+	}
+	if manifest.Entries[1].Records != 3 {
+		t.Fatalf("expected record count in the manifest, got %+v", manifest.Entries[1])
+	}
+	if string(contents[domain.DatasetPath("users")]) != "{\"id\":\"user-1\"}\n" {
+		t.Fatalf("unexpected users dataset: %q", contents[domain.DatasetPath("users")])
+	}
+}
+
+func TestRunDataExportLeavesOutFilesOfOtherOrganizations(t *testing.T) {
+	repo, exports := newExportFixture()
+	reader := &staticTenantData{data: &tenantSnapshot{SchemaVersion: 7, Datasets: []exportedDataset{{
+		Name:     "email_attachments",
+		Records:  []json.RawMessage{json.RawMessage(`{"id":"att-1"}`), json.RawMessage(`{"id":"att-2"}`)},
+		FileKeys: []string{"tenant/other/inbox/secret.pdf", "tenant/acme/../other/inbox/secret.pdf"},
+	}}}}
+	files := &memoryFiles{files: map[string][]byte{
+		"tenant/other/inbox/secret.pdf":         []byte("secret"),
+		"tenant/acme/../other/inbox/secret.pdf": []byte("secret"),
+	}}
+
+	if err := NewRunDataExportCommand(repo, exports, reader, files, &recordingExportNotifier{}).Execute(context.Background(), "exp-1"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	bundle := files.files[exports.exports["exp-1"].FilePath]
+	archive, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatalf("bundle is not a zip: %v", err)
+	}
+	for _, f := range archive.File {
+		if f.Name != domain.ExportManifestPath && f.Name != domain.DatasetPath("email_attachments") {
+			t.Fatalf("expected no foreign file in the bundle, found %s", f.Name)
+		}
+	}
+}
+
+type rejectingUploads struct {
+	*memoryFiles
+}
+
+func (s rejectingUploads) UploadFile(ctx context.Context, input platformStorage.UploadFileInput) (*platformStorage.UploadFileResult, error) {
+	return nil, errors.New("bucket unavailable")
+}
+
+func TestRunDataExportFailsWhenTheUploadStops(t *testing.T) {
+	repo, exports := newExportFixture()
+	reader := &staticTenantData{data: &tenantSnapshot{SchemaVersion: 7, Datasets: []exportedDataset{
+		{Name: "users", Records: []json.RawMessage{json.RawMessage(`{"id":"user-1"}`)}},
+	}}}
+	files := rejectingUploads{&memoryFiles{files: map[string][]byte{}}}
+
+	// The bundle is built while it uploads; a stopped upload must not leave
+	// the build blocked on the pipe.
+	if err := NewRunDataExportCommand(repo, exports, reader, files, &recordingExportNotifier{}).Execute(context.Background(), "exp-1"); err == nil {
+		t.Fatal("expected the export to fail")
+	}
+	if exports.exports["exp-1"].Status != domain.ExportStatusFailed {
+		t.Fatalf("expected a failed export, got %+v", exports.exports["exp-1"])
+	}
+}
+
+func TestRunDataExportSkipsExportsNoLongerPending(t *testing.T) {
+	repo, exports := newExportFixture()
+	exports.exports["exp-1"].Status = domain.ExportStatusCompleted
+	reader := &staticTenantData{err: errors.New("must not read")}
+	cmd := NewRunDataExportCommand(repo, exports, reader, &memoryFiles{files: map[string][]byte{}}, &recordingExportNotifier{})
+
+	if err := cmd.Execute(context.Background(), "exp-1"); err != nil {
+		t.Fatalf("expected a duplicate job to be skipped, got %v", err)
+	}
+}
+
+func TestRunDataExportMarksFailure(t *testing.T) {
+	repo, exports := newExportFixture()
+	reader := &staticTenantData{err: errors.New("connection refused")}
+	notifier := &recordingExportNotifier{}
+	cmd := NewRunDataExportCommand(repo, exports, reader, &memoryFiles{files: map[string][]byte{}}, notifier)
+
+	if err := cmd.Execute(context.Background(), "exp-1"); err == nil {
+		t.Fatal("expected the export to fail")
+	}
+	if exports.exports["exp-1"].Status != domain.ExportStatusFailed || len(notifier.ready) != 0 {
+		t.Fatalf("expected a failed export without notification, got %+v", exports.exports["exp-1"])
+	}
+}
+
+func TestRequestDataExportRejectsConcurrentExport(t *testing.T) {
+	repo, exports := newExportFixture()
+	cmd := NewRequestDataExportCommand(repo, exports, NewInlineExportScheduler(nil))
+
+	_, err := cmd.Execute(context.Background(), "org-1", "user-1")
+	if !errors.Is(err, domain.ErrExportInProgress) {
+		t.Fatalf("expected ErrExportInProgress, got %v", err)
+	}
+	if len(exports.created) != 0 || len(repo.audit) != 0 {
+		t.Fatal("expected no export to be registered")
+	}
+}
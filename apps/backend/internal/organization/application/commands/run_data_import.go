@@ -0,0 +1,456 @@
+package commands
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	"github.com/bowerbird/internal/platform/id"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+// importProgressEvery is how many restored files go by between progress updates.
+const importProgressEvery = 25
+
+// importRecordBatch is how many records are read from a dataset and handed
+// to the tenant database at a time.
+const importRecordBatch = 500
+
+type RunDataImportCommand struct {
+	repo    ports.OrganizationRepository
+	imports ports.ImportRepository
+	writer  ports.TenantDataWriter
+	files   ports.ImportFileStore
+	newID   func() string
+	batch   int
+}
+
+func NewRunDataImportCommand(repo ports.OrganizationRepository, imports ports.ImportRepository, writer ports.TenantDataWriter, files ports.ImportFileStore) *RunDataImportCommand {
+	return &RunDataImportCommand{repo: repo, imports: imports, writer: writer, files: files, newID: id.NewULID, batch: importRecordBatch}
+}
+
+// Execute verifies the uploaded bundle against its manifest, copies its
+// files under the organization's prefix and restores its records in one
+// transaction. Imports that are no longer pending are skipped, which makes
+// duplicate jobs harmless.
+func (cmd *RunDataImportCommand) Execute(ctx context.Context, importID string) error {
+	claimed, err := cmd.imports.Claim(ctx, importID)
+	if err != nil {
+		return fmt.Errorf("failed to claim data import: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	dataImport, err := cmd.imports.GetByID(ctx, importID)
+	if err != nil {
+		return fmt.Errorf("failed to load data import: %w", err)
+	}
+
+	org, err := cmd.repo.GetByID(ctx, dataImport.OrganizationID, "")
+	if err != nil {
+		return cmd.fail(ctx, dataImport, fmt.Errorf("load organization: %w", err))
+	}
+	if err := org.CanImport(); err != nil {
+		return cmd.fail(ctx, dataImport, err)
+	}
+
+	progress, err := cmd.restore(ctx, org, dataImport)
+	if err != nil {
+		return cmd.fail(ctx, dataImport, err)
+	}
+
+	if err := cmd.imports.Complete(ctx, dataImport.ID, progress); err != nil {
+		return fmt.Errorf("failed to complete data import: %w", err)
+	}
+
+	return nil
+}
+
+func (cmd *RunDataImportCommand) restore(ctx context.Context, org *domain.Organization, dataImport *domain.DataImport) (domain.ImportProgress, error) {
+	progress := domain.ImportProgress{Step: domain.ImportStepValidating}
+	cmd.report(ctx, dataImport, progress)
+
+	spool, err := cmd.spoolBundle(ctx, dataImport.FilePath)
+	if err != nil {
+		return progress, err
+	}
+	defer removeSpool(spool)
+
+	bundle, err := readImportBundle(spool)
+	if err != nil {
+		return progress, err
+	}
+
+	progress.SourceOrganizationID = bundle.manifest.OrganizationID
+	progress.FilesTotal = len(bundle.files)
+	for _, dataset := range bundle.datasets {
+		progress.RecordsTotal += dataset.entry.Records
+	}
+	cmd.report(ctx, dataImport, progress)
+
+	session, err := cmd.writer.BeginImport(ctx, org.Location())
+	if err != nil {
+		return progress, fmt.Errorf("begin import: %w", err)
+	}
+	defer session.Rollback(ctx)
+
+	version, err := session.SchemaVersion(ctx)
+	if err != nil {
+		return progress, fmt.Errorf("read schema version: %w", err)
+	}
+	if bundle.manifest.SchemaVersion > version {
+		return progress, fmt.Errorf("%w: bundle %d, organization %d", domain.ErrImportSchemaTooNew, bundle.manifest.SchemaVersion, version)
+	}
+
+	target, err := session.TargetKeys(ctx)
+	if err != nil {
+		return progress, fmt.Errorf("read existing records: %w", err)
+	}
+	if err := target.EnsureEmpty(); err != nil {
+		return progress, err
+	}
+
+	// Files go first: they are written only if absent, so a failed import
+	// can be retried, while the records land all at once at the end.
+	progress.Step = domain.ImportStepRestoringFiles
+	cmd.report(ctx, dataImport, progress)
+	fileKeys := make(map[string]string, len(bundle.files))
+	for _, file := range bundle.files {
+		key, err := domain.ImportFileKey(org.Slug, file.entry.Source)
+		if err != nil {
+			return progress, err
+		}
+		// Files are read one at a time, so memory holds at most the largest.
+		content, err := readVerifiedEntry(file.archived, file.entry)
+		if err != nil {
+			return progress, err
+		}
+		if _, err := cmd.files.WriteFileIfAbsent(ctx, platformStorage.WriteFileIfAbsentInput{
+			Path:     key,
+			Data:     content,
+			Metadata: map[string]string{"organization-id": org.ID, "import-id": dataImport.ID},
+		}); err != nil {
+			return progress, fmt.Errorf("restore file %s: %w", file.entry.Source, err)
+		}
+		fileKeys[file.entry.Source] = key
+		progress.FilesImported++
+		if progress.FilesImported%importProgressEvery == 0 {
+			cmd.report(ctx, dataImport, progress)
+		}
+	}
+
+	// Records may still reference files the export could not find; they
+	// keep a key under the organization's prefix, where nothing is stored.
+	for _, missing := range bundle.manifest.MissingFiles {
+		if _, ok := fileKeys[missing]; ok {
+			continue
+		}
+		key, err := domain.ImportFileKey(org.Slug, missing)
+		if err != nil {
+			return progress, err
+		}
+		fileKeys[missing] = key
+	}
+
+	progress.Step = domain.ImportStepRestoringRecords
+	cmd.report(ctx, dataImport, progress)
+	remapper := domain.NewImportRemapper(target, fileKeys, domain.ImportFilePrefix(org.Slug), cmd.newID)
+	for _, spec := range domain.ImportDatasets {
+		dataset, ok := bundle.datasets[spec.Name]
+		if !ok {
+			continue
+		}
+
+		// Datasets are streamed from the archive, so memory holds one batch.
+		err := streamDataset(dataset, cmd.batch, func(records []domain.ImportRecord) error {
+			insert := make([]domain.ImportRecord, 0, len(records))
+			for _, record := range records {
+				keep, err := remapper.Remap(spec, record)
+				if err != nil {
+					return fmt.Errorf("remap %s: %w", spec.Name, err)
+				}
+				if keep {
+					insert = append(insert, record)
+				}
+			}
+			if len(insert) > 0 {
+				if err := session.Insert(ctx, spec, insert); err != nil {
+					return fmt.Errorf("restore %s: %w", spec.Name, err)
+				}
+			}
+
+			progress.RecordsImported += len(records)
+			progress.RemappedIDs = remapper.Remapped()
+			cmd.report(ctx, dataImport, progress)
+			return nil
+		})
+		if err != nil {
+			return progress, err
+		}
+	}
+
+	if err := session.Commit(ctx); err != nil {
+		return progress, fmt.Errorf("commit import: %w", err)
+	}
+
+	return progress, nil
+}
+
+// report stores the progress. It only informs the owner, so a failed update
+// does not stop the import.
+func (cmd *RunDataImportCommand) report(ctx context.Context, dataImport *domain.DataImport, progress domain.ImportProgress) {
+	if err := cmd.imports.UpdateProgress(ctx, dataImport.ID, progress); err != nil {
+		slog.Warn("Data import progress update failed", "organization_id", dataImport.OrganizationID, "import_id", dataImport.ID, "error", err)
+	}
+}
+
+func (cmd *RunDataImportCommand) fail(ctx context.Context, dataImport *domain.DataImport, cause error) error {
+	err := fmt.Errorf("data import %s failed: %w", dataImport.ID, cause)
+	if failErr := cmd.imports.Fail(ctx, dataImport.ID, cause.Error()); failErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to mark data import as failed: %w", failErr))
+	}
+
+	return err
+}
+
+// spoolBundle downloads the bundle into a temporary file. A zip is read from
+// its end, so it needs random access, and the bundle can be larger than the
+// worker's memory.
+func (cmd *RunDataImportCommand) spoolBundle(ctx context.Context, path string) (*os.File, error) {
+	body, err := cmd.files.OpenFile(ctx, platformStorage.ReadFileInput{Path: path})
+	if err != nil {
+		return nil, fmt.Errorf("open bundle: %w", err)
+	}
+	defer body.Close()
+
+	spool, err := os.CreateTemp("", "data-import-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("create bundle spool: %w", err)
+	}
+	if _, err := io.Copy(spool, body); err != nil {
+		removeSpool(spool)
+		return nil, fmt.Errorf("download bundle: %w", err)
+	}
+	return spool, nil
+}
+
+func removeSpool(spool *os.File) {
+	spool.Close()
+	if err := os.Remove(spool.Name()); err != nil {
+		slog.Warn("Data import bundle spool removal failed", "path", spool.Name(), "error", err)
+	}
+}
+
+type importEntry struct {
+	entry    domain.ExportManifestEntry
+	archived *zip.File
+}
+
+type importBundle struct {
+	manifest domain.ExportManifest
+	datasets map[string]importEntry
+	files    []importEntry
+}
+
+// readImportBundle checks every entry of the bundle against the size and
+// checksum in its manifest. Entries the manifest does not list are rejected,
+// as they cannot be verified. Datasets and files stay in the archive until
+// they are restored.
+func readImportBundle(spool *os.File) (*importBundle, error) {
+	info, err := spool.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat bundle spool: %w", err)
+	}
+	archive, err := zip.NewReader(spool, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidImportBundle, err)
+	}
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		entries[f.Name] = f
+	}
+
+	manifestFile, ok := entries[domain.ExportManifestPath]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %s", domain.ErrInvalidImportBundle, domain.ExportManifestPath)
+	}
+	manifestJSON, err := readArchiveEntry(manifestFile, int64(manifestFile.UncompressedSize64))
+	if err != nil {
+		return nil, err
+	}
+	bundle := &importBundle{datasets: map[string]importEntry{}}
+	if err := json.Unmarshal(manifestJSON, &bundle.manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", domain.ErrInvalidImportBundle, err)
+	}
+	if bundle.manifest.FormatVersion != domain.ExportFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", domain.ErrInvalidImportBundle, bundle.manifest.FormatVersion)
+	}
+
+	listed := map[string]bool{domain.ExportManifestPath: true}
+	for _, entry := range bundle.manifest.Entries {
+		f, ok := entries[entry.Path]
+		if !ok {
+			return nil, fmt.Errorf("%w: missing %s", domain.ErrInvalidImportBundle, entry.Path)
+		}
+		listed[entry.Path] = true
+
+		switch entry.Kind {
+		case domain.ExportEntryDataset:
+			if err := checkDataset(entry); err != nil {
+				return nil, err
+			}
+			if err := verifyArchiveEntry(f, entry); err != nil {
+				return nil, err
+			}
+			bundle.datasets[entry.Dataset] = importEntry{entry: entry, archived: f}
+		case domain.ExportEntryFile:
+			if entry.Source == "" || entry.Path != domain.ExportFilePath(entry.Source) {
+				return nil, fmt.Errorf("%w: file entry %s does not match its source", domain.ErrInvalidImportBundle, entry.Path)
+			}
+			if err := verifyArchiveEntry(f, entry); err != nil {
+				return nil, err
+			}
+			bundle.files = append(bundle.files, importEntry{entry: entry, archived: f})
+		default:
+			return nil, fmt.Errorf("%w: unknown entry kind %q", domain.ErrInvalidImportBundle, entry.Kind)
+		}
+	}
+
+	for name := range entries {
+		if !listed[name] {
+			return nil, fmt.Errorf("%w: %s is not in the manifest", domain.ErrInvalidImportBundle, name)
+		}
+	}
+
+	return bundle, nil
+}
+
+// verifyArchiveEntry streams the entry through its checksum without keeping it.
+func verifyArchiveEntry(f *zip.File, entry domain.ExportManifestEntry) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("%w: open %s: %v", domain.ErrInvalidImportBundle, f.Name, err)
+	}
+	defer rc.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, io.LimitReader(rc, entry.SizeBytes+1))
+	if err != nil {
+		return fmt.Errorf("%w: read %s: %v", domain.ErrInvalidImportBundle, f.Name, err)
+	}
+	return checkEntrySum(entry, size, hash.Sum(nil))
+}
+
+// readVerifiedEntry reads the entry and checks it against the manifest.
+func readVerifiedEntry(f *zip.File, entry domain.ExportManifestEntry) ([]byte, error) {
+	data, err := readArchiveEntry(f, entry.SizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if err := checkEntrySum(entry, int64(len(data)), sum[:]); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func checkEntrySum(entry domain.ExportManifestEntry, size int64, sum []byte) error {
+	if size != entry.SizeBytes || hex.EncodeToString(sum) != entry.SHA256 {
+		return fmt.Errorf("%w: checksum mismatch for %s", domain.ErrInvalidImportBundle, entry.Path)
+	}
+	return nil
+}
+
+// readArchiveEntry reads at most limit bytes plus one, so an entry larger
+// than its manifest claims fails the checksum instead of filling memory.
+func readArchiveEntry(f *zip.File, limit int64) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: open %s: %v", domain.ErrInvalidImportBundle, f.Name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: read %s: %v", domain.ErrInvalidImportBundle, f.Name, err)
+	}
+	return data, nil
+}
+
+func checkDataset(entry domain.ExportManifestEntry) error {
+	known := slices.ContainsFunc(domain.ImportDatasets, func(spec domain.ImportDatasetSpec) bool {
+		return spec.Name == entry.Dataset
+	})
+	if !known || entry.Path != domain.DatasetPath(entry.Dataset) {
+		return fmt.Errorf("%w: unknown dataset %q", domain.ErrInvalidImportBundle, entry.Dataset)
+	}
+	return nil
+}
+
+// streamDataset decodes the dataset's records and passes them to insert in
+// batches of at most size. The entry was verified when the bundle was read,
+// so only its record count is checked here.
+func streamDataset(dataset importEntry, size int, insert func([]domain.ImportRecord) error) error {
+	entry := dataset.entry
+	rc, err := dataset.archived.Open()
+	if err != nil {
+		return fmt.Errorf("%w: open %s: %v", domain.ErrInvalidImportBundle, entry.Path, err)
+	}
+	defer rc.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(rc, entry.SizeBytes))
+	batch := make([]domain.ImportRecord, 0, size)
+	count := 0
+	for {
+		var record domain.ImportRecord
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("%w: invalid record in %s: %v", domain.ErrInvalidImportBundle, entry.Path, err)
+		}
+		count++
+		if count > entry.Records {
+			return fmt.Errorf("%w: %s has more records than the manifest's %d", domain.ErrInvalidImportBundle, entry.Path, entry.Records)
+		}
+
+		batch = append(batch, record)
+		if len(batch) == size {
+			if err := insert(batch); err != nil {
+				return err
+			}
+			batch = make([]domain.ImportRecord, 0, size)
+		}
+	}
+	if count != entry.Records {
+		return fmt.Errorf("%w: %s has %d records, manifest says %d", domain.ErrInvalidImportBundle, entry.Path, count, entry.Records)
+	}
+	if len(batch) > 0 {
+		return insert(batch)
+	}
+	return nil
+}
+
+// InlineImportScheduler runs imports in the caller's goroutine, for tools
+// such as the seed that have no job queue.
+type InlineImportScheduler struct {
+	command *RunDataImportCommand
+}
+
+func NewInlineImportScheduler(command *RunDataImportCommand) *InlineImportScheduler {
+	return &InlineImportScheduler{command: command}
+}
+
+func (s *InlineImportScheduler) ScheduleImport(ctx context.Context, organizationID, importID string) error {
+	return s.command.Execute(ctx, importID)
+}
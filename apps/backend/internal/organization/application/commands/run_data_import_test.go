@@ -0,0 +1,259 @@
+package commands
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type memoryImports struct {
+	ports.ImportRepository
+
+	imports  map[string]*domain.DataImport
+	progress []domain.ImportProgress
+	failures []string
+}
+
+func (r *memoryImports) GetByID(ctx context.Context, importID string) (*domain.DataImport, error) {
+	dataImport, ok := r.imports[importID]
+	if !ok {
+		return nil, domain.ErrImportNotFound
+	}
+	return dataImport, nil
+}
+
+func (r *memoryImports) Claim(ctx context.Context, importID string) (bool, error) {
+	dataImport := r.imports[importID]
+	if dataImport.Status != domain.ImportStatusPending {
+		return false, nil
+	}
+	dataImport.Status = domain.ImportStatusRunning
+	return true, nil
+}
+
+func (r *memoryImports) UpdateProgress(ctx context.Context, importID string, progress domain.ImportProgress) error {
+	r.progress = append(r.progress, progress)
+	return nil
+}
+
+func (r *memoryImports) Complete(ctx context.Context, importID string, progress domain.ImportProgress) error {
+	r.imports[importID].Status = domain.ImportStatusCompleted
+	r.imports[importID].Progress = progress
+	return nil
+}
+
+func (r *memoryImports) Fail(ctx context.Context, importID, cause string) error {
+	r.imports[importID].Status = domain.ImportStatusFailed
+	r.failures = append(r.failures, cause)
+	return nil
+}
+
+type fakeTenantImport struct {
+	version   uint
+	target    *domain.ImportTargetKeys
+	inserted  map[string][]domain.ImportRecord
+	batches   [][]domain.ImportRecord
+	committed bool
+}
+
+func (w *fakeTenantImport) BeginImport(ctx context.Context, location domain.DatabaseLocation) (ports.TenantImport, error) {
+	return w, nil
+}
+
+func (w *fakeTenantImport) SchemaVersion(ctx context.Context) (uint, error) {
+	return w.version, nil
+}
+
+func (w *fakeTenantImport) TargetKeys(ctx context.Context) (*domain.ImportTargetKeys, error) {
+	return w.target, nil
+}
+
+func (w *fakeTenantImport) Insert(ctx context.Context, spec domain.ImportDatasetSpec, records []domain.ImportRecord) error {
+	w.inserted[spec.Name] = append(w.inserted[spec.Name], records...)
+	w.batches = append(w.batches, records)
+	return nil
+}
+
+func (w *fakeTenantImport) Commit(ctx context.Context) error {
+	w.committed = true
+	return nil
+}
+
+func (w *fakeTenantImport) Rollback(ctx context.Context) error {
+	return nil
+}
+
+// exportBundle builds a bundle with the export command, so imports are
+// tested against what exports really produce.
+func exportBundle(t *testing.T) []byte {
+	t.Helper()
+	repo, exports := newExportFixture()
+	repo.org.Slug = "acme-staging"
+	reader := &staticTenantData{data: &tenantSnapshot{SchemaVersion: 7, Datasets: []exportedDataset{
+		{Name: "users", Records: []json.RawMessage{
+			json.RawMessage(`{"id":"SRC-OWNER","email":"owner@acme.test"}`),
+			json.RawMessage(`{"id":"TAKEN","email":"ana@acme.test"}`),
+		}},
+		{Name: "connections", Records: []json.RawMessage{json.RawMessage(`{"id":"CONN","owner_user_id":"TAKEN","status":"active"}`)}},
+		{Name: "email_messages", Records: []json.RawMessage{json.RawMessage(`{"id":"MSG","account_id":"CONN"}`)}},
+		{
+			Name:     "email_attachments",
+			Records:  []json.RawMessage{json.RawMessage(`{"id":"ATT","message_id":"MSG","s3_key":"tenant/acme-staging/inbox/a.pdf"}`)},
+			FileKeys: []string{"tenant/acme-staging/inbox/a.pdf"},
+		},
+	}}}
+	files := &memoryFiles{files: map[string][]byte{"tenant/acme-staging/inbox/a.pdf": []byte("%PDF-1.7")}}
+	if err := NewRunDataExportCommand(repo, exports, reader, files, &recordingExportNotifier{}).Execute(context.Background(), "exp-1"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	return files.files[exports.exports["exp-1"].FilePath]
+}
+
+func newImportFixture(bundle []byte) (*lifecycleRepo, *memoryImports, *fakeTenantImport, *memoryFiles) {
+	repo := &lifecycleRepo{org: &domain.Organization{ID: "org-2", Slug: "acme", DBName: "tenant_acme", Status: domain.StatusActive}}
+	imports := &memoryImports{imports: map[string]*domain.DataImport{
+		"imp-1": {ID: "imp-1", OrganizationID: "org-2", Status: domain.ImportStatusPending, FilePath: domain.ImportPath("org-2", "imp-1"), CreatedAt: time.Now()},
+	}}
+	writer := &fakeTenantImport{
+		version: 7,
+		target: &domain.ImportTargetKeys{
+			IDs:     map[string]map[string]bool{"users": {"OWNER": true, "TAKEN": true}},
+			Natural: map[string]map[string]string{"users": {"owner@acme.test": "OWNER"}},
+		},
+		inserted: map[string][]domain.ImportRecord{},
+	}
+	files := &memoryFiles{files: map[string][]byte{domain.ImportPath("org-2", "imp-1"): bundle}}
+	return repo, imports, writer, files
+}
+
+func TestRunDataImportRestoresBundleWithRemappedIDs(t *testing.T) {
+	repo, imports, writer, files := newImportFixture(exportBundle(t))
+	cmd := NewRunDataImportCommand(repo, imports, writer, files)
+	cmd.newID = func() string { return "NEW" }
+
+	if err := cmd.Execute(context.Background(), "imp-1"); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	dataImport := imports.imports["imp-1"]
+	if dataImport.Status != domain.ImportStatusCompleted || !writer.committed {
+		t.Fatalf("expected a committed import, got %+v", dataImport)
+	}
+	if p := dataImport.Progress; p.RecordsTotal != 5 || p.RecordsImported != 5 || p.FilesImported != 1 || p.RemappedIDs != 1 || p.SourceOrganizationID != "org-1" {
+		t.Fatalf("unexpected progress: %+v", p)
+	}
+
+	users := writer.inserted["users"]
+	if len(users) != 1 || string(users[0]["id"]) != `"NEW"` {
+		t.Fatalf("expected only the colliding user to be inserted with a new ID, got %v", users)
+	}
+	connection := writer.inserted["connections"][0]
+	if string(connection["owner_user_id"]) != `"NEW"` || string(connection["status"]) != `"requires_reconnect"` {
+		t.Fatalf("expected the connection to follow the remapped owner, got %v", connection)
+	}
+	attachment := writer.inserted["email_attachments"][0]
+	if string(attachment["s3_key"]) != `"tenant/acme/inbox/a.pdf"` {
+		t.Fatalf("expected the attachment under the target prefix, got %s", attachment["s3_key"])
+	}
+	if string(files.files["tenant/acme/inbox/a.pdf"]) != "%PDF-1.7" {
+		t.Fatal("expected the attachment to be restored")
+	}
+}
+
+func TestRunDataImportInsertsRecordsInBatches(t *testing.T) {
+	repo, imports, writer, files := newImportFixture(exportBundle(t))
+	writer.target = &domain.ImportTargetKeys{}
+	cmd := NewRunDataImportCommand(repo, imports, writer, files)
+	cmd.batch = 1
+
+	if err := cmd.Execute(context.Background(), "imp-1"); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	if len(writer.batches) != 5 {
+		t.Fatalf("expected one insert per record, got %d", len(writer.batches))
+	}
+	for _, batch := range writer.batches {
+		if len(batch) != 1 {
+			t.Fatalf("expected batches of one record, got %d", len(batch))
+		}
+	}
+	if len(writer.inserted["users"]) != 2 || !writer.committed {
+		t.Fatalf("expected both users in the committed import, got %v", writer.inserted["users"])
+	}
+}
+
+func TestRunDataImportRejectsTamperedBundle(t *testing.T) {
+	bundle := exportBundle(t)
+	archive, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatalf("bundle is not a zip: %v", err)
+	}
+	var tampered bytes.Buffer
+	w := zip.NewWriter(&tampered)
+	for _, f := range archive.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		if f.Name == domain.DatasetPath("users") {
+			content = bytes.Replace(content, []byte("ana@"), []byte("eve@"), 1)
+		}
+		entry, _ := w.Create(f.Name)
+		entry.Write(content)
+	}
+	w.Close()
+
+	repo, imports, writer, files := newImportFixture(tampered.Bytes())
+	err = NewRunDataImportCommand(repo, imports, writer, files).Execute(context.Background(), "imp-1")
+	if !errors.Is(err, domain.ErrInvalidImportBundle) {
+		t.Fatalf("expected ErrInvalidImportBundle, got %v", err)
+	}
+	if imports.imports["imp-1"].Status != domain.ImportStatusFailed || writer.committed || len(writer.inserted) != 0 {
+		t.Fatal("expected a failed import without writes")
+	}
+}
+
+func TestRunDataImportRejectsNewerSchema(t *testing.T) {
+	repo, imports, writer, files := newImportFixture(exportBundle(t))
+	writer.version = 6
+
+	err := NewRunDataImportCommand(repo, imports, writer, files).Execute(context.Background(), "imp-1")
+	if !errors.Is(err, domain.ErrImportSchemaTooNew) {
+		t.Fatalf("expected ErrImportSchemaTooNew, got %v", err)
+	}
+}
+
+func TestRunDataImportKeepsReferencesToMissingFilesUnderTheTargetPrefix(t *testing.T) {
+	repo, exports := newExportFixture()
+	repo.org.Slug = "acme-staging"
+	reader := &staticTenantData{data: &tenantSnapshot{SchemaVersion: 7, Datasets: []exportedDataset{
+		{Name: "connections", Records: []json.RawMessage{json.RawMessage(`{"id":"CONN","status":"active"}`)}},
+		{Name: "email_messages", Records: []json.RawMessage{json.RawMessage(`{"id":"MSG","account_id":"CONN"}`)}},
+		{
+			Name:     "email_attachments",
+			Records:  []json.RawMessage{json.RawMessage(`{"id":"ATT","message_id":"MSG","s3_key":"tenant/acme-staging/inbox/lost.pdf"}`)},
+			FileKeys: []string{"tenant/acme-staging/inbox/lost.pdf"},
+		},
+	}}}
+	exportFiles := &memoryFiles{files: map[string][]byte{}}
+	if err := NewRunDataExportCommand(repo, exports, reader, exportFiles, &recordingExportNotifier{}).Execute(context.Background(), "exp-1"); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	repo, imports, writer, files := newImportFixture(exportFiles.files[exports.exports["exp-1"].FilePath])
+	if err := NewRunDataImportCommand(repo, imports, writer, files).Execute(context.Background(), "imp-1"); err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	attachment := writer.inserted["email_attachments"][0]
+	if string(attachment["s3_key"]) != `"tenant/acme/inbox/lost.pdf"` {
+		t.Fatalf("expected the missing file key under the target prefix, got %s", attachment["s3_key"])
+	}
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type StartDataImportCommand struct {
+	repo      ports.OrganizationRepository
+	imports   ports.ImportRepository
+	files     ports.ImportFileStore
+	scheduler ports.ImportScheduler
+	now       func() time.Time
+}
+
+func NewStartDataImportCommand(repo ports.OrganizationRepository, imports ports.ImportRepository, files ports.ImportFileStore, scheduler ports.ImportScheduler) *StartDataImportCommand {
+	return &StartDataImportCommand{repo: repo, imports: imports, files: files, scheduler: scheduler, now: time.Now}
+}
+
+// Execute schedules an import whose bundle has been uploaded. Only one
+// import runs at a time per organization.
+func (cmd *StartDataImportCommand) Execute(ctx context.Context, organizationID, importID, actorID string) (*domain.DataImport, error) {
+	org, err := requireOwner(ctx, cmd.repo, organizationID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := org.CanImport(); err != nil {
+		return nil, err
+	}
+
+	dataImport, err := cmd.imports.Get(ctx, org.ID, importID)
+	if err != nil {
+		return nil, err
+	}
+	if dataImport.Status != domain.ImportStatusAwaitingUpload {
+		return nil, domain.ErrImportNotAwaitingUpload
+	}
+
+	now := cmd.now().UTC()
+	existing, err := cmd.imports.ListByOrganization(ctx, org.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data imports: %w", err)
+	}
+	for _, other := range existing {
+		if other.InProgress(now) {
+			return nil, domain.ErrImportInProgress
+		}
+	}
+
+	uploaded, err := cmd.files.Exists(ctx, platformStorage.ExistsFileInput{Path: dataImport.FilePath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check bundle upload: %w", err)
+	}
+	if !uploaded {
+		return nil, domain.ErrImportBundleMissing
+	}
+
+	started, err := cmd.imports.MarkUploaded(ctx, dataImport.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start data import: %w", err)
+	}
+	if !started {
+		return nil, domain.ErrImportNotAwaitingUpload
+	}
+
+	details := map[string]any{"import_id": dataImport.ID}
+	if err := recordAudit(ctx, cmd.repo, org.ID, actorID, domain.ActionDataImportStarted, details, now); err != nil {
+		return nil, err
+	}
+
+	if err := cmd.scheduler.ScheduleImport(ctx, org.ID, dataImport.ID); err != nil {
+		err = fmt.Errorf("failed to schedule data import: %w", err)
+		if failErr := cmd.imports.Fail(ctx, dataImport.ID, err.Error()); failErr != nil {
+			return nil, errors.Join(err, failErr)
+		}
+		return nil, err
+	}
+
+	return cmd.imports.Get(ctx, org.ID, dataImport.ID)
+}
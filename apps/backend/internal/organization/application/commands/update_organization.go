@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type UpdateOrganizationInput struct {
+	OrganizationID string
+	ActorID        string
+	// Nil fields are left unchanged.
+	Name *string
+	Slug *string
+}
+
+type UpdateOrganizationCommand struct {
+	repo ports.OrganizationRepository
+	now  func() time.Time
+}
+
+func NewUpdateOrganizationCommand(repo ports.OrganizationRepository) *UpdateOrganizationCommand {
+	return &UpdateOrganizationCommand{repo: repo, now: time.Now}
+}
+
+// Execute renames the organization and/or changes its slug. The old slug
+// keeps resolving to the organization as a redirect.
+func (cmd *UpdateOrganizationCommand) Execute(ctx context.Context, input UpdateOrganizationInput) (*domain.Organization, error) {
+	org, err := requireOwner(ctx, cmd.repo, input.OrganizationID, input.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsEditable() {
+		return nil, domain.ErrInvalidStatusTransition
+	}
+
+	if input.Name != nil {
+		name, err := domain.NormalizeOrganizationName(*input.Name)
+		if err != nil {
+			return nil, err
+		}
+		if name != org.Name {
+			details := map[string]any{"from": org.Name, "to": name}
+			event := newAuditEvent(org.ID, input.ActorID, domain.ActionRenamed, details, cmd.now().UTC())
+			if err := cmd.repo.Rename(ctx, org.ID, name, event); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if input.Slug != nil {
+		slug, err := domain.NormalizeSlug(*input.Slug)
+		if err != nil {
+			return nil, err
+		}
+		if slug != org.Slug {
+			details := map[string]any{"from": org.Slug, "to": slug}
+			event := newAuditEvent(org.ID, input.ActorID, domain.ActionSlugChanged, details, cmd.now().UTC())
+			if err := cmd.repo.ChangeSlug(ctx, org.ID, org.Slug, slug, event); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return cmd.repo.GetByID(ctx, org.ID, input.ActorID)
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type UpdateSecurityPolicyInput struct {
+	TenantID    string
+	MFARequired bool
+	// ActorHasMFA is whether the caller's own session passed a second factor.
+	ActorHasMFA bool
+}
+
+type UpdateSecurityPolicyCommand struct {
+	repo ports.OrganizationRepository
+}
+
+func NewUpdateSecurityPolicyCommand(repo ports.OrganizationRepository) *UpdateSecurityPolicyCommand {
+	return &UpdateSecurityPolicyCommand{repo: repo}
+}
+
+// Execute only lets an MFA session turn the requirement on, so an admin cannot
+// lock the organization behind a factor they have not set up themselves.
+func (cmd *UpdateSecurityPolicyCommand) Execute(ctx context.Context, input UpdateSecurityPolicyInput) error {
+	if input.MFARequired && !input.ActorHasMFA {
+		return domain.ErrMFASessionRequired
+	}
+
+	return cmd.repo.SetMFARequired(ctx, input.TenantID, input.MFARequired)
+}
@@ -16,8 +16,11 @@ type CreateOrganizationUseCase struct {
 	command *commands.CreateOrganizationCommand
 }
 
+// NewCreateOrganizationUseCase provisions inline, each organization in its own database.
 func NewCreateOrganizationUseCase(repo ports.OrganizationRepository, provisioner ports.Provisioner) *CreateOrganizationUseCase {
-	return &CreateOrganizationUseCase{command: commands.NewCreateOrganizationCommand(repo, provisioner)}
+	scheduler := commands.NewInlineProvisioningScheduler(commands.NewProvisionOrganizationCommand(repo, provisioner))
+	isolation := domain.IsolationPolicy{Mode: domain.IsolationDatabase}
+	return &CreateOrganizationUseCase{command: commands.NewCreateOrganizationCommand(repo, scheduler, isolation)}
 }
 
 func NewCreateOrganizationUseCaseFromCommand(command *commands.CreateOrganizationCommand) *CreateOrganizationUseCase {
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/bowerbird/internal/organization/application/commands"
 	"github.com/bowerbird/internal/organization/domain"
 )
 
@@ -15,10 +17,12 @@ type fakeOrganizationRepo struct {
 	addMembershipErr error
 	updateStatusErr  error
 
-	createdOrg     *domain.Organization
-	createdStatus  string
-	statusUpdates  []string
-	membershipAdds int
+	createdOrg       *domain.Organization
+	createdStatus    string
+	statusUpdates    []string
+	membershipAdds   int
+	completedStep    string
+	provisioningErrs []string
 }
 
 func (r *fakeOrganizationRepo) Create(ctx context.Context, org *domain.Organization) error {
@@ -43,7 +47,19 @@ func (r *fakeOrganizationRepo) ExistsBySlug(ctx context.Context, slug string) (b
 }
 
 func (r *fakeOrganizationRepo) GetByID(ctx context.Context, id, userID string) (*domain.Organization, error) {
-	return nil, nil // Dummy implementation for test
+	if r.createdOrg == nil || r.createdOrg.ID != id {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	org := *r.createdOrg
+	if len(r.statusUpdates) > 0 {
+		org.Status = r.statusUpdates[len(r.statusUpdates)-1]
+	}
+	org.ProvisioningStep = r.completedStep
+	return &org, nil
+}
+
+func (r *fakeOrganizationRepo) SetMFARequired(ctx context.Context, organizationID string, required bool) error {
+	return nil
 }
 
 func (r *fakeOrganizationRepo) AddMembership(ctx context.Context, userID, tenantID, role string) error {
@@ -54,24 +70,81 @@ func (r *fakeOrganizationRepo) AddMembership(ctx context.Context, userID, tenant
 	return nil
 }
 
+func (r *fakeOrganizationRepo) CompleteProvisioningStep(ctx context.Context, organizationID, step string) error {
+	r.completedStep = step
+	return nil
+}
+
+func (r *fakeOrganizationRepo) FailProvisioning(ctx context.Context, organizationID, cause string) error {
+	if r.updateStatusErr != nil {
+		return r.updateStatusErr
+	}
+	r.statusUpdates = append(r.statusUpdates, domain.StatusFailed)
+	r.provisioningErrs = append(r.provisioningErrs, cause)
+	return nil
+}
+
+func (r *fakeOrganizationRepo) Rename(ctx context.Context, organizationID, name string, event *domain.AuditEvent) error {
+	return nil
+}
+
+func (r *fakeOrganizationRepo) ChangeSlug(ctx context.Context, organizationID, oldSlug, newSlug string, event *domain.AuditEvent) error {
+	return nil
+}
+
+func (r *fakeOrganizationRepo) ListSlugRedirects(ctx context.Context, organizationID string) ([]string, error) {
+	return nil, nil
+}
+
+func (r *fakeOrganizationRepo) ChangeStatus(ctx context.Context, organizationID string, from []string, status string, event *domain.AuditEvent) error {
+	return nil
+}
+
+func (r *fakeOrganizationRepo) ScheduleDeletion(ctx context.Context, organizationID string, from []string, purgeAt time.Time, event *domain.AuditEvent) error {
+	return nil
+}
+
+func (r *fakeOrganizationRepo) CancelDeletion(ctx context.Context, organizationID string, event *domain.AuditEvent) (string, error) {
+	return "", nil
+}
+
+func (r *fakeOrganizationRepo) ClaimDueDeletions(ctx context.Context, now time.Time, staleAfter time.Duration) ([]*domain.Organization, error) {
+	return nil, nil
+}
+
+func (r *fakeOrganizationRepo) Purge(ctx context.Context, organizationID string, event *domain.AuditEvent) error {
+	return nil
+}
+
+func (r *fakeOrganizationRepo) RecordAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	return nil
+}
+
 type fakeProvisioner struct {
 	createDatabaseErr  error
 	migrateDatabaseErr error
 	seedOwnerErr       error
+
+	created []domain.DatabaseLocation
 }
 
-func (p *fakeProvisioner) CreateDatabase(ctx context.Context, dbName string) error {
+func (p *fakeProvisioner) CreateDatabase(ctx context.Context, location domain.DatabaseLocation) error {
+	p.created = append(p.created, location)
 	return p.createDatabaseErr
 }
 
-func (p *fakeProvisioner) MigrateDatabase(ctx context.Context, dbName string) error {
+func (p *fakeProvisioner) MigrateDatabase(ctx context.Context, location domain.DatabaseLocation) error {
 	return p.migrateDatabaseErr
 }
 
-func (p *fakeProvisioner) SeedOwner(ctx context.Context, dbName string, owner domain.OwnerData) error {
+func (p *fakeProvisioner) SeedOwner(ctx context.Context, location domain.DatabaseLocation, owner domain.OwnerData) error {
 	return p.seedOwnerErr
 }
 
+func (p *fakeProvisioner) DropDatabase(ctx context.Context, location domain.DatabaseLocation) error {
+	return nil
+}
+
 func TestCreateOrganizationStartsProvisioningAndEndsActive(t *testing.T) {
 	repo := &fakeOrganizationRepo{}
 	provisioner := &fakeProvisioner{}
@@ -126,3 +199,29 @@ func TestCreateOrganizationMarksFailedWhenProvisioningFails(t *testing.T) {
 		t.Fatalf("expected one status update to failed, got %#v", repo.statusUpdates)
 	}
 }
+
+func TestCreateOrganizationInSharedDatabase(t *testing.T) {
+	repo := &fakeOrganizationRepo{}
+	provisioner := &fakeProvisioner{}
+	scheduler := commands.NewInlineProvisioningScheduler(commands.NewProvisionOrganizationCommand(repo, provisioner))
+	isolation := domain.IsolationPolicy{Mode: domain.IsolationSchema, SharedDatabase: "bowerbird_tenants"}
+	uc := NewCreateOrganizationUseCaseFromCommand(commands.NewCreateOrganizationCommand(repo, scheduler, isolation))
+
+	_, err := uc.Execute(context.Background(), CreateOrganizationCommand{
+		Name:       "Acme",
+		Slug:       "acme",
+		OwnerID:    "user-1",
+		OwnerEmail: "owner@acme.com",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if repo.createdOrg.IsolationMode != domain.IsolationSchema || repo.createdOrg.SharedDatabase != "bowerbird_tenants" {
+		t.Fatalf("expected the organization in the shared database, got %+v", repo.createdOrg)
+	}
+	want := domain.DatabaseLocation{DBName: "tenant_acme", SharedDatabase: "bowerbird_tenants"}
+	if len(provisioner.created) != 1 || provisioner.created[0] != want {
+		t.Fatalf("expected the schema to be provisioned at %+v, got %+v", want, provisioner.created)
+	}
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *domain.APIKey) error
+	ListByTenant(ctx context.Context, tenantID string) ([]*domain.APIKey, error)
+	// GetByID returns the key of an active tenant, including its slug.
+	GetByID(ctx context.Context, id string) (*domain.APIKey, error)
+	Revoke(ctx context.Context, tenantID, id string, at time.Time) error
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
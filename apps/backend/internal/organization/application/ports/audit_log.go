@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+// AuditLog reads the audit events of the organization in the context.
+type AuditLog interface {
+	List(ctx context.Context, filter audit.Filter) ([]audit.Entry, error)
+}
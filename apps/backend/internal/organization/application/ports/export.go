@@ -0,0 +1,57 @@
+package ports
+
+import (
+	"context"
+	"io"
+
+	"github.com/bowerbird/internal/organization/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type ExportRepository interface {
+	Create(ctx context.Context, export *domain.DataExport) error
+	// Get returns ErrExportNotFound unless the export belongs to the organization.
+	Get(ctx context.Context, organizationID, exportID string) (*domain.DataExport, error)
+	GetByID(ctx context.Context, exportID string) (*domain.DataExport, error)
+	ListByOrganization(ctx context.Context, organizationID string) ([]*domain.DataExport, error)
+	// Claim moves a pending export to running. It reports false when the
+	// export is no longer pending, so duplicate jobs do not run it twice.
+	Claim(ctx context.Context, exportID string) (bool, error)
+	Complete(ctx context.Context, exportID, filePath string, sizeBytes int64, sha256 string) error
+	Fail(ctx context.Context, exportID, cause string) error
+}
+
+// TenantDataReader streams every exportable table of an organization's
+// database.
+type TenantDataReader interface {
+	// ReadTenantData reads every dataset from one snapshot, parents before
+	// children, and passes each to visit as a cursor that is only valid during
+	// the call. It returns the tenant migration version the records follow.
+	ReadTenantData(ctx context.Context, location domain.DatabaseLocation, visit func(dataset string, rows ExportRows) error) (uint, error)
+}
+
+// ExportRows is a cursor over the records of one dataset.
+type ExportRows interface {
+	Next() bool
+	Record() domain.ExportRecord
+	Err() error
+}
+
+// ExportFileStore streams the files referenced by the exported records and
+// the bundle itself, and hands out the bundle.
+type ExportFileStore interface {
+	Exists(ctx context.Context, input platformStorage.ExistsFileInput) (bool, error)
+	OpenFile(ctx context.Context, input platformStorage.ReadFileInput) (io.ReadCloser, error)
+	UploadFile(ctx context.Context, input platformStorage.UploadFileInput) (*platformStorage.UploadFileResult, error)
+	PresignDownload(ctx context.Context, input platformStorage.PresignDownloadInput) (*platformStorage.PresignDownloadResult, error)
+}
+
+// ExportScheduler runs an export, usually asynchronously.
+type ExportScheduler interface {
+	ScheduleExport(ctx context.Context, organizationID, exportID string) error
+}
+
+// ExportNotifier tells the requester that their export can be downloaded.
+type ExportNotifier interface {
+	ExportReady(ctx context.Context, org *domain.Organization, export *domain.DataExport) error
+}
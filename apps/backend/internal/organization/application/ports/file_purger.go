@@ -0,0 +1,12 @@
+package ports
+
+import (
+	"context"
+
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+// FilePurger deletes an organization's stored files when it is purged.
+type FilePurger interface {
+	DeletePrefix(ctx context.Context, input platformStorage.DeletePrefixInput) (int, error)
+}
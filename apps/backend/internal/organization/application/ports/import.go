@@ -0,0 +1,56 @@
+package ports
+
+import (
+	"context"
+	"io"
+
+	"github.com/bowerbird/internal/organization/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+type ImportRepository interface {
+	Create(ctx context.Context, dataImport *domain.DataImport) error
+	// Get returns ErrImportNotFound unless the import belongs to the organization.
+	Get(ctx context.Context, organizationID, importID string) (*domain.DataImport, error)
+	GetByID(ctx context.Context, importID string) (*domain.DataImport, error)
+	ListByOrganization(ctx context.Context, organizationID string) ([]*domain.DataImport, error)
+	// MarkUploaded moves an import awaiting its upload to pending. It
+	// reports false when the import was already started.
+	MarkUploaded(ctx context.Context, importID string) (bool, error)
+	// Claim moves a pending import to running. It reports false when the
+	// import is no longer pending, so duplicate jobs do not run it twice.
+	Claim(ctx context.Context, importID string) (bool, error)
+	UpdateProgress(ctx context.Context, importID string, progress domain.ImportProgress) error
+	Complete(ctx context.Context, importID string, progress domain.ImportProgress) error
+	Fail(ctx context.Context, importID, cause string) error
+}
+
+// TenantDataWriter restores records into an organization's database.
+type TenantDataWriter interface {
+	BeginImport(ctx context.Context, location domain.DatabaseLocation) (TenantImport, error)
+}
+
+// TenantImport writes a whole import in one transaction, so a failed import
+// leaves the organization as it was.
+type TenantImport interface {
+	// SchemaVersion is the tenant migration version of the organization.
+	SchemaVersion(ctx context.Context) (uint, error)
+	TargetKeys(ctx context.Context) (*domain.ImportTargetKeys, error)
+	Insert(ctx context.Context, spec domain.ImportDatasetSpec, records []domain.ImportRecord) error
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// ImportFileStore receives the uploaded bundle and the files it carries. The
+// bundle is streamed, as it can be larger than the worker's memory.
+type ImportFileStore interface {
+	Exists(ctx context.Context, input platformStorage.ExistsFileInput) (bool, error)
+	OpenFile(ctx context.Context, input platformStorage.ReadFileInput) (io.ReadCloser, error)
+	WriteFileIfAbsent(ctx context.Context, input platformStorage.WriteFileIfAbsentInput) (*platformStorage.WriteFileIfAbsentResult, error)
+	PresignUpload(ctx context.Context, input platformStorage.PresignUploadInput) (*platformStorage.PresignUploadResult, error)
+}
+
+// ImportScheduler runs an import, usually asynchronously.
+type ImportScheduler interface {
+	ScheduleImport(ctx context.Context, organizationID, importID string) error
+}
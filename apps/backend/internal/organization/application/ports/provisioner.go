@@ -7,7 +7,8 @@ import (
 )
 
 type Provisioner interface {
-	CreateDatabase(ctx context.Context, dbName string) error
-	MigrateDatabase(ctx context.Context, dbName string) error
-	SeedOwner(ctx context.Context, dbName string, owner domain.OwnerData) error
+	CreateDatabase(ctx context.Context, location domain.DatabaseLocation) error
+	MigrateDatabase(ctx context.Context, location domain.DatabaseLocation) error
+	SeedOwner(ctx context.Context, location domain.DatabaseLocation, owner domain.OwnerData) error
+	DropDatabase(ctx context.Context, location domain.DatabaseLocation) error
 }
@@ -0,0 +1,9 @@
+package ports
+
+import "context"
+
+// ProvisioningScheduler runs an organization's provisioning, usually
+// asynchronously; its status is then polled from the organization.
+type ProvisioningScheduler interface {
+	ScheduleProvisioning(ctx context.Context, organizationID string) error
+}
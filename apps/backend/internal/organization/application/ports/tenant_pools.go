@@ -0,0 +1,7 @@
+package ports
+
+// TenantPools closes the cached connections to a tenant database, so an
+// organization taken offline stops being served by this process at once.
+type TenantPools interface {
+	Invalidate(dbName string)
+}
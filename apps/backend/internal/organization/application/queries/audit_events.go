@@ -0,0 +1,80 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/platform/audit"
+)
+
+const (
+	// AuditEventsMaxPageSize bounds a single listing page.
+	AuditEventsMaxPageSize = 200
+	// AuditEventsMaxExport bounds an export; narrower filters get the rest.
+	AuditEventsMaxExport = 10000
+)
+
+type AuditEventPage struct {
+	Events     []audit.Entry
+	NextCursor string
+}
+
+type ListAuditEventsQuery struct {
+	log ports.AuditLog
+}
+
+func NewListAuditEventsQuery(log ports.AuditLog) *ListAuditEventsQuery {
+	return &ListAuditEventsQuery{log: log}
+}
+
+// Execute returns one page, newest first. One extra event is read to know
+// whether another page follows.
+func (q *ListAuditEventsQuery) Execute(ctx context.Context, filter audit.Filter) (*AuditEventPage, error) {
+	limit := filter.Limit
+	if limit <= 0 || limit > AuditEventsMaxPageSize {
+		limit = AuditEventsMaxPageSize
+	}
+	filter.Limit = limit + 1
+
+	events, err := q.log.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AuditEventPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.NextCursor = page.Events[limit-1].ID
+	}
+	return page, nil
+}
+
+type ExportAuditEventsQuery struct {
+	log ports.AuditLog
+}
+
+func NewExportAuditEventsQuery(log ports.AuditLog) *ExportAuditEventsQuery {
+	return &ExportAuditEventsQuery{log: log}
+}
+
+// Execute returns every matching event up to AuditEventsMaxExport and
+// reports whether the export was cut short.
+func (q *ExportAuditEventsQuery) Execute(ctx context.Context, filter audit.Filter) ([]audit.Entry, bool, error) {
+	var events []audit.Entry
+	filter.Cursor = ""
+	for {
+		filter.Limit = min(AuditEventsMaxPageSize, AuditEventsMaxExport+1-len(events))
+		page, err := q.log.List(ctx, filter)
+		if err != nil {
+			return nil, false, err
+		}
+		events = append(events, page...)
+		if len(events) > AuditEventsMaxExport {
+			return events[:AuditEventsMaxExport], true, nil
+		}
+		if len(page) < filter.Limit {
+			return events, false, nil
+		}
+		filter.Cursor = page[len(page)-1].ID
+	}
+}
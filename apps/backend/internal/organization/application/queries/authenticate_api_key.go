@@ -0,0 +1,49 @@
+package queries
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+// AuthenticateAPIKeyQuery resolves a presented bbk_ token to its key and
+// records the use. Lookup failures are reported as ErrInvalidAPIKey so callers
+// cannot probe which key ids exist.
+type AuthenticateAPIKeyQuery struct {
+	repo ports.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewAuthenticateAPIKeyQuery(repo ports.APIKeyRepository) *AuthenticateAPIKeyQuery {
+	return &AuthenticateAPIKeyQuery{repo: repo, now: time.Now}
+}
+
+func (q *AuthenticateAPIKeyQuery) Execute(ctx context.Context, token string) (*domain.APIKey, error) {
+	keyID, secret, err := domain.ParseAPIKeyToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := q.repo.GetByID(ctx, keyID)
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	now := q.now().UTC()
+	if err := key.Verify(secret, now); err != nil {
+		return nil, err
+	}
+
+	if err := q.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+		slog.Warn("failed to record api key usage", "api_key_id", key.ID, "error", err)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,94 @@
+package queries
+
+import (
+	"context"
+	"time"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
+)
+
+// exportDownloadTTL keeps download links short-lived; the owner can ask for
+// a new one while the bundle exists.
+const exportDownloadTTL = 15 * time.Minute
+
+// requireOwner checks that the actor owns the organization. Exports and
+// imports carry every member's data, so they are not delegated through RBAC
+// permissions.
+func requireOwner(ctx context.Context, repo ports.OrganizationRepository, organizationID, actorID string) error {
+	org, err := repo.GetByID(ctx, organizationID, actorID)
+	if err != nil {
+		return err
+	}
+	if org.CurrentUserRole != domain.RoleOwner {
+		return domain.ErrNotOrganizationOwner
+	}
+
+	return nil
+}
+
+type ListDataExportsQuery struct {
+	repo    ports.OrganizationRepository
+	exports ports.ExportRepository
+}
+
+func NewListDataExportsQuery(repo ports.OrganizationRepository, exports ports.ExportRepository) *ListDataExportsQuery {
+	return &ListDataExportsQuery{repo: repo, exports: exports}
+}
+
+func (q *ListDataExportsQuery) Execute(ctx context.Context, organizationID, actorID string) ([]*domain.DataExport, error) {
+	if err := requireOwner(ctx, q.repo, organizationID, actorID); err != nil {
+		return nil, err
+	}
+
+	return q.exports.ListByOrganization(ctx, organizationID)
+}
+
+type GetDataExportQuery struct {
+	repo    ports.OrganizationRepository
+	exports ports.ExportRepository
+}
+
+func NewGetDataExportQuery(repo ports.OrganizationRepository, exports ports.ExportRepository) *GetDataExportQuery {
+	return &GetDataExportQuery{repo: repo, exports: exports}
+}
+
+// Execute returns the export so its status can be polled.
+func (q *GetDataExportQuery) Execute(ctx context.Context, organizationID, exportID, actorID string) (*domain.DataExport, error) {
+	if err := requireOwner(ctx, q.repo, organizationID, actorID); err != nil {
+		return nil, err
+	}
+
+	return q.exports.Get(ctx, organizationID, exportID)
+}
+
+type DownloadDataExportQuery struct {
+	repo    ports.OrganizationRepository
+	exports ports.ExportRepository
+	files   ports.ExportFileStore
+}
+
+func NewDownloadDataExportQuery(repo ports.OrganizationRepository, exports ports.ExportRepository, files ports.ExportFileStore) *DownloadDataExportQuery {
+	return &DownloadDataExportQuery{repo: repo, exports: exports, files: files}
+}
+
+// Execute returns a short-lived link to a completed export bundle.
+func (q *DownloadDataExportQuery) Execute(ctx context.Context, organizationID, exportID, actorID string) (*platformStorage.PresignDownloadResult, error) {
+	if err := requireOwner(ctx, q.repo, organizationID, actorID); err != nil {
+		return nil, err
+	}
+
+	export, err := q.exports.Get(ctx, organizationID, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if export.Status != domain.ExportStatusCompleted {
+		return nil, domain.ErrExportNotReady
+	}
+
+	return q.files.PresignDownload(ctx, platformStorage.PresignDownloadInput{
+		Path:      export.FilePath,
+		ExpiresIn: exportDownloadTTL,
+	})
+}
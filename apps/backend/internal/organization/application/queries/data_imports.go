@@ -0,0 +1,43 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type ListDataImportsQuery struct {
+	repo    ports.OrganizationRepository
+	imports ports.ImportRepository
+}
+
+func NewListDataImportsQuery(repo ports.OrganizationRepository, imports ports.ImportRepository) *ListDataImportsQuery {
+	return &ListDataImportsQuery{repo: repo, imports: imports}
+}
+
+func (q *ListDataImportsQuery) Execute(ctx context.Context, organizationID, actorID string) ([]*domain.DataImport, error) {
+	if err := requireOwner(ctx, q.repo, organizationID, actorID); err != nil {
+		return nil, err
+	}
+
+	return q.imports.ListByOrganization(ctx, organizationID)
+}
+
+type GetDataImportQuery struct {
+	repo    ports.OrganizationRepository
+	imports ports.ImportRepository
+}
+
+func NewGetDataImportQuery(repo ports.OrganizationRepository, imports ports.ImportRepository) *GetDataImportQuery {
+	return &GetDataImportQuery{repo: repo, imports: imports}
+}
+
+// Execute returns the import with its progress so it can be polled.
+func (q *GetDataImportQuery) Execute(ctx context.Context, organizationID, importID, actorID string) (*domain.DataImport, error) {
+	if err := requireOwner(ctx, q.repo, organizationID, actorID); err != nil {
+		return nil, err
+	}
+
+	return q.imports.Get(ctx, organizationID, importID)
+}
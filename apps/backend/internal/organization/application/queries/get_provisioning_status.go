@@ -0,0 +1,30 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type GetProvisioningStatusQuery struct {
+	repo ports.OrganizationRepository
+}
+
+func NewGetProvisioningStatusQuery(repo ports.OrganizationRepository) *GetProvisioningStatusQuery {
+	return &GetProvisioningStatusQuery{repo: repo}
+}
+
+// Execute returns the organization for provisioning status polling. Only the
+// user who requested it and its members can see it.
+func (q *GetProvisioningStatusQuery) Execute(ctx context.Context, id, userID string) (*domain.Organization, error) {
+	org, err := q.repo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !org.IsProvisioningOwner(userID) && org.CurrentUserRole == "" {
+		return nil, domain.ErrOrganizationNotFound
+	}
+
+	return org, nil
+}
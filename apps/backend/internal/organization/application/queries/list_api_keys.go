@@ -0,0 +1,20 @@
+package queries
+
+import (
+	"context"
+
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/domain"
+)
+
+type ListAPIKeysQuery struct {
+	repo ports.APIKeyRepository
+}
+
+func NewListAPIKeysQuery(repo ports.APIKeyRepository) *ListAPIKeysQuery {
+	return &ListAPIKeysQuery{repo: repo}
+}
+
+func (q *ListAPIKeysQuery) Execute(ctx context.Context, tenantID string) ([]*domain.APIKey, error) {
+	return q.repo.ListByTenant(ctx, tenantID)
+}
@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	OrganizationExportRequestedType = "OrganizationExportRequested"
+)
+
+// OrganizationExportRequested builds a data export bundle. Duplicate
+// deliveries are harmless: exports that are no longer pending are skipped.
+type OrganizationExportRequested struct {
+	OrganizationID string `json:"organization_id"`
+	ExportID       string `json:"export_id"`
+	RequestedAt    string `json:"requested_at"`
+}
+
+func (j OrganizationExportRequested) Validate() error {
+	if j.OrganizationID == "" {
+		return errors.New("organization_id is required")
+	}
+	if j.ExportID == "" {
+		return errors.New("export_id is required")
+	}
+
+	return nil
+}
+
+func MarshalOrganizationExportRequested(job OrganizationExportRequested) ([]byte, error) {
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(job)
+}
+
+func UnmarshalOrganizationExportRequested(data []byte) (OrganizationExportRequested, error) {
+	var job OrganizationExportRequested
+	if err := json.Unmarshal(data, &job); err != nil {
+		return OrganizationExportRequested{}, err
+	}
+
+	if err := job.Validate(); err != nil {
+		return OrganizationExportRequested{}, err
+	}
+
+	return job, nil
+}
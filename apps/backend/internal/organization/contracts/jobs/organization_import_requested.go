@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	OrganizationImportRequestedType = "OrganizationImportRequested"
+)
+
+// OrganizationImportRequested restores an uploaded export bundle. Duplicate
+// deliveries are harmless: imports that are no longer pending are skipped.
+type OrganizationImportRequested struct {
+	OrganizationID string `json:"organization_id"`
+	ImportID       string `json:"import_id"`
+	RequestedAt    string `json:"requested_at"`
+}
+
+func (j OrganizationImportRequested) Validate() error {
+	if j.OrganizationID == "" {
+		return errors.New("organization_id is required")
+	}
+	if j.ImportID == "" {
+		return errors.New("import_id is required")
+	}
+
+	return nil
+}
+
+func MarshalOrganizationImportRequested(job OrganizationImportRequested) ([]byte, error) {
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(job)
+}
+
+func UnmarshalOrganizationImportRequested(data []byte) (OrganizationImportRequested, error) {
+	var job OrganizationImportRequested
+	if err := json.Unmarshal(data, &job); err != nil {
+		return OrganizationImportRequested{}, err
+	}
+
+	if err := job.Validate(); err != nil {
+		return OrganizationImportRequested{}, err
+	}
+
+	return job, nil
+}
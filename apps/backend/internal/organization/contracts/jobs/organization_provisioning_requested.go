@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	OrganizationProvisioningRequestedType = "OrganizationProvisioningRequested"
+)
+
+// OrganizationProvisioningRequested runs the organization's pending
+// provisioning steps. Duplicate deliveries are harmless: steps are idempotent
+// and organizations that are no longer provisioning are skipped.
+type OrganizationProvisioningRequested struct {
+	OrganizationID string `json:"organization_id"`
+	RequestedAt    string `json:"requested_at"`
+}
+
+func (j OrganizationProvisioningRequested) Validate() error {
+	if j.OrganizationID == "" {
+		return errors.New("organization_id is required")
+	}
+
+	return nil
+}
+
+func MarshalOrganizationProvisioningRequested(job OrganizationProvisioningRequested) ([]byte, error) {
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(job)
+}
+
+func UnmarshalOrganizationProvisioningRequested(data []byte) (OrganizationProvisioningRequested, error) {
+	var job OrganizationProvisioningRequested
+	if err := json.Unmarshal(data, &job); err != nil {
+		return OrganizationProvisioningRequested{}, err
+	}
+
+	if err := job.Validate(); err != nil {
+		return OrganizationProvisioningRequested{}, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound      = errors.New("api key not found")
+	ErrInvalidAPIKey       = errors.New("invalid api key")
+	ErrAPIKeyExpired       = errors.New("api key has expired")
+	ErrAPIKeyRevoked       = errors.New("api key has been revoked")
+	ErrAPIKeyCreatorLeft   = errors.New("api key creator is no longer a member")
+	ErrInvalidAPIKeyScope  = errors.New("api key scope is not granted to the creator")
+	ErrInvalidAPIKeyName   = errors.New("api key name is required")
+	ErrInvalidAPIKeyExpiry = errors.New("api key expiry must be in the future")
+)
+
+// APIKeyTokenPrefix marks machine credentials so the auth middleware can tell
+// them apart from JWTs without trying to parse them.
+const APIKeyTokenPrefix = "bbk_"
+
+const apiKeySecretBytes = 32
+
+// APIKey is an organization-scoped machine credential. Only the SHA-256 of the
+// secret is stored; the full token is returned once, at creation.
+type APIKey struct {
+	ID         string
+	TenantID   string
+	TenantSlug string
+	Name       string
+	SecretHash []byte
+	Scopes     []string
+	CreatedBy  string
+	// CreatorActive reports whether the creator still belongs to the
+	// organization. The key acts as them, so it stops working once they leave.
+	CreatorActive bool
+	ExpiresAt     *time.Time
+	LastUsedAt    *time.Time
+	RevokedAt     *time.Time
+	CreatedAt     time.Time
+}
+
+// NewAPIKey builds a key and its plaintext token. The token has the form
+// bbk_<id>_<secret> so it can be looked up by id and compared by hash.
+func NewAPIKey(id, tenantID, name, createdBy string, scopes []string, expiresAt *time.Time, now time.Time) (*APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrInvalidAPIKeyName
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", ErrInvalidAPIKeyExpiry
+	}
+
+	raw := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("generate api key secret: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+
+	key := &APIKey{
+		ID:            id,
+		TenantID:      tenantID,
+		Name:          name,
+		SecretHash:    hashAPIKeySecret(secret),
+		Scopes:        scopes,
+		CreatedBy:     createdBy,
+		CreatorActive: true,
+		ExpiresAt:     expiresAt,
+		CreatedAt:     now,
+	}
+
+	return key, APIKeyTokenPrefix + id + "_" + secret, nil
+}
+
+// ParseAPIKeyToken splits a bbk_<id>_<secret> token.
+func ParseAPIKeyToken(token string) (id, secret string, err error) {
+	rest, ok := strings.CutPrefix(token, APIKeyTokenPrefix)
+	if !ok {
+		return "", "", ErrInvalidAPIKey
+	}
+
+	id, secret, ok = strings.Cut(rest, "_")
+	if !ok || id == "" || secret == "" {
+		return "", "", ErrInvalidAPIKey
+	}
+
+	return id, secret, nil
+}
+
+// DisplayPrefix identifies the key in listings without revealing the secret.
+func (k *APIKey) DisplayPrefix() string {
+	return APIKeyTokenPrefix + k.ID
+}
+
+// Verify checks the secret and that the key is still usable at now.
+func (k *APIKey) Verify(secret string, now time.Time) error {
+	if subtle.ConstantTimeCompare(k.SecretHash, hashAPIKeySecret(secret)) != 1 {
+		return ErrInvalidAPIKey
+	}
+	if k.RevokedAt != nil {
+		return ErrAPIKeyRevoked
+	}
+	if !k.CreatorActive {
+		return ErrAPIKeyCreatorLeft
+	}
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return ErrAPIKeyExpired
+	}
+
+	return nil
+}
+
+// ValidateAPIKeyScopes ensures every requested scope is one the creator holds,
+// so a key can never grant more than the person who minted it.
+func ValidateAPIKeyScopes(requested, granted []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyScope)
+	}
+
+	allowed := make(map[string]struct{}, len(granted))
+	for _, code := range granted {
+		allowed[code] = struct{}{}
+	}
+
+	seen := make(map[string]struct{}, len(requested))
+	scopes := make([]string, 0, len(requested))
+	for _, code := range requested {
+		code = strings.TrimSpace(code)
+		if _, ok := allowed[code]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAPIKeyScope, code)
+		}
+		if _, dup := seen[code]; dup {
+			continue
+		}
+		seen[code] = struct{}{}
+		scopes = append(scopes, code)
+	}
+
+	return scopes, nil
+}
+
+func hashAPIKeySecret(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewAPIKeyTokenVerifiesAgainstStoredHash(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	key, token, err := NewAPIKey("01JW58TAT9M0N4R8M1P3Q6R9Y0", "tenant-1", " ERP sync ", "user-1", []string{"users:read"}, nil, now)
+	if err != nil {
+		t.Fatalf("new api key failed: %v", err)
+	}
+
+	if key.Name != "ERP sync" {
+		t.Fatalf("expected trimmed name, got %q", key.Name)
+	}
+	if !strings.HasPrefix(token, key.DisplayPrefix()+"_") {
+		t.Fatalf("expected token to start with %q, got %q", key.DisplayPrefix(), token)
+	}
+
+	id, secret, err := ParseAPIKeyToken(token)
+	if err != nil {
+		t.Fatalf("parse token failed: %v", err)
+	}
+	if id != key.ID {
+		t.Fatalf("expected id %q, got %q", key.ID, id)
+	}
+	if strings.Contains(string(key.SecretHash), secret) {
+		t.Fatal("secret must not be stored in clear")
+	}
+	if err := key.Verify(secret, now); err != nil {
+		t.Fatalf("expected secret to verify, got %v", err)
+	}
+	if err := key.Verify(secret+"x", now); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expected ErrInvalidAPIKey for wrong secret, got %v", err)
+	}
+}
+
+func TestAPIKeyVerifyRejectsExpiredAndRevokedKeys(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(time.Hour)
+	key, token, err := NewAPIKey("01JW58TAT9M0N4R8M1P3Q6R9Y0", "tenant-1", "ERP", "user-1", []string{"users:read"}, &expiresAt, now)
+	if err != nil {
+		t.Fatalf("new api key failed: %v", err)
+	}
+	_, secret, _ := ParseAPIKeyToken(token)
+
+	if err := key.Verify(secret, expiresAt); !errors.Is(err, ErrAPIKeyExpired) {
+		t.Fatalf("expected ErrAPIKeyExpired, got %v", err)
+	}
+
+	revokedAt := now.Add(time.Minute)
+	key.RevokedAt = &revokedAt
+	if err := key.Verify(secret, now.Add(2*time.Minute)); !errors.Is(err, ErrAPIKeyRevoked) {
+		t.Fatalf("expected ErrAPIKeyRevoked, got %v", err)
+	}
+}
+
+func TestAPIKeyVerifyRejectsKeysOfFormerMembers(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	key, token, err := NewAPIKey("01JW58TAT9M0N4R8M1P3Q6R9Y0", "tenant-1", "ERP", "user-1", []string{"users:read"}, nil, now)
+	if err != nil {
+		t.Fatalf("new api key failed: %v", err)
+	}
+	_, secret, _ := ParseAPIKeyToken(token)
+
+	key.CreatorActive = false
+	if err := key.Verify(secret, now); !errors.Is(err, ErrAPIKeyCreatorLeft) {
+		t.Fatalf("expected ErrAPIKeyCreatorLeft, got %v", err)
+	}
+}
+
+func TestNewAPIKeyRejectsPastExpiry(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+
+	if _, _, err := NewAPIKey("id", "tenant-1", "ERP", "user-1", []string{"users:read"}, &past, now); !errors.Is(err, ErrInvalidAPIKeyExpiry) {
+		t.Fatalf("expected ErrInvalidAPIKeyExpiry, got %v", err)
+	}
+}
+
+func TestParseAPIKeyTokenRejectsMalformedTokens(t *testing.T) {
+	for _, token := range []string{"", "eyJhbGciOi.jwt", "bbk_", "bbk_onlyid", "bbk__secret"} {
+		if _, _, err := ParseAPIKeyToken(token); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Fatalf("expected ErrInvalidAPIKey for %q, got %v", token, err)
+		}
+	}
+}
+
+func TestValidateAPIKeyScopesMustBeSubsetOfCreatorPermissions(t *testing.T) {
+	granted := []string{"users:read", "settings:write"}
+
+	scopes, err := ValidateAPIKeyScopes([]string{"users:read", "users:read"}, granted)
+	if err != nil {
+		t.Fatalf("expected valid scopes, got %v", err)
+	}
+	if len(scopes) != 1 || scopes[0] != "users:read" {
+		t.Fatalf("expected deduplicated scopes, got %v", scopes)
+	}
+
+	if _, err := ValidateAPIKeyScopes([]string{"roles:write"}, granted); !errors.Is(err, ErrInvalidAPIKeyScope) {
+		t.Fatalf("expected ErrInvalidAPIKeyScope, got %v", err)
+	}
+	if _, err := ValidateAPIKeyScopes(nil, granted); !errors.Is(err, ErrInvalidAPIKeyScope) {
+		t.Fatalf("expected ErrInvalidAPIKeyScope for empty scopes, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrExportNotFound   = errors.New("data export not found")
+	ErrExportInProgress = errors.New("a data export is already in progress")
+	ErrExportNotReady   = errors.New("data export is not ready")
+)
+
+const (
+	ExportStatusPending   = "pending"
+	ExportStatusRunning   = "running"
+	ExportStatusCompleted = "completed"
+	ExportStatusFailed    = "failed"
+)
+
+// ActionDataExportRequested is the audit action of a requested data export.
+const ActionDataExportRequested = "organization.data_export_requested"
+
+// ExportFormatVersion changes whenever the bundle layout changes, so an
+// import can tell which layout it is reading.
+const ExportFormatVersion = 1
+
+// ExportStaleAfter is how long an export can stay pending or running before
+// it is considered lost, e.g. because its worker crashed, and stops blocking
+// new requests.
+const ExportStaleAfter = time.Hour
+
+// ExportManifestPath is where the manifest sits inside the bundle.
+const ExportManifestPath = "manifest.json"
+
+// exportableStatuses lists the statuses whose data can be exported. Pending
+// deletion is included so a leaving customer can take their data during the
+// grace period.
+var exportableStatuses = []string{StatusActive, StatusSuspended, StatusArchived, StatusPendingDeletion}
+
+// CanExport reports whether the organization's data can be exported.
+func (o *Organization) CanExport() error {
+	if !slices.Contains(exportableStatuses, o.Status) {
+		return ErrInvalidStatusTransition
+	}
+	return nil
+}
+
+// DataExport is a portability bundle of everything an organization holds.
+type DataExport struct {
+	ID             string
+	OrganizationID string
+	RequestedBy    string
+	// RequestedByEmail is where the ready notification goes.
+	RequestedByEmail string
+	Status           string
+	FilePath         string
+	SizeBytes        int64
+	SHA256           string
+	Error            string
+	CreatedAt        time.Time
+	StartedAt        *time.Time
+	CompletedAt      *time.Time
+}
+
+// InProgress reports whether the export still has to run or is running.
+func (e *DataExport) InProgress(now time.Time) bool {
+	if e.Status != ExportStatusPending && e.Status != ExportStatusRunning {
+		return false
+	}
+	return now.Sub(e.CreatedAt) < ExportStaleAfter
+}
+
+// ExportPath keeps bundles under the organization's tenant prefix, so they
+// are removed when the organization is purged.
+func ExportPath(organizationID, exportID string) string {
+	return "tenant/" + organizationID + "/exports/" + exportID + ".zip"
+}
+
+// ExportRecord is one exported row as a JSON object and the stored files it
+// references.
+type ExportRecord struct {
+	Data     json.RawMessage
+	FileKeys []string
+}
+
+// ExportableFileKey reports whether a referenced file may be copied into the
+// organization's bundle: it must lie under one of the organization's storage
+// prefixes and have no dot segments that a client could resolve elsewhere.
+func ExportableFileKey(key string, prefixes []string) bool {
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return slices.ContainsFunc(prefixes, func(prefix string) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+// ExportManifest describes the bundle and lets its contents be verified.
+type ExportManifest struct {
+	FormatVersion    int                   `json:"format_version"`
+	ExportID         string                `json:"export_id"`
+	OrganizationID   string                `json:"organization_id"`
+	OrganizationSlug string                `json:"organization_slug"`
+	OrganizationName string                `json:"organization_name"`
+	SchemaVersion    uint                  `json:"schema_version"`
+	GeneratedAt      time.Time             `json:"generated_at"`
+	Entries          []ExportManifestEntry `json:"entries"`
+	// MissingFiles are referenced by a record but are not in the bundle:
+	// they were no longer stored, or lay outside the organization's prefixes.
+	MissingFiles []string `json:"missing_files"`
+}
+
+const (
+	ExportEntryDataset = "dataset"
+	ExportEntryFile    = "file"
+)
+
+// ExportManifestEntry is one file of the bundle. Datasets are JSON Lines
+// files with one record per line; files keep their storage key as Source.
+type ExportManifestEntry struct {
+	Path      string `json:"path"`
+	Kind      string `json:"kind"`
+	Dataset   string `json:"dataset,omitempty"`
+	Records   int    `json:"records,omitempty"`
+	Source    string `json:"source,omitempty"`
+	SizeBytes int64  `json:"size_bytes"`
+	SHA256    string `json:"sha256"`
+}
+
+// DatasetPath is the bundle path of a dataset.
+func DatasetPath(name string) string {
+	return "data/" + name + ".jsonl"
+}
+
+// ExportFilePath is the bundle path of a stored file.
+func ExportFilePath(key string) string {
+	return "files/" + key
+}
@@ -0,0 +1,310 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrImportNotFound          = errors.New("data import not found")
+	ErrImportInProgress        = errors.New("a data import is already in progress")
+	ErrImportNotAwaitingUpload = errors.New("data import has already been started")
+	ErrImportBundleMissing     = errors.New("the export bundle has not been uploaded")
+	ErrInvalidImportBundle     = errors.New("invalid export bundle")
+	ErrImportSchemaTooNew      = errors.New("the export bundle was made with a newer schema")
+	ErrImportTargetNotEmpty    = errors.New("the organization already holds data")
+)
+
+const (
+	ImportStatusAwaitingUpload = "awaiting_upload"
+	ImportStatusPending        = "pending"
+	ImportStatusRunning        = "running"
+	ImportStatusCompleted      = "completed"
+	ImportStatusFailed         = "failed"
+)
+
+// Import steps, in the order they run.
+const (
+	ImportStepValidating       = "validating"
+	ImportStepRestoringFiles   = "restoring_files"
+	ImportStepRestoringRecords = "restoring_records"
+)
+
+// ActionDataImportStarted is the audit action of a started data import.
+const ActionDataImportStarted = "organization.data_import_started"
+
+// ImportStaleAfter is how long an import can stay pending or running before
+// it stops blocking new ones.
+const ImportStaleAfter = time.Hour
+
+// CanImport reports whether an export bundle can be restored into the
+// organization. Only active organizations take imports.
+func (o *Organization) CanImport() error {
+	if o.Status != StatusActive {
+		return ErrInvalidStatusTransition
+	}
+	return nil
+}
+
+// ImportProgress is updated while an import runs so its owner can follow it.
+type ImportProgress struct {
+	Step                 string
+	SourceOrganizationID string
+	RecordsTotal         int
+	RecordsImported      int
+	FilesTotal           int
+	FilesImported        int
+	// RemappedIDs counts records that received a new ID because theirs was
+	// already taken in the organization.
+	RemappedIDs int
+}
+
+// DataImport restores an export bundle into an organization.
+type DataImport struct {
+	ID             string
+	OrganizationID string
+	RequestedBy    string
+	Status         string
+	// FilePath is where the owner uploads the bundle.
+	FilePath    string
+	Progress    ImportProgress
+	Error       string
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	CompletedAt *time.Time
+}
+
+// InProgress reports whether the import still has to run or is running.
+func (i *DataImport) InProgress(now time.Time) bool {
+	if i.Status != ImportStatusPending && i.Status != ImportStatusRunning {
+		return false
+	}
+	return now.Sub(i.CreatedAt) < ImportStaleAfter
+}
+
+// ImportPath keeps uploaded bundles under the organization's tenant prefix.
+func ImportPath(organizationID, importID string) string {
+	return "tenant/" + organizationID + "/imports/" + importID + ".zip"
+}
+
+// ImportFilePrefix is the storage prefix every restored file lands under.
+func ImportFilePrefix(targetRef string) string {
+	return "tenant/" + targetRef + "/"
+}
+
+// ImportFileKey places a file of the bundle under the target organization's
+// prefix. Keys of the source tenant keep their layout after the tenant
+// segment; any other key is kept whole under "imported/". Keys with empty,
+// "." or ".." segments are rejected, as storage clients may resolve them to
+// a path outside the prefix.
+func ImportFileKey(targetRef, sourceKey string) (string, error) {
+	for _, segment := range strings.Split(sourceKey, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", fmt.Errorf("%w: unsafe file key %q", ErrInvalidImportBundle, sourceKey)
+		}
+	}
+
+	if rest, ok := strings.CutPrefix(sourceKey, "tenant/"); ok {
+		if _, path, found := strings.Cut(rest, "/"); found {
+			return ImportFilePrefix(targetRef) + path, nil
+		}
+	}
+	return ImportFilePrefix(targetRef) + "imported/" + sourceKey, nil
+}
+
+// ImportDatasetSpec describes how the records of one dataset are restored.
+type ImportDatasetSpec struct {
+	Name string
+	// IDColumn holds the record's own ID; pivot tables have none.
+	IDColumn string
+	// NaturalKey matches records that already exist in the organization,
+	// such as the owner or the seeded roles. Matched records are not
+	// inserted; references to them point to the existing row.
+	NaturalKey string
+	// References maps columns to the dataset whose IDs they hold.
+	References map[string]string
+	// FileColumns hold storage keys of files in the bundle.
+	FileColumns []string
+	// Overrides replace exported values, e.g. to restore fields an export
+	// leaves out on purpose.
+	Overrides map[string]json.RawMessage
+	// Seeded datasets are filled when the organization is provisioned.
+	// Every other dataset must be empty before an import.
+	Seeded bool
+	// Pivot rows that already exist are skipped.
+	Pivot bool
+}
+
+// ImportDatasets lists the datasets an import restores, parents before
+// children.
+var ImportDatasets = []ImportDatasetSpec{
+	{Name: "users", IDColumn: "id", NaturalKey: "email", Seeded: true},
+	{Name: "permissions", IDColumn: "id", NaturalKey: "code", Seeded: true},
+	{Name: "roles", IDColumn: "id", NaturalKey: "name", Seeded: true},
+	{Name: "role_permissions", References: map[string]string{"role_id": "roles", "permission_id": "permissions"}, Seeded: true, Pivot: true},
+	{Name: "user_roles", References: map[string]string{"user_id": "users", "role_id": "roles"}, Seeded: true, Pivot: true},
+	{
+		Name:       "connections",
+		IDColumn:   "id",
+		References: map[string]string{"owner_user_id": "users"},
+		// Credentials never leave an export, so restored connections must
+		// be reconnected before they sync again.
+		Overrides: map[string]json.RawMessage{
+			"encrypted_credentials": json.RawMessage(`"\\x"`),
+			"status":                json.RawMessage(`"requires_reconnect"`),
+		},
+	},
+	{Name: "inbox_sync_cursors", References: map[string]string{"connection_id": "connections"}},
+	{Name: "email_messages", IDColumn: "id", References: map[string]string{"account_id": "connections"}},
+	{Name: "email_attachments", IDColumn: "id", References: map[string]string{"message_id": "email_messages"}, FileColumns: []string{"s3_key"}},
+	{Name: "invoice_headers", IDColumn: "id", References: map[string]string{"source_message_id": "email_messages"}, FileColumns: []string{"document_ref_s3_key"}},
+	{Name: "invoice_lines", IDColumn: "id", References: map[string]string{"invoice_header_id": "invoice_headers"}},
+}
+
+// ImportRecord is one exported row, column by column.
+type ImportRecord map[string]json.RawMessage
+
+// text returns the column as a string; null and missing columns are empty.
+func (r ImportRecord) text(column string) (string, error) {
+	raw, ok := r[column]
+	if !ok || string(raw) == "null" {
+		return "", nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("%w: column %s is not text", ErrInvalidImportBundle, column)
+	}
+	return value, nil
+}
+
+func (r ImportRecord) setText(column, value string) {
+	encoded, _ := json.Marshal(value)
+	r[column] = encoded
+}
+
+// ImportTargetKeys describes the rows the organization already holds.
+type ImportTargetKeys struct {
+	// IDs lists the taken IDs per dataset.
+	IDs map[string]map[string]bool
+	// Natural maps natural key values to IDs per dataset.
+	Natural map[string]map[string]string
+}
+
+// EnsureEmpty rejects organizations that hold more than provisioning seeds,
+// so an import never merges into existing business data.
+func (k *ImportTargetKeys) EnsureEmpty() error {
+	for _, spec := range ImportDatasets {
+		if !spec.Seeded && len(k.IDs[spec.Name]) > 0 {
+			return ErrImportTargetNotEmpty
+		}
+	}
+	return nil
+}
+
+// ImportRemapper rewrites IDs, references and file keys of imported records
+// so they fit the target organization.
+type ImportRemapper struct {
+	target   *ImportTargetKeys
+	ids      map[string]map[string]string
+	files    map[string]string
+	prefix   string
+	newID    func() string
+	remapped int
+}
+
+// NewImportRemapper builds a remapper. files maps the storage keys of the
+// bundle's files to their new keys, which must all start with filePrefix;
+// newID generates replacement IDs.
+func NewImportRemapper(target *ImportTargetKeys, files map[string]string, filePrefix string, newID func() string) *ImportRemapper {
+	if target.IDs == nil {
+		target.IDs = map[string]map[string]bool{}
+	}
+	return &ImportRemapper{target: target, ids: map[string]map[string]string{}, files: files, prefix: filePrefix, newID: newID}
+}
+
+// Remapped returns how many records received a new ID.
+func (m *ImportRemapper) Remapped() int {
+	return m.remapped
+}
+
+// Remap rewrites the record in place. It reports false when the record
+// matches an existing row and must not be inserted.
+func (m *ImportRemapper) Remap(spec ImportDatasetSpec, record ImportRecord) (bool, error) {
+	for column, dataset := range spec.References {
+		value, err := record.text(column)
+		if err != nil {
+			return false, err
+		}
+		// Unknown references are kept; foreign keys reject the broken ones.
+		if mapped, ok := m.ids[dataset][value]; ok && value != "" {
+			record.setText(column, mapped)
+		}
+	}
+
+	for _, column := range spec.FileColumns {
+		key, err := record.text(column)
+		if err != nil {
+			return false, err
+		}
+		if key == "" {
+			continue
+		}
+		// A key that is not in the bundle would point the record at a file
+		// the import never wrote, possibly another organization's.
+		mapped, ok := m.files[key]
+		if !ok {
+			return false, fmt.Errorf("%w: %s.%s references %q, which is not in the bundle", ErrInvalidImportBundle, spec.Name, column, key)
+		}
+		if !strings.HasPrefix(mapped, m.prefix) {
+			return false, fmt.Errorf("%w: %s.%s maps outside the organization's files", ErrInvalidImportBundle, spec.Name, column)
+		}
+		record.setText(column, mapped)
+	}
+
+	for column, value := range spec.Overrides {
+		record[column] = value
+	}
+
+	if spec.IDColumn == "" {
+		return true, nil
+	}
+
+	oldID, err := record.text(spec.IDColumn)
+	if err != nil {
+		return false, err
+	}
+	if oldID == "" {
+		return false, fmt.Errorf("%w: %s record without %s", ErrInvalidImportBundle, spec.Name, spec.IDColumn)
+	}
+	if m.ids[spec.Name] == nil {
+		m.ids[spec.Name] = map[string]string{}
+	}
+
+	if spec.NaturalKey != "" {
+		natural, err := record.text(spec.NaturalKey)
+		if err != nil {
+			return false, err
+		}
+		if existing, ok := m.target.Natural[spec.Name][natural]; ok {
+			m.ids[spec.Name][oldID] = existing
+			return false, nil
+		}
+	}
+
+	newID := oldID
+	if m.target.IDs[spec.Name][oldID] {
+		newID = m.newID()
+		m.remapped++
+		record.setText(spec.IDColumn, newID)
+	}
+	m.ids[spec.Name][oldID] = newID
+	if m.target.IDs[spec.Name] == nil {
+		m.target.IDs[spec.Name] = map[string]bool{}
+	}
+	m.target.IDs[spec.Name][newID] = true
+
+	return true, nil
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func datasetSpec(t *testing.T, name string) ImportDatasetSpec {
+	t.Helper()
+	for _, spec := range ImportDatasets {
+		if spec.Name == name {
+			return spec
+		}
+	}
+	t.Fatalf("unknown dataset %s", name)
+	return ImportDatasetSpec{}
+}
+
+func decodeRecord(t *testing.T, raw string) ImportRecord {
+	t.Helper()
+	var record ImportRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		t.Fatalf("invalid record: %v", err)
+	}
+	return record
+}
+
+func TestImportFileKeyMovesFilesToTargetPrefix(t *testing.T) {
+	cases := map[string]string{
+		"tenant/acme-staging/inbox/msg/a.pdf": "tenant/acme/inbox/msg/a.pdf",
+		"uploads/a.pdf":                       "tenant/acme/imported/uploads/a.pdf",
+		"tenant/a.pdf":                        "tenant/acme/imported/tenant/a.pdf",
+	}
+	for source, want := range cases {
+		if got, err := ImportFileKey("acme", source); err != nil || got != want {
+			t.Fatalf("ImportFileKey(%q) = %q, %v, want %q", source, got, err, want)
+		}
+	}
+
+	for _, source := range []string{"tenant/acme-staging/../other/a.pdf", "/etc/a.pdf", "tenant//a.pdf", ""} {
+		if _, err := ImportFileKey("acme", source); !errors.Is(err, ErrInvalidImportBundle) {
+			t.Fatalf("expected ImportFileKey(%q) to be rejected, got %v", source, err)
+		}
+	}
+}
+
+func TestImportRemapperMatchesNaturalKeysAndRemapsCollisions(t *testing.T) {
+	target := &ImportTargetKeys{
+		IDs: map[string]map[string]bool{
+			"users":          {"OWNER": true},
+			"email_messages": {},
+		},
+		Natural: map[string]map[string]string{"users": {"owner@acme.test": "OWNER"}},
+	}
+	files := map[string]string{"tenant/old/inbox/a.pdf": "tenant/acme/inbox/a.pdf"}
+	remapper := NewImportRemapper(target, files, ImportFilePrefix("acme"), func() string { return "NEW" })
+
+	owner := decodeRecord(t, `{"id":"SRC-OWNER","email":"owner@acme.test"}`)
+	if insert, err := remapper.Remap(datasetSpec(t, "users"), owner); err != nil || insert {
+		t.Fatalf("expected the existing owner to be reused, got insert=%v err=%v", insert, err)
+	}
+
+	colliding := decodeRecord(t, `{"id":"OWNER","email":"other@acme.test"}`)
+	if insert, err := remapper.Remap(datasetSpec(t, "users"), colliding); err != nil || !insert {
+		t.Fatalf("expected the colliding user to be inserted, got insert=%v err=%v", insert, err)
+	}
+	if string(colliding["id"]) != `"NEW"` || remapper.Remapped() != 1 {
+		t.Fatalf("expected a new ID for the colliding user, got %s", colliding["id"])
+	}
+
+	connection := decodeRecord(t, `{"id":"CONN","owner_user_id":"SRC-OWNER","status":"active"}`)
+	if _, err := remapper.Remap(datasetSpec(t, "connections"), connection); err != nil {
+		t.Fatalf("remap connection: %v", err)
+	}
+	if string(connection["owner_user_id"]) != `"OWNER"` || string(connection["status"]) != `"requires_reconnect"` {
+		t.Fatalf("expected reference to the owner and a reconnect status, got %v", connection)
+	}
+
+	attachment := decodeRecord(t, `{"id":"ATT","message_id":"MSG","s3_key":"tenant/old/inbox/a.pdf"}`)
+	if _, err := remapper.Remap(datasetSpec(t, "email_attachments"), attachment); err != nil {
+		t.Fatalf("remap attachment: %v", err)
+	}
+	if string(attachment["s3_key"]) != `"tenant/acme/inbox/a.pdf"` {
+		t.Fatalf("expected the new file key, got %s", attachment["s3_key"])
+	}
+}
+
+func TestImportRemapperRejectsFilesOutsideTheBundle(t *testing.T) {
+	files := map[string]string{
+		"tenant/old/inbox/a.pdf": "tenant/acme/inbox/a.pdf",
+		"tenant/old/inbox/b.pdf": "tenant/other/inbox/b.pdf",
+	}
+	remapper := NewImportRemapper(&ImportTargetKeys{}, files, ImportFilePrefix("acme"), func() string { return "NEW" })
+
+	for _, key := range []string{"tenant/victim/inbox/secret.pdf", "tenant/old/inbox/b.pdf"} {
+		attachment := decodeRecord(t, `{"id":"ATT","message_id":"MSG","s3_key":"`+key+`"}`)
+		if _, err := remapper.Remap(datasetSpec(t, "email_attachments"), attachment); !errors.Is(err, ErrInvalidImportBundle) {
+			t.Fatalf("expected %s to be rejected, got %v", key, err)
+		}
+	}
+
+	header := decodeRecord(t, `{"id":"INV","document_ref_s3_key":null}`)
+	if _, err := remapper.Remap(datasetSpec(t, "invoice_headers"), header); err != nil {
+		t.Fatalf("expected records without a file to be accepted, got %v", err)
+	}
+}
+
+func TestImportTargetKeysRejectsOrganizationsWithData(t *testing.T) {
+	seeded := &ImportTargetKeys{IDs: map[string]map[string]bool{"users": {"OWNER": true}, "roles": {"ADMIN": true}}}
+	if err := seeded.EnsureEmpty(); err != nil {
+		t.Fatalf("expected a freshly provisioned organization to be accepted, got %v", err)
+	}
+
+	used := &ImportTargetKeys{IDs: map[string]map[string]bool{"invoice_headers": {"INV": true}}}
+	if err := used.EnsureEmpty(); !errors.Is(err, ErrImportTargetNotEmpty) {
+		t.Fatalf("expected ErrImportTargetNotEmpty, got %v", err)
+	}
+}
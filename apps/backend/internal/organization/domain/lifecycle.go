@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOrganizationNotFound    = errors.New("organization not found")
+	ErrNotOrganizationOwner    = errors.New("only the organization owner can do this")
+	ErrInvalidStatusTransition = errors.New("organization status does not allow this operation")
+	ErrInvalidOrganizationName = errors.New("invalid organization name")
+)
+
+// DefaultDeletionGracePeriod is how long a deleted organization can still be restored.
+const DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+const maxOrganizationNameLength = 255
+
+// Lifecycle actions, also used as audit event actions.
+const (
+	ActionRenamed           = "organization.renamed"
+	ActionSlugChanged       = "organization.slug_changed"
+	ActionSuspended         = "organization.suspended"
+	ActionArchived          = "organization.archived"
+	ActionReactivated       = "organization.reactivated"
+	ActionDeletionScheduled = "organization.deletion_scheduled"
+	ActionDeletionCancelled = "organization.deletion_cancelled"
+	ActionPurged            = "organization.purged"
+)
+
+// statusTransitions lists the statuses each status change can start from.
+var statusTransitions = map[string][]string{
+	StatusSuspended:       {StatusActive},
+	StatusArchived:        {StatusActive, StatusSuspended},
+	StatusActive:          {StatusSuspended, StatusArchived},
+	StatusPendingDeletion: {StatusActive, StatusSuspended, StatusArchived},
+}
+
+// TransitionSources returns the statuses from which an organization can move
+// to the target status through a lifecycle operation.
+func TransitionSources(to string) []string {
+	return statusTransitions[to]
+}
+
+// CanTransition reports whether the organization can move to the target status.
+func (o *Organization) CanTransition(to string) error {
+	if !slices.Contains(statusTransitions[to], o.Status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, o.Status, to)
+	}
+	return nil
+}
+
+// IsEditable reports whether name and slug can still change; organizations
+// being deleted or not yet provisioned are frozen.
+func (o *Organization) IsEditable() bool {
+	return o.Status == StatusActive || o.Status == StatusSuspended || o.Status == StatusArchived
+}
+
+func NormalizeOrganizationName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxOrganizationNameLength {
+		return "", ErrInvalidOrganizationName
+	}
+	return name, nil
+}
+
+func NormalizeSlug(slug string) (string, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if len(slug) > 100 || !slugRegex.MatchString(slug) {
+		return "", ErrInvalidSlug
+	}
+	return slug, nil
+}
+
+// StoragePrefixes lists the object storage prefixes that can hold the
+// organization's files: its tenant files and short-lived uploads.
+// Event-driven writers key objects by slug, so former slugs are included as
+// well as the ID.
+func StoragePrefixes(organizationID string, slugs ...string) []string {
+	var prefixes []string
+	for _, ref := range append([]string{organizationID}, slugs...) {
+		if ref == "" {
+			continue
+		}
+		for _, prefix := range []string{"tenant/" + ref + "/", "1-day/tenants/" + ref + "/"} {
+			if !slices.Contains(prefixes, prefix) {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	return prefixes
+}
+
+// AuditEvent records who changed the organization and how.
+type AuditEvent struct {
+	ID             string
+	OrganizationID string
+	ActorUserID    string
+	Action         string
+	Details        map[string]any
+	CreatedAt      time.Time
+}
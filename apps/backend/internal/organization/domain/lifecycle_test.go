@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCanTransitionFollowsLifecycleRules(t *testing.T) {
+	cases := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{StatusActive, StatusSuspended, true},
+		{StatusSuspended, StatusActive, true},
+		{StatusSuspended, StatusArchived, true},
+		{StatusArchived, StatusSuspended, false},
+		{StatusArchived, StatusPendingDeletion, true},
+		{StatusPendingDeletion, StatusActive, false},
+		{StatusProvisioning, StatusSuspended, false},
+		{StatusActive, StatusDeleting, false},
+	}
+
+	for _, tc := range cases {
+		org := &Organization{Status: tc.from}
+		err := org.CanTransition(tc.to)
+		if tc.allowed && err != nil {
+			t.Fatalf("%s -> %s: expected allowed, got %v", tc.from, tc.to, err)
+		}
+		if !tc.allowed && !errors.Is(err, ErrInvalidStatusTransition) {
+			t.Fatalf("%s -> %s: expected ErrInvalidStatusTransition, got %v", tc.from, tc.to, err)
+		}
+	}
+}
+
+func TestNormalizeSlugLowercasesAndRejectsInvalid(t *testing.T) {
+	slug, err := NormalizeSlug("  Acme-Corp ")
+	if err != nil || slug != "acme-corp" {
+		t.Fatalf("expected acme-corp, got %q (%v)", slug, err)
+	}
+
+	if _, err := NormalizeSlug("acme corp"); !errors.Is(err, ErrInvalidSlug) {
+		t.Fatalf("expected ErrInvalidSlug, got %v", err)
+	}
+}
+
+func TestStoragePrefixesCoverIDAndEverySlug(t *testing.T) {
+	prefixes := StoragePrefixes("01JW58TAT9M0N4R8M1P3Q6R9Y0", "acme", "", "old-acme", "acme")
+
+	expected := []string{
+		"tenant/01JW58TAT9M0N4R8M1P3Q6R9Y0/",
+		"1-day/tenants/01JW58TAT9M0N4R8M1P3Q6R9Y0/",
+		"tenant/acme/",
+		"1-day/tenants/acme/",
+		"tenant/old-acme/",
+		"1-day/tenants/old-acme/",
+	}
+	if !slices.Equal(prefixes, expected) {
+		t.Fatalf("expected %v, got %v", expected, prefixes)
+	}
+}
+
+func TestPendingProvisioningStepsStartAfterCompletedStep(t *testing.T) {
+	if steps := PendingProvisioningSteps(""); len(steps) != 4 || steps[0] != ProvisioningStepDBCreated {
+		t.Fatalf("expected every step pending, got %v", steps)
+	}
+
+	steps := PendingProvisioningSteps(ProvisioningStepMigrated)
+	if !slices.Equal(steps, []string{ProvisioningStepOwnerSeeded, ProvisioningStepMembershipAdded}) {
+		t.Fatalf("unexpected pending steps %v", steps)
+	}
+
+	if steps := PendingProvisioningSteps(ProvisioningStepMembershipAdded); len(steps) != 0 {
+		t.Fatalf("expected no pending steps, got %v", steps)
+	}
+}
@@ -8,47 +8,143 @@ import (
 )
 
 var (
-	ErrInvalidSlug = errors.New("invalid organization slug: must be alphanumeric and hyphens only")
+	ErrInvalidSlug       = errors.New("invalid organization slug: must be alphanumeric and hyphens only")
+	ErrSlugAlreadyExists = errors.New("organization slug already exists")
+	// ErrMFASessionRequired is returned when a session without MFA tries to require MFA for the organization.
+	ErrMFASessionRequired = errors.New("an mfa session is required to enforce mfa")
+	ErrInvalidIsolation   = errors.New("invalid tenant isolation: mode must be 'database' or 'schema' with a valid shared database")
 )
 
 const (
 	StatusProvisioning = "provisioning"
 	StatusActive       = "active"
 	StatusFailed       = "failed"
+	StatusSuspended    = "suspended"
+	StatusArchived     = "archived"
+	// StatusPendingDeletion keeps the data during the grace period; StatusDeleting
+	// marks a purge in progress so only one worker runs it.
+	StatusPendingDeletion = "pending_deletion"
+	StatusDeleting        = "deleting"
+	// StatusMaintenance takes the organization offline while the migrate tool
+	// moves it to a dedicated database.
+	StatusMaintenance = "maintenance"
 )
 
-var slugRegex = regexp.MustCompile(`^[a-z0-9-]+$`)
+// Isolation modes: a dedicated database per tenant, or a schema named after
+// DBName inside a database shared by many small tenants.
+const (
+	IsolationDatabase = "database"
+	IsolationSchema   = "schema"
+)
+
+// RoleOwner is the control plane membership role allowed to manage the organization's lifecycle.
+const RoleOwner = "OWNER"
+
+var (
+	slugRegex   = regexp.MustCompile(`^[a-z0-9-]+$`)
+	dbNameRegex = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
 
 // Organization represents a tenant in the system.
 type Organization struct {
-	ID              string
-	Name            string
-	Slug            string
-	DBName          string
-	Status          string
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
-	DeletedAt       *time.Time
-	MembersCount    int
-	CurrentUserRole string
+	ID          string
+	Name        string
+	Slug        string
+	DBName      string
+	Status      string
+	MFARequired bool
+	// IsolationMode is IsolationDatabase or IsolationSchema; SharedDatabase is
+	// the database holding the tenant's schema in schema mode.
+	IsolationMode  string
+	SharedDatabase string
+	// DeletionScheduledAt is when a pending deletion becomes permanent.
+	DeletionScheduledAt *time.Time
+	// ProvisioningStep is the last completed provisioning step and
+	// ProvisioningError the cause of the last failure.
+	ProvisioningStep  string
+	ProvisioningError string
+	// ProvisioningOwner is seeded into the tenant database as its first user.
+	ProvisioningOwner *OwnerData
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	DeletedAt         *time.Time
+	MembersCount      int
+	CurrentUserRole   string
 }
 
 // NewOrganization creates a new organization entity with valid defaults.
 func NewOrganization(name, slug string) (*Organization, error) {
-	slug = strings.ToLower(strings.TrimSpace(slug))
-
-	if !slugRegex.MatchString(slug) {
-		return nil, ErrInvalidSlug
+	slug, err := NormalizeSlug(slug)
+	if err != nil {
+		return nil, err
 	}
 
 	dbName := "tenant_" + strings.ReplaceAll(slug, "-", "_")
 
 	return &Organization{
-		Name:      strings.TrimSpace(name),
-		Slug:      slug,
-		DBName:    dbName,
-		Status:    StatusProvisioning,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Name:          strings.TrimSpace(name),
+		Slug:          slug,
+		DBName:        dbName,
+		Status:        StatusProvisioning,
+		IsolationMode: IsolationDatabase,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}, nil
 }
+
+// Location returns where the organization's data lives.
+func (o *Organization) Location() DatabaseLocation {
+	if o.IsolationMode != IsolationSchema {
+		return DatabaseLocation{DBName: o.DBName}
+	}
+	return DatabaseLocation{DBName: o.DBName, SharedDatabase: o.SharedDatabase}
+}
+
+// IsolationPolicy decides how new organizations are isolated.
+type IsolationPolicy struct {
+	Mode           string
+	SharedDatabase string
+}
+
+func (p IsolationPolicy) Validate() error {
+	switch p.Mode {
+	case IsolationDatabase:
+		return nil
+	case IsolationSchema:
+		if !dbNameRegex.MatchString(p.SharedDatabase) {
+			return ErrInvalidIsolation
+		}
+		return nil
+	default:
+		return ErrInvalidIsolation
+	}
+}
+
+// Apply sets the policy's isolation on a new organization.
+func (p IsolationPolicy) Apply(org *Organization) {
+	org.IsolationMode = p.Mode
+	org.SharedDatabase = ""
+	if p.Mode == IsolationSchema {
+		org.SharedDatabase = p.SharedDatabase
+	}
+}
+
+// DatabaseLocation identifies a tenant's data: its own database named DBName,
+// or the schema DBName inside SharedDatabase.
+type DatabaseLocation struct {
+	DBName         string
+	SharedDatabase string
+}
+
+// Shared reports whether the tenant lives in a schema of a shared database.
+func (l DatabaseLocation) Shared() bool {
+	return l.SharedDatabase != ""
+}
+
+// Database is the physical database holding the tenant's data.
+func (l DatabaseLocation) Database() string {
+	if l.Shared() {
+		return l.SharedDatabase
+	}
+	return l.DBName
+}
@@ -3,21 +3,27 @@ package domain
 import "context"
 
 type OwnerData struct {
-	ID        string
-	Email     string
-	FirstName string
-	LastName  string
-	AvatarURL string
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	AvatarURL string `json:"avatar_url"`
 }
 
 // Provisioner is responsible for the physical infrastructure provisioning of a new organization.
+// Every step is idempotent so that failed provisioning can be resumed.
 type Provisioner interface {
-	// CreateDatabase creates a new physical database.
-	CreateDatabase(ctx context.Context, dbName string) error
+	// CreateDatabase creates the organization's database, or its schema in a
+	// shared database, unless it already exists.
+	CreateDatabase(ctx context.Context, location DatabaseLocation) error
 
 	// MigrateDatabase applies the latest business schemas to the organization's database.
-	MigrateDatabase(ctx context.Context, dbName string) error
+	MigrateDatabase(ctx context.Context, location DatabaseLocation) error
+
+	// DropDatabase removes the organization's database or schema if it exists.
+	DropDatabase(ctx context.Context, location DatabaseLocation) error
 
 	// SeedOwner inserts the initial user into the tenant DB and assigns the admin role.
-	SeedOwner(ctx context.Context, dbName string, owner OwnerData) error
+	// An owner that is already seeded is left untouched.
+	SeedOwner(ctx context.Context, location DatabaseLocation, owner OwnerData) error
 }
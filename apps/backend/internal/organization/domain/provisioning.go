@@ -0,0 +1,43 @@
+package domain
+
+import "slices"
+
+// Provisioning steps in the order they run. The organization records the last
+// completed step, so a failed provisioning resumes from the next one.
+const (
+	ProvisioningStepDBCreated       = "db_created"
+	ProvisioningStepMigrated        = "migrated"
+	ProvisioningStepOwnerSeeded     = "owner_seeded"
+	ProvisioningStepMembershipAdded = "membership_added"
+)
+
+const (
+	ActionProvisioningResumed    = "organization.provisioning_resumed"
+	ActionProvisioningRolledBack = "organization.provisioning_rolled_back"
+)
+
+var provisioningSteps = []string{
+	ProvisioningStepDBCreated,
+	ProvisioningStepMigrated,
+	ProvisioningStepOwnerSeeded,
+	ProvisioningStepMembershipAdded,
+}
+
+// PendingProvisioningSteps returns the steps left after the completed one. An
+// empty or unknown step means nothing has completed yet.
+func PendingProvisioningSteps(completed string) []string {
+	index := slices.Index(provisioningSteps, completed)
+	return slices.Clone(provisioningSteps[index+1:])
+}
+
+// CompletedProvisioningSteps returns the steps done up to and including the completed one.
+func CompletedProvisioningSteps(completed string) []string {
+	index := slices.Index(provisioningSteps, completed)
+	return slices.Clone(provisioningSteps[:index+1])
+}
+
+// IsProvisioningOwner reports whether the user requested the organization;
+// until provisioning completes there is no membership to check.
+func (o *Organization) IsProvisioningOwner(userID string) bool {
+	return o.ProvisioningOwner != nil && userID != "" && o.ProvisioningOwner.ID == userID
+}
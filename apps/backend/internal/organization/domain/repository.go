@@ -1,11 +1,43 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type Repository interface {
 	Create(ctx context.Context, org *Organization) error
 	UpdateStatus(ctx context.Context, organizationID, status string) error
 	ExistsBySlug(ctx context.Context, slug string) (bool, error)
 	GetByID(ctx context.Context, id, userID string) (*Organization, error)
+	// AddMembership is a no-op when the user is already a member.
 	AddMembership(ctx context.Context, userID, tenantID, role string) error
+	// CompleteProvisioningStep records the last completed provisioning step.
+	CompleteProvisioningStep(ctx context.Context, organizationID, step string) error
+	// FailProvisioning marks the organization failed and keeps the cause.
+	FailProvisioning(ctx context.Context, organizationID, cause string) error
+	// SetMFARequired changes the MFA policy. Enabling it also revokes the
+	// organization's outstanding tenant tokens.
+	SetMFARequired(ctx context.Context, organizationID string, required bool) error
+	// Lifecycle changes take the audit event that records them and store it in
+	// the same transaction; a nil event records nothing.
+	Rename(ctx context.Context, organizationID, name string, event *AuditEvent) error
+	// ChangeSlug keeps the old slug as a redirect and revokes tenant tokens,
+	// which carry the slug. It returns ErrSlugAlreadyExists when the slug is
+	// used or redirected by another organization.
+	ChangeSlug(ctx context.Context, organizationID, oldSlug, newSlug string, event *AuditEvent) error
+	ListSlugRedirects(ctx context.Context, organizationID string) ([]string, error)
+	// ChangeStatus moves the organization to status only if its current status
+	// is one of from, otherwise it returns ErrInvalidStatusTransition.
+	ChangeStatus(ctx context.Context, organizationID string, from []string, status string, event *AuditEvent) error
+	ScheduleDeletion(ctx context.Context, organizationID string, from []string, purgeAt time.Time, event *AuditEvent) error
+	// CancelDeletion restores the status the organization had before deletion
+	// was scheduled and adds it to the event details as restored_status.
+	CancelDeletion(ctx context.Context, organizationID string, event *AuditEvent) (string, error)
+	// ClaimDueDeletions marks organizations past their grace period as deleting
+	// and returns them; purges stuck for longer than staleAfter are claimed again.
+	ClaimDueDeletions(ctx context.Context, now time.Time, staleAfter time.Duration) ([]*Organization, error)
+	// Purge removes the memberships and the control plane record.
+	Purge(ctx context.Context, organizationID string, event *AuditEvent) error
+	RecordAuditEvent(ctx context.Context, event *AuditEvent) error
 }
@@ -1,31 +1,90 @@
 package organization
 
 import (
+	"context"
 	"net/http"
 
+	orgEvents "github.com/bowerbird/internal/organization/adapters/events"
+	exporterpostgres "github.com/bowerbird/internal/organization/adapters/exporter/postgres"
 	httpV1 "github.com/bowerbird/internal/organization/adapters/http/v1"
+	importerpostgres "github.com/bowerbird/internal/organization/adapters/importer/postgres"
+	orgJobs "github.com/bowerbird/internal/organization/adapters/jobs"
+	orgJobsHandlers "github.com/bowerbird/internal/organization/adapters/jobs/handlers"
 	provisionerpostgres "github.com/bowerbird/internal/organization/adapters/provisioner/postgres"
 	repositorypostgres "github.com/bowerbird/internal/organization/adapters/repository/postgres"
 	"github.com/bowerbird/internal/organization/application"
+	"github.com/bowerbird/internal/organization/application/ports"
+	"github.com/bowerbird/internal/organization/application/queries"
+	"github.com/bowerbird/internal/organization/domain"
+	auditPostgres "github.com/bowerbird/internal/platform/audit/postgres"
+	"github.com/bowerbird/internal/platform/auth"
 	"github.com/bowerbird/internal/platform/config"
+	"github.com/bowerbird/internal/platform/database"
+	platformEvents "github.com/bowerbird/internal/platform/events"
+	platformJobs "github.com/bowerbird/internal/platform/jobs"
+	platformStorage "github.com/bowerbird/internal/platform/storage"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewApplication(pool *pgxpool.Pool, databaseURL, migrationsDir string) *application.Application {
+// NewApplication builds the organization module. Provisioning runs through
+// the job queue; a nil queue runs it inline, for tools such as the seed.
+// Taking an organization offline closes its pools in the tenant registry.
+// cfg sets the database server and how new organizations are isolated.
+// Data exports and imports run through the same queue and keep their
+// bundles in fileStore; finished exports are announced on the event bus.
+// The tenant audit log is read from each organization's database.
+func NewApplication(pool *pgxpool.Pool, cfg config.Config, migrationsDir string, files ports.FilePurger, queue platformJobs.Queue, tenantRegistry *database.Registry, fileStore platformStorage.StreamingFileStore, eventBus platformEvents.EventBus) *application.Application {
 	if pool == nil {
 		panic("control plane db pool is required")
 	}
-	if databaseURL == "" {
+	if cfg.DatabaseURL == "" {
 		panic("database url is required")
 	}
+	isolation := domain.IsolationPolicy{Mode: cfg.TenantIsolationMode, SharedDatabase: cfg.TenantSharedDatabase}
+	if err := isolation.Validate(); err != nil {
+		panic("tenant isolation config: " + err.Error())
+	}
 	if migrationsDir == "" {
 		panic("tenant migrations dir is required")
 	}
+	if files == nil {
+		panic("file purger is required")
+	}
+	if tenantRegistry == nil {
+		panic("tenant registry is required")
+	}
+	if fileStore == nil {
+		panic("file store is required")
+	}
+	if eventBus == nil {
+		panic("event bus is required")
+	}
 
 	organizationRepo := repositorypostgres.NewPostgresRepository(pool)
-	organizationProvisioner := provisionerpostgres.NewPostgresProvisioner(pool, databaseURL, migrationsDir)
+	organizationProvisioner := provisionerpostgres.NewPostgresProvisioner(pool, cfg.DatabaseURL, migrationsDir)
+	apiKeyRepo := repositorypostgres.NewAPIKeyRepository(pool)
+
+	exports := application.ExportDependencies{
+		Repository: repositorypostgres.NewExportRepository(pool),
+		Reader:     exporterpostgres.NewTenantDataReader(tenantRegistry),
+		Files:      fileStore,
+		Notifier:   orgEvents.NewExportNotifier(eventBus, cfg.FrontendURL),
+	}
 
-	return application.NewApplication(organizationRepo, organizationProvisioner)
+	imports := application.ImportDependencies{
+		Repository: repositorypostgres.NewImportRepository(pool),
+		Writer:     importerpostgres.NewTenantDataWriter(tenantRegistry),
+		Files:      fileStore,
+	}
+
+	var scheduler ports.ProvisioningScheduler
+	if queue != nil {
+		scheduler = orgJobs.NewQueueProvisioningScheduler(queue)
+		exports.Scheduler = orgJobs.NewQueueExportScheduler(queue)
+		imports.Scheduler = orgJobs.NewQueueImportScheduler(queue)
+	}
+
+	return application.NewApplication(organizationRepo, organizationProvisioner, apiKeyRepo, files, scheduler, tenantRegistry, isolation, exports, imports, auditPostgres.NewStore(tenantRegistry))
 }
 
 func NewHTTPHandler(mux *http.ServeMux, app *application.Application, authMiddleware func(http.Handler) http.Handler, cfg config.Config) *httpV1.Router {
@@ -40,8 +99,108 @@ func NewHTTPHandler(mux *http.ServeMux, app *application.Application, authMiddle
 		application.NewCreateOrganizationUseCaseFromCommand(app.Commands.CreateOrganization),
 		application.NewGetOrganizationUseCaseFromQuery(app.Queries.GetOrganization),
 	)
-	router := httpV1.NewRouter(controller)
+	apiKeyController := httpV1.NewAPIKeyController(
+		app.Commands.CreateAPIKey,
+		app.Commands.RevokeAPIKey,
+		app.Queries.ListAPIKeys,
+	)
+	securityController := httpV1.NewSecurityController(app.Commands.UpdateSecurityPolicy)
+	lifecycleController := httpV1.NewLifecycleController(
+		app.Commands.UpdateOrganization,
+		app.Commands.ChangeStatus,
+		app.Commands.DeleteOrganization,
+		app.Commands.CancelDeletion,
+	)
+	provisioningController := httpV1.NewProvisioningController(
+		app.Queries.GetProvisioningStatus,
+		app.Commands.ResumeProvisioning,
+		app.Commands.RollbackProvisioning,
+	)
+	exportController := httpV1.NewExportController(
+		app.Commands.RequestDataExport,
+		app.Queries.ListDataExports,
+		app.Queries.GetDataExport,
+		app.Queries.DownloadDataExport,
+	)
+	importController := httpV1.NewImportController(
+		app.Commands.CreateDataImport,
+		app.Commands.StartDataImport,
+		app.Queries.ListDataImports,
+		app.Queries.GetDataImport,
+	)
+	auditController := httpV1.NewAuditController(app.Queries.ListAuditEvents, app.Queries.ExportAuditEvents)
+	router := httpV1.NewRouter(controller, apiKeyController, securityController, lifecycleController, provisioningController, exportController, importController, auditController)
 	router.Register(mux, cfg, authMiddleware)
 
 	return router
 }
+
+// NewProvisioningProcessor runs the provisioning jobs scheduled through the queue.
+func NewProvisioningProcessor(app *application.Application) *orgJobsHandlers.ProcessOrganizationProvisioningRequested {
+	if app == nil {
+		panic("organization application is required")
+	}
+
+	return orgJobs.NewOrganizationProvisioningRequestedProcessor(app.Commands.ProvisionOrganization)
+}
+
+// NewExportProcessor runs the data export jobs scheduled through the queue.
+func NewExportProcessor(app *application.Application) *orgJobsHandlers.ProcessOrganizationExportRequested {
+	if app == nil {
+		panic("organization application is required")
+	}
+
+	return orgJobs.NewOrganizationExportRequestedProcessor(app.Commands.RunDataExport)
+}
+
+// NewImportProcessor runs the data import jobs scheduled through the queue.
+func NewImportProcessor(app *application.Application) *orgJobsHandlers.ProcessOrganizationImportRequested {
+	if app == nil {
+		panic("organization application is required")
+	}
+
+	return orgJobs.NewOrganizationImportRequestedProcessor(app.Commands.RunDataImport)
+}
+
+// NewPurgeDueSubscriber purges organizations whose deletion grace period is
+// over each time the scheduled OrganizationPurgeDue event fires.
+func NewPurgeDueSubscriber(app *application.Application) *orgEvents.OnOrganizationPurgeDue {
+	if app == nil {
+		panic("organization application is required")
+	}
+
+	return orgEvents.NewOnOrganizationPurgeDue(app.Commands.PurgeOrganizations)
+}
+
+// NewAPIKeyAuthenticator exposes organization API keys to auth.Middleware.
+func NewAPIKeyAuthenticator(app *application.Application) auth.APIKeyAuthenticator {
+	if app == nil {
+		panic("organization application is required")
+	}
+
+	return apiKeyAuthenticator{query: app.Queries.AuthenticateAPIKey}
+}
+
+type apiKeyAuthenticator struct {
+	query *queries.AuthenticateAPIKeyQuery
+}
+
+func (a apiKeyAuthenticator) AuthenticateAPIKey(ctx context.Context, token string) (*auth.CustomClaims, error) {
+	key, err := a.query.Execute(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The creator is kept as the acting user for attribution and must still
+	// be a member; the key's scopes, not the creator's current roles, bound
+	// what it can do.
+	return &auth.CustomClaims{
+		UserID:   key.CreatedBy,
+		APIKeyID: key.ID,
+		TenantClaims: auth.TenantClaims{
+			TenantID:    key.TenantID,
+			TenantSlug:  key.TenantSlug,
+			Permissions: key.Scopes,
+		},
+	}, nil
+}
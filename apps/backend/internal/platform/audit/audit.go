@@ -0,0 +1,236 @@
+// Package audit records security-relevant and business actions in the
+// tenant's append-only audit_events table.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/bowerbird/internal/platform/id"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+// Actor types.
+const (
+	ActorUser   = "user"
+	ActorAPIKey = "api_key"
+	ActorSystem = "system"
+)
+
+// Recorded actions. Codes are stable: they are stored and used as filters.
+const (
+	ActionConnectionDeleted           = "connection.deleted"
+	ActionConnectionRequiresReconnect = "connection.requires_reconnect"
+	ActionMemberLeft                  = "member.left"
+	ActionInvoiceExtractionQueued     = "invoice.extraction_queued"
+	ActionConnectionTransferred       = "connection.transferred"
+	ActionAccountErased               = "account.erased"
+	ActionConnectionUpdated           = "connection.updated"
+	ActionConnectionReconnected       = "connection.reconnected"
+)
+
+// Target types.
+const (
+	TargetConnection        = "connection"
+	TargetUser              = "user"
+	TargetInvoiceExtraction = "invoice_extraction"
+)
+
+var ErrMissingAction = errors.New("audit action is required")
+
+// Event is what a command records. Before and After are snapshots of the
+// target; only the fields that differ end up in the stored diff, so callers
+// must leave secrets out of them.
+type Event struct {
+	// ActorUserID names the user who acted when the context carries no
+	// claims, e.g. an OAuth callback. Claims, when present, take precedence.
+	ActorUserID string
+	Action      string
+	TargetType  string
+	TargetID    string
+	Before      any
+	After       any
+	Metadata    map[string]any
+}
+
+// Entry is a stored audit event.
+type Entry struct {
+	ID            string
+	OccurredAt    time.Time
+	ActorType     string
+	ActorUserID   string
+	ActorAPIKeyID string
+	Action        string
+	TargetType    string
+	TargetID      string
+	IPAddress     string
+	UserAgent     string
+	TraceID       string
+	Changes       map[string]Change
+	Metadata      map[string]any
+}
+
+// Filter narrows a listing. Zero values match everything; Cursor is the ID
+// of the last entry of the previous page.
+type Filter struct {
+	Action     string
+	ActorID    string
+	TargetType string
+	TargetID   string
+	From       time.Time
+	To         time.Time
+	Cursor     string
+	Limit      int
+}
+
+// Store persists entries in the database of the tenant in the context.
+type Store interface {
+	Append(ctx context.Context, entry Entry) error
+	List(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+// Anonymization describes the personal data of an erased user that stored
+// entries may hold. The entries themselves, and the pseudonymous actor and
+// target IDs, are kept.
+type Anonymization struct {
+	// UserID loses the IP address and user agent of the actions it performed.
+	UserID string
+	// Identifiers, e.g. email addresses, are replaced wherever they appear
+	// in changes and metadata.
+	Identifiers []string
+	Replacement string
+}
+
+// Anonymizer scrubs personal data from the audit log of the tenant in the
+// context and returns how many entries changed.
+type Anonymizer interface {
+	Anonymize(ctx context.Context, anonymization Anonymization) (int, error)
+}
+
+// Recorder is the helper commands use to record what they did.
+type Recorder interface {
+	Record(ctx context.Context, event Event) error
+}
+
+type StoreRecorder struct {
+	store Store
+	now   func() time.Time
+	newID func() string
+}
+
+func NewRecorder(store Store) *StoreRecorder {
+	if store == nil {
+		panic("audit store is required")
+	}
+
+	return &StoreRecorder{store: store, now: time.Now, newID: id.NewULID}
+}
+
+// Record stores the event in the tenant's audit log. The actor comes from
+// the authenticated claims, then from Event.ActorUserID; without either the
+// action is attributed to the system (background jobs). Request details come
+// from Middleware.
+func (r *StoreRecorder) Record(ctx context.Context, event Event) error {
+	if event.Action == "" {
+		return ErrMissingAction
+	}
+	if _, err := tenant.TenantIDFromContext(ctx); err != nil {
+		return fmt.Errorf("record %s: %w", event.Action, err)
+	}
+
+	changes, err := Diff(event.Before, event.After)
+	if err != nil {
+		return fmt.Errorf("record %s: %w", event.Action, err)
+	}
+
+	request := RequestInfoFromContext(ctx)
+	entry := Entry{
+		ID:         r.newID(),
+		OccurredAt: r.now().UTC(),
+		ActorType:  ActorSystem,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		IPAddress:  request.IPAddress,
+		UserAgent:  request.UserAgent,
+		TraceID:    request.TraceID,
+		Changes:    changes,
+		Metadata:   event.Metadata,
+	}
+	if claims, ok := auth.ClaimsFromContext(ctx); ok {
+		switch {
+		case claims.APIKeyID != "":
+			entry.ActorType, entry.ActorAPIKeyID = ActorAPIKey, claims.APIKeyID
+		case claims.UserID != "":
+			entry.ActorType, entry.ActorUserID = ActorUser, claims.UserID
+		}
+	}
+	if entry.ActorType == ActorSystem && event.ActorUserID != "" {
+		entry.ActorType, entry.ActorUserID = ActorUser, event.ActorUserID
+	}
+
+	if err := r.store.Append(ctx, entry); err != nil {
+		return fmt.Errorf("record %s: %w", event.Action, err)
+	}
+	return nil
+}
+
+// Change is the before and after value of a single field. A missing side
+// means the field did not exist (creation or deletion).
+type Change struct {
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// Diff compares the JSON encoding of two snapshots field by field and keeps
+// the fields that changed. Snapshots that are not JSON objects are compared
+// as a whole under the "value" key.
+func Diff(before, after any) (map[string]Change, error) {
+	beforeFields, err := snapshotFields(before)
+	if err != nil {
+		return nil, fmt.Errorf("encode before snapshot: %w", err)
+	}
+	afterFields, err := snapshotFields(after)
+	if err != nil {
+		return nil, fmt.Errorf("encode after snapshot: %w", err)
+	}
+
+	changes := map[string]Change{}
+	for field, value := range beforeFields {
+		if string(afterFields[field]) != string(value) {
+			changes[field] = Change{Before: value, After: afterFields[field]}
+		}
+	}
+	for field, value := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			changes[field] = Change{After: value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, nil
+}
+
+func snapshotFields(snapshot any) (map[string]json.RawMessage, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	if string(raw) == "null" {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return map[string]json.RawMessage{"value": raw}, nil
+	}
+	return fields, nil
+}
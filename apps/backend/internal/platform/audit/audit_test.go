@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/bowerbird/internal/platform/http/api"
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+type memoryStore struct {
+	Store
+
+	entries []Entry
+}
+
+func (s *memoryStore) Append(ctx context.Context, entry Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func newTestRecorder() (*StoreRecorder, *memoryStore) {
+	store := &memoryStore{}
+	recorder := NewRecorder(store)
+	recorder.newID = func() string { return "evt-1" }
+	recorder.now = func() time.Time { return time.Date(2026, time.March, 4, 5, 6, 7, 0, time.UTC) }
+	return recorder, store
+}
+
+func TestDiffKeepsChangedFieldsOnly(t *testing.T) {
+	changes, err := Diff(
+		map[string]any{"status": "active", "provider": "gmail", "scopes": []string{"a"}},
+		map[string]any{"status": "requires_reconnect", "provider": "gmail", "reason": "revoked"},
+	)
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+
+	if len(changes) != 3 {
+		t.Fatalf("expected status, scopes and reason to change, got %v", changes)
+	}
+	if string(changes["status"].Before) != `"active"` || string(changes["status"].After) != `"requires_reconnect"` {
+		t.Fatalf("unexpected status change: %+v", changes["status"])
+	}
+	if changes["scopes"].After != nil || changes["reason"].Before != nil {
+		t.Fatalf("expected removed and added fields to keep one side only, got %+v", changes)
+	}
+}
+
+func TestDiffOfEqualSnapshotsIsEmpty(t *testing.T) {
+	changes, err := Diff(map[string]any{"status": "active"}, map[string]any{"status": "active"})
+	if err != nil || changes != nil {
+		t.Fatalf("expected no changes, got %v (err %v)", changes, err)
+	}
+}
+
+func TestRecordAttributesUserAndRequest(t *testing.T) {
+	recorder, store := newTestRecorder()
+
+	var ctx context.Context
+	handler := api.GenerateTraceIDMiddleware(api.ClientIPMiddleware(1)(Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))))
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/connections/conn-1", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("sentry-trace", "trace-123")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	ctx = tenant.WithTenantID(ctx, "tenant-1")
+	ctx = auth.WithClaims(ctx, &auth.CustomClaims{UserID: "user-1"})
+	err := recorder.Record(ctx, Event{
+		Action:     ActionConnectionDeleted,
+		TargetType: TargetConnection,
+		TargetID:   "conn-1",
+		Before:     map[string]any{"status": "active"},
+	})
+	if err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+
+	if len(store.entries) != 1 {
+		t.Fatalf("expected one entry, got %d", len(store.entries))
+	}
+	entry := store.entries[0]
+	if entry.ActorType != ActorUser || entry.ActorUserID != "user-1" {
+		t.Fatalf("expected the user as actor, got %+v", entry)
+	}
+	if entry.IPAddress != "203.0.113.7" || entry.UserAgent != "curl/8.0" || entry.TraceID != "trace-123" {
+		t.Fatalf("expected request details, got %+v", entry)
+	}
+	if string(entry.Changes["status"].Before) != `"active"` || entry.Changes["status"].After != nil {
+		t.Fatalf("expected the deleted state in the diff, got %+v", entry.Changes)
+	}
+}
+
+func TestRecordAttributesAPIKeysAndSystem(t *testing.T) {
+	recorder, store := newTestRecorder()
+	ctx := tenant.WithTenantID(context.Background(), "tenant-1")
+
+	if err := recorder.Record(auth.WithClaims(ctx, &auth.CustomClaims{UserID: "creator", APIKeyID: "key-1"}), Event{Action: ActionInvoiceExtractionQueued}); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	if err := recorder.Record(ctx, Event{Action: ActionConnectionRequiresReconnect}); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+
+	if store.entries[0].ActorType != ActorAPIKey || store.entries[0].ActorAPIKeyID != "key-1" || store.entries[0].ActorUserID != "" {
+		t.Fatalf("expected the api key as actor, got %+v", store.entries[0])
+	}
+	if store.entries[1].ActorType != ActorSystem {
+		t.Fatalf("expected the system as actor, got %+v", store.entries[1])
+	}
+}
+
+func TestRecordAttributesTheEventActorWithoutClaims(t *testing.T) {
+	recorder, store := newTestRecorder()
+	ctx := tenant.WithTenantID(context.Background(), "tenant-1")
+	event := Event{ActorUserID: "user-1", Action: ActionConnectionReconnected}
+
+	if err := recorder.Record(ctx, event); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+	if err := recorder.Record(auth.WithClaims(ctx, &auth.CustomClaims{UserID: "creator", APIKeyID: "key-1"}), event); err != nil {
+		t.Fatalf("record failed: %v", err)
+	}
+
+	if store.entries[0].ActorType != ActorUser || store.entries[0].ActorUserID != "user-1" {
+		t.Fatalf("expected the event's user as actor, got %+v", store.entries[0])
+	}
+	if store.entries[1].ActorType != ActorAPIKey || store.entries[1].ActorUserID != "" {
+		t.Fatalf("expected the claims to take precedence, got %+v", store.entries[1])
+	}
+}
+
+func TestRecordRequiresTenant(t *testing.T) {
+	recorder, store := newTestRecorder()
+
+	err := recorder.Record(context.Background(), Event{Action: ActionMemberLeft})
+	if !errors.Is(err, tenant.ErrNoTenantIdInContext) || len(store.entries) != 0 {
+		t.Fatalf("expected ErrNoTenantIdInContext without writes, got %v", err)
+	}
+}
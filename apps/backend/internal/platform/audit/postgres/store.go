@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bowerbird/internal/platform/audit"
+	"github.com/bowerbird/internal/platform/database"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 1000
+)
+
+// Store writes to and reads from the audit_events table of the tenant in
+// the context. The table rejects updates and deletes; Anonymize is the only
+// exception and may only rewrite the columns that hold personal data.
+type Store struct {
+	registry *database.Registry
+}
+
+func NewStore(registry *database.Registry) *Store {
+	if registry == nil {
+		panic("database registry is required")
+	}
+
+	return &Store{registry: registry}
+}
+
+func (s *Store) Append(ctx context.Context, entry audit.Entry) error {
+	pool, err := s.registry.GetPool(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return fmt.Errorf("marshal audit changes: %w", err)
+	}
+	metadata, err := json.Marshal(entry.Metadata)
+	if err != nil {
+		return fmt.Errorf("marshal audit metadata: %w", err)
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO audit_events (
+			id, occurred_at, actor_type, actor_user_id, actor_api_key_id, action,
+			target_type, target_id, ip_address, user_agent, trace_id, changes, metadata
+		)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), NULLIF($11, ''), $12, $13)
+	`, entry.ID, entry.OccurredAt, entry.ActorType, entry.ActorUserID, entry.ActorAPIKeyID, entry.Action,
+		entry.TargetType, entry.TargetID, entry.IPAddress, entry.UserAgent, entry.TraceID, nullJSON(changes), nullJSON(metadata))
+	if err != nil {
+		return fmt.Errorf("insert audit event: %w", err)
+	}
+	return nil
+}
+
+// List returns entries newest first. IDs are ULIDs, so ordering by ID
+// follows occurred_at and doubles as the pagination cursor.
+func (s *Store) List(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	pool, err := s.registry.GetPool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	var args []any
+	where := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
+	}
+	if filter.ActorID != "" {
+		args = append(args, filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("(actor_user_id = $%[1]d OR actor_api_key_id = $%[1]d)", len(args)))
+	}
+	if filter.TargetType != "" {
+		where("target_type = $%d", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		where("target_id = $%d", filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		where("occurred_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("occurred_at < $%d", filter.To)
+	}
+	if filter.Cursor != "" {
+		where("id < $%d", filter.Cursor)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	limit = min(limit, maxListLimit)
+
+	query := `
+		SELECT id, occurred_at, actor_type, COALESCE(actor_user_id, ''), COALESCE(actor_api_key_id, ''), action,
+			COALESCE(target_type, ''), COALESCE(target_id, ''), COALESCE(ip_address, ''), COALESCE(user_agent, ''),
+			COALESCE(trace_id, ''), changes, metadata
+		FROM audit_events
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []audit.Entry
+	for rows.Next() {
+		var entry audit.Entry
+		var changes, metadata []byte
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.OccurredAt,
+			&entry.ActorType,
+			&entry.ActorUserID,
+			&entry.ActorAPIKeyID,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.IPAddress,
+			&entry.UserAgent,
+			&entry.TraceID,
+			&changes,
+			&metadata,
+		); err != nil {
+			return nil, fmt.Errorf("scan audit event: %w", err)
+		}
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+				return nil, fmt.Errorf("unmarshal audit changes: %w", err)
+			}
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &entry.Metadata); err != nil {
+				return nil, fmt.Errorf("unmarshal audit metadata: %w", err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list audit events: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Anonymize enables the erasure session flag the append-only trigger checks
+// and rewrites the matching entries in a single statement.
+func (s *Store) Anonymize(ctx context.Context, anonymization audit.Anonymization) (int, error) {
+	if anonymization.UserID == "" {
+		return 0, fmt.Errorf("anonymize audit events: user id is required")
+	}
+
+	pool, err := s.registry.GetPool(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin audit anonymization: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT set_config('bowerbird.audit_erasure', 'on', true)`); err != nil {
+		return 0, fmt.Errorf("enable audit erasure: %w", err)
+	}
+
+	replacement, err := jsonStringContent(anonymization.Replacement)
+	if err != nil {
+		return 0, fmt.Errorf("encode audit replacement: %w", err)
+	}
+
+	args := []any{anonymization.UserID, replacement}
+	changes, metadata := "changes::text", "metadata::text"
+	conditions := []string{"actor_user_id = $1"}
+	for _, identifier := range anonymization.Identifiers {
+		encoded, err := jsonStringContent(identifier)
+		if err != nil || encoded == "" {
+			continue
+		}
+		args = append(args, encoded)
+		n := len(args)
+		changes = fmt.Sprintf("replace(%s, $%d, $2)", changes, n)
+		metadata = fmt.Sprintf("replace(%s, $%d, $2)", metadata, n)
+		conditions = append(conditions, fmt.Sprintf("strpos(changes::text, $%[1]d) > 0 OR strpos(metadata::text, $%[1]d) > 0", n))
+	}
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf(`
+		UPDATE audit_events
+		SET ip_address = CASE WHEN actor_user_id = $1 THEN NULL ELSE ip_address END,
+			user_agent = CASE WHEN actor_user_id = $1 THEN NULL ELSE user_agent END,
+			changes = (%s)::jsonb,
+			metadata = (%s)::jsonb
+		WHERE %s
+	`, changes, metadata, strings.Join(conditions, " OR ")), args...)
+	if err != nil {
+		return 0, fmt.Errorf("anonymize audit events: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit audit anonymization: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// jsonStringContent returns the value as it appears inside a JSON string, so
+// it can be matched against the stored documents as text.
+func jsonStringContent(value string) (string, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return strings.Trim(string(raw), `"`), nil
+}
+
+func nullJSON(raw []byte) []byte {
+	if string(raw) == "null" {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bowerbird/internal/platform/http/api"
+)
+
+// RequestInfo describes the HTTP request an action came from.
+type RequestInfo struct {
+	IPAddress string
+	UserAgent string
+	TraceID   string
+}
+
+type requestInfoContextKey struct{}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoContextKey{}, info)
+}
+
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoContextKey{}).(RequestInfo)
+	return info
+}
+
+// Middleware captures the client address, user agent and trace ID of the
+// request. It must run after api.GenerateTraceIDMiddleware and
+// api.ClientIPMiddleware, which resolves the address through the trusted
+// proxies and leaves it empty when it is not a valid IP.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID := api.TraceIDFromContext(r.Context())
+		if traceID == "" {
+			traceID = r.Header.Get("sentry-trace")
+		}
+
+		ctx := WithRequestInfo(r.Context(), RequestInfo{
+			IPAddress: api.ClientIPFromContext(r.Context()),
+			UserAgent: r.UserAgent(),
+			TraceID:   traceID,
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
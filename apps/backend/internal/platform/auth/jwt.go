@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bowerbird/internal/platform/config"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -19,20 +20,89 @@ type TokenPair struct {
 	ExpiresIn    int
 }
 
+const (
+	accessTokenType  = "at+jwt"
+	refreshTokenType = "rt+jwt"
+)
+
+// TokenGenerator issues and validates access/refresh tokens. With a KeySet it
+// signs with the active asymmetric key (RS256/ES256) and stamps the kid header;
+// the shared secrets, when present, are only kept so HS256 tokens issued before
+// the switch remain valid until they expire.
 type TokenGenerator struct {
 	accessSecret  []byte
 	refreshSecret []byte
+	keys          *KeySet
+	issuer        string
 	accessTTL     time.Duration
 	refreshTTL    time.Duration
+	tenantTTL     time.Duration
 }
 
+const defaultTenantAccessTTL = 5 * time.Minute
+
 func NewTokenGenerator(accessSecret, refreshSecret string, accessTTL, refreshTTL time.Duration) *TokenGenerator {
 	return &TokenGenerator{
 		accessSecret:  []byte(accessSecret),
 		refreshSecret: []byte(refreshSecret),
 		accessTTL:     accessTTL,
 		refreshTTL:    refreshTTL,
+		tenantTTL:     min(defaultTenantAccessTTL, accessTTL),
+	}
+}
+
+// WithTenantAccessTTL overrides the lifetime of organization-scoped access tokens.
+func (t *TokenGenerator) WithTenantAccessTTL(ttl time.Duration) *TokenGenerator {
+	if ttl > 0 {
+		t.tenantTTL = ttl
+	}
+	return t
+}
+
+func NewTokenGeneratorWithKeySet(keys *KeySet, issuer, legacyAccessSecret, legacyRefreshSecret string, accessTTL, refreshTTL time.Duration) (*TokenGenerator, error) {
+	if _, err := keys.active(); err != nil {
+		return nil, err
+	}
+
+	t := NewTokenGenerator(legacyAccessSecret, legacyRefreshSecret, accessTTL, refreshTTL)
+	t.keys = keys
+	t.issuer = issuer
+	return t, nil
+}
+
+// NewTokenGeneratorFromConfig picks asymmetric signing when keys are configured
+// and falls back to the HS256 shared secrets otherwise.
+func NewTokenGeneratorFromConfig(cfg config.JWTConfig) (*TokenGenerator, error) {
+	if len(cfg.SigningKeys) == 0 {
+		return NewTokenGenerator(cfg.AccessSecret, cfg.RefreshSecret, cfg.AccessTTL, cfg.RefreshTTL).WithTenantAccessTTL(cfg.TenantAccessTTL), nil
+	}
+
+	keyConfigs := make([]KeyConfig, 0, len(cfg.SigningKeys))
+	for _, k := range cfg.SigningKeys {
+		keyConfigs = append(keyConfigs, KeyConfig{
+			KeyID:         k.KeyID,
+			Algorithm:     k.Algorithm,
+			PrivateKeyPEM: k.PrivateKeyPEM,
+			PublicKeyPEM:  k.PublicKeyPEM,
+		})
 	}
+
+	keys, err := NewKeySet(keyConfigs, cfg.ActiveKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("load jwt signing keys: %w", err)
+	}
+
+	t, err := NewTokenGeneratorWithKeySet(keys, cfg.Issuer, cfg.AccessSecret, cfg.RefreshSecret, cfg.AccessTTL, cfg.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.WithTenantAccessTTL(cfg.TenantAccessTTL), nil
+}
+
+// JWKS returns the public verification keys. It is empty in HS256-only mode.
+func (t *TokenGenerator) JWKS() JWKS {
+	return t.keys.JWKS()
 }
 
 type CustomClaims struct {
@@ -41,41 +111,140 @@ type CustomClaims struct {
 	FirstName  string `json:"first_name"`
 	LastName   string `json:"last_name"`
 	PictureURL string `json:"picture_url"`
+	// MFA is set once the session has passed a second factor.
+	MFA bool `json:"mfa,omitempty"`
+	// SSOTenantID is set when the session was started through that organization's SSO.
+	SSOTenantID string `json:"sso_tenant_id,omitempty"`
+	// APIKeyID is set when the request was authenticated with an organization
+	// API key instead of a JWT; it is never part of a signed token.
+	APIKeyID string `json:"-"`
+	TenantClaims
 	jwt.RegisteredClaims
 }
 
-func (t *TokenGenerator) GenerateTokens(userID, email, firstName, lastName, pictureURL string) (*TokenPair, error) {
+// TenantClaims are only present on organization-scoped access tokens issued by
+// the token exchange. MembershipVersion lets the middleware reject the token as
+// soon as the membership it was issued for changes.
+type TenantClaims struct {
+	TenantID          string   `json:"tenant_id,omitempty"`
+	TenantSlug        string   `json:"tenant_slug,omitempty"`
+	Role              string   `json:"role,omitempty"`
+	Permissions       []string `json:"permissions,omitempty"`
+	MembershipVersion int64    `json:"membership_version,omitempty"`
+}
+
+// IsTenantScoped reports whether the token was issued for a single organization.
+func (c *CustomClaims) IsTenantScoped() bool {
+	return c != nil && c.TenantID != ""
+}
+
+// HasPermission reports whether a tenant-scoped token grants the given RBAC permission code.
+func (c *CustomClaims) HasPermission(code string) bool {
+	if !c.IsTenantScoped() {
+		return false
+	}
+
+	for _, p := range c.Permissions {
+		if p == code {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenOption customizes a user token pair.
+type TokenOption func(*tokenOptions)
+
+type tokenOptions struct {
+	mfa         bool
+	ssoTenantID string
+}
+
+// WithMFA marks the session as having passed a second factor. The flag is also
+// stamped on the refresh token so it survives refreshes.
+func WithMFA() TokenOption {
+	return func(o *tokenOptions) {
+		o.mfa = true
+	}
+}
+
+// WithSSOTenant records that the session was started through an organization's
+// SSO, which organizations enforcing SSO-only login require.
+func WithSSOTenant(tenantID string) TokenOption {
+	return func(o *tokenOptions) {
+		o.ssoTenantID = tenantID
+	}
+}
+
+// refreshClaims only carries what is needed to reissue a session.
+type refreshClaims struct {
+	MFA         bool   `json:"mfa,omitempty"`
+	SSOTenantID string `json:"sso_tenant_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// RefreshSession is the validated content of a refresh token.
+type RefreshSession struct {
+	UserID      string
+	MFA         bool
+	SSOTenantID string
+}
+
+// Options returns the token options that reissue a session with the same guarantees.
+func (s *RefreshSession) Options() []TokenOption {
+	var opts []TokenOption
+	if s.MFA {
+		opts = append(opts, WithMFA())
+	}
+	if s.SSOTenantID != "" {
+		opts = append(opts, WithSSOTenant(s.SSOTenantID))
+	}
+	return opts
+}
+
+func (t *TokenGenerator) GenerateTokens(userID, email, firstName, lastName, pictureURL string, opts ...TokenOption) (*TokenPair, error) {
+	var options tokenOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	now := time.Now()
 
 	// Access Token
 	accessClaims := CustomClaims{
-		UserID:     userID,
-		Email:      email,
-		FirstName:  firstName,
-		LastName:   lastName,
-		PictureURL: pictureURL,
+		UserID:      userID,
+		Email:       email,
+		FirstName:   firstName,
+		LastName:    lastName,
+		PictureURL:  pictureURL,
+		MFA:         options.mfa,
+		SSOTenantID: options.ssoTenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
 			Subject:   userID,
 			ExpiresAt: jwt.NewNumericDate(now.Add(t.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	accessString, err := accessToken.SignedString(t.accessSecret)
+	accessString, err := t.sign(accessClaims, accessTokenType, t.accessSecret)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign access token: %w", err)
 	}
 
 	// Refresh Token
-	refreshClaims := jwt.RegisteredClaims{
-		Subject:   userID,
-		ExpiresAt: jwt.NewNumericDate(now.Add(t.refreshTTL)),
-		IssuedAt:  jwt.NewNumericDate(now),
+	refresh := refreshClaims{
+		MFA:         options.mfa,
+		SSOTenantID: options.ssoTenantID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.refreshTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims)
-	refreshString, err := refreshToken.SignedString(t.refreshSecret)
+	refreshString, err := t.sign(refresh, refreshTokenType, t.refreshSecret)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign refresh token: %w", err)
 	}
@@ -87,13 +256,48 @@ func (t *TokenGenerator) GenerateTokens(userID, email, firstName, lastName, pict
 	}, nil
 }
 
+// GenerateTenantAccessToken issues a short-lived access token bound to one
+// organization. No refresh token is issued: clients exchange their regular
+// access token again once it expires.
+func (t *TokenGenerator) GenerateTenantAccessToken(user *CustomClaims, scope TenantClaims) (*TokenPair, error) {
+	if user == nil || user.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+	if scope.TenantID == "" {
+		return nil, errors.New("tenant id is required for a tenant-scoped token")
+	}
+
+	now := time.Now()
+	claims := CustomClaims{
+		UserID:       user.UserID,
+		Email:        user.Email,
+		FirstName:    user.FirstName,
+		LastName:     user.LastName,
+		PictureURL:   user.PictureURL,
+		MFA:          user.MFA,
+		SSOTenantID:  user.SSOTenantID,
+		TenantClaims: scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    t.issuer,
+			Subject:   user.UserID,
+			ExpiresAt: jwt.NewNumericDate(now.Add(t.tenantTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	accessString, err := t.sign(claims, accessTokenType, t.accessSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign tenant access token: %w", err)
+	}
+
+	return &TokenPair{
+		AccessToken: accessString,
+		ExpiresIn:   int(t.tenantTTL.Seconds()),
+	}, nil
+}
+
 func (t *TokenGenerator) ValidateAccessToken(tokenString string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return t.accessSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, t.keyFunc(accessTokenType, t.accessSecret))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -111,24 +315,77 @@ func (t *TokenGenerator) ValidateAccessToken(tokenString string) (*CustomClaims,
 }
 
 func (t *TokenGenerator) ValidateRefreshToken(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return t.refreshSecret, nil
-	})
+	session, err := t.ValidateRefreshSession(tokenString)
+	if err != nil {
+		return "", err
+	}
+
+	return session.UserID, nil
+}
+
+// ValidateRefreshSession validates a refresh token and returns the session it
+// belongs to, including whether it was issued after a second factor.
+func (t *TokenGenerator) ValidateRefreshSession(tokenString string) (*RefreshSession, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &refreshClaims{}, t.keyFunc(refreshTokenType, t.refreshSecret))
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
-			return "", ErrExpiredToken
+			return nil, ErrExpiredToken
 		}
-		return "", err
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*refreshClaims)
 	if !ok || !token.Valid {
-		return "", ErrInvalidToken
+		return nil, ErrInvalidToken
 	}
 
-	return claims.Subject, nil
+	return &RefreshSession{UserID: claims.Subject, MFA: claims.MFA, SSOTenantID: claims.SSOTenantID}, nil
+}
+
+func (t *TokenGenerator) sign(claims jwt.Claims, tokenType string, secret []byte) (string, error) {
+	if t.keys == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	}
+
+	key, err := t.keys.active()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.id
+	token.Header["typ"] = tokenType
+	return token.SignedString(key.private)
+}
+
+// keyFunc resolves the verification key for a token. Asymmetric tokens are
+// looked up by kid and must carry the expected typ header, since access and
+// refresh tokens share the same signing key; HS256 tokens are accepted only
+// while the matching shared secret is still configured.
+func (t *TokenGenerator) keyFunc(tokenType string, secret []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if len(secret) == 0 {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return secret, nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+			kid, _ := token.Header["kid"].(string)
+			key, ok := t.keys.lookup(kid)
+			if !ok {
+				return nil, fmt.Errorf("%w: %q", ErrUnknownKeyID, kid)
+			}
+			if key.method.Alg() != token.Method.Alg() {
+				return nil, ErrKeyAlgorithmMismatch
+			}
+			if typ, _ := token.Header["typ"].(string); typ != tokenType {
+				return nil, ErrInvalidToken
+			}
+			return key.public, nil
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+	}
 }
@@ -0,0 +1,281 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func rsaKeyConfig(t *testing.T, kid string) (KeyConfig, KeyConfig) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key failed: %v", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal private key failed: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key failed: %v", err)
+	}
+
+	signing := KeyConfig{
+		KeyID:         kid,
+		Algorithm:     AlgorithmRS256,
+		PrivateKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+	}
+	verifyOnly := KeyConfig{
+		KeyID:        kid,
+		Algorithm:    AlgorithmRS256,
+		PublicKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	}
+
+	return signing, verifyOnly
+}
+
+func ecKeyConfig(t *testing.T, kid string) KeyConfig {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key failed: %v", err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal ec key failed: %v", err)
+	}
+
+	return KeyConfig{
+		KeyID:         kid,
+		Algorithm:     AlgorithmES256,
+		PrivateKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})),
+	}
+}
+
+func newKeySetGenerator(t *testing.T, configs []KeyConfig, activeKeyID string) *TokenGenerator {
+	t.Helper()
+
+	keys, err := NewKeySet(configs, activeKeyID)
+	if err != nil {
+		t.Fatalf("new key set failed: %v", err)
+	}
+
+	gen, err := NewTokenGeneratorWithKeySet(keys, "https://api.bowerbird.test", "", "", 15*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("new token generator failed: %v", err)
+	}
+
+	return gen
+}
+
+func TestTokenGeneratorSignsWithActiveAsymmetricKey(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		key  KeyConfig
+	}{
+		{name: "RS256", key: func() KeyConfig { k, _ := rsaKeyConfig(t, "rsa-1"); return k }()},
+		{name: "ES256", key: ecKeyConfig(t, "ec-1")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gen := newKeySetGenerator(t, []KeyConfig{tc.key}, "")
+
+			pair, err := gen.GenerateTokens("user-1", "a@b.co", "Ana", "Perez", "")
+			if err != nil {
+				t.Fatalf("generate tokens failed: %v", err)
+			}
+
+			claims, err := gen.ValidateAccessToken(pair.AccessToken)
+			if err != nil {
+				t.Fatalf("validate access token failed: %v", err)
+			}
+			if claims.UserID != "user-1" || claims.Issuer != "https://api.bowerbird.test" {
+				t.Fatalf("unexpected claims: %+v", claims)
+			}
+
+			subject, err := gen.ValidateRefreshToken(pair.RefreshToken)
+			if err != nil {
+				t.Fatalf("validate refresh token failed: %v", err)
+			}
+			if subject != "user-1" {
+				t.Fatalf("expected subject user-1, got %q", subject)
+			}
+		})
+	}
+}
+
+func TestTokenGeneratorRejectsRefreshTokenAsAccessToken(t *testing.T) {
+	signing, _ := rsaKeyConfig(t, "rsa-1")
+	gen := newKeySetGenerator(t, []KeyConfig{signing}, "rsa-1")
+
+	pair, err := gen.GenerateTokens("user-1", "a@b.co", "", "", "")
+	if err != nil {
+		t.Fatalf("generate tokens failed: %v", err)
+	}
+
+	if _, err := gen.ValidateAccessToken(pair.RefreshToken); err == nil {
+		t.Fatal("expected refresh token to be rejected as access token")
+	}
+	if _, err := gen.ValidateRefreshToken(pair.AccessToken); err == nil {
+		t.Fatal("expected access token to be rejected as refresh token")
+	}
+}
+
+func TestTokenGeneratorKeepsRetiredKeysValidAfterRotation(t *testing.T) {
+	oldSigning, oldPublic := rsaKeyConfig(t, "2026-01")
+	newSigning := ecKeyConfig(t, "2026-07")
+
+	before := newKeySetGenerator(t, []KeyConfig{oldSigning}, "2026-01")
+	pair, err := before.GenerateTokens("user-1", "a@b.co", "", "", "")
+	if err != nil {
+		t.Fatalf("generate tokens failed: %v", err)
+	}
+
+	after := newKeySetGenerator(t, []KeyConfig{newSigning, oldPublic}, "2026-07")
+	if _, err := after.ValidateAccessToken(pair.AccessToken); err != nil {
+		t.Fatalf("expected token signed by retired key to validate, got %v", err)
+	}
+
+	dropped := newKeySetGenerator(t, []KeyConfig{newSigning}, "2026-07")
+	if _, err := dropped.ValidateAccessToken(pair.AccessToken); !errors.Is(err, ErrUnknownKeyID) {
+		t.Fatalf("expected ErrUnknownKeyID once the old key is removed, got %v", err)
+	}
+}
+
+func TestTokenGeneratorAcceptsLegacyHS256TokensDuringMigration(t *testing.T) {
+	legacy := NewTokenGenerator("access-secret", "refresh-secret", 15*time.Minute, time.Hour)
+	pair, err := legacy.GenerateTokens("user-1", "a@b.co", "", "", "")
+	if err != nil {
+		t.Fatalf("generate tokens failed: %v", err)
+	}
+
+	signing, _ := rsaKeyConfig(t, "rsa-1")
+	keys, err := NewKeySet([]KeyConfig{signing}, "rsa-1")
+	if err != nil {
+		t.Fatalf("new key set failed: %v", err)
+	}
+
+	withSecrets, err := NewTokenGeneratorWithKeySet(keys, "", "access-secret", "refresh-secret", 15*time.Minute, time.Hour)
+	if err != nil {
+		t.Fatalf("new token generator failed: %v", err)
+	}
+	if _, err := withSecrets.ValidateAccessToken(pair.AccessToken); err != nil {
+		t.Fatalf("expected legacy token to validate while secret is configured, got %v", err)
+	}
+
+	withoutSecrets := newKeySetGenerator(t, []KeyConfig{signing}, "rsa-1")
+	if _, err := withoutSecrets.ValidateAccessToken(pair.AccessToken); err == nil {
+		t.Fatal("expected legacy token to be rejected once the secret is removed")
+	}
+}
+
+func TestNewKeySetRejectsVerificationOnlyActiveKey(t *testing.T) {
+	_, public := rsaKeyConfig(t, "rsa-1")
+
+	if _, err := NewKeySet([]KeyConfig{public}, "rsa-1"); !errors.Is(err, ErrNoActiveSigningKey) {
+		t.Fatalf("expected ErrNoActiveSigningKey, got %v", err)
+	}
+}
+
+func TestJWKSHandlerPublishesAllVerificationKeys(t *testing.T) {
+	_, oldPublic := rsaKeyConfig(t, "2026-01")
+	gen := newKeySetGenerator(t, []KeyConfig{ecKeyConfig(t, "2026-07"), oldPublic}, "2026-07")
+
+	rec := httptest.NewRecorder()
+	JWKSHandler(gen).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var doc JWKS
+	if err := json.NewDecoder(rec.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode jwks failed: %v", err)
+	}
+	if len(doc.Keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(doc.Keys))
+	}
+
+	ec, rsaKey := doc.Keys[0], doc.Keys[1]
+	if ec.KeyID != "2026-07" || ec.KeyType != "EC" || ec.Curve != "P-256" || ec.X == "" || ec.Y == "" {
+		t.Fatalf("unexpected ec jwk: %+v", ec)
+	}
+	if rsaKey.KeyID != "2026-01" || rsaKey.KeyType != "RSA" || rsaKey.Algorithm != AlgorithmRS256 || rsaKey.N == "" || rsaKey.E != "AQAB" {
+		t.Fatalf("unexpected rsa jwk: %+v", rsaKey)
+	}
+}
+
+func TestTokenGeneratorCarriesMFAAcrossRefresh(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+
+	pair, err := gen.GenerateTokens("user-1", "a@b.co", "", "", "", WithMFA())
+	if err != nil {
+		t.Fatalf("generate tokens failed: %v", err)
+	}
+
+	claims, err := gen.ValidateAccessToken(pair.AccessToken)
+	if err != nil || !claims.MFA {
+		t.Fatalf("expected mfa access token, got %+v (%v)", claims, err)
+	}
+
+	session, err := gen.ValidateRefreshSession(pair.RefreshToken)
+	if err != nil || !session.MFA || session.UserID != "user-1" {
+		t.Fatalf("expected mfa refresh session, got %+v (%v)", session, err)
+	}
+
+	tenantPair, err := gen.GenerateTenantAccessToken(claims, TenantClaims{TenantID: "tenant-1"})
+	if err != nil {
+		t.Fatalf("generate tenant token failed: %v", err)
+	}
+	if tenantClaims, err := gen.ValidateAccessToken(tenantPair.AccessToken); err != nil || !tenantClaims.MFA {
+		t.Fatalf("expected tenant token to keep the mfa flag, got %+v (%v)", tenantClaims, err)
+	}
+
+	plain, err := gen.GenerateTokens("user-1", "a@b.co", "", "", "")
+	if err != nil {
+		t.Fatalf("generate tokens failed: %v", err)
+	}
+	if session, err := gen.ValidateRefreshSession(plain.RefreshToken); err != nil || session.MFA {
+		t.Fatalf("expected session without mfa, got %+v (%v)", session, err)
+	}
+}
+
+func TestJWKPublicKeyRoundTrip(t *testing.T) {
+	signing, _ := rsaKeyConfig(t, "rsa-1")
+	gen := newKeySetGenerator(t, []KeyConfig{signing, ecKeyConfig(t, "ec-1")}, "rsa-1")
+
+	for _, jwk := range gen.JWKS().Keys {
+		key, ok := gen.keys.lookup(jwk.KeyID)
+		if !ok {
+			t.Fatalf("missing key %q", jwk.KeyID)
+		}
+
+		public, err := jwk.PublicKey()
+		if err != nil {
+			t.Fatalf("decode %s jwk failed: %v", jwk.KeyType, err)
+		}
+
+		type equaler interface{ Equal(crypto.PublicKey) bool }
+		if !public.(equaler).Equal(key.public) {
+			t.Fatalf("decoded %s key does not match the original", jwk.KeyType)
+		}
+	}
+
+	if _, err := (JWK{KeyType: "oct"}).PublicKey(); err == nil {
+		t.Fatal("expected symmetric jwk to be rejected")
+	}
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+var (
+	ErrUnknownKeyID         = errors.New("unknown signing key id")
+	ErrNoActiveSigningKey   = errors.New("no active signing key configured")
+	ErrUnsupportedKeyAlg    = errors.New("unsupported signing key algorithm")
+	ErrInvalidKeyMaterial   = errors.New("invalid signing key material")
+	ErrDuplicateKeyID       = errors.New("duplicate signing key id")
+	ErrKeyAlgorithmMismatch = errors.New("signing key does not match token algorithm")
+)
+
+// KeyConfig describes one asymmetric key as loaded from configuration.
+// Keys without a private part are kept for verification only, which is how
+// retired keys stay valid until the tokens they signed expire.
+type KeyConfig struct {
+	KeyID         string
+	Algorithm     string
+	PrivateKeyPEM string
+	PublicKeyPEM  string
+}
+
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// KeySet holds the active signing key and every key still accepted for verification.
+type KeySet struct {
+	activeKeyID string
+	keys        map[string]*signingKey
+	order       []string
+}
+
+// NewKeySet parses the configured keys. When activeKeyID is empty and exactly one
+// key carries a private part, that key becomes the active one.
+func NewKeySet(configs []KeyConfig, activeKeyID string) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*signingKey, len(configs))}
+
+	var signers []string
+	for _, cfg := range configs {
+		key, err := parseKeyConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("parse key %q: %w", cfg.KeyID, err)
+		}
+		if _, exists := set.keys[key.id]; exists {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateKeyID, key.id)
+		}
+
+		set.keys[key.id] = key
+		set.order = append(set.order, key.id)
+		if key.private != nil {
+			signers = append(signers, key.id)
+		}
+	}
+
+	if activeKeyID == "" && len(signers) == 1 {
+		activeKeyID = signers[0]
+	}
+
+	if activeKeyID != "" {
+		active, ok := set.keys[activeKeyID]
+		if !ok {
+			return nil, fmt.Errorf("active key %q: %w", activeKeyID, ErrUnknownKeyID)
+		}
+		if active.private == nil {
+			return nil, fmt.Errorf("active key %q has no private key: %w", activeKeyID, ErrNoActiveSigningKey)
+		}
+	}
+
+	set.activeKeyID = activeKeyID
+	return set, nil
+}
+
+// ActiveKeyID returns the kid stamped on newly issued tokens.
+func (s *KeySet) ActiveKeyID() string {
+	return s.activeKeyID
+}
+
+func (s *KeySet) active() (*signingKey, error) {
+	if s == nil || s.activeKeyID == "" {
+		return nil, ErrNoActiveSigningKey
+	}
+
+	return s.keys[s.activeKeyID], nil
+}
+
+func (s *KeySet) lookup(kid string) (*signingKey, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func parseKeyConfig(cfg KeyConfig) (*signingKey, error) {
+	if cfg.KeyID == "" {
+		return nil, fmt.Errorf("kid is required: %w", ErrInvalidKeyMaterial)
+	}
+
+	var method jwt.SigningMethod
+	switch cfg.Algorithm {
+	case AlgorithmRS256:
+		method = jwt.SigningMethodRS256
+	case AlgorithmES256:
+		method = jwt.SigningMethodES256
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKeyAlg, cfg.Algorithm)
+	}
+
+	key := &signingKey{id: cfg.KeyID, method: method}
+
+	if cfg.PrivateKeyPEM != "" {
+		private, err := parsePrivateKey(cfg.PrivateKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		key.private = private
+		key.public = private.Public()
+	}
+
+	if key.public == nil && cfg.PublicKeyPEM != "" {
+		public, err := parsePublicKey(cfg.PublicKeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		key.public = public
+	}
+
+	if key.public == nil {
+		return nil, fmt.Errorf("private or public key is required: %w", ErrInvalidKeyMaterial)
+	}
+
+	if err := checkKeyType(cfg.Algorithm, key.public); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func checkKeyType(alg string, public crypto.PublicKey) error {
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		if alg != AlgorithmRS256 {
+			return fmt.Errorf("rsa key used with %s: %w", alg, ErrKeyAlgorithmMismatch)
+		}
+		if k.N.BitLen() < 2048 {
+			return fmt.Errorf("rsa key must be at least 2048 bits: %w", ErrInvalidKeyMaterial)
+		}
+	case *ecdsa.PublicKey:
+		if alg != AlgorithmES256 {
+			return fmt.Errorf("ecdsa key used with %s: %w", alg, ErrKeyAlgorithmMismatch)
+		}
+		if k.Curve != elliptic.P256() {
+			return fmt.Errorf("ES256 requires a P-256 key: %w", ErrInvalidKeyMaterial)
+		}
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedKeyAlg, public)
+	}
+
+	return nil
+}
+
+func parsePrivateKey(encoded string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, fmt.Errorf("decode private key pem: %w", ErrInvalidKeyMaterial)
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyAlg, key)
+		}
+		return signer, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("parse private key: %w", ErrInvalidKeyMaterial)
+}
+
+func parsePublicKey(encoded string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, fmt.Errorf("decode public key pem: %w", ErrInvalidKeyMaterial)
+	}
+
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("parse public key: %w", ErrInvalidKeyMaterial)
+}
@@ -2,16 +2,79 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
+
+	"github.com/bowerbird/internal/platform/tenant"
 )
 
 type contextKey string
 
 const userContextKey = contextKey("user_claims")
 
-func Middleware(tokenGen *TokenGenerator) func(http.Handler) http.Handler {
+// ErrMembershipNotFound is returned by a MembershipVerifier when the user no
+// longer belongs to the organization.
+var ErrMembershipNotFound = errors.New("tenant membership not found")
+
+// Membership is the current state of a user's membership in an organization,
+// including the login policies the organization enforces.
+type Membership struct {
+	TenantID string
+	// Version is bumped on every role change or removal, so comparing it with
+	// the one stamped on a tenant-scoped token revokes the token immediately.
+	Version     int64
+	MFARequired bool
+	SSOOnly     bool
+}
+
+// MembershipVerifier resolves a membership by tenant id or slug on every
+// request that targets an organization.
+type MembershipVerifier interface {
+	VerifyMembership(ctx context.Context, userID, tenantRef string) (*Membership, error)
+}
+
+// APIKeyAuthenticator resolves an organization API key into tenant-scoped
+// claims whose permissions are the key's scopes.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, token string) (*CustomClaims, error)
+}
+
+// apiKeyTokenPrefix distinguishes API keys from JWTs in the Authorization header.
+const apiKeyTokenPrefix = "bbk_"
+
+type MiddlewareOption func(*middlewareOptions)
+
+type middlewareOptions struct {
+	memberships MembershipVerifier
+	apiKeys     APIKeyAuthenticator
+}
+
+// WithMembershipVerifier checks tenant-scoped tokens against the current membership on every request.
+func WithMembershipVerifier(verifier MembershipVerifier) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.memberships = verifier
+	}
+}
+
+// WithAPIKeys accepts "Authorization: Bearer bbk_..." API keys next to JWTs.
+func WithAPIKeys(authenticator APIKeyAuthenticator) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.apiKeys = authenticator
+	}
+}
+
+func Middleware(tokenGen *TokenGenerator, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	var options middlewareOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	return func(next http.Handler) http.Handler {
+		// API keys act with their creator's user ID, so they are only let
+		// through to routes that check a permission against the key's scopes.
+		_, acceptsAPIKeys := next.(permissionGate)
+
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
@@ -26,19 +89,143 @@ func Middleware(tokenGen *TokenGenerator) func(http.Handler) http.Handler {
 			}
 
 			tokenString := parts[1]
-			claims, err := tokenGen.ValidateAccessToken(tokenString)
+			var claims *CustomClaims
+			var err error
+			if options.apiKeys != nil && strings.HasPrefix(tokenString, apiKeyTokenPrefix) {
+				claims, err = options.apiKeys.AuthenticateAPIKey(r.Context(), tokenString)
+			} else {
+				claims, err = tokenGen.ValidateAccessToken(tokenString)
+			}
 			if err != nil {
 				http.Error(w, "invalid token: "+err.Error(), http.StatusUnauthorized)
 				return
 			}
+			if claims.APIKeyID != "" && !acceptsAPIKeys {
+				http.Error(w, "api keys are not accepted on this route", http.StatusForbidden)
+				return
+			}
+
+			ctx := WithClaims(r.Context(), claims)
+
+			if claims.IsTenantScoped() {
+				// The token already names the organization; an X-Tenant-ID header
+				// pointing elsewhere is rejected rather than silently overridden.
+				if requested, err := tenant.TenantIDFromContext(ctx); err == nil && requested != claims.TenantID && requested != claims.TenantSlug {
+					http.Error(w, "token is scoped to a different organization", http.StatusForbidden)
+					return
+				}
+
+				// API keys belong to the organization, not to a membership.
+				if options.memberships != nil && claims.APIKeyID == "" {
+					membership, err := options.memberships.VerifyMembership(ctx, claims.UserID, claims.TenantID)
+					if err != nil && !errors.Is(err, ErrMembershipNotFound) {
+						http.Error(w, "failed to verify membership", http.StatusInternalServerError)
+						return
+					}
+					if err != nil || membership.Version != claims.MembershipVersion {
+						http.Error(w, "invalid token: membership has changed", http.StatusUnauthorized)
+						return
+					}
+					if reason := membership.policyViolation(claims); reason != "" {
+						http.Error(w, reason, http.StatusForbidden)
+						return
+					}
+				}
+
+				ctx = tenant.WithTenantID(ctx, claims.TenantID)
+			} else if requested, err := tenant.TenantIDFromContext(ctx); err == nil && options.memberships != nil {
+				// A session token sent with X-Tenant-ID must meet the same
+				// policies as the token exchange, or it would sidestep them.
+				membership, err := options.memberships.VerifyMembership(ctx, claims.UserID, requested)
+				if err != nil && !errors.Is(err, ErrMembershipNotFound) {
+					http.Error(w, "failed to verify membership", http.StatusInternalServerError)
+					return
+				}
+				if err == nil {
+					if reason := membership.policyViolation(claims); reason != "" {
+						http.Error(w, reason, http.StatusForbidden)
+						return
+					}
+				}
+			}
 
-			ctx := context.WithValue(r.Context(), userContextKey, claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+// policyViolation explains why the organization's login policies reject the
+// session, or returns an empty string when they admit it. An SSO session
+// proves identity only to the organization whose provider issued it.
+func (m *Membership) policyViolation(claims *CustomClaims) string {
+	switch {
+	case claims.SSOTenantID != "" && claims.SSOTenantID != m.TenantID:
+		return "this session was started through another organization's sso"
+	case m.SSOOnly && claims.SSOTenantID != m.TenantID:
+		return "this organization requires signing in through its sso"
+	case m.MFARequired && !claims.MFA:
+		return "this organization requires multi-factor authentication"
+	}
+	return ""
+}
+
+// WithClaims attaches authenticated claims to the context, as the middleware does.
+func WithClaims(ctx context.Context, claims *CustomClaims) context.Context {
+	return context.WithValue(ctx, userContextKey, claims)
+}
+
 func ClaimsFromContext(ctx context.Context) (*CustomClaims, bool) {
 	claims, ok := ctx.Value(userContextKey).(*CustomClaims)
 	return claims, ok
 }
+
+// RequirePermission only lets through tenant-scoped requests whose token grants
+// the permission code. Wrapping a handler in it is also what opts a route in to
+// API keys, whose scopes are then checked like a role's permissions.
+func RequirePermission(code string, opts ...PermissionOption) func(http.Handler) http.Handler {
+	gate := permissionGate{code: code}
+	for _, opt := range opts {
+		opt(&gate)
+	}
+
+	return func(next http.Handler) http.Handler {
+		gate.next = next
+		return gate
+	}
+}
+
+type PermissionOption func(*permissionGate)
+
+// AllowSessionTokens also lets through session tokens, which carry no
+// permissions and reach an organization through X-Tenant-ID. It keeps routes
+// every member used before RBAC open to them while API keys and
+// tenant-scoped tokens are held to the permission.
+func AllowSessionTokens() PermissionOption {
+	return func(g *permissionGate) {
+		g.allowSessions = true
+	}
+}
+
+type permissionGate struct {
+	code          string
+	allowSessions bool
+	next          http.Handler
+}
+
+func (g permissionGate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	claims, ok := ClaimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if g.allowSessions && !claims.IsTenantScoped() {
+		g.next.ServeHTTP(w, r)
+		return
+	}
+	if !claims.HasPermission(g.code) {
+		http.Error(w, "missing permission: "+g.code, http.StatusForbidden)
+		return
+	}
+
+	g.next.ServeHTTP(w, r)
+}
@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/platform/tenant"
+)
+
+type stubMembershipVerifier struct {
+	version     int64
+	mfaRequired bool
+	ssoOnly     bool
+	err         error
+}
+
+func (s stubMembershipVerifier) VerifyMembership(context.Context, string, string) (*Membership, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &Membership{TenantID: "tenant-1", Version: s.version, MFARequired: s.mfaRequired, SSOOnly: s.ssoOnly}, nil
+}
+
+func issueTenantToken(t *testing.T, gen *TokenGenerator) string {
+	t.Helper()
+
+	pair, err := gen.GenerateTenantAccessToken(&CustomClaims{UserID: "user-1", Email: "a@b.co"}, TenantClaims{
+		TenantID:          "tenant-1",
+		TenantSlug:        "acme",
+		Role:              "ADMIN",
+		Permissions:       []string{"users:read", "settings:write"},
+		MembershipVersion: 3,
+	})
+	if err != nil {
+		t.Fatalf("generate tenant token failed: %v", err)
+	}
+
+	return pair.AccessToken
+}
+
+func serveWithToken(handler http.Handler, token, tenantHeader string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/organization", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if tenantHeader != "" {
+		req = req.WithContext(tenant.WithTenantID(req.Context(), tenantHeader))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareExposesTenantClaims(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	token := issueTenantToken(t, gen)
+
+	var gotClaims *CustomClaims
+	var gotTenant string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClaims, _ = ClaimsFromContext(r.Context())
+		gotTenant, _ = tenant.TenantIDFromContext(r.Context())
+	})
+
+	rec := serveWithToken(Middleware(gen, WithMembershipVerifier(stubMembershipVerifier{version: 3}))(next), token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if gotTenant != "tenant-1" {
+		t.Fatalf("expected tenant from token in context, got %q", gotTenant)
+	}
+	if gotClaims == nil || gotClaims.Role != "ADMIN" || !gotClaims.HasPermission("settings:write") || gotClaims.HasPermission("roles:write") {
+		t.Fatalf("unexpected claims: %+v", gotClaims)
+	}
+}
+
+func TestMiddlewareRejectsTenantTokenAfterMembershipChange(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	token := issueTenantToken(t, gen)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	rec := serveWithToken(Middleware(gen, WithMembershipVerifier(stubMembershipVerifier{version: 4}))(next), token, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after version bump, got %d", rec.Code)
+	}
+
+	rec = serveWithToken(Middleware(gen, WithMembershipVerifier(stubMembershipVerifier{err: ErrMembershipNotFound}))(next), token, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 after membership removal, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewareRejectsTenantHeaderMismatch(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	token := issueTenantToken(t, gen)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := Middleware(gen)(next)
+
+	if rec := serveWithToken(handler, token, "other-tenant"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a different tenant header, got %d", rec.Code)
+	}
+	if rec := serveWithToken(handler, token, "acme"); rec.Code != http.StatusOK {
+		t.Fatalf("expected slug header to match the token, got %d", rec.Code)
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tenantToken := issueTenantToken(t, gen)
+	userPair, err := gen.GenerateTokens("user-1", "a@b.co", "", "", "")
+	if err != nil {
+		t.Fatalf("generate tokens failed: %v", err)
+	}
+
+	allowed := Middleware(gen)(RequirePermission("users:read")(next))
+	denied := Middleware(gen)(RequirePermission("roles:write")(next))
+
+	if rec := serveWithToken(allowed, tenantToken, ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with granted permission, got %d", rec.Code)
+	}
+	if rec := serveWithToken(denied, tenantToken, ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 without permission, got %d", rec.Code)
+	}
+	if rec := serveWithToken(allowed, userPair.AccessToken, ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a token without tenant scope, got %d", rec.Code)
+	}
+}
+
+type stubAPIKeyAuthenticator struct {
+	claims *CustomClaims
+	err    error
+}
+
+func (s stubAPIKeyAuthenticator) AuthenticateAPIKey(context.Context, string) (*CustomClaims, error) {
+	return s.claims, s.err
+}
+
+func TestMiddlewareAcceptsAPIKeys(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	apiKeys := stubAPIKeyAuthenticator{claims: &CustomClaims{
+		UserID:       "user-1",
+		APIKeyID:     "key-1",
+		TenantClaims: TenantClaims{TenantID: "tenant-1", TenantSlug: "acme", Permissions: []string{"users:read"}},
+	}}
+	// The verifier would reject any token: the authenticator, not the middleware, checks the creator's membership.
+	memberships := stubMembershipVerifier{err: ErrMembershipNotFound}
+
+	var gotTenant string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant, _ = tenant.TenantIDFromContext(r.Context())
+	})
+	handler := Middleware(gen, WithMembershipVerifier(memberships), WithAPIKeys(apiKeys))(RequirePermission("users:read")(next))
+
+	rec := serveWithToken(handler, "bbk_key-1_secret", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if gotTenant != "tenant-1" {
+		t.Fatalf("expected tenant from api key in context, got %q", gotTenant)
+	}
+
+	withoutAPIKeys := Middleware(gen)(next)
+	if rec := serveWithToken(withoutAPIKeys, "bbk_key-1_secret", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 when api keys are not enabled, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewareRejectsAPIKeysOnRoutesWithoutPermissionGate(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	apiKeys := stubAPIKeyAuthenticator{claims: &CustomClaims{
+		UserID:       "user-1",
+		APIKeyID:     "key-1",
+		TenantClaims: TenantClaims{TenantID: "tenant-1", TenantSlug: "acme", Permissions: []string{"users:read"}},
+	}}
+	called := false
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true })
+	middleware := Middleware(gen, WithAPIKeys(apiKeys))
+
+	if rec := serveWithToken(middleware(next), "bbk_key-1_secret", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 on a route without a permission gate, got %d", rec.Code)
+	}
+	if rec := serveWithToken(middleware(RequirePermission("roles:write")(next)), "bbk_key-1_secret", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a scope the key lacks, got %d", rec.Code)
+	}
+	if called {
+		t.Fatal("handler must not run for a rejected api key")
+	}
+
+	// Session tokens keep working on ungated routes.
+	if rec := serveWithToken(middleware(next), issueTenantToken(t, gen), ""); rec.Code != http.StatusOK || !called {
+		t.Fatalf("expected session token to pass an ungated route, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewareEnforcesTenantMFAPolicy(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := Middleware(gen, WithMembershipVerifier(stubMembershipVerifier{version: 3, mfaRequired: true}))(next)
+
+	if rec := serveWithToken(handler, issueTenantToken(t, gen), ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a tenant token without mfa, got %d", rec.Code)
+	}
+
+	session, err := gen.GenerateTokens("user-1", "a@b.co", "Ana", "", "")
+	if err != nil {
+		t.Fatalf("generate session failed: %v", err)
+	}
+	if rec := serveWithToken(handler, session.AccessToken, "acme"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a session without mfa sent with X-Tenant-ID, got %d", rec.Code)
+	}
+	if rec := serveWithToken(handler, session.AccessToken, ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected a session without X-Tenant-ID to pass, got %d", rec.Code)
+	}
+
+	withMFA, err := gen.GenerateTokens("user-1", "a@b.co", "Ana", "", "", WithMFA())
+	if err != nil {
+		t.Fatalf("generate mfa session failed: %v", err)
+	}
+	if rec := serveWithToken(handler, withMFA.AccessToken, "acme"); rec.Code != http.StatusOK {
+		t.Fatalf("expected a session with mfa to pass, got %d", rec.Code)
+	}
+}
+
+func TestMiddlewareEnforcesTenantSSOPolicy(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := Middleware(gen, WithMembershipVerifier(stubMembershipVerifier{version: 3, ssoOnly: true}))(next)
+
+	if rec := serveWithToken(handler, issueTenantToken(t, gen), ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a tenant token without sso, got %d", rec.Code)
+	}
+
+	password, err := gen.GenerateTokens("user-1", "a@b.co", "Ana", "", "")
+	if err != nil {
+		t.Fatalf("generate session failed: %v", err)
+	}
+	if rec := serveWithToken(handler, password.AccessToken, "acme"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a password session sent with X-Tenant-ID, got %d", rec.Code)
+	}
+
+	otherSSO, err := gen.GenerateTokens("user-1", "a@b.co", "Ana", "", "", WithSSOTenant("tenant-2"))
+	if err != nil {
+		t.Fatalf("generate sso session failed: %v", err)
+	}
+	open := Middleware(gen, WithMembershipVerifier(stubMembershipVerifier{version: 3}))(next)
+	if rec := serveWithToken(open, otherSSO.AccessToken, "acme"); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for another organization's sso session, got %d", rec.Code)
+	}
+
+	ownSSO, err := gen.GenerateTokens("user-1", "a@b.co", "Ana", "", "", WithSSOTenant("tenant-1"))
+	if err != nil {
+		t.Fatalf("generate sso session failed: %v", err)
+	}
+	if rec := serveWithToken(handler, ownSSO.AccessToken, "acme"); rec.Code != http.StatusOK {
+		t.Fatalf("expected the organization's own sso session to pass, got %d", rec.Code)
+	}
+}
+
+func TestRequirePermissionAllowSessionTokens(t *testing.T) {
+	gen := NewTokenGenerator("access", "refresh", 15*time.Minute, time.Hour)
+	apiKeys := stubAPIKeyAuthenticator{claims: &CustomClaims{
+		UserID:       "user-1",
+		APIKeyID:     "key-1",
+		TenantClaims: TenantClaims{TenantID: "tenant-1", TenantSlug: "acme", Permissions: []string{"users:read"}},
+	}}
+	next := http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	handler := Middleware(gen, WithAPIKeys(apiKeys))(RequirePermission("roles:write", AllowSessionTokens())(next))
+
+	session, err := gen.GenerateTokens("user-1", "a@b.co", "", "", "")
+	if err != nil {
+		t.Fatalf("generate session token failed: %v", err)
+	}
+	if rec := serveWithToken(handler, session.AccessToken, "tenant-1"); rec.Code != http.StatusOK {
+		t.Fatalf("expected a session token to pass, got %d", rec.Code)
+	}
+	if rec := serveWithToken(handler, issueTenantToken(t, gen), ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for a tenant-scoped token without the permission, got %d", rec.Code)
+	}
+	if rec := serveWithToken(handler, "bbk_key-1_secret", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected 403 for an api key without the scope, got %d", rec.Code)
+	}
+}
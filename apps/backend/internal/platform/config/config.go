@@ -14,33 +14,64 @@ import (
 )
 
 type Config struct {
-	AppEnv                        string    `json:"app_env"`
-	Port                          string    `json:"port"`
-	DatabaseURL                   string    `json:"database_url"`
-	SQSQueueURL                   string    `json:"sqs_queue_url"`
-	EventBridgeQueueURL           string    `json:"eventbridge_queue_url"`
-	EventBusName                  string    `json:"event_bus_name"`
-	S3BucketName                  string    `json:"s3_bucket_name"`
-	S3PresignEndpointURL          string    `json:"s3_presign_endpoint_url"`
-	AWSRegion                     string    `json:"aws_region"`
-	AWSEndpointURL                string    `json:"aws_endpoint_url"`
-	AWSAccessKeyID                string    `json:"aws_access_key_id"`
-	AWSSecretAccessKey            string    `json:"aws_secret_access_key"`
-	SSMParameterName              string    `json:"ssm_parameter_name"`
-	EnableLocalEventLoop          bool      `json:"enable_local_event_loop"`
-	AllowedOrigins                string    `json:"allowed_origins"`
-	Debug                         bool      `json:"debug"`
-	GoogleClientID                string    `json:"google_client_id"`
-	GoogleClientSecret            string    `json:"google_client_secret"`
-	MicrosoftClientID             string    `json:"microsoft_client_id"`
-	MicrosoftClientSecret         string    `json:"microsoft_client_secret"`
-	GeminiAPIKey                  string    `json:"gemini_api_key"`
-	GeminiModel                   string    `json:"gemini_model"`
-	GeminiEndpoint                string    `json:"gemini_endpoint"`
-	InboxCredentialsEncryptionKey string    `json:"inbox_credentials_encryption_key"`
-	FrontendURL                   string    `json:"frontend_url"`
-	BackendURL                    string    `json:"backend_url"`
-	JWT                           JWTConfig `json:"-"`
+	AppEnv                        string                 `json:"app_env"`
+	Port                          string                 `json:"port"`
+	DatabaseURL                   string                 `json:"database_url"`
+	SQSQueueURL                   string                 `json:"sqs_queue_url"`
+	EventBridgeQueueURL           string                 `json:"eventbridge_queue_url"`
+	EventBusName                  string                 `json:"event_bus_name"`
+	S3BucketName                  string                 `json:"s3_bucket_name"`
+	S3PresignEndpointURL          string                 `json:"s3_presign_endpoint_url"`
+	AWSRegion                     string                 `json:"aws_region"`
+	AWSEndpointURL                string                 `json:"aws_endpoint_url"`
+	AWSAccessKeyID                string                 `json:"aws_access_key_id"`
+	AWSSecretAccessKey            string                 `json:"aws_secret_access_key"`
+	SSMParameterName              string                 `json:"ssm_parameter_name"`
+	EnableLocalEventLoop          bool                   `json:"enable_local_event_loop"`
+	AllowedOrigins                string                 `json:"allowed_origins"`
+	Debug                         bool                   `json:"debug"`
+	LocalAuthEnabled              bool                   `json:"local_auth_enabled"`
+	RequireEmailVerification      bool                   `json:"require_email_verification"`
+	GoogleClientID                string                 `json:"google_client_id"`
+	GoogleClientSecret            string                 `json:"google_client_secret"`
+	MicrosoftClientID             string                 `json:"microsoft_client_id"`
+	MicrosoftClientSecret         string                 `json:"microsoft_client_secret"`
+	GeminiAPIKey                  string                 `json:"gemini_api_key"`
+	GeminiModel                   string                 `json:"gemini_model"`
+	GeminiEndpoint                string                 `json:"gemini_endpoint"`
+	InboxCredentialsEncryptionKey string                 `json:"inbox_credentials_encryption_key"`
+	CredentialsMasterKeys         []CredentialsMasterKey `json:"credentials_master_keys"`
+	CredentialsActiveKeyID        string                 `json:"credentials_active_key_id"`
+	CredentialsKeyFile            string                 `json:"credentials_key_file"`
+	FrontendURL                   string                 `json:"frontend_url"`
+	BackendURL                    string                 `json:"backend_url"`
+	JWTSigningKeys                []JWTSigningKey        `json:"jwt_signing_keys"`
+	JWTActiveKeyID                string                 `json:"jwt_active_key_id"`
+	TenantDBMaxPools              int                    `json:"tenant_db_max_pools"`
+	TenantDBMaxConns              int                    `json:"tenant_db_max_conns"`
+	TenantDBPoolIdleTimeout       time.Duration          `json:"-"`
+	TenantIsolationMode           string                 `json:"tenant_isolation_mode"`
+	TenantSharedDatabase          string                 `json:"tenant_shared_database"`
+	InboxInitialSyncDays          int                    `json:"-"`
+	InboxSyncConcurrency          int                    `json:"-"`
+	TrustedProxyHops              int                    `json:"-"`
+	JWT                           JWTConfig              `json:"-"`
+}
+
+// CredentialsMasterKey is one base64 encoded AES key that wraps the data keys of stored
+// credentials. Retired keys stay listed until every record is rewrapped.
+type CredentialsMasterKey struct {
+	KeyID string `json:"kid"`
+	Key   string `json:"key"`
+}
+
+// JWTSigningKey is one RS256/ES256 key. Retired keys keep only public_key_pem
+// so tokens they signed stay verifiable until they expire.
+type JWTSigningKey struct {
+	KeyID         string `json:"kid"`
+	Algorithm     string `json:"alg"`
+	PrivateKeyPEM string `json:"private_key_pem,omitempty"`
+	PublicKeyPEM  string `json:"public_key_pem,omitempty"`
 }
 
 type JWTConfig struct {
@@ -48,6 +79,12 @@ type JWTConfig struct {
 	RefreshSecret string
 	AccessTTL     time.Duration
 	RefreshTTL    time.Duration
+	// TenantAccessTTL bounds organization-scoped tokens; kept short because
+	// their role and permissions are a snapshot taken at exchange time.
+	TenantAccessTTL time.Duration
+	Issuer          string
+	ActiveKeyID     string
+	SigningKeys     []JWTSigningKey
 }
 
 func Load(ctx context.Context) (Config, error) {
@@ -70,6 +107,34 @@ func Load(ctx context.Context) (Config, error) {
 	defaultDebug := cfg.AppEnv == "development" || cfg.AppEnv == "local"
 	cfg.Debug = getEnvAsBool("DEBUG", defaultDebug)
 
+	// Email/password login is always available locally; other environments opt in.
+	localEnv := cfg.AppEnv == "development" || cfg.AppEnv == "local"
+	cfg.LocalAuthEnabled = getEnvAsBool("LOCAL_AUTH_ENABLED", localEnv)
+	cfg.RequireEmailVerification = getEnvAsBool("REQUIRE_EMAIL_VERIFICATION", !localEnv)
+
+	// Tenant pool limits; zero keeps the registry defaults. Size them per
+	// process: every API instance and warm Lambda holds its own pools.
+	cfg.TenantDBMaxPools = getEnvAsInt("TENANT_DB_MAX_POOLS", 0)
+	cfg.TenantDBMaxConns = getEnvAsInt("TENANT_DB_MAX_CONNS", 0)
+	cfg.TenantDBPoolIdleTimeout = getEnvAsDuration("TENANT_DB_POOL_IDLE_TIMEOUT", 0)
+
+	// New organizations get a dedicated database unless set to "schema", which
+	// places them in TENANT_SHARED_DATABASE. Existing ones keep their mode.
+	cfg.TenantIsolationMode = getEnv("TENANT_ISOLATION_MODE", "database")
+	cfg.TenantSharedDatabase = getEnv("TENANT_SHARED_DATABASE", "bowerbird_tenants")
+
+	// How far back the first sync of a new connection reaches; zero keeps
+	// the inbox default. Older mail is brought in by a historical import.
+	cfg.InboxInitialSyncDays = getEnvAsInt("INBOX_INITIAL_SYNC_DAYS", 0)
+
+	// How many messages of one connection a sync processes at once; zero
+	// keeps the inbox default.
+	cfg.InboxSyncConcurrency = getEnvAsInt("INBOX_SYNC_CONCURRENCY", 0)
+
+	// Number of proxies in front of the API that append to X-Forwarded-For;
+	// 0 trusts only the socket address.
+	cfg.TrustedProxyHops = getEnvAsInt("TRUSTED_PROXY_HOPS", 0)
+
 	// Load AWS Config to fetch SSM
 	awsCfg, err := awsConfig.Load(ctx, cfg.AWSRegion, cfg.AWSEndpointURL, cfg.AWSAccessKeyID, cfg.AWSSecretAccessKey)
 	if err != nil {
@@ -117,8 +182,36 @@ func Load(ctx context.Context) (Config, error) {
 		panic("GEMINI_API_KEY is required (from SSM or env)")
 	}
 
+	if len(cfg.CredentialsMasterKeys) == 0 {
+		if raw := os.Getenv("CREDENTIALS_MASTER_KEYS"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &cfg.CredentialsMasterKeys); err != nil {
+				return cfg, fmt.Errorf("parse CREDENTIALS_MASTER_KEYS: %w", err)
+			}
+		}
+	}
+	if cfg.CredentialsActiveKeyID == "" {
+		cfg.CredentialsActiveKeyID = os.Getenv("CREDENTIALS_ACTIVE_KEY_ID")
+	}
+	if cfg.CredentialsKeyFile == "" {
+		cfg.CredentialsKeyFile = os.Getenv("CREDENTIALS_KEY_FILE")
+	}
+
+	if len(cfg.JWTSigningKeys) == 0 {
+		if raw := os.Getenv("JWT_SIGNING_KEYS"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &cfg.JWTSigningKeys); err != nil {
+				return cfg, fmt.Errorf("parse JWT_SIGNING_KEYS: %w", err)
+			}
+		}
+	}
+	if cfg.JWTActiveKeyID == "" {
+		cfg.JWTActiveKeyID = os.Getenv("JWT_ACTIVE_KEY_ID")
+	}
+	// With asymmetric keys the shared secrets are optional: they are only kept
+	// to verify HS256 tokens issued before the switch.
+	asymmetricSigning := len(cfg.JWTSigningKeys) > 0
+
 	accessSecret := os.Getenv("JWT_ACCESS_SECRET")
-	if accessSecret == "" {
+	if accessSecret == "" && !asymmetricSigning {
 		if cfg.AppEnv == "local" || cfg.AppEnv == "development" {
 			accessSecret = "local-dev-access-secret-do-not-use-in-prod"
 		} else {
@@ -127,7 +220,7 @@ func Load(ctx context.Context) (Config, error) {
 	}
 
 	refreshSecret := os.Getenv("JWT_REFRESH_SECRET")
-	if refreshSecret == "" {
+	if refreshSecret == "" && !asymmetricSigning {
 		if cfg.AppEnv == "local" || cfg.AppEnv == "development" {
 			refreshSecret = "local-dev-refresh-secret-do-not-use-in-prod"
 		} else {
@@ -136,10 +229,14 @@ func Load(ctx context.Context) (Config, error) {
 	}
 
 	cfg.JWT = JWTConfig{
-		AccessSecret:  accessSecret,
-		RefreshSecret: refreshSecret,
-		AccessTTL:     15 * time.Minute,
-		RefreshTTL:    7 * 24 * time.Hour,
+		AccessSecret:    accessSecret,
+		RefreshSecret:   refreshSecret,
+		AccessTTL:       15 * time.Minute,
+		RefreshTTL:      7 * 24 * time.Hour,
+		TenantAccessTTL: 5 * time.Minute,
+		Issuer:          getEnv("JWT_ISSUER", cfg.BackendURL),
+		ActiveKeyID:     cfg.JWTActiveKeyID,
+		SigningKeys:     cfg.JWTSigningKeys,
 	}
 
 	return cfg, nil
@@ -190,3 +287,31 @@ func getEnvAsBool(key string, fallback bool) bool {
 
 	return parsed
 }
+
+func getEnvAsInt(key string, fallback int) int {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return fallback
+	}
+
+	return parsed
+}
+
+func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+
+	return parsed
+}
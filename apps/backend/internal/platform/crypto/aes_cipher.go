@@ -1,10 +1,8 @@
 package crypto
 
 import (
-	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -23,27 +21,9 @@ type AESCipher struct {
 }
 
 func NewAESCipherFromBase64Key(encodedKey string) (*AESCipher, error) {
-	if encodedKey == "" {
-		return nil, ErrInvalidKey
-	}
-
-	key, err := base64.StdEncoding.DecodeString(encodedKey)
-	if err != nil {
-		return nil, fmt.Errorf("decode key: %w", err)
-	}
-
-	if l := len(key); l != 16 && l != 24 && l != 32 {
-		return nil, fmt.Errorf("invalid key length %d: %w", l, ErrInvalidKey)
-	}
-
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return nil, fmt.Errorf("create aes cipher: %w", err)
-	}
-
-	aead, err := cipher.NewGCM(block)
+	aead, err := newAEADFromBase64Key(encodedKey)
 	if err != nil {
-		return nil, fmt.Errorf("create gcm cipher: %w", err)
+		return nil, err
 	}
 
 	return &AESCipher{aead: aead}, nil
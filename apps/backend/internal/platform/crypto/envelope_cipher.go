@@ -0,0 +1,234 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+)
+
+// envelopeMagic starts every envelope; its last byte is the format version.
+var envelopeMagic = []byte{'B', 'B', 'E', 1}
+
+const dataKeySize = 32
+
+var (
+	ErrMalformedEnvelope = errors.New("malformed envelope")
+	ErrKeyInUse          = errors.New("master key still seals stored records")
+)
+
+// EnvelopeCipher encrypts each record with its own data key and stores that
+// key wrapped by a master key of the KeyManager:
+//
+//	magic+version | key ID length (1) | key ID | wrapped key length (2) | wrapped key | nonce | ciphertext
+//
+// Rotating the master key only rewraps data keys. Ciphertext written by
+// AESCipher before envelopes existed still decrypts with the legacy cipher.
+type EnvelopeCipher struct {
+	keys   KeyManager
+	legacy *AESCipher
+}
+
+func NewEnvelopeCipher(keys KeyManager, legacy *AESCipher) *EnvelopeCipher {
+	if keys == nil {
+		panic("key manager is required")
+	}
+
+	return &EnvelopeCipher{keys: keys, legacy: legacy}
+}
+
+type envelope struct {
+	keyID      string
+	wrappedKey []byte
+	nonce      []byte
+	payload    []byte
+}
+
+func (c *EnvelopeCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	if len(plaintext) == 0 {
+		return nil, ErrEmptyPlaintext
+	}
+
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("generate data key: %w", err)
+	}
+
+	keyID := c.keys.ActiveKeyID()
+	wrappedKey, err := c.keys.WrapKey(keyID, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("read nonce: %w", err)
+	}
+
+	return envelope{
+		keyID:      keyID,
+		wrappedKey: wrappedKey,
+		nonce:      nonce,
+		payload:    aead.Seal(nil, nonce, plaintext, nil),
+	}.marshal(), nil
+}
+
+func (c *EnvelopeCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 {
+		return nil, ErrEmptyCiphertext
+	}
+
+	env, err := parseEnvelope(ciphertext)
+	if err != nil {
+		return c.decryptLegacy(ciphertext, err)
+	}
+
+	dataKey, err := c.keys.UnwrapKey(env.keyID, env.wrappedKey)
+	if err != nil {
+		return c.decryptLegacy(ciphertext, err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := aead.Open(nil, env.nonce, env.payload, nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// decryptLegacy falls back to the pre-envelope format. A legacy nonce can
+// start like an envelope by chance, so envelope errors also fall back.
+func (c *EnvelopeCipher) decryptLegacy(ciphertext []byte, envelopeErr error) ([]byte, error) {
+	if c.legacy == nil {
+		return nil, envelopeErr
+	}
+
+	plaintext, err := c.legacy.Decrypt(ciphertext)
+	if err != nil {
+		return nil, errors.Join(envelopeErr, err)
+	}
+	return plaintext, nil
+}
+
+// KeyID returns the master key that wraps the ciphertext's data key, or ""
+// for legacy ciphertext.
+func (c *EnvelopeCipher) KeyID(ciphertext []byte) string {
+	env, err := parseEnvelope(ciphertext)
+	if err != nil {
+		return ""
+	}
+	return env.keyID
+}
+
+// ActiveKeyID returns the master key that wraps new data keys.
+func (c *EnvelopeCipher) ActiveKeyID() string {
+	return c.keys.ActiveKeyID()
+}
+
+// CheckKeyUsage fails when usage, the records sealed per master key with ""
+// for legacy ciphertext, names a key the cipher can no longer unwrap, i.e.
+// one retired before every record was rewrapped. A key listed with no
+// records was active during the count and may seal records written since.
+func (c *EnvelopeCipher) CheckKeyUsage(usage map[string]int) error {
+	var errs []error
+	for _, keyID := range slices.Sorted(maps.Keys(usage)) {
+		if keyID == "" {
+			if c.legacy == nil {
+				errs = append(errs, fmt.Errorf("%w: %d legacy records need inbox_credentials_encryption_key", ErrKeyInUse, usage[keyID]))
+			}
+			continue
+		}
+		if !c.keys.HasKey(keyID) {
+			errs = append(errs, fmt.Errorf("%w: %s sealed %d records at the last rewrap", ErrKeyInUse, keyID, usage[keyID]))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Rewrap moves ciphertext under the active master key and reports whether it
+// changed. Envelopes keep their data key and payload; legacy ciphertext is
+// encrypted again.
+func (c *EnvelopeCipher) Rewrap(ciphertext []byte) ([]byte, bool, error) {
+	activeKeyID := c.keys.ActiveKeyID()
+
+	env, err := parseEnvelope(ciphertext)
+	if err == nil {
+		if env.keyID == activeKeyID {
+			return ciphertext, false, nil
+		}
+		dataKey, unwrapErr := c.keys.UnwrapKey(env.keyID, env.wrappedKey)
+		if unwrapErr == nil {
+			wrappedKey, err := c.keys.WrapKey(activeKeyID, dataKey)
+			if err != nil {
+				return nil, false, fmt.Errorf("wrap data key: %w", err)
+			}
+			env.keyID = activeKeyID
+			env.wrappedKey = wrappedKey
+			return env.marshal(), true, nil
+		}
+		err = unwrapErr
+	}
+
+	plaintext, err := c.decryptLegacy(ciphertext, err)
+	if err != nil {
+		return nil, false, err
+	}
+	rewrapped, err := c.Encrypt(plaintext)
+	if err != nil {
+		return nil, false, err
+	}
+	return rewrapped, true, nil
+}
+
+func (e envelope) marshal() []byte {
+	out := make([]byte, 0, len(envelopeMagic)+1+len(e.keyID)+2+len(e.wrappedKey)+len(e.nonce)+len(e.payload))
+	out = append(out, envelopeMagic...)
+	out = append(out, byte(len(e.keyID)))
+	out = append(out, e.keyID...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(e.wrappedKey)))
+	out = append(out, e.wrappedKey...)
+	out = append(out, e.nonce...)
+	return append(out, e.payload...)
+}
+
+func parseEnvelope(data []byte) (envelope, error) {
+	if !bytes.HasPrefix(data, envelopeMagic) {
+		return envelope{}, ErrMalformedEnvelope
+	}
+	rest := data[len(envelopeMagic):]
+
+	if len(rest) < 1 {
+		return envelope{}, ErrMalformedEnvelope
+	}
+	keyIDLen := int(rest[0])
+	rest = rest[1:]
+	if keyIDLen == 0 || len(rest) < keyIDLen+2 {
+		return envelope{}, ErrMalformedEnvelope
+	}
+	keyID := string(rest[:keyIDLen])
+	rest = rest[keyIDLen:]
+
+	wrappedLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if wrappedLen == 0 || len(rest) <= wrappedLen+gcmNonceSize {
+		return envelope{}, ErrMalformedEnvelope
+	}
+
+	return envelope{
+		keyID:      keyID,
+		wrappedKey: rest[:wrappedLen],
+		nonce:      rest[wrappedLen : wrappedLen+gcmNonceSize],
+		payload:    rest[wrappedLen+gcmNonceSize:],
+	}, nil
+}
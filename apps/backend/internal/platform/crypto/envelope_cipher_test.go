@@ -0,0 +1,133 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(fill byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, 32))
+}
+
+func writeKeyFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write key file: %v", err)
+	}
+	return path
+}
+
+func TestEnvelopeCipherRoundtripCarriesKeyID(t *testing.T) {
+	keys, err := LoadLocalKeyManager(writeKeyFile(t, `{"active_key_id":"k1","keys":[{"kid":"k1","key":"`+testKey(1)+`"}]}`))
+	if err != nil {
+		t.Fatalf("load key file: %v", err)
+	}
+	c := NewEnvelopeCipher(keys, nil)
+
+	plaintext := []byte(`{"refresh_token":"abc123"}`)
+	ciphertext, err := c.Encrypt(plaintext)
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+	if c.KeyID(ciphertext) != "k1" {
+		t.Fatalf("expected the envelope to name k1, got %q", c.KeyID(ciphertext))
+	}
+
+	decoded, err := c.Decrypt(ciphertext)
+	if err != nil || !bytes.Equal(decoded, plaintext) {
+		t.Fatalf("roundtrip failed: %q, %v", decoded, err)
+	}
+
+	ciphertext[len(ciphertext)-1] ^= 0x01
+	if _, err := c.Decrypt(ciphertext); err == nil {
+		t.Fatal("expected decrypt error for tampered payload")
+	}
+}
+
+func TestEnvelopeCipherRotation(t *testing.T) {
+	oldKeys, _ := NewLocalKeyManager("k1", []MasterKey{{KeyID: "k1", Key: testKey(1)}})
+	rotated, _ := NewLocalKeyManager("k2", []MasterKey{{KeyID: "k1", Key: testKey(1)}, {KeyID: "k2", Key: testKey(2)}})
+	newKeys, _ := NewLocalKeyManager("k2", []MasterKey{{KeyID: "k2", Key: testKey(2)}})
+
+	ciphertext, err := NewEnvelopeCipher(oldKeys, nil).Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	c := NewEnvelopeCipher(rotated, nil)
+	if plaintext, err := c.Decrypt(ciphertext); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("expected retired keys to keep decrypting, got %q, %v", plaintext, err)
+	}
+
+	rewrapped, changed, err := c.Rewrap(ciphertext)
+	if err != nil || !changed || c.KeyID(rewrapped) != "k2" {
+		t.Fatalf("expected a rewrap under k2, got changed=%v key=%q err=%v", changed, c.KeyID(rewrapped), err)
+	}
+	if _, changed, _ := c.Rewrap(rewrapped); changed {
+		t.Fatal("ciphertext under the active key must be left alone")
+	}
+
+	if plaintext, err := NewEnvelopeCipher(newKeys, nil).Decrypt(rewrapped); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("expected the rewrapped record to outlive k1, got %q, %v", plaintext, err)
+	}
+	if _, err := NewEnvelopeCipher(newKeys, nil).Decrypt(ciphertext); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey once k1 is gone, got %v", err)
+	}
+}
+
+func TestEnvelopeCipherReadsAndRewrapsLegacyCiphertext(t *testing.T) {
+	legacy, err := NewAESCipherFromBase64Key(testKey(9))
+	if err != nil {
+		t.Fatalf("new cipher failed: %v", err)
+	}
+	ciphertext, err := legacy.Encrypt([]byte("secret"))
+	if err != nil {
+		t.Fatalf("encrypt failed: %v", err)
+	}
+
+	keys, _ := NewLocalKeyManager("k1", []MasterKey{{KeyID: "k1", Key: testKey(1)}})
+	c := NewEnvelopeCipher(keys, legacy)
+	if plaintext, err := c.Decrypt(ciphertext); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("expected legacy ciphertext to decrypt, got %q, %v", plaintext, err)
+	}
+
+	rewrapped, changed, err := c.Rewrap(ciphertext)
+	if err != nil || !changed || c.KeyID(rewrapped) != "k1" {
+		t.Fatalf("expected legacy ciphertext to move into an envelope, got changed=%v err=%v", changed, err)
+	}
+	if _, err := NewEnvelopeCipher(keys, nil).Decrypt(rewrapped); err != nil {
+		t.Fatalf("expected the envelope to no longer need the legacy key, got %v", err)
+	}
+}
+
+func TestEnvelopeCipherRefusesRetiredKeysStillInUse(t *testing.T) {
+	keys, _ := NewLocalKeyManager("k2", []MasterKey{{KeyID: "k2", Key: testKey(2)}})
+	c := NewEnvelopeCipher(keys, nil)
+
+	if err := c.CheckKeyUsage(map[string]int{"k2": 5}); err != nil {
+		t.Fatalf("expected keys in the ring to pass, got %v", err)
+	}
+	if err := c.CheckKeyUsage(map[string]int{"k1": 3, "k2": 5}); !errors.Is(err, ErrKeyInUse) {
+		t.Fatalf("expected ErrKeyInUse for a retired key, got %v", err)
+	}
+	if err := c.CheckKeyUsage(map[string]int{"": 1}); !errors.Is(err, ErrKeyInUse) {
+		t.Fatalf("expected ErrKeyInUse for legacy records without the legacy key, got %v", err)
+	}
+}
+
+func TestLocalKeyManagerRejectsInvalidKeyRings(t *testing.T) {
+	if _, err := NewLocalKeyManager("missing", []MasterKey{{KeyID: "k1", Key: testKey(1)}}); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey for an unknown active key, got %v", err)
+	}
+	if _, err := NewLocalKeyManager("k1", []MasterKey{{KeyID: "k1", Key: testKey(1)}, {KeyID: "k1", Key: testKey(2)}}); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected ErrInvalidKey for duplicate IDs, got %v", err)
+	}
+	if _, err := LoadLocalKeyManager(writeKeyFile(t, `{"active_key_id":"k1","keys":[{"kid":"k1","key":"c2hvcnQ="}]}`)); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected ErrInvalidKey for a short key, got %v", err)
+	}
+}
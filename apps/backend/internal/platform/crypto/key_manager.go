@@ -0,0 +1,150 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var ErrUnknownKey = errors.New("unknown master key")
+
+// KeyManager wraps data keys with master keys that never leave it, the role a
+// KMS plays. The active key wraps new data keys; every key it holds can still
+// unwrap, so records wrapped before a rotation stay readable.
+type KeyManager interface {
+	ActiveKeyID() string
+	HasKey(keyID string) bool
+	WrapKey(keyID string, dataKey []byte) ([]byte, error)
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// MasterKey is one base64 encoded AES key of a key ring.
+type MasterKey struct {
+	KeyID string `json:"kid"`
+	Key   string `json:"key"`
+}
+
+// LocalKeyManager keeps the master keys in process memory. It backs local
+// development and tests through a key file, and deployments that hand the
+// key ring over as configuration.
+type LocalKeyManager struct {
+	activeKeyID string
+	keys        map[string]cipher.AEAD
+}
+
+func NewLocalKeyManager(activeKeyID string, keys []MasterKey) (*LocalKeyManager, error) {
+	manager := &LocalKeyManager{activeKeyID: activeKeyID, keys: make(map[string]cipher.AEAD, len(keys))}
+	for _, key := range keys {
+		if key.KeyID == "" || len(key.KeyID) > 255 {
+			return nil, fmt.Errorf("invalid master key id %q: %w", key.KeyID, ErrInvalidKey)
+		}
+		if _, ok := manager.keys[key.KeyID]; ok {
+			return nil, fmt.Errorf("duplicate master key %q: %w", key.KeyID, ErrInvalidKey)
+		}
+		aead, err := newAEADFromBase64Key(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q: %w", key.KeyID, err)
+		}
+		manager.keys[key.KeyID] = aead
+	}
+	if _, ok := manager.keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active master key %q: %w", activeKeyID, ErrUnknownKey)
+	}
+
+	return manager, nil
+}
+
+// LoadLocalKeyManager reads a key file such as
+// {"active_key_id": "2026-01", "keys": [{"kid": "2026-01", "key": "<base64>"}]}.
+func LoadLocalKeyManager(path string) (*LocalKeyManager, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+
+	var file struct {
+		ActiveKeyID string      `json:"active_key_id"`
+		Keys        []MasterKey `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("decode key file: %w", err)
+	}
+
+	return NewLocalKeyManager(file.ActiveKeyID, file.Keys)
+}
+
+func (m *LocalKeyManager) ActiveKeyID() string {
+	return m.activeKeyID
+}
+
+func (m *LocalKeyManager) HasKey(keyID string) bool {
+	_, ok := m.keys[keyID]
+	return ok
+}
+
+func (m *LocalKeyManager) WrapKey(keyID string, dataKey []byte) ([]byte, error) {
+	aead, ok := m.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("read nonce: %w", err)
+	}
+
+	// The key ID is authenticated so a wrapped key cannot be relabelled.
+	return aead.Seal(nonce, nonce, dataKey, []byte(keyID)), nil
+}
+
+func (m *LocalKeyManager) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := m.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	if len(wrapped) <= gcmNonceSize {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+
+	dataKey, err := aead.Open(nil, wrapped[:gcmNonceSize], wrapped[gcmNonceSize:], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	return dataKey, nil
+}
+
+func newAEADFromBase64Key(encodedKey string) (cipher.AEAD, error) {
+	if encodedKey == "" {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode key: %w", err)
+	}
+
+	return newAEAD(key)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if l := len(key); l != 16 && l != 24 && l != 32 {
+		return nil, fmt.Errorf("invalid key length %d: %w", l, ErrInvalidKey)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create aes cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm cipher: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// KeyUsageStore keeps, per module, how many stored records each master key
+// seals, as counted by the last credentials rewrap.
+type KeyUsageStore struct {
+	controlDB *pgxpool.Pool
+}
+
+func NewKeyUsageStore(controlDB *pgxpool.Pool) *KeyUsageStore {
+	if controlDB == nil {
+		panic("control plane db pool is required")
+	}
+
+	return &KeyUsageStore{controlDB: controlDB}
+}
+
+// RecordKeyUsage stores the keys a rewrap run of scope found. A complete run
+// replaces what the scope recorded before; an incomplete one only adds to it,
+// since records it could not reach may still use the keys it did not see.
+func (s *KeyUsageStore) RecordKeyUsage(ctx context.Context, scope string, usage map[string]int, complete bool) error {
+	return pgx.BeginFunc(ctx, s.controlDB, func(tx pgx.Tx) error {
+		if complete {
+			if _, err := tx.Exec(ctx, `DELETE FROM credentials_key_usage WHERE scope = $1`, scope); err != nil {
+				return fmt.Errorf("clear key usage: %w", err)
+			}
+		}
+
+		for keyID, records := range usage {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO credentials_key_usage (scope, key_id, records, counted_at)
+				VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+				ON CONFLICT (scope, key_id) DO UPDATE SET records = EXCLUDED.records, counted_at = EXCLUDED.counted_at
+			`, scope, keyID, records)
+			if err != nil {
+				return fmt.Errorf("record key usage of %q: %w", keyID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// KeyUsage returns the records each master key seals across every scope.
+func (s *KeyUsageStore) KeyUsage(ctx context.Context) (map[string]int, error) {
+	rows, err := s.controlDB.Query(ctx, `SELECT key_id, SUM(records)::BIGINT FROM credentials_key_usage GROUP BY key_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query key usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := map[string]int{}
+	for rows.Next() {
+		var (
+			keyID   string
+			records int
+		)
+		if err := rows.Scan(&keyID, &records); err != nil {
+			return nil, fmt.Errorf("scan key usage: %w", err)
+		}
+		usage[keyID] = records
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating key usage: %w", err)
+	}
+	return usage, nil
+}
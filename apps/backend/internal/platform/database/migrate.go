@@ -4,6 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -33,3 +37,80 @@ func RunMigrations(dbURL string, migrationsPath string) error {
 
 	return nil
 }
+
+// MigrationVersion returns the version applied to the database and whether a
+// failed migration left it dirty. A database without migrations is at version 0.
+func MigrationVersion(dbURL string, migrationsPath string) (uint, bool, error) {
+	m, err := migrate.New(fmt.Sprintf("file://%s", migrationsPath), dbURL)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to init migrate: %w", err)
+	}
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+// MigrateToVersion migrates the database up or down to version.
+func MigrateToVersion(dbURL string, migrationsPath string, version uint) error {
+	m, err := migrate.New(fmt.Sprintf("file://%s", migrationsPath), dbURL)
+	if err != nil {
+		return fmt.Errorf("failed to init migrate: %w", err)
+	}
+	defer m.Close()
+
+	err = migrateTo(m, version)
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to migrate to version %d: %w", version, err)
+	}
+
+	return nil
+}
+
+// versionMigrator is the part of *migrate.Migrate that MigrateToVersion uses.
+type versionMigrator interface {
+	Migrate(version uint) error
+	Down() error
+}
+
+func migrateTo(m versionMigrator, version uint) error {
+	// No migration file has version 0, so Migrate(0) would fail; reverting
+	// every migration is a Down.
+	if version == 0 {
+		return m.Down()
+	}
+	return m.Migrate(version)
+}
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.+\.up\.sql$`)
+
+// AvailableMigrationVersions lists the versions in the migrations folder in ascending order.
+func AvailableMigrationVersions(migrationsPath string) ([]uint, error) {
+	entries, err := os.ReadDir(migrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations dir: %w", err)
+	}
+
+	var versions []uint
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		versions = append(versions, uint(version))
+	}
+	slices.Sort(versions)
+
+	return versions, nil
+}
@@ -0,0 +1,36 @@
+package database
+
+import "testing"
+
+type recordingMigrator struct {
+	migratedTo []uint
+	downs      int
+}
+
+func (m *recordingMigrator) Migrate(version uint) error {
+	m.migratedTo = append(m.migratedTo, version)
+	return nil
+}
+
+func (m *recordingMigrator) Down() error {
+	m.downs++
+	return nil
+}
+
+func TestMigrateToVersionZeroRevertsEverything(t *testing.T) {
+	m := &recordingMigrator{}
+
+	if err := migrateTo(m, 0); err != nil {
+		t.Fatalf("migrate to 0 failed: %v", err)
+	}
+	if m.downs != 1 || len(m.migratedTo) != 0 {
+		t.Fatalf("expected a single Down, got %d downs and migrations to %v", m.downs, m.migratedTo)
+	}
+
+	if err := migrateTo(m, 3); err != nil {
+		t.Fatalf("migrate to 3 failed: %v", err)
+	}
+	if len(m.migratedTo) != 1 || m.migratedTo[0] != 3 {
+		t.Fatalf("expected a migration to version 3, got %v", m.migratedTo)
+	}
+}
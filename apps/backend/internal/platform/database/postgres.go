@@ -2,12 +2,27 @@ package database
 
 import (
 	"context"
+	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func Connect(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+	return ConnectWithLimits(ctx, databaseURL, 10, 2)
+}
+
+// ConnectWithLimits opens a pool capped at maxConns connections that keeps
+// minConns open while idle.
+func ConnectWithLimits(ctx context.Context, databaseURL string, maxConns, minConns int32) (*pgxpool.Pool, error) {
+	return connectPool(ctx, databaseURL, func(cfg *pgxpool.Config) {
+		cfg.MaxConns = maxConns
+		cfg.MinConns = minConns
+	})
+}
+
+func connectPool(ctx context.Context, databaseURL string, configure func(*pgxpool.Config)) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, err
@@ -17,6 +32,7 @@ func Connect(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
 	cfg.MinConns = 2
 	cfg.MaxConnLifetime = 30 * time.Minute
 	cfg.MaxConnIdleTime = 5 * time.Minute
+	configure(cfg)
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
@@ -30,3 +46,22 @@ func Connect(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
 
 	return pool, nil
 }
+
+// TenantURL points databaseURL at a tenant database. A non-empty schema sets
+// the search_path of every connection, for tenants stored as a schema of a
+// shared database.
+func TenantURL(databaseURL, dbName, schema string) (string, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return "", fmt.Errorf("parse database url: %w", err)
+	}
+
+	u.Path = "/" + dbName
+	if schema != "" {
+		query := u.Query()
+		query.Set("search_path", schema)
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String(), nil
+}
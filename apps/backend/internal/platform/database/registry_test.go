@@ -0,0 +1,300 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bowerbird/internal/platform/tenant"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type registryFixture struct {
+	registry *Registry
+	now      time.Time
+	connects atomic.Int32
+	// tenants maps tenant identifiers to locations; missing ones are not active.
+	tenants map[string]tenantLocation
+	mu      sync.Mutex
+}
+
+func newRegistryFixture(t *testing.T, cfg RegistryConfig) *registryFixture {
+	t.Helper()
+
+	f := &registryFixture{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), tenants: map[string]tenantLocation{}}
+	f.registry = NewRegistry(nil, "postgres://bowerbird@127.0.0.1:1/%s", cfg)
+	f.registry.now = func() time.Time {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.now
+	}
+	f.registry.connect = func(ctx context.Context, dbName string, maxConns int32, shared bool) (*pgxpool.Pool, error) {
+		f.connects.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		// pgxpool dials lazily, so no database is needed.
+		return pgxpool.New(ctx, "postgres://bowerbird@127.0.0.1:1/"+dbName)
+	}
+	f.registry.resolve = func(ctx context.Context, identifier string) (tenantLocation, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		location, ok := f.tenants[identifier]
+		if !ok {
+			return tenantLocation{}, pgx.ErrNoRows
+		}
+		return location, nil
+	}
+	f.registry.locate = func(ctx context.Context, dbName string) (tenantLocation, error) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, location := range f.tenants {
+			if location.dbName == dbName {
+				return location, nil
+			}
+		}
+		return tenantLocation{dbName: dbName}, nil
+	}
+	t.Cleanup(func() {
+		for _, entry := range f.registry.pools {
+			entry.pool.Close()
+		}
+	})
+
+	return f
+}
+
+func (f *registryFixture) advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
+
+func (f *registryFixture) setTenant(identifier, dbName string) {
+	f.setSharedTenant(identifier, dbName, "")
+}
+
+func (f *registryFixture) setSharedTenant(identifier, dbName, sharedDatabase string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if dbName == "" {
+		delete(f.tenants, identifier)
+		return
+	}
+	f.tenants[identifier] = tenantLocation{dbName: dbName, sharedDatabase: sharedDatabase}
+}
+
+func TestRegistrySharesOnePoolPerDatabase(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{})
+	f.setTenant("acme", "tenant_acme")
+	f.setTenant("01TENANT", "tenant_acme")
+
+	bySlug, err := f.registry.GetPool(tenant.WithTenantID(context.Background(), "acme"))
+	if err != nil {
+		t.Fatalf("get pool by slug: %v", err)
+	}
+	byID, err := f.registry.GetPool(tenant.WithTenantID(context.Background(), "01TENANT"))
+	if err != nil {
+		t.Fatalf("get pool by id: %v", err)
+	}
+	byName, err := f.registry.GetPoolByDBName(context.Background(), "tenant_acme")
+	if err != nil {
+		t.Fatalf("get pool by db name: %v", err)
+	}
+
+	if bySlug != byID || byID != byName {
+		t.Fatal("expected one pool for the tenant database")
+	}
+	if got := f.connects.Load(); got != 1 {
+		t.Fatalf("expected 1 connection attempt, got %d", got)
+	}
+}
+
+func TestRegistryConnectsOnceForConcurrentCallers(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{})
+	f.setTenant("acme", "tenant_acme")
+	ctx := tenant.WithTenantID(context.Background(), "acme")
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := f.registry.GetPool(ctx); err != nil {
+				t.Errorf("get pool: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := f.connects.Load(); got != 1 {
+		t.Fatalf("expected 1 connection attempt, got %d", got)
+	}
+}
+
+func TestRegistryEvictsLeastRecentlyUsedPool(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{MaxPools: 2, MaxConns: 10})
+	ctx := context.Background()
+
+	for _, dbName := range []string{"tenant_a", "tenant_b"} {
+		if _, err := f.registry.GetPoolByDBName(ctx, dbName); err != nil {
+			t.Fatalf("open %s: %v", dbName, err)
+		}
+		f.advance(time.Second)
+	}
+	// Using tenant_a again leaves tenant_b as the least recently used.
+	if _, err := f.registry.GetPoolByDBName(ctx, "tenant_a"); err != nil {
+		t.Fatalf("reuse tenant_a: %v", err)
+	}
+	f.advance(time.Second)
+	if _, err := f.registry.GetPoolByDBName(ctx, "tenant_c"); err != nil {
+		t.Fatalf("open tenant_c: %v", err)
+	}
+
+	if _, exists := f.registry.pools["tenant_b"]; exists {
+		t.Fatal("expected tenant_b to be evicted")
+	}
+	stats := f.registry.Stats()
+	if stats.OpenPools != 2 || stats.Evictions != 1 {
+		t.Fatalf("expected 2 open pools and 1 eviction, got %+v", stats)
+	}
+	if stats.MaxConnsPerPool != 5 {
+		t.Fatalf("expected the budget split in 5 connections per pool, got %d", stats.MaxConnsPerPool)
+	}
+}
+
+func TestRegistryClosesIdlePools(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{IdleTimeout: 10 * time.Minute})
+	ctx := context.Background()
+
+	if _, err := f.registry.GetPoolByDBName(ctx, "tenant_a"); err != nil {
+		t.Fatalf("open tenant_a: %v", err)
+	}
+	f.advance(11 * time.Minute)
+	if _, err := f.registry.GetPoolByDBName(ctx, "tenant_b"); err != nil {
+		t.Fatalf("open tenant_b: %v", err)
+	}
+
+	if _, exists := f.registry.pools["tenant_a"]; exists {
+		t.Fatal("expected idle tenant_a pool to be closed")
+	}
+	if _, exists := f.registry.pools["tenant_b"]; !exists {
+		t.Fatal("expected tenant_b pool to stay open")
+	}
+}
+
+func TestRegistryInvalidateForgetsPoolAndLookup(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{})
+	f.setTenant("acme", "tenant_acme")
+	ctx := tenant.WithTenantID(context.Background(), "acme")
+
+	if _, err := f.registry.GetPool(ctx); err != nil {
+		t.Fatalf("get pool: %v", err)
+	}
+	f.setTenant("acme", "")
+	f.registry.Invalidate("tenant_acme")
+
+	if _, err := f.registry.GetPool(ctx); !errors.Is(err, ErrTenantUnavailable) {
+		t.Fatalf("expected ErrTenantUnavailable after invalidation, got %v", err)
+	}
+	if stats := f.registry.Stats(); stats.OpenPools != 0 || stats.Invalidations != 1 {
+		t.Fatalf("expected the pool to be closed, got %+v", stats)
+	}
+}
+
+func TestRegistryClosesPoolWhenTenantStopsResolving(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{ResolveTTL: time.Minute})
+	f.setTenant("acme", "tenant_acme")
+	ctx := tenant.WithTenantID(context.Background(), "acme")
+
+	if _, err := f.registry.GetPool(ctx); err != nil {
+		t.Fatalf("get pool: %v", err)
+	}
+
+	// Suspended by another process: the cached lookup is used until it expires.
+	f.setTenant("acme", "")
+	if _, err := f.registry.GetPool(ctx); err != nil {
+		t.Fatalf("expected cached lookup before the TTL, got %v", err)
+	}
+	f.advance(2 * time.Minute)
+
+	if _, err := f.registry.GetPool(ctx); !errors.Is(err, ErrTenantUnavailable) {
+		t.Fatalf("expected ErrTenantUnavailable, got %v", err)
+	}
+	if _, exists := f.registry.pools["tenant_acme"]; exists {
+		t.Fatal("expected the suspended tenant's pool to be closed")
+	}
+}
+
+func TestRegistryGetPoolReachesInactiveTenantByDatabase(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{})
+
+	if _, err := f.registry.GetPool(tenant.WithTenantID(context.Background(), "wayne")); !errors.Is(err, ErrTenantUnavailable) {
+		t.Fatalf("expected ErrTenantUnavailable by tenant ID, got %v", err)
+	}
+
+	ctx := WithTenantDatabase(tenant.WithTenantID(context.Background(), "wayne"), "tenant_wayne")
+	if _, err := f.registry.GetPool(ctx); err != nil {
+		t.Fatalf("expected the tenant database to be reached, got %v", err)
+	}
+	if _, exists := f.registry.pools["tenant_wayne"]; !exists {
+		t.Fatal("expected a pool for the tenant database")
+	}
+}
+
+func TestRegistrySharesPoolBetweenSchemaTenants(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{})
+	f.setSharedTenant("acme", "tenant_acme", "bowerbird_tenants")
+	f.setSharedTenant("stark", "tenant_stark", "bowerbird_tenants")
+
+	acme, err := f.registry.GetPool(tenant.WithTenantID(context.Background(), "acme"))
+	if err != nil {
+		t.Fatalf("get acme pool: %v", err)
+	}
+	stark, err := f.registry.GetPool(tenant.WithTenantID(context.Background(), "stark"))
+	if err != nil {
+		t.Fatalf("get stark pool: %v", err)
+	}
+	byName, err := f.registry.GetPoolByDBName(context.Background(), "tenant_acme")
+	if err != nil {
+		t.Fatalf("get pool by db name: %v", err)
+	}
+
+	if acme != stark || stark != byName {
+		t.Fatal("expected schema tenants to share the shared database pool")
+	}
+	if _, exists := f.registry.pools["bowerbird_tenants"]; !exists {
+		t.Fatal("expected the pool to be keyed by the shared database")
+	}
+}
+
+func TestRegistryConnectionSchema(t *testing.T) {
+	f := newRegistryFixture(t, RegistryConfig{})
+	f.setSharedTenant("acme", "tenant_acme", "bowerbird_tenants")
+	f.setTenant("stark", "tenant_stark")
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{"tenant in context", tenant.WithTenantID(context.Background(), "acme"), "tenant_acme"},
+		{"tenant database in context", WithTenantDatabase(context.Background(), "tenant_acme"), "tenant_acme"},
+		{"no tenant", context.Background(), noTenantSchema},
+		{"inactive tenant", tenant.WithTenantID(context.Background(), "wayne"), noTenantSchema},
+		{"dedicated tenant", tenant.WithTenantID(context.Background(), "stark"), noTenantSchema},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.registry.connectionSchema(tt.ctx, "bowerbird_tenants"); got != tt.want {
+				t.Fatalf("expected schema %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if got := f.registry.connectionSchema(tenant.WithTenantID(context.Background(), "acme"), "other_shared"); got != noTenantSchema {
+		t.Fatalf("expected no schema on another shared database, got %q", got)
+	}
+}
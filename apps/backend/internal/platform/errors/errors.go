@@ -46,11 +46,14 @@ func Wrap(err error, code, message string) *AppError {
 
 // Common error codes (Examples, can be extended by domain packages)
 const (
-	CodeInternal       = "ERR_INTERNAL"
-	CodeNotFound       = "ERR_NOT_FOUND"
-	CodeValidation     = "ERR_VALIDATION"
-	CodeUnauthorized   = "ERR_UNAUTHORIZED"
-	CodeForbidden      = "ERR_FORBIDDEN"
-	CodeConflict       = "ERR_CONFLICT"
-	CodeNotImplemented = "ERR_NOT_IMPLEMENTED"
+	CodeInternal        = "ERR_INTERNAL"
+	CodeNotFound        = "ERR_NOT_FOUND"
+	CodeValidation      = "ERR_VALIDATION"
+	CodeUnauthorized    = "ERR_UNAUTHORIZED"
+	CodeForbidden       = "ERR_FORBIDDEN"
+	CodeConflict        = "ERR_CONFLICT"
+	CodeTooManyRequests = "ERR_TOO_MANY_REQUESTS"
+	CodeMFARequired     = "ERR_MFA_REQUIRED"
+	CodeSSORequired     = "ERR_SSO_REQUIRED"
+	CodeNotImplemented  = "ERR_NOT_IMPLEMENTED"
 )
@@ -2,6 +2,7 @@ package events
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/aws/aws-lambda-go/events"
@@ -12,19 +13,21 @@ type EventBridgeSubscriber interface {
 	HandleEventBridge(ctx context.Context, event events.CloudWatchEvent) error
 }
 
+// EventHandler routes each event to every subscriber of its detail type, so
+// several modules can react to the same event.
 type EventHandler struct {
-	eventBridgeSubscribers map[string]EventBridgeSubscriber
+	eventBridgeSubscribers map[string][]EventBridgeSubscriber
 }
 
 func NewEventHandler(subscribers ...EventBridgeSubscriber) EventHandler {
-	ebRoutes := make(map[string]EventBridgeSubscriber)
+	ebRoutes := make(map[string][]EventBridgeSubscriber)
 
 	for _, subscriber := range subscribers {
 		if subscriber == nil {
 			continue
 		}
 
-		ebRoutes[subscriber.DetailType()] = subscriber
+		ebRoutes[subscriber.DetailType()] = append(ebRoutes[subscriber.DetailType()], subscriber)
 	}
 
 	return EventHandler{
@@ -33,8 +36,15 @@ func NewEventHandler(subscribers ...EventBridgeSubscriber) EventHandler {
 }
 
 func (h EventHandler) HandleEventBridgeEvent(ctx context.Context, event events.CloudWatchEvent) error {
-	if subscriber, ok := h.eventBridgeSubscribers[event.DetailType]; ok {
-		if err := subscriber.HandleEventBridge(ctx, event); err != nil {
+	if subscribers, ok := h.eventBridgeSubscribers[event.DetailType]; ok {
+		// A failing subscriber does not keep the others from running.
+		var errs []error
+		for _, subscriber := range subscribers {
+			if err := subscriber.HandleEventBridge(ctx, event); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
 			return err
 		}
 
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type clientIPContextKey struct{}
+
+// ClientIPMiddleware resolves the client address once per request.
+// trustedProxyHops is how many proxies in front of the API append to
+// X-Forwarded-For; with zero the header is ignored, since any client can set it.
+func ClientIPMiddleware(trustedProxyHops int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), clientIPContextKey{}, ClientIP(r, trustedProxyHops))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIPFromContext returns the address set by ClientIPMiddleware, or an
+// empty string when it could not be resolved to a valid IP.
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPContextKey{}).(string)
+	return ip
+}
+
+// ClientIP returns the normalized address of the client, or an empty string
+// when it is not a valid IP. Behind trustedProxyHops proxies it is the
+// X-Forwarded-For entry appended by the outermost one: entries to its left
+// come from the client and cannot be trusted.
+func ClientIP(r *http.Request, trustedProxyHops int) string {
+	if trustedProxyHops > 0 {
+		var hops []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			for hop := range strings.SplitSeq(header, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+		if len(hops) >= trustedProxyHops {
+			return normalizeIP(hops[len(hops)-trustedProxyHops])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return normalizeIP(host)
+}
+
+func normalizeIP(raw string) string {
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
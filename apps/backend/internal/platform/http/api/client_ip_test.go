@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		hops      int
+		forwarded []string
+		remote    string
+		want      string
+	}{
+		{name: "ignores the header without trusted proxies", forwarded: []string{"203.0.113.9"}, remote: "10.0.0.1:5000", want: "10.0.0.1"},
+		{name: "takes the hop appended by the trusted proxy", hops: 1, forwarded: []string{"198.51.100.7, 203.0.113.9"}, remote: "10.0.0.1:5000", want: "203.0.113.9"},
+		{name: "counts hops across repeated headers", hops: 2, forwarded: []string{"198.51.100.7", "203.0.113.9, 10.0.0.2"}, remote: "10.0.0.1:5000", want: "203.0.113.9"},
+		{name: "falls back to the socket with fewer hops than proxies", hops: 2, forwarded: []string{"203.0.113.9"}, remote: "10.0.0.1:5000", want: "10.0.0.1"},
+		{name: "normalizes ipv6", remote: "[2001:DB8::0001]:443", want: "2001:db8::1"},
+		{name: "rejects a forged hop that is not an ip", hops: 1, forwarded: []string{"'; DROP TABLE login_attempts; --"}, remote: "10.0.0.1:5000", want: ""},
+		{name: "rejects an unparsable socket address", remote: "pipe", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			for _, header := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", header)
+			}
+
+			if got := ClientIP(req, tt.hops); got != tt.want {
+				t.Fatalf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
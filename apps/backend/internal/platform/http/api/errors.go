@@ -141,12 +141,14 @@ func statusFromCode(code string) (int, string) {
 		return http.StatusBadRequest, "Bad Request"
 	case appErrors.CodeUnauthorized:
 		return http.StatusUnauthorized, "Unauthorized"
-	case appErrors.CodeForbidden:
+	case appErrors.CodeForbidden, appErrors.CodeMFARequired, appErrors.CodeSSORequired:
 		return http.StatusForbidden, "Forbidden"
 	case appErrors.CodeNotFound:
 		return http.StatusNotFound, "Not Found"
 	case appErrors.CodeConflict:
 		return http.StatusConflict, "Conflict"
+	case appErrors.CodeTooManyRequests:
+		return http.StatusTooManyRequests, "Too Many Requests"
 	case appErrors.CodeNotImplemented:
 		return http.StatusNotImplemented, "Not Implemented"
 	case appErrors.CodeInternal:
@@ -228,6 +230,8 @@ func captureStackTrace(skip int) string {
 	return builder.String()
 }
 
+type traceIDContextKey struct{}
+
 // GenerateTraceIDMiddleware adds a trace ID to the context if not present (Optional helper)
 func GenerateTraceIDMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -236,7 +240,13 @@ func GenerateTraceIDMiddleware(next http.Handler) http.Handler {
 			traceID = fmt.Sprintf("req-%d", time.Now().UnixNano())
 			r.Header.Set("sentry-trace", traceID)
 		}
-		ctx := context.WithValue(r.Context(), "traceID", traceID)
+		ctx := context.WithValue(r.Context(), traceIDContextKey{}, traceID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// TraceIDFromContext returns the trace ID set by GenerateTraceIDMiddleware.
+func TraceIDFromContext(ctx context.Context) string {
+	traceID, _ := ctx.Value(traceIDContextKey{}).(string)
+	return traceID
+}
@@ -1,10 +1,18 @@
 package jobs
 
-import "context"
+import (
+	"context"
+	"time"
+)
+
+// MaxDelay is the longest a job can be held back before it is delivered.
+const MaxDelay = 15 * time.Minute
 
 type Job struct {
 	Type    string
 	Payload []byte
+	// Delay holds the job back before delivery, up to MaxDelay.
+	Delay time.Duration
 }
 
 type Queue interface {
@@ -2,6 +2,7 @@ package jobs
 
 import (
 	"context"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -47,6 +48,7 @@ func (q *SQSQueue) Dispatch(ctx context.Context, job Job) error {
 		QueueUrl:          aws.String(q.queueURL),
 		MessageBody:       aws.String(string(job.Payload)),
 		MessageAttributes: attrs,
+		DelaySeconds:      int32(min(max(job.Delay, 0), MaxDelay) / time.Second),
 	})
 
 	return err
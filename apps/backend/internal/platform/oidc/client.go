@@ -0,0 +1,301 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bowerbird/internal/platform/auth"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+var (
+	ErrDiscovery      = errors.New("oidc discovery failed")
+	ErrInvalidIDToken = errors.New("invalid id token")
+)
+
+const (
+	discoveryTTL = time.Hour
+	// A token signed with an unknown kid triggers a JWKS refresh at most this often.
+	jwksRefreshInterval = time.Minute
+	maxResponseBytes    = 1 << 20
+	clockSkew           = time.Minute
+)
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}
+
+// Metadata is the subset of the discovery document the login flow needs.
+type Metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// IDToken holds the verified claims used to sign a user in.
+type IDToken struct {
+	Subject string
+	Email   string
+	// EmailVerified is nil when the provider does not send the claim (e.g. Azure AD).
+	EmailVerified *bool
+	Name          string
+	// AuthMethods is the amr claim; "mfa" means the provider enforced a second factor.
+	AuthMethods []string
+}
+
+// Client discovers OpenID providers and caches their metadata and keys.
+type Client struct {
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	providers map[string]*Provider
+}
+
+func NewClient(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		now:        time.Now,
+		providers:  make(map[string]*Provider),
+	}
+}
+
+// Discover loads (or returns the cached) provider for an issuer URL.
+func (c *Client) Discover(ctx context.Context, issuer string) (*Provider, error) {
+	issuer = strings.TrimRight(issuer, "/")
+
+	c.mu.Lock()
+	cached, ok := c.providers[issuer]
+	c.mu.Unlock()
+	if ok && c.now().Sub(cached.discoveredAt) < discoveryTTL {
+		return cached, nil
+	}
+
+	var metadata Metadata
+	if err := c.getJSON(ctx, issuer+"/.well-known/openid-configuration", &metadata); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscovery, err)
+	}
+	// The document must describe the issuer it was fetched from, otherwise a
+	// compromised or misconfigured endpoint could vouch for another provider.
+	if strings.TrimRight(metadata.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("%w: issuer mismatch %q", ErrDiscovery, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete provider metadata", ErrDiscovery)
+	}
+
+	provider := &Provider{Metadata: metadata, client: c, discoveredAt: c.now()}
+
+	c.mu.Lock()
+	c.providers[issuer] = provider
+	c.mu.Unlock()
+
+	return provider, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %d", url, resp.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out)
+}
+
+// Provider is a discovered OpenID provider.
+type Provider struct {
+	Metadata     Metadata
+	client       *Client
+	discoveredAt time.Time
+
+	mu            sync.Mutex
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+// OAuth2Config builds the authorization code flow configuration for a client.
+func (p *Provider) OAuth2Config(clientID, clientSecret, redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"openid", "email", "profile"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.Metadata.AuthorizationEndpoint,
+			TokenURL: p.Metadata.TokenEndpoint,
+		},
+	}
+}
+
+// AuthCodeURL returns the authorization URL with the nonce and a PKCE challenge.
+func (p *Provider) AuthCodeURL(config *oauth2.Config, state, nonce, codeVerifier string) string {
+	return config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.S256ChallengeOption(codeVerifier),
+	)
+}
+
+// Exchange redeems an authorization code and returns the raw ID token.
+func (p *Provider) Exchange(ctx context.Context, config *oauth2.Config, code, codeVerifier string) (string, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client.httpClient)
+
+	token, err := config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return "", fmt.Errorf("exchange authorization code: %w", err)
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return "", fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+
+	return rawIDToken, nil
+}
+
+type idTokenClaims struct {
+	Nonce           string   `json:"nonce"`
+	Email           string   `json:"email"`
+	EmailVerified   any      `json:"email_verified"`
+	Name            string   `json:"name"`
+	AuthorizedParty string   `json:"azp"`
+	AuthMethods     []string `json:"amr"`
+	jwt.RegisteredClaims
+}
+
+// VerifyIDToken checks the signature against the provider's JWKS, and the
+// issuer, audience, expiry and nonce of an ID token.
+func (p *Provider) VerifyIDToken(ctx context.Context, rawIDToken, clientID, nonce string) (*IDToken, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(rawIDToken, &claims, p.keyFunc(ctx),
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(p.Metadata.Issuer),
+		jwt.WithAudience(clientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.client.now),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != clientID {
+		return nil, fmt.Errorf("%w: unexpected authorized party %q", ErrInvalidIDToken, claims.AuthorizedParty)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return &IDToken{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: parseBool(claims.EmailVerified),
+		Name:          claims.Name,
+		AuthMethods:   claims.AuthMethods,
+	}, nil
+}
+
+// parseBool accepts email_verified as a boolean or, as some providers send it, a string.
+func parseBool(value any) *bool {
+	var parsed bool
+	switch v := value.(type) {
+	case bool:
+		parsed = v
+	case string:
+		parsed = strings.EqualFold(v, "true")
+	default:
+		return nil
+	}
+	return &parsed
+}
+
+func (p *Provider) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		if key, ok := p.lookupKey(kid); ok {
+			return key, nil
+		}
+		// Unknown kid: the provider may have rotated its keys since the last fetch.
+		if err := p.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+		if key, ok := p.lookupKey(kid); ok {
+			return key, nil
+		}
+
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+}
+
+func (p *Provider) lookupKey(kid string) (any, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (p *Provider) refreshKeys(ctx context.Context) error {
+	p.mu.Lock()
+	fresh := p.keys != nil && p.client.now().Sub(p.keysFetchedAt) < jwksRefreshInterval
+	p.mu.Unlock()
+	if fresh {
+		return nil
+	}
+
+	var doc auth.JWKS
+	if err := p.client.getJSON(ctx, p.Metadata.JWKSURI, &doc); err != nil {
+		return fmt.Errorf("fetch jwks: %w", err)
+	}
+
+	keys := make(map[string]any, len(doc.Keys))
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.PublicKey()
+		if err != nil {
+			// Providers publish key types we do not use (e.g. encryption keys); skip them.
+			continue
+		}
+		keys[jwk.KeyID] = key
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = p.client.now()
+	p.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/bowerbird/internal/platform/oidc"
+	"github.com/bowerbird/internal/platform/oidc/oidctest"
+	"golang.org/x/oauth2"
+)
+
+// authorize follows the stand-in's authorization endpoint and returns the code.
+func authorize(t *testing.T, authURL string) string {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || location.Query().Get("code") == "" {
+		t.Fatalf("expected redirect with code, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+
+	return location.Query().Get("code")
+}
+
+func TestProviderAuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+	idp.SetUser(oidctest.User{Subject: "sub-42", Email: "Ana@Example.com", EmailVerified: true, Name: "Ana", AuthMethods: []string{"pwd", "mfa"}})
+
+	ctx := context.Background()
+	provider, err := oidc.NewClient(nil).Discover(ctx, idp.Issuer()+"/")
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+
+	config := provider.OAuth2Config(idp.ClientID, idp.ClientSecret, "https://api.bowerbird.test/callback")
+	verifier := oauth2.GenerateVerifier()
+	code := authorize(t, provider.AuthCodeURL(config, "state", "nonce-1", verifier))
+
+	rawIDToken, err := provider.Exchange(ctx, config, code, verifier)
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+
+	if _, err := provider.VerifyIDToken(ctx, rawIDToken, idp.ClientID, "other-nonce"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Fatalf("expected nonce mismatch to be rejected, got %v", err)
+	}
+	if _, err := provider.VerifyIDToken(ctx, rawIDToken, "other-client", "nonce-1"); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Fatalf("expected wrong audience to be rejected, got %v", err)
+	}
+
+	token, err := provider.VerifyIDToken(ctx, rawIDToken, idp.ClientID, "nonce-1")
+	if err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+	if token.Subject != "sub-42" || token.Email != "ana@example.com" || token.EmailVerified == nil || !*token.EmailVerified || len(token.AuthMethods) != 2 {
+		t.Fatalf("unexpected id token: %+v", token)
+	}
+}
+
+func TestProviderRejectsWrongPKCEVerifier(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	ctx := context.Background()
+	provider, err := oidc.NewClient(nil).Discover(ctx, idp.Issuer())
+	if err != nil {
+		t.Fatalf("discover failed: %v", err)
+	}
+
+	config := provider.OAuth2Config(idp.ClientID, idp.ClientSecret, "https://api.bowerbird.test/callback")
+	code := authorize(t, provider.AuthCodeURL(config, "state", "nonce-1", oauth2.GenerateVerifier()))
+
+	if _, err := provider.Exchange(ctx, config, code, oauth2.GenerateVerifier()); err == nil {
+		t.Fatal("expected exchange with a different verifier to fail")
+	}
+}
+
+func TestDiscoverRejectsUnknownIssuer(t *testing.T) {
+	idp := oidctest.NewServer()
+	defer idp.Close()
+
+	if _, err := oidc.NewClient(nil).Discover(context.Background(), idp.Issuer()+"/tenant"); !errors.Is(err, oidc.ErrDiscovery) {
+		t.Fatalf("expected discovery error, got %v", err)
+	}
+}
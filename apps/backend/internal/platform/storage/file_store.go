@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"io"
 	"time"
 )
 
@@ -17,6 +18,18 @@ type WriteFileIfAbsentResult struct {
 	SizeBytes int64
 }
 
+// UploadFileInput streams Body to Path, replacing any file already there.
+type UploadFileInput struct {
+	Path        string
+	Body        io.Reader
+	ContentType string
+	Metadata    map[string]string
+}
+
+type UploadFileResult struct {
+	SizeBytes int64
+}
+
 type ReadFileInput struct {
 	Path string
 }
@@ -42,6 +55,16 @@ type PresignDownloadInput struct {
 	ExpiresIn time.Duration
 }
 
+type DeletePrefixInput struct {
+	Prefix string
+	// Match, when set, limits the deletion to the keys it accepts.
+	Match func(key string) bool
+}
+
+type DeleteFilesInput struct {
+	Paths []string
+}
+
 type FileReference struct {
 	Bucket string `json:"bucket"`
 	Key    string `json:"key"`
@@ -71,3 +94,32 @@ type FileStore interface {
 	PresignUpload(ctx context.Context, input PresignUploadInput) (*PresignUploadResult, error)
 	PresignDownload(ctx context.Context, input PresignDownloadInput) (*PresignDownloadResult, error)
 }
+
+// FileOpener streams a file instead of reading it into memory, for files
+// too large to hold at once such as data bundles.
+type FileOpener interface {
+	OpenFile(ctx context.Context, input ReadFileInput) (io.ReadCloser, error)
+}
+
+// FileUploader writes a file of unknown size as it is produced, holding at
+// most one part of it in memory.
+type FileUploader interface {
+	UploadFile(ctx context.Context, input UploadFileInput) (*UploadFileResult, error)
+}
+
+// StreamingFileStore is a FileStore that also streams large files.
+type StreamingFileStore interface {
+	FileStore
+	FileOpener
+	FileUploader
+}
+
+// PrefixDeleter removes whole folders, e.g. when an organization is purged.
+type PrefixDeleter interface {
+	DeletePrefix(ctx context.Context, input DeletePrefixInput) (int, error)
+}
+
+// FileDeleter removes individual files; missing files are not an error.
+type FileDeleter interface {
+	DeleteFiles(ctx context.Context, input DeleteFilesInput) (int, error)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"time"
 
@@ -22,6 +23,12 @@ type apiClient interface {
 	GetObject(ctx context.Context, params *awsS3.GetObjectInput, optFns ...func(*awsS3.Options)) (*awsS3.GetObjectOutput, error)
 	CopyObject(ctx context.Context, params *awsS3.CopyObjectInput, optFns ...func(*awsS3.Options)) (*awsS3.CopyObjectOutput, error)
 	DeleteObject(ctx context.Context, params *awsS3.DeleteObjectInput, optFns ...func(*awsS3.Options)) (*awsS3.DeleteObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *awsS3.ListObjectsV2Input, optFns ...func(*awsS3.Options)) (*awsS3.ListObjectsV2Output, error)
+	DeleteObjects(ctx context.Context, params *awsS3.DeleteObjectsInput, optFns ...func(*awsS3.Options)) (*awsS3.DeleteObjectsOutput, error)
+	CreateMultipartUpload(ctx context.Context, params *awsS3.CreateMultipartUploadInput, optFns ...func(*awsS3.Options)) (*awsS3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, params *awsS3.UploadPartInput, optFns ...func(*awsS3.Options)) (*awsS3.UploadPartOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *awsS3.CompleteMultipartUploadInput, optFns ...func(*awsS3.Options)) (*awsS3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *awsS3.AbortMultipartUploadInput, optFns ...func(*awsS3.Options)) (*awsS3.AbortMultipartUploadOutput, error)
 }
 
 type ObjectStore struct {
@@ -36,7 +43,18 @@ type presignAPIClient interface {
 	PresignGetObject(ctx context.Context, params *awsS3.GetObjectInput, optFns ...func(*awsS3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
 }
 
-var _ platformStorage.FileStore = (*ObjectStore)(nil)
+var (
+	_ platformStorage.StreamingFileStore = (*ObjectStore)(nil)
+	_ platformStorage.PrefixDeleter      = (*ObjectStore)(nil)
+	_ platformStorage.FileDeleter        = (*ObjectStore)(nil)
+)
+
+// deleteBatchSize is the most keys a DeleteObjects request accepts.
+const deleteBatchSize = 1000
+
+// uploadPartSize is the size of each part of a multipart upload, above the
+// 5 MiB minimum S3 accepts for every part but the last.
+const uploadPartSize = 8 << 20
 
 func NewObjectStore(client *awsS3.Client, bucket string) *ObjectStore {
 	return &ObjectStore{
@@ -97,6 +115,22 @@ func (s *ObjectStore) WriteFileIfAbsent(ctx context.Context, input platformStora
 }
 
 func (s *ObjectStore) ReadFile(ctx context.Context, input platformStorage.ReadFileInput) ([]byte, error) {
+	body, err := s.OpenFile(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read object body: %w", err)
+	}
+
+	return content, nil
+}
+
+// OpenFile returns the object body as it downloads; the caller closes it.
+func (s *ObjectStore) OpenFile(ctx context.Context, input platformStorage.ReadFileInput) (io.ReadCloser, error) {
 	if s.client == nil {
 		return nil, fmt.Errorf("s3 client is required")
 	}
@@ -114,14 +148,107 @@ func (s *ObjectStore) ReadFile(ctx context.Context, input platformStorage.ReadFi
 	if err != nil {
 		return nil, fmt.Errorf("get object: %w", err)
 	}
-	defer res.Body.Close()
 
-	body, err := io.ReadAll(res.Body)
+	return res.Body, nil
+}
+
+// UploadFile reads the body in parts of uploadPartSize. A body that fits in
+// one part is stored with a single PutObject; larger ones go through a
+// multipart upload, which is aborted if any part fails.
+func (s *ObjectStore) UploadFile(ctx context.Context, input platformStorage.UploadFileInput) (*platformStorage.UploadFileResult, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("s3 client is required")
+	}
+	if strings.TrimSpace(s.bucket) == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	if strings.TrimSpace(input.Path) == "" {
+		return nil, fmt.Errorf("path is required")
+	}
+	if input.Body == nil {
+		return nil, fmt.Errorf("body is required")
+	}
+
+	part := make([]byte, uploadPartSize)
+	n, err := io.ReadFull(input.Body, part)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		if _, err := s.client.PutObject(ctx, &awsS3.PutObjectInput{
+			Bucket:      aws.String(s.bucket),
+			Key:         aws.String(input.Path),
+			Body:        bytes.NewReader(part[:n]),
+			ContentType: aws.String(defaultContentType(input.ContentType)),
+			Metadata:    input.Metadata,
+		}); err != nil {
+			return nil, fmt.Errorf("put object: %w", err)
+		}
+		return &platformStorage.UploadFileResult{SizeBytes: int64(n)}, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("read object body: %w", err)
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	upload, err := s.client.CreateMultipartUpload(ctx, &awsS3.CreateMultipartUploadInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(input.Path),
+		ContentType: aws.String(defaultContentType(input.ContentType)),
+		Metadata:    input.Metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create multipart upload: %w", err)
 	}
 
-	return body, nil
+	size, err := s.uploadParts(ctx, input.Path, upload.UploadId, input.Body, part)
+	if err != nil {
+		// A detached context so that a cancelled upload is still cleaned up.
+		if _, abortErr := s.client.AbortMultipartUpload(context.WithoutCancel(ctx), &awsS3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      aws.String(input.Path),
+			UploadId: upload.UploadId,
+		}); abortErr != nil {
+			return nil, errors.Join(err, fmt.Errorf("abort multipart upload: %w", abortErr))
+		}
+		return nil, err
+	}
+
+	return &platformStorage.UploadFileResult{SizeBytes: size}, nil
+}
+
+// uploadParts uploads the first part, already read into buf, and the rest of
+// the body, then completes the upload.
+func (s *ObjectStore) uploadParts(ctx context.Context, path string, uploadID *string, body io.Reader, buf []byte) (int64, error) {
+	var completed []awsS3Types.CompletedPart
+	var size int64
+	n := len(buf)
+	for partNumber := int32(1); n > 0; partNumber++ {
+		res, err := s.client.UploadPart(ctx, &awsS3.UploadPartInput{
+			Bucket:     aws.String(s.bucket),
+			Key:        aws.String(path),
+			UploadId:   uploadID,
+			PartNumber: aws.Int32(partNumber),
+			Body:       bytes.NewReader(buf[:n]),
+		})
+		if err != nil {
+			return 0, fmt.Errorf("upload part %d: %w", partNumber, err)
+		}
+		completed = append(completed, awsS3Types.CompletedPart{ETag: res.ETag, PartNumber: aws.Int32(partNumber)})
+		size += int64(n)
+
+		var readErr error
+		n, readErr = io.ReadFull(body, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return 0, fmt.Errorf("read body: %w", readErr)
+		}
+	}
+
+	if _, err := s.client.CompleteMultipartUpload(ctx, &awsS3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.bucket),
+		Key:             aws.String(path),
+		UploadId:        uploadID,
+		MultipartUpload: &awsS3Types.CompletedMultipartUpload{Parts: completed},
+	}); err != nil {
+		return 0, fmt.Errorf("complete multipart upload: %w", err)
+	}
+	return size, nil
 }
 
 func (s *ObjectStore) Exists(ctx context.Context, input platformStorage.ExistsFileInput) (bool, error) {
@@ -186,6 +313,95 @@ func (s *ObjectStore) MoveFile(ctx context.Context, input platformStorage.MoveFi
 	return nil
 }
 
+// DeletePrefix lists and deletes every object under the prefix, one page
+// (up to 1000 keys) per batch request.
+func (s *ObjectStore) DeletePrefix(ctx context.Context, input platformStorage.DeletePrefixInput) (int, error) {
+	if s.client == nil {
+		return 0, fmt.Errorf("s3 client is required")
+	}
+	if strings.TrimSpace(s.bucket) == "" {
+		return 0, fmt.Errorf("bucket is required")
+	}
+	// An empty or rootless prefix would wipe unrelated objects.
+	if strings.TrimSpace(input.Prefix) == "" || !strings.HasSuffix(input.Prefix, "/") {
+		return 0, fmt.Errorf("prefix must be a non-empty folder path ending in /")
+	}
+
+	deleted := 0
+	var continuationToken *string
+	for {
+		page, err := s.client.ListObjectsV2(ctx, &awsS3.ListObjectsV2Input{
+			Bucket:            aws.String(s.bucket),
+			Prefix:            aws.String(input.Prefix),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("list objects: %w", err)
+		}
+
+		objects := make([]awsS3Types.ObjectIdentifier, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			if input.Match != nil && !input.Match(aws.ToString(object.Key)) {
+				continue
+			}
+			objects = append(objects, awsS3Types.ObjectIdentifier{Key: object.Key})
+		}
+		if err := s.deleteObjects(ctx, objects); err != nil {
+			return deleted, err
+		}
+		deleted += len(objects)
+
+		if !aws.ToBool(page.IsTruncated) {
+			return deleted, nil
+		}
+		continuationToken = page.NextContinuationToken
+	}
+}
+
+// DeleteFiles deletes the given keys in batches of up to 1000.
+func (s *ObjectStore) DeleteFiles(ctx context.Context, input platformStorage.DeleteFilesInput) (int, error) {
+	if s.client == nil {
+		return 0, fmt.Errorf("s3 client is required")
+	}
+	if strings.TrimSpace(s.bucket) == "" {
+		return 0, fmt.Errorf("bucket is required")
+	}
+
+	deleted := 0
+	for batch := range slices.Chunk(input.Paths, deleteBatchSize) {
+		objects := make([]awsS3Types.ObjectIdentifier, 0, len(batch))
+		for _, path := range batch {
+			if strings.TrimSpace(path) == "" {
+				continue
+			}
+			objects = append(objects, awsS3Types.ObjectIdentifier{Key: aws.String(path)})
+		}
+		if err := s.deleteObjects(ctx, objects); err != nil {
+			return deleted, err
+		}
+		deleted += len(objects)
+	}
+	return deleted, nil
+}
+
+func (s *ObjectStore) deleteObjects(ctx context.Context, objects []awsS3Types.ObjectIdentifier) error {
+	if len(objects) == 0 {
+		return nil
+	}
+
+	res, err := s.client.DeleteObjects(ctx, &awsS3.DeleteObjectsInput{
+		Bucket: aws.String(s.bucket),
+		Delete: &awsS3Types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+	})
+	if err != nil {
+		return fmt.Errorf("delete objects: %w", err)
+	}
+	if len(res.Errors) > 0 {
+		return fmt.Errorf("delete objects: %d keys failed, first %s: %s", len(res.Errors), aws.ToString(res.Errors[0].Key), aws.ToString(res.Errors[0].Message))
+	}
+	return nil
+}
+
 func (s *ObjectStore) PresignUpload(ctx context.Context, input platformStorage.PresignUploadInput) (*platformStorage.PresignUploadResult, error) {
 	if s.presignClient == nil {
 		return nil, fmt.Errorf("s3 presign client is required")
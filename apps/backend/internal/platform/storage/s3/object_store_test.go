@@ -5,17 +5,24 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	awsS3 "github.com/aws/aws-sdk-go-v2/service/s3"
+	awsS3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	platformStorage "github.com/bowerbird/internal/platform/storage"
 )
 
 type fakeS3Client struct {
 	objects map[string][]byte
+	// parts holds the parts of open multipart uploads by upload ID.
+	parts   map[string][][]byte
+	aborted int
+	failPut bool
 }
 
 func (f *fakeS3Client) HeadObject(ctx context.Context, params *awsS3.HeadObjectInput, optFns ...func(*awsS3.Options)) (*awsS3.HeadObjectOutput, error) {
@@ -72,6 +79,71 @@ func (f *fakeS3Client) DeleteObject(ctx context.Context, params *awsS3.DeleteObj
 	return &awsS3.DeleteObjectOutput{}, nil
 }
 
+// ListObjectsV2 pages two keys at a time to exercise continuation.
+func (f *fakeS3Client) ListObjectsV2(ctx context.Context, params *awsS3.ListObjectsV2Input, optFns ...func(*awsS3.Options)) (*awsS3.ListObjectsV2Output, error) {
+	var keys []string
+	for key := range f.objects {
+		// The continuation token is the last key of the previous page.
+		if strings.HasPrefix(key, *params.Prefix) && (params.ContinuationToken == nil || key > *params.ContinuationToken) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	page := keys[:min(2, len(keys))]
+	out := &awsS3.ListObjectsV2Output{IsTruncated: aws.Bool(len(page) < len(keys))}
+	for _, key := range page {
+		out.Contents = append(out.Contents, awsS3Types.Object{Key: aws.String(key)})
+	}
+	if len(page) < len(keys) {
+		out.NextContinuationToken = aws.String(page[len(page)-1])
+	}
+	return out, nil
+}
+
+func (f *fakeS3Client) DeleteObjects(ctx context.Context, params *awsS3.DeleteObjectsInput, optFns ...func(*awsS3.Options)) (*awsS3.DeleteObjectsOutput, error) {
+	for _, object := range params.Delete.Objects {
+		delete(f.objects, *object.Key)
+	}
+	return &awsS3.DeleteObjectsOutput{}, nil
+}
+
+func (f *fakeS3Client) CreateMultipartUpload(ctx context.Context, params *awsS3.CreateMultipartUploadInput, optFns ...func(*awsS3.Options)) (*awsS3.CreateMultipartUploadOutput, error) {
+	if f.parts == nil {
+		f.parts = map[string][][]byte{}
+	}
+	uploadID := "upload-" + *params.Key
+	f.parts[uploadID] = nil
+	return &awsS3.CreateMultipartUploadOutput{UploadId: aws.String(uploadID)}, nil
+}
+
+func (f *fakeS3Client) UploadPart(ctx context.Context, params *awsS3.UploadPartInput, optFns ...func(*awsS3.Options)) (*awsS3.UploadPartOutput, error) {
+	if f.failPut && *params.PartNumber > 1 {
+		return nil, errors.New("part rejected")
+	}
+	body, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.parts[*params.UploadId] = append(f.parts[*params.UploadId], body)
+	return &awsS3.UploadPartOutput{ETag: aws.String("etag")}, nil
+}
+
+func (f *fakeS3Client) CompleteMultipartUpload(ctx context.Context, params *awsS3.CompleteMultipartUploadInput, optFns ...func(*awsS3.Options)) (*awsS3.CompleteMultipartUploadOutput, error) {
+	if f.objects == nil {
+		f.objects = map[string][]byte{}
+	}
+	f.objects[*params.Key] = bytes.Join(f.parts[*params.UploadId], nil)
+	delete(f.parts, *params.UploadId)
+	return &awsS3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3Client) AbortMultipartUpload(ctx context.Context, params *awsS3.AbortMultipartUploadInput, optFns ...func(*awsS3.Options)) (*awsS3.AbortMultipartUploadOutput, error) {
+	delete(f.parts, *params.UploadId)
+	f.aborted++
+	return &awsS3.AbortMultipartUploadOutput{}, nil
+}
+
 type fakePresignClient struct{}
 
 func (f fakePresignClient) PresignPutObject(ctx context.Context, params *awsS3.PutObjectInput, optFns ...func(*awsS3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
@@ -128,6 +200,47 @@ func TestReadFileReadsObjectContent(t *testing.T) {
 	}
 }
 
+func TestUploadFileStreamsLargeBodiesInParts(t *testing.T) {
+	client := &fakeS3Client{}
+	store := NewObjectStoreWithClient(client, "bucket")
+	body := bytes.Repeat([]byte("x"), 2*uploadPartSize+10)
+
+	res, err := store.UploadFile(context.Background(), platformStorage.UploadFileInput{
+		Path: "tenant/t/exports/e.zip",
+		Body: bytes.NewReader(body),
+	})
+	if err != nil {
+		t.Fatalf("upload file failed: %v", err)
+	}
+	if res.SizeBytes != int64(len(body)) || !bytes.Equal(client.objects["tenant/t/exports/e.zip"], body) {
+		t.Fatalf("expected the whole body to be stored, got %d bytes", len(client.objects["tenant/t/exports/e.zip"]))
+	}
+
+	small, err := store.UploadFile(context.Background(), platformStorage.UploadFileInput{Path: "tenant/t/small", Body: strings.NewReader("abc")})
+	if err != nil || small.SizeBytes != 3 || string(client.objects["tenant/t/small"]) != "abc" {
+		t.Fatalf("expected a small body in one put, got %+v, %v", small, err)
+	}
+}
+
+func TestUploadFileAbortsFailedMultipartUpload(t *testing.T) {
+	client := &fakeS3Client{failPut: true}
+	store := NewObjectStoreWithClient(client, "bucket")
+
+	_, err := store.UploadFile(context.Background(), platformStorage.UploadFileInput{
+		Path: "tenant/t/exports/e.zip",
+		Body: bytes.NewReader(bytes.Repeat([]byte("x"), uploadPartSize+1)),
+	})
+	if err == nil {
+		t.Fatal("expected the upload to fail")
+	}
+	if client.aborted != 1 || len(client.parts) != 0 {
+		t.Fatalf("expected the multipart upload to be aborted, got %d aborts", client.aborted)
+	}
+	if _, ok := client.objects["tenant/t/exports/e.zip"]; ok {
+		t.Fatal("expected no object to be stored")
+	}
+}
+
 func TestExistsReturnsTrueWhenObjectExists(t *testing.T) {
 	client := &fakeS3Client{objects: map[string][]byte{"tenant/t/inbox/raw/key": []byte("abc")}}
 	store := NewObjectStoreWithClient(client, "bucket")
@@ -157,6 +270,71 @@ func TestMoveFileCopiesAndDeletesSource(t *testing.T) {
 	}
 }
 
+func TestDeletePrefixRemovesOnlyObjectsUnderPrefix(t *testing.T) {
+	client := &fakeS3Client{objects: map[string][]byte{
+		"tenant/a/1":   nil,
+		"tenant/a/2":   nil,
+		"tenant/a/x/3": nil,
+		"tenant/ab/4":  nil,
+		"tenant/b/5":   nil,
+	}}
+	store := NewObjectStoreWithClient(client, "bucket")
+
+	deleted, err := store.DeletePrefix(context.Background(), platformStorage.DeletePrefixInput{Prefix: "tenant/a/"})
+	if err != nil {
+		t.Fatalf("delete prefix failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Fatalf("expected 3 deleted objects, got %d", deleted)
+	}
+	if len(client.objects) != 2 {
+		t.Fatalf("expected other tenants' objects to remain, got %v", client.objects)
+	}
+
+	if _, err := store.DeletePrefix(context.Background(), platformStorage.DeletePrefixInput{Prefix: "tenant/a"}); err == nil {
+		t.Fatal("expected prefix without trailing slash to be rejected")
+	}
+}
+
+func TestDeletePrefixOnlyRemovesMatchingKeys(t *testing.T) {
+	client := &fakeS3Client{objects: map[string][]byte{
+		"1-day/tenants/a/uploads/invoices/user-1/a.pdf": nil,
+		"1-day/tenants/a/uploads/invoices/user-2/b.pdf": nil,
+		"1-day/tenants/a/uploads/other/user-1/c.pdf":    nil,
+	}}
+	store := NewObjectStoreWithClient(client, "bucket")
+
+	deleted, err := store.DeletePrefix(context.Background(), platformStorage.DeletePrefixInput{
+		Prefix: "1-day/tenants/a/uploads/",
+		Match:  func(key string) bool { return strings.Contains(key, "/user-1/") },
+	})
+	if err != nil {
+		t.Fatalf("delete prefix failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("expected 2 deleted objects, got %d", deleted)
+	}
+	if _, ok := client.objects["1-day/tenants/a/uploads/invoices/user-2/b.pdf"]; !ok || len(client.objects) != 1 {
+		t.Fatalf("expected only the other user's upload to remain, got %v", client.objects)
+	}
+}
+
+func TestDeleteFilesRemovesGivenKeys(t *testing.T) {
+	client := &fakeS3Client{objects: map[string][]byte{"a": nil, "b": nil, "c": nil}}
+	store := NewObjectStoreWithClient(client, "bucket")
+
+	deleted, err := store.DeleteFiles(context.Background(), platformStorage.DeleteFilesInput{Paths: []string{"a", "", "c", "missing"}})
+	if err != nil {
+		t.Fatalf("delete files failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Fatalf("expected 3 requested deletions, got %d", deleted)
+	}
+	if len(client.objects) != 1 {
+		t.Fatalf("expected only b to remain, got %v", client.objects)
+	}
+}
+
 func TestPresignUploadReturnsURLAndReference(t *testing.T) {
 	store := NewObjectStoreWithClients(&fakeS3Client{}, fakePresignClient{}, "bucket")
 
@@ -6,8 +6,12 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/bowerbird/internal/platform/audit"
+	auditPostgres "github.com/bowerbird/internal/platform/audit/postgres"
 	awsConfig "github.com/bowerbird/internal/platform/awsconfig"
 	"github.com/bowerbird/internal/platform/config"
+	platformCrypto "github.com/bowerbird/internal/platform/crypto"
+	cryptoPostgres "github.com/bowerbird/internal/platform/crypto/postgres"
 	"github.com/bowerbird/internal/platform/database"
 	"github.com/bowerbird/internal/platform/events"
 	"github.com/bowerbird/internal/platform/jobs"
@@ -21,9 +25,15 @@ type Dependencies struct {
 	ControlDB      *pgxpool.Pool
 	AWSConfig      aws.Config
 	TenantRegistry *database.Registry
-	FileStore      platformStorage.FileStore
+	FileStore      platformStorage.StreamingFileStore
+	FilePurger     platformStorage.PrefixDeleter
+	FileDeleter    platformStorage.FileDeleter
 	EventBus       events.EventBus
 	JobQueue       jobs.Queue
+	AuditRecorder  audit.Recorder
+	// CredentialsCipher protects OAuth credentials, OAuth state and TOTP
+	// secrets with envelope encryption under the configured master keys.
+	CredentialsCipher *platformCrypto.EnvelopeCipher
 }
 
 func NewModule(ctx context.Context) (*Dependencies, error) {
@@ -32,12 +42,31 @@ func NewModule(ctx context.Context) (*Dependencies, error) {
 		return nil, fmt.Errorf("load config: %w", err)
 	}
 
+	credentialsCipher, err := NewCredentialsCipher(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("build credentials cipher: %w", err)
+	}
+
 	controlDB, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("connect postgres: %w", err)
 	}
 
-	tenantRegistry := database.NewRegistry(controlDB, buildBaseTenantDBUrl(cfg.DatabaseURL))
+	// A master key dropped from the ring while records still use it would
+	// leave them undecryptable, so the process refuses to start instead.
+	keyUsage, err := cryptoPostgres.NewKeyUsageStore(controlDB).KeyUsage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load credentials key usage: %w", err)
+	}
+	if err := credentialsCipher.CheckKeyUsage(keyUsage); err != nil {
+		return nil, fmt.Errorf("check credentials key ring: %w", err)
+	}
+
+	tenantRegistry := database.NewRegistry(controlDB, buildBaseTenantDBUrl(cfg.DatabaseURL), database.RegistryConfig{
+		MaxPools:    cfg.TenantDBMaxPools,
+		MaxConns:    cfg.TenantDBMaxConns,
+		IdleTimeout: cfg.TenantDBPoolIdleTimeout,
+	})
 
 	awsCfg, err := awsConfig.Load(ctx, cfg.AWSRegion, cfg.AWSEndpointURL, cfg.AWSAccessKeyID, cfg.AWSSecretAccessKey)
 	if err != nil {
@@ -60,11 +89,55 @@ func NewModule(ctx context.Context) (*Dependencies, error) {
 		AWSConfig:      awsCfg,
 		TenantRegistry: tenantRegistry,
 		FileStore:      fileStore,
+		FilePurger:     fileStore,
+		FileDeleter:    fileStore,
 		EventBus:       eventBus,
 		JobQueue:       jobQueue,
+		AuditRecorder:  audit.NewRecorder(auditPostgres.NewStore(tenantRegistry)),
+
+		CredentialsCipher: credentialsCipher,
 	}, nil
 }
 
+// NewCredentialsCipher builds the envelope cipher from, in order of
+// preference, the key file, the configured master keys or, when neither is
+// set, inbox_credentials_encryption_key as the only master key. That key also
+// keeps decrypting ciphertext written before envelopes existed.
+func NewCredentialsCipher(cfg config.Config) (*platformCrypto.EnvelopeCipher, error) {
+	var legacy *platformCrypto.AESCipher
+	if cfg.InboxCredentialsEncryptionKey != "" {
+		var err error
+		legacy, err = platformCrypto.NewAESCipherFromBase64Key(cfg.InboxCredentialsEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("legacy credentials key: %w", err)
+		}
+	}
+
+	var keys platformCrypto.KeyManager
+	var err error
+	switch {
+	case cfg.CredentialsKeyFile != "":
+		keys, err = platformCrypto.LoadLocalKeyManager(cfg.CredentialsKeyFile)
+	case len(cfg.CredentialsMasterKeys) > 0:
+		masterKeys := make([]platformCrypto.MasterKey, 0, len(cfg.CredentialsMasterKeys))
+		for _, key := range cfg.CredentialsMasterKeys {
+			masterKeys = append(masterKeys, platformCrypto.MasterKey{KeyID: key.KeyID, Key: key.Key})
+		}
+		keys, err = platformCrypto.NewLocalKeyManager(cfg.CredentialsActiveKeyID, masterKeys)
+	default:
+		keys, err = platformCrypto.NewLocalKeyManager(legacyMasterKeyID, []platformCrypto.MasterKey{{KeyID: legacyMasterKeyID, Key: cfg.InboxCredentialsEncryptionKey}})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return platformCrypto.NewEnvelopeCipher(keys, legacy), nil
+}
+
+// legacyMasterKeyID names inbox_credentials_encryption_key when it is the
+// only master key.
+const legacyMasterKeyID = "legacy"
+
 func buildBaseTenantDBUrl(databaseURL string) string {
 	baseDbURL := strings.Replace(databaseURL, "/bowerbird?", "/%s?", 1)
 	if baseDbURL == databaseURL {
@@ -0,0 +1 @@
+ALTER TABLE tenant_memberships DROP COLUMN version;
@@ -0,0 +1,4 @@
+-- Se incrementa en cada cambio de rol o baja de la membresía.
+-- Los access tokens con alcance de organización guardan la versión con la que fueron emitidos
+-- y dejan de ser válidos en cuanto no coincide.
+ALTER TABLE tenant_memberships ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
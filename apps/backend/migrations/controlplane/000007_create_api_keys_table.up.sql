@@ -0,0 +1,18 @@
+-- Credenciales de máquina por organización (integraciones ERP, scripts).
+-- Solo se guarda el SHA-256 del secreto; el token completo se muestra una única vez al crearlo.
+-- scopes contiene códigos de la tabla permissions del esquema de la organización.
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id CHAR(26) PRIMARY KEY,
+    tenant_id CHAR(26) NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    secret_hash BYTEA NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_by CHAR(26) NOT NULL REFERENCES users(id),
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id ON api_keys(tenant_id);
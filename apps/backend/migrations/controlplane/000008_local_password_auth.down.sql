@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS auth_tokens;
+
+ALTER TABLE users DROP COLUMN email_verified_at;
+
+UPDATE user_identities
+SET provider_id = password_hash
+WHERE provider = 'local' AND password_hash IS NOT NULL;
+
+ALTER TABLE user_identities DROP COLUMN password_hash;
@@ -0,0 +1,36 @@
+-- Credenciales locales en su propia columna en lugar de reutilizar provider_id.
+ALTER TABLE user_identities ADD COLUMN password_hash VARCHAR(255);
+
+UPDATE user_identities
+SET password_hash = provider_id, provider_id = user_id
+WHERE provider = 'local';
+
+-- Los usuarios existentes entraron por OAuth o en entornos locales: se consideran verificados.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP WITH TIME ZONE;
+UPDATE users SET email_verified_at = created_at;
+
+-- Tokens de un solo uso (verificación de email, restablecimiento de contraseña).
+-- Solo se guarda el SHA-256 del token enviado por email.
+CREATE TABLE IF NOT EXISTS auth_tokens (
+    id CHAR(26) PRIMARY KEY,
+    user_id CHAR(26) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(50) NOT NULL,
+    token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_auth_tokens_user_id ON auth_tokens(user_id);
+
+-- Intentos de login local, para el bloqueo por cuenta y por IP.
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(64) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_email ON login_attempts(email, attempted_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip ON login_attempts(ip_address, attempted_at);
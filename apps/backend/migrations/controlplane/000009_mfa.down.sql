@@ -0,0 +1,4 @@
+ALTER TABLE tenants DROP COLUMN mfa_required;
+
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_mfa_factors;
@@ -0,0 +1,12 @@
+-- Proveedor OIDC de cada organización. El client secret se guarda cifrado (AES-GCM).
+CREATE TABLE IF NOT EXISTS sso_connections (
+    tenant_id CHAR(26) PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    issuer VARCHAR(512) NOT NULL,
+    client_id VARCHAR(255) NOT NULL,
+    encrypted_client_secret BYTEA NOT NULL,
+    allowed_domains TEXT[] NOT NULL,
+    sso_only BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
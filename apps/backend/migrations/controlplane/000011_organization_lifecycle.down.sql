@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS organization_audit_events;
+DROP TABLE IF EXISTS tenant_slug_redirects;
+DROP INDEX IF EXISTS idx_tenants_deletion_scheduled_at;
+ALTER TABLE tenants DROP COLUMN IF EXISTS status_before_deletion;
+ALTER TABLE tenants DROP COLUMN IF EXISTS deletion_scheduled_at;
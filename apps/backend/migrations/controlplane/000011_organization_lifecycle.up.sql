@@ -0,0 +1,28 @@
+-- Ciclo de vida de organizaciones: suspensión, archivado y borrado con periodo de gracia.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+-- Estado al que vuelve la organización si se cancela el borrado.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS status_before_deletion VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_tenants_deletion_scheduled_at ON tenants(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Slugs anteriores de cada organización, para redirigir enlaces y eventos antiguos.
+CREATE TABLE IF NOT EXISTS tenant_slug_redirects (
+    slug VARCHAR(100) PRIMARY KEY,
+    tenant_id CHAR(26) NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tenant_slug_redirects_tenant_id ON tenant_slug_redirects(tenant_id);
+
+-- Auditoría de operaciones sobre la organización. Sin clave foránea a tenants:
+-- el registro debe sobrevivir al borrado definitivo.
+CREATE TABLE IF NOT EXISTS organization_audit_events (
+    id CHAR(26) PRIMARY KEY,
+    tenant_id CHAR(26) NOT NULL,
+    actor_user_id CHAR(26),
+    action VARCHAR(100) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_audit_events_tenant_id ON organization_audit_events(tenant_id, created_at);
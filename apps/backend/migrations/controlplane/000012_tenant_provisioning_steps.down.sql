@@ -0,0 +1,3 @@
+ALTER TABLE tenants DROP COLUMN IF EXISTS provisioning_owner;
+ALTER TABLE tenants DROP COLUMN IF EXISTS provisioning_error;
+ALTER TABLE tenants DROP COLUMN IF EXISTS provisioning_step;
@@ -0,0 +1,8 @@
+-- Aprovisionamiento reanudable: último paso completado, último error y el
+-- propietario que se debe sembrar al reanudar.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS provisioning_step VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS provisioning_error TEXT;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS provisioning_owner JSONB;
+
+-- Las organizaciones ya aprovisionadas completaron todos los pasos.
+UPDATE tenants SET provisioning_step = 'membership_added' WHERE status NOT IN ('provisioning', 'failed');
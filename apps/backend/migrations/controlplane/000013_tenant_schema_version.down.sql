@@ -0,0 +1,4 @@
+ALTER TABLE tenants DROP COLUMN IF EXISTS last_migrated_at;
+ALTER TABLE tenants DROP COLUMN IF EXISTS last_migration_error;
+ALTER TABLE tenants DROP COLUMN IF EXISTS schema_dirty;
+ALTER TABLE tenants DROP COLUMN IF EXISTS schema_version;
@@ -0,0 +1,5 @@
+-- Versión del esquema de cada base de tenant, registrada por cmd/migrate.
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS schema_version BIGINT;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS schema_dirty BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS last_migration_error TEXT;
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS last_migrated_at TIMESTAMP WITH TIME ZONE;
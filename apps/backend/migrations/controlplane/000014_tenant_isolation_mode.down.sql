@@ -0,0 +1,3 @@
+ALTER TABLE tenants DROP CONSTRAINT IF EXISTS tenants_isolation_mode_check;
+ALTER TABLE tenants DROP COLUMN IF EXISTS shared_database;
+ALTER TABLE tenants DROP COLUMN IF EXISTS isolation_mode;
@@ -0,0 +1,9 @@
+-- Modo de aislamiento de cada tenant: base de datos dedicada ('database') o
+-- un esquema llamado db_name dentro de una base compartida ('schema').
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS isolation_mode VARCHAR(20) NOT NULL DEFAULT 'database';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS shared_database VARCHAR(63);
+
+ALTER TABLE tenants ADD CONSTRAINT tenants_isolation_mode_check CHECK (
+    (isolation_mode = 'database' AND shared_database IS NULL)
+    OR (isolation_mode = 'schema' AND shared_database IS NOT NULL)
+);
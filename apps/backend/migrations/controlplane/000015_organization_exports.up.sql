@@ -0,0 +1,18 @@
+-- Exportaciones de datos (portabilidad) solicitadas por el propietario de
+-- cada organización. El ZIP se guarda en el almacén de archivos bajo el
+-- prefijo del tenant, por lo que se elimina junto con la organización.
+CREATE TABLE IF NOT EXISTS organization_exports (
+    id CHAR(26) PRIMARY KEY,
+    tenant_id CHAR(26) NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    requested_by CHAR(26) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    file_path TEXT,
+    size_bytes BIGINT,
+    sha256 CHAR(64),
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_exports_tenant_id ON organization_exports(tenant_id, created_at);
@@ -0,0 +1,23 @@
+-- Importaciones de paquetes de exportación a una organización recién
+-- aprovisionada. El propietario sube el ZIP con una URL prefirmada y un job
+-- lo restaura; las columnas de progreso se actualizan mientras se ejecuta.
+CREATE TABLE IF NOT EXISTS organization_imports (
+    id CHAR(26) PRIMARY KEY,
+    tenant_id CHAR(26) NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    requested_by CHAR(26) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'awaiting_upload',
+    file_path TEXT NOT NULL,
+    source_organization_id CHAR(26),
+    step VARCHAR(30),
+    records_total INTEGER NOT NULL DEFAULT 0,
+    records_imported INTEGER NOT NULL DEFAULT 0,
+    files_total INTEGER NOT NULL DEFAULT 0,
+    files_imported INTEGER NOT NULL DEFAULT 0,
+    remapped_ids INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_imports_tenant_id ON organization_imports(tenant_id, created_at);
@@ -0,0 +1,21 @@
+-- Solicitudes de supresión de cuenta (derecho de supresión, RGPD art. 17).
+-- La supresión se ejecuta en segundo plano cuando vence el periodo de gracia;
+-- hasta entonces el usuario puede cancelarla. El informe resume lo que se
+-- borró, transfirió o anonimizó en cada organización.
+CREATE TABLE IF NOT EXISTS account_erasures (
+    id CHAR(26) PRIMARY KEY,
+    user_id CHAR(26) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled', -- scheduled | running | completed | failed | cancelled
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    scheduled_for TIMESTAMP WITH TIME ZONE NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    identifiers JSONB, -- emails a anonimizar en los registros de auditoría; se borran al completar
+    report JSONB,
+    error TEXT
+);
+
+-- Una sola supresión pendiente o en curso por usuario.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_account_erasures_user_open ON account_erasures(user_id) WHERE status IN ('scheduled', 'running');
+CREATE INDEX IF NOT EXISTS idx_account_erasures_due ON account_erasures(scheduled_for) WHERE status IN ('scheduled', 'running', 'failed');
@@ -0,0 +1 @@
+ALTER TABLE auth_tokens DROP COLUMN IF EXISTS failed_attempts;
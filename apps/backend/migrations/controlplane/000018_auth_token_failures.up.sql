@@ -0,0 +1,4 @@
+-- Intentos fallidos contra un token de un solo uso. Un reto MFA se consume al
+-- alcanzar el máximo de códigos erróneos, además de contar para el bloqueo de
+-- la cuenta en login_attempts.
+ALTER TABLE auth_tokens ADD COLUMN IF NOT EXISTS failed_attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS credentials_key_usage;
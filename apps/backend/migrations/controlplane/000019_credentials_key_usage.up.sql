@@ -0,0 +1,11 @@
+-- Registros cifrados por cada clave maestra según el último reenvoltorio de
+-- credenciales. scope separa el módulo que los cuenta (connections, identity)
+-- y key_id vacío denota texto cifrado anterior al formato de sobre. Mientras
+-- una clave figure aquí, el arranque rechaza un anillo de claves sin ella.
+CREATE TABLE IF NOT EXISTS credentials_key_usage (
+    scope VARCHAR(64) NOT NULL,
+    key_id VARCHAR(255) NOT NULL,
+    records BIGINT NOT NULL,
+    counted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, key_id)
+);
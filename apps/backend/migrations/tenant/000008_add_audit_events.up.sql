@@ -0,0 +1,45 @@
+-- Registro de auditoría de la organización: quién hizo qué, sobre qué y desde dónde.
+CREATE TABLE audit_events (
+    id CHAR(26) PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    actor_type VARCHAR(20) NOT NULL, -- user | api_key | system
+    actor_user_id CHAR(26),
+    actor_api_key_id CHAR(26),
+    action VARCHAR(100) NOT NULL,
+    target_type VARCHAR(50),
+    target_id VARCHAR(255),
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    trace_id VARCHAR(255),
+    changes JSONB, -- diff antes/después por campo
+    metadata JSONB
+);
+
+CREATE INDEX ix_audit_events_occurred_at ON audit_events (occurred_at DESC);
+CREATE INDEX ix_audit_events_action ON audit_events (action, occurred_at DESC);
+CREATE INDEX ix_audit_events_actor_user ON audit_events (actor_user_id, occurred_at DESC) WHERE actor_user_id IS NOT NULL;
+CREATE INDEX ix_audit_events_target ON audit_events (target_type, target_id);
+
+-- Solo se permite añadir eventos: ninguna modificación ni borrado.
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tr_audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER tr_audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_events_append_only();
+
+-- Permiso para consultar y exportar el registro; se concede al rol admin.
+INSERT INTO permissions (id, code, description) VALUES
+('01JW58TAT9M0N4R8M1P3Q6R9Y5', 'audit:read', 'Consultar y exportar el registro de auditoría');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.code = 'audit:read';
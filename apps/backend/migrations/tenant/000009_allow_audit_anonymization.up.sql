@@ -0,0 +1,24 @@
+-- La supresión de una cuenta debe anonimizar los datos personales que el
+-- registro de auditoría guarda (IP, user agent, emails en los diffs). El
+-- registro sigue siendo de solo inserción salvo en la sesión que activa
+-- bowerbird.audit_erasure, y aun así solo pueden cambiar esas columnas.
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE'
+        AND current_setting('bowerbird.audit_erasure', true) = 'on'
+        AND NEW.id = OLD.id
+        AND NEW.occurred_at = OLD.occurred_at
+        AND NEW.actor_type = OLD.actor_type
+        AND NEW.actor_user_id IS NOT DISTINCT FROM OLD.actor_user_id
+        AND NEW.actor_api_key_id IS NOT DISTINCT FROM OLD.actor_api_key_id
+        AND NEW.action = OLD.action
+        AND NEW.target_type IS NOT DISTINCT FROM OLD.target_type
+        AND NEW.target_id IS NOT DISTINCT FROM OLD.target_id
+        AND NEW.trace_id IS NOT DISTINCT FROM OLD.trace_id
+    THEN
+        RETURN NEW;
+    END IF;
+
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,10 @@
+-- Permiso para ver, pausar, reanudar y eliminar las conexiones de otros
+-- miembros; se concede al rol admin. Leer el correo privado, compartir y
+-- reconectar siguen reservados al propietario.
+INSERT INTO permissions (id, code, description) VALUES
+('01JX4K7N2P5R8T1V3W6Y9Z2B4D', 'connections:manage', 'Administrar las conexiones de correo de la organización');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.code = 'connections:manage';
@@ -0,0 +1 @@
+ALTER TABLE connections DROP COLUMN IF EXISTS requires_reconnect_reason;
@@ -0,0 +1,4 @@
+-- Motivo estructurado por el que una conexión requiere reconexión
+-- (grant_revoked, missing_refresh_token, scope_downgraded, provider_rejected,
+-- ownership_transferred). Se limpia al volver a activarla.
+ALTER TABLE connections ADD COLUMN requires_reconnect_reason VARCHAR(50);
@@ -0,0 +1,9 @@
+ALTER TABLE inbox_sync_cursors
+    DROP COLUMN IF EXISTS consecutive_failures,
+    DROP COLUMN IF EXISTS last_succeeded_at,
+    DROP COLUMN IF EXISTS last_failed_at,
+    DROP COLUMN IF EXISTS last_error_code,
+    DROP COLUMN IF EXISTS error_counts,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS rate_limited_until,
+    DROP COLUMN IF EXISTS suspended_at;
@@ -0,0 +1,15 @@
+-- Salud de la sincronización de cada conexión: fallos consecutivos, ventanas
+-- de rate limit, último éxito, histograma de códigos de error y el momento a
+-- partir del cual se permite el siguiente intento.
+ALTER TABLE inbox_sync_cursors
+    ADD COLUMN consecutive_failures INTEGER DEFAULT 0 NOT NULL,
+    ADD COLUMN last_succeeded_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN last_failed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN last_error_code VARCHAR(50),
+    ADD COLUMN error_counts JSONB DEFAULT '{}'::jsonb NOT NULL,
+    ADD COLUMN next_attempt_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN rate_limited_until TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN suspended_at TIMESTAMP WITH TIME ZONE;
+
+-- Hasta ahora una sincronización correcta era la que dejaba el cursor en idle.
+UPDATE inbox_sync_cursors SET last_succeeded_at = last_synced_at WHERE status = 'idle';
@@ -0,0 +1,4 @@
+-- Reglas de ingesta por conexión: remitentes permitidos y bloqueados,
+-- etiquetas, patrones de asunto, adjuntos obligatorios y antigüedad máxima.
+-- Un objeto vacío sincroniza todo el correo que devuelve el proveedor.
+ALTER TABLE connections ADD COLUMN ingestion_rules JSONB DEFAULT '{}'::jsonb NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS inbox_label_cache;
+ALTER TABLE connections DROP COLUMN IF EXISTS write_back;
@@ -0,0 +1,13 @@
+-- Escritura de vuelta en el buzón: etiqueta los mensajes procesados o con
+-- error y, opcionalmente, los archiva. Desactivada por defecto.
+ALTER TABLE connections ADD COLUMN write_back JSONB DEFAULT '{}'::jsonb NOT NULL;
+
+-- Caché de los IDs de etiqueta del proveedor por conexión, para no crear ni
+-- buscar la etiqueta en cada mensaje.
+CREATE TABLE IF NOT EXISTS inbox_label_cache (
+    connection_id CHAR(26) NOT NULL REFERENCES connections(id) ON DELETE CASCADE,
+    label_name TEXT NOT NULL,
+    label_id TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (connection_id, label_name)
+);
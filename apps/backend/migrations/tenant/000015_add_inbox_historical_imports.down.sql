@@ -0,0 +1 @@
+DROP TABLE IF EXISTS inbox_historical_imports;
@@ -0,0 +1,32 @@
+-- Importaciones históricas: traen el correo de un rango de fechas de una
+-- conexión, aparte de la sincronización incremental. Se ejecutan por
+-- ventanas de 7 días y guardan el punto de control (ventana y página del
+-- proveedor) tras cada página, para reanudarse donde se quedaron.
+CREATE TABLE IF NOT EXISTS inbox_historical_imports (
+    id CHAR(26) PRIMARY KEY,
+    connection_id CHAR(26) NOT NULL REFERENCES connections(id) ON DELETE CASCADE,
+    requested_by CHAR(26),
+    since TIMESTAMPTZ NOT NULL,
+    until TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    progress JSONB DEFAULT '{}'::jsonb NOT NULL,
+    window_start TIMESTAMPTZ NOT NULL,
+    page_token TEXT DEFAULT '' NOT NULL,
+    consecutive_failures INTEGER DEFAULT 0 NOT NULL,
+    next_attempt_at TIMESTAMPTZ,
+    rate_limited_until TIMESTAMPTZ,
+    lease_until TIMESTAMPTZ,
+    error TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+-- Una sola importación en curso por conexión.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_inbox_historical_imports_in_progress
+    ON inbox_historical_imports (connection_id)
+    WHERE status IN ('pending', 'running');
+
+CREATE INDEX IF NOT EXISTS idx_inbox_historical_imports_connection
+    ON inbox_historical_imports (connection_id, created_at DESC);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS inbox_sync_runs;
+
+UPDATE inbox_sync_cursors SET status = 'error' WHERE status = 'partially_synced';
+
+ALTER TABLE inbox_sync_cursors
+    DROP COLUMN IF EXISTS checkpoint;
@@ -0,0 +1,22 @@
+-- Punto de control de la sincronización incremental: rango, consulta,
+-- página del proveedor y correos ya procesados de esa página. Se guarda tras
+-- cada página y se borra al terminar, para que una sincronización que falla
+-- se reanude donde se quedó.
+ALTER TABLE inbox_sync_cursors
+    ADD COLUMN checkpoint JSONB;
+
+-- Historial de ejecuciones de la sincronización de cada conexión, con sus
+-- estadísticas. Se conservan 30 días.
+CREATE TABLE IF NOT EXISTS inbox_sync_runs (
+    id CHAR(26) PRIMARY KEY,
+    connection_id CHAR(26) NOT NULL REFERENCES connections(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    resumed BOOLEAN DEFAULT FALSE NOT NULL,
+    stats JSONB DEFAULT '{}'::jsonb NOT NULL,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_inbox_sync_runs_connection
+    ON inbox_sync_runs (connection_id, started_at DESC);
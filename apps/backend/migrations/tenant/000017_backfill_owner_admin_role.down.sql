@@ -0,0 +1,2 @@
+-- El relleno no se deshace: no se distingue de una asignación posterior.
+SELECT 1;
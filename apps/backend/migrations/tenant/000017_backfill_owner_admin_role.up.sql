@@ -0,0 +1,10 @@
+-- El alta del propietario no le asignaba el rol admin, así que sus tokens de
+-- organización no tenían permisos. Los únicos usuarios sin ningún rol son esos
+-- propietarios: no hay otra vía de alta de usuarios en la base del tenant.
+INSERT INTO user_roles (user_id, role_id)
+SELECT u.id, r.id
+FROM users u
+JOIN roles r ON r.name = 'admin'
+WHERE u.deleted_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM user_roles ur WHERE ur.user_id = u.id)
+ON CONFLICT (user_id, role_id) DO NOTHING;
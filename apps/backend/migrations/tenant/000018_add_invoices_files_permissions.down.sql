@@ -0,0 +1 @@
+DELETE FROM permissions WHERE code IN ('invoices:read', 'invoices:write', 'files:read', 'files:write');
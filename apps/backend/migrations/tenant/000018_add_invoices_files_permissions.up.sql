@@ -0,0 +1,13 @@
+-- Permisos para consultar y extraer facturas y para subir y descargar
+-- archivos. Permiten dar a una API key solo el acceso que necesita una
+-- integración (por ejemplo, un ERP); se conceden al rol admin.
+INSERT INTO permissions (id, code, description) VALUES
+('01JZ3M8Q4R6T9V2X5Z7B1D3F5H', 'invoices:read', 'Consultar las facturas de la organización'),
+('01JZ3M8Q4R6T9V2X5Z7B1D3F5J', 'invoices:write', 'Extraer facturas de archivos subidos'),
+('01JZ3M8Q4R6T9V2X5Z7B1D3F5K', 'files:read', 'Descargar archivos de la organización'),
+('01JZ3M8Q4R6T9V2X5Z7B1D3F5M', 'files:write', 'Subir archivos a la organización');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.name = 'admin' AND p.code IN ('invoices:read', 'invoices:write', 'files:read', 'files:write');
@@ -1,10 +1,16 @@
 import { Injectable, inject, signal } from '@angular/core';
 import { Router } from '@angular/router';
+import { Observable, filter, map, of, switchMap, take, timer } from 'rxjs';
 import { AuthStore } from './auth.store';
 import { TenantMembership } from '../domain/auth.model';
-import { OrganizationHttpService } from '../../organization/infrastructure/organization.http.service';
+import {
+  OrganizationHttpService,
+  OrganizationResponse,
+} from '../../organization/infrastructure/organization.http.service';
 import { ToastService } from '../../core/services/toast.service';
 
+const PROVISIONING_POLL_MS = 2000;
+
 @Injectable({ providedIn: 'root' })
 export class LobbyStore {
   readonly showCreateForm = signal(false);
@@ -59,18 +65,25 @@ export class LobbyStore {
     this.isCreating.set(true);
     this.createError.set('');
 
-    this.orgService.createOrganization({ name, slug }).subscribe({
-      next: () => {
-        this.isCreating.set(false);
-        this.toggleCreateForm();
-        this.authStore.loadTenants();
-        this.toast.showSuccess('Organización creada correctamente');
-      },
-      error: (err) => {
-        this.isCreating.set(false);
-        this.createError.set(err.error || 'Failed to create organization');
-      },
-    });
+    this.orgService
+      .createOrganization({ name, slug })
+      .pipe(switchMap((org) => this.waitForProvisioning(org)))
+      .subscribe({
+        next: (status) => {
+          this.isCreating.set(false);
+          if (status !== 'active') {
+            this.createError.set('No se pudo aprovisionar la organización');
+            return;
+          }
+          this.toggleCreateForm();
+          this.authStore.loadTenants();
+          this.toast.showSuccess('Organización creada correctamente');
+        },
+        error: (err) => {
+          this.isCreating.set(false);
+          this.createError.set(err.error || 'Failed to create organization');
+        },
+      });
   }
 
   logout(): void {
@@ -89,6 +102,20 @@ export class LobbyStore {
     this.newOrgSlug.set(slug);
   }
 
+  // Provisioning runs in the background; poll until it leaves "provisioning".
+  private waitForProvisioning(org: OrganizationResponse): Observable<string> {
+    if (org.status !== 'provisioning') {
+      return of(org.status);
+    }
+
+    return timer(PROVISIONING_POLL_MS, PROVISIONING_POLL_MS).pipe(
+      switchMap(() => this.orgService.getProvisioningStatus(org.id)),
+      filter((status) => status.status !== 'provisioning'),
+      take(1),
+      map((status) => status.status),
+    );
+  }
+
   private generateSlug(text: string): string {
     return text
       .toLowerCase()
@@ -1,7 +1,7 @@
 export type MailProvider = 'gmail' | 'microsoft' | 'outlook' | 'yahoo' | 'hotmail';
 
 export type ConnectionStatus = 'active' | 'requires_reconnect' | 'paused' | 'error';
-export type SyncStatus = 'idle' | 'syncing' | 'error';
+export type SyncStatus = 'idle' | 'syncing' | 'partially_synced' | 'error';
 
 export const MAIL_PROVIDERS: MailProvider[] = ['gmail', 'microsoft', 'outlook', 'yahoo', 'hotmail'];
 
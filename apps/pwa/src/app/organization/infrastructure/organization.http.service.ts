@@ -18,6 +18,14 @@ export interface OrganizationResponse {
   current_user_role?: string;
 }
 
+export interface ProvisioningStatusResponse {
+  id: string;
+  slug: string;
+  status: string;
+  completed_steps: string[];
+  error?: string;
+}
+
 @Injectable({ providedIn: 'root' })
 export class OrganizationHttpService {
   private readonly http = inject(HttpClient);
@@ -27,6 +35,10 @@ export class OrganizationHttpService {
     return this.http.post<OrganizationResponse>(this.baseUrl, data);
   }
 
+  getProvisioningStatus(id: string): Observable<ProvisioningStatusResponse> {
+    return this.http.get<ProvisioningStatusResponse>(`${this.baseUrl}/${id}/provisioning`);
+  }
+
   getOrganization(id: string): Observable<OrganizationResponse> {
     return this.http.get<OrganizationResponse>(`${this.baseUrl}/${id}`);
   }